@@ -79,6 +79,9 @@ func (noopBridgeStore) LastFrozenEventBlockNum() uint64 {
 func (noopBridgeStore) EventTxnToBlockNum(ctx context.Context, borTxHash common.Hash) (uint64, bool, error) {
 	return 0, false, errors.New("noop")
 }
+func (noopBridgeStore) EventTxnsToBlockNums(ctx context.Context, borTxHashes []common.Hash) (map[common.Hash]uint64, error) {
+	return nil, errors.New("noop")
+}
 func (noopBridgeStore) EventsByTimeframe(ctx context.Context, timeFrom, timeTo uint64) ([][]byte, []uint64, error) {
 	return nil, nil, errors.New("noop")
 }
@@ -109,7 +112,7 @@ func (noopBridgeStore) BorStartEventId(ctx context.Context, hash common.Hash, bl
 func (noopBridgeStore) EventsByBlock(ctx context.Context, hash common.Hash, blockNum uint64) ([]rlp.RawValue, error) {
 	return nil, errors.New("noop")
 }
-func (noopBridgeStore) EventsByIdFromSnapshot(from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
+func (noopBridgeStore) EventsByIdFromSnapshot(ctx context.Context, from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
 	return nil, false, errors.New("noop")
 }
 func (noopBridgeStore) PruneEvents(ctx context.Context, blocksTo uint64, blocksDeleteLimit int) (deleted int, err error) {
@@ -221,8 +224,8 @@ func (h *HeimdallSimulator) FetchSpans(ctx context.Context, page uint64, limit u
 	return nil, errors.New("method FetchSpans is not implemented")
 }
 
-func (h *HeimdallSimulator) FetchStateSyncEvents(_ context.Context, fromId uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, error) {
-	events, _, err := h.blockReader.EventsByIdFromSnapshot(fromId, to, limit)
+func (h *HeimdallSimulator) FetchStateSyncEvents(ctx context.Context, fromId uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, error) {
+	events, _, err := h.blockReader.EventsByIdFromSnapshot(ctx, fromId, to, limit)
 	return events, err
 }
 
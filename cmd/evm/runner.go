@@ -90,6 +90,9 @@ func readGenesis(genesisPath string) *types.Genesis {
 	if err := json.NewDecoder(file).Decode(genesis); err != nil {
 		utils.Fatalf("invalid genesis file: %v", err)
 	}
+	if err := types.ResolveAllocFile(genesis, genesisPath); err != nil {
+		utils.Fatalf("invalid genesis file: %v", err)
+	}
 	return genesis
 }
 
@@ -1262,6 +1262,7 @@ func newSync(ctx context.Context, db kv.TemporalRwDB, miningConfig *params.Minin
 		engine,
 		nil,
 		ethconfig.Defaults.Sync,
+		nil, /* receiptsGenerator */
 		blockReader,
 		blockBufferSize,
 		statusDataProvider,
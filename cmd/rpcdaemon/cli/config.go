@@ -34,7 +34,6 @@ import (
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/grpc"
-	grpcHealth "google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/erigontech/erigon-db/rawdb"
@@ -124,10 +123,14 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().BoolVar(&cfg.GraphQLEnabled, "graphql", false, "enables graphql endpoint (disabled by default)")
 	rootCmd.PersistentFlags().Uint64Var(&cfg.Gascap, "rpc.gascap", 50_000_000, "Sets a cap on gas that can be used in eth_call/estimateGas")
 	rootCmd.PersistentFlags().Uint64Var(&cfg.MaxTraces, "trace.maxtraces", 200, "Sets a limit on traces that can be returned in trace_filter")
+	rootCmd.PersistentFlags().Uint64Var(&cfg.TraceFilterMaxBytes, utils.TraceFilterMaxBytesFlag.Name, utils.TraceFilterMaxBytesFlag.Value, utils.TraceFilterMaxBytesFlag.Usage)
 
-	rootCmd.PersistentFlags().StringVar(&cfg.RpcAllowListFilePath, utils.RpcAccessListFlag.Name, "", "Specify granular (method-by-method) API allowlist")
+	rootCmd.PersistentFlags().StringVar(&cfg.RpcAllowListFilePath, utils.RpcAccessListFlag.Name, "", utils.RpcAccessListFlag.Usage)
 	rootCmd.PersistentFlags().UintVar(&cfg.RpcBatchConcurrency, utils.RpcBatchConcurrencyFlag.Name, 2, utils.RpcBatchConcurrencyFlag.Usage)
 	rootCmd.PersistentFlags().BoolVar(&cfg.RpcStreamingDisable, utils.RpcStreamingDisableFlag.Name, false, utils.RpcStreamingDisableFlag.Usage)
+	rootCmd.PersistentFlags().StringVar((*string)(&cfg.RpcTxReuse), utils.RpcTxReuseFlag.Name, utils.RpcTxReuseFlag.Value, utils.RpcTxReuseFlag.Usage)
+	rootCmd.PersistentFlags().DurationVar(&cfg.RpcTxReuseMaxAge, utils.RpcTxReuseMaxAgeFlag.Name, utils.RpcTxReuseMaxAgeFlag.Value, utils.RpcTxReuseMaxAgeFlag.Usage)
+	rootCmd.PersistentFlags().BoolVar(&cfg.RpcKvStats, utils.RpcKvStatsFlag.Name, false, utils.RpcKvStatsFlag.Usage)
 	rootCmd.PersistentFlags().BoolVar(&cfg.DebugSingleRequest, utils.HTTPDebugSingleFlag.Name, false, utils.HTTPDebugSingleFlag.Usage)
 	rootCmd.PersistentFlags().IntVar(&cfg.DBReadConcurrency, utils.DBReadConcurrencyFlag.Name, utils.DBReadConcurrencyFlag.Value, utils.DBReadConcurrencyFlag.Usage)
 	rootCmd.PersistentFlags().BoolVar(&cfg.TraceCompatibility, "trace.compat", false, "Bug for bug compatibility with OE for trace_ routines")
@@ -137,7 +140,7 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().BoolVar(&cfg.GRPCServerEnabled, "grpc", false, "Enable GRPC server")
 	rootCmd.PersistentFlags().StringVar(&cfg.GRPCListenAddress, "grpc.addr", nodecfg.DefaultGRPCHost, "GRPC server listening interface")
 	rootCmd.PersistentFlags().IntVar(&cfg.GRPCPort, "grpc.port", nodecfg.DefaultGRPCPort, "GRPC server listening port")
-	rootCmd.PersistentFlags().BoolVar(&cfg.GRPCHealthCheckEnabled, "grpc.healthcheck", false, "Enable GRPC health check")
+	rootCmd.PersistentFlags().BoolVar(&cfg.GRPCHealthCheckEnabled, "grpc.healthcheck", false, "Enable GRPC health check, running the checks of /health given as the query string of the service name")
 	rootCmd.PersistentFlags().Float64Var(&ethconfig.Defaults.RPCTxFeeCap, utils.RPCGlobalTxFeeCapFlag.Name, utils.RPCGlobalTxFeeCapFlag.Value, utils.RPCGlobalTxFeeCapFlag.Usage)
 	rootCmd.PersistentFlags().StringVar(&cfg.TLSCertfile, "tls.cert", "", "certificate for client side TLS handshake for GRPC")
 	rootCmd.PersistentFlags().StringVar(&cfg.TLSKeyFile, "tls.key", "", "key file for client side TLS handshake for GRPC")
@@ -154,6 +157,8 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().BoolVar(&cfg.HttpCompression, "http.compression", true, "Enable http compression enabled by default. Use --http.compression=false to disable it")
 	rootCmd.PersistentFlags().BoolVar(&cfg.WebsocketEnabled, "ws", false, "Enable Websockets - Same port as HTTP[S]")
 	rootCmd.PersistentFlags().BoolVar(&cfg.WebsocketCompression, "ws.compression", true, "Enable Websocket compression (RFC 7692) enabled by default is Websockets is enabled. Use --ws.compression=false to disable it")
+	rootCmd.PersistentFlags().IntVar(&cfg.WebsocketCompressionLevel, utils.WsCompressionLevelFlag.Name, utils.WsCompressionLevelFlag.Value, utils.WsCompressionLevelFlag.Usage)
+	rootCmd.PersistentFlags().IntVar(&cfg.WebsocketCompressionMinSize, utils.WsCompressionMinSizeFlag.Name, utils.WsCompressionMinSizeFlag.Value, utils.WsCompressionMinSizeFlag.Usage)
 	rootCmd.PersistentFlags().IntVar(&cfg.WebsocketPort, "ws.port", nodecfg.DefaultWSPort, "rpc WebSocket server listening port")
 	rootCmd.PersistentFlags().BoolVar(&cfg.HttpsServerEnabled, "https.enabled", false, "Enable HTTPS server")
 	rootCmd.PersistentFlags().StringVar(&cfg.HttpsListenAddress, "https.addr", nodecfg.DefaultHTTPHost, "rpc HTTPS server listening interface")
@@ -172,12 +177,25 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().DurationVar(&cfg.EvmCallTimeout, "rpc.evmtimeout", rpccfg.DefaultEvmCallTimeout, "Maximum amount of time to wait for the answer from EVM call.")
 	rootCmd.PersistentFlags().DurationVar(&cfg.OverlayGetLogsTimeout, "rpc.overlay.getlogstimeout", rpccfg.DefaultOverlayGetLogsTimeout, "Maximum amount of time to wait for the answer from the overlay_getLogs call.")
 	rootCmd.PersistentFlags().DurationVar(&cfg.OverlayReplayBlockTimeout, "rpc.overlay.replayblocktimeout", rpccfg.DefaultOverlayReplayBlockTimeout, "Maximum amount of time to wait for the answer to replay a single block when called from an overlay_getLogs call.")
-	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionFiltersMaxLogs, "rpc.subscription.filters.maxlogs", rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxLogs, "Maximum number of logs to store per subscription.")
+	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionFiltersMaxLogs, "rpc.subscription.filters.maxlogs", rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxLogs, "Maximum number of logs to store per subscription, and to return per eth_getLogs call.")
 	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionFiltersMaxHeaders, "rpc.subscription.filters.maxheaders", rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxHeaders, "Maximum number of block headers to store per subscription.")
 	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionFiltersMaxTxs, "rpc.subscription.filters.maxtxs", rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxTxs, "Maximum number of transactions to store per subscription.")
 	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionFiltersMaxAddresses, "rpc.subscription.filters.maxaddresses", rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxAddresses, "Maximum number of addresses per subscription to filter logs by.")
 	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionFiltersMaxTopics, "rpc.subscription.filters.maxtopics", rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxTopics, "Maximum number of topics per subscription to filter logs by.")
+	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionHeadsBufferSize, "rpc.subscription.heads.buffer", rpchelper.DefaultFiltersConfig.RpcSubscriptionHeadsBufferSize, "Number of block headers buffered per newHeads subscription (0 = default of the subscription).")
+	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionLogsBufferSize, "rpc.subscription.logs.buffer", rpchelper.DefaultFiltersConfig.RpcSubscriptionLogsBufferSize, "Number of logs buffered per logs subscription (0 = default of the subscription).")
+	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionTxsBufferSize, "rpc.subscription.txs.buffer", rpchelper.DefaultFiltersConfig.RpcSubscriptionTxsBufferSize, "Number of transaction batches buffered per pending transactions subscription (0 = default of the subscription).")
+	rootCmd.PersistentFlags().StringVar((*string)(&cfg.RpcFiltersConfig.RpcSubscriptionSlowConsumer), "rpc.subscription.slowconsumer", string(rpchelper.DefaultFiltersConfig.RpcSubscriptionSlowConsumer), "What to do when a subscriber doesn't keep up and its buffer is full: drop-newest, drop-oldest or disconnect.")
+	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionLogsRateLimit, "rpc.subscription.logs.ratelimit", rpchelper.DefaultFiltersConfig.RpcSubscriptionLogsRateLimit, "Maximum number of logs notified per second per logs subscription, the subscription is sent a truncation notice instead of the logs beyond it (0 = no limit).")
+	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionLogsMaxPerBlock, "rpc.subscription.logs.maxperblock", rpchelper.DefaultFiltersConfig.RpcSubscriptionLogsMaxPerBlock, "Maximum number of logs of a block notified per logs subscription, the subscription is sent a truncation notice instead of the logs beyond it (0 = no limit).")
+	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionLogsGlobalRateLimit, "rpc.subscription.logs.globalratelimit", rpchelper.DefaultFiltersConfig.RpcSubscriptionLogsGlobalRateLimit, "Maximum number of logs notified per second across all logs subscriptions, a subscription is sent a truncation notice instead of the logs beyond it (0 = no limit).")
+	rootCmd.PersistentFlags().DurationVar(&cfg.RpcFiltersConfig.RpcFiltersTTL, "rpc.filters.ttl", rpchelper.DefaultFiltersConfig.RpcFiltersTTL, "How long a filter installed by eth_newFilter, eth_newBlockFilter or eth_newPendingTransactionFilter lives without being polled or kept alive by eth_keepAliveFilter (0 = forever).")
+	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcFiltersMaxPerClient, "rpc.filters.maxperclient", rpchelper.DefaultFiltersConfig.RpcFiltersMaxPerClient, "Maximum number of filters installed per client IP address (0 = no limit).")
+	rootCmd.PersistentFlags().IntVar(&cfg.Sync.RPCReceiptsCache.Blocks, "rpc.receipts.cache.blocks", ethconfig.Defaults.Sync.RPCReceiptsCache.Blocks, "Number of blocks whose generated receipts are cached (0 = default)")
+	rootCmd.PersistentFlags().DurationVar(&cfg.Sync.RPCReceiptsCache.TTL, "rpc.receipts.cache.ttl", ethconfig.Defaults.Sync.RPCReceiptsCache.TTL, "How long generated receipts stay cached (0 = until evicted)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.Sync.PersistentReceiptsCache.Enabled, "receipts.persistent.cache", ethconfig.Defaults.Sync.PersistentReceiptsCache.Enabled, "Look up generated receipts in the persistent cache written by the erigon node")
 	rootCmd.PersistentFlags().IntVar(&cfg.BatchLimit, utils.RpcBatchLimit.Name, utils.RpcBatchLimit.Value, utils.RpcBatchLimit.Usage)
+	rootCmd.PersistentFlags().Uint64Var(&cfg.BatchGasLimit, utils.RpcBatchGasLimit.Name, utils.RpcBatchGasLimit.Value, utils.RpcBatchGasLimit.Usage)
 	rootCmd.PersistentFlags().IntVar(&cfg.ReturnDataLimit, utils.RpcReturnDataLimit.Name, utils.RpcReturnDataLimit.Value, utils.RpcReturnDataLimit.Usage)
 	rootCmd.PersistentFlags().BoolVar(&cfg.AllowUnprotectedTxs, utils.AllowUnprotectedTxs.Name, utils.AllowUnprotectedTxs.Value, utils.AllowUnprotectedTxs.Usage)
 	rootCmd.PersistentFlags().Uint64Var(&cfg.OtsMaxPageSize, utils.OtsSearchMaxCapFlag.Name, utils.OtsSearchMaxCapFlag.Value, utils.OtsSearchMaxCapFlag.Usage)
@@ -203,6 +221,10 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 			return fmt.Errorf("state.cache value of %v is not valid", stateCacheStr)
 		}
 
+		if err := cfg.RpcFiltersConfig.RpcSubscriptionSlowConsumer.Validate(); err != nil {
+			return fmt.Errorf("rpc.subscription.slowconsumer: %w", err)
+		}
+
 		cfg.WithDatadir = cfg.DataDir != ""
 		if cfg.WithDatadir {
 			if cfg.DataDir == "" {
@@ -548,6 +570,7 @@ func RemoteServices(ctx context.Context, cfg *httpcfg.HttpCfg, logger log.Logger
 	}
 
 	remoteEth := rpcservices.NewRemoteBackend(remoteBackendClient, db, blockReader)
+	remoteEth.SetConnection(conn)
 	blockReader = remoteEth
 	eth = remoteEth
 
@@ -656,9 +679,11 @@ func RemoteServices(ctx context.Context, cfg *httpcfg.HttpCfg, logger log.Logger
 	return db, eth, txPool, mining, stateCache, blockReader, engine, ff, bridgeReader, heimdallReader, err
 }
 
-func StartRpcServer(ctx context.Context, cfg *httpcfg.HttpCfg, rpcAPI []rpc.API, logger log.Logger) error {
+// StartRpcServer serves rpcAPI. backendAPI reports the connection to the backend in the /health checks, nil if the
+// backend is in-process.
+func StartRpcServer(ctx context.Context, cfg *httpcfg.HttpCfg, rpcAPI []rpc.API, backendAPI health.BackendAPI, logger log.Logger) error {
 	if cfg.Enabled {
-		return startRegularRpcServer(ctx, cfg, rpcAPI, logger)
+		return startRegularRpcServer(ctx, cfg, rpcAPI, backendAPI, logger)
 	}
 
 	return nil
@@ -676,19 +701,36 @@ func StartRpcServerWithJwtAuthentication(ctx context.Context, cfg *httpcfg.HttpC
 	return nil
 }
 
-func startRegularRpcServer(ctx context.Context, cfg *httpcfg.HttpCfg, rpcAPI []rpc.API, logger log.Logger) error {
+func startRegularRpcServer(ctx context.Context, cfg *httpcfg.HttpCfg, rpcAPI []rpc.API, backendAPI health.BackendAPI, logger log.Logger) error {
 	// register apis and create handler stack
 	srv := rpc.NewServer(cfg.RpcBatchConcurrency, cfg.TraceRequests, cfg.DebugSingleRequest, cfg.RpcStreamingDisable, logger, cfg.RPCSlowLogThreshold)
 
-	allowListForRPC, err := parseAllowListForRPC(cfg.RpcAllowListFilePath)
+	accessListForRPC, err := parseAccessListForRPC(cfg.RpcAllowListFilePath)
 	if err != nil {
 		return err
 	}
-	srv.SetAllowList(allowListForRPC)
+	srv.SetAccessList(accessListForRPC)
+	if accessListForRPC != nil {
+		reloadAccessListOnSIGHUP(ctx, srv, cfg.RpcAllowListFilePath, logger)
+	}
 
 	srv.SetBatchLimit(cfg.BatchLimit)
+	srv.SetBatchGasLimit(cfg.BatchGasLimit, cfg.Gascap)
+	txReuse, err := rpchelper.ParseTxReuse(string(cfg.RpcTxReuse))
+	if err != nil {
+		return err
+	}
+	if txReuse != rpchelper.TxReuseOff {
+		srv.SetRequestScope(rpchelper.TxScope, txReuse == rpchelper.TxReuseConnection)
+	}
+	srv.SetMethodLabels(cfg.RpcKvStats)
 
-	defer srv.Stop()
+	defer func() {
+		// lets the subscriptions, closed by the filters on shutdown, send their pending notifications
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
 
 	var defaultAPIList []rpc.API
 
@@ -740,10 +782,10 @@ func startRegularRpcServer(ctx context.Context, cfg *httpcfg.HttpCfg, rpcAPI []r
 	httpHandler := node.NewHTTPHandlerStack(srv, cfg.HttpCORSDomain, cfg.HttpVirtualHost, cfg.HttpCompression)
 	var wsHandler http.Handler
 	if cfg.WebsocketEnabled {
-		wsHandler = srv.WebsocketHandler([]string{"*"}, nil, cfg.WebsocketCompression, logger)
+		wsHandler = srv.WebsocketHandler([]string{"*"}, nil, websocketCompression(cfg), logger)
 	}
 	graphQLHandler := graphql.CreateHandler(defaultAPIList)
-	apiHandler, err := createHandler(cfg, defaultAPIList, httpHandler, wsHandler, graphQLHandler, nil)
+	apiHandler, err := createHandler(cfg, defaultAPIList, backendAPI, httpHandler, wsHandler, graphQLHandler, nil)
 	if err != nil {
 		return err
 	}
@@ -819,7 +861,7 @@ func startRegularRpcServer(ctx context.Context, cfg *httpcfg.HttpCfg, rpcAPI []r
 	}
 
 	var (
-		healthServer *grpcHealth.Server
+		healthServer *health.GRPCServer
 		grpcServer   *grpc.Server
 		grpcListener net.Listener
 		grpcEndpoint string
@@ -831,7 +873,7 @@ func startRegularRpcServer(ctx context.Context, cfg *httpcfg.HttpCfg, rpcAPI []r
 		}
 		grpcServer = grpc.NewServer()
 		if cfg.GRPCHealthCheckEnabled {
-			healthServer = grpcHealth.NewServer()
+			healthServer = health.NewGRPCServer(rpcAPI, backendAPI)
 			grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 		}
 		go grpcServer.Serve(grpcListener)
@@ -925,14 +967,23 @@ func ObtainJWTSecret(cfg *httpcfg.HttpCfg, logger log.Logger) ([]byte, error) {
 	return jwtSecret, nil
 }
 
-func createHandler(cfg *httpcfg.HttpCfg, apiList []rpc.API, httpHandler http.Handler, wsHandler http.Handler, graphQLHandler http.Handler, jwtSecret []byte) (http.Handler, error) {
+// websocketCompression returns the compression of the websocket messages set by cfg.
+func websocketCompression(cfg *httpcfg.HttpCfg) rpc.WebsocketCompression {
+	return rpc.WebsocketCompression{
+		Enabled: cfg.WebsocketCompression,
+		Level:   cfg.WebsocketCompressionLevel,
+		MinSize: cfg.WebsocketCompressionMinSize,
+	}
+}
+
+func createHandler(cfg *httpcfg.HttpCfg, apiList []rpc.API, backendAPI health.BackendAPI, httpHandler http.Handler, wsHandler http.Handler, graphQLHandler http.Handler, jwtSecret []byte) (http.Handler, error) {
 	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if cfg.GraphQLEnabled && graphql.ProcessGraphQLcheckIfNeeded(graphQLHandler, w, r) {
 			return
 		}
 
 		// adding a healthcheck here
-		if health.ProcessHealthcheckIfNeeded(w, r, apiList) {
+		if health.ProcessHealthcheckIfNeeded(w, r, apiList, backendAPI) {
 			return
 		}
 		if cfg.WebsocketEnabled && wsHandler != nil && isWebsocket(r) {
@@ -964,13 +1015,13 @@ func createEngineListener(cfg *httpcfg.HttpCfg, engineApi []rpc.API, logger log.
 		return nil, nil, "", err
 	}
 
-	wsHandler := engineSrv.WebsocketHandler([]string{"*"}, jwtSecret, cfg.WebsocketCompression, logger)
+	wsHandler := engineSrv.WebsocketHandler([]string{"*"}, jwtSecret, websocketCompression(cfg), logger)
 
 	engineHttpHandler := node.NewHTTPHandlerStack(engineSrv, nil /* authCors */, cfg.AuthRpcVirtualHost, cfg.HttpCompression)
 
 	graphQLHandler := graphql.CreateHandler(engineApi)
 
-	engineApiHandler, err := createHandler(cfg, engineApi, engineHttpHandler, wsHandler, graphQLHandler, jwtSecret)
+	engineApiHandler, err := createHandler(cfg, engineApi, nil /* backendAPI */, engineHttpHandler, wsHandler, graphQLHandler, jwtSecret)
 	if err != nil {
 		return nil, nil, "", err
 	}
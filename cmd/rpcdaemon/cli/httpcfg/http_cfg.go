@@ -61,13 +61,19 @@ type HttpCfg struct {
 	Gascap                            uint64
 	Feecap                            float64
 	MaxTraces                         uint64
+	TraceFilterMaxBytes               uint64 // size of the traces from which trace_filter ends its response with an error, unlimited if 0
 	WebsocketPort                     int
 	WebsocketEnabled                  bool
 	WebsocketCompression              bool
+	WebsocketCompressionLevel         int
+	WebsocketCompressionMinSize       int
 	WebsocketSubscribeLogsChannelSize int
 	RpcAllowListFilePath              string
 	RpcBatchConcurrency               uint
 	RpcStreamingDisable               bool
+	RpcTxReuse                        rpchelper.TxReuse // scope of the read transactions reused by the requests
+	RpcTxReuseMaxAge                  time.Duration     // age from which a reused read transaction is refreshed
+	RpcKvStats                        bool              // whether the reads of the database are counted per table and method
 	RpcFiltersConfig                  rpchelper.FiltersConfig
 	DBReadConcurrency                 int
 	TraceCompatibility                bool // Bug for bug compatibility for trace_ routines with OpenEthereum
@@ -98,10 +104,11 @@ type HttpCfg struct {
 	LogDirVerbosity string
 	LogDirPath      string
 
-	BatchLimit                  int  // Maximum number of requests in a batch
-	ReturnDataLimit             int  // Maximum number of bytes returned from calls (like eth_call)
-	AllowUnprotectedTxs         bool // Whether to allow non EIP-155 protected transactions  txs over RPC
-	MaxGetProofRewindBlockCount int  //Max GetProof rewind block count
+	BatchLimit                  int    // Maximum number of requests in a batch
+	BatchGasLimit               uint64 // Maximum cumulative gas of the call-type requests in a batch
+	ReturnDataLimit             int    // Maximum number of bytes returned from calls (like eth_call)
+	AllowUnprotectedTxs         bool   // Whether to allow non EIP-155 protected transactions  txs over RPC
+	MaxGetProofRewindBlockCount int    //Max GetProof rewind block count
 	// Ots API
 	OtsMaxPageSize uint64
 
@@ -17,19 +17,20 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+
+	"github.com/erigontech/erigon-lib/log/v3"
 
 	"github.com/erigontech/erigon/rpc"
 )
 
-type allowListFile struct {
-	Allow rpc.AllowList `json:"allow"`
-}
-
-func parseAllowListForRPC(path string) (rpc.AllowList, error) {
+func parseAccessListForRPC(path string) (*rpc.AccessList, error) {
 	path = strings.TrimSpace(path)
 	if path == "" { // no file is provided
 		return nil, nil
@@ -48,12 +49,37 @@ func parseAllowListForRPC(path string) (rpc.AllowList, error) {
 		return nil, err
 	}
 
-	var allowListFileObj allowListFile
+	var accessList rpc.AccessList
 
-	err = json.Unmarshal(fileContents, &allowListFileObj)
+	err = json.Unmarshal(fileContents, &accessList)
 	if err != nil {
 		return nil, err
 	}
 
-	return allowListFileObj.Allow, nil
+	return &accessList, nil
+}
+
+// reloadAccessListOnSIGHUP sets the access list of srv to the one read again from path on each SIGHUP, until ctx is
+// done. The open connections are kept, their next calls are handled under the new list. An invalid file keeps the
+// current list.
+func reloadAccessListOnSIGHUP(ctx context.Context, srv *rpc.Server, path string, logger log.Logger) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(sighup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sighup:
+			}
+			accessList, err := parseAccessListForRPC(path)
+			if err != nil {
+				logger.Warn("[rpc] can't reload the access list, keeping the current one", "path", path, "err", err)
+				continue
+			}
+			srv.SetAccessList(accessList)
+			logger.Info("[rpc] reloaded the access list", "path", path, "allow", len(accessList.Allow), "deny", len(accessList.Deny))
+		}
+	}()
 }
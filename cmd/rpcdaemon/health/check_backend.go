@@ -0,0 +1,36 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/erigontech/erigon/rpc/rpchelper"
+)
+
+func checkBackend(ctx context.Context, api BackendAPI) error {
+	if api == nil {
+		return errCheckDisabled
+	}
+	h := api.Health(ctx)
+	if h.Connected {
+		return nil
+	}
+	return fmt.Errorf("%w since %s: %v", rpchelper.ErrBackendUnavailable, h.Since.Format(time.RFC3339), h.LastError)
+}
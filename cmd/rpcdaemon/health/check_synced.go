@@ -17,8 +17,8 @@
 package health
 
 import (
+	"context"
 	"errors"
-	"net/http"
 
 	"github.com/erigontech/erigon-lib/log/v3"
 )
@@ -27,8 +27,11 @@ var (
 	errNotSynced = errors.New("not synced")
 )
 
-func checkSynced(ethAPI EthAPI, r *http.Request) error {
-	i, err := ethAPI.Syncing(r.Context())
+func checkSynced(ctx context.Context, ethAPI EthAPI) error {
+	if ethAPI == nil {
+		return errors.New("no connection to the Erigon server or `eth` namespace isn't enabled")
+	}
+	i, err := ethAPI.Syncing(ctx)
 	if err != nil {
 		log.Root().Warn("unable to process synced request", "err", err.Error())
 		return err
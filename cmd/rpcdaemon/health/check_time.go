@@ -17,9 +17,9 @@
 package health
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
 
 	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon/rpc"
@@ -30,11 +30,14 @@ var (
 )
 
 func checkTime(
-	r *http.Request,
+	ctx context.Context,
 	seconds int,
 	ethAPI EthAPI,
 ) error {
-	i, err := ethAPI.GetBlockByNumber(r.Context(), rpc.LatestBlockNumber, false)
+	if ethAPI == nil {
+		return errors.New("no connection to the Erigon server or `eth` namespace isn't enabled")
+	}
+	i, err := ethAPI.GetBlockByNumber(ctx, rpc.LatestBlockNumber, false)
 	if err != nil {
 		return err
 	}
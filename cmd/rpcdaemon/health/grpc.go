@@ -0,0 +1,130 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/rpc"
+)
+
+// watchInterval is the interval at which GRPCServer.Watch runs the checks again.
+const watchInterval = 5 * time.Second
+
+// GRPCServer is a gRPC health service running the checks of the /health endpoint. The service name of a request is
+// the query string of the checks, e.g. "min_peer_count=3&max_seconds_behind=60&synced=true": the service is SERVING
+// if all of them pass, and the backend is connected. The empty service name checks the backend only.
+type GRPCServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	netAPI     NetAPI
+	ethAPI     EthAPI
+	backendAPI BackendAPI // nil if the backend is in-process
+
+	shutdownOnce sync.Once
+	shutdown     chan struct{}
+}
+
+func NewGRPCServer(rpcAPI []rpc.API, backendAPI BackendAPI) *GRPCServer {
+	netAPI, ethAPI := parseAPI(rpcAPI)
+	return &GRPCServer{netAPI: netAPI, ethAPI: ethAPI, backendAPI: backendAPI, shutdown: make(chan struct{})}
+}
+
+func (s *GRPCServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	select {
+	case <-s.shutdown:
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}, nil
+	default:
+	}
+	params, err := serviceChecks(req.Service)
+	if err != nil {
+		return nil, err
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: s.status(ctx, params)}, nil
+}
+
+// Watch sends the status of the service, then each change of it, running the checks every watchInterval.
+func (s *GRPCServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	params, err := serviceChecks(req.Service)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	last := grpc_health_v1.HealthCheckResponse_UNKNOWN
+	for {
+		current := s.status(stream.Context(), params)
+		if current != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: current}); err != nil {
+				return status.Error(codes.Canceled, "stream has ended")
+			}
+			last = current
+		}
+		select {
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		case <-s.shutdown:
+			// as grpc's own health server, let the watchers know the server is going away
+			if last != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+				_ = stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING})
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown reports the service NOT_SERVING from now on, and ends the Watch streams so that the gRPC server can stop
+// gracefully.
+func (s *GRPCServer) Shutdown() {
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+}
+
+func (s *GRPCServer) status(ctx context.Context, params map[string]string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	errs := runChecks(ctx, params, s.ethAPI, s.netAPI)
+	errs[backend] = checkBackend(ctx, s.backendAPI)
+	for name, err := range errs {
+		if shouldChangeStatusCode(err) {
+			log.Root().Debug("[rpc] gRPC health check failed", "check", name, "err", err)
+			return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVING
+}
+
+// serviceChecks returns the checks requested by the service name of a gRPC health check, see GRPCServer. Unknown
+// services are reported NOT_FOUND, as by grpc's own health server.
+func serviceChecks(service string) (map[string]string, error) {
+	query, err := url.ParseQuery(service)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q: %v", service, err)
+	}
+	params := queryChecks(query)
+	if len(params) != len(query) {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q, expected the query string of the checks", service)
+	}
+	return params, nil
+}
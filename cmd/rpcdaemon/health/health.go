@@ -17,11 +17,13 @@
 package health
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -43,17 +45,23 @@ const (
 	minPeerCount     = "min_peer_count"
 	checkBlock       = "check_block"
 	maxSecondsBehind = "max_seconds_behind"
+	backend          = "backend"
 )
 
 var (
-	errCheckDisabled  = errors.New("error check disabled")
-	errBadHeaderValue = errors.New("bad header value")
+	errCheckDisabled = errors.New("error check disabled")
+	errBadCheckValue = errors.New("bad check value")
 )
 
+// checkNames are the checks a probe requests by the headers, the query string or the service of a gRPC health
+// check, see runChecks.
+var checkNames = []string{synced, minPeerCount, checkBlock, maxSecondsBehind}
+
 func ProcessHealthcheckIfNeeded(
 	w http.ResponseWriter,
 	r *http.Request,
 	rpcAPI []rpc.API,
+	backendAPI BackendAPI, // nil if the backend is in-process
 ) bool {
 	if !strings.EqualFold(r.URL.Path, urlPath) {
 		return false
@@ -61,64 +69,108 @@ func ProcessHealthcheckIfNeeded(
 
 	netAPI, ethAPI := parseAPI(rpcAPI)
 
+	// always checked: nothing else can be healthy without the backend
+	errCheckBackend := checkBackend(r.Context(), backendAPI)
+
 	headers := r.Header.Values(healthHeader)
 	if len(headers) != 0 {
-		processFromHeaders(headers, ethAPI, netAPI, w, r)
+		processFromHeaders(headers, ethAPI, netAPI, errCheckBackend, w, r)
+	} else if params := queryChecks(r.URL.Query()); len(params) != 0 {
+		reportHealthFromChecks(runChecks(r.Context(), params, ethAPI, netAPI), errCheckBackend, w)
 	} else {
-		processFromBody(w, r, netAPI, ethAPI)
+		processFromBody(w, r, netAPI, ethAPI, errCheckBackend)
 	}
 
 	return true
 }
 
-func processFromHeaders(headers []string, ethAPI EthAPI, netAPI NetAPI, w http.ResponseWriter, r *http.Request) {
-	var (
-		errCheckSynced  = errCheckDisabled
-		errCheckPeer    = errCheckDisabled
-		errCheckBlock   = errCheckDisabled
-		errCheckSeconds = errCheckDisabled
-	)
-
+func processFromHeaders(headers []string, ethAPI EthAPI, netAPI NetAPI, errCheckBackend error, w http.ResponseWriter, r *http.Request) {
+	params := make(map[string]string)
 	for _, header := range headers {
 		lHeader := strings.ToLower(header)
 		if lHeader == synced {
-			errCheckSynced = checkSynced(ethAPI, r)
+			params[synced] = "true"
+			continue
 		}
-		if after, ok := strings.CutPrefix(lHeader, minPeerCount); ok {
-			peers, err := strconv.Atoi(after)
-			if err != nil {
-				errCheckPeer = err
-				break
+		for _, name := range []string{minPeerCount, checkBlock, maxSecondsBehind} {
+			if after, ok := strings.CutPrefix(lHeader, name); ok {
+				params[name] = after
 			}
-			errCheckPeer = checkMinPeers(uint(peers), netAPI)
 		}
-		if after, ok := strings.CutPrefix(lHeader, checkBlock); ok {
-			block, err := strconv.Atoi(after)
-			if err != nil {
-				errCheckBlock = err
-				break
-			}
-			errCheckBlock = checkBlockNumber(rpc.BlockNumber(block), ethAPI)
+	}
+
+	reportHealthFromChecks(runChecks(r.Context(), params, ethAPI, netAPI), errCheckBackend, w)
+}
+
+// queryChecks returns the checks requested by the query string, by name with their parameter, e.g.
+// ?min_peer_count=3&max_seconds_behind=60&synced=true. The other parameters are ignored.
+func queryChecks(query url.Values) map[string]string {
+	params := make(map[string]string)
+	for _, name := range checkNames {
+		if query.Has(name) {
+			params[name] = query.Get(name)
 		}
-		if after, ok := strings.CutPrefix(lHeader, maxSecondsBehind); ok {
-			seconds, err := strconv.Atoi(after)
-			if err != nil {
-				errCheckSeconds = err
-				break
-			}
-			if seconds < 0 {
-				errCheckSeconds = errBadHeaderValue
-				break
-			}
-			now := time.Now().Unix()
-			errCheckSeconds = checkTime(r, int(now)-seconds, ethAPI)
+	}
+	return params
+}
+
+// runChecks runs the checks requested by a probe, given by name with their parameter, and returns their results by
+// name: the checks not requested are reported disabled, the ones with an invalid parameter fail.
+func runChecks(ctx context.Context, params map[string]string, ethAPI EthAPI, netAPI NetAPI) map[string]error {
+	errs := make(map[string]error, len(checkNames))
+	for _, name := range checkNames {
+		param, ok := params[name]
+		if !ok {
+			errs[name] = errCheckDisabled
+			continue
 		}
+		errs[name] = runCheck(ctx, name, param, ethAPI, netAPI)
 	}
+	return errs
+}
 
-	reportHealthFromHeaders(errCheckSynced, errCheckPeer, errCheckBlock, errCheckSeconds, w)
+func runCheck(ctx context.Context, name, param string, ethAPI EthAPI, netAPI NetAPI) error {
+	switch name {
+	case synced:
+		enabled, err := strconv.ParseBool(param)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return errCheckDisabled
+		}
+		return checkSynced(ctx, ethAPI)
+	case minPeerCount:
+		peers, err := strconv.Atoi(param)
+		if err != nil {
+			return err
+		}
+		if peers < 0 {
+			return errBadCheckValue
+		}
+		return checkMinPeers(ctx, uint(peers), netAPI)
+	case checkBlock:
+		block, err := strconv.Atoi(param)
+		if err != nil {
+			return err
+		}
+		return checkBlockNumber(ctx, rpc.BlockNumber(block), ethAPI)
+	case maxSecondsBehind:
+		seconds, err := strconv.Atoi(param)
+		if err != nil {
+			return err
+		}
+		if seconds < 0 {
+			return errBadCheckValue
+		}
+		now := time.Now().Unix()
+		return checkTime(ctx, int(now)-seconds, ethAPI)
+	default:
+		return fmt.Errorf("unknown check %q", name)
+	}
 }
 
-func processFromBody(w http.ResponseWriter, r *http.Request, netAPI NetAPI, ethAPI EthAPI) {
+func processFromBody(w http.ResponseWriter, r *http.Request, netAPI NetAPI, ethAPI EthAPI, errCheckBackend error) {
 	body, errParse := parseHealthCheckBody(r.Body)
 	defer r.Body.Close()
 
@@ -130,16 +182,16 @@ func processFromBody(w http.ResponseWriter, r *http.Request, netAPI NetAPI, ethA
 	} else {
 		// 1. net_peerCount
 		if body.MinPeerCount != nil {
-			errMinPeerCount = checkMinPeers(*body.MinPeerCount, netAPI)
+			errMinPeerCount = checkMinPeers(r.Context(), *body.MinPeerCount, netAPI)
 		}
 		// 2. custom query (shouldn't fail)
 		if body.BlockNumber != nil {
-			errCheckBlock = checkBlockNumber(*body.BlockNumber, ethAPI)
+			errCheckBlock = checkBlockNumber(r.Context(), *body.BlockNumber, ethAPI)
 		}
 		// TODO add time from the last sync cycle
 	}
 
-	err := reportHealthFromBody(errParse, errMinPeerCount, errCheckBlock, w)
+	err := reportHealthFromBody(errParse, errMinPeerCount, errCheckBlock, errCheckBackend, w)
 	if err != nil {
 		log.Root().Warn("unable to process healthcheck request", "err", err)
 	}
@@ -161,51 +213,50 @@ func parseHealthCheckBody(reader io.Reader) (requestBody, error) {
 	return body, nil
 }
 
-func reportHealthFromBody(errParse, errMinPeerCount, errCheckBlock error, w http.ResponseWriter) error {
+func reportHealthFromBody(errParse, errMinPeerCount, errCheckBlock, errCheckBackend error, w http.ResponseWriter) error {
 	statusCode := http.StatusOK
 	errors := make(map[string]string)
 
 	if shouldChangeStatusCode(errParse) {
-		statusCode = http.StatusInternalServerError
+		statusCode = http.StatusServiceUnavailable
 	}
 	errors["healthcheck_query"] = errorStringOrOK(errParse)
 
 	if shouldChangeStatusCode(errMinPeerCount) {
-		statusCode = http.StatusInternalServerError
+		statusCode = http.StatusServiceUnavailable
 	}
 	errors["min_peer_count"] = errorStringOrOK(errMinPeerCount)
 
 	if shouldChangeStatusCode(errCheckBlock) {
-		statusCode = http.StatusInternalServerError
+		statusCode = http.StatusServiceUnavailable
 	}
 	errors["check_block"] = errorStringOrOK(errCheckBlock)
 
+	if shouldChangeStatusCode(errCheckBackend) {
+		statusCode = http.StatusServiceUnavailable
+	}
+	errors[backend] = errorStringOrOK(errCheckBackend)
+
 	return writeResponse(w, errors, statusCode)
 }
 
-func reportHealthFromHeaders(errCheckSynced, errCheckPeer, errCheckBlock, errCheckSeconds error, w http.ResponseWriter) error {
+// reportHealthFromChecks reports the results of the checks requested by the headers or the query string, and of the
+// backend check.
+func reportHealthFromChecks(checkErrs map[string]error, errCheckBackend error, w http.ResponseWriter) error {
 	statusCode := http.StatusOK
-	errs := make(map[string]string)
-
-	if shouldChangeStatusCode(errCheckSynced) {
-		statusCode = http.StatusInternalServerError
-	}
-	errs[synced] = errorStringOrOK(errCheckSynced)
+	errs := make(map[string]string, len(checkErrs)+1)
 
-	if shouldChangeStatusCode(errCheckPeer) {
-		statusCode = http.StatusInternalServerError
-	}
-	errs[minPeerCount] = errorStringOrOK(errCheckPeer)
-
-	if shouldChangeStatusCode(errCheckBlock) {
-		statusCode = http.StatusInternalServerError
+	for name, err := range checkErrs {
+		if shouldChangeStatusCode(err) {
+			statusCode = http.StatusServiceUnavailable
+		}
+		errs[name] = errorStringOrOK(err)
 	}
-	errs[checkBlock] = errorStringOrOK(errCheckBlock)
 
-	if shouldChangeStatusCode(errCheckSeconds) {
-		statusCode = http.StatusInternalServerError
+	if shouldChangeStatusCode(errCheckBackend) {
+		statusCode = http.StatusServiceUnavailable
 	}
-	errs[maxSecondsBehind] = errorStringOrOK(errCheckSeconds)
+	errs[backend] = errorStringOrOK(errCheckBackend)
 
 	return writeResponse(w, errs, statusCode)
 }
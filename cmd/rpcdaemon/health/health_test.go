@@ -27,9 +27,14 @@ import (
 	"testing"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
 	"github.com/erigontech/erigon-lib/common/hexutil"
 
 	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/rpc/rpchelper"
 )
 
 type netApiStub struct {
@@ -56,6 +61,14 @@ func (e *ethApiStub) Syncing(_ context.Context) (interface{}, error) {
 	return e.syncingResult, e.syncingError
 }
 
+type backendApiStub struct {
+	health rpchelper.BackendHealth
+}
+
+func (b *backendApiStub) Health(_ context.Context) rpchelper.BackendHealth {
+	return b.health
+}
+
 func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 	cases := []struct {
 		headers             []string
@@ -94,7 +107,7 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 			ethApiBlockError:    nil,
 			ethApiSyncingResult: struct{}{},
 			ethApiSyncingError:  nil,
-			expectedStatusCode:  http.StatusInternalServerError,
+			expectedStatusCode:  http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				synced:           "ERROR: not synced",
 				minPeerCount:     "DISABLED",
@@ -111,7 +124,7 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 			ethApiBlockError:    nil,
 			ethApiSyncingResult: struct{}{},
 			ethApiSyncingError:  errors.New("problem checking sync"),
-			expectedStatusCode:  http.StatusInternalServerError,
+			expectedStatusCode:  http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				synced:           "ERROR: problem checking sync",
 				minPeerCount:     "DISABLED",
@@ -145,7 +158,7 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 			ethApiBlockError:    nil,
 			ethApiSyncingResult: false,
 			ethApiSyncingError:  nil,
-			expectedStatusCode:  http.StatusInternalServerError,
+			expectedStatusCode:  http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				synced:           "DISABLED",
 				minPeerCount:     "ERROR: not enough peers: 1 (minimum 10)",
@@ -162,7 +175,7 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 			ethApiBlockError:    nil,
 			ethApiSyncingResult: false,
 			ethApiSyncingError:  nil,
-			expectedStatusCode:  http.StatusInternalServerError,
+			expectedStatusCode:  http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				synced:           "DISABLED",
 				minPeerCount:     "ERROR: problem checking peers",
@@ -179,7 +192,7 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 			ethApiBlockError:    nil,
 			ethApiSyncingResult: false,
 			ethApiSyncingError:  nil,
-			expectedStatusCode:  http.StatusInternalServerError,
+			expectedStatusCode:  http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				synced:           "DISABLED",
 				minPeerCount:     "ERROR: strconv.Atoi: parsing \"abc\": invalid syntax",
@@ -213,7 +226,7 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 			ethApiBlockError:    nil,
 			ethApiSyncingResult: false,
 			ethApiSyncingError:  nil,
-			expectedStatusCode:  http.StatusInternalServerError,
+			expectedStatusCode:  http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				synced:           "DISABLED",
 				minPeerCount:     "DISABLED",
@@ -230,7 +243,7 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 			ethApiBlockError:    errors.New("problem checking block"),
 			ethApiSyncingResult: false,
 			ethApiSyncingError:  nil,
-			expectedStatusCode:  http.StatusInternalServerError,
+			expectedStatusCode:  http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				synced:           "DISABLED",
 				minPeerCount:     "DISABLED",
@@ -247,7 +260,7 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 			ethApiBlockError:    nil,
 			ethApiSyncingResult: false,
 			ethApiSyncingError:  nil,
-			expectedStatusCode:  http.StatusInternalServerError,
+			expectedStatusCode:  http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				synced:           "DISABLED",
 				minPeerCount:     "DISABLED",
@@ -285,7 +298,7 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 			ethApiBlockError:    nil,
 			ethApiSyncingResult: false,
 			ethApiSyncingError:  nil,
-			expectedStatusCode:  http.StatusInternalServerError,
+			expectedStatusCode:  http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				synced:           "DISABLED",
 				minPeerCount:     "DISABLED",
@@ -304,12 +317,12 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 			ethApiBlockError:    nil,
 			ethApiSyncingResult: false,
 			ethApiSyncingError:  nil,
-			expectedStatusCode:  http.StatusInternalServerError,
+			expectedStatusCode:  http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				synced:           "DISABLED",
 				minPeerCount:     "DISABLED",
 				checkBlock:       "DISABLED",
-				maxSecondsBehind: "ERROR: bad header value",
+				maxSecondsBehind: "ERROR: bad check value",
 			},
 		},
 		// 14 - seconds check - badly formed request
@@ -321,7 +334,7 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 			ethApiBlockError:    nil,
 			ethApiSyncingResult: false,
 			ethApiSyncingError:  nil,
-			expectedStatusCode:  http.StatusInternalServerError,
+			expectedStatusCode:  http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				synced:           "DISABLED",
 				minPeerCount:     "DISABLED",
@@ -387,7 +400,7 @@ func TestProcessHealthcheckIfNeeded_HeadersTests(t *testing.T) {
 		apis[0] = netAPI
 		apis[1] = ethAPI
 
-		ProcessHealthcheckIfNeeded(w, r, apis)
+		ProcessHealthcheckIfNeeded(w, r, apis, nil)
 
 		result := w.Result()
 		if result.StatusCode != c.expectedStatusCode {
@@ -449,7 +462,7 @@ func TestProcessHealthcheckIfNeeded_RequestBody(t *testing.T) {
 			netApiError:        nil,
 			ethApiBlockResult:  map[string]interface{}{"test": struct{}{}},
 			ethApiBlockError:   nil,
-			expectedStatusCode: http.StatusInternalServerError,
+			expectedStatusCode: http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				"healthcheck_query": "ERROR:",
 				"min_peer_count":    "DISABLED",
@@ -463,7 +476,7 @@ func TestProcessHealthcheckIfNeeded_RequestBody(t *testing.T) {
 			netApiError:        errors.New("problem getting peers"),
 			ethApiBlockResult:  map[string]interface{}{"test": struct{}{}},
 			ethApiBlockError:   nil,
-			expectedStatusCode: http.StatusInternalServerError,
+			expectedStatusCode: http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				"healthcheck_query": "HEALTHY",
 				"min_peer_count":    "ERROR: problem getting peers",
@@ -477,7 +490,7 @@ func TestProcessHealthcheckIfNeeded_RequestBody(t *testing.T) {
 			netApiError:        nil,
 			ethApiBlockResult:  map[string]interface{}{"test": struct{}{}},
 			ethApiBlockError:   nil,
-			expectedStatusCode: http.StatusInternalServerError,
+			expectedStatusCode: http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				"healthcheck_query": "HEALTHY",
 				"min_peer_count":    "ERROR: not enough peers",
@@ -491,7 +504,7 @@ func TestProcessHealthcheckIfNeeded_RequestBody(t *testing.T) {
 			netApiError:        nil,
 			ethApiBlockResult:  map[string]interface{}{},
 			ethApiBlockError:   nil,
-			expectedStatusCode: http.StatusInternalServerError,
+			expectedStatusCode: http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				"healthcheck_query": "HEALTHY",
 				"min_peer_count":    "HEALTHY",
@@ -505,7 +518,7 @@ func TestProcessHealthcheckIfNeeded_RequestBody(t *testing.T) {
 			netApiError:        nil,
 			ethApiBlockResult:  map[string]interface{}{},
 			ethApiBlockError:   errors.New("problem getting block"),
-			expectedStatusCode: http.StatusInternalServerError,
+			expectedStatusCode: http.StatusServiceUnavailable,
 			expectedBody: map[string]string{
 				"healthcheck_query": "HEALTHY",
 				"min_peer_count":    "HEALTHY",
@@ -547,7 +560,7 @@ func TestProcessHealthcheckIfNeeded_RequestBody(t *testing.T) {
 		apis[0] = netAPI
 		apis[1] = ethAPI
 
-		ProcessHealthcheckIfNeeded(w, r, apis)
+		ProcessHealthcheckIfNeeded(w, r, apis, nil)
 
 		result := w.Result()
 		if result.StatusCode != c.expectedStatusCode {
@@ -577,3 +590,271 @@ func TestProcessHealthcheckIfNeeded_RequestBody(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessHealthcheckIfNeeded_Backend(t *testing.T) {
+	cases := []struct {
+		headers            []string
+		body               string
+		backendAPI         BackendAPI
+		expectedStatusCode int
+		expectedBody       map[string]string
+	}{
+		// 0 - in-process backend
+		{
+			headers:            []string{"min_peer_count1"},
+			backendAPI:         nil,
+			expectedStatusCode: http.StatusOK,
+			expectedBody: map[string]string{
+				minPeerCount: "HEALTHY",
+				backend:      "DISABLED",
+			},
+		},
+		// 1 - connected backend
+		{
+			headers:            []string{"min_peer_count1"},
+			backendAPI:         &backendApiStub{health: rpchelper.BackendHealth{Connected: true, Since: time.Now()}},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: map[string]string{
+				minPeerCount: "HEALTHY",
+				backend:      "HEALTHY",
+			},
+		},
+		// 2 - disconnected backend, checked with the headers
+		{
+			headers:            []string{"min_peer_count1"},
+			backendAPI:         &backendApiStub{health: rpchelper.BackendHealth{LastError: errors.New("connection refused"), Since: time.Now()}},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody: map[string]string{
+				minPeerCount: "HEALTHY",
+				backend:      "ERROR: execution backend unavailable since",
+			},
+		},
+		// 3 - disconnected backend, checked with the body
+		{
+			body:               "{\"min_peer_count\": 1}",
+			backendAPI:         &backendApiStub{health: rpchelper.BackendHealth{LastError: errors.New("connection refused"), Since: time.Now()}},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody: map[string]string{
+				"min_peer_count": "HEALTHY",
+				backend:          "connection refused",
+			},
+		},
+	}
+
+	for idx, c := range cases {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest(http.MethodGet, "http://localhost:9090/health", nil)
+		if err != nil {
+			t.Errorf("%v: creating request: %v", idx, err)
+		}
+		for _, header := range c.headers {
+			r.Header.Add("X-ERIGON-HEALTHCHECK", header)
+		}
+		r.Body = io.NopCloser(strings.NewReader(c.body))
+
+		apis := []rpc.API{{Service: &netApiStub{response: hexutil.Uint(1)}}}
+
+		ProcessHealthcheckIfNeeded(w, r, apis, c.backendAPI)
+
+		result := w.Result()
+		if result.StatusCode != c.expectedStatusCode {
+			t.Errorf("%v: expected status code: %v, but got: %v", idx, c.expectedStatusCode, result.StatusCode)
+		}
+
+		bodyBytes, err := io.ReadAll(result.Body)
+		if err != nil {
+			t.Errorf("%v: reading response body: %s", idx, err)
+		}
+
+		var body map[string]string
+		err = json.Unmarshal(bodyBytes, &body)
+		if err != nil {
+			t.Errorf("%v: unmarshalling the response body: %s", idx, err)
+		}
+		result.Body.Close()
+
+		for k, v := range c.expectedBody {
+			val, found := body[k]
+			if !found {
+				t.Errorf("%v: expected the key: %s to be in the response body but it wasn't there", idx, k)
+			}
+			if !strings.Contains(val, v) {
+				t.Errorf("%v: expected the response body key: %s to contain: %s, but it contained: %s", idx, k, v, val)
+			}
+		}
+	}
+}
+
+func TestProcessHealthcheckIfNeeded_Query(t *testing.T) {
+	recent := map[string]interface{}{"timestamp": hexutil.Uint64(time.Now().Add(-10 * time.Second).Unix())}
+	old := map[string]interface{}{"timestamp": hexutil.Uint64(time.Now().Add(-time.Hour).Unix())}
+	cases := []struct {
+		query              string
+		peers              hexutil.Uint
+		block              map[string]interface{}
+		syncing            interface{}
+		expectedStatusCode int
+		expectedBody       map[string]string
+	}{
+		// 0 - enough peers and recent head
+		{
+			query:              "min_peer_count=3&max_seconds_behind=60",
+			peers:              3,
+			block:              recent,
+			syncing:            false,
+			expectedStatusCode: http.StatusOK,
+			expectedBody: map[string]string{
+				synced:           "DISABLED",
+				minPeerCount:     "HEALTHY",
+				checkBlock:       "DISABLED",
+				maxSecondsBehind: "HEALTHY",
+				backend:          "DISABLED",
+			},
+		},
+		// 1 - not enough peers
+		{
+			query:              "min_peer_count=3&max_seconds_behind=60",
+			peers:              2,
+			block:              recent,
+			syncing:            false,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody: map[string]string{
+				minPeerCount:     "ERROR: not enough peers: 2 (minimum 3)",
+				maxSecondsBehind: "HEALTHY",
+			},
+		},
+		// 2 - head too old
+		{
+			query:              "min_peer_count=3&max_seconds_behind=60",
+			peers:              3,
+			block:              old,
+			syncing:            false,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody: map[string]string{
+				minPeerCount:     "HEALTHY",
+				maxSecondsBehind: "ERROR: timestamp too old",
+			},
+		},
+		// 3 - all checks, syncing
+		{
+			query:              "min_peer_count=1&max_seconds_behind=60&synced=true&check_block=10",
+			peers:              1,
+			block:              recent,
+			syncing:            struct{}{},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody: map[string]string{
+				synced:           "ERROR: not synced",
+				minPeerCount:     "HEALTHY",
+				checkBlock:       "HEALTHY",
+				maxSecondsBehind: "HEALTHY",
+			},
+		},
+		// 4 - sync check disabled explicitly
+		{
+			query:              "synced=false&min_peer_count=1",
+			peers:              1,
+			syncing:            struct{}{},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: map[string]string{
+				synced:       "DISABLED",
+				minPeerCount: "HEALTHY",
+			},
+		},
+		// 5 - badly formed values
+		{
+			query:              "min_peer_count=-1&synced=yes",
+			peers:              1,
+			syncing:            false,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody: map[string]string{
+				synced:       "ERROR: strconv.ParseBool: parsing \"yes\": invalid syntax",
+				minPeerCount: "ERROR: bad check value",
+			},
+		},
+	}
+
+	for idx, c := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "http://localhost:9090/health?"+c.query, nil)
+		apis := []rpc.API{
+			{Service: &netApiStub{response: c.peers}},
+			{Service: &ethApiStub{blockResult: c.block, syncingResult: c.syncing}},
+		}
+
+		ProcessHealthcheckIfNeeded(w, r, apis, nil)
+
+		result := w.Result()
+		if result.StatusCode != c.expectedStatusCode {
+			t.Errorf("%v: expected status code: %v, but got: %v", idx, c.expectedStatusCode, result.StatusCode)
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(result.Body).Decode(&body); err != nil {
+			t.Errorf("%v: unmarshalling the response body: %s", idx, err)
+		}
+		result.Body.Close()
+
+		for k, v := range c.expectedBody {
+			val, found := body[k]
+			if !found {
+				t.Errorf("%v: expected the key: %s to be in the response body but it wasn't there", idx, k)
+			}
+			if !strings.Contains(val, v) {
+				t.Errorf("%v: expected the response body key: %s to contain: %s, but it contained: %s", idx, k, v, val)
+			}
+		}
+	}
+}
+
+func TestGRPCServer(t *testing.T) {
+	recent := map[string]interface{}{"timestamp": hexutil.Uint64(time.Now().Add(-10 * time.Second).Unix())}
+	connected := &backendApiStub{health: rpchelper.BackendHealth{Connected: true, Since: time.Now()}}
+	disconnected := &backendApiStub{health: rpchelper.BackendHealth{LastError: errors.New("connection refused"), Since: time.Now()}}
+	cases := []struct {
+		service    string
+		peers      hexutil.Uint
+		backendAPI BackendAPI
+		expected   grpc_health_v1.HealthCheckResponse_ServingStatus
+		expectCode codes.Code
+	}{
+		// 0 - no checks, connected backend
+		{service: "", backendAPI: connected, expected: grpc_health_v1.HealthCheckResponse_SERVING},
+		// 1 - no checks, disconnected backend
+		{service: "", backendAPI: disconnected, expected: grpc_health_v1.HealthCheckResponse_NOT_SERVING},
+		// 2 - all checks pass
+		{service: "min_peer_count=2&max_seconds_behind=60&synced=true", peers: 2, backendAPI: connected, expected: grpc_health_v1.HealthCheckResponse_SERVING},
+		// 3 - not enough peers
+		{service: "min_peer_count=3&max_seconds_behind=60&synced=true", peers: 2, backendAPI: connected, expected: grpc_health_v1.HealthCheckResponse_NOT_SERVING},
+		// 4 - not a query string of the checks
+		{service: "erigon.rpcdaemon", expectCode: codes.NotFound},
+	}
+
+	for idx, c := range cases {
+		apis := []rpc.API{
+			{Service: &netApiStub{response: c.peers}},
+			{Service: &ethApiStub{blockResult: recent, syncingResult: false}},
+		}
+		server := NewGRPCServer(apis, c.backendAPI)
+		reply, err := server.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: c.service})
+		if c.expectCode != codes.OK {
+			if status.Code(err) != c.expectCode {
+				t.Errorf("%v: expected code: %v, but got: %v", idx, c.expectCode, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", idx, err)
+			continue
+		}
+		if reply.Status != c.expected {
+			t.Errorf("%v: expected status: %v, but got: %v", idx, c.expected, reply.Status)
+		}
+	}
+
+	server := NewGRPCServer(nil, nil)
+	server.Shutdown()
+	reply, err := server.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil || reply.Status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected NOT_SERVING after shutdown, but got: %v, %v", reply, err)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/erigontech/erigon-lib/common/hexutil"
 
 	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/rpc/rpchelper"
 )
 
 type NetAPI interface {
@@ -32,3 +33,8 @@ type EthAPI interface {
 	GetBlockByNumber(_ context.Context, number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error)
 	Syncing(ctx context.Context) (interface{}, error)
 }
+
+// BackendAPI reports the state of the connection to the execution backend of a standalone RPC daemon.
+type BackendAPI interface {
+	Health(ctx context.Context) rpchelper.BackendHealth
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/erigontech/erigon/cmd/rpcdaemon/cli"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/jsonrpc"
+	"github.com/erigontech/erigon/rpc/jsonrpc/receipts"
 	"github.com/erigontech/erigon/turbo/debug"
 
 	_ "github.com/erigontech/erigon-db/snaptype"      //hack
@@ -49,6 +50,7 @@ func main() {
 		}
 		defer db.Close()
 		defer engine.Close()
+		defer ff.Close()
 		if bridgeReader != nil {
 			defer bridgeReader.Close()
 		}
@@ -56,9 +58,21 @@ func main() {
 			defer heimdallReader.Close()
 		}
 
-		apiList := jsonrpc.APIList(db, backend, txPool, mining, ff, stateCache, blockReader, cfg, engine, logger, bridgeReader, heimdallReader)
+		var receiptsGenerator *receipts.Generator
+		if cfg.Sync.PersistentReceiptsCache.Enabled {
+			receiptsGenerator = receipts.NewGenerator(blockReader, engine, cfg.EvmCallTimeout, cfg.Sync.RPCReceiptsCache, "rpc")
+			// read-only: the cache is written by the erigon node owning the datadir
+			if receiptsCache, err := receipts.OpenPersistentCache(ctx, cfg.Dirs.DataDir, cfg.Sync.PersistentReceiptsCache, true /* readonly */, logger); err != nil {
+				logger.Warn("[rpc] receipts cache not available", "err", err)
+			} else {
+				defer receiptsCache.Close()
+				receiptsGenerator.SetPersistentCache(receiptsCache)
+			}
+		}
+
+		apiList := jsonrpc.APIList(db, backend, txPool, mining, ff, stateCache, blockReader, cfg, engine, logger, bridgeReader, heimdallReader, receiptsGenerator)
 		rpc.PreAllocateRPCMetricLabels(apiList)
-		if err := cli.StartRpcServer(ctx, cfg, apiList, logger); err != nil {
+		if err := cli.StartRpcServer(ctx, cfg, apiList, backend, logger); err != nil {
 			logger.Error(err.Error())
 			return nil
 		}
@@ -0,0 +1,139 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/erigontech/erigon/rpc/rpchelper"
+)
+
+const (
+	reconnectMinBackoff = 50 * time.Millisecond
+	reconnectMaxBackoff = 2 * time.Second
+	reconnectTimeout    = 10 * time.Second // how long a unary call waits for the backend to come back
+	healthCheckTimeout  = time.Second
+)
+
+// backendHealth tracks the connection to the backend from the outcome of the calls and streams.
+type backendHealth struct {
+	lock  sync.Mutex
+	state rpchelper.BackendHealth
+}
+
+func newBackendHealth() *backendHealth {
+	return &backendHealth{state: rpchelper.BackendHealth{Connected: true, Since: time.Now()}}
+}
+
+func (h *backendHealth) set(err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if connected := err == nil; connected != h.state.Connected {
+		h.state.Connected = connected
+		h.state.Since = time.Now()
+	}
+	if err != nil {
+		h.state.LastError = err
+	}
+}
+
+func (h *backendHealth) get() rpchelper.BackendHealth {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.state
+}
+
+func isUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// SetConnection gives the connection to the backend, to redial it right away when it is found unavailable
+// instead of waiting for the next attempt of the connection backoff.
+func (back *RemoteBackend) SetConnection(conn *grpc.ClientConn) {
+	back.conn = conn
+}
+
+// Health reports the state of the connection to the backend, checking it first.
+func (back *RemoteBackend) Health(ctx context.Context) rpchelper.BackendHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	_, err := back.remoteEthBackend.Version(ctx, &emptypb.Empty{})
+	back.health.set(err)
+	return back.health.get()
+}
+
+// observeStream records the outcome of a stream operation, and returns the error for the caller to tell if it is
+// worth resubscribing: the status of an unavailable backend is kept, see grpcutil.IsRetryLater.
+func (back *RemoteBackend) observeStream(err error) error {
+	if err == nil {
+		back.health.set(nil)
+		return nil
+	}
+	if isUnavailable(err) {
+		back.health.set(err)
+		back.redial()
+		return err
+	}
+	if s, ok := status.FromError(err); ok {
+		return errors.New(s.Message())
+	}
+	return err
+}
+
+func (back *RemoteBackend) redial() {
+	if back.conn != nil {
+		back.conn.ResetConnectBackoff()
+	}
+}
+
+// unaryCall runs call, retrying it with backoff while the backend is unavailable, e.g. restarting, for up to
+// reconnectTimeout. It then fails with an error wrapping rpchelper.ErrBackendUnavailable.
+func unaryCall[T any](ctx context.Context, back *RemoteBackend, call func(ctx context.Context) (T, error)) (T, error) {
+	backoff := reconnectMinBackoff
+	deadline := time.Now().Add(reconnectTimeout)
+	for {
+		res, err := call(ctx)
+		if !isUnavailable(err) {
+			back.health.set(nil) // any answer means the backend is there
+			if s, ok := status.FromError(err); ok && err != nil {
+				return res, errors.New(s.Message())
+			}
+			return res, err
+		}
+
+		back.health.set(err)
+		back.redial()
+		if time.Now().Add(backoff).After(deadline) {
+			return res, fmt.Errorf("%w: %s", rpchelper.ErrBackendUnavailable, status.Convert(err).Message())
+		}
+		select {
+		case <-ctx.Done():
+			return res, fmt.Errorf("%w: %w", rpchelper.ErrBackendUnavailable, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, reconnectMaxBackoff)
+	}
+}
@@ -22,10 +22,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"sync/atomic"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/erigontech/erigon-db/rawdb"
@@ -40,9 +38,11 @@ import (
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/p2p"
+	"github.com/erigontech/erigon/p2p/protocols/eth"
 	"github.com/erigontech/erigon/polygon/heimdall"
 	"github.com/erigontech/erigon/turbo/privateapi"
 	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/shards"
 	"github.com/erigontech/erigon/turbo/snapshotsync"
 )
 
@@ -50,6 +50,8 @@ var _ services.FullBlockReader = &RemoteBackend{}
 
 type RemoteBackend struct {
 	remoteEthBackend remote.ETHBACKENDClient
+	conn             *grpc.ClientConn // nil if the client isn't a remote one
+	health           *backendHealth
 	log              log.Logger
 	version          gointerfaces.Version
 	db               kv.RoDB
@@ -59,6 +61,7 @@ type RemoteBackend struct {
 func NewRemoteBackend(client remote.ETHBACKENDClient, db kv.RoDB, blockReader services.FullBlockReader) *RemoteBackend {
 	return &RemoteBackend{
 		remoteEthBackend: client,
+		health:           newBackendHealth(),
 		version:          gointerfaces.VersionFromProto(privateapi.EthBackendAPIVersion),
 		log:              log.New("remote_service", "eth_backend"),
 		db:               db,
@@ -150,11 +153,10 @@ func (back *RemoteBackend) EnsureVersionCompatibility() bool {
 }
 
 func (back *RemoteBackend) Etherbase(ctx context.Context) (common.Address, error) {
-	res, err := back.remoteEthBackend.Etherbase(ctx, &remote.EtherbaseRequest{})
+	res, err := unaryCall(ctx, back, func(ctx context.Context) (*remote.EtherbaseReply, error) {
+		return back.remoteEthBackend.Etherbase(ctx, &remote.EtherbaseRequest{})
+	})
 	if err != nil {
-		if s, ok := status.FromError(err); ok {
-			return common.Address{}, errors.New(s.Message())
-		}
 		return common.Address{}, err
 	}
 
@@ -162,11 +164,10 @@ func (back *RemoteBackend) Etherbase(ctx context.Context) (common.Address, error
 }
 
 func (back *RemoteBackend) Syncing(ctx context.Context) (*remote.SyncingReply, error) {
-	res, err := back.remoteEthBackend.Syncing(ctx, &emptypb.Empty{})
+	res, err := unaryCall(ctx, back, func(ctx context.Context) (*remote.SyncingReply, error) {
+		return back.remoteEthBackend.Syncing(ctx, &emptypb.Empty{})
+	})
 	if err != nil {
-		if s, ok := status.FromError(err); ok {
-			return nil, errors.New(s.Message())
-		}
 		return nil, err
 	}
 
@@ -174,11 +175,10 @@ func (back *RemoteBackend) Syncing(ctx context.Context) (*remote.SyncingReply, e
 }
 
 func (back *RemoteBackend) NetVersion(ctx context.Context) (uint64, error) {
-	res, err := back.remoteEthBackend.NetVersion(ctx, &remote.NetVersionRequest{})
+	res, err := unaryCall(ctx, back, func(ctx context.Context) (*remote.NetVersionReply, error) {
+		return back.remoteEthBackend.NetVersion(ctx, &remote.NetVersionRequest{})
+	})
 	if err != nil {
-		if s, ok := status.FromError(err); ok {
-			return 0, errors.New(s.Message())
-		}
 		return 0, err
 	}
 
@@ -186,11 +186,10 @@ func (back *RemoteBackend) NetVersion(ctx context.Context) (uint64, error) {
 }
 
 func (back *RemoteBackend) NetPeerCount(ctx context.Context) (uint64, error) {
-	res, err := back.remoteEthBackend.NetPeerCount(ctx, &remote.NetPeerCountRequest{})
+	res, err := unaryCall(ctx, back, func(ctx context.Context) (*remote.NetPeerCountReply, error) {
+		return back.remoteEthBackend.NetPeerCount(ctx, &remote.NetPeerCountRequest{})
+	})
 	if err != nil {
-		if s, ok := status.FromError(err); ok {
-			return 0, errors.New(s.Message())
-		}
 		return 0, err
 	}
 
@@ -198,7 +197,9 @@ func (back *RemoteBackend) NetPeerCount(ctx context.Context) (uint64, error) {
 }
 
 func (back *RemoteBackend) PendingBlock(ctx context.Context) (*types.Block, error) {
-	blockRlp, err := back.remoteEthBackend.PendingBlock(ctx, &emptypb.Empty{})
+	blockRlp, err := unaryCall(ctx, back, func(ctx context.Context) (*remote.PendingBlockReply, error) {
+		return back.remoteEthBackend.PendingBlock(ctx, &emptypb.Empty{})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("ETHBACKENDClient.PendingBlock() error: %w", err)
 	}
@@ -216,11 +217,10 @@ func (back *RemoteBackend) PendingBlock(ctx context.Context) (*types.Block, erro
 }
 
 func (back *RemoteBackend) ProtocolVersion(ctx context.Context) (uint64, error) {
-	res, err := back.remoteEthBackend.ProtocolVersion(ctx, &remote.ProtocolVersionRequest{})
+	res, err := unaryCall(ctx, back, func(ctx context.Context) (*remote.ProtocolVersionReply, error) {
+		return back.remoteEthBackend.ProtocolVersion(ctx, &remote.ProtocolVersionRequest{})
+	})
 	if err != nil {
-		if s, ok := status.FromError(err); ok {
-			return 0, errors.New(s.Message())
-		}
 		return 0, err
 	}
 
@@ -228,11 +228,10 @@ func (back *RemoteBackend) ProtocolVersion(ctx context.Context) (uint64, error)
 }
 
 func (back *RemoteBackend) ClientVersion(ctx context.Context) (string, error) {
-	res, err := back.remoteEthBackend.ClientVersion(ctx, &remote.ClientVersionRequest{})
+	res, err := unaryCall(ctx, back, func(ctx context.Context) (*remote.ClientVersionReply, error) {
+		return back.remoteEthBackend.ClientVersion(ctx, &remote.ClientVersionRequest{})
+	})
 	if err != nil {
-		if s, ok := status.FromError(err); ok {
-			return "", errors.New(s.Message())
-		}
 		return "", err
 	}
 
@@ -242,10 +241,7 @@ func (back *RemoteBackend) ClientVersion(ctx context.Context) (string, error) {
 func (back *RemoteBackend) Subscribe(ctx context.Context, onNewEvent func(*remote.SubscribeReply)) error {
 	subscription, err := back.remoteEthBackend.Subscribe(ctx, &remote.SubscribeRequest{}, grpc.WaitForReady(true))
 	if err != nil {
-		if s, ok := status.FromError(err); ok {
-			return errors.New(s.Message())
-		}
-		return err
+		return back.observeStream(err)
 	}
 	for {
 		event, err := subscription.Recv()
@@ -254,23 +250,81 @@ func (back *RemoteBackend) Subscribe(ctx context.Context, onNewEvent func(*remot
 			break
 		}
 		if err != nil {
-			return err
+			return back.observeStream(err)
 		}
+		back.observeStream(nil)
 
 		onNewEvent(event)
 	}
 	return nil
 }
 
-func (back *RemoteBackend) SubscribeLogs(ctx context.Context, onNewLogs func(reply *remote.SubscribeLogsReply), requestor *atomic.Value) error {
-	subscription, err := back.remoteEthBackend.SubscribeLogs(ctx, grpc.WaitForReady(true))
+func (back *RemoteBackend) SubscribeBlocks(ctx context.Context, onNewBlock func(*shards.NewBlock)) error {
+	subscription, err := back.remoteEthBackend.SubscribeBlocks(ctx, &remote.SubscribeRequest{}, grpc.WaitForReady(true))
+	if err != nil {
+		back.observeStream(err)
+		return err // keeps the status code, e.g. for the filters to tell that the node doesn't support it
+	}
+	for {
+		event, err := subscription.Recv()
+		if errors.Is(err, io.EOF) {
+			log.Debug("rpcdaemon: the blocks subscription channel was closed")
+			break
+		}
+		if err != nil {
+			back.observeStream(err)
+			return err
+		}
+		back.observeStream(nil)
+
+		block := &shards.NewBlock{
+			HeaderRlp: event.HeaderRlp,
+			BodyRlp:   event.BodyRlp,
+			NewHead:   gointerfaces.ConvertH256ToHash(event.NewHead),
+		}
+		if event.OldHead != nil {
+			block.OldHead = gointerfaces.ConvertH256ToHash(event.OldHead)
+		}
+		if event.CommonAncestor != nil {
+			block.CommonAncestor = gointerfaces.ConvertH256ToHash(event.CommonAncestor)
+		}
+		onNewBlock(block)
+	}
+	return nil
+}
+
+func (back *RemoteBackend) SubscribePeerEvents(ctx context.Context, onPeerEvent func(*shards.PeerEvent)) error {
+	subscription, err := back.remoteEthBackend.SubscribePeerEvents(ctx, &remote.SubscribeRequest{}, grpc.WaitForReady(true))
 	if err != nil {
-		if s, ok := status.FromError(err); ok {
-			return errors.New(s.Message())
+		return err // keeps the status code, e.g. for the filters to tell that the node doesn't support it
+	}
+	for {
+		event, err := subscription.Recv()
+		if errors.Is(err, io.EOF) {
+			log.Debug("rpcdaemon: the peer events subscription channel was closed")
+			break
+		}
+		if err != nil {
+			return err
 		}
-		return err
+
+		onPeerEvent(&shards.PeerEvent{
+			EventId: uint64(event.EventId),
+			PeerId:  gointerfaces.ConvertH512ToHash(event.PeerId),
+			Enode:   event.Enode,
+			Name:    event.Name,
+			Caps:    event.Caps,
+		})
+	}
+	return nil
+}
+
+func (back *RemoteBackend) SubscribeLogs(ctx context.Context, onNewLogs func(reply *remote.SubscribeLogsReply), requestor func(send func(*remote.LogsFilterRequest) error)) error {
+	subscription, err := back.remoteEthBackend.SubscribeLogs(ctx, grpc.WaitForReady(true))
+	if err != nil {
+		return back.observeStream(err)
 	}
-	requestor.Store(subscription.Send)
+	requestor(subscription.Send)
 	for {
 		logs, err := subscription.Recv()
 		if errors.Is(err, io.EOF) {
@@ -278,8 +332,9 @@ func (back *RemoteBackend) SubscribeLogs(ctx context.Context, onNewLogs func(rep
 			break
 		}
 		if err != nil {
-			return err
+			return back.observeStream(err)
 		}
+		back.observeStream(nil)
 		onNewLogs(logs)
 	}
 	return nil
@@ -308,12 +363,18 @@ func (back *RemoteBackend) BodyWithTransactions(ctx context.Context, tx kv.Gette
 func (back *RemoteBackend) BodyRlp(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (bodyRlp rlp.RawValue, err error) {
 	return back.blockReader.BodyRlp(ctx, tx, hash, blockNum)
 }
+func (back *RemoteBackend) FrozenBodiesRlp(ctx context.Context, from uint64, count int) ([]rlp.RawValue, error) {
+	return back.blockReader.FrozenBodiesRlp(ctx, from, count)
+}
 func (back *RemoteBackend) Body(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (body *types.Body, txCount uint32, err error) {
 	return back.blockReader.Body(ctx, tx, hash, blockNum)
 }
 func (back *RemoteBackend) Header(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (*types.Header, error) {
 	return back.blockReader.Header(ctx, tx, hash, blockNum)
 }
+func (back *RemoteBackend) HeaderRLP(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (rlp.RawValue, error) {
+	return back.blockReader.HeaderRLP(ctx, tx, hash, blockNum)
+}
 func (back *RemoteBackend) HeaderByNumber(ctx context.Context, tx kv.Getter, blockNum uint64) (*types.Header, error) {
 	return back.blockReader.HeaderByNumber(ctx, tx, blockNum)
 }
@@ -382,7 +443,9 @@ func (back *RemoteBackend) LastFrozenSpanId() uint64 {
 }
 
 func (back *RemoteBackend) NodeInfo(ctx context.Context, limit uint32) ([]p2p.NodeInfo, error) {
-	nodes, err := back.remoteEthBackend.NodeInfo(ctx, &remote.NodesInfoRequest{Limit: limit})
+	nodes, err := unaryCall(ctx, back, func(ctx context.Context) (*remote.NodesInfoReply, error) {
+		return back.remoteEthBackend.NodeInfo(ctx, &remote.NodesInfoRequest{Limit: limit})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("nodes info request error: %w", err)
 	}
@@ -425,7 +488,9 @@ func (back *RemoteBackend) NodeInfo(ctx context.Context, limit uint32) ([]p2p.No
 }
 
 func (back *RemoteBackend) AddPeer(ctx context.Context, request *remote.AddPeerRequest) (*remote.AddPeerReply, error) {
-	result, err := back.remoteEthBackend.AddPeer(ctx, request)
+	result, err := unaryCall(ctx, back, func(ctx context.Context) (*remote.AddPeerReply, error) {
+		return back.remoteEthBackend.AddPeer(ctx, request)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("ETHBACKENDClient.AddPeer() error: %w", err)
 	}
@@ -433,12 +498,14 @@ func (back *RemoteBackend) AddPeer(ctx context.Context, request *remote.AddPeerR
 }
 
 func (back *RemoteBackend) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) {
-	rpcPeers, err := back.remoteEthBackend.Peers(ctx, &emptypb.Empty{})
+	rpcPeers, err := unaryCall(ctx, back, func(ctx context.Context) (*remote.PeersReply, error) {
+		return back.remoteEthBackend.Peers(ctx, &emptypb.Empty{})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("ETHBACKENDClient.Peers() error: %w", err)
 	}
 
-	peers := make([]*p2p.PeerInfo, 0, len(rpcPeers.Peers))
+	peers := make([]*p2p.PeerInfo, 0, len(rpcPeers.Peers)+len(rpcPeers.Errors))
 
 	for _, rpcPeer := range rpcPeers.Peers {
 		peer := p2p.PeerInfo{
@@ -460,11 +527,30 @@ func (back *RemoteBackend) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) {
 				Trusted:       rpcPeer.ConnIsTrusted,
 				Static:        rpcPeer.ConnIsStatic,
 			},
-			Protocols: nil,
+			Protocols: map[string]interface{}{},
+		}
+		if rpcPeer.HeadHash != nil || len(rpcPeer.ForkHash) > 0 {
+			ethInfo := &eth.PeerInfo{
+				ForkHash:            rpcPeer.ForkHash,
+				ForkNext:            rpcPeer.ForkNext,
+				ForkIncompatibility: rpcPeer.ForkIncompatibility,
+			}
+			if rpcPeer.HeadHash != nil {
+				head := gointerfaces.ConvertH256ToHash(rpcPeer.HeadHash)
+				ethInfo.Head = &head
+			}
+			if rpcPeer.TotalDifficulty != nil {
+				ethInfo.Difficulty = gointerfaces.ConvertH256ToUint256Int(rpcPeer.TotalDifficulty).ToBig()
+			}
+			peer.Protocols[eth.ProtocolName] = ethInfo
 		}
 
 		peers = append(peers, &peer)
 	}
+	// the sentries which couldn't list their peers are reported after the peers of the others
+	for _, e := range rpcPeers.Errors {
+		peers = append(peers, &p2p.PeerInfo{Error: e})
+	}
 
 	return peers, nil
 }
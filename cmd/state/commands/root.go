@@ -79,6 +79,9 @@ func genesisFromFile(genesisPath string) *types.Genesis {
 	if err := json.NewDecoder(file).Decode(genesis); err != nil {
 		utils.Fatalf("invalid genesis file: %v", err)
 	}
+	if err := types.ResolveAllocFile(genesis, genesisPath); err != nil {
+		utils.Fatalf("invalid genesis file: %v", err)
+	}
 	return genesis
 }
 
@@ -123,6 +123,10 @@ var (
 		Name:  "override.osaka",
 		Usage: "Manually specify the Osaka fork time, overriding the bundled setting",
 	}
+	OverrideChainConfigFlag = cli.StringFlag{
+		Name:  "override.chainconfig",
+		Usage: "Path of a JSON file overriding the fork blocks and times, and the blobSchedule, of the chain config. Stored in the db and applied on the next starts, {} removes it",
+	}
 	TrustedSetupFile = cli.StringFlag{
 		Name:  "trusted-setup-file",
 		Usage: "Absolute path to trusted_setup.json file",
@@ -350,6 +354,16 @@ var (
 		Usage: "Enable compression over WebSocket (enabled by default in case WS-RPC is enabled). Use --ws.enabled=false to disable it",
 		Value: true,
 	}
+	WsCompressionLevelFlag = cli.IntFlag{
+		Name:  "ws.compression.level",
+		Usage: "Flate compression level of the WebSocket messages, from -2 (huffman only) to 9 (best compression)",
+		Value: 1,
+	}
+	WsCompressionMinSizeFlag = cli.IntFlag{
+		Name:  "ws.compression.minsize",
+		Usage: "Size in bytes under which the WebSocket messages are sent uncompressed",
+		Value: 1024,
+	}
 	HTTPCORSDomainFlag = cli.StringFlag{
 		Name:  "http.corsdomain",
 		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
@@ -372,18 +386,37 @@ var (
 	}
 	RpcBatchConcurrencyFlag = cli.UintFlag{
 		Name:  "rpc.batch.concurrency",
-		Usage: "Does limit amount of goroutines to process 1 batch request. Means 1 bach request can't overload server. 1 batch still can have unlimited amount of request",
+		Usage: "Does limit amount of goroutines to process the batch requests of all the connections, whose requests are interleaved. Means batch requests can't overload server nor delay the single requests. Also limits the amount of blocks processed concurrently by 1 eth_getLogs request",
 		Value: 2,
 	}
 	RpcStreamingDisableFlag = cli.BoolFlag{
 		Name:  "rpc.streaming.disable",
 		Usage: "Erigon has enabled json streaming for some heavy endpoints (like trace_*). It's a trade-off: greatly reduce amount of RAM (in some cases from 30GB to 30mb), but it produce invalid json format if error happened in the middle of streaming (because json is not streaming-friendly format)",
 	}
+	RpcTxReuseFlag = cli.StringFlag{
+		Name:  "rpc.txreuse",
+		Usage: "Read transaction shared by requests, so that they observe the same state of the chain: 'batch' for the items of a batch, executed one after another then, 'connection' for the requests of a websocket or IPC connection, or 'off'",
+		Value: "off",
+	}
+	RpcTxReuseMaxAgeFlag = cli.DurationFlag{
+		Name:  "rpc.txreuse.maxage",
+		Usage: "Age from which a read transaction shared by requests (see --rpc.txreuse) is refreshed, the requests served after it observing the latest state of the chain (0 = never)",
+		Value: 10 * time.Second,
+	}
+	RpcKvStatsFlag = cli.BoolFlag{
+		Name:  "rpc.kvstats",
+		Usage: "Count the reads of the database by the requests per table and method, exported as metrics and reported at /debug/kv/tables on the metrics server",
+	}
 	RpcBatchLimit = cli.IntFlag{
 		Name:  "rpc.batch.limit",
-		Usage: "Maximum number of requests in a batch",
+		Usage: "Maximum number of requests in a batch, the ones beyond it are answered with a -32005 error",
 		Value: 100,
 	}
+	RpcBatchGasLimit = cli.Uint64Flag{
+		Name:  "rpc.batch.gaslimit",
+		Usage: "Maximum cumulative gas of the eth_call-like requests in a batch, counting --rpc.gascap for the ones not setting it. The ones beyond it are answered with a -32005 error. 0 = no limit",
+		Value: 0,
+	}
 	RpcReturnDataLimit = cli.IntFlag{
 		Name:  "rpc.returndata.limit",
 		Usage: "Maximum number of bytes returned from eth_call or similar invocations",
@@ -404,7 +437,7 @@ var (
 	}
 	RpcAccessListFlag = cli.StringFlag{
 		Name:  "rpc.accessList",
-		Usage: "Specify granular (method-by-method) API allowlist",
+		Usage: "Specify granular (method-by-method) API allowlist and denylist: a JSON file {\"allow\": [...], \"deny\": [...]}, reloaded on SIGHUP",
 	}
 
 	RpcGasCapFlag = cli.UintFlag{
@@ -427,6 +460,11 @@ var (
 		Usage: "Sets a limit on traces that can be returned in trace_filter",
 		Value: 200,
 	}
+	TraceFilterMaxBytesFlag = cli.Uint64Flag{
+		Name:  "trace.filter.maxbytes",
+		Usage: "Sets a limit on the size of the traces returned by trace_filter, which ends its array with an error object once exceeded (0 = no limit)",
+		Value: 1 << 30,
+	}
 
 	HTTPPathPrefixFlag = cli.StringFlag{
 		Name:  "http.rpcprefix",
@@ -1008,7 +1046,7 @@ var (
 	}
 	RPCSlowFlag = cli.DurationFlag{
 		Name:  "rpc.slow",
-		Usage: "Print in logs RPC requests slower than given threshold: 100ms, 1s, 1m. Exluded methods: " + strings.Join(rpccfg.SlowLogBlackList, ","),
+		Usage: "Print in logs RPC requests slower than given threshold: 100ms, 1s, 1m, the slowest ones are also reported at /debug/rpc/stats on the metrics server. Exluded methods: " + strings.Join(rpccfg.SlowLogBlackList, ","),
 		Value: 0,
 	}
 	CaplinArchiveBlocksFlag = cli.BoolFlag{
@@ -1117,6 +1155,18 @@ var (
 		Name:  "polygon.pos.ssf.block",
 		Usage: "Enabling Polygon PoS Single Slot Finality since block",
 	}
+	PolygonBridgeHealEventsGapFlag = cli.BoolFlag{
+		Name:  "polygon.bridge.heal-events-gap",
+		Usage: "Fetch again the bridge events missing between the frozen files and the db, rather than refusing to start",
+	}
+	PolygonBridgeEventsRetentionBlocksFlag = cli.Uint64Flag{
+		Name:  "polygon.bridge.events-retention.blocks",
+		Usage: "Keep the bridge events of the last blocks in the db once frozen, rather than pruning them (0 = prune all frozen events)",
+	}
+	PolygonBridgeEventsRetentionDurationFlag = cli.DurationFlag{
+		Name:  "polygon.bridge.events-retention.duration",
+		Usage: "Keep the bridge events of the blocks of the last duration (e.g. 2160h for 90 days) in the db once frozen, rather than pruning them (0 = prune all frozen events)",
+	}
 	ExperimentalConcurrentCommitmentFlag = cli.BoolFlag{
 		Name:  "experimental.concurrent-commitment",
 		Usage: "EXPERIMENTAL: enables concurrent trie for commitment",
@@ -1201,7 +1251,7 @@ func GetBootnodesFromFlags(urlsStr, chain string) ([]*enode.Node, error) {
 	if urlsStr != "" {
 		urls = common.CliString2Array(urlsStr)
 	} else {
-		urls = chainspec.BootnodeURLsOfChain(chain)
+		urls = chainspec.BootnodesByChainName(chain)
 	}
 	return enode.ParseNodesFromURLs(urls)
 }
@@ -1721,6 +1771,9 @@ func setBorConfig(ctx *cli.Context, cfg *ethconfig.Config, nodeConfig *nodecfg.C
 
 	cfg.PolygonPosSingleSlotFinality = ctx.Bool(PolygonPosSingleSlotFinalityFlag.Name)
 	cfg.PolygonPosSingleSlotFinalityBlockAt = ctx.Uint64(PolygonPosSingleSlotFinalityBlockAtFlag.Name)
+	cfg.PolygonBridgeHealEventsGap = ctx.Bool(PolygonBridgeHealEventsGapFlag.Name)
+	cfg.PolygonBridgeEventsRetentionBlocks = ctx.Uint64(PolygonBridgeEventsRetentionBlocksFlag.Name)
+	cfg.PolygonBridgeEventsRetentionDuration = ctx.Duration(PolygonBridgeEventsRetentionDurationFlag.Name)
 }
 
 func setMiner(ctx *cli.Context, cfg *params2.MiningConfig) {
@@ -2042,6 +2095,9 @@ func SetEthConfig(ctx *cli.Context, nodeConfig *nodecfg.Config, cfg *ethconfig.C
 	if ctx.IsSet(OverrideOsakaFlag.Name) {
 		cfg.OverrideOsakaTime = flags.GlobalBig(ctx, OverrideOsakaFlag.Name)
 	}
+	if ctx.IsSet(OverrideChainConfigFlag.Name) {
+		cfg.OverrideChainConfig = ctx.String(OverrideChainConfigFlag.Name)
+	}
 
 	if clparams.EmbeddedSupported(cfg.NetworkID) || cfg.CaplinConfig.IsDevnet() {
 		cfg.InternalCL = !ctx.Bool(ExternalConsensusFlag.Name)
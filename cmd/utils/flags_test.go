@@ -23,6 +23,12 @@ package utils
 import (
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon/execution/chainspec"
+	_ "github.com/erigontech/erigon/polygon/chain" // registers the polygon networks
 )
 
 func Test_SplitTagsFlag(t *testing.T) {
@@ -65,3 +71,14 @@ func Test_SplitTagsFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBootnodesFromFlags(t *testing.T) {
+	for _, network := range networkname.All {
+		nodes, err := GetBootnodesFromFlags("", network)
+		require.NoError(t, err, network)
+		require.Len(t, nodes, len(chainspec.BootnodesByChainName(network)), network)
+	}
+
+	_, err := GetBootnodesFromFlags("enode://nope", networkname.Mainnet)
+	require.ErrorContains(t, err, "invalid node URL")
+}
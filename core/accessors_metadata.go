@@ -99,6 +99,8 @@ func WriteGenesisIfNotExist(db kv.RwTx, g *types.Genesis) error {
 	return db.Put(kv.ConfigTable, kv.GenesisKey, val)
 }
 
+// ReadGenesis returns the genesis specification stored by WriteGenesisIfNotExist, if any.
+// It is nil for nodes which were started without a custom genesis.
 func ReadGenesis(db kv.Getter) (*types.Genesis, error) {
 	val, err := db.GetOne(kv.ConfigTable, kv.GenesisKey)
 	if err != nil {
@@ -114,6 +116,26 @@ func ReadGenesis(db kv.Getter) (*types.Genesis, error) {
 	return &g, nil
 }
 
+// WriteConfigOverride stores the chain config override applied by WriteGenesisBlock, an empty one removes it.
+func WriteConfigOverride(db kv.Putter, o *ConfigOverride) error {
+	if o.Empty() {
+		return db.Delete(kv.ConfigTable, kv.ConfigOverrideKey)
+	}
+	return db.Put(kv.ConfigTable, kv.ConfigOverrideKey, o.raw)
+}
+
+// ReadConfigOverride returns the chain config override stored by WriteConfigOverride, nil if there is none.
+func ReadConfigOverride(db kv.Getter) (*ConfigOverride, error) {
+	val, err := db.GetOne(kv.ConfigTable, kv.ConfigOverrideKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(val) == 0 {
+		return nil, nil
+	}
+	return ParseConfigOverride(val)
+}
+
 func AllSegmentsDownloadComplete(tx kv.Getter) (allSegmentsDownloadComplete bool, err error) {
 	snapshotsStageProgress, err := stages.GetStageProgress(tx, stages.Snapshots)
 	return snapshotsStageProgress > 0, err
@@ -316,12 +316,12 @@ func SysCallContractWithBlockContext(contract common.Address, data []byte, chain
 }
 
 // SysCreate is a special (system) contract creation methods for genesis constructors.
-func SysCreate(contract common.Address, data []byte, chainConfig *chain.Config, ibs *state.IntraBlockState, header *types.Header) (result []byte, err error) {
+func SysCreate(contract common.Address, data []byte, gas uint64, chainConfig *chain.Config, ibs *state.IntraBlockState, header *types.Header) (result []byte, err error) {
 	msg := types.NewMessage(
 		contract,
 		nil, // to
 		0, u256.Num0,
-		SysCallGasLimit,
+		gas,
 		u256.Num0,
 		nil, nil,
 		data, nil, false,
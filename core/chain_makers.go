@@ -417,6 +417,59 @@ func GenerateChain(config *chain.Config, parent *types.Block, engine consensus.E
 	return &ChainPack{Headers: headers, Blocks: blocks, Receipts: receipts, TopBlock: blocks[n-1]}, nil
 }
 
+// GenerateChainWithFork creates two branches from parent sharing their first commonLen blocks, then forking for
+// forkALen and forkBLen blocks, branch B winning the fork choice: inserting A then B reorgs to B. Both branches are
+// returned with the common blocks, see ChainPack.Slice to get the fork alone.
+//
+// genA generates the common blocks and those of branch A, genB those of branch B, both called with the index of the
+// block in its branch. Before genB runs, the time of the blocks of branch B is offset: to 1 second after their parent
+// for proof of work, increasing their difficulty, and to 11 seconds for proof of stake, a later timestamp than those
+// of branch A. With proof of work it's an error if branch B still doesn't have a higher total difficulty than branch A,
+// forkBLen should be at least forkALen.
+//
+// The generation is deterministic: as GenerateChain, the blocks only depend on the parent, the state of db and the
+// generators, so the common blocks of both branches are identical, and calling GenerateChainWithFork again with the
+// same arguments returns the same blocks, as long as the generators are deterministic themselves.
+func GenerateChainWithFork(config *chain.Config, parent *types.Block, engine consensus.Engine, db kv.TemporalRwDB, commonLen, forkALen, forkBLen int, genA, genB func(int, *BlockGen)) (a *ChainPack, b *ChainPack, err error) {
+	if forkALen < 1 || forkBLen < 1 {
+		return nil, nil, fmt.Errorf("both branches must fork: got %d and %d blocks", forkALen, forkBLen)
+	}
+	a, err = GenerateChain(config, parent, engine, db, commonLen+forkALen, genA)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating branch A: %w", err)
+	}
+	b, err = GenerateChain(config, parent, engine, db, commonLen+forkBLen, func(i int, block *BlockGen) {
+		if i < commonLen {
+			if genA != nil {
+				genA(i, block)
+			}
+			return
+		}
+		if block.header.Difficulty.Sign() == 0 {
+			block.OffsetTime(1)
+		} else {
+			block.OffsetTime(-9)
+		}
+		if genB != nil {
+			genB(i, block)
+		}
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating branch B: %w", err)
+	}
+	tdA, tdB := new(big.Int), new(big.Int)
+	for _, header := range a.Headers[commonLen:] {
+		tdA.Add(tdA, header.Difficulty)
+	}
+	for _, header := range b.Headers[commonLen:] {
+		tdB.Add(tdB, header.Difficulty)
+	}
+	if tdA.Sign() != 0 && tdB.Cmp(tdA) <= 0 {
+		return nil, nil, fmt.Errorf("branch B doesn't have a higher total difficulty than branch A: %d <= %d", tdB, tdA)
+	}
+	return a, b, nil
+}
+
 func MakeEmptyHeader(parent *types.Header, chainConfig *chain.Config, timestamp uint64, targetGasLimit *uint64) *types.Header {
 	header := types.NewEmptyHeaderForAssembling()
 	header.Root = parent.Root
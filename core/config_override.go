@@ -0,0 +1,150 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/jinzhu/copier"
+
+	"github.com/erigontech/erigon-db/rawdb"
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/types"
+)
+
+// ConfigOverride is a JSON fragment of chain.Config rescheduling the forks of a chain without a new release: only the
+// fork blocks and times, and the blob schedule, can be overridden. It's stored in the db by WriteGenesisBlock, which
+// applies the stored one on the next starts, until another override replaces it: {} removes it.
+type ConfigOverride struct {
+	raw    json.RawMessage
+	fields int
+}
+
+// ParseConfigOverride parses the JSON fragment of a ConfigOverride, rejecting the unknown fields and the fields which
+// aren't fork blocks or times, or the blob schedule.
+func ParseConfigOverride(data []byte) (*ConfigOverride, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("invalid chain config override: %w", err)
+	}
+	for _, field := range common.SortedKeys(fields) {
+		if !strings.HasSuffix(field, "Block") && !strings.HasSuffix(field, "Time") && field != "blobSchedule" {
+			return nil, fmt.Errorf("invalid chain config override: %s can't be overridden, only the fork blocks and times, and blobSchedule", field)
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&chain.Config{}); err != nil {
+		return nil, fmt.Errorf("invalid chain config override: %w", err)
+	}
+	return &ConfigOverride{raw: common.CopyBytes(data), fields: len(fields)}, nil
+}
+
+// Empty reports whether the override overrides no field, it removes the stored override.
+func (o *ConfigOverride) Empty() bool { return o == nil || o.fields == 0 }
+
+// ReadConfigOverrideFile parses the ConfigOverride of the file at path.
+func ReadConfigOverrideFile(path string) (*ConfigOverride, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read chain config override: %w", err)
+	}
+	return ParseConfigOverride(data)
+}
+
+// Apply overrides the fields of config, if the result doesn't fail the config checks of ValidateGenesis the config
+// passed: the order of the forks, and the blob schedule entries of scheduled forks. config is left as is otherwise.
+func (o *ConfigOverride) Apply(config *chain.Config) error {
+	var overridden chain.Config
+	if err := copier.Copy(&overridden, config); err != nil {
+		return err
+	}
+	overridden.BlobSchedule = maps.Clone(config.BlobSchedule)
+	if err := json.Unmarshal(o.raw, &overridden); err != nil {
+		return fmt.Errorf("invalid chain config override: %w", err)
+	}
+	passed := make(map[GenesisProblem]struct{})
+	for _, p := range ValidateGenesis(&types.Genesis{Config: config}) {
+		passed[p] = struct{}{}
+	}
+	var errs []error
+	for _, p := range ValidateGenesis(&types.Genesis{Config: &overridden}) {
+		if _, ok := passed[p]; !ok {
+			errs = append(errs, p)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid chain config override: %w", errors.Join(errs...))
+	}
+	// the blob schedule may be shared with the embedded spec of the chain
+	config.BlobSchedule = maps.Clone(config.BlobSchedule)
+	return json.Unmarshal(o.raw, config)
+}
+
+// checkConfigOverrideCompatible returns an error if config, overridden, reschedules a fork the chain is already past:
+// its head, at height, was processed with the rules of the stored config.
+func checkConfigOverrideCompatible(tx kv.Getter, stored, config *chain.Config, height uint64) error {
+	if compatErr := stored.CheckCompatible(config, height); compatErr != nil {
+		return fmt.Errorf("chain config override conflicts with the synced chain: %s changed from %v to %v, the head block %d is past it",
+			compatErr.What, compatErr.StoredConfig, compatErr.NewConfig, height)
+	}
+	head := rawdb.ReadHeaderByNumber(tx, height)
+	if head == nil {
+		return nil
+	}
+	forked := func(forkTime *big.Int) bool { return forkTime != nil && forkTime.Uint64() <= head.Time }
+	for _, fork := range []struct {
+		name             string
+		stored, override *big.Int
+	}{
+		{"shanghaiTime", stored.ShanghaiTime, config.ShanghaiTime},
+		{"cancunTime", stored.CancunTime, config.CancunTime},
+		{"pragueTime", stored.PragueTime, config.PragueTime},
+		{"osakaTime", stored.OsakaTime, config.OsakaTime},
+		{"bpo1Time", stored.Bpo1Time, config.Bpo1Time},
+		{"bpo2Time", stored.Bpo2Time, config.Bpo2Time},
+		{"bpo3Time", stored.Bpo3Time, config.Bpo3Time},
+		{"bpo4Time", stored.Bpo4Time, config.Bpo4Time},
+		{"bpo5Time", stored.Bpo5Time, config.Bpo5Time},
+	} {
+		if (forked(fork.stored) || forked(fork.override)) && !numEqual(fork.stored, fork.override) {
+			return fmt.Errorf("chain config override conflicts with the synced chain: %s changed from %v to %v, the head block %d at time %d is past it",
+				fork.name, fork.stored, fork.override, height, head.Time)
+		}
+	}
+	if !reflect.DeepEqual(stored.GetBlobConfig(head.Time), config.GetBlobConfig(head.Time)) {
+		return fmt.Errorf("chain config override conflicts with the synced chain: the blob schedule of the head block %d at time %d changed", height, head.Time)
+	}
+	return nil
+}
+
+func numEqual(x, y *big.Int) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	return x.Cmp(y) == 0
+}
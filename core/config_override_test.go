@@ -0,0 +1,102 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-db/rawdb"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core"
+)
+
+func mustParseConfigOverride(t *testing.T, data string) *core.ConfigOverride {
+	t.Helper()
+	override, err := core.ParseConfigOverride([]byte(data))
+	require.NoError(t, err)
+	return override
+}
+
+func TestWriteGenesisBlockConfigOverride(t *testing.T) {
+	t.Parallel()
+	ctx, logger := context.Background(), log.New()
+	dirs := datadir.New(t.TempDir())
+	tx, err := temporaltest.NewTestDB(t, dirs).BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	genesis := func() *types.Genesis { return core.DevnetGenesisBlock(1, "dev", 30_000_000) }
+	require.Nil(t, genesis().Config.OsakaTime)
+
+	config, block, err := core.WriteGenesisBlock(ctx, tx, genesis(), nil, mustParseConfigOverride(t, `{"osakaTime": 1000}`), dirs, logger)
+	require.NoError(t, err)
+	require.False(t, config.Rules(1, 999).IsOsaka)
+	require.True(t, config.Rules(1, 1000).IsOsaka)
+
+	// the stored override applies on the next starts
+	config, _, err = core.WriteGenesisBlock(ctx, tx, genesis(), nil, nil, dirs, logger)
+	require.NoError(t, err)
+	require.False(t, config.IsOsaka(999))
+	require.True(t, config.IsOsaka(1000))
+
+	head := &types.Header{ParentHash: block.Hash(), Number: big.NewInt(1), Time: 1500, Difficulty: big.NewInt(0), GasLimit: 30_000_000}
+	require.NoError(t, rawdb.WriteHeader(tx, head))
+	require.NoError(t, rawdb.WriteCanonicalHash(tx, head.Hash(), 1))
+	require.NoError(t, rawdb.WriteHeadHeaderHash(tx, head.Hash()))
+
+	// the synced chain is past osaka
+	_, _, err = core.WriteGenesisBlock(ctx, tx, genesis(), nil, mustParseConfigOverride(t, `{"osakaTime": 2000}`), dirs, logger)
+	require.ErrorContains(t, err, "chain config override conflicts with the synced chain: osakaTime changed from 1000 to 2000")
+	_, _, err = core.WriteGenesisBlock(ctx, tx, genesis(), nil, mustParseConfigOverride(t, `{}`), dirs, logger)
+	require.ErrorContains(t, err, "osakaTime changed from 1000 to <nil>")
+	config, _, err = core.WriteGenesisBlock(ctx, tx, genesis(), nil, nil, dirs, logger)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1000), config.OsakaTime)
+
+	// but not past bpo1
+	config, _, err = core.WriteGenesisBlock(ctx, tx, genesis(), nil, mustParseConfigOverride(t, `{"osakaTime": 1000, "bpo1Time": 3000}`), dirs, logger)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(3000), config.Bpo1Time)
+	stored, err := core.ReadConfigOverride(tx)
+	require.NoError(t, err)
+	require.False(t, stored.Empty())
+
+	_, _, err = core.WriteGenesisBlock(ctx, tx, genesis(), nil, mustParseConfigOverride(t, `{"osakaTime": 1000, "bpo1Time": 500}`), dirs, logger)
+	require.ErrorContains(t, err, "unsupported fork ordering")
+}
+
+func TestParseConfigOverride(t *testing.T) {
+	t.Parallel()
+	for _, data := range []string{
+		`{"chainId": 5}`,
+		`{"fooTime": 5}`,
+		`{"osakaTime": "soon"}`,
+		`[]`,
+	} {
+		_, err := core.ParseConfigOverride([]byte(data))
+		require.ErrorContains(t, err, "invalid chain config override", data)
+	}
+	override := mustParseConfigOverride(t, `{"pragueTime": 10, "blobSchedule": {"prague": {"target": 6, "max": 9, "baseFeeUpdateFraction": 5007716}}}`)
+	require.False(t, override.Empty())
+	require.True(t, mustParseConfigOverride(t, `{}`).Empty())
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/erigontech/erigon-db/rawdb"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
+)
+
+var errStopAllocFile = errors.New("stop reading alloc file")
+
+// applyGenesisAllocFile is applyGenesisAlloc for a genesis whose alloc is in g.AllocFile. The file is decoded
+// incrementally, a batch of batchSize accounts at a time, so memory use doesn't depend on the size of the alloc.
+// ReadGenesisAllocFile enforces address order, so the accounts are applied in the same order as the equivalent
+// inline alloc and the resulting state root is the same. If g.AllocFileHash is set, the content of the file is
+// checked against it.
+func applyGenesisAllocFile(ctx context.Context, g *types.Genesis, head *types.Header, st *genesisAllocState, batchSize int, logger log.Logger) error {
+	// See applyGenesisAlloc: the zero account has to exist before any alloc is applied, so look ahead for constructors
+	if g.Config.Aura != nil {
+		hasConstructorAllocation, err := genesisAllocFileHasConstructor(g.AllocFile)
+		if err != nil {
+			return err
+		}
+		if hasConstructorAllocation {
+			st.statedb.CreateAccount(common.Address{}, false)
+		}
+	}
+
+	f, err := os.Open(g.AllocFile)
+	if err != nil {
+		return fmt.Errorf("genesis alloc file: %w", err)
+	}
+	defer f.Close()
+	hasher := crypto.NewKeccakState()
+	r := io.TeeReader(f, hasher)
+
+	batch := make(types.GenesisAlloc, batchSize)
+	done := 0
+	started := time.Now()
+	flush := func() error {
+		if done > 0 {
+			if err := st.nextBatch(); err != nil {
+				return err
+			}
+			logger.Info("[genesis] writing alloc", "file", g.AllocFile, "accounts", done, "elapsed", time.Since(started).Round(time.Second))
+		}
+		addrs := sortedAllocAddresses(batch)
+		if err := applyGenesisAllocBatch(ctx, g, batch, addrs, head, st.statedb); err != nil {
+			return err
+		}
+		done += len(addrs)
+		clear(batch)
+		return nil
+	}
+	err = types.ReadGenesisAllocFile(r, func(addr common.Address, account types.GenesisAccount) error {
+		batch[addr] = account
+		if len(batch) < batchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		if err = flush(); err != nil {
+			return err
+		}
+	}
+	if err = st.finish(); err != nil {
+		return err
+	}
+
+	if g.AllocFileHash != nil {
+		// the decoder may stop short of trailing whitespace
+		if _, err = io.Copy(io.Discard, r); err != nil {
+			return fmt.Errorf("genesis alloc file: %w", err)
+		}
+		var hash common.Hash
+		hasher.Read(hash[:]) //nolint:errcheck
+		if hash != *g.AllocFileHash {
+			return fmt.Errorf("genesis alloc file %s has changed (hash %x, expected %x)", g.AllocFile, hash, *g.AllocFileHash)
+		}
+	}
+	return nil
+}
+
+func genesisAllocFileHasConstructor(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("genesis alloc file: %w", err)
+	}
+	defer f.Close()
+	found := false
+	err = types.ReadGenesisAllocFile(f, func(_ common.Address, account types.GenesisAccount) error {
+		if len(account.Constructor) > 0 {
+			found = true
+			return errStopAllocFile
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopAllocFile) {
+		return false, err
+	}
+	return found, nil
+}
+
+// GenesisAllocFileHash returns the keccak256 hash of the content of a genesis alloc file.
+func GenesisAllocFileHash(path string) (common.Hash, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("genesis alloc file: %w", err)
+	}
+	defer f.Close()
+	hasher := crypto.NewKeccakState()
+	if _, err = io.Copy(hasher, f); err != nil {
+		return common.Hash{}, fmt.Errorf("genesis alloc file: %w", err)
+	}
+	var hash common.Hash
+	hasher.Read(hash[:]) //nolint:errcheck
+	return hash, nil
+}
+
+// genesisWithAllocFileHash returns a copy of g with the AllocFileHash set to the hash of its alloc file, so that
+// it is stored along with the genesis. If g already carries a hash, the file is checked against it instead.
+func genesisWithAllocFileHash(g *types.Genesis) (*types.Genesis, error) {
+	hash, err := GenesisAllocFileHash(g.AllocFile)
+	if err != nil {
+		return nil, err
+	}
+	if g.AllocFileHash != nil && *g.AllocFileHash != hash {
+		return nil, fmt.Errorf("genesis alloc file %s has changed (hash %x, expected %x)", g.AllocFile, hash, *g.AllocFileHash)
+	}
+	withHash := *g
+	withHash.AllocFileHash = &hash
+	if withHash.Alloc == nil {
+		// alloc is a required field of the stored genesis
+		withHash.Alloc = types.GenesisAlloc{}
+	}
+	return &withHash, nil
+}
+
+// genesisBlockFromStoredAllocFile returns the genesis block of g without applying its alloc file, if the database
+// was initialized with an alloc file of the same hash: the state root is then taken from the stored genesis header.
+// The file isn't read if it is the one the database was initialized with and g doesn't pin a hash, so it is
+// only needed until block 0 is executed. It fails if the hashes differ, since the alloc changed.
+func genesisBlockFromStoredAllocFile(tx kv.Tx, g *types.Genesis, storedHash common.Hash) (*types.Block, bool, error) {
+	if g.AllocFile == "" {
+		return nil, false, nil
+	}
+	stored, err := ReadGenesis(tx)
+	if err != nil {
+		return nil, false, err
+	}
+	if stored == nil || stored.AllocFileHash == nil {
+		return nil, false, nil
+	}
+	hash := g.AllocFileHash
+	if hash == nil && g.AllocFile == stored.AllocFile {
+		hash = stored.AllocFileHash
+	}
+	if hash == nil {
+		fileHash, err := GenesisAllocFileHash(g.AllocFile)
+		if err != nil {
+			return nil, false, err
+		}
+		hash = &fileHash
+	}
+	if *stored.AllocFileHash != *hash {
+		return nil, false, fmt.Errorf("genesis alloc file %s differs from the one the database was initialized with (have %x, new %x)",
+			g.AllocFile, *stored.AllocFileHash, *hash)
+	}
+	storedHeader := rawdb.ReadHeader(tx, storedHash, 0)
+	if storedHeader == nil {
+		return nil, false, nil
+	}
+	head, withdrawals := GenesisWithoutStateToBlock(g)
+	head.Root = storedHeader.Root
+	return types.NewBlock(head, nil, nil, nil, withdrawals), true, nil
+}
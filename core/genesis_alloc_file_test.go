@@ -0,0 +1,186 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core"
+)
+
+func allocFileGenesis(t *testing.T, accounts int) (inline, fromFile *types.Genesis) {
+	t.Helper()
+	alloc := make(types.GenesisAlloc, accounts)
+	for i := 0; i < accounts; i++ {
+		var addr common.Address
+		binary.BigEndian.PutUint64(addr[12:], uint64(i)*0x9e3779b97f4a7c15)
+		account := types.GenesisAccount{Balance: big.NewInt(int64(i + 1)), Nonce: uint64(i % 3)}
+		if i%1000 == 0 {
+			account.Code = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+			account.Storage = map[common.Hash]common.Hash{
+				common.BigToHash(big.NewInt(1)): common.BigToHash(big.NewInt(int64(i))),
+			}
+		}
+		alloc[addr] = account
+	}
+	inline = &types.Genesis{Config: chain.TestChainConfig, Alloc: alloc}
+
+	path := filepath.Join(t.TempDir(), "alloc.jsonl")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, types.WriteGenesisAllocFile(f, alloc))
+	require.NoError(t, f.Close())
+	return inline, &types.Genesis{Config: chain.TestChainConfig, Alloc: types.GenesisAlloc{}, AllocFile: path}
+}
+
+func TestGenesisAllocFile(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	inline, fromFile := allocFileGenesis(t, 100_000)
+
+	want, _, err := core.GenesisToBlock(context.Background(), inline, datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+	got, _, err := core.GenesisToBlock(context.Background(), fromFile, datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+	require.Equal(t, want.Root(), got.Root())
+	require.Equal(t, want.Hash(), got.Hash())
+}
+
+func TestGenesisAllocFileRoundTrip(t *testing.T) {
+	t.Parallel()
+	inline, fromFile := allocFileGenesis(t, 2_000)
+	f, err := os.Open(fromFile.AllocFile)
+	require.NoError(t, err)
+	defer f.Close()
+	read := types.GenesisAlloc{}
+	require.NoError(t, types.ReadGenesisAllocFile(f, func(addr common.Address, account types.GenesisAccount) error {
+		read[addr] = account
+		return nil
+	}))
+	want, err := json.Marshal(inline.Alloc)
+	require.NoError(t, err)
+	got, err := json.Marshal(read)
+	require.NoError(t, err)
+	require.JSONEq(t, string(want), string(got))
+
+	// a relative path is resolved against the directory of the genesis file
+	data, err := json.Marshal(&types.Genesis{Config: chain.TestChainConfig, Alloc: types.GenesisAlloc{}, AllocFile: "alloc.jsonl"})
+	require.NoError(t, err)
+	require.Empty(t, core.ValidateGenesisJSON(data))
+	var g types.Genesis
+	require.NoError(t, json.Unmarshal(data, &g))
+	require.NoError(t, types.ResolveAllocFile(&g, filepath.Join(filepath.Dir(fromFile.AllocFile), "genesis.json")))
+	require.Equal(t, fromFile.AllocFile, g.AllocFile)
+	// and made absolute, since it is stored with the genesis
+	g.AllocFile = "alloc.jsonl"
+	require.NoError(t, types.ResolveAllocFile(&g, "genesis.json"))
+	require.True(t, filepath.IsAbs(g.AllocFile))
+
+	problems := core.ValidateGenesisJSON([]byte(`{"config": {"chainId": 1337}, "gasLimit": "0x1c9c380", "difficulty": "0x1"}`))
+	require.Equal(t, []core.GenesisProblem{{Field: "alloc", Msg: "missing"}}, problems)
+	problems = core.ValidateGenesisJSON([]byte(`{"config": {"chainId": 1337}, "gasLimit": "0x1c9c380", "difficulty": "0x1", "allocFile": "alloc.jsonl"}`))
+	require.Equal(t, []core.GenesisProblem{{Field: "alloc", Msg: "missing, must be {} when allocFile is set"}}, problems)
+	require.ErrorContains(t, json.Unmarshal([]byte(`{"config": {"chainId": 1337}, "allocFile": "alloc.jsonl"}`), &g), "missing required field 'alloc'")
+	inline.AllocFile = fromFile.AllocFile
+	require.Contains(t, core.ValidateGenesis(inline), core.GenesisProblem{Field: "allocFile", Msg: "alloc and allocFile are mutually exclusive"})
+}
+
+func TestGenesisAllocFileChanged(t *testing.T) {
+	t.Parallel()
+	inline, fromFile := allocFileGenesis(t, 100)
+	want, _, err := core.GenesisToBlock(context.Background(), inline, datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+
+	dirs := datadir.New(t.TempDir())
+	db := temporaltest.NewTestDB(t, dirs)
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	_, block, err := core.WriteGenesisBlock(context.Background(), tx, fromFile, nil, nil, dirs, log.New())
+	require.NoError(t, err)
+	require.Equal(t, want.Hash(), block.Hash())
+	require.Nil(t, fromFile.AllocFileHash, "the supplied genesis must not be modified")
+
+	stored, err := core.ReadGenesis(tx)
+	require.NoError(t, err)
+	hash, err := core.GenesisAllocFileHash(fromFile.AllocFile)
+	require.NoError(t, err)
+	require.Equal(t, &hash, stored.AllocFileHash)
+
+	// the stored state root is reused while the file is unchanged
+	_, block, err = core.WriteGenesisBlock(context.Background(), tx, &types.Genesis{Config: chain.TestChainConfig, Alloc: types.GenesisAlloc{}, AllocFile: fromFile.AllocFile}, nil, nil, dirs, log.New())
+	require.NoError(t, err)
+	require.Equal(t, want.Hash(), block.Hash())
+
+	for addr, account := range inline.Alloc {
+		account.Balance = new(big.Int).Add(account.Balance, big.NewInt(1))
+		inline.Alloc[addr] = account
+		break
+	}
+	f, err := os.Create(fromFile.AllocFile)
+	require.NoError(t, err)
+	require.NoError(t, types.WriteGenesisAllocFile(f, inline.Alloc))
+	require.NoError(t, f.Close())
+
+	// the file the database was initialized with isn't read again
+	_, block, err = core.WriteGenesisBlock(context.Background(), tx, &types.Genesis{Config: chain.TestChainConfig, Alloc: types.GenesisAlloc{}, AllocFile: fromFile.AllocFile}, nil, nil, dirs, log.New())
+	require.NoError(t, err)
+	require.Equal(t, want.Hash(), block.Hash())
+
+	// another file is compared by hash
+	changed := filepath.Join(t.TempDir(), "changed.jsonl")
+	require.NoError(t, os.Rename(fromFile.AllocFile, changed))
+	_, _, err = core.WriteGenesisBlock(context.Background(), tx, &types.Genesis{Config: chain.TestChainConfig, Alloc: types.GenesisAlloc{}, AllocFile: changed}, nil, nil, dirs, log.New())
+	require.ErrorContains(t, err, "differs from the one the database was initialized with")
+	require.NoError(t, os.Rename(changed, fromFile.AllocFile))
+
+	// applying the stored genesis, as execution does for block 0, detects the change too
+	_, _, err = core.GenesisToBlock(context.Background(), stored, datadir.New(t.TempDir()), log.New())
+	require.ErrorContains(t, err, "has changed")
+}
+
+func TestGenesisAllocFileOrder(t *testing.T) {
+	t.Parallel()
+	read := func(data string) error {
+		return types.ReadGenesisAllocFile(strings.NewReader(data), func(common.Address, types.GenesisAccount) error { return nil })
+	}
+	require.NoError(t, read(`{"address": "0x00000000000000000000000000000000000000aa", "balance": "0x1"}
+{"address": "0x00000000000000000000000000000000000000bb", "balance": "0x1"}`))
+	// duplicates are found however far apart they are, without remembering every address
+	require.ErrorContains(t, read(`{"address": "0x00000000000000000000000000000000000000aa", "balance": "0x1"}
+{"address": "0x00000000000000000000000000000000000000aa", "balance": "0x2"}`), "entry 2: duplicate account")
+	require.ErrorContains(t, read(`{"address": "0x00000000000000000000000000000000000000bb", "balance": "0x1"}
+{"address": "0x00000000000000000000000000000000000000aa", "balance": "0x1"}
+{"address": "0x00000000000000000000000000000000000000bb", "balance": "0x1"}`), "entry 2: account 00000000000000000000000000000000000000aa is not in ascending address order")
+}
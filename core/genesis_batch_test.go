@@ -0,0 +1,95 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/execution/chainspec"
+)
+
+func syntheticGenesis(accounts int) *types.Genesis {
+	alloc := make(types.GenesisAlloc, accounts)
+	for i := 0; i < accounts; i++ {
+		var addr common.Address
+		binary.BigEndian.PutUint64(addr[12:], uint64(i)*0x9e3779b97f4a7c15)
+		acc := types.GenesisAccount{Balance: big.NewInt(int64(i + 1)), Nonce: uint64(i % 3)}
+		if i%1000 == 0 {
+			acc.Code = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+			acc.Storage = map[common.Hash]common.Hash{
+				common.BigToHash(big.NewInt(1)): common.BigToHash(big.NewInt(int64(i))),
+			}
+		}
+		alloc[addr] = acc
+	}
+	return &types.Genesis{Config: chain.TestChainConfig, Alloc: alloc}
+}
+
+func TestGenesisAllocBatchingKeepsRoot(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	logger := log.New()
+	g := syntheticGenesis(200_000)
+
+	unbatched, statedb, err := genesisToBlock(context.Background(), g, datadir.New(t.TempDir()), len(g.Alloc)+1, true /* keepState */, logger)
+	require.NoError(t, err)
+	require.NotNil(t, statedb)
+	// batches flushed to the database one by one, the commitment is computed incrementally
+	batched, statedb, err := genesisToBlock(context.Background(), g, datadir.New(t.TempDir()), genesisAllocBatchSize, false /* keepState */, logger)
+	require.NoError(t, err)
+	require.Nil(t, statedb)
+	oddBatches, _, err := genesisToBlock(context.Background(), g, datadir.New(t.TempDir()), 7919, false /* keepState */, logger)
+	require.NoError(t, err)
+	// batches kept in the same state
+	keptBatches, _, err := genesisToBlock(context.Background(), g, datadir.New(t.TempDir()), 7919, true /* keepState */, logger)
+	require.NoError(t, err)
+
+	require.Equal(t, unbatched.Root(), batched.Root())
+	require.Equal(t, unbatched.Root(), oddBatches.Root())
+	require.Equal(t, unbatched.Root(), keptBatches.Root())
+	require.Equal(t, unbatched.Hash(), batched.Hash())
+}
+
+func TestGenesisAllocCancel(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := genesisToBlock(ctx, syntheticGenesis(100), datadir.New(t.TempDir()), 10, false /* keepState */, log.New())
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func BenchmarkGenesisToBlockGnosis(b *testing.B) {
+	g := chainspec.GnosisGenesisBlock()
+	logger := log.New()
+	for i := 0; i < b.N; i++ {
+		block, _, err := genesisToBlock(context.Background(), g, datadir.New(b.TempDir()), genesisAllocBatchSize, false /* keepState */, logger)
+		require.NoError(b, err)
+		require.Equal(b, chainspec.GnosisGenesisStateRoot, block.Root())
+	}
+}
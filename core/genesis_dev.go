@@ -0,0 +1,85 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/jinzhu/copier"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/execution/chainspec"
+)
+
+// DevAccountBalance is the balance every account returned by DeveloperAccounts is funded with: 10^12 ether.
+var DevAccountBalance = new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)
+
+// DevAccount is a pre-funded account of a developer chain.
+type DevAccount struct {
+	Address common.Address
+	Key     *ecdsa.PrivateKey
+}
+
+// DeveloperAccounts derives count accounts from mnemonicOrSeed. The derivation is keccak256(seed || index || attempt),
+// which is deterministic but not BIP-39/BIP-44 compatible: the same seed always yields the same accounts in this
+// function only, wallets will derive different ones from the same mnemonic.
+func DeveloperAccounts(count int, mnemonicOrSeed string) []DevAccount {
+	accounts := make([]DevAccount, count)
+	buf := make([]byte, len(mnemonicOrSeed)+16)
+	copy(buf, mnemonicOrSeed)
+	for i := range accounts {
+		binary.BigEndian.PutUint64(buf[len(mnemonicOrSeed):], uint64(i))
+		for attempt := uint64(0); ; attempt++ {
+			binary.BigEndian.PutUint64(buf[len(mnemonicOrSeed)+8:], attempt)
+			// a hash is not a valid secp256k1 key with probability ~2^-128, hence the retry
+			key, err := crypto.ToECDSA(crypto.Keccak256(buf))
+			if err != nil {
+				continue
+			}
+			accounts[i] = DevAccount{Address: crypto.PubkeyToAddress(key.PublicKey), Key: key}
+			break
+		}
+	}
+	return accounts
+}
+
+// DevnetGenesisBlock returns a genesis for a local developer chain: faucetCount accounts derived from mnemonicOrSeed
+// with DeveloperAccounts are funded with DevAccountBalance. The chain is proof-of-stake from genesis with every fork
+// up to Prague active, like chain.AllProtocolChanges, and the system contracts of Cancun (EIP-4788) and
+// Prague (EIP-2935, EIP-7002, EIP-7251) are pre-deployed, so it only needs a consensus layer client to produce blocks.
+// Unlike chainspec.DeveloperGenesisBlock, which is the clique chain of --dev, it is not mined by erigon itself.
+func DevnetGenesisBlock(faucetCount int, mnemonicOrSeed string, gasLimit uint64) *types.Genesis {
+	var config chain.Config
+	copier.Copy(&config, chain.AllProtocolChanges)
+	config.ChainName = "devnet"
+
+	alloc := chainspec.SystemContractsPrealloc()
+	for _, account := range DeveloperAccounts(faucetCount, mnemonicOrSeed) {
+		alloc[account.Address] = types.GenesisAccount{Balance: new(big.Int).Set(DevAccountBalance)}
+	}
+
+	return &types.Genesis{
+		Config:   &config,
+		GasLimit: gasLimit,
+		Alloc:    alloc,
+	}
+}
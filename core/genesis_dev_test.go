@@ -0,0 +1,124 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-db/rawdb"
+	"github.com/erigontech/erigon-lib/chain/params"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/execution/consensus/misc"
+	"github.com/erigontech/erigon/execution/stages/mock"
+)
+
+func TestDevnetGenesisDeterministic(t *testing.T) {
+	t.Parallel()
+	const seed = "test test test test test test test test test test test junk"
+
+	accounts := core.DeveloperAccounts(5, seed)
+	require.Equal(t, accounts, core.DeveloperAccounts(5, seed))
+	require.Equal(t, accounts[:3], core.DeveloperAccounts(3, seed))
+	require.NotEqual(t, accounts[0].Address, core.DeveloperAccounts(1, seed+" ")[0].Address)
+
+	g := core.DevnetGenesisBlock(5, seed, 30_000_000)
+	require.Empty(t, core.ValidateGenesis(g))
+	require.Equal(t, 0, g.Config.TerminalTotalDifficulty.Sign())
+	require.True(t, g.Config.IsPrague(0))
+	require.Len(t, g.Alloc, 5+4)
+	for _, account := range accounts {
+		require.Equal(t, core.DevAccountBalance, g.Alloc[account.Address].Balance)
+	}
+	for _, addr := range []common.Address{params.BeaconRootsAddress, params.HistoryStorageAddress, params.WithdrawalRequestAddress, params.ConsolidationRequestAddress} {
+		require.NotEmpty(t, g.Alloc[addr].Code, addr)
+	}
+
+	block1, _, err := core.GenesisToBlock(context.Background(), g, datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+	block2, _, err := core.GenesisToBlock(context.Background(), core.DevnetGenesisBlock(5, seed, 30_000_000), datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+	require.Equal(t, block1.Root(), block2.Root())
+	require.Equal(t, block1.Hash(), block2.Hash())
+
+	other, _, err := core.GenesisToBlock(context.Background(), core.DevnetGenesisBlock(5, "another seed", 30_000_000), datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+	require.NotEqual(t, block1.Root(), other.Root())
+}
+
+func TestDevnetGenesisBuildsBlocks(t *testing.T) {
+	t.Parallel()
+	accounts := core.DeveloperAccounts(2, "dev")
+	m := mock.MockWithGenesis(t, core.DevnetGenesisBlock(2, "dev", 30_000_000), accounts[0].Key, false)
+	signer := types.LatestSigner(m.ChainConfig)
+
+	chainPack, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3, func(i int, gen *core.BlockGen) {
+		txn, err := types.SignTx(types.NewTransaction(gen.TxNonce(accounts[0].Address), accounts[1].Address, uint256.NewInt(common.Ether), params.TxGas, uint256.NewInt(10*common.GWei), nil), *signer, accounts[0].Key)
+		require.NoError(t, err)
+		gen.AddTx(txn)
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chainPack))
+
+	tx, err := m.DB.BeginRo(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.Equal(t, chainPack.TopBlock.Hash(), rawdb.ReadHeadBlockHash(tx))
+}
+
+func TestDevnetGenesisBlobSchedule(t *testing.T) {
+	t.Parallel()
+	g := core.DevnetGenesisBlock(1, "dev", 30_000_000)
+	g.Config.BlobSchedule = map[string]*params.BlobConfig{
+		"cancun": {Target: 1, Max: 2, BaseFeeUpdateFraction: params.DefaultCancunBlobConfig.BaseFeeUpdateFraction},
+	}
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+	require.Empty(t, core.ValidateGenesisJSON(data))
+
+	var decoded types.Genesis
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	config := decoded.Config
+	require.Equal(t, uint64(1), config.GetTargetBlobsPerBlock(0))
+	require.Equal(t, uint64(2), config.GetMaxBlobsPerBlock(0))
+
+	// block building only admits as many blobs as the schedule allows
+	gp := new(core.GasPool).AddBlobGas(config.GetMaxBlobGasPerBlock(0))
+	require.NoError(t, gp.SubBlobGas(2*params.GasPerBlob))
+	require.ErrorIs(t, gp.SubBlobGas(params.GasPerBlob), core.ErrBlobGasLimitReached)
+
+	// the blob fee market moves around the supplied target rather than Cancun's default of 3
+	blobGasUsed, excessBlobGas := 2*params.GasPerBlob, uint64(0)
+	parent := &types.Header{Time: 0, BlobGasUsed: &blobGasUsed, ExcessBlobGas: &excessBlobGas}
+	require.Equal(t, params.GasPerBlob, misc.CalcExcessBlobGas(config, parent, 12))
+
+	// and the stage loop builds on it with the supplied schedule
+	m := mock.MockWithGenesis(t, g, core.DeveloperAccounts(1, "dev")[0].Key, false)
+	chainPack, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 1, nil)
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chainPack))
+	require.NotNil(t, chainPack.TopBlock.Header().ExcessBlobGas)
+	require.Equal(t, uint64(2), m.ChainConfig.GetMaxBlobsPerBlock(chainPack.TopBlock.Time()))
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+
+	"github.com/holiman/uint256"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/empty"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/trie"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon-lib/types/accounts"
+	"github.com/erigontech/erigon/core/state"
+)
+
+// GenesisInMemAllocLimit is the largest alloc (in accounts) for which GenesisToBlock computes the state root
+// in memory instead of through a temporary database in the datadir.
+var GenesisInMemAllocLimit = 50_000
+
+// GenesisToBlockInMem creates the genesis block of g without touching disk: the alloc is applied to
+// an in-memory state and the state root is computed with an in-memory trie. The result is identical
+// to GenesisToBlock, but the whole state is held in memory, so it is meant for small allocs.
+func GenesisToBlockInMem(g *types.Genesis) (*types.Block, *state.IntraBlockState, error) {
+	return genesisToBlockInMem(context.Background(), g, genesisAllocBatchSize, log.Root())
+}
+
+func genesisToBlockInMem(ctx context.Context, g *types.Genesis, batchSize int, logger log.Logger) (*types.Block, *state.IntraBlockState, error) {
+	_ = g.Alloc //nil-check
+
+	head, withdrawals := GenesisWithoutStateToBlock(g)
+
+	w := newGenesisMemWriter()
+	allocState := &genesisAllocState{statedb: state.New(state.NewNoopReader()), w: w}
+	allocState.statedb.SetTrace(false)
+	if err := applyGenesisAlloc(ctx, g, head, allocState, batchSize, logger); err != nil {
+		return nil, nil, err
+	}
+	head.Root = w.root()
+
+	return types.NewBlock(head, nil, nil, nil, withdrawals), allocState.statedb, nil
+}
+
+// genesisMemWriter keeps the latest state written to it, mirroring what state.Writer leaves in the domains.
+type genesisMemWriter struct {
+	accounts map[common.Address]*accounts.Account
+	storage  map[common.Address]map[common.Hash]uint256.Int
+}
+
+func newGenesisMemWriter() *genesisMemWriter {
+	return &genesisMemWriter{
+		accounts: map[common.Address]*accounts.Account{},
+		storage:  map[common.Address]map[common.Hash]uint256.Int{},
+	}
+}
+
+func (w *genesisMemWriter) UpdateAccountData(address common.Address, original, account *accounts.Account) error {
+	if original.Incarnation > account.Incarnation {
+		delete(w.storage, address)
+	}
+	acc := new(accounts.Account)
+	acc.Copy(account)
+	w.accounts[address] = acc
+	return nil
+}
+
+func (w *genesisMemWriter) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	return nil // the code hash is part of the account
+}
+
+func (w *genesisMemWriter) DeleteAccount(address common.Address, original *accounts.Account) error {
+	delete(w.accounts, address)
+	return nil
+}
+
+func (w *genesisMemWriter) WriteAccountStorage(address common.Address, incarnation uint64, key common.Hash, original, value uint256.Int) error {
+	if original == value {
+		return nil
+	}
+	if value.IsZero() {
+		delete(w.storage[address], key)
+		return nil
+	}
+	if w.storage[address] == nil {
+		w.storage[address] = map[common.Hash]uint256.Int{}
+	}
+	w.storage[address][key] = value
+	return nil
+}
+
+func (w *genesisMemWriter) CreateContract(address common.Address) error {
+	delete(w.storage, address)
+	return nil
+}
+
+func (w *genesisMemWriter) root() common.Hash {
+	if len(w.accounts) == 0 {
+		return empty.RootHash
+	}
+	t := trie.New(common.Hash{})
+	for addr, account := range w.accounts {
+		acc := new(accounts.Account)
+		acc.Copy(account)
+		acc.Root = empty.RootHash
+		if slots := w.storage[addr]; len(slots) > 0 {
+			st := trie.New(common.Hash{})
+			for key, value := range slots {
+				st.Update(crypto.Keccak256(key[:]), value.Bytes())
+			}
+			acc.Root = st.Hash()
+		}
+		if acc.CodeHash == (common.Hash{}) {
+			acc.CodeHash = empty.CodeHash
+		}
+		t.UpdateAccount(crypto.Keccak256(addr[:]), acc)
+	}
+	return t.Hash()
+}
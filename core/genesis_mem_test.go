@@ -0,0 +1,76 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/execution/chainspec"
+	_ "github.com/erigontech/erigon/polygon/chain" // registers the polygon networks
+)
+
+func requireSameGenesisBlock(t *testing.T, g *types.Genesis) {
+	t.Helper()
+	onDisk, _, err := genesisToBlock(context.Background(), g, datadir.New(t.TempDir()), genesisAllocBatchSize, true /* keepState */, log.New())
+	require.NoError(t, err)
+	inMem, _, err := GenesisToBlockInMem(g)
+	require.NoError(t, err)
+	require.Equal(t, onDisk.Root(), inMem.Root())
+	require.Equal(t, onDisk.Hash(), inMem.Hash())
+}
+
+func TestGenesisToBlockInMem(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	for _, network := range networkname.All {
+		t.Run(network, func(t *testing.T) {
+			t.Parallel()
+			requireSameGenesisBlock(t, chainspec.GenesisBlockByChainName(network))
+		})
+	}
+	t.Run("synthetic", func(t *testing.T) {
+		t.Parallel()
+		requireSameGenesisBlock(t, syntheticGenesis(5_000))
+	})
+	t.Run("constructor", func(t *testing.T) {
+		t.Parallel()
+		requireSameGenesisBlock(t, &types.Genesis{
+			Config: chain.AllProtocolChanges,
+			Alloc: types.GenesisAlloc{
+				// sets storage slots 0 and 1 on deployment, overwriting the preset slot 1
+				common.HexToAddress("0x1000000000000000000000000000000000000001"): {
+					Constructor: common.FromHex("602a5f556101c960015560048060135f395ff35f355f55"),
+					Storage:     map[common.Hash]common.Hash{common.BigToHash(big.NewInt(1)): common.BigToHash(big.NewInt(7))},
+					Balance:     big.NewInt(1),
+				},
+				common.HexToAddress("0x1000000000000000000000000000000000000002"): {Balance: big.NewInt(0)},
+			},
+		})
+	})
+}
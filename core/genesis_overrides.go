@@ -0,0 +1,126 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core/state"
+)
+
+// GenesisOverrides replaces fields of the genesis block header, so that local networks and tests don't have to
+// maintain a whole genesis file to change one of them. Nil fields are left as specified by the genesis.
+type GenesisOverrides struct {
+	ExtraData     []byte
+	BaseFee       *big.Int
+	ExcessBlobGas *uint64
+	Difficulty    *big.Int
+	GasLimit      *uint64
+	Timestamp     *uint64
+	Coinbase      *common.Address
+}
+
+// Apply returns a copy of g with the overrides applied. It fails, listing every problem, if an override
+// doesn't fit the chain config of g, e.g. a base fee for a chain that isn't London at genesis.
+// The alloc and the config are shared with g.
+func (o *GenesisOverrides) Apply(g *types.Genesis) (*types.Genesis, error) {
+	if g == nil || g.Config == nil {
+		return nil, types.ErrGenesisNoConfig
+	}
+	overridden := *g
+	if o == nil {
+		return &overridden, nil
+	}
+	if o.ExtraData != nil {
+		overridden.ExtraData = common.Copy(o.ExtraData)
+	}
+	if o.BaseFee != nil {
+		overridden.BaseFee = new(big.Int).Set(o.BaseFee)
+	}
+	if o.ExcessBlobGas != nil {
+		excessBlobGas := *o.ExcessBlobGas
+		overridden.ExcessBlobGas = &excessBlobGas
+	}
+	if o.Difficulty != nil {
+		overridden.Difficulty = new(big.Int).Set(o.Difficulty)
+	}
+	if o.GasLimit != nil {
+		overridden.GasLimit = *o.GasLimit
+	}
+	if o.Timestamp != nil {
+		overridden.Timestamp = *o.Timestamp
+	}
+	if o.Coinbase != nil {
+		overridden.Coinbase = *o.Coinbase
+	}
+
+	var problems []GenesisProblem
+	report := func(field, msg string) {
+		problems = append(problems, GenesisProblem{Field: field, Msg: msg})
+	}
+	if o.BaseFee != nil {
+		if !g.Config.IsLondon(0) {
+			report("baseFeePerGas", "requires London to be active at genesis")
+		} else if o.BaseFee.Sign() <= 0 {
+			report("baseFeePerGas", "must be positive")
+		}
+	}
+	if o.ExcessBlobGas != nil && !g.Config.IsCancun(overridden.Timestamp) {
+		report("excessBlobGas", "requires Cancun to be active at genesis")
+	}
+	if o.Difficulty != nil && o.Difficulty.Sign() < 0 {
+		report("difficulty", "must not be negative")
+	}
+	if o.GasLimit != nil && *o.GasLimit == 0 {
+		report("gasLimit", "must be non-zero")
+	}
+	if o.ExtraData != nil && g.Config.Clique != nil && len(o.ExtraData) < 32+65 {
+		report("extraData", "too short for clique, must hold the 32-byte vanity and the 65-byte seal")
+	}
+	if err := genesisProblemsError(problems); err != nil {
+		return nil, err
+	}
+	return &overridden, nil
+}
+
+// GenesisToBlockWithOverrides is GenesisToBlock for g with overrides applied.
+func GenesisToBlockWithOverrides(ctx context.Context, g *types.Genesis, overrides *GenesisOverrides, dirs datadir.Dirs, logger log.Logger) (*types.Block, *state.IntraBlockState, error) {
+	overridden, err := overrides.Apply(g)
+	if err != nil {
+		return nil, nil, err
+	}
+	return GenesisToBlock(ctx, overridden, dirs, logger)
+}
+
+// WriteGenesisBlockWithOverrides is WriteGenesisBlock for genesis with overrides applied.
+func WriteGenesisBlockWithOverrides(ctx context.Context, tx kv.RwTx, genesis *types.Genesis, overrides *GenesisOverrides, overrideOsakaTime *big.Int, dirs datadir.Dirs, logger log.Logger) (*chain.Config, *types.Block, error) {
+	overridden, err := overrides.Apply(genesis)
+	if err != nil {
+		if genesis != nil && genesis.Config != nil {
+			return genesis.Config, nil, err
+		}
+		return chain.AllProtocolChanges, nil, err
+	}
+	return WriteGenesisBlock(ctx, tx, overridden, overrideOsakaTime, nil, dirs, logger)
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/execution/stages/mock"
+)
+
+func overridesGenesis(config *chain.Config) *types.Genesis {
+	return &types.Genesis{
+		Config:     config,
+		GasLimit:   30_000_000,
+		Difficulty: big.NewInt(1),
+		Alloc: types.GenesisAlloc{
+			common.HexToAddress("0x00000000000000000000000000000000000000aa"): {Balance: big.NewInt(1)},
+		},
+	}
+}
+
+func TestGenesisOverrides(t *testing.T) {
+	t.Parallel()
+	g := overridesGenesis(chain.AllProtocolChanges)
+	excessBlobGas, gasLimit, timestamp := uint64(1<<20), uint64(60_000_000), uint64(42)
+	coinbase := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	overrides := &core.GenesisOverrides{
+		ExtraData:     []byte("local network"),
+		BaseFee:       big.NewInt(7),
+		ExcessBlobGas: &excessBlobGas,
+		Difficulty:    big.NewInt(3),
+		GasLimit:      &gasLimit,
+		Timestamp:     &timestamp,
+		Coinbase:      &coinbase,
+	}
+
+	block, _, err := core.GenesisToBlockWithOverrides(context.Background(), g, overrides, datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+	require.Equal(t, []byte("local network"), block.Extra())
+	require.Equal(t, big.NewInt(7), block.BaseFee())
+	require.Equal(t, excessBlobGas, *block.Header().ExcessBlobGas)
+	require.Equal(t, big.NewInt(3), block.Difficulty())
+	require.Equal(t, gasLimit, block.GasLimit())
+	require.Equal(t, timestamp, block.Time())
+	require.Equal(t, coinbase, block.Coinbase())
+
+	// the spec itself is left untouched
+	require.Nil(t, g.ExtraData)
+	require.Equal(t, uint64(30_000_000), g.GasLimit)
+	plain, _, err := core.GenesisToBlock(context.Background(), g, datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+	require.Equal(t, plain.Root(), block.Root())
+	require.NotEqual(t, plain.Hash(), block.Hash())
+
+	db := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	_, written, err := core.WriteGenesisBlockWithOverrides(context.Background(), tx, g, overrides, nil, datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+	require.Equal(t, block.Hash(), written.Hash())
+}
+
+func TestGenesisOverridesInvalid(t *testing.T) {
+	t.Parallel()
+	excessBlobGas, gasLimit := uint64(1), uint64(0)
+	_, err := (&core.GenesisOverrides{
+		BaseFee:       big.NewInt(1),
+		ExcessBlobGas: &excessBlobGas,
+		Difficulty:    big.NewInt(-1),
+		GasLimit:      &gasLimit,
+	}).Apply(overridesGenesis(chain.TestChainConfig))
+	require.ErrorContains(t, err, "baseFeePerGas: requires London to be active at genesis")
+	require.ErrorContains(t, err, "excessBlobGas: requires Cancun to be active at genesis")
+	require.ErrorContains(t, err, "difficulty: must not be negative")
+	require.ErrorContains(t, err, "gasLimit: must be non-zero")
+
+	_, err = (&core.GenesisOverrides{BaseFee: big.NewInt(0)}).Apply(overridesGenesis(chain.AllProtocolChanges))
+	require.ErrorContains(t, err, "baseFeePerGas: must be positive")
+
+	clique := overridesGenesis(&chain.Config{ChainID: big.NewInt(1337), Clique: &chain.CliqueConfig{Epoch: 30000}})
+	_, err = (&core.GenesisOverrides{ExtraData: []byte{0x01}}).Apply(clique)
+	require.ErrorContains(t, err, "extraData: too short for clique")
+
+	_, err = (&core.GenesisOverrides{}).Apply(nil)
+	require.ErrorIs(t, err, types.ErrGenesisNoConfig)
+}
+
+func TestMockWithGenesisOverrides(t *testing.T) {
+	t.Parallel()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	m := mock.MockWithGenesisOverrides(t, overridesGenesis(chain.AllProtocolChanges), &core.GenesisOverrides{
+		ExtraData: []byte("mock"),
+		BaseFee:   big.NewInt(common.GWei),
+	}, key, false)
+	require.Equal(t, []byte("mock"), m.Genesis.Extra())
+	require.Equal(t, big.NewInt(common.GWei), m.Genesis.BaseFee())
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/execution/chainspec"
+	"github.com/erigontech/erigon/turbo/services"
+)
+
+// ReconstructGenesis reconstructs the genesis specification a node was initialized with, from the
+// stored chain config and the fields of the block 0 header.
+//
+// The alloc is taken from the genesis specification stored at init time or, for the built-in
+// networks, from the embedded chain spec. If neither is available the alloc is left empty,
+// use ReadGenesisAlloc to recover it from the genesis-time state.
+func ReconstructGenesis(tx kv.Tx, blockReader services.FullBlockReader) (*types.Genesis, error) {
+	ctx := context.Background()
+	hash, ok, err := blockReader.CanonicalHash(ctx, tx, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("genesis block not found")
+	}
+	header, err := blockReader.Header(ctx, tx, hash, 0)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("genesis header %x not found", hash)
+	}
+	config, err := ReadChainConfig(tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, fmt.Errorf("chain config for genesis %x not found", hash)
+	}
+
+	g := &types.Genesis{
+		Config:                config,
+		Nonce:                 header.Nonce.Uint64(),
+		Timestamp:             header.Time,
+		ExtraData:             common.Copy(header.Extra),
+		GasLimit:              header.GasLimit,
+		Difficulty:            new(big.Int).Set(header.Difficulty),
+		Mixhash:               header.MixDigest,
+		Coinbase:              header.Coinbase,
+		Number:                header.Number.Uint64(),
+		GasUsed:               header.GasUsed,
+		ParentHash:            header.ParentHash,
+		BaseFee:               header.BaseFee,
+		BlobGasUsed:           header.BlobGasUsed,
+		ExcessBlobGas:         header.ExcessBlobGas,
+		ParentBeaconBlockRoot: header.ParentBeaconBlockRoot,
+		RequestsHash:          header.RequestsHash,
+	}
+	if len(header.AuRaSeal) > 0 {
+		g.AuRaSeal = types.NewAuraSeal(header.AuRaStep, header.AuRaSeal)
+	}
+
+	stored, err := ReadGenesis(tx)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		g.Alloc = stored.Alloc
+	} else if known := chainspec.GenesisHashByChainName(config.ChainName); known != nil && *known == hash {
+		g.Alloc = chainspec.GenesisBlockByChainName(config.ChainName).Alloc
+	}
+	return g, nil
+}
+
+// ReadGenesisAlloc recovers the genesis allocation by iterating the state as of the end of block 0.
+// Accounts deployed via constructors are returned with their resulting code and storage.
+func ReadGenesisAlloc(tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader) (types.GenesisAlloc, error) {
+	collector := genesisAllocCollector{alloc: types.GenesisAlloc{}}
+	if _, err := state.NewDumper(tx, txNumsReader, 0).DumpToCollector(&collector, false, false, common.Address{}, 0); err != nil {
+		return nil, err
+	}
+	if collector.err != nil {
+		return nil, collector.err
+	}
+	return collector.alloc, nil
+}
+
+type genesisAllocCollector struct {
+	alloc types.GenesisAlloc
+	err   error
+}
+
+func (c *genesisAllocCollector) OnRoot(common.Hash) {}
+
+func (c *genesisAllocCollector) OnAccount(addr common.Address, account state.DumpAccount) {
+	balance, ok := new(big.Int).SetString(account.Balance, 10)
+	if !ok {
+		c.err = errors.Join(c.err, fmt.Errorf("account %x: invalid balance %q", addr, account.Balance))
+		return
+	}
+	genesisAccount := types.GenesisAccount{
+		Balance: balance,
+		Nonce:   account.Nonce,
+		Code:    account.Code,
+	}
+	if len(account.Storage) > 0 {
+		genesisAccount.Storage = make(map[common.Hash]common.Hash, len(account.Storage))
+		for k, v := range account.Storage {
+			genesisAccount.Storage[common.HexToHash(k)] = common.HexToHash(v)
+		}
+	}
+	c.alloc[addr] = genesisAccount
+}
@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/holiman/uint256"
+	"github.com/jinzhu/copier"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -59,13 +60,13 @@ func TestGenesisBlockHashes(t *testing.T) {
 			t.Fatal(err)
 		}
 		defer tx.Rollback()
-		_, block, err := core.WriteGenesisBlock(tx, genesis, nil, datadir.New(t.TempDir()), logger)
+		_, block, err := core.WriteGenesisBlock(context.Background(), tx, genesis, nil, nil, datadir.New(t.TempDir()), logger)
 		require.NoError(t, err)
 		expect := chainspec.GenesisHashByChainName(network)
 		require.NotNil(t, expect, network)
 		require.Equal(t, block.Hash(), *expect, network)
 	}
-	for _, network := range networkname.All {
+	for _, network := range chainspec.AllRegistered() {
 		check(network)
 	}
 }
@@ -74,13 +75,13 @@ func TestGenesisBlockRoots(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
 
-	block, _, err := core.GenesisToBlock(chainspec.MainnetGenesisBlock(), datadir.New(t.TempDir()), log.Root())
+	block, _, err := core.GenesisToBlock(context.Background(), chainspec.MainnetGenesisBlock(), datadir.New(t.TempDir()), log.Root())
 	require.NoError(err)
 	if block.Hash() != chainspec.MainnetGenesisHash {
 		t.Errorf("wrong mainnet genesis hash, got %v, want %v", block.Hash(), chainspec.MainnetGenesisHash)
 	}
 
-	block, _, err = core.GenesisToBlock(chainspec.GnosisGenesisBlock(), datadir.New(t.TempDir()), log.Root())
+	block, _, err = core.GenesisToBlock(context.Background(), chainspec.GnosisGenesisBlock(), datadir.New(t.TempDir()), log.Root())
 	require.NoError(err)
 	if block.Root() != chainspec.GnosisGenesisStateRoot {
 		t.Errorf("wrong Gnosis Chain genesis state root, got %v, want %v", block.Root(), chainspec.GnosisGenesisStateRoot)
@@ -89,7 +90,7 @@ func TestGenesisBlockRoots(t *testing.T) {
 		t.Errorf("wrong Gnosis Chain genesis hash, got %v, want %v", block.Hash(), chainspec.GnosisGenesisHash)
 	}
 
-	block, _, err = core.GenesisToBlock(chainspec.ChiadoGenesisBlock(), datadir.New(t.TempDir()), log.Root())
+	block, _, err = core.GenesisToBlock(context.Background(), chainspec.ChiadoGenesisBlock(), datadir.New(t.TempDir()), log.Root())
 	require.NoError(err)
 	if block.Root() != chainspec.ChiadoGenesisStateRoot {
 		t.Errorf("wrong Chiado genesis state root, got %v, want %v", block.Root(), chainspec.ChiadoGenesisStateRoot)
@@ -98,7 +99,7 @@ func TestGenesisBlockRoots(t *testing.T) {
 		t.Errorf("wrong Chiado genesis hash, got %v, want %v", block.Hash(), chainspec.ChiadoGenesisHash)
 	}
 
-	block, _, err = core.GenesisToBlock(chainspec.TestGenesisBlock(), datadir.New(t.TempDir()), log.Root())
+	block, _, err = core.GenesisToBlock(context.Background(), chainspec.TestGenesisBlock(), datadir.New(t.TempDir()), log.Root())
 	require.NoError(err)
 	if block.Root() != chainspec.TestGenesisStateRoot {
 		t.Errorf("wrong test genesis state root, got %v, want %v", block.Root(), chainspec.TestGenesisStateRoot)
@@ -117,19 +118,180 @@ func TestCommitGenesisIdempotency(t *testing.T) {
 	defer tx.Rollback()
 
 	genesis := chainspec.GenesisBlockByChainName(networkname.Mainnet)
-	_, _, err = core.WriteGenesisBlock(tx, genesis, nil, datadir.New(t.TempDir()), logger)
+	_, _, err = core.WriteGenesisBlock(context.Background(), tx, genesis, nil, nil, datadir.New(t.TempDir()), logger)
 	require.NoError(t, err)
 	seq, err := tx.ReadSequence(kv.EthTx)
 	require.NoError(t, err)
 	require.Equal(t, uint64(2), seq)
 
-	_, _, err = core.WriteGenesisBlock(tx, genesis, nil, datadir.New(t.TempDir()), logger)
+	_, _, err = core.WriteGenesisBlock(context.Background(), tx, genesis, nil, nil, datadir.New(t.TempDir()), logger)
 	require.NoError(t, err)
 	seq, err = tx.ReadSequence(kv.EthTx)
 	require.NoError(t, err)
 	require.Equal(t, uint64(2), seq)
 }
 
+func TestWriteGenesisBlockWithoutAlloc(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	for _, network := range []string{networkname.Mainnet, networkname.Sepolia, networkname.Gnosis, networkname.Chiado} {
+		t.Run(network, func(t *testing.T) {
+			t.Parallel()
+			logger := log.New()
+			genesis := chainspec.GenesisBlockByChainName(network)
+			db := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+			tx, err := db.BeginRw(context.Background())
+			require.NoError(t, err)
+			defer tx.Rollback()
+
+			_, block, err := core.WriteGenesisBlock(context.Background(), tx, genesis, nil, nil, datadir.New(t.TempDir()), logger)
+			require.NoError(t, err)
+			require.Equal(t, *chainspec.GenesisHashByChainName(network), block.Hash())
+			stateRoot, ok := chainspec.GenesisStateRootByChainName(network)
+			require.True(t, ok)
+			require.Equal(t, stateRoot, block.Root())
+
+			// execution materializes the genesis state with GenesisToBlock, it must arrive at the written root
+			executed, _, err := core.GenesisToBlock(context.Background(), genesis, datadir.New(t.TempDir()), logger)
+			require.NoError(t, err)
+			require.Equal(t, block.Root(), executed.Root())
+			require.Equal(t, block.Hash(), executed.Hash())
+
+			_, again, err := core.WriteGenesisBlock(context.Background(), tx, genesis, nil, nil, datadir.New(t.TempDir()), logger)
+			require.NoError(t, err)
+			require.Equal(t, block.Hash(), again.Hash())
+		})
+	}
+}
+
+func TestWriteGenesisBlockWithoutAllocMismatch(t *testing.T) {
+	t.Parallel()
+	logger := log.New()
+	spec := chainspec.GenesisBlockByChainName(networkname.Chiado)
+	withAlloc := func(edit func(alloc types.GenesisAlloc)) *types.Genesis {
+		g := *spec
+		g.Alloc = make(types.GenesisAlloc, len(spec.Alloc))
+		for addr, account := range spec.Alloc {
+			g.Alloc[addr] = account
+		}
+		edit(g.Alloc)
+		return &g
+	}
+	first := common.SortedKeys(spec.Alloc)[0]
+	tests := map[string]*types.Genesis{
+		"corrupted": withAlloc(func(alloc types.GenesisAlloc) {
+			account := alloc[first]
+			account.Nonce++
+			alloc[first] = account
+		}),
+		"mismatched": withAlloc(func(alloc types.GenesisAlloc) {
+			alloc[common.HexToAddress("0x00000000000000000000000000000000000000aa")] = types.GenesisAccount{Balance: common.Big1}
+		}),
+	}
+	for name, genesis := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			db := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+			tx, err := db.BeginRw(context.Background())
+			require.NoError(t, err)
+			defer tx.Rollback()
+
+			// only the embedded spec is written with the recorded state root, an edited alloc is applied and hashed
+			_, block, err := core.WriteGenesisBlock(context.Background(), tx, spec, nil, nil, datadir.New(t.TempDir()), logger)
+			require.NoError(t, err)
+			require.Equal(t, chainspec.ChiadoGenesisHash, block.Hash())
+
+			_, _, err = core.WriteGenesisBlock(context.Background(), tx, genesis, nil, nil, datadir.New(t.TempDir()), logger)
+			var mismatch *core.GenesisMismatchError
+			require.ErrorAs(t, err, &mismatch)
+			require.Equal(t, chainspec.ChiadoGenesisHash, mismatch.Stored)
+			require.NotEqual(t, chainspec.ChiadoGenesisHash, mismatch.New)
+			require.False(t, mismatch.SameChain)
+		})
+	}
+}
+
+func TestWriteGenesisBlockWithoutAllocExecution(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	require := require.New(t)
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	spec := chainspec.GenesisBlockByChainName(networkname.Sepolia)
+
+	// a copy of the spec is not trusted, its alloc is applied to compute the state root
+	copied := *spec
+	withAlloc := mock.MockWithGenesis(t, &copied, key, false)
+	// the spec itself is written with the state root from chainspec, the alloc is only applied by the execution of block 0
+	m := mock.MockWithGenesis(t, spec, key, false)
+	require.Equal(chainspec.SepoliaGenesisStateRoot, m.Genesis.Root())
+	require.Equal(withAlloc.Genesis.Hash(), m.Genesis.Hash())
+
+	// the blocks are generated on the state of the alloc-applied path, executing them checks every state root
+	const blocks = 3
+	chainPack, err := core.GenerateChain(withAlloc.ChainConfig, withAlloc.Genesis, withAlloc.Engine, withAlloc.DB, blocks, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+	})
+	require.NoError(err)
+	require.NoError(withAlloc.InsertChain(chainPack))
+	require.NoError(m.InsertChain(chainPack))
+
+	tx, err := m.DB.BeginTemporalRo(context.Background())
+	require.NoError(err)
+	defer tx.Rollback()
+	reader, err := rpchelper.CreateHistoryStateReader(tx, blocks, 0, rawdbv3.TxNums)
+	require.NoError(err)
+	executed := state.New(reader)
+	for addr, account := range spec.Alloc {
+		balance, err := executed.GetBalance(addr)
+		require.NoError(err)
+		require.Equal(account.Balance, balance.ToBig(), addr)
+	}
+}
+
+func TestGenesisMismatchDiff(t *testing.T) {
+	t.Parallel()
+	logger := log.New()
+	db := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	_, _, err = core.WriteGenesisBlock(context.Background(), tx, chainspec.MainnetGenesisBlock(), nil, nil, datadir.New(t.TempDir()), logger)
+	require.NoError(t, err)
+
+	t.Run("different chain", func(t *testing.T) {
+		_, _, err := core.WriteGenesisBlock(context.Background(), tx, chainspec.SepoliaGenesisBlock(), nil, nil, datadir.New(t.TempDir()), logger)
+		var mismatch *core.GenesisMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, chainspec.MainnetGenesisHash, mismatch.Stored)
+		assert.Equal(t, chainspec.SepoliaGenesisHash, mismatch.New)
+		assert.False(t, mismatch.SameChain)
+		assert.Contains(t, mismatch.ConfigDiff, "chainId: stored=1 supplied=11155111")
+		assert.Contains(t, err.Error(), "different chain id or state root")
+		assert.Contains(t, err.Error(), "--chain=mainnet")
+	})
+
+	t.Run("same chain, incompatible config", func(t *testing.T) {
+		var config chain.Config
+		require.NoError(t, copier.Copy(&config, chainspec.MainnetChainConfig))
+		config.LondonBlock = big.NewInt(0)
+		genesis := chainspec.MainnetGenesisBlock()
+		genesis.Config = &config
+
+		_, _, err := core.WriteGenesisBlock(context.Background(), tx, genesis, nil, nil, datadir.New(t.TempDir()), logger)
+		var mismatch *core.GenesisMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, chainspec.MainnetGenesisHash, mismatch.Stored)
+		assert.True(t, mismatch.SameChain)
+		assert.Equal(t, []string{"londonBlock: stored=12965000 supplied=0"}, mismatch.ConfigDiff)
+		assert.Contains(t, err.Error(), "likely an incompatible config")
+	})
+}
+
 func TestAllocConstructor(t *testing.T) {
 	t.Parallel()
 	require := require.New(t)
@@ -139,12 +301,18 @@ func TestAllocConstructor(t *testing.T) {
 	// and its 1st storage to 0x01c9.
 	deploymentCode := common.FromHex("602a5f556101c960015560048060135f395ff35f355f55")
 
+	// This deployment code copies the 32-byte argument appended to it into the contract's 2nd storage.
+	deploymentCodeWithArg := common.FromHex("602060185f395f516002556004806014" + "5f395ff35f355f55")
+	arg := common.HexToHash("0xdeadbeef")
+
 	funds := big.NewInt(1000000000)
 	address := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	addressWithArg := common.HexToAddress("0x1000000000000000000000000000000000000002")
 	genSpec := &types.Genesis{
 		Config: chain.AllProtocolChanges,
 		Alloc: types.GenesisAlloc{
-			address: {Constructor: deploymentCode, Balance: funds},
+			address:        {Constructor: deploymentCode, Balance: funds},
+			addressWithArg: {Constructor: deploymentCodeWithArg, ConstructorArgs: arg[:], ConstructorGas: 100_000, Balance: funds},
 		},
 	}
 
@@ -174,6 +342,104 @@ func TestAllocConstructor(t *testing.T) {
 	storage1 := &uint256.Int{}
 	state.GetState(address, key1, storage1)
 	assert.Equal(uint256.NewInt(0x01c9), storage1)
+
+	code, err = state.GetCode(addressWithArg)
+	require.NoError(err)
+	assert.Equal(common.FromHex("5f355f55"), code)
+	key2 := common.HexToHash("0000000000000000000000000000000000000000000000000000000000000002")
+	storage2 := &uint256.Int{}
+	state.GetState(addressWithArg, key2, storage2)
+	assert.Equal(uint256.NewInt(0xdeadbeef), storage2)
+}
+
+func TestAllocConstructorOutOfGas(t *testing.T) {
+	t.Parallel()
+	// JUMPDEST PUSH0 JUMP: loops until the gas is exhausted
+	address := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	genSpec := &types.Genesis{
+		Config: chain.AllProtocolChanges,
+		Alloc: types.GenesisAlloc{
+			address: {Constructor: common.FromHex("5b5f56"), ConstructorGas: 100_000, Balance: big.NewInt(1)},
+		},
+	}
+
+	db := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	_, _, err = core.WriteGenesisBlock(context.Background(), tx, genSpec, nil, nil, datadir.New(t.TempDir()), log.New())
+	require.ErrorContains(t, err, "1000000000000000000000000000000000000001")
+	require.ErrorContains(t, err, "constructor ran out of gas (limit 100000)")
+}
+
+func TestAllocConstructorJSON(t *testing.T) {
+	t.Parallel()
+	var account types.GenesisAccount
+	require.NoError(t, json.Unmarshal([]byte(`{"balance": "1", "constructor": "0x6000"}`), &account))
+	require.Empty(t, account.ConstructorArgs)
+	require.Zero(t, account.ConstructorGas)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"balance": "1", "constructor": "0x6000", "constructorArgs": "0x01", "constructorGas": "0x186a0"}`), &account))
+	require.Equal(t, []byte{0x01}, account.ConstructorArgs)
+	require.Equal(t, uint64(100_000), account.ConstructorGas)
+	enc, err := json.Marshal(account)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"balance": "0x1", "constructor": "0x6000", "constructorArgs": "0x01", "constructorGas": "0x186a0"}`, string(enc))
+}
+
+func TestReconstructGenesisRoundTrip(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := context.Background()
+
+	funds := big.NewInt(1000000000)
+	address := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	contract := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	genSpec := &types.Genesis{
+		Config:    chain.AllProtocolChanges,
+		Timestamp: 1_700_000_000,
+		ExtraData: []byte("round trip"),
+		GasLimit:  30_000_000,
+		Alloc: types.GenesisAlloc{
+			address: {Balance: funds},
+			contract: {
+				Balance: big.NewInt(1),
+				Code:    common.FromHex("5f355f55"),
+				Storage: map[common.Hash]common.Hash{common.HexToHash("0x01"): common.HexToHash("0x2a")},
+			},
+		},
+	}
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	m := mock.MockWithGenesis(t, genSpec, key, false)
+
+	tx, err := m.DB.BeginTemporalRo(ctx)
+	require.NoError(err)
+	defer tx.Rollback()
+
+	read, err := core.ReconstructGenesis(tx, m.BlockReader)
+	require.NoError(err)
+	require.Equal(genSpec.Timestamp, read.Timestamp)
+	require.Equal(genSpec.ExtraData, read.ExtraData)
+	require.Equal(genSpec.GasLimit, read.GasLimit)
+	require.Equal(genSpec.Config.ChainID, read.Config.ChainID)
+	require.Len(read.Alloc, 2)
+
+	alloc, err := core.ReadGenesisAlloc(tx, m.BlockReader.TxnumReader(ctx))
+	require.NoError(err)
+	require.Equal(funds, alloc[address].Balance)
+	require.Equal(genSpec.Alloc[contract].Code, alloc[contract].Code)
+	require.Equal(genSpec.Alloc[contract].Storage, alloc[contract].Storage)
+
+	for _, readAlloc := range []types.GenesisAlloc{read.Alloc, alloc} {
+		read.Alloc = readAlloc
+		db := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+		rwTx, err := db.BeginRw(ctx)
+		require.NoError(err)
+		_, block, err := core.WriteGenesisBlock(ctx, rwTx, read, nil, nil, datadir.New(t.TempDir()), log.New())
+		rwTx.Rollback()
+		require.NoError(err)
+		require.Equal(m.Genesis.Hash(), block.Hash())
+	}
 }
 
 // See https://github.com/erigontech/erigon/pull/11264
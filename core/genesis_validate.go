@@ -0,0 +1,226 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/polygon/bor/borcfg"
+)
+
+// GenesisProblem describes a single defect found in a genesis specification.
+// Field is a JSON-style path to the offending value, e.g. "alloc.0x..balance" or "config.chainId".
+type GenesisProblem struct {
+	Field string
+	Msg   string
+}
+
+func (p GenesisProblem) Error() string {
+	return p.Field + ": " + p.Msg
+}
+
+// genesisProblemsError joins problems into a single error listing every one of them, or returns nil.
+func genesisProblemsError(problems []GenesisProblem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	errs := make([]error, len(problems))
+	for i, p := range problems {
+		errs[i] = p
+	}
+	return fmt.Errorf("invalid genesis: %w", errors.Join(errs...))
+}
+
+// ValidateGenesis checks a decoded genesis specification for mistakes that would otherwise surface
+// much later as a state root mismatch or a consensus failure. It returns every problem found rather than stopping at the first.
+//
+// Checks that can only be made on the raw JSON (malformed balances, alloc addresses that collide after case normalization)
+// are done by ValidateGenesisJSON.
+func ValidateGenesis(g *types.Genesis) []GenesisProblem {
+	var problems []GenesisProblem
+	report := func(field, format string, args ...any) {
+		problems = append(problems, GenesisProblem{Field: field, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	if g.AllocFile != "" && len(g.Alloc) > 0 {
+		report("allocFile", "alloc and allocFile are mutually exclusive")
+	}
+	for _, addr := range sortedAllocAddresses(g.Alloc) {
+		account := g.Alloc[addr]
+		field := "alloc." + addr.Hex()
+		if account.Balance != nil {
+			if account.Balance.Sign() < 0 {
+				report(field+".balance", "negative balance %v", account.Balance)
+			} else if account.Balance.BitLen() > 256 {
+				report(field+".balance", "balance %v overflows 256 bits", account.Balance)
+			}
+		}
+		if len(account.Constructor) > 0 && len(account.Code) > 0 {
+			report(field, "both constructor and code are set")
+		}
+		if len(account.Constructor) == 0 && (len(account.ConstructorArgs) > 0 || account.ConstructorGas > 0) {
+			report(field, "constructorArgs or constructorGas set without constructor")
+		}
+	}
+
+	config := g.Config
+	if config == nil {
+		report("config", "%v", types.ErrGenesisNoConfig)
+		return problems
+	}
+	if config.ChainID == nil || config.ChainID.Sign() == 0 {
+		report("config.chainId", "must be set and non-zero")
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		report("config", "%v", err)
+	}
+	forkTimes := []struct {
+		name string
+		time *big.Int
+	}{
+		{"shanghaiTime", config.ShanghaiTime},
+		{"cancunTime", config.CancunTime},
+		{"pragueTime", config.PragueTime},
+		{"osakaTime", config.OsakaTime},
+		{"bpo1Time", config.Bpo1Time},
+		{"bpo2Time", config.Bpo2Time},
+		{"bpo3Time", config.Bpo3Time},
+		{"bpo4Time", config.Bpo4Time},
+		{"bpo5Time", config.Bpo5Time},
+	}
+	last := -1
+	for i, fork := range forkTimes {
+		if fork.time == nil {
+			continue
+		}
+		if last >= 0 && forkTimes[last].time.Cmp(fork.time) > 0 {
+			report("config."+fork.name, "unsupported fork ordering: %s enabled at %v, but %s enabled at %v",
+				forkTimes[last].name, forkTimes[last].time, fork.name, fork.time)
+		}
+		last = i
+	}
+	for _, fork := range common.SortedKeys(config.BlobSchedule) {
+		field := "config.blobSchedule." + fork
+		forkTime, known := config.BlobScheduleForkTime(fork)
+		if !known {
+			report(field, "unknown fork")
+			continue
+		}
+		if forkTime == nil {
+			report(field, "fork is not scheduled, the entry would never apply")
+		}
+		blobConfig := config.BlobSchedule[fork]
+		if blobConfig == nil {
+			report(field, "must not be null")
+			continue
+		}
+		if blobConfig.Target > blobConfig.Max {
+			report(field, "target %d exceeds max %d", blobConfig.Target, blobConfig.Max)
+		}
+		if blobConfig.BaseFeeUpdateFraction == 0 {
+			report(field+".baseFeeUpdateFraction", "must be non-zero")
+		}
+	}
+
+	if config.Clique != nil && config.Clique.Epoch == 0 {
+		report("config.clique.epoch", "must be non-zero")
+	}
+	if config.Bor != nil || config.BorJSON != nil {
+		borConfig, ok := config.Bor.(*borcfg.BorConfig)
+		if !ok && config.Bor == nil {
+			borConfig = &borcfg.BorConfig{}
+			if err := json.Unmarshal(config.BorJSON, borConfig); err != nil {
+				report("config.bor", "%v", err)
+				borConfig = nil
+			}
+		}
+		if borConfig != nil {
+			if len(borConfig.Sprint) == 0 {
+				report("config.bor.sprint", "must be set")
+			}
+			if _, ok := borConfig.Sprint["0"]; len(borConfig.Sprint) > 0 && !ok {
+				report("config.bor.sprint", "no sprint length for block 0")
+			}
+			for _, from := range common.SortedKeys(borConfig.Sprint) {
+				if borConfig.Sprint[from] == 0 {
+					report("config.bor.sprint."+from, "sprint length must be non-zero")
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// ValidateGenesisJSON validates a genesis file before it is decoded: each alloc entry is checked individually
+// so that a malformed balance or nonce is reported against its address, and addresses that only differ in case are reported
+// as duplicates instead of silently overwriting each other. If the file decodes, ValidateGenesis problems are appended.
+func ValidateGenesisJSON(data []byte) []GenesisProblem {
+	var problems []GenesisProblem
+	var raw struct {
+		Alloc     map[string]json.RawMessage `json:"alloc"`
+		AllocFile string                     `json:"allocFile"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return []GenesisProblem{{Field: "genesis", Msg: err.Error()}}
+	}
+	if raw.Alloc == nil {
+		msg := "missing"
+		if raw.AllocFile != "" {
+			msg = "missing, must be {} when allocFile is set"
+		}
+		return []GenesisProblem{{Field: "alloc", Msg: msg}}
+	}
+
+	seen := make(map[string]string, len(raw.Alloc))
+	allocOk := true
+	for _, key := range common.SortedKeys(raw.Alloc) {
+		field := "alloc." + key
+		normalized := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(key, "0x"), "0X"))
+		if _, err := hexutil.Decode("0x" + normalized); err != nil || len(normalized) != 40 {
+			problems = append(problems, GenesisProblem{Field: field, Msg: "invalid address"})
+			allocOk = false
+			continue
+		}
+		if prev, ok := seen[normalized]; ok {
+			problems = append(problems, GenesisProblem{Field: field, Msg: fmt.Sprintf("duplicate of alloc.%s", prev)})
+			allocOk = false
+			continue
+		}
+		seen[normalized] = key
+		var account types.GenesisAccount
+		if err := json.Unmarshal(raw.Alloc[key], &account); err != nil {
+			problems = append(problems, GenesisProblem{Field: field, Msg: err.Error()})
+			allocOk = false
+		}
+	}
+	if !allocOk {
+		return problems
+	}
+
+	var g types.Genesis
+	if err := json.Unmarshal(data, &g); err != nil {
+		return append(problems, GenesisProblem{Field: "genesis", Msg: err.Error()})
+	}
+	return append(problems, ValidateGenesis(&g)...)
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/chain/params"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/execution/chainspec"
+	polychain "github.com/erigontech/erigon/polygon/chain"
+)
+
+func problemFields(problems []core.GenesisProblem) []string {
+	fields := make([]string, len(problems))
+	for i, p := range problems {
+		fields[i] = p.Field
+	}
+	return fields
+}
+
+func TestValidateGenesisKnownChains(t *testing.T) {
+	t.Parallel()
+	for _, g := range []*types.Genesis{
+		chainspec.MainnetGenesisBlock(),
+		chainspec.SepoliaGenesisBlock(),
+		chainspec.GnosisGenesisBlock(),
+		polychain.AmoyGenesisBlock(),
+		polychain.BorMainnetGenesisBlock(),
+	} {
+		require.Empty(t, core.ValidateGenesis(g), g.Config.ChainName)
+	}
+}
+
+func TestValidateGenesisJSON(t *testing.T) {
+	t.Parallel()
+	const config = `"config": {"chainId": 1337, "homesteadBlock": 0, "eip150Block": 0, "eip155Block": 0, "ethash": {}}`
+	tests := []struct {
+		name   string
+		json   string
+		fields []string
+	}{
+		{
+			name:   "valid",
+			json:   `{` + config + `, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {"0x00000000000000000000000000000000000000aa": {"balance": "0x10"}}}`,
+			fields: []string{},
+		},
+		{
+			name:   "malformed balance",
+			json:   `{` + config + `, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {"00000000000000000000000000000000000000aa": {"balance": "ten"}}}`,
+			fields: []string{"alloc.00000000000000000000000000000000000000aa"},
+		},
+		{
+			name:   "malformed nonce",
+			json:   `{` + config + `, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {"00000000000000000000000000000000000000aa": {"balance": "1", "nonce": "0xzz"}}}`,
+			fields: []string{"alloc.00000000000000000000000000000000000000aa"},
+		},
+		{
+			name: "duplicate address differing in case",
+			json: `{` + config + `, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {
+				"0x00000000000000000000000000000000000000aa": {"balance": "1"},
+				"0x00000000000000000000000000000000000000AA": {"balance": "2"}}}`,
+			fields: []string{"alloc.0x00000000000000000000000000000000000000aa"},
+		},
+		{
+			name:   "invalid address",
+			json:   `{` + config + `, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {"0x1234": {"balance": "1"}}}`,
+			fields: []string{"alloc.0x1234"},
+		},
+		{
+			name:   "zero chain id",
+			json:   `{"config": {"chainId": 0, "ethash": {}}, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {}}`,
+			fields: []string{"config.chainId"},
+		},
+		{
+			name:   "clique without epoch",
+			json:   `{"config": {"chainId": 5, "clique": {"period": 15}}, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {}}`,
+			fields: []string{"config.clique.epoch"},
+		},
+		{
+			name:   "bor without sprint",
+			json:   `{"config": {"chainId": 137, "bor": {"period": {"0": 2}}}, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {}}`,
+			fields: []string{"config.bor.sprint"},
+		},
+		{
+			name:   "bor sprint not starting at genesis",
+			json:   `{"config": {"chainId": 137, "bor": {"sprint": {"100": 16, "200": 0}}}, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {}}`,
+			fields: []string{"config.bor.sprint", "config.bor.sprint.200"},
+		},
+		{
+			name:   "fork timestamps out of order",
+			json:   `{"config": {"chainId": 1, "shanghaiTime": 100, "cancunTime": 50, "pragueTime": 200}, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {}}`,
+			fields: []string{"config.cancunTime"},
+		},
+		{
+			name:   "fork blocks out of order",
+			json:   `{"config": {"chainId": 1, "homesteadBlock": 10, "eip150Block": 5}, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {}}`,
+			fields: []string{"config"},
+		},
+		{
+			name: "blob schedule",
+			json: `{"config": {"chainId": 1, "shanghaiTime": 0, "cancunTime": 0, "blobSchedule": {
+				"cancun": {"target": 4, "max": 2, "baseFeeUpdateFraction": 0},
+				"prague": {"target": 6, "max": 9, "baseFeeUpdateFraction": 5007716},
+				"verkle": {"target": 1, "max": 1, "baseFeeUpdateFraction": 1}}}, "gasLimit": "0x1c9c380", "difficulty": "0x1", "alloc": {}}`,
+			fields: []string{"config.blobSchedule.cancun", "config.blobSchedule.cancun.baseFeeUpdateFraction", "config.blobSchedule.prague", "config.blobSchedule.verkle"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.fields, problemFields(core.ValidateGenesisJSON([]byte(tt.json))))
+		})
+	}
+}
+
+func TestValidateGenesisReportsEveryProblem(t *testing.T) {
+	t.Parallel()
+	addr := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	g := &types.Genesis{
+		Config: &chain.Config{
+			HomesteadBlock:        big.NewInt(10),
+			TangerineWhistleBlock: big.NewInt(5),
+			Clique:                &chain.CliqueConfig{Period: 15},
+		},
+		Alloc: types.GenesisAlloc{
+			addr: {Balance: big.NewInt(-1), Code: []byte{0x00}, Constructor: []byte{0x00}},
+		},
+	}
+	require.Equal(t, []string{
+		"alloc." + addr.Hex() + ".balance",
+		"alloc." + addr.Hex(),
+		"config.chainId",
+		"config",
+		"config.clique.epoch",
+	}, problemFields(core.ValidateGenesis(g)))
+
+	db := temporaltest.NewTestDB(t, datadir.New(t.TempDir()))
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+	_, _, err = core.WriteGenesisBlock(context.Background(), tx, g, nil, nil, datadir.New(t.TempDir()), log.New())
+	require.ErrorContains(t, err, "negative balance")
+	require.ErrorContains(t, err, "both constructor and code are set")
+	require.ErrorContains(t, err, "config.chainId")
+	require.ErrorContains(t, err, "unsupported fork ordering")
+	require.ErrorContains(t, err, "config.clique.epoch")
+	var problem core.GenesisProblem
+	require.ErrorAs(t, err, &problem)
+}
+
+func TestValidateGenesisWithOverrides(t *testing.T) {
+	t.Parallel()
+	// the osaka entry only applies once osakaTime is set by the override
+	genesis := func() *types.Genesis {
+		g := core.DevnetGenesisBlock(1, "dev", 30_000_000)
+		g.Config.BlobSchedule = map[string]*params.BlobConfig{
+			"osaka": {Target: 6, Max: 9, BaseFeeUpdateFraction: params.DefaultPragueBlobConfig.BaseFeeUpdateFraction},
+		}
+		return g
+	}
+
+	_, _, err := core.CommitGenesisBlockWithOverride(temporaltest.NewTestDB(t, datadir.New(t.TempDir())), genesis(), nil, datadir.New(t.TempDir()), log.New())
+	require.ErrorContains(t, err, "config.blobSchedule.osaka: fork is not scheduled")
+
+	config, _, err := core.CommitGenesisBlockWithOverride(temporaltest.NewTestDB(t, datadir.New(t.TempDir())), genesis(), big.NewInt(0), datadir.New(t.TempDir()), log.New())
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), config.GetMaxBlobsPerBlock(0))
+}
@@ -23,18 +23,24 @@ import (
 	"bytes"
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"math/big"
 	"slices"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/c2h5oh/datasize"
 	"github.com/holiman/uint256"
+	"github.com/jinzhu/copier"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/erigontech/erigon-db/rawdb"
 	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/chain/networkname"
 	"github.com/erigontech/erigon-lib/chain/params"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/datadir"
@@ -51,6 +57,7 @@ import (
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/tracing"
+	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/execution/chainspec"
 )
 
@@ -58,14 +65,107 @@ import (
 // genesis block with an incompatible one.
 type GenesisMismatchError struct {
 	Stored, New common.Hash
+	// SameChain is set when both genesis blocks share chain id and state root. It is a hint for the
+	// error message only: the alloc is the same, but the genesis header fields may differ as well as the
+	// chain config, and two unrelated chains may share both.
+	SameChain bool
+	// ConfigDiff lists the chain config fields that differ, as "field: stored=X supplied=Y".
+	ConfigDiff []string
 }
 
 func (e *GenesisMismatchError) Error() string {
-	config := chainspec.ChainConfigByGenesisHash(e.Stored)
-	if config == nil {
-		return fmt.Sprintf("database contains incompatible genesis (have %x, new %x)", e.Stored, e.New)
+	var msg string
+	if e.SameChain {
+		msg = fmt.Sprintf("database contains genesis with the same chain id and state root but a different hash, likely an incompatible config (have %x, new %x)", e.Stored, e.New)
+	} else {
+		msg = fmt.Sprintf("database contains genesis with a different chain id or state root (have %x, new %x)", e.Stored, e.New)
+		if name, ok := chainspec.ChainNameByGenesisHash(e.Stored); ok {
+			msg += fmt.Sprintf(", try with --chain=%s", name)
+		}
+	}
+	if len(e.ConfigDiff) > 0 {
+		msg += ": " + strings.Join(e.ConfigDiff, ", ")
+	}
+	return msg
+}
+
+// newGenesisMismatchError compares the stored genesis header and chain config against the supplied block and config.
+func newGenesisMismatchError(tx kv.Tx, storedHash common.Hash, block *types.Block, config *chain.Config) (*GenesisMismatchError, error) {
+	mismatch := &GenesisMismatchError{Stored: storedHash, New: block.Hash()}
+	storedCfg, err := ReadChainConfig(tx, storedHash)
+	if err != nil {
+		return nil, err
+	}
+	if storedCfg != nil {
+		if mismatch.ConfigDiff, err = chainConfigDiff(storedCfg, config); err != nil {
+			return nil, err
+		}
+	}
+	storedHeader := rawdb.ReadHeader(tx, storedHash, 0)
+	mismatch.SameChain = storedCfg != nil && storedHeader != nil &&
+		storedHeader.Root == block.Root() &&
+		storedCfg.ChainID != nil && config.ChainID != nil && storedCfg.ChainID.Cmp(config.ChainID) == 0
+	return mismatch, nil
+}
+
+// chainConfigDiff returns the JSON fields which differ between stored and supplied, in alphabetical order.
+func chainConfigDiff(stored, supplied *chain.Config) ([]string, error) {
+	storedFields, err := chainConfigFields(stored)
+	if err != nil {
+		return nil, err
+	}
+	suppliedFields, err := chainConfigFields(supplied)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]struct{}, len(storedFields))
+	for name := range storedFields {
+		names[name] = struct{}{}
+	}
+	for name := range suppliedFields {
+		names[name] = struct{}{}
+	}
+	var diff []string
+	for _, name := range common.SortedKeys(names) {
+		storedVal, storedOk := storedFields[name]
+		suppliedVal, suppliedOk := suppliedFields[name]
+		if storedVal == suppliedVal && storedOk == suppliedOk {
+			continue
+		}
+		if !storedOk {
+			storedVal = "unset"
+		}
+		if !suppliedOk {
+			suppliedVal = "unset"
+		}
+		diff = append(diff, fmt.Sprintf("%s: stored=%s supplied=%s", name, storedVal, suppliedVal))
+	}
+	return diff, nil
+}
+
+func chainConfigFields(config *chain.Config) (map[string]string, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if config.Bor != nil {
+		if raw["bor"], err = json.Marshal(config.Bor); err != nil {
+			return nil, err
+		}
+	}
+	fields := make(map[string]string, len(raw))
+	for name, value := range raw {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, value); err != nil {
+			return nil, err
+		}
+		fields[name] = compact.String()
 	}
-	return fmt.Sprintf("database contains incompatible genesis (try with --chain=%s)", config.ChainName)
+	return fields, nil
 }
 
 // CommitGenesisBlock writes or updates the genesis block in db.
@@ -91,7 +191,7 @@ func CommitGenesisBlockWithOverride(db kv.RwDB, genesis *types.Genesis, override
 		return nil, nil, err
 	}
 	defer tx.Rollback()
-	c, b, err := WriteGenesisBlock(tx, genesis, overrideOsakaTime, dirs, logger)
+	c, b, err := WriteGenesisBlock(context.Background(), tx, genesis, overrideOsakaTime, nil, dirs, logger)
 	if err != nil {
 		return c, b, err
 	}
@@ -107,15 +207,46 @@ func configOrDefault(g *types.Genesis, genesisHash common.Hash) *chain.Config {
 		return g.Config
 	}
 
-	config := chainspec.ChainConfigByGenesisHash(genesisHash)
-	if config != nil {
+	if config, ok := chainspec.ChainConfigByGenesisHash(genesisHash); ok {
 		return config
 	} else {
 		return chain.AllProtocolChanges
 	}
 }
 
-func WriteGenesisBlock(tx kv.RwTx, genesis *types.Genesis, overrideOsakaTime *big.Int, dirs datadir.Dirs, logger log.Logger) (*chain.Config, *types.Block, error) {
+// WriteGenesisBlock is CommitGenesisBlockWithOverride within an existing transaction. Writing of a
+// custom genesis allocation can be cancelled via ctx, the next call then starts over from scratch.
+// A custom genesis is checked with ValidateGenesis first and rejected with an error listing every problem found,
+// the embedded specs of the known chains are trusted.
+// The config override, if any, is authoritative over the config of the genesis and the stored one, it's stored to be
+// applied on the next calls without one, and rejected with an error if it reschedules a fork the synced chain is past.
+func WriteGenesisBlock(ctx context.Context, tx kv.RwTx, genesis *types.Genesis, overrideOsakaTime *big.Int, override *ConfigOverride, dirs datadir.Dirs, logger log.Logger) (*chain.Config, *types.Block, error) {
+	newOverride := override != nil
+	if !newOverride {
+		var err error
+		if override, err = ReadConfigOverride(tx); err != nil {
+			return nil, nil, err
+		}
+	}
+	if genesis != nil && genesis.Config != nil && !isChainspecGenesis(genesis) {
+		if err := validateGenesisWithOverrides(genesis, overrideOsakaTime, override); err != nil {
+			return genesis.Config, nil, err
+		}
+	}
+	if genesis != nil && genesis.AllocFile != "" {
+		// the hash of the alloc file is stored with the genesis when the database is initialized
+		stored, err := ReadGenesis(tx)
+		if err != nil {
+			return genesis.Config, nil, err
+		}
+		if stored == nil {
+			withHash, err := genesisWithAllocFileHash(genesis)
+			if err != nil {
+				return genesis.Config, nil, err
+			}
+			genesis = withHash
+		}
+	}
 	if err := WriteGenesisIfNotExist(tx, genesis); err != nil {
 		return nil, nil, err
 	}
@@ -130,24 +261,42 @@ func WriteGenesisBlock(tx kv.RwTx, genesis *types.Genesis, overrideOsakaTime *bi
 		return nil, nil, storedErr
 	}
 
-	applyOverrides := func(config *chain.Config) {
+	applyOverrides := func(config *chain.Config) error {
 		if overrideOsakaTime != nil {
 			config.OsakaTime = overrideOsakaTime
 		}
+		if override != nil {
+			return override.Apply(config)
+		}
+		return nil
+	}
+	writeOverride := func() error {
+		if !newOverride {
+			return nil
+		}
+		if !override.Empty() {
+			logger.Info("Applied chain config override", "config", string(override.raw))
+		}
+		return WriteConfigOverride(tx, override)
 	}
 
 	if (storedHash == common.Hash{}) {
 		custom := true
 		if genesis == nil {
 			logger.Info("Writing main-net genesis block")
-			genesis = chainspec.MainnetGenesisBlock()
+			genesis = chainspec.GenesisBlockByChainName(networkname.Mainnet)
 			custom = false
 		}
-		applyOverrides(genesis.Config)
-		block, _, err1 := write(tx, genesis, dirs, logger)
+		if err := applyOverrides(genesis.Config); err != nil {
+			return genesis.Config, nil, err
+		}
+		block, err1 := write(ctx, tx, genesis, dirs, logger)
 		if err1 != nil {
 			return genesis.Config, nil, err1
 		}
+		if err := writeOverride(); err != nil {
+			return genesis.Config, nil, err
+		}
 		if custom {
 			logger.Info("Writing custom genesis block", "hash", block.Hash().String())
 		}
@@ -156,13 +305,26 @@ func WriteGenesisBlock(tx kv.RwTx, genesis *types.Genesis, overrideOsakaTime *bi
 
 	// Check whether the genesis block is already written.
 	if genesis != nil {
-		block, _, err1 := GenesisToBlock(genesis, dirs, logger)
-		if err1 != nil {
-			return genesis.Config, nil, err1
+		block, ok := genesisBlockFromChainspec(genesis)
+		if !ok {
+			var err1 error
+			if block, ok, err1 = genesisBlockFromStoredAllocFile(tx, genesis, storedHash); err1 != nil {
+				return genesis.Config, nil, err1
+			}
+		}
+		if !ok {
+			var err1 error
+			if block, err1 = genesisBlock(ctx, genesis, dirs, logger); err1 != nil {
+				return genesis.Config, nil, err1
+			}
 		}
 		hash := block.Hash()
 		if hash != storedHash {
-			return genesis.Config, block, &GenesisMismatchError{Stored: storedHash, New: hash}
+			mismatch, err1 := newGenesisMismatchError(tx, storedHash, block, genesis.Config)
+			if err1 != nil {
+				return genesis.Config, nil, err1
+			}
+			return genesis.Config, block, mismatch
 		}
 	}
 	number := rawdb.ReadHeaderNumber(tx, storedHash)
@@ -175,7 +337,9 @@ func WriteGenesisBlock(tx kv.RwTx, genesis *types.Genesis, overrideOsakaTime *bi
 	}
 	// Get the existing chain configuration.
 	newCfg := configOrDefault(genesis, storedHash)
-	applyOverrides(newCfg)
+	if err := applyOverrides(newCfg); err != nil {
+		return newCfg, nil, err
+	}
 	if err := newCfg.CheckConfigForkOrder(); err != nil {
 		return newCfg, nil, err
 	}
@@ -189,18 +353,31 @@ func WriteGenesisBlock(tx kv.RwTx, genesis *types.Genesis, overrideOsakaTime *bi
 		if err1 != nil {
 			return newCfg, nil, err1
 		}
+		if err := writeOverride(); err != nil {
+			return newCfg, nil, err
+		}
 		return newCfg, storedBlock, nil
 	}
 	// Special case: don't change the existing config of a private chain if no new
 	// config is supplied. This is useful, for example, to preserve DB config created by erigon init.
 	// In that case, only apply the overrides.
-	if genesis == nil && chainspec.ChainConfigByGenesisHash(storedHash) == nil {
-		newCfg = storedCfg
-		applyOverrides(newCfg)
+	if _, known := chainspec.ChainConfigByGenesisHash(storedHash); genesis == nil && !known {
+		newCfg = &chain.Config{}
+		if err := copier.Copy(newCfg, storedCfg); err != nil {
+			return storedCfg, nil, err
+		}
+		if err := applyOverrides(newCfg); err != nil {
+			return newCfg, nil, err
+		}
 	}
 	// Check config compatibility and write the config. Compatibility errors
 	// are returned to the caller unless we're already at block zero.
 	height := rawdb.ReadHeaderNumber(tx, rawdb.ReadHeadHeaderHash(tx))
+	if height != nil && override != nil {
+		if err := checkConfigOverrideCompatible(tx, storedCfg, newCfg, *height); err != nil {
+			return newCfg, storedBlock, err
+		}
+	}
 	if height != nil {
 		compatibilityErr := storedCfg.CheckCompatible(newCfg, *height)
 		if compatibilityErr != nil && *height != 0 && compatibilityErr.RewindTo != 0 {
@@ -210,11 +387,14 @@ func WriteGenesisBlock(tx kv.RwTx, genesis *types.Genesis, overrideOsakaTime *bi
 	if err := WriteChainConfig(tx, storedHash, newCfg); err != nil {
 		return newCfg, nil, err
 	}
+	if err := writeOverride(); err != nil {
+		return newCfg, nil, err
+	}
 	return newCfg, storedBlock, nil
 }
 
-func WriteGenesisState(g *types.Genesis, tx kv.RwTx, dirs datadir.Dirs, logger log.Logger) (*types.Block, *state.IntraBlockState, error) {
-	block, statedb, err := GenesisToBlock(g, dirs, logger)
+func WriteGenesisState(ctx context.Context, g *types.Genesis, tx kv.RwTx, dirs datadir.Dirs, logger log.Logger) (*types.Block, *state.IntraBlockState, error) {
+	block, statedb, err := GenesisToBlock(ctx, g, dirs, logger)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -237,7 +417,7 @@ func MustCommitGenesis(g *types.Genesis, db kv.RwDB, dirs datadir.Dirs, logger l
 		panic(err)
 	}
 	defer tx.Rollback()
-	block, _, err := write(tx, g, dirs, logger)
+	block, err := write(context.Background(), tx, g, dirs, logger)
 	if err != nil {
 		panic(err)
 	}
@@ -248,15 +428,23 @@ func MustCommitGenesis(g *types.Genesis, db kv.RwDB, dirs datadir.Dirs, logger l
 	return block
 }
 
-// Write writes the block and state of a genesis specification to the database.
-// The block is committed as the canonical head block.
-func write(tx kv.RwTx, g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (*types.Block, *state.IntraBlockState, error) {
-	block, statedb, err := WriteGenesisState(g, tx, dirs, logger)
+// Write writes the block of a genesis specification to the database.
+// The block is committed as the canonical head block. The alloc state itself is written by the
+// execution of block 0, here it is only needed for the state root of the block.
+// For a built-in network the alloc is not applied at all, see genesisBlockFromChainspec.
+func write(ctx context.Context, tx kv.RwTx, g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (*types.Block, error) {
+	if block, ok := genesisBlockFromChainspec(g); ok {
+		logger.Info("[genesis] using state root from chainspec, alloc is applied by execution", "chain", g.Config.ChainName, "root", block.Root())
+		return block, WriteGenesisBesideState(block, tx, g)
+	}
+	block, err := genesisBlock(ctx, g, dirs, logger)
 	if err != nil {
-		return block, statedb, err
+		return nil, err
+	}
+	if block.Number().Sign() != 0 {
+		return nil, errors.New("can't commit genesis block with number > 0")
 	}
-	err = WriteGenesisBesideState(block, tx, g)
-	return block, statedb, err
+	return block, WriteGenesisBesideState(block, tx, g)
 }
 
 // Write writes the block a genesis specification to the database.
@@ -317,9 +505,40 @@ var DevnetSignKey = func(address common.Address) *ecdsa.PrivateKey {
 	return DevnetSignPrivateKey
 }
 
-// GenesisToBlock creates the genesis block and writes state of a genesis specification
-// to the given database (or discards it if nil).
-func GenesisToBlock(g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (*types.Block, *state.IntraBlockState, error) {
+// genesisAllocBatchSize is the number of alloc accounts applied to the genesis state between
+// progress reports and cancellation checks. When only the block is needed, each batch is also
+// flushed to the temporary database, see genesisBlock. Batching doesn't affect the resulting
+// state root: the commitment only depends on the final state.
+const genesisAllocBatchSize = 100_000
+
+// DefaultGenesisConstructorGas is the gas available to a genesis alloc constructor that does not set constructorGas.
+const DefaultGenesisConstructorGas = SysCallGasLimit
+
+// GenesisToBlock creates the genesis block and the state of a genesis specification. The returned state
+// holds the whole alloc, as the execution of block 0 commits it. Allocs of up to GenesisInMemAllocLimit accounts
+// are computed in memory, larger ones and alloc files go through a temporary database in dirs.
+func GenesisToBlock(ctx context.Context, g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (*types.Block, *state.IntraBlockState, error) {
+	if g.AllocFile == "" && len(g.Alloc) <= GenesisInMemAllocLimit {
+		return genesisToBlockInMem(ctx, g, genesisAllocBatchSize, logger)
+	}
+	return genesisToBlock(ctx, g, dirs, genesisAllocBatchSize, true /* keepState */, logger)
+}
+
+// genesisBlock is GenesisToBlock for callers which only need the block, e.g. to write or check the genesis header.
+// Big allocs are flushed to the temporary database batch by batch, so memory use is bounded by the batch size
+// rather than by the size of the alloc.
+func genesisBlock(ctx context.Context, g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (*types.Block, error) {
+	if g.AllocFile == "" && len(g.Alloc) <= GenesisInMemAllocLimit {
+		block, _, err := genesisToBlockInMem(ctx, g, genesisAllocBatchSize, logger)
+		return block, err
+	}
+	block, _, err := genesisToBlock(ctx, g, dirs, genesisAllocBatchSize, false /* keepState */, logger)
+	return block, err
+}
+
+// genesisToBlock applies the alloc of g through a temporary database. Unless keepState is set, the returned state
+// is nil: every batch of accounts is flushed to the database and the next one starts from a fresh state.
+func genesisToBlock(ctx context.Context, g *types.Genesis, dirs datadir.Dirs, batchSize int, keepState bool, logger log.Logger) (*types.Block, *state.IntraBlockState, error) {
 	if dirs.SnapDomain == "" {
 		panic("empty `dirs` variable")
 	}
@@ -330,7 +549,6 @@ func GenesisToBlock(g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (*ty
 	var root common.Hash
 	var statedb *state.IntraBlockState // reader behind this statedb is dead at the moment of return, tx is rolled back
 
-	ctx := context.Background()
 	wg, ctx := errgroup.WithContext(ctx)
 	// we may run inside write tx, can't open 2nd write tx in same goroutine
 	wg.Go(func() (err error) {
@@ -376,53 +594,32 @@ func GenesisToBlock(g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (*ty
 
 		//r, w := state.NewDbStateReader(tx), state.NewDbStateWriter(tx, 0)
 		r, w := state.NewReaderV3(sd.AsGetter(tx)), state.NewWriter(sd.AsPutDel(tx), nil, txNum)
-		statedb = state.New(r)
-		statedb.SetTrace(false)
-
-		hasConstructorAllocation := false
-		for _, account := range g.Alloc {
-			if len(account.Constructor) > 0 {
-				hasConstructorAllocation = true
-				break
-			}
-		}
-		// See https://github.com/NethermindEth/nethermind/blob/master/src/Nethermind/Nethermind.Consensus.AuRa/InitializationSteps/LoadGenesisBlockAuRa.cs
-		if hasConstructorAllocation && g.Config.Aura != nil {
-			statedb.CreateAccount(common.Address{}, false)
-		}
-
-		addrs := sortedAllocAddresses(g.Alloc)
-		for _, addr := range addrs {
-			account := g.Alloc[addr]
-
-			balance, overflow := uint256.FromBig(account.Balance)
-			if overflow {
-				panic("overflow at genesis allocs")
-			}
-			statedb.AddBalance(addr, *balance, tracing.BalanceIncreaseGenesisBalance)
-			statedb.SetCode(addr, account.Code)
-			statedb.SetNonce(addr, account.Nonce)
-			var slotVal uint256.Int
-			for key, value := range account.Storage {
-				slotVal.SetBytes(value.Bytes())
-				statedb.SetState(addr, key, slotVal)
-			}
-
-			if len(account.Constructor) > 0 {
-				if _, err = SysCreate(addr, account.Constructor, g.Config, statedb, head); err != nil {
-					return err
+		allocState := &genesisAllocState{statedb: state.New(r), w: w}
+		allocState.statedb.SetTrace(false)
+		if !keepState {
+			allocState.endBatch = func() (*state.IntraBlockState, error) {
+				// the commitment is computed incrementally, the branches computed so far are flushed along with the state
+				if _, err := sd.ComputeCommitment(ctx, true, blockNum, txNum, "genesis"); err != nil {
+					return nil, err
 				}
-			}
-
-			if len(account.Code) > 0 || len(account.Storage) > 0 || len(account.Constructor) > 0 {
-				statedb.SetIncarnation(addr, state.FirstContractIncarnation)
+				if err := sd.Flush(ctx, tx); err != nil {
+					return nil, err
+				}
+				sd.ClearRam(true)
+				statedb := state.New(r)
+				statedb.SetTrace(false)
+				return statedb, nil
 			}
 		}
-		if err = statedb.FinalizeTx(&chain.Rules{}, w); err != nil {
+
+		if err = applyGenesisAlloc(ctx, g, head, allocState, batchSize, logger); err != nil {
 			return err
 		}
+		if keepState {
+			statedb = allocState.statedb
+		}
 
-		rh, err := sd.ComputeCommitment(context.Background(), true, blockNum, txNum, "genesis")
+		rh, err := sd.ComputeCommitment(ctx, true, blockNum, txNum, "genesis")
 		if err != nil {
 			return err
 		}
@@ -439,6 +636,172 @@ func GenesisToBlock(g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (*ty
 	return types.NewBlock(head, nil, nil, nil, withdrawals), statedb, nil
 }
 
+// genesisAllocState is the state applyGenesisAlloc applies the accounts to. Each batch of accounts is
+// finalized to w; if endBatch is set, it is then called to persist the batch, and returns the state
+// the next batch is applied to.
+type genesisAllocState struct {
+	statedb  *state.IntraBlockState
+	w        state.StateWriter
+	endBatch func() (*state.IntraBlockState, error)
+}
+
+// nextBatch finalizes the accounts applied so far, before the next batch is applied.
+func (s *genesisAllocState) nextBatch() error {
+	if err := s.statedb.FinalizeTx(&chain.Rules{}, s.w); err != nil {
+		return err
+	}
+	if s.endBatch == nil {
+		return nil
+	}
+	statedb, err := s.endBatch()
+	if err != nil {
+		return err
+	}
+	s.statedb = statedb
+	return nil
+}
+
+// finish finalizes the accounts of the last batch.
+func (s *genesisAllocState) finish() error {
+	return s.statedb.FinalizeTx(&chain.Rules{}, s.w)
+}
+
+// applyGenesisAlloc applies the alloc of g to st, running constructors, in batches of batchSize accounts.
+func applyGenesisAlloc(ctx context.Context, g *types.Genesis, head *types.Header, st *genesisAllocState, batchSize int, logger log.Logger) (err error) {
+	if g.AllocFile != "" {
+		if len(g.Alloc) > 0 {
+			return errors.New("genesis has both alloc and allocFile")
+		}
+		return applyGenesisAllocFile(ctx, g, head, st, batchSize, logger)
+	}
+
+	hasConstructorAllocation := false
+	for _, account := range g.Alloc {
+		if len(account.Constructor) > 0 {
+			hasConstructorAllocation = true
+			break
+		}
+	}
+	// See https://github.com/NethermindEth/nethermind/blob/master/src/Nethermind/Nethermind.Consensus.AuRa/InitializationSteps/LoadGenesisBlockAuRa.cs
+	if hasConstructorAllocation && g.Config.Aura != nil {
+		st.statedb.CreateAccount(common.Address{}, false)
+	}
+
+	addrs := sortedAllocAddresses(g.Alloc)
+	progress := newGenesisAllocProgress(len(addrs), batchSize, logger)
+	for from := 0; from < len(addrs); from += batchSize {
+		if from > 0 {
+			if err = st.nextBatch(); err != nil {
+				return err
+			}
+			progress.report(from)
+		}
+		batch := addrs[from:min(from+batchSize, len(addrs))]
+		if err = applyGenesisAllocBatch(ctx, g, g.Alloc, batch, head, st.statedb); err != nil {
+			return err
+		}
+	}
+	if err = st.finish(); err != nil {
+		return err
+	}
+	progress.report(len(addrs))
+	return nil
+}
+
+// applyGenesisAllocBatch applies the accounts of alloc listed in batch to statedb.
+func applyGenesisAllocBatch(ctx context.Context, g *types.Genesis, alloc types.GenesisAlloc, batch []common.Address, head *types.Header, statedb *state.IntraBlockState) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var slotVal uint256.Int
+	for _, addr := range batch {
+		account := alloc[addr]
+		balance, overflow := uint256.FromBig(account.Balance)
+		if overflow {
+			return fmt.Errorf("genesis alloc %x: balance overflows 256 bits", addr)
+		}
+		statedb.AddBalance(addr, *balance, tracing.BalanceIncreaseGenesisBalance)
+		statedb.SetCode(addr, account.Code)
+		statedb.SetNonce(addr, account.Nonce)
+		for key, value := range account.Storage {
+			slotVal.SetBytes(value.Bytes())
+			statedb.SetState(addr, key, slotVal)
+		}
+
+		if len(account.Constructor) > 0 {
+			gas := account.ConstructorGas
+			if gas == 0 {
+				gas = DefaultGenesisConstructorGas
+			}
+			data := append(common.CopyBytes(account.Constructor), account.ConstructorArgs...)
+			if _, err := SysCreate(addr, data, gas, g.Config, statedb, head); err != nil {
+				if errors.Is(err, vm.ErrOutOfGas) {
+					return fmt.Errorf("genesis alloc %x: constructor ran out of gas (limit %d): %w", addr, gas, err)
+				}
+				return fmt.Errorf("genesis alloc %x: constructor: %w", addr, err)
+			}
+		}
+
+		if len(account.Code) > 0 || len(account.Storage) > 0 || len(account.Constructor) > 0 {
+			statedb.SetIncarnation(addr, state.FirstContractIncarnation)
+		}
+	}
+	return nil
+}
+
+// genesisBlockFromChainspec returns the genesis block of g without applying its alloc, if g is the genesis of
+// a built-in network whose state root is recorded in chainspec. The block hash is checked against chainspec.
+// Writing the genesis block doesn't need the alloc state: it is materialized by the execution stage when it
+// executes block 0, or comes with the state snapshot files, so for large allocs (e.g. Gnosis) this saves
+// computing the state root on first start.
+func genesisBlockFromChainspec(g *types.Genesis) (*types.Block, bool) {
+	if g.Config == nil {
+		return nil, false
+	}
+	name := g.Config.ChainName
+	root, ok := chainspec.GenesisStateRootByChainName(name)
+	if !ok || !isChainspecGenesis(g) {
+		return nil, false
+	}
+	head, withdrawals := GenesisWithoutStateToBlock(g)
+	head.Root = root
+	block := types.NewBlock(head, nil, nil, nil, withdrawals)
+	if hash := chainspec.GenesisHashByChainName(name); hash == nil || *hash != block.Hash() {
+		return nil, false
+	}
+	return block, true
+}
+
+// validateGenesisWithOverrides runs ValidateGenesis on g as it will be written, i.e. with the overrides applied:
+// an override may be what schedules a fork that the blob schedule of g refers to.
+func validateGenesisWithOverrides(g *types.Genesis, overrideOsakaTime *big.Int, override *ConfigOverride) error {
+	if overrideOsakaTime == nil && override == nil {
+		return genesisProblemsError(ValidateGenesis(g))
+	}
+	var config chain.Config
+	if err := copier.Copy(&config, g.Config); err != nil {
+		return err
+	}
+	config.BlobSchedule = maps.Clone(g.Config.BlobSchedule)
+	if overrideOsakaTime != nil {
+		config.OsakaTime = overrideOsakaTime
+	}
+	if override != nil {
+		if err := json.Unmarshal(override.raw, &config); err != nil {
+			return err
+		}
+	}
+	overridden := *g
+	overridden.Config = &config
+	return genesisProblemsError(ValidateGenesis(&overridden))
+}
+
+// isChainspecGenesis reports whether g is the embedded spec of a known chain. Only the spec itself is trusted,
+// a copy may have a different alloc.
+func isChainspecGenesis(g *types.Genesis) bool {
+	return g.Config != nil && g == chainspec.GenesisBlockByChainName(g.Config.ChainName)
+}
+
 // GenesisWithoutStateToBlock creates the genesis block, assuming an empty state.
 func GenesisWithoutStateToBlock(g *types.Genesis) (head *types.Header, withdrawals []*types.Withdrawal) {
 	head = &types.Header{
@@ -510,6 +873,29 @@ func GenesisWithoutStateToBlock(g *types.Genesis) (head *types.Header, withdrawa
 	return
 }
 
+// genesisAllocProgress logs progress of writing big genesis allocations, so that operators
+// don't take a multi-minute `erigon init` for a hung node. Small allocations are not reported.
+type genesisAllocProgress struct {
+	total     int
+	batchSize int
+	started   time.Time
+	logger    log.Logger
+}
+
+func newGenesisAllocProgress(total, batchSize int, logger log.Logger) *genesisAllocProgress {
+	return &genesisAllocProgress{total: total, batchSize: batchSize, started: time.Now(), logger: logger}
+}
+
+func (p *genesisAllocProgress) report(done int) {
+	if p.total <= p.batchSize || done == 0 {
+		return
+	}
+	elapsed := time.Since(p.started)
+	eta := time.Duration(float64(elapsed) / float64(done) * float64(p.total-done))
+	p.logger.Info("[genesis] writing alloc", "accounts", fmt.Sprintf("%d/%d", done, p.total),
+		"elapsed", elapsed.Round(time.Second), "eta", eta.Round(time.Second))
+}
+
 func sortedAllocAddresses(m types.GenesisAlloc) []common.Address {
 	addrs := make([]common.Address, 0, len(m))
 	for addr := range m {
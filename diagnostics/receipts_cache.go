@@ -0,0 +1,48 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/erigontech/erigon/rpc/jsonrpc/receipts"
+	"github.com/erigontech/erigon/turbo/node"
+)
+
+func SetupReceiptsCacheAccess(metricsMux *http.ServeMux, node *node.ErigonNode) {
+	if metricsMux == nil {
+		return
+	}
+
+	metricsMux.HandleFunc("/receipts-cache", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		writeReceiptsCache(w, node)
+	})
+}
+
+func writeReceiptsCache(w http.ResponseWriter, node *node.ErigonNode) {
+	var stats struct {
+		Blocks receipts.CacheStats `json:"blocks"`
+		Txns   receipts.CacheStats `json:"txns"`
+	}
+	stats.Blocks, stats.Txns = node.Backend().ReceiptsCacheStats()
+
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -143,6 +143,8 @@ func SetupEndpoints(ctx *cli.Context, node *node.ErigonNode, diagMux *http.Serve
 	SetupBootnodesAccess(diagMux, node)
 	SetupStagesAccess(diagMux, diagnostic)
 	SetupMemAccess(diagMux)
+	SetupReceiptsCacheAccess(diagMux, node)
+	SetupRequestTracesAccess(diagMux, node)
 	SetupHeadersAccess(diagMux, diagnostic)
 	SetupBodiesAccess(diagMux, diagnostic)
 	SetupSysInfoAccess(diagMux, diagnostic)
@@ -1026,6 +1026,10 @@ func (d *Downloader) afterAddNewTorrent(metainfoOnDisk bool, t *torrent.Torrent)
 		// Always download, even if we think we're complete already. Failure to validate, or things
 		// changing can result in us needing to be in the download state to repair. It costs nothing
 		// if the torrent is already complete.
+		if !metainfoOnDisk {
+			// Resume a partial download: keep the pieces on disk whose hashes match rather than fetching them again.
+			d.verifyPartialFiles(t)
+		}
 		t.DownloadAll()
 		if !metainfoOnDisk {
 			d.saveMetainfoWhenComplete(t)
@@ -1033,6 +1037,19 @@ func (d *Downloader) afterAddNewTorrent(metainfoOnDisk bool, t *torrent.Torrent)
 	})
 }
 
+// Verify the pieces of the files of a torrent partially downloaded before a restart. Files absent from disk have
+// nothing to keep.
+func (d *Downloader) verifyPartialFiles(t *torrent.Torrent) {
+	for _, f := range t.Files() {
+		fi, err := os.Stat(d.filePathForName(f.Path()))
+		if err != nil || fi.Size() == 0 {
+			continue
+		}
+		d.logger.Debug("[snapshots] resuming partial download", "name", f.Path(), "size", fi.Size(), "expected", f.Length())
+		d.verifyFile(f)
+	}
+}
+
 func (d *Downloader) saveMetainfoWhenComplete(t *torrent.Torrent) {
 	for {
 		select {
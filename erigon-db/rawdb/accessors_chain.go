@@ -1287,6 +1287,36 @@ func ReadReceiptsCacheV2(tx kv.TemporalTx, block *types.Block, txNumReader rawdb
 	return res, nil
 }
 
+// ReadFrozenReceipts reads the receipts of block from the snapshot files of the receipts cache domain, through the
+// history of tx, when the whole block is within the range of the files. ok is false otherwise, or if any receipt of the
+// block is missing from the files, e.g. when they were built without the receipts: the receipts must be regenerated.
+func ReadFrozenReceipts(tx kv.TemporalTx, block *types.Block, txNumReader rawdbv3.TxNumsReader) (receipts types.Receipts, ok bool, err error) {
+	minTxNum, err := txNumReader.Min(tx, block.NumberU64())
+	if err != nil {
+		return nil, false, err
+	}
+	maxTxNum, err := txNumReader.Max(tx, block.NumberU64())
+	if err != nil {
+		return nil, false, err
+	}
+	if minTxNum < tx.Debug().HistoryStartFrom(kv.RCacheDomain) || maxTxNum >= tx.Debug().TxNumsInFiles(kv.RCacheDomain) {
+		return nil, false, nil
+	}
+	receipts, err = ReadReceiptsCacheV2(tx, block, txNumReader)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return nil, false, nil
+	}
+	for i, receipt := range receipts {
+		if receipt.TransactionIndex != uint(i) {
+			return nil, false, nil
+		}
+	}
+	return receipts, true, nil
+}
+
 func WriteReceiptCacheV2(tx kv.TemporalPutDel, receipt *types.Receipt, txNum uint64) error {
 	var toWrite []byte
 
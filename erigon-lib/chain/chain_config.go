@@ -356,54 +356,54 @@ func (c *Config) GetMinBlobGasPrice() uint64 {
 	return 1 // MIN_BLOB_GASPRICE (EIP-4844)
 }
 
+type blobScheduleFork struct {
+	name     string
+	time     *big.Int
+	defaults *params.BlobConfig
+}
+
+// blobScheduleForks lists the forks that can have an entry in BlobSchedule, in activation order.
+func (c *Config) blobScheduleForks() []blobScheduleFork {
+	return []blobScheduleFork{
+		{"cancun", c.CancunTime, &params.DefaultCancunBlobConfig},
+		{"prague", c.PragueTime, &params.DefaultPragueBlobConfig},
+		{"osaka", c.OsakaTime, &params.DefaultOsakaBlobConfig},
+		{"bpo1", c.Bpo1Time, nil},
+		{"bpo2", c.Bpo2Time, nil},
+		{"bpo3", c.Bpo3Time, nil},
+		{"bpo4", c.Bpo4Time, nil},
+		{"bpo5", c.Bpo5Time, nil},
+	}
+}
+
+// BlobScheduleForkTime returns the activation time of a fork named in BlobSchedule (nil if not scheduled),
+// and whether BlobSchedule entries with that name are recognized at all.
+func (c *Config) BlobScheduleForkTime(fork string) (*big.Int, bool) {
+	for _, f := range c.blobScheduleForks() {
+		if f.name == fork {
+			return f.time, true
+		}
+	}
+	return nil, false
+}
+
 func (c *Config) GetBlobConfig(time uint64) *params.BlobConfig {
 	c.parseBlobScheduleOnce.Do(func() {
-		// Populate with default values
 		c.parsedBlobSchedule = map[uint64]*params.BlobConfig{
 			0: {},
 		}
-		if c.CancunTime != nil {
-			c.parsedBlobSchedule[c.CancunTime.Uint64()] = &params.DefaultCancunBlobConfig
-		}
-		if c.PragueTime != nil {
-			c.parsedBlobSchedule[c.PragueTime.Uint64()] = &params.DefaultPragueBlobConfig
-		}
-		if c.OsakaTime != nil {
-			c.parsedBlobSchedule[c.OsakaTime.Uint64()] = &params.DefaultOsakaBlobConfig
+		forks := c.blobScheduleForks()
+		// Populate with default values
+		for _, fork := range forks {
+			if fork.time != nil && fork.defaults != nil {
+				c.parsedBlobSchedule[fork.time.Uint64()] = fork.defaults
+			}
 		}
-
 		// Override with supplied values
-		val, ok := c.BlobSchedule["cancun"]
-		if ok && c.CancunTime != nil {
-			c.parsedBlobSchedule[c.CancunTime.Uint64()] = val
-		}
-		val, ok = c.BlobSchedule["prague"]
-		if ok && c.PragueTime != nil {
-			c.parsedBlobSchedule[c.PragueTime.Uint64()] = val
-		}
-		val, ok = c.BlobSchedule["osaka"]
-		if ok && c.OsakaTime != nil {
-			c.parsedBlobSchedule[c.OsakaTime.Uint64()] = val
-		}
-		val, ok = c.BlobSchedule["bpo1"]
-		if ok && c.Bpo1Time != nil {
-			c.parsedBlobSchedule[c.Bpo1Time.Uint64()] = val
-		}
-		val, ok = c.BlobSchedule["bpo2"]
-		if ok && c.Bpo2Time != nil {
-			c.parsedBlobSchedule[c.Bpo2Time.Uint64()] = val
-		}
-		val, ok = c.BlobSchedule["bpo3"]
-		if ok && c.Bpo3Time != nil {
-			c.parsedBlobSchedule[c.Bpo3Time.Uint64()] = val
-		}
-		val, ok = c.BlobSchedule["bpo4"]
-		if ok && c.Bpo4Time != nil {
-			c.parsedBlobSchedule[c.Bpo4Time.Uint64()] = val
-		}
-		val, ok = c.BlobSchedule["bpo5"]
-		if ok && c.Bpo5Time != nil {
-			c.parsedBlobSchedule[c.Bpo5Time.Uint64()] = val
+		for _, fork := range forks {
+			if val, ok := c.BlobSchedule[fork.name]; ok && fork.time != nil {
+				c.parsedBlobSchedule[fork.time.Uint64()] = val
+			}
 		}
 	})
 
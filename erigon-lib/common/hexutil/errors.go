@@ -16,7 +16,10 @@
 
 package hexutil
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // These errors are from go-ethereum in order to keep compatibility with geth error codes.
 var (
@@ -33,6 +36,9 @@ var (
 	ErrHexStringInvalid = &decError{"hex string invalid"}
 )
 
+// ErrTsTooShort is returned when decoding a TimeStamp from a too short byte slice.
+var ErrTsTooShort = errors.New("timestamp encoding too short")
+
 type decError struct{ msg string }
 
 func (err decError) Error() string { return err.msg }
@@ -0,0 +1,51 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package hexutil_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/erigontech/erigon-lib/common/hexutil"
+)
+
+var topicBufPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 32)
+		return &b
+	},
+}
+
+func ExampleDecodeInto() {
+	topics := []string{
+		"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+		"0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925",
+	}
+	bufp := topicBufPool.Get().(*[]byte)
+	defer topicBufPool.Put(bufp)
+
+	for _, topic := range topics {
+		var err error
+		if *bufp, err = hexutil.DecodeInto((*bufp)[:0], topic); err != nil {
+			panic(err)
+		}
+		fmt.Printf("%x\n", (*bufp)[:4])
+	}
+	// Output:
+	// ddf252ad
+	// 8c5be1e5
+}
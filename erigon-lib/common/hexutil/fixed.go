@@ -0,0 +1,128 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package hexutil
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+var (
+	bytes48T = reflect.TypeOf(Bytes48{})
+	bytes96T = reflect.TypeOf(Bytes96{})
+)
+
+// Bytes48 marshals/unmarshals as a JSON string with 0x prefix holding exactly 48 bytes,
+// e.g. KZG commitments/proofs and BLS public keys.
+type Bytes48 [48]byte
+
+// Bytes48FromSlice converts b to Bytes48. It fails unless b is exactly 48 bytes long.
+func Bytes48FromSlice(b []byte) (Bytes48, error) {
+	var out Bytes48
+	if len(b) != len(out) {
+		return out, fmt.Errorf("byte slice has length %d, want %d for Bytes48", len(b), len(out))
+	}
+	copy(out[:], b)
+	return out, nil
+}
+
+// Array returns b as a plain byte array.
+func (b Bytes48) Array() [48]byte { return b }
+
+// MarshalText implements encoding.TextMarshaler
+func (b Bytes48) MarshalText() ([]byte, error) {
+	return Bytes(b[:]).MarshalText()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Bytes48) UnmarshalJSON(input []byte) error {
+	if !isString(input) {
+		return errNonString(bytes48T)
+	}
+	return wrapTypeError(b.UnmarshalText(input[1:len(input)-1]), bytes48T)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *Bytes48) UnmarshalText(input []byte) error {
+	return unmarshalExactText("Bytes48", input, b[:])
+}
+
+// String returns the hex encoding of b.
+func (b Bytes48) String() string {
+	return Encode(b[:])
+}
+
+// Bytes96 marshals/unmarshals as a JSON string with 0x prefix holding exactly 96 bytes,
+// e.g. BLS signatures.
+type Bytes96 [96]byte
+
+// Bytes96FromSlice converts b to Bytes96. It fails unless b is exactly 96 bytes long.
+func Bytes96FromSlice(b []byte) (Bytes96, error) {
+	var out Bytes96
+	if len(b) != len(out) {
+		return out, fmt.Errorf("byte slice has length %d, want %d for Bytes96", len(b), len(out))
+	}
+	copy(out[:], b)
+	return out, nil
+}
+
+// Array returns b as a plain byte array.
+func (b Bytes96) Array() [96]byte { return b }
+
+// MarshalText implements encoding.TextMarshaler
+func (b Bytes96) MarshalText() ([]byte, error) {
+	return Bytes(b[:]).MarshalText()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Bytes96) UnmarshalJSON(input []byte) error {
+	if !isString(input) {
+		return errNonString(bytes96T)
+	}
+	return wrapTypeError(b.UnmarshalText(input[1:len(input)-1]), bytes96T)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *Bytes96) UnmarshalText(input []byte) error {
+	return unmarshalExactText("Bytes96", input, b[:])
+}
+
+// String returns the hex encoding of b.
+func (b Bytes96) String() string {
+	return Encode(b[:])
+}
+
+// unmarshalExactText is a stricter UnmarshalFixedText: the 0x prefix is mandatory (also for
+// empty input) and a wrong length, including an odd one, is reported with the expected length.
+func unmarshalExactText(typeName string, input, out []byte) error {
+	if !bytesHave0xPrefix(input) {
+		return ErrMissingPrefix
+	}
+	raw := input[2:]
+	if len(raw) != len(out)*2 {
+		return fmt.Errorf("hex string has length %d, want %d for %s", len(raw), len(out)*2, typeName)
+	}
+	// Pre-verify syntax before modifying out.
+	for _, c := range raw {
+		if decodeNibble(c) == badNibble {
+			return ErrSyntax
+		}
+	}
+	_, err := hex.Decode(out, raw)
+	return err
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package hexutil
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBytes48JSONRoundTrip(t *testing.T) {
+	var in Bytes48
+	for i := range in {
+		in[i] = byte(i)
+	}
+	enc, err := json.Marshal(in)
+	require.NoError(t, err)
+	require.Equal(t, `"`+Encode(in[:])+`"`, string(enc))
+
+	var out Bytes48
+	require.NoError(t, json.Unmarshal(enc, &out))
+	require.Equal(t, in, out)
+	require.Equal(t, [48]byte(in), out.Array())
+}
+
+func TestBytes96JSONRoundTrip(t *testing.T) {
+	var in Bytes96
+	for i := range in {
+		in[i] = byte(255 - i)
+	}
+	enc, err := json.Marshal(in)
+	require.NoError(t, err)
+	require.Equal(t, `"`+Encode(in[:])+`"`, string(enc))
+
+	var out Bytes96
+	require.NoError(t, json.Unmarshal(enc, &out))
+	require.Equal(t, in, out)
+	require.Equal(t, [96]byte(in), out.Array())
+}
+
+func TestFixedBytesUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		into    interface{}
+		wantErr string
+	}{
+		{input: `"0x` + strings.Repeat("ab", 47) + `"`, into: new(Bytes48), wantErr: "hex string has length 94, want 96 for Bytes48"},
+		{input: `"0x` + strings.Repeat("ab", 49) + `"`, into: new(Bytes48), wantErr: "hex string has length 98, want 96 for Bytes48"},
+		{input: `"0x` + strings.Repeat("ab", 48) + `a"`, into: new(Bytes48), wantErr: "hex string has length 97, want 96 for Bytes48"},
+		{input: `"0x` + strings.Repeat("ab", 95) + `"`, into: new(Bytes96), wantErr: "hex string has length 190, want 192 for Bytes96"},
+		{input: `"0x` + strings.Repeat("ab", 96) + `a"`, into: new(Bytes96), wantErr: "hex string has length 193, want 192 for Bytes96"},
+		{input: `""`, into: new(Bytes48), wantErr: "json: cannot unmarshal hex string without 0x prefix into Go value of type hexutil.Bytes48"},
+		{input: `"` + strings.Repeat("ab", 48) + `"`, into: new(Bytes48), wantErr: "json: cannot unmarshal hex string without 0x prefix into Go value of type hexutil.Bytes48"},
+		{input: `"0x` + strings.Repeat("zz", 96) + `"`, into: new(Bytes96), wantErr: "json: cannot unmarshal invalid hex string into Go value of type hexutil.Bytes96"},
+		{input: `12`, into: new(Bytes96), wantErr: "json: cannot unmarshal non-string into Go value of type hexutil.Bytes96"},
+	}
+	for _, test := range tests {
+		err := json.Unmarshal([]byte(test.input), test.into)
+		require.EqualError(t, err, test.wantErr, "input %s", test.input)
+	}
+}
+
+func TestFixedBytesFromSlice(t *testing.T) {
+	b48, err := Bytes48FromSlice(make([]byte, 48))
+	require.NoError(t, err)
+	require.Equal(t, Bytes48{}, b48)
+	_, err = Bytes48FromSlice(make([]byte, 32))
+	require.EqualError(t, err, "byte slice has length 32, want 48 for Bytes48")
+
+	b96, err := Bytes96FromSlice(make([]byte, 96))
+	require.NoError(t, err)
+	require.Equal(t, Bytes96{}, b96)
+	_, err = Bytes96FromSlice(make([]byte, 97))
+	require.EqualError(t, err, "byte slice has length 97, want 96 for Bytes96")
+}
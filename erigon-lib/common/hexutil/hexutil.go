@@ -21,7 +21,9 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"slices"
 	"strconv"
+	"unsafe"
 )
 
 const uintBits = 32 << (uint64(^uint(0)) >> 63)
@@ -41,6 +43,57 @@ func Decode(input string) ([]byte, error) {
 	return b, nil
 }
 
+// DecodeInto decodes a hex string with 0x prefix and appends the result to dst, reusing
+// its capacity when sufficient. Pass dst[:0] to overwrite a previously used buffer.
+// On error dst is returned with its original length.
+func DecodeInto(dst []byte, input string) ([]byte, error) {
+	raw, err := checkHexString(input)
+	if err != nil {
+		return dst, err
+	}
+	n := len(dst)
+	dst = slices.Grow(dst, len(raw)/2)[:n+len(raw)/2]
+	if _, err = hex.Decode(dst[n:], stringBytes(raw)); err != nil {
+		return dst[:n], mapError(err)
+	}
+	return dst, nil
+}
+
+// DecodeFixedInto decodes a hex string with 0x prefix into dst. The decoded length must be
+// exactly len(dst), otherwise an error is returned and dst is left untouched.
+// The contents of dst are unspecified if input contains invalid characters.
+func DecodeFixedInto(dst []byte, input string) error {
+	raw, err := checkHexString(input)
+	if err != nil {
+		return err
+	}
+	if len(raw)/2 != len(dst) {
+		return fmt.Errorf("hex string has length %d, want %d", len(raw), len(dst)*2)
+	}
+	if _, err = hex.Decode(dst, stringBytes(raw)); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+// checkHexString applies the prefix and length rules of Decode and returns input without its prefix.
+func checkHexString(input string) (string, error) {
+	if len(input) == 0 {
+		return "", ErrEmptyString
+	}
+	if !has0xPrefix(input) {
+		return "", ErrMissingPrefix
+	}
+	raw := input[2:]
+	if len(raw)%2 != 0 {
+		return "", ErrOddLength
+	}
+	return raw, nil
+}
+
+// stringBytes returns the bytes of s without copying. The result must not be modified.
+func stringBytes(s string) []byte { return unsafe.Slice(unsafe.StringData(s), len(s)) }
+
 // MustDecode decodes a hex string with 0x prefix. It panics for invalid input.
 func MustDecode(input string) []byte {
 	dec, err := Decode(input)
@@ -142,23 +195,46 @@ func has0xPrefix(input string) bool {
 }
 
 // IsValidQuantity checks if input is a valid hex-encoded quantity as per JSON-RPC spec.
+// Quantities are bounded by 2^256-1, i.e. at most 64 hex digits.
 // It returns nil if the input is valid, otherwise an error.
 func IsValidQuantity(input string) error {
+	return IsValidQuantityN(input, 64)
+}
+
+// IsValidUint64Quantity checks if input is a valid hex-encoded quantity that fits into uint64.
+func IsValidUint64Quantity(input string) error {
+	return IsValidQuantityN(input, 16)
+}
+
+// IsValidQuantityN checks if input is a valid hex-encoded quantity of at most maxHexDigits digits.
+// Invalid characters are always reported as ErrHexStringInvalid, regardless of the input length.
+func IsValidQuantityN(input string, maxHexDigits int) error {
 	input, err := checkNumber(input)
 	if err != nil {
 		return err
 	}
-	if len(input) > 64 {
-		return ErrTooBigHexString
-	}
-	for _, b := range input {
-		if decodeNibble(byte(b)) == badNibble {
+	for i := 0; i < len(input); i++ {
+		if decodeNibble(input[i]) == badNibble {
 			return ErrHexStringInvalid
 		}
 	}
+	if len(input) > maxHexDigits {
+		return tooLongQuantityError(maxHexDigits)
+	}
 	return nil
 }
 
+func tooLongQuantityError(maxHexDigits int) error {
+	switch maxHexDigits {
+	case 16:
+		return ErrUint64Range
+	case 64:
+		return ErrTooBigHexString
+	default:
+		return &decError{fmt.Sprintf("hex string too long, want at most %d hex digits", maxHexDigits)}
+	}
+}
+
 func checkNumber(input string) (raw string, err error) {
 	if len(input) == 0 {
 		return "", ErrEmptyString
@@ -246,6 +322,50 @@ func EncodeTs(number uint64) []byte {
 	return enc[:]
 }
 
+// EncodeTsTo writes ts as big endian into the first 8 bytes of dst, which must be at least 8 bytes long.
+// It is the allocation-free counterpart of EncodeTs for preallocated key buffers.
+func EncodeTsTo(dst []byte, ts uint64) {
+	binary.BigEndian.PutUint64(dst, ts)
+}
+
+// DecodeTs decodes a TimeStamp encoded by EncodeTs from the first 8 bytes of b.
+// Trailing bytes are ignored, so it can be used on composite keys starting with a TimeStamp.
+func DecodeTs(b []byte) (uint64, error) {
+	if len(b) < 8 {
+		return 0, fmt.Errorf("%w: got %d bytes", ErrTsTooShort, len(b))
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// MustDecodeTs is DecodeTs for key iteration hot paths where b is known to be well-formed.
+// It panics for input shorter than 8 bytes.
+func MustDecodeTs(b []byte) uint64 {
+	ts, err := DecodeTs(b)
+	if err != nil {
+		panic(err)
+	}
+	return ts
+}
+
+// EncodeTsVar encodes a TimeStamp as unsigned varint, for tables where key compactness matters
+// more than byte-wise ordering of keys.
+func EncodeTsVar(ts uint64) []byte {
+	return binary.AppendUvarint(nil, ts)
+}
+
+// DecodeTsVar decodes a TimeStamp encoded by EncodeTsVar from the beginning of b and
+// returns it with the number of bytes consumed.
+func DecodeTsVar(b []byte) (ts uint64, n int, err error) {
+	ts, n = binary.Uvarint(b)
+	switch {
+	case n == 0:
+		return 0, 0, fmt.Errorf("%w: truncated varint", ErrTsTooShort)
+	case n < 0:
+		return 0, 0, ErrUint64Range
+	}
+	return ts, n, nil
+}
+
 // Encode encodes b as a hex string with 0x prefix.
 func Encode(b []byte) string {
 	enc := make([]byte, len(b)*2+2)
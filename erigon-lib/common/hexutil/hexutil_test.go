@@ -17,8 +17,10 @@
 package hexutil
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -142,6 +144,22 @@ var (
 		{input: `0x123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0`, wantErr: nil},
 	}
 
+	isValidQtyBoundaryTests = []struct {
+		input     string
+		maxDigits int
+		wantErr   error
+	}{
+		{input: "0x" + strings.Repeat("f", 15), maxDigits: 16, wantErr: nil},
+		{input: "0x" + strings.Repeat("f", 16), maxDigits: 16, wantErr: nil},
+		{input: "0x" + strings.Repeat("f", 17), maxDigits: 16, wantErr: ErrUint64Range},
+		{input: "0x" + strings.Repeat("f", 63), maxDigits: 64, wantErr: nil},
+		{input: "0x" + strings.Repeat("f", 64), maxDigits: 64, wantErr: nil},
+		{input: "0x" + strings.Repeat("f", 65), maxDigits: 64, wantErr: ErrTooBigHexString},
+		{input: "0x" + strings.Repeat("f", 97), maxDigits: 96, wantErr: errors.New("hex string too long, want at most 96 hex digits")},
+		{input: "0x" + strings.Repeat("z", 65), maxDigits: 64, wantErr: ErrHexStringInvalid},
+		{input: "0x" + strings.Repeat("f", 16) + "z", maxDigits: 16, wantErr: ErrHexStringInvalid},
+	}
+
 	decodeUint64Tests = []unmarshalTest{
 		// invalid
 		{input: `0`, wantErr: ErrMissingPrefix},
@@ -232,3 +250,141 @@ func TestIsValidQuantity(t *testing.T) {
 		})
 	}
 }
+
+func TestIsValidQuantityN(t *testing.T) {
+	for idx, test := range isValidQtyBoundaryTests {
+		t.Run(fmt.Sprintf("%d", idx), func(t *testing.T) {
+			err := IsValidQuantityN(test.input, test.maxDigits)
+			checkError(t, test.input, err, test.wantErr)
+		})
+	}
+}
+
+func TestIsValidUint64Quantity(t *testing.T) {
+	checkError(t, "0xffffffffffffffff", IsValidUint64Quantity("0xffffffffffffffff"), nil)
+	checkError(t, "0x10000000000000000", IsValidUint64Quantity("0x10000000000000000"), ErrUint64Range)
+	checkError(t, "0x01", IsValidUint64Quantity("0x01"), ErrLeadingZero)
+}
+
+func TestDecodeTs(t *testing.T) {
+	for _, ts := range []uint64{0, 1, 0xff, 0x1122334455667788, ^uint64(0)} {
+		enc := EncodeTs(ts)
+		dec, err := DecodeTs(enc)
+		require.NoError(t, err)
+		require.Equal(t, ts, dec)
+		require.Equal(t, ts, MustDecodeTs(append(enc, 0xaa, 0xbb)))
+
+		buf := make([]byte, 10)
+		EncodeTsTo(buf, ts)
+		require.Equal(t, enc, buf[:8])
+	}
+
+	_, err := DecodeTs(make([]byte, 7))
+	require.ErrorIs(t, err, ErrTsTooShort)
+	_, err = DecodeTs(nil)
+	require.ErrorIs(t, err, ErrTsTooShort)
+	require.Panics(t, func() { MustDecodeTs([]byte{1, 2, 3}) })
+}
+
+func TestDecodeTsVar(t *testing.T) {
+	for _, ts := range []uint64{0, 1, 127, 128, 0x1122334455667788, ^uint64(0)} {
+		enc := EncodeTsVar(ts)
+		dec, n, err := DecodeTsVar(append(enc, 0xff))
+		require.NoError(t, err)
+		require.Equal(t, ts, dec)
+		require.Equal(t, len(enc), n)
+	}
+	require.Len(t, EncodeTsVar(127), 1)
+
+	_, _, err := DecodeTsVar(nil)
+	require.ErrorIs(t, err, ErrTsTooShort)
+	_, _, err = DecodeTsVar([]byte{0x80, 0x80})
+	require.ErrorIs(t, err, ErrTsTooShort)
+	_, _, err = DecodeTsVar([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	require.ErrorIs(t, err, ErrUint64Range)
+}
+
+func TestDecodeInto(t *testing.T) {
+	for idx, test := range decodeBytesTests {
+		t.Run(fmt.Sprintf("%d", idx), func(t *testing.T) {
+			prefix := []byte{0xde, 0xad}
+			dst := make([]byte, len(prefix), 64)
+			copy(dst, prefix)
+			dec, err := DecodeInto(dst, test.input)
+			checkError(t, test.input, err, test.wantErr)
+			if test.wantErr != nil {
+				require.Equal(t, prefix, dec)
+				return
+			}
+			require.Equal(t, prefix, dec[:len(prefix)])
+			require.EqualValues(t, test.want, dec[len(prefix):])
+			require.Equal(t, &dst[:1][0], &dec[0], "capacity of dst must be reused")
+		})
+	}
+}
+
+func TestDecodeFixedInto(t *testing.T) {
+	var dst [4]byte
+	require.NoError(t, DecodeFixedInto(dst[:], "0x01020304"))
+	require.Equal(t, [4]byte{1, 2, 3, 4}, dst)
+
+	checkError(t, "0x010203", DecodeFixedInto(dst[:], "0x010203"), errors.New("hex string has length 6, want 8"))
+	checkError(t, "0x0102030405", DecodeFixedInto(dst[:], "0x0102030405"), errors.New("hex string has length 10, want 8"))
+	checkError(t, "0x0102030", DecodeFixedInto(dst[:], "0x0102030"), ErrOddLength)
+	checkError(t, "01020304", DecodeFixedInto(dst[:], "01020304"), ErrMissingPrefix)
+	checkError(t, "", DecodeFixedInto(dst[:], ""), ErrEmptyString)
+	require.Equal(t, [4]byte{1, 2, 3, 4}, dst, "dst must be untouched on length errors")
+	checkError(t, "0x010203zz", DecodeFixedInto(dst[:], "0x010203zz"), ErrSyntax)
+}
+
+func hashStrings(n int) []string {
+	res := make([]string, n)
+	for i := range res {
+		var h [32]byte
+		EncodeTsTo(h[24:], uint64(i))
+		res[i] = Encode(h[:])
+	}
+	return res
+}
+
+func BenchmarkDecode32(b *testing.B) {
+	inputs := hashStrings(10_000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, in := range inputs {
+			if _, err := Decode(in); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkDecodeInto32(b *testing.B) {
+	inputs := hashStrings(10_000)
+	buf := make([]byte, 0, 32)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, in := range inputs {
+			var err error
+			if buf, err = DecodeInto(buf[:0], in); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkDecodeFixedInto32(b *testing.B) {
+	inputs := hashStrings(10_000)
+	var dst [32]byte
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, in := range inputs {
+			if err := DecodeFixedInto(dst[:], in); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
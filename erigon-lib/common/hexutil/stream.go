@@ -0,0 +1,160 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package hexutil
+
+import (
+	"encoding/hex"
+	"io"
+)
+
+// streamChunkSize is the number of raw bytes encoded (or decoded) per write to the underlying stream.
+const streamChunkSize = 4096
+
+type encoder struct {
+	w        io.Writer
+	prefixed bool
+	buf      [streamChunkSize * 2]byte
+}
+
+// NewEncoder returns a writer which hex-encodes everything written to it into w, prefixed with 0x.
+// The prefix is emitted on the first call to Write, including a Write of an empty slice.
+// Data is encoded in fixed-size chunks, so no full-size copy of the input is ever allocated.
+func NewEncoder(w io.Writer) io.Writer {
+	return &encoder{w: w}
+}
+
+func (e *encoder) Write(p []byte) (n int, err error) {
+	if !e.prefixed {
+		if _, err = io.WriteString(e.w, hexPrefix); err != nil {
+			return 0, err
+		}
+		e.prefixed = true
+	}
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > streamChunkSize {
+			chunk = chunk[:streamChunkSize]
+		}
+		encoded := hex.Encode(e.buf[:], chunk)
+		written, err := e.w.Write(e.buf[:encoded])
+		n += written / 2
+		if err != nil {
+			return n, err
+		}
+		p = p[len(chunk):]
+	}
+	return n, nil
+}
+
+type decoder struct {
+	r        io.Reader
+	prefixed bool
+	err      error
+	buf      [streamChunkSize * 2]byte
+	// pending holds hex characters that were read but not yet decoded:
+	// at most one dangling nibble between reads, or the tail of a short output buffer.
+	pending []byte
+}
+
+// NewDecoder returns a reader which decodes 0x-prefixed hex read from r.
+// A byte whose two nibbles are split across reads of r is decoded correctly.
+// Decoding errors are reported with the same errors Decode uses (ErrEmptyString,
+// ErrMissingPrefix, ErrOddLength, ErrSyntax).
+func NewDecoder(r io.Reader) io.Reader {
+	return &decoder{r: r}
+}
+
+func (d *decoder) readPrefix() error {
+	var prefix [2]byte
+	n, err := io.ReadFull(d.r, prefix[:])
+	switch {
+	case n == 0 && (err == io.EOF || err == io.ErrUnexpectedEOF):
+		return ErrEmptyString
+	case err == io.ErrUnexpectedEOF:
+		return ErrMissingPrefix
+	case err != nil:
+		return err
+	}
+	if !has0xPrefix(string(prefix[:])) {
+		return ErrMissingPrefix
+	}
+	return nil
+}
+
+func (d *decoder) Read(p []byte) (n int, err error) {
+	if !d.prefixed {
+		if err = d.readPrefix(); err != nil {
+			d.err = err
+			return 0, err
+		}
+		d.prefixed = true
+	}
+	for n < len(p) {
+		// fill pending with at least one full byte worth of hex, unless the source is exhausted
+		for len(d.pending) < 2 && d.err == nil {
+			free := copy(d.buf[:], d.pending)
+			want := len(p)*2 - len(d.pending)
+			if want < 2 {
+				want = 2
+			}
+			if free+want > len(d.buf) {
+				want = len(d.buf) - free
+			}
+			var read int
+			read, d.err = d.r.Read(d.buf[free : free+want])
+			d.pending = d.buf[:free+read]
+		}
+		if len(d.pending) < 2 {
+			break
+		}
+		toDecode := len(d.pending) &^ 1
+		if toDecode/2 > len(p)-n {
+			toDecode = (len(p) - n) * 2
+		}
+		decoded, decErr := hex.Decode(p[n:], d.pending[:toDecode])
+		n += decoded
+		if decErr != nil {
+			d.err = mapError(decErr)
+			return n, d.err
+		}
+		d.pending = d.pending[toDecode:]
+		if d.err != nil {
+			break
+		}
+	}
+	if n > 0 {
+		return n, nil
+	}
+	if d.err == io.EOF && len(d.pending) == 1 {
+		d.err = ErrOddLength
+	}
+	return 0, d.err
+}
+
+// EncodeToJSONWriter writes b to w as a quoted JSON string with 0x prefix, without
+// materializing the full hex encoding in memory. It is equivalent to writing
+// the JSON encoding of Bytes(b).
+func EncodeToJSONWriter(w io.Writer, b []byte) error {
+	if _, err := io.WriteString(w, `"`); err != nil {
+		return err
+	}
+	if _, err := NewEncoder(w).Write(b); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, `"`)
+	return err
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package hexutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func streamPayload(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i * 7)
+	}
+	return b
+}
+
+func TestEncoder(t *testing.T) {
+	for _, size := range []int{0, 1, streamChunkSize - 1, streamChunkSize, streamChunkSize + 1, 3*streamChunkSize + 17} {
+		payload := streamPayload(size)
+		var buf bytes.Buffer
+		n, err := NewEncoder(&buf).Write(payload)
+		require.NoError(t, err)
+		require.Equal(t, size, n)
+		require.Equal(t, Encode(payload), buf.String())
+	}
+}
+
+func TestEncoderMultipleWrites(t *testing.T) {
+	payload := streamPayload(2*streamChunkSize + 3)
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	for _, part := range [][]byte{payload[:1], payload[1 : streamChunkSize+1], payload[streamChunkSize+1:]} {
+		_, err := enc.Write(part)
+		require.NoError(t, err)
+	}
+	require.Equal(t, Encode(payload), buf.String())
+}
+
+func TestDecoder(t *testing.T) {
+	readers := map[string]func(io.Reader) io.Reader{
+		"plain":   func(r io.Reader) io.Reader { return r },
+		"onebyte": iotest.OneByteReader,
+		"half":    iotest.HalfReader,
+		"dataerr": iotest.DataErrReader,
+	}
+	for _, size := range []int{0, 1, 2, streamChunkSize - 1, streamChunkSize, streamChunkSize + 1, 3*streamChunkSize + 17} {
+		payload := streamPayload(size)
+		for name, wrap := range readers {
+			dec := NewDecoder(wrap(strings.NewReader(Encode(payload))))
+			got, err := io.ReadAll(dec)
+			require.NoError(t, err, "reader %s, size %d", name, size)
+			require.Equal(t, payload, got, "reader %s, size %d", name, size)
+		}
+	}
+}
+
+func TestDecoderSmallReads(t *testing.T) {
+	// Reading one byte at a time from a one-byte-at-a-time source forces every
+	// byte's two nibbles to arrive in separate reads of the underlying stream.
+	payload := streamPayload(513)
+	dec := NewDecoder(iotest.OneByteReader(strings.NewReader(Encode(payload))))
+	got := make([]byte, 0, len(payload))
+	var one [1]byte
+	for {
+		n, err := dec.Read(one[:])
+		got = append(got, one[:n]...)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	require.Equal(t, payload, got)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr error
+	}{
+		{input: ``, wantErr: ErrEmptyString},
+		{input: `0`, wantErr: ErrMissingPrefix},
+		{input: `00`, wantErr: ErrMissingPrefix},
+		{input: `0x0`, wantErr: ErrOddLength},
+		{input: `0x023`, wantErr: ErrOddLength},
+		{input: `0xxx`, wantErr: ErrSyntax},
+		{input: `0x01zz01`, wantErr: ErrSyntax},
+	}
+	for _, test := range tests {
+		_, err := io.ReadAll(NewDecoder(iotest.OneByteReader(strings.NewReader(test.input))))
+		checkError(t, test.input, err, test.wantErr)
+	}
+}
+
+func TestEncodeToJSONWriter(t *testing.T) {
+	for _, size := range []int{0, 1, streamChunkSize + 1} {
+		payload := streamPayload(size)
+		var buf bytes.Buffer
+		require.NoError(t, EncodeToJSONWriter(&buf, payload))
+		want, err := json.Marshal(Bytes(payload))
+		require.NoError(t, err)
+		require.Equal(t, string(want), buf.String())
+	}
+}
+
+func BenchmarkEncode8MiB(b *testing.B) {
+	payload := streamPayload(8 << 20)
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = io.WriteString(io.Discard, Encode(payload))
+	}
+}
+
+func BenchmarkEncoder8MiB(b *testing.B) {
+	payload := streamPayload(8 << 20)
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = NewEncoder(io.Discard).Write(payload)
+	}
+}
+
+func BenchmarkDecoder8MiB(b *testing.B) {
+	encoded := Encode(streamPayload(8 << 20))
+	b.SetBytes(int64(len(encoded)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = io.Copy(io.Discard, NewDecoder(strings.NewReader(encoded)))
+	}
+}
@@ -119,6 +119,118 @@ func (c *SubscribeStreamC) Context() context.Context { return c.ctx }
 
 // -- end Subscribe
 
+// -- SubscribeBlocks
+
+func (s *EthBackendClientDirect) SubscribeBlocks(ctx context.Context, in *remote.SubscribeRequest, opts ...grpc.CallOption) (remote.ETHBACKEND_SubscribeBlocksClient, error) {
+	ch := make(chan *subscribeBlocksReply, 16384)
+	streamServer := &SubscribeBlocksStreamS{ch: ch, ctx: ctx}
+	go func() {
+		defer close(ch)
+		streamServer.Err(s.server.SubscribeBlocks(in, streamServer))
+	}()
+	return &SubscribeBlocksStreamC{ch: ch, ctx: ctx}, nil
+}
+
+type subscribeBlocksReply struct {
+	r   *remote.SubscribeBlocksReply
+	err error
+}
+type SubscribeBlocksStreamS struct {
+	ch  chan *subscribeBlocksReply
+	ctx context.Context
+	grpc.ServerStream
+}
+
+func (s *SubscribeBlocksStreamS) Send(m *remote.SubscribeBlocksReply) error {
+	s.ch <- &subscribeBlocksReply{r: m}
+	return nil
+}
+func (s *SubscribeBlocksStreamS) Context() context.Context { return s.ctx }
+func (s *SubscribeBlocksStreamS) Err(err error) {
+	if err == nil {
+		return
+	}
+	s.ch <- &subscribeBlocksReply{err: err}
+}
+
+type SubscribeBlocksStreamC struct {
+	ch  chan *subscribeBlocksReply
+	ctx context.Context
+	grpc.ClientStream
+}
+
+func (c *SubscribeBlocksStreamC) Recv() (*remote.SubscribeBlocksReply, error) {
+	select {
+	case m, ok := <-c.ch:
+		if !ok || m == nil {
+			return nil, io.EOF
+		}
+		return m.r, m.err
+	case <-c.ctx.Done():
+		return nil, c.ctx.Err()
+	}
+}
+
+func (c *SubscribeBlocksStreamC) Context() context.Context { return c.ctx }
+
+// -- end SubscribeBlocks
+
+// -- SubscribePeerEvents
+
+func (s *EthBackendClientDirect) SubscribePeerEvents(ctx context.Context, in *remote.SubscribeRequest, opts ...grpc.CallOption) (remote.ETHBACKEND_SubscribePeerEventsClient, error) {
+	ch := make(chan *subscribePeerEventsReply, 16384)
+	streamServer := &SubscribePeerEventsStreamS{ch: ch, ctx: ctx}
+	go func() {
+		defer close(ch)
+		streamServer.Err(s.server.SubscribePeerEvents(in, streamServer))
+	}()
+	return &SubscribePeerEventsStreamC{ch: ch, ctx: ctx}, nil
+}
+
+type subscribePeerEventsReply struct {
+	r   *remote.SubscribePeerEventsReply
+	err error
+}
+type SubscribePeerEventsStreamS struct {
+	ch  chan *subscribePeerEventsReply
+	ctx context.Context
+	grpc.ServerStream
+}
+
+func (s *SubscribePeerEventsStreamS) Send(m *remote.SubscribePeerEventsReply) error {
+	s.ch <- &subscribePeerEventsReply{r: m}
+	return nil
+}
+func (s *SubscribePeerEventsStreamS) Context() context.Context { return s.ctx }
+func (s *SubscribePeerEventsStreamS) Err(err error) {
+	if err == nil {
+		return
+	}
+	s.ch <- &subscribePeerEventsReply{err: err}
+}
+
+type SubscribePeerEventsStreamC struct {
+	ch  chan *subscribePeerEventsReply
+	ctx context.Context
+	grpc.ClientStream
+}
+
+func (c *SubscribePeerEventsStreamC) Recv() (*remote.SubscribePeerEventsReply, error) {
+	select {
+	case m, ok := <-c.ch:
+		if !ok || m == nil {
+			return nil, io.EOF
+		}
+		return m.r, m.err
+	case <-c.ctx.Done():
+		return nil, c.ctx.Err()
+	}
+}
+
+func (c *SubscribePeerEventsStreamC) Context() context.Context { return c.ctx }
+
+// -- end SubscribePeerEvents
+
 // -- SubscribeLogs
 
 func (s *EthBackendClientDirect) SubscribeLogs(ctx context.Context, opts ...grpc.CallOption) (remote.ETHBACKEND_SubscribeLogsClient, error) {
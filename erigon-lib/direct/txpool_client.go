@@ -56,6 +56,10 @@ func (s *TxPoolClient) All(ctx context.Context, in *txpool_proto.AllRequest, opt
 	return s.server.All(ctx, in)
 }
 
+func (s *TxPoolClient) Content(ctx context.Context, in *txpool_proto.ContentRequest, opts ...grpc.CallOption) (*txpool_proto.ContentReply, error) {
+	return s.server.Content(ctx, in)
+}
+
 func (s *TxPoolClient) Pending(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*txpool_proto.PendingReply, error) {
 	return s.server.Pending(ctx, in)
 }
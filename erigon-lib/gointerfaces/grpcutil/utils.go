@@ -142,6 +142,14 @@ func IsRetryLater(err error) bool {
 	return false
 }
 
+// IsUnimplemented tells if the server doesn't support the method, e.g. a remote running an older version
+func IsUnimplemented(err error) bool {
+	if s, ok := status.FromError(err); ok {
+		return s.Code() == codes.Unimplemented
+	}
+	return false
+}
+
 func IsEndOfStream(err error) bool {
 	if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
 		return true
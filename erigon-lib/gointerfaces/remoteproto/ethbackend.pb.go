@@ -78,6 +78,52 @@ func (Event) EnumDescriptor() ([]byte, []int) {
 	return file_remote_ethbackend_proto_rawDescGZIP(), []int{0}
 }
 
+type SubscribePeerEventsReply_PeerEventId int32
+
+const (
+	SubscribePeerEventsReply_Connect    SubscribePeerEventsReply_PeerEventId = 0
+	SubscribePeerEventsReply_Disconnect SubscribePeerEventsReply_PeerEventId = 1
+)
+
+// Enum value maps for SubscribePeerEventsReply_PeerEventId.
+var (
+	SubscribePeerEventsReply_PeerEventId_name = map[int32]string{
+		0: "Connect",
+		1: "Disconnect",
+	}
+	SubscribePeerEventsReply_PeerEventId_value = map[string]int32{
+		"Connect":    0,
+		"Disconnect": 1,
+	}
+)
+
+func (x SubscribePeerEventsReply_PeerEventId) Enum() *SubscribePeerEventsReply_PeerEventId {
+	p := new(SubscribePeerEventsReply_PeerEventId)
+	*p = x
+	return p
+}
+
+func (x SubscribePeerEventsReply_PeerEventId) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SubscribePeerEventsReply_PeerEventId) Descriptor() protoreflect.EnumDescriptor {
+	return file_remote_ethbackend_proto_enumTypes[1].Descriptor()
+}
+
+func (SubscribePeerEventsReply_PeerEventId) Type() protoreflect.EnumType {
+	return &file_remote_ethbackend_proto_enumTypes[1]
+}
+
+func (x SubscribePeerEventsReply_PeerEventId) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SubscribePeerEventsReply_PeerEventId.Descriptor instead.
+func (SubscribePeerEventsReply_PeerEventId) EnumDescriptor() ([]byte, []int) {
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{22, 0}
+}
+
 type EtherbaseRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -239,12 +285,14 @@ func (x *NetVersionReply) GetId() uint64 {
 }
 
 type SyncingReply struct {
-	state            protoimpl.MessageState        `protogen:"open.v1"`
-	LastNewBlockSeen uint64                        `protobuf:"varint,1,opt,name=last_new_block_seen,json=lastNewBlockSeen,proto3" json:"last_new_block_seen,omitempty"`
-	FrozenBlocks     uint64                        `protobuf:"varint,2,opt,name=frozen_blocks,json=frozenBlocks,proto3" json:"frozen_blocks,omitempty"`
-	CurrentBlock     uint64                        `protobuf:"varint,3,opt,name=current_block,json=currentBlock,proto3" json:"current_block,omitempty"`
-	Syncing          bool                          `protobuf:"varint,4,opt,name=syncing,proto3" json:"syncing,omitempty"`
-	Stages           []*SyncingReply_StageProgress `protobuf:"bytes,5,rep,name=stages,proto3" json:"stages,omitempty"`
+	state            protoimpl.MessageState          `protogen:"open.v1"`
+	LastNewBlockSeen uint64                          `protobuf:"varint,1,opt,name=last_new_block_seen,json=lastNewBlockSeen,proto3" json:"last_new_block_seen,omitempty"`
+	FrozenBlocks     uint64                          `protobuf:"varint,2,opt,name=frozen_blocks,json=frozenBlocks,proto3" json:"frozen_blocks,omitempty"`
+	CurrentBlock     uint64                          `protobuf:"varint,3,opt,name=current_block,json=currentBlock,proto3" json:"current_block,omitempty"`
+	Syncing          bool                            `protobuf:"varint,4,opt,name=syncing,proto3" json:"syncing,omitempty"`
+	Stages           []*SyncingReply_StageProgress   `protobuf:"bytes,5,rep,name=stages,proto3" json:"stages,omitempty"`
+	Snapshots        *SyncingReply_SnapshotsProgress `protobuf:"bytes,6,opt,name=snapshots,proto3" json:"snapshots,omitempty"`               // unset unless the snapshots are being downloaded or indexed
+	SyncMode         string                          `protobuf:"bytes,7,opt,name=sync_mode,json=syncMode,proto3" json:"sync_mode,omitempty"` // of the header downloader, unset if unknown
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
@@ -314,6 +362,20 @@ func (x *SyncingReply) GetStages() []*SyncingReply_StageProgress {
 	return nil
 }
 
+func (x *SyncingReply) GetSnapshots() *SyncingReply_SnapshotsProgress {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+func (x *SyncingReply) GetSyncMode() string {
+	if x != nil {
+		return x.SyncMode
+	}
+	return ""
+}
+
 type NetPeerCountRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -1090,6 +1152,162 @@ func (x *SubscribeLogsReply) GetRemoved() bool {
 	return false
 }
 
+// SubscribeBlocksReply is a new canonical block with its body. The first block of a new branch also
+// carries the reorg it completes: the head of the old branch and the last block common to both branches.
+type SubscribeBlocksReply struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	HeaderRlp      []byte                 `protobuf:"bytes,1,opt,name=header_rlp,json=headerRlp,proto3" json:"header_rlp,omitempty"`
+	BodyRlp        []byte                 `protobuf:"bytes,2,opt,name=body_rlp,json=bodyRlp,proto3" json:"body_rlp,omitempty"`
+	NewHead        *typesproto.H256       `protobuf:"bytes,3,opt,name=new_head,json=newHead,proto3" json:"new_head,omitempty"`                      // head of the chain once all the blocks of the update are applied
+	OldHead        *typesproto.H256       `protobuf:"bytes,4,opt,name=old_head,json=oldHead,proto3" json:"old_head,omitempty"`                      // unset if unknown, e.g. for the first update after a restart
+	CommonAncestor *typesproto.H256       `protobuf:"bytes,5,opt,name=common_ancestor,json=commonAncestor,proto3" json:"common_ancestor,omitempty"` // unset if the block doesn't follow a reorg
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SubscribeBlocksReply) Reset() {
+	*x = SubscribeBlocksReply{}
+	mi := &file_remote_ethbackend_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeBlocksReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeBlocksReply) ProtoMessage() {}
+
+func (x *SubscribeBlocksReply) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_ethbackend_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeBlocksReply.ProtoReflect.Descriptor instead.
+func (*SubscribeBlocksReply) Descriptor() ([]byte, []int) {
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SubscribeBlocksReply) GetHeaderRlp() []byte {
+	if x != nil {
+		return x.HeaderRlp
+	}
+	return nil
+}
+
+func (x *SubscribeBlocksReply) GetBodyRlp() []byte {
+	if x != nil {
+		return x.BodyRlp
+	}
+	return nil
+}
+
+func (x *SubscribeBlocksReply) GetNewHead() *typesproto.H256 {
+	if x != nil {
+		return x.NewHead
+	}
+	return nil
+}
+
+func (x *SubscribeBlocksReply) GetOldHead() *typesproto.H256 {
+	if x != nil {
+		return x.OldHead
+	}
+	return nil
+}
+
+func (x *SubscribeBlocksReply) GetCommonAncestor() *typesproto.H256 {
+	if x != nil {
+		return x.CommonAncestor
+	}
+	return nil
+}
+
+// SubscribePeerEventsReply is a peer connecting to or disconnecting from one of the sentries. The enode, name
+// and caps are only known for connections, and only if the sentry could still find the peer.
+type SubscribePeerEventsReply struct {
+	state         protoimpl.MessageState               `protogen:"open.v1"`
+	EventId       SubscribePeerEventsReply_PeerEventId `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3,enum=remote.SubscribePeerEventsReply_PeerEventId" json:"event_id,omitempty"`
+	PeerId        *typesproto.H512                     `protobuf:"bytes,2,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Enode         string                               `protobuf:"bytes,3,opt,name=enode,proto3" json:"enode,omitempty"`
+	Name          string                               `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Caps          []string                             `protobuf:"bytes,5,rep,name=caps,proto3" json:"caps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribePeerEventsReply) Reset() {
+	*x = SubscribePeerEventsReply{}
+	mi := &file_remote_ethbackend_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribePeerEventsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribePeerEventsReply) ProtoMessage() {}
+
+func (x *SubscribePeerEventsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_ethbackend_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribePeerEventsReply.ProtoReflect.Descriptor instead.
+func (*SubscribePeerEventsReply) Descriptor() ([]byte, []int) {
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SubscribePeerEventsReply) GetEventId() SubscribePeerEventsReply_PeerEventId {
+	if x != nil {
+		return x.EventId
+	}
+	return SubscribePeerEventsReply_Connect
+}
+
+func (x *SubscribePeerEventsReply) GetPeerId() *typesproto.H512 {
+	if x != nil {
+		return x.PeerId
+	}
+	return nil
+}
+
+func (x *SubscribePeerEventsReply) GetEnode() string {
+	if x != nil {
+		return x.Enode
+	}
+	return ""
+}
+
+func (x *SubscribePeerEventsReply) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SubscribePeerEventsReply) GetCaps() []string {
+	if x != nil {
+		return x.Caps
+	}
+	return nil
+}
+
 type BlockRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BlockHeight   uint64                 `protobuf:"varint,2,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
@@ -1100,7 +1318,7 @@ type BlockRequest struct {
 
 func (x *BlockRequest) Reset() {
 	*x = BlockRequest{}
-	mi := &file_remote_ethbackend_proto_msgTypes[21]
+	mi := &file_remote_ethbackend_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1112,7 +1330,7 @@ func (x *BlockRequest) String() string {
 func (*BlockRequest) ProtoMessage() {}
 
 func (x *BlockRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[21]
+	mi := &file_remote_ethbackend_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1125,7 +1343,7 @@ func (x *BlockRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlockRequest.ProtoReflect.Descriptor instead.
 func (*BlockRequest) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{21}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *BlockRequest) GetBlockHeight() uint64 {
@@ -1152,7 +1370,7 @@ type BlockReply struct {
 
 func (x *BlockReply) Reset() {
 	*x = BlockReply{}
-	mi := &file_remote_ethbackend_proto_msgTypes[22]
+	mi := &file_remote_ethbackend_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1164,7 +1382,7 @@ func (x *BlockReply) String() string {
 func (*BlockReply) ProtoMessage() {}
 
 func (x *BlockReply) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[22]
+	mi := &file_remote_ethbackend_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1177,7 +1395,7 @@ func (x *BlockReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlockReply.ProtoReflect.Descriptor instead.
 func (*BlockReply) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{22}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *BlockReply) GetBlockRlp() []byte {
@@ -1203,7 +1421,7 @@ type TxnLookupRequest struct {
 
 func (x *TxnLookupRequest) Reset() {
 	*x = TxnLookupRequest{}
-	mi := &file_remote_ethbackend_proto_msgTypes[23]
+	mi := &file_remote_ethbackend_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1215,7 +1433,7 @@ func (x *TxnLookupRequest) String() string {
 func (*TxnLookupRequest) ProtoMessage() {}
 
 func (x *TxnLookupRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[23]
+	mi := &file_remote_ethbackend_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1228,7 +1446,7 @@ func (x *TxnLookupRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxnLookupRequest.ProtoReflect.Descriptor instead.
 func (*TxnLookupRequest) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{23}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *TxnLookupRequest) GetTxnHash() *typesproto.H256 {
@@ -1248,7 +1466,7 @@ type TxnLookupReply struct {
 
 func (x *TxnLookupReply) Reset() {
 	*x = TxnLookupReply{}
-	mi := &file_remote_ethbackend_proto_msgTypes[24]
+	mi := &file_remote_ethbackend_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1260,7 +1478,7 @@ func (x *TxnLookupReply) String() string {
 func (*TxnLookupReply) ProtoMessage() {}
 
 func (x *TxnLookupReply) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[24]
+	mi := &file_remote_ethbackend_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1273,7 +1491,7 @@ func (x *TxnLookupReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TxnLookupReply.ProtoReflect.Descriptor instead.
 func (*TxnLookupReply) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{24}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *TxnLookupReply) GetBlockNumber() uint64 {
@@ -1299,7 +1517,7 @@ type NodesInfoRequest struct {
 
 func (x *NodesInfoRequest) Reset() {
 	*x = NodesInfoRequest{}
-	mi := &file_remote_ethbackend_proto_msgTypes[25]
+	mi := &file_remote_ethbackend_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1311,7 +1529,7 @@ func (x *NodesInfoRequest) String() string {
 func (*NodesInfoRequest) ProtoMessage() {}
 
 func (x *NodesInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[25]
+	mi := &file_remote_ethbackend_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1324,7 +1542,7 @@ func (x *NodesInfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodesInfoRequest.ProtoReflect.Descriptor instead.
 func (*NodesInfoRequest) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{25}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *NodesInfoRequest) GetLimit() uint32 {
@@ -1343,7 +1561,7 @@ type AddPeerRequest struct {
 
 func (x *AddPeerRequest) Reset() {
 	*x = AddPeerRequest{}
-	mi := &file_remote_ethbackend_proto_msgTypes[26]
+	mi := &file_remote_ethbackend_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1355,7 +1573,7 @@ func (x *AddPeerRequest) String() string {
 func (*AddPeerRequest) ProtoMessage() {}
 
 func (x *AddPeerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[26]
+	mi := &file_remote_ethbackend_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1368,7 +1586,7 @@ func (x *AddPeerRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddPeerRequest.ProtoReflect.Descriptor instead.
 func (*AddPeerRequest) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{26}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *AddPeerRequest) GetUrl() string {
@@ -1387,7 +1605,7 @@ type NodesInfoReply struct {
 
 func (x *NodesInfoReply) Reset() {
 	*x = NodesInfoReply{}
-	mi := &file_remote_ethbackend_proto_msgTypes[27]
+	mi := &file_remote_ethbackend_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1399,7 +1617,7 @@ func (x *NodesInfoReply) String() string {
 func (*NodesInfoReply) ProtoMessage() {}
 
 func (x *NodesInfoReply) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[27]
+	mi := &file_remote_ethbackend_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1412,7 +1630,7 @@ func (x *NodesInfoReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodesInfoReply.ProtoReflect.Descriptor instead.
 func (*NodesInfoReply) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{27}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *NodesInfoReply) GetNodesInfo() []*typesproto.NodeInfoReply {
@@ -1423,15 +1641,17 @@ func (x *NodesInfoReply) GetNodesInfo() []*typesproto.NodeInfoReply {
 }
 
 type PeersReply struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Peers         []*typesproto.PeerInfo `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Peers []*typesproto.PeerInfo `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	// one per sentry which couldn't list its peers, which are missing from peers
+	Errors        []string `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PeersReply) Reset() {
 	*x = PeersReply{}
-	mi := &file_remote_ethbackend_proto_msgTypes[28]
+	mi := &file_remote_ethbackend_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1443,7 +1663,7 @@ func (x *PeersReply) String() string {
 func (*PeersReply) ProtoMessage() {}
 
 func (x *PeersReply) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[28]
+	mi := &file_remote_ethbackend_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1456,7 +1676,7 @@ func (x *PeersReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PeersReply.ProtoReflect.Descriptor instead.
 func (*PeersReply) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{28}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *PeersReply) GetPeers() []*typesproto.PeerInfo {
@@ -1466,6 +1686,13 @@ func (x *PeersReply) GetPeers() []*typesproto.PeerInfo {
 	return nil
 }
 
+func (x *PeersReply) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
 type AddPeerReply struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -1475,7 +1702,7 @@ type AddPeerReply struct {
 
 func (x *AddPeerReply) Reset() {
 	*x = AddPeerReply{}
-	mi := &file_remote_ethbackend_proto_msgTypes[29]
+	mi := &file_remote_ethbackend_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1487,7 +1714,7 @@ func (x *AddPeerReply) String() string {
 func (*AddPeerReply) ProtoMessage() {}
 
 func (x *AddPeerReply) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[29]
+	mi := &file_remote_ethbackend_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1500,7 +1727,7 @@ func (x *AddPeerReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddPeerReply.ProtoReflect.Descriptor instead.
 func (*AddPeerReply) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{29}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *AddPeerReply) GetSuccess() bool {
@@ -1519,7 +1746,7 @@ type PendingBlockReply struct {
 
 func (x *PendingBlockReply) Reset() {
 	*x = PendingBlockReply{}
-	mi := &file_remote_ethbackend_proto_msgTypes[30]
+	mi := &file_remote_ethbackend_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1531,7 +1758,7 @@ func (x *PendingBlockReply) String() string {
 func (*PendingBlockReply) ProtoMessage() {}
 
 func (x *PendingBlockReply) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[30]
+	mi := &file_remote_ethbackend_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1544,7 +1771,7 @@ func (x *PendingBlockReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PendingBlockReply.ProtoReflect.Descriptor instead.
 func (*PendingBlockReply) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{30}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *PendingBlockReply) GetBlockRlp() []byte {
@@ -1563,7 +1790,7 @@ type EngineGetPayloadBodiesByHashV1Request struct {
 
 func (x *EngineGetPayloadBodiesByHashV1Request) Reset() {
 	*x = EngineGetPayloadBodiesByHashV1Request{}
-	mi := &file_remote_ethbackend_proto_msgTypes[31]
+	mi := &file_remote_ethbackend_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1575,7 +1802,7 @@ func (x *EngineGetPayloadBodiesByHashV1Request) String() string {
 func (*EngineGetPayloadBodiesByHashV1Request) ProtoMessage() {}
 
 func (x *EngineGetPayloadBodiesByHashV1Request) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[31]
+	mi := &file_remote_ethbackend_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1588,7 +1815,7 @@ func (x *EngineGetPayloadBodiesByHashV1Request) ProtoReflect() protoreflect.Mess
 
 // Deprecated: Use EngineGetPayloadBodiesByHashV1Request.ProtoReflect.Descriptor instead.
 func (*EngineGetPayloadBodiesByHashV1Request) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{31}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *EngineGetPayloadBodiesByHashV1Request) GetHashes() []*typesproto.H256 {
@@ -1608,7 +1835,7 @@ type EngineGetPayloadBodiesByRangeV1Request struct {
 
 func (x *EngineGetPayloadBodiesByRangeV1Request) Reset() {
 	*x = EngineGetPayloadBodiesByRangeV1Request{}
-	mi := &file_remote_ethbackend_proto_msgTypes[32]
+	mi := &file_remote_ethbackend_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1620,7 +1847,7 @@ func (x *EngineGetPayloadBodiesByRangeV1Request) String() string {
 func (*EngineGetPayloadBodiesByRangeV1Request) ProtoMessage() {}
 
 func (x *EngineGetPayloadBodiesByRangeV1Request) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[32]
+	mi := &file_remote_ethbackend_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1633,7 +1860,7 @@ func (x *EngineGetPayloadBodiesByRangeV1Request) ProtoReflect() protoreflect.Mes
 
 // Deprecated: Use EngineGetPayloadBodiesByRangeV1Request.ProtoReflect.Descriptor instead.
 func (*EngineGetPayloadBodiesByRangeV1Request) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{32}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *EngineGetPayloadBodiesByRangeV1Request) GetStart() uint64 {
@@ -1659,7 +1886,7 @@ type AAValidationRequest struct {
 
 func (x *AAValidationRequest) Reset() {
 	*x = AAValidationRequest{}
-	mi := &file_remote_ethbackend_proto_msgTypes[33]
+	mi := &file_remote_ethbackend_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1671,7 +1898,7 @@ func (x *AAValidationRequest) String() string {
 func (*AAValidationRequest) ProtoMessage() {}
 
 func (x *AAValidationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[33]
+	mi := &file_remote_ethbackend_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1684,7 +1911,7 @@ func (x *AAValidationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AAValidationRequest.ProtoReflect.Descriptor instead.
 func (*AAValidationRequest) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{33}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *AAValidationRequest) GetTx() *typesproto.AccountAbstractionTransaction {
@@ -1703,7 +1930,7 @@ type AAValidationReply struct {
 
 func (x *AAValidationReply) Reset() {
 	*x = AAValidationReply{}
-	mi := &file_remote_ethbackend_proto_msgTypes[34]
+	mi := &file_remote_ethbackend_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1715,7 +1942,7 @@ func (x *AAValidationReply) String() string {
 func (*AAValidationReply) ProtoMessage() {}
 
 func (x *AAValidationReply) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[34]
+	mi := &file_remote_ethbackend_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1728,7 +1955,7 @@ func (x *AAValidationReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AAValidationReply.ProtoReflect.Descriptor instead.
 func (*AAValidationReply) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{34}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *AAValidationReply) GetValid() bool {
@@ -1747,7 +1974,7 @@ type BlockForTxNumRequest struct {
 
 func (x *BlockForTxNumRequest) Reset() {
 	*x = BlockForTxNumRequest{}
-	mi := &file_remote_ethbackend_proto_msgTypes[35]
+	mi := &file_remote_ethbackend_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1759,7 +1986,7 @@ func (x *BlockForTxNumRequest) String() string {
 func (*BlockForTxNumRequest) ProtoMessage() {}
 
 func (x *BlockForTxNumRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[35]
+	mi := &file_remote_ethbackend_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1772,7 +1999,7 @@ func (x *BlockForTxNumRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlockForTxNumRequest.ProtoReflect.Descriptor instead.
 func (*BlockForTxNumRequest) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{35}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *BlockForTxNumRequest) GetTxnum() uint64 {
@@ -1792,7 +2019,7 @@ type BlockForTxNumResponse struct {
 
 func (x *BlockForTxNumResponse) Reset() {
 	*x = BlockForTxNumResponse{}
-	mi := &file_remote_ethbackend_proto_msgTypes[36]
+	mi := &file_remote_ethbackend_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1804,7 +2031,7 @@ func (x *BlockForTxNumResponse) String() string {
 func (*BlockForTxNumResponse) ProtoMessage() {}
 
 func (x *BlockForTxNumResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[36]
+	mi := &file_remote_ethbackend_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1817,7 +2044,7 @@ func (x *BlockForTxNumResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlockForTxNumResponse.ProtoReflect.Descriptor instead.
 func (*BlockForTxNumResponse) Descriptor() ([]byte, []int) {
-	return file_remote_ethbackend_proto_rawDescGZIP(), []int{36}
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *BlockForTxNumResponse) GetBlockNumber() uint64 {
@@ -1838,13 +2065,14 @@ type SyncingReply_StageProgress struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	StageName     string                 `protobuf:"bytes,1,opt,name=stage_name,json=stageName,proto3" json:"stage_name,omitempty"`
 	BlockNumber   uint64                 `protobuf:"varint,2,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	TxNum         uint64                 `protobuf:"varint,3,opt,name=tx_num,json=txNum,proto3" json:"tx_num,omitempty"` // last txn of the block the stage reached
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SyncingReply_StageProgress) Reset() {
 	*x = SyncingReply_StageProgress{}
-	mi := &file_remote_ethbackend_proto_msgTypes[37]
+	mi := &file_remote_ethbackend_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1856,7 +2084,7 @@ func (x *SyncingReply_StageProgress) String() string {
 func (*SyncingReply_StageProgress) ProtoMessage() {}
 
 func (x *SyncingReply_StageProgress) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_ethbackend_proto_msgTypes[37]
+	mi := &file_remote_ethbackend_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1886,6 +2114,65 @@ func (x *SyncingReply_StageProgress) GetBlockNumber() uint64 {
 	return 0
 }
 
+func (x *SyncingReply_StageProgress) GetTxNum() uint64 {
+	if x != nil {
+		return x.TxNum
+	}
+	return 0
+}
+
+type SyncingReply_SnapshotsProgress struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	DownloadPercent float32                `protobuf:"fixed32,1,opt,name=download_percent,json=downloadPercent,proto3" json:"download_percent,omitempty"`
+	IndexingPercent float32                `protobuf:"fixed32,2,opt,name=indexing_percent,json=indexingPercent,proto3" json:"indexing_percent,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SyncingReply_SnapshotsProgress) Reset() {
+	*x = SyncingReply_SnapshotsProgress{}
+	mi := &file_remote_ethbackend_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncingReply_SnapshotsProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncingReply_SnapshotsProgress) ProtoMessage() {}
+
+func (x *SyncingReply_SnapshotsProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_ethbackend_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncingReply_SnapshotsProgress.ProtoReflect.Descriptor instead.
+func (*SyncingReply_SnapshotsProgress) Descriptor() ([]byte, []int) {
+	return file_remote_ethbackend_proto_rawDescGZIP(), []int{4, 1}
+}
+
+func (x *SyncingReply_SnapshotsProgress) GetDownloadPercent() float32 {
+	if x != nil {
+		return x.DownloadPercent
+	}
+	return 0
+}
+
+func (x *SyncingReply_SnapshotsProgress) GetIndexingPercent() float32 {
+	if x != nil {
+		return x.IndexingPercent
+	}
+	return 0
+}
+
 var File_remote_ethbackend_proto protoreflect.FileDescriptor
 
 const file_remote_ethbackend_proto_rawDesc = "" +
@@ -1896,17 +2183,23 @@ const file_remote_ethbackend_proto_rawDesc = "" +
 	"\aaddress\x18\x01 \x01(\v2\v.types.H160R\aaddress\"\x13\n" +
 	"\x11NetVersionRequest\"!\n" +
 	"\x0fNetVersionReply\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x04R\x02id\"\xb0\x02\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\x95\x04\n" +
 	"\fSyncingReply\x12-\n" +
 	"\x13last_new_block_seen\x18\x01 \x01(\x04R\x10lastNewBlockSeen\x12#\n" +
 	"\rfrozen_blocks\x18\x02 \x01(\x04R\ffrozenBlocks\x12#\n" +
 	"\rcurrent_block\x18\x03 \x01(\x04R\fcurrentBlock\x12\x18\n" +
 	"\asyncing\x18\x04 \x01(\bR\asyncing\x12:\n" +
-	"\x06stages\x18\x05 \x03(\v2\".remote.SyncingReply.StageProgressR\x06stages\x1aQ\n" +
+	"\x06stages\x18\x05 \x03(\v2\".remote.SyncingReply.StageProgressR\x06stages\x12D\n" +
+	"\tsnapshots\x18\x06 \x01(\v2&.remote.SyncingReply.SnapshotsProgressR\tsnapshots\x12\x1b\n" +
+	"\tsync_mode\x18\a \x01(\tR\bsyncMode\x1ah\n" +
 	"\rStageProgress\x12\x1d\n" +
 	"\n" +
 	"stage_name\x18\x01 \x01(\tR\tstageName\x12!\n" +
-	"\fblock_number\x18\x02 \x01(\x04R\vblockNumber\"\x15\n" +
+	"\fblock_number\x18\x02 \x01(\x04R\vblockNumber\x12\x15\n" +
+	"\x06tx_num\x18\x03 \x01(\x04R\x05txNum\x1ai\n" +
+	"\x11SnapshotsProgress\x12)\n" +
+	"\x10download_percent\x18\x01 \x01(\x02R\x0fdownloadPercent\x12)\n" +
+	"\x10indexing_percent\x18\x02 \x01(\x02R\x0findexingPercent\"\x15\n" +
 	"\x13NetPeerCountRequest\")\n" +
 	"\x11NetPeerCountReply\x12\x14\n" +
 	"\x05count\x18\x01 \x01(\x04R\x05count\"\x18\n" +
@@ -1950,7 +2243,24 @@ const file_remote_ethbackend_proto_rawDesc = "" +
 	"\x06topics\x18\x06 \x03(\v2\v.types.H256R\x06topics\x126\n" +
 	"\x10transaction_hash\x18\a \x01(\v2\v.types.H256R\x0ftransactionHash\x12+\n" +
 	"\x11transaction_index\x18\b \x01(\x04R\x10transactionIndex\x12\x18\n" +
-	"\aremoved\x18\t \x01(\bR\aremoved\"]\n" +
+	"\aremoved\x18\t \x01(\bR\aremoved\"\xd6\x01\n" +
+	"\x14SubscribeBlocksReply\x12\x1d\n" +
+	"\n" +
+	"header_rlp\x18\x01 \x01(\fR\theaderRlp\x12\x19\n" +
+	"\bbody_rlp\x18\x02 \x01(\fR\abodyRlp\x12&\n" +
+	"\bnew_head\x18\x03 \x01(\v2\v.types.H256R\anewHead\x12&\n" +
+	"\bold_head\x18\x04 \x01(\v2\v.types.H256R\aoldHead\x124\n" +
+	"\x0fcommon_ancestor\x18\x05 \x01(\v2\v.types.H256R\x0ecommonAncestor\"\xf3\x01\n" +
+	"\x18SubscribePeerEventsReply\x12G\n" +
+	"\bevent_id\x18\x01 \x01(\x0e2,.remote.SubscribePeerEventsReply.PeerEventIdR\aeventId\x12$\n" +
+	"\apeer_id\x18\x02 \x01(\v2\v.types.H512R\x06peerId\x12\x14\n" +
+	"\x05enode\x18\x03 \x01(\tR\x05enode\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x12\n" +
+	"\x04caps\x18\x05 \x03(\tR\x04caps\"*\n" +
+	"\vPeerEventId\x12\v\n" +
+	"\aConnect\x10\x00\x12\x0e\n" +
+	"\n" +
+	"Disconnect\x10\x01\"]\n" +
 	"\fBlockRequest\x12!\n" +
 	"\fblock_height\x18\x02 \x01(\x04R\vblockHeight\x12*\n" +
 	"\n" +
@@ -1970,10 +2280,11 @@ const file_remote_ethbackend_proto_rawDesc = "" +
 	"\x03url\x18\x01 \x01(\tR\x03url\"E\n" +
 	"\x0eNodesInfoReply\x123\n" +
 	"\n" +
-	"nodes_info\x18\x01 \x03(\v2\x14.types.NodeInfoReplyR\tnodesInfo\"3\n" +
+	"nodes_info\x18\x01 \x03(\v2\x14.types.NodeInfoReplyR\tnodesInfo\"K\n" +
 	"\n" +
 	"PeersReply\x12%\n" +
-	"\x05peers\x18\x01 \x03(\v2\x0f.types.PeerInfoR\x05peers\"(\n" +
+	"\x05peers\x18\x01 \x03(\v2\x0f.types.PeerInfoR\x05peers\x12\x16\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\"(\n" +
 	"\fAddPeerReply\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\"0\n" +
 	"\x11PendingBlockReply\x12\x1b\n" +
@@ -1997,7 +2308,7 @@ const file_remote_ethbackend_proto_rawDesc = "" +
 	"\x06HEADER\x10\x00\x12\x10\n" +
 	"\fPENDING_LOGS\x10\x01\x12\x11\n" +
 	"\rPENDING_BLOCK\x10\x02\x12\x10\n" +
-	"\fNEW_SNAPSHOT\x10\x032\xe9\v\n" +
+	"\fNEW_SNAPSHOT\x10\x032\x8b\r\n" +
 	"\n" +
 	"ETHBACKEND\x12=\n" +
 	"\tEtherbase\x12\x18.remote.EtherbaseRequest\x1a\x16.remote.EtherbaseReply\x12@\n" +
@@ -2009,7 +2320,9 @@ const file_remote_ethbackend_proto_rawDesc = "" +
 	"\x0fProtocolVersion\x12\x1e.remote.ProtocolVersionRequest\x1a\x1c.remote.ProtocolVersionReply\x12I\n" +
 	"\rClientVersion\x12\x1c.remote.ClientVersionRequest\x1a\x1a.remote.ClientVersionReply\x12?\n" +
 	"\tSubscribe\x12\x18.remote.SubscribeRequest\x1a\x16.remote.SubscribeReply0\x01\x12J\n" +
-	"\rSubscribeLogs\x12\x19.remote.LogsFilterRequest\x1a\x1a.remote.SubscribeLogsReply(\x010\x01\x121\n" +
+	"\rSubscribeLogs\x12\x19.remote.LogsFilterRequest\x1a\x1a.remote.SubscribeLogsReply(\x010\x01\x12K\n" +
+	"\x0fSubscribeBlocks\x12\x18.remote.SubscribeRequest\x1a\x1c.remote.SubscribeBlocksReply0\x01\x12S\n" +
+	"\x13SubscribePeerEvents\x12\x18.remote.SubscribeRequest\x1a .remote.SubscribePeerEventsReply0\x01\x121\n" +
 	"\x05Block\x12\x14.remote.BlockRequest\x1a\x12.remote.BlockReply\x12g\n" +
 	"\x17CanonicalBodyForStorage\x12&.remote.CanonicalBodyForStorageRequest\x1a$.remote.CanonicalBodyForStorageReply\x12I\n" +
 	"\rCanonicalHash\x12\x1c.remote.CanonicalHashRequest\x1a\x1a.remote.CanonicalHashReply\x12F\n" +
@@ -2036,128 +2349,143 @@ func file_remote_ethbackend_proto_rawDescGZIP() []byte {
 	return file_remote_ethbackend_proto_rawDescData
 }
 
-var file_remote_ethbackend_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_remote_ethbackend_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
+var file_remote_ethbackend_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_remote_ethbackend_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
 var file_remote_ethbackend_proto_goTypes = []any{
-	(Event)(0),                                       // 0: remote.Event
-	(*EtherbaseRequest)(nil),                         // 1: remote.EtherbaseRequest
-	(*EtherbaseReply)(nil),                           // 2: remote.EtherbaseReply
-	(*NetVersionRequest)(nil),                        // 3: remote.NetVersionRequest
-	(*NetVersionReply)(nil),                          // 4: remote.NetVersionReply
-	(*SyncingReply)(nil),                             // 5: remote.SyncingReply
-	(*NetPeerCountRequest)(nil),                      // 6: remote.NetPeerCountRequest
-	(*NetPeerCountReply)(nil),                        // 7: remote.NetPeerCountReply
-	(*ProtocolVersionRequest)(nil),                   // 8: remote.ProtocolVersionRequest
-	(*ProtocolVersionReply)(nil),                     // 9: remote.ProtocolVersionReply
-	(*ClientVersionRequest)(nil),                     // 10: remote.ClientVersionRequest
-	(*ClientVersionReply)(nil),                       // 11: remote.ClientVersionReply
-	(*CanonicalHashRequest)(nil),                     // 12: remote.CanonicalHashRequest
-	(*CanonicalHashReply)(nil),                       // 13: remote.CanonicalHashReply
-	(*HeaderNumberRequest)(nil),                      // 14: remote.HeaderNumberRequest
-	(*HeaderNumberReply)(nil),                        // 15: remote.HeaderNumberReply
-	(*CanonicalBodyForStorageRequest)(nil),           // 16: remote.CanonicalBodyForStorageRequest
-	(*CanonicalBodyForStorageReply)(nil),             // 17: remote.CanonicalBodyForStorageReply
-	(*SubscribeRequest)(nil),                         // 18: remote.SubscribeRequest
-	(*SubscribeReply)(nil),                           // 19: remote.SubscribeReply
-	(*LogsFilterRequest)(nil),                        // 20: remote.LogsFilterRequest
-	(*SubscribeLogsReply)(nil),                       // 21: remote.SubscribeLogsReply
-	(*BlockRequest)(nil),                             // 22: remote.BlockRequest
-	(*BlockReply)(nil),                               // 23: remote.BlockReply
-	(*TxnLookupRequest)(nil),                         // 24: remote.TxnLookupRequest
-	(*TxnLookupReply)(nil),                           // 25: remote.TxnLookupReply
-	(*NodesInfoRequest)(nil),                         // 26: remote.NodesInfoRequest
-	(*AddPeerRequest)(nil),                           // 27: remote.AddPeerRequest
-	(*NodesInfoReply)(nil),                           // 28: remote.NodesInfoReply
-	(*PeersReply)(nil),                               // 29: remote.PeersReply
-	(*AddPeerReply)(nil),                             // 30: remote.AddPeerReply
-	(*PendingBlockReply)(nil),                        // 31: remote.PendingBlockReply
-	(*EngineGetPayloadBodiesByHashV1Request)(nil),    // 32: remote.EngineGetPayloadBodiesByHashV1Request
-	(*EngineGetPayloadBodiesByRangeV1Request)(nil),   // 33: remote.EngineGetPayloadBodiesByRangeV1Request
-	(*AAValidationRequest)(nil),                      // 34: remote.AAValidationRequest
-	(*AAValidationReply)(nil),                        // 35: remote.AAValidationReply
-	(*BlockForTxNumRequest)(nil),                     // 36: remote.BlockForTxNumRequest
-	(*BlockForTxNumResponse)(nil),                    // 37: remote.BlockForTxNumResponse
-	(*SyncingReply_StageProgress)(nil),               // 38: remote.SyncingReply.StageProgress
-	(*typesproto.H160)(nil),                          // 39: types.H160
-	(*typesproto.H256)(nil),                          // 40: types.H256
-	(*typesproto.NodeInfoReply)(nil),                 // 41: types.NodeInfoReply
-	(*typesproto.PeerInfo)(nil),                      // 42: types.PeerInfo
-	(*typesproto.AccountAbstractionTransaction)(nil), // 43: types.AccountAbstractionTransaction
-	(*emptypb.Empty)(nil),                            // 44: google.protobuf.Empty
-	(*BorTxnLookupRequest)(nil),                      // 45: remote.BorTxnLookupRequest
-	(*BorEventsRequest)(nil),                         // 46: remote.BorEventsRequest
-	(*typesproto.VersionReply)(nil),                  // 47: types.VersionReply
-	(*BorTxnLookupReply)(nil),                        // 48: remote.BorTxnLookupReply
-	(*BorEventsReply)(nil),                           // 49: remote.BorEventsReply
+	(Event)(0), // 0: remote.Event
+	(SubscribePeerEventsReply_PeerEventId)(0),        // 1: remote.SubscribePeerEventsReply.PeerEventId
+	(*EtherbaseRequest)(nil),                         // 2: remote.EtherbaseRequest
+	(*EtherbaseReply)(nil),                           // 3: remote.EtherbaseReply
+	(*NetVersionRequest)(nil),                        // 4: remote.NetVersionRequest
+	(*NetVersionReply)(nil),                          // 5: remote.NetVersionReply
+	(*SyncingReply)(nil),                             // 6: remote.SyncingReply
+	(*NetPeerCountRequest)(nil),                      // 7: remote.NetPeerCountRequest
+	(*NetPeerCountReply)(nil),                        // 8: remote.NetPeerCountReply
+	(*ProtocolVersionRequest)(nil),                   // 9: remote.ProtocolVersionRequest
+	(*ProtocolVersionReply)(nil),                     // 10: remote.ProtocolVersionReply
+	(*ClientVersionRequest)(nil),                     // 11: remote.ClientVersionRequest
+	(*ClientVersionReply)(nil),                       // 12: remote.ClientVersionReply
+	(*CanonicalHashRequest)(nil),                     // 13: remote.CanonicalHashRequest
+	(*CanonicalHashReply)(nil),                       // 14: remote.CanonicalHashReply
+	(*HeaderNumberRequest)(nil),                      // 15: remote.HeaderNumberRequest
+	(*HeaderNumberReply)(nil),                        // 16: remote.HeaderNumberReply
+	(*CanonicalBodyForStorageRequest)(nil),           // 17: remote.CanonicalBodyForStorageRequest
+	(*CanonicalBodyForStorageReply)(nil),             // 18: remote.CanonicalBodyForStorageReply
+	(*SubscribeRequest)(nil),                         // 19: remote.SubscribeRequest
+	(*SubscribeReply)(nil),                           // 20: remote.SubscribeReply
+	(*LogsFilterRequest)(nil),                        // 21: remote.LogsFilterRequest
+	(*SubscribeLogsReply)(nil),                       // 22: remote.SubscribeLogsReply
+	(*SubscribeBlocksReply)(nil),                     // 23: remote.SubscribeBlocksReply
+	(*SubscribePeerEventsReply)(nil),                 // 24: remote.SubscribePeerEventsReply
+	(*BlockRequest)(nil),                             // 25: remote.BlockRequest
+	(*BlockReply)(nil),                               // 26: remote.BlockReply
+	(*TxnLookupRequest)(nil),                         // 27: remote.TxnLookupRequest
+	(*TxnLookupReply)(nil),                           // 28: remote.TxnLookupReply
+	(*NodesInfoRequest)(nil),                         // 29: remote.NodesInfoRequest
+	(*AddPeerRequest)(nil),                           // 30: remote.AddPeerRequest
+	(*NodesInfoReply)(nil),                           // 31: remote.NodesInfoReply
+	(*PeersReply)(nil),                               // 32: remote.PeersReply
+	(*AddPeerReply)(nil),                             // 33: remote.AddPeerReply
+	(*PendingBlockReply)(nil),                        // 34: remote.PendingBlockReply
+	(*EngineGetPayloadBodiesByHashV1Request)(nil),    // 35: remote.EngineGetPayloadBodiesByHashV1Request
+	(*EngineGetPayloadBodiesByRangeV1Request)(nil),   // 36: remote.EngineGetPayloadBodiesByRangeV1Request
+	(*AAValidationRequest)(nil),                      // 37: remote.AAValidationRequest
+	(*AAValidationReply)(nil),                        // 38: remote.AAValidationReply
+	(*BlockForTxNumRequest)(nil),                     // 39: remote.BlockForTxNumRequest
+	(*BlockForTxNumResponse)(nil),                    // 40: remote.BlockForTxNumResponse
+	(*SyncingReply_StageProgress)(nil),               // 41: remote.SyncingReply.StageProgress
+	(*SyncingReply_SnapshotsProgress)(nil),           // 42: remote.SyncingReply.SnapshotsProgress
+	(*typesproto.H160)(nil),                          // 43: types.H160
+	(*typesproto.H256)(nil),                          // 44: types.H256
+	(*typesproto.H512)(nil),                          // 45: types.H512
+	(*typesproto.NodeInfoReply)(nil),                 // 46: types.NodeInfoReply
+	(*typesproto.PeerInfo)(nil),                      // 47: types.PeerInfo
+	(*typesproto.AccountAbstractionTransaction)(nil), // 48: types.AccountAbstractionTransaction
+	(*emptypb.Empty)(nil),                            // 49: google.protobuf.Empty
+	(*BorTxnLookupRequest)(nil),                      // 50: remote.BorTxnLookupRequest
+	(*BorEventsRequest)(nil),                         // 51: remote.BorEventsRequest
+	(*typesproto.VersionReply)(nil),                  // 52: types.VersionReply
+	(*BorTxnLookupReply)(nil),                        // 53: remote.BorTxnLookupReply
+	(*BorEventsReply)(nil),                           // 54: remote.BorEventsReply
 }
 var file_remote_ethbackend_proto_depIdxs = []int32{
-	39, // 0: remote.EtherbaseReply.address:type_name -> types.H160
-	38, // 1: remote.SyncingReply.stages:type_name -> remote.SyncingReply.StageProgress
-	40, // 2: remote.CanonicalHashReply.hash:type_name -> types.H256
-	40, // 3: remote.HeaderNumberRequest.hash:type_name -> types.H256
-	0,  // 4: remote.SubscribeRequest.type:type_name -> remote.Event
-	0,  // 5: remote.SubscribeReply.type:type_name -> remote.Event
-	39, // 6: remote.LogsFilterRequest.addresses:type_name -> types.H160
-	40, // 7: remote.LogsFilterRequest.topics:type_name -> types.H256
-	39, // 8: remote.SubscribeLogsReply.address:type_name -> types.H160
-	40, // 9: remote.SubscribeLogsReply.block_hash:type_name -> types.H256
-	40, // 10: remote.SubscribeLogsReply.topics:type_name -> types.H256
-	40, // 11: remote.SubscribeLogsReply.transaction_hash:type_name -> types.H256
-	40, // 12: remote.BlockRequest.block_hash:type_name -> types.H256
-	40, // 13: remote.TxnLookupRequest.txn_hash:type_name -> types.H256
-	41, // 14: remote.NodesInfoReply.nodes_info:type_name -> types.NodeInfoReply
-	42, // 15: remote.PeersReply.peers:type_name -> types.PeerInfo
-	40, // 16: remote.EngineGetPayloadBodiesByHashV1Request.hashes:type_name -> types.H256
-	43, // 17: remote.AAValidationRequest.tx:type_name -> types.AccountAbstractionTransaction
-	1,  // 18: remote.ETHBACKEND.Etherbase:input_type -> remote.EtherbaseRequest
-	3,  // 19: remote.ETHBACKEND.NetVersion:input_type -> remote.NetVersionRequest
-	6,  // 20: remote.ETHBACKEND.NetPeerCount:input_type -> remote.NetPeerCountRequest
-	44, // 21: remote.ETHBACKEND.Version:input_type -> google.protobuf.Empty
-	44, // 22: remote.ETHBACKEND.Syncing:input_type -> google.protobuf.Empty
-	8,  // 23: remote.ETHBACKEND.ProtocolVersion:input_type -> remote.ProtocolVersionRequest
-	10, // 24: remote.ETHBACKEND.ClientVersion:input_type -> remote.ClientVersionRequest
-	18, // 25: remote.ETHBACKEND.Subscribe:input_type -> remote.SubscribeRequest
-	20, // 26: remote.ETHBACKEND.SubscribeLogs:input_type -> remote.LogsFilterRequest
-	22, // 27: remote.ETHBACKEND.Block:input_type -> remote.BlockRequest
-	16, // 28: remote.ETHBACKEND.CanonicalBodyForStorage:input_type -> remote.CanonicalBodyForStorageRequest
-	12, // 29: remote.ETHBACKEND.CanonicalHash:input_type -> remote.CanonicalHashRequest
-	14, // 30: remote.ETHBACKEND.HeaderNumber:input_type -> remote.HeaderNumberRequest
-	24, // 31: remote.ETHBACKEND.TxnLookup:input_type -> remote.TxnLookupRequest
-	26, // 32: remote.ETHBACKEND.NodeInfo:input_type -> remote.NodesInfoRequest
-	44, // 33: remote.ETHBACKEND.Peers:input_type -> google.protobuf.Empty
-	27, // 34: remote.ETHBACKEND.AddPeer:input_type -> remote.AddPeerRequest
-	44, // 35: remote.ETHBACKEND.PendingBlock:input_type -> google.protobuf.Empty
-	45, // 36: remote.ETHBACKEND.BorTxnLookup:input_type -> remote.BorTxnLookupRequest
-	46, // 37: remote.ETHBACKEND.BorEvents:input_type -> remote.BorEventsRequest
-	34, // 38: remote.ETHBACKEND.AAValidation:input_type -> remote.AAValidationRequest
-	36, // 39: remote.ETHBACKEND.BlockForTxNum:input_type -> remote.BlockForTxNumRequest
-	2,  // 40: remote.ETHBACKEND.Etherbase:output_type -> remote.EtherbaseReply
-	4,  // 41: remote.ETHBACKEND.NetVersion:output_type -> remote.NetVersionReply
-	7,  // 42: remote.ETHBACKEND.NetPeerCount:output_type -> remote.NetPeerCountReply
-	47, // 43: remote.ETHBACKEND.Version:output_type -> types.VersionReply
-	5,  // 44: remote.ETHBACKEND.Syncing:output_type -> remote.SyncingReply
-	9,  // 45: remote.ETHBACKEND.ProtocolVersion:output_type -> remote.ProtocolVersionReply
-	11, // 46: remote.ETHBACKEND.ClientVersion:output_type -> remote.ClientVersionReply
-	19, // 47: remote.ETHBACKEND.Subscribe:output_type -> remote.SubscribeReply
-	21, // 48: remote.ETHBACKEND.SubscribeLogs:output_type -> remote.SubscribeLogsReply
-	23, // 49: remote.ETHBACKEND.Block:output_type -> remote.BlockReply
-	17, // 50: remote.ETHBACKEND.CanonicalBodyForStorage:output_type -> remote.CanonicalBodyForStorageReply
-	13, // 51: remote.ETHBACKEND.CanonicalHash:output_type -> remote.CanonicalHashReply
-	15, // 52: remote.ETHBACKEND.HeaderNumber:output_type -> remote.HeaderNumberReply
-	25, // 53: remote.ETHBACKEND.TxnLookup:output_type -> remote.TxnLookupReply
-	28, // 54: remote.ETHBACKEND.NodeInfo:output_type -> remote.NodesInfoReply
-	29, // 55: remote.ETHBACKEND.Peers:output_type -> remote.PeersReply
-	30, // 56: remote.ETHBACKEND.AddPeer:output_type -> remote.AddPeerReply
-	31, // 57: remote.ETHBACKEND.PendingBlock:output_type -> remote.PendingBlockReply
-	48, // 58: remote.ETHBACKEND.BorTxnLookup:output_type -> remote.BorTxnLookupReply
-	49, // 59: remote.ETHBACKEND.BorEvents:output_type -> remote.BorEventsReply
-	35, // 60: remote.ETHBACKEND.AAValidation:output_type -> remote.AAValidationReply
-	37, // 61: remote.ETHBACKEND.BlockForTxNum:output_type -> remote.BlockForTxNumResponse
-	40, // [40:62] is the sub-list for method output_type
-	18, // [18:40] is the sub-list for method input_type
-	18, // [18:18] is the sub-list for extension type_name
-	18, // [18:18] is the sub-list for extension extendee
-	0,  // [0:18] is the sub-list for field type_name
+	43, // 0: remote.EtherbaseReply.address:type_name -> types.H160
+	41, // 1: remote.SyncingReply.stages:type_name -> remote.SyncingReply.StageProgress
+	42, // 2: remote.SyncingReply.snapshots:type_name -> remote.SyncingReply.SnapshotsProgress
+	44, // 3: remote.CanonicalHashReply.hash:type_name -> types.H256
+	44, // 4: remote.HeaderNumberRequest.hash:type_name -> types.H256
+	0,  // 5: remote.SubscribeRequest.type:type_name -> remote.Event
+	0,  // 6: remote.SubscribeReply.type:type_name -> remote.Event
+	43, // 7: remote.LogsFilterRequest.addresses:type_name -> types.H160
+	44, // 8: remote.LogsFilterRequest.topics:type_name -> types.H256
+	43, // 9: remote.SubscribeLogsReply.address:type_name -> types.H160
+	44, // 10: remote.SubscribeLogsReply.block_hash:type_name -> types.H256
+	44, // 11: remote.SubscribeLogsReply.topics:type_name -> types.H256
+	44, // 12: remote.SubscribeLogsReply.transaction_hash:type_name -> types.H256
+	44, // 13: remote.SubscribeBlocksReply.new_head:type_name -> types.H256
+	44, // 14: remote.SubscribeBlocksReply.old_head:type_name -> types.H256
+	44, // 15: remote.SubscribeBlocksReply.common_ancestor:type_name -> types.H256
+	1,  // 16: remote.SubscribePeerEventsReply.event_id:type_name -> remote.SubscribePeerEventsReply.PeerEventId
+	45, // 17: remote.SubscribePeerEventsReply.peer_id:type_name -> types.H512
+	44, // 18: remote.BlockRequest.block_hash:type_name -> types.H256
+	44, // 19: remote.TxnLookupRequest.txn_hash:type_name -> types.H256
+	46, // 20: remote.NodesInfoReply.nodes_info:type_name -> types.NodeInfoReply
+	47, // 21: remote.PeersReply.peers:type_name -> types.PeerInfo
+	44, // 22: remote.EngineGetPayloadBodiesByHashV1Request.hashes:type_name -> types.H256
+	48, // 23: remote.AAValidationRequest.tx:type_name -> types.AccountAbstractionTransaction
+	2,  // 24: remote.ETHBACKEND.Etherbase:input_type -> remote.EtherbaseRequest
+	4,  // 25: remote.ETHBACKEND.NetVersion:input_type -> remote.NetVersionRequest
+	7,  // 26: remote.ETHBACKEND.NetPeerCount:input_type -> remote.NetPeerCountRequest
+	49, // 27: remote.ETHBACKEND.Version:input_type -> google.protobuf.Empty
+	49, // 28: remote.ETHBACKEND.Syncing:input_type -> google.protobuf.Empty
+	9,  // 29: remote.ETHBACKEND.ProtocolVersion:input_type -> remote.ProtocolVersionRequest
+	11, // 30: remote.ETHBACKEND.ClientVersion:input_type -> remote.ClientVersionRequest
+	19, // 31: remote.ETHBACKEND.Subscribe:input_type -> remote.SubscribeRequest
+	21, // 32: remote.ETHBACKEND.SubscribeLogs:input_type -> remote.LogsFilterRequest
+	19, // 33: remote.ETHBACKEND.SubscribeBlocks:input_type -> remote.SubscribeRequest
+	19, // 34: remote.ETHBACKEND.SubscribePeerEvents:input_type -> remote.SubscribeRequest
+	25, // 35: remote.ETHBACKEND.Block:input_type -> remote.BlockRequest
+	17, // 36: remote.ETHBACKEND.CanonicalBodyForStorage:input_type -> remote.CanonicalBodyForStorageRequest
+	13, // 37: remote.ETHBACKEND.CanonicalHash:input_type -> remote.CanonicalHashRequest
+	15, // 38: remote.ETHBACKEND.HeaderNumber:input_type -> remote.HeaderNumberRequest
+	27, // 39: remote.ETHBACKEND.TxnLookup:input_type -> remote.TxnLookupRequest
+	29, // 40: remote.ETHBACKEND.NodeInfo:input_type -> remote.NodesInfoRequest
+	49, // 41: remote.ETHBACKEND.Peers:input_type -> google.protobuf.Empty
+	30, // 42: remote.ETHBACKEND.AddPeer:input_type -> remote.AddPeerRequest
+	49, // 43: remote.ETHBACKEND.PendingBlock:input_type -> google.protobuf.Empty
+	50, // 44: remote.ETHBACKEND.BorTxnLookup:input_type -> remote.BorTxnLookupRequest
+	51, // 45: remote.ETHBACKEND.BorEvents:input_type -> remote.BorEventsRequest
+	37, // 46: remote.ETHBACKEND.AAValidation:input_type -> remote.AAValidationRequest
+	39, // 47: remote.ETHBACKEND.BlockForTxNum:input_type -> remote.BlockForTxNumRequest
+	3,  // 48: remote.ETHBACKEND.Etherbase:output_type -> remote.EtherbaseReply
+	5,  // 49: remote.ETHBACKEND.NetVersion:output_type -> remote.NetVersionReply
+	8,  // 50: remote.ETHBACKEND.NetPeerCount:output_type -> remote.NetPeerCountReply
+	52, // 51: remote.ETHBACKEND.Version:output_type -> types.VersionReply
+	6,  // 52: remote.ETHBACKEND.Syncing:output_type -> remote.SyncingReply
+	10, // 53: remote.ETHBACKEND.ProtocolVersion:output_type -> remote.ProtocolVersionReply
+	12, // 54: remote.ETHBACKEND.ClientVersion:output_type -> remote.ClientVersionReply
+	20, // 55: remote.ETHBACKEND.Subscribe:output_type -> remote.SubscribeReply
+	22, // 56: remote.ETHBACKEND.SubscribeLogs:output_type -> remote.SubscribeLogsReply
+	23, // 57: remote.ETHBACKEND.SubscribeBlocks:output_type -> remote.SubscribeBlocksReply
+	24, // 58: remote.ETHBACKEND.SubscribePeerEvents:output_type -> remote.SubscribePeerEventsReply
+	26, // 59: remote.ETHBACKEND.Block:output_type -> remote.BlockReply
+	18, // 60: remote.ETHBACKEND.CanonicalBodyForStorage:output_type -> remote.CanonicalBodyForStorageReply
+	14, // 61: remote.ETHBACKEND.CanonicalHash:output_type -> remote.CanonicalHashReply
+	16, // 62: remote.ETHBACKEND.HeaderNumber:output_type -> remote.HeaderNumberReply
+	28, // 63: remote.ETHBACKEND.TxnLookup:output_type -> remote.TxnLookupReply
+	31, // 64: remote.ETHBACKEND.NodeInfo:output_type -> remote.NodesInfoReply
+	32, // 65: remote.ETHBACKEND.Peers:output_type -> remote.PeersReply
+	33, // 66: remote.ETHBACKEND.AddPeer:output_type -> remote.AddPeerReply
+	34, // 67: remote.ETHBACKEND.PendingBlock:output_type -> remote.PendingBlockReply
+	53, // 68: remote.ETHBACKEND.BorTxnLookup:output_type -> remote.BorTxnLookupReply
+	54, // 69: remote.ETHBACKEND.BorEvents:output_type -> remote.BorEventsReply
+	38, // 70: remote.ETHBACKEND.AAValidation:output_type -> remote.AAValidationReply
+	40, // 71: remote.ETHBACKEND.BlockForTxNum:output_type -> remote.BlockForTxNumResponse
+	48, // [48:72] is the sub-list for method output_type
+	24, // [24:48] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
 }
 
 func init() { file_remote_ethbackend_proto_init() }
@@ -2172,8 +2500,8 @@ func file_remote_ethbackend_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_remote_ethbackend_proto_rawDesc), len(file_remote_ethbackend_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   38,
+			NumEnums:      2,
+			NumMessages:   41,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
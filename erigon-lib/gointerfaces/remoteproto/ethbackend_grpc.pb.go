@@ -30,6 +30,8 @@ const (
 	ETHBACKEND_ClientVersion_FullMethodName           = "/remote.ETHBACKEND/ClientVersion"
 	ETHBACKEND_Subscribe_FullMethodName               = "/remote.ETHBACKEND/Subscribe"
 	ETHBACKEND_SubscribeLogs_FullMethodName           = "/remote.ETHBACKEND/SubscribeLogs"
+	ETHBACKEND_SubscribeBlocks_FullMethodName         = "/remote.ETHBACKEND/SubscribeBlocks"
+	ETHBACKEND_SubscribePeerEvents_FullMethodName     = "/remote.ETHBACKEND/SubscribePeerEvents"
 	ETHBACKEND_Block_FullMethodName                   = "/remote.ETHBACKEND/Block"
 	ETHBACKEND_CanonicalBodyForStorage_FullMethodName = "/remote.ETHBACKEND/CanonicalBodyForStorage"
 	ETHBACKEND_CanonicalHash_FullMethodName           = "/remote.ETHBACKEND/CanonicalHash"
@@ -63,6 +65,10 @@ type ETHBACKENDClient interface {
 	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SubscribeReply], error)
 	// Only one subscription is needed to serve all the users, LogsFilterRequest allows to dynamically modifying the subscription
 	SubscribeLogs(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LogsFilterRequest, SubscribeLogsReply], error)
+	// SubscribeBlocks streams the new canonical blocks with their bodies, including the reorg markers
+	SubscribeBlocks(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SubscribeBlocksReply], error)
+	// SubscribePeerEvents streams the peers connecting to and disconnecting from the sentries
+	SubscribePeerEvents(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SubscribePeerEventsReply], error)
 	// High-level method - can read block from db, snapshots or apply any other logic
 	// it doesn't provide consistency
 	// Request fields are optional - it's ok to request block only by hash or only by number
@@ -199,6 +205,44 @@ func (c *eTHBACKENDClient) SubscribeLogs(ctx context.Context, opts ...grpc.CallO
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type ETHBACKEND_SubscribeLogsClient = grpc.BidiStreamingClient[LogsFilterRequest, SubscribeLogsReply]
 
+func (c *eTHBACKENDClient) SubscribeBlocks(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SubscribeBlocksReply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ETHBACKEND_ServiceDesc.Streams[2], ETHBACKEND_SubscribeBlocks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, SubscribeBlocksReply]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ETHBACKEND_SubscribeBlocksClient = grpc.ServerStreamingClient[SubscribeBlocksReply]
+
+func (c *eTHBACKENDClient) SubscribePeerEvents(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SubscribePeerEventsReply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ETHBACKEND_ServiceDesc.Streams[3], ETHBACKEND_SubscribePeerEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, SubscribePeerEventsReply]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ETHBACKEND_SubscribePeerEventsClient = grpc.ServerStreamingClient[SubscribePeerEventsReply]
+
 func (c *eTHBACKENDClient) Block(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BlockReply)
@@ -347,6 +391,10 @@ type ETHBACKENDServer interface {
 	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[SubscribeReply]) error
 	// Only one subscription is needed to serve all the users, LogsFilterRequest allows to dynamically modifying the subscription
 	SubscribeLogs(grpc.BidiStreamingServer[LogsFilterRequest, SubscribeLogsReply]) error
+	// SubscribeBlocks streams the new canonical blocks with their bodies, including the reorg markers
+	SubscribeBlocks(*SubscribeRequest, grpc.ServerStreamingServer[SubscribeBlocksReply]) error
+	// SubscribePeerEvents streams the peers connecting to and disconnecting from the sentries
+	SubscribePeerEvents(*SubscribeRequest, grpc.ServerStreamingServer[SubscribePeerEventsReply]) error
 	// High-level method - can read block from db, snapshots or apply any other logic
 	// it doesn't provide consistency
 	// Request fields are optional - it's ok to request block only by hash or only by number
@@ -408,6 +456,12 @@ func (UnimplementedETHBACKENDServer) Subscribe(*SubscribeRequest, grpc.ServerStr
 func (UnimplementedETHBACKENDServer) SubscribeLogs(grpc.BidiStreamingServer[LogsFilterRequest, SubscribeLogsReply]) error {
 	return status.Errorf(codes.Unimplemented, "method SubscribeLogs not implemented")
 }
+func (UnimplementedETHBACKENDServer) SubscribeBlocks(*SubscribeRequest, grpc.ServerStreamingServer[SubscribeBlocksReply]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeBlocks not implemented")
+}
+func (UnimplementedETHBACKENDServer) SubscribePeerEvents(*SubscribeRequest, grpc.ServerStreamingServer[SubscribePeerEventsReply]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribePeerEvents not implemented")
+}
 func (UnimplementedETHBACKENDServer) Block(context.Context, *BlockRequest) (*BlockReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Block not implemented")
 }
@@ -612,6 +666,28 @@ func _ETHBACKEND_SubscribeLogs_Handler(srv interface{}, stream grpc.ServerStream
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type ETHBACKEND_SubscribeLogsServer = grpc.BidiStreamingServer[LogsFilterRequest, SubscribeLogsReply]
 
+func _ETHBACKEND_SubscribeBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ETHBACKENDServer).SubscribeBlocks(m, &grpc.GenericServerStream[SubscribeRequest, SubscribeBlocksReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ETHBACKEND_SubscribeBlocksServer = grpc.ServerStreamingServer[SubscribeBlocksReply]
+
+func _ETHBACKEND_SubscribePeerEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ETHBACKENDServer).SubscribePeerEvents(m, &grpc.GenericServerStream[SubscribeRequest, SubscribePeerEventsReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ETHBACKEND_SubscribePeerEventsServer = grpc.ServerStreamingServer[SubscribePeerEventsReply]
+
 func _ETHBACKEND_Block_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BlockRequest)
 	if err := dec(in); err != nil {
@@ -946,6 +1022,16 @@ var ETHBACKEND_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "SubscribeBlocks",
+			Handler:       _ETHBACKEND_SubscribeBlocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribePeerEvents",
+			Handler:       _ETHBACKEND_SubscribePeerEvents_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "remote/ethbackend.proto",
 }
@@ -850,6 +850,118 @@ func (x *GetBlobsReply) GetProofs() [][]byte {
 	return nil
 }
 
+type ContentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sender        *typesproto.H160       `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`                        // only the transactions of this sender if set
+	Limit         uint32                 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                         // maximum number of transactions in the reply, the server's default if 0
+	PageToken     []byte                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"` // next_page_token of the previous page, unset for the first page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContentRequest) Reset() {
+	*x = ContentRequest{}
+	mi := &file_txpool_txpool_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContentRequest) ProtoMessage() {}
+
+func (x *ContentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContentRequest.ProtoReflect.Descriptor instead.
+func (*ContentRequest) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ContentRequest) GetSender() *typesproto.H160 {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *ContentRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ContentRequest) GetPageToken() []byte {
+	if x != nil {
+		return x.PageToken
+	}
+	return nil
+}
+
+type ContentReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Txs           []*AllReply_Tx         `protobuf:"bytes,1,rep,name=txs,proto3" json:"txs,omitempty"`                                            // ordered by sender, then nonce
+	NextPageToken []byte                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // unset on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContentReply) Reset() {
+	*x = ContentReply{}
+	mi := &file_txpool_txpool_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContentReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContentReply) ProtoMessage() {}
+
+func (x *ContentReply) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_txpool_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContentReply.ProtoReflect.Descriptor instead.
+func (*ContentReply) Descriptor() ([]byte, []int) {
+	return file_txpool_txpool_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ContentReply) GetTxs() []*AllReply_Tx {
+	if x != nil {
+		return x.Txs
+	}
+	return nil
+}
+
+func (x *ContentReply) GetNextPageToken() []byte {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return nil
+}
+
 type AllReply_Tx struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TxnType       AllReply_TxnType       `protobuf:"varint,1,opt,name=txn_type,json=txnType,proto3,enum=txpool.AllReply_TxnType" json:"txn_type,omitempty"`
@@ -861,7 +973,7 @@ type AllReply_Tx struct {
 
 func (x *AllReply_Tx) Reset() {
 	*x = AllReply_Tx{}
-	mi := &file_txpool_txpool_proto_msgTypes[16]
+	mi := &file_txpool_txpool_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -873,7 +985,7 @@ func (x *AllReply_Tx) String() string {
 func (*AllReply_Tx) ProtoMessage() {}
 
 func (x *AllReply_Tx) ProtoReflect() protoreflect.Message {
-	mi := &file_txpool_txpool_proto_msgTypes[16]
+	mi := &file_txpool_txpool_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -921,7 +1033,7 @@ type PendingReply_Tx struct {
 
 func (x *PendingReply_Tx) Reset() {
 	*x = PendingReply_Tx{}
-	mi := &file_txpool_txpool_proto_msgTypes[17]
+	mi := &file_txpool_txpool_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -933,7 +1045,7 @@ func (x *PendingReply_Tx) String() string {
 func (*PendingReply_Tx) ProtoMessage() {}
 
 func (x *PendingReply_Tx) ProtoReflect() protoreflect.Message {
-	mi := &file_txpool_txpool_proto_msgTypes[17]
+	mi := &file_txpool_txpool_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1026,14 +1138,22 @@ const file_txpool_txpool_proto_rawDesc = "" +
 	"blobHashes\"=\n" +
 	"\rGetBlobsReply\x12\x14\n" +
 	"\x05blobs\x18\x01 \x03(\fR\x05blobs\x12\x16\n" +
-	"\x06proofs\x18\x02 \x03(\fR\x06proofs*l\n" +
+	"\x06proofs\x18\x02 \x03(\fR\x06proofs\"j\n" +
+	"\x0eContentRequest\x12#\n" +
+	"\x06sender\x18\x01 \x01(\v2\v.types.H160R\x06sender\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\fR\tpageToken\"]\n" +
+	"\fContentReply\x12%\n" +
+	"\x03txs\x18\x01 \x03(\v2\x13.txpool.AllReply.TxR\x03txs\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\fR\rnextPageToken*l\n" +
 	"\fImportResult\x12\v\n" +
 	"\aSUCCESS\x10\x00\x12\x12\n" +
 	"\x0eALREADY_EXISTS\x10\x01\x12\x0f\n" +
 	"\vFEE_TOO_LOW\x10\x02\x12\t\n" +
 	"\x05STALE\x10\x03\x12\v\n" +
 	"\aINVALID\x10\x04\x12\x12\n" +
-	"\x0eINTERNAL_ERROR\x10\x052\xa8\x04\n" +
+	"\x0eINTERNAL_ERROR\x10\x052\xe1\x04\n" +
 	"\x06Txpool\x126\n" +
 	"\aVersion\x12\x16.google.protobuf.Empty\x1a\x13.types.VersionReply\x121\n" +
 	"\vFindUnknown\x12\x10.txpool.TxHashes\x1a\x10.txpool.TxHashes\x12+\n" +
@@ -1044,7 +1164,8 @@ const file_txpool_txpool_proto_rawDesc = "" +
 	"\x05OnAdd\x12\x14.txpool.OnAddRequest\x1a\x12.txpool.OnAddReply0\x01\x124\n" +
 	"\x06Status\x12\x15.txpool.StatusRequest\x1a\x13.txpool.StatusReply\x121\n" +
 	"\x05Nonce\x12\x14.txpool.NonceRequest\x1a\x12.txpool.NonceReply\x12:\n" +
-	"\bGetBlobs\x12\x17.txpool.GetBlobsRequest\x1a\x15.txpool.GetBlobsReplyB\x16Z\x14./txpool;txpoolprotob\x06proto3"
+	"\bGetBlobs\x12\x17.txpool.GetBlobsRequest\x1a\x15.txpool.GetBlobsReply\x127\n" +
+	"\aContent\x12\x16.txpool.ContentRequest\x1a\x14.txpool.ContentReplyB\x16Z\x14./txpool;txpoolprotob\x06proto3"
 
 var (
 	file_txpool_txpool_proto_rawDescOnce sync.Once
@@ -1059,7 +1180,7 @@ func file_txpool_txpool_proto_rawDescGZIP() []byte {
 }
 
 var file_txpool_txpool_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_txpool_txpool_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_txpool_txpool_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_txpool_txpool_proto_goTypes = []any{
 	(ImportResult)(0),               // 0: txpool.ImportResult
 	(AllReply_TxnType)(0),           // 1: txpool.AllReply.TxnType
@@ -1079,49 +1200,55 @@ var file_txpool_txpool_proto_goTypes = []any{
 	(*NonceReply)(nil),              // 15: txpool.NonceReply
 	(*GetBlobsRequest)(nil),         // 16: txpool.GetBlobsRequest
 	(*GetBlobsReply)(nil),           // 17: txpool.GetBlobsReply
-	(*AllReply_Tx)(nil),             // 18: txpool.AllReply.Tx
-	(*PendingReply_Tx)(nil),         // 19: txpool.PendingReply.Tx
-	(*typesproto.H256)(nil),         // 20: types.H256
-	(*typesproto.H160)(nil),         // 21: types.H160
-	(*emptypb.Empty)(nil),           // 22: google.protobuf.Empty
-	(*typesproto.VersionReply)(nil), // 23: types.VersionReply
+	(*ContentRequest)(nil),          // 18: txpool.ContentRequest
+	(*ContentReply)(nil),            // 19: txpool.ContentReply
+	(*AllReply_Tx)(nil),             // 20: txpool.AllReply.Tx
+	(*PendingReply_Tx)(nil),         // 21: txpool.PendingReply.Tx
+	(*typesproto.H256)(nil),         // 22: types.H256
+	(*typesproto.H160)(nil),         // 23: types.H160
+	(*emptypb.Empty)(nil),           // 24: google.protobuf.Empty
+	(*typesproto.VersionReply)(nil), // 25: types.VersionReply
 }
 var file_txpool_txpool_proto_depIdxs = []int32{
-	20, // 0: txpool.TxHashes.hashes:type_name -> types.H256
+	22, // 0: txpool.TxHashes.hashes:type_name -> types.H256
 	0,  // 1: txpool.AddReply.imported:type_name -> txpool.ImportResult
-	20, // 2: txpool.TransactionsRequest.hashes:type_name -> types.H256
-	18, // 3: txpool.AllReply.txs:type_name -> txpool.AllReply.Tx
-	19, // 4: txpool.PendingReply.txs:type_name -> txpool.PendingReply.Tx
-	21, // 5: txpool.NonceRequest.address:type_name -> types.H160
-	20, // 6: txpool.GetBlobsRequest.blob_hashes:type_name -> types.H256
-	1,  // 7: txpool.AllReply.Tx.txn_type:type_name -> txpool.AllReply.TxnType
-	21, // 8: txpool.AllReply.Tx.sender:type_name -> types.H160
-	21, // 9: txpool.PendingReply.Tx.sender:type_name -> types.H160
-	22, // 10: txpool.Txpool.Version:input_type -> google.protobuf.Empty
-	2,  // 11: txpool.Txpool.FindUnknown:input_type -> txpool.TxHashes
-	3,  // 12: txpool.Txpool.Add:input_type -> txpool.AddRequest
-	5,  // 13: txpool.Txpool.Transactions:input_type -> txpool.TransactionsRequest
-	9,  // 14: txpool.Txpool.All:input_type -> txpool.AllRequest
-	22, // 15: txpool.Txpool.Pending:input_type -> google.protobuf.Empty
-	7,  // 16: txpool.Txpool.OnAdd:input_type -> txpool.OnAddRequest
-	12, // 17: txpool.Txpool.Status:input_type -> txpool.StatusRequest
-	14, // 18: txpool.Txpool.Nonce:input_type -> txpool.NonceRequest
-	16, // 19: txpool.Txpool.GetBlobs:input_type -> txpool.GetBlobsRequest
-	23, // 20: txpool.Txpool.Version:output_type -> types.VersionReply
-	2,  // 21: txpool.Txpool.FindUnknown:output_type -> txpool.TxHashes
-	4,  // 22: txpool.Txpool.Add:output_type -> txpool.AddReply
-	6,  // 23: txpool.Txpool.Transactions:output_type -> txpool.TransactionsReply
-	10, // 24: txpool.Txpool.All:output_type -> txpool.AllReply
-	11, // 25: txpool.Txpool.Pending:output_type -> txpool.PendingReply
-	8,  // 26: txpool.Txpool.OnAdd:output_type -> txpool.OnAddReply
-	13, // 27: txpool.Txpool.Status:output_type -> txpool.StatusReply
-	15, // 28: txpool.Txpool.Nonce:output_type -> txpool.NonceReply
-	17, // 29: txpool.Txpool.GetBlobs:output_type -> txpool.GetBlobsReply
-	20, // [20:30] is the sub-list for method output_type
-	10, // [10:20] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	22, // 2: txpool.TransactionsRequest.hashes:type_name -> types.H256
+	20, // 3: txpool.AllReply.txs:type_name -> txpool.AllReply.Tx
+	21, // 4: txpool.PendingReply.txs:type_name -> txpool.PendingReply.Tx
+	23, // 5: txpool.NonceRequest.address:type_name -> types.H160
+	22, // 6: txpool.GetBlobsRequest.blob_hashes:type_name -> types.H256
+	23, // 7: txpool.ContentRequest.sender:type_name -> types.H160
+	20, // 8: txpool.ContentReply.txs:type_name -> txpool.AllReply.Tx
+	1,  // 9: txpool.AllReply.Tx.txn_type:type_name -> txpool.AllReply.TxnType
+	23, // 10: txpool.AllReply.Tx.sender:type_name -> types.H160
+	23, // 11: txpool.PendingReply.Tx.sender:type_name -> types.H160
+	24, // 12: txpool.Txpool.Version:input_type -> google.protobuf.Empty
+	2,  // 13: txpool.Txpool.FindUnknown:input_type -> txpool.TxHashes
+	3,  // 14: txpool.Txpool.Add:input_type -> txpool.AddRequest
+	5,  // 15: txpool.Txpool.Transactions:input_type -> txpool.TransactionsRequest
+	9,  // 16: txpool.Txpool.All:input_type -> txpool.AllRequest
+	24, // 17: txpool.Txpool.Pending:input_type -> google.protobuf.Empty
+	7,  // 18: txpool.Txpool.OnAdd:input_type -> txpool.OnAddRequest
+	12, // 19: txpool.Txpool.Status:input_type -> txpool.StatusRequest
+	14, // 20: txpool.Txpool.Nonce:input_type -> txpool.NonceRequest
+	16, // 21: txpool.Txpool.GetBlobs:input_type -> txpool.GetBlobsRequest
+	18, // 22: txpool.Txpool.Content:input_type -> txpool.ContentRequest
+	25, // 23: txpool.Txpool.Version:output_type -> types.VersionReply
+	2,  // 24: txpool.Txpool.FindUnknown:output_type -> txpool.TxHashes
+	4,  // 25: txpool.Txpool.Add:output_type -> txpool.AddReply
+	6,  // 26: txpool.Txpool.Transactions:output_type -> txpool.TransactionsReply
+	10, // 27: txpool.Txpool.All:output_type -> txpool.AllReply
+	11, // 28: txpool.Txpool.Pending:output_type -> txpool.PendingReply
+	8,  // 29: txpool.Txpool.OnAdd:output_type -> txpool.OnAddReply
+	13, // 30: txpool.Txpool.Status:output_type -> txpool.StatusReply
+	15, // 31: txpool.Txpool.Nonce:output_type -> txpool.NonceReply
+	17, // 32: txpool.Txpool.GetBlobs:output_type -> txpool.GetBlobsReply
+	19, // 33: txpool.Txpool.Content:output_type -> txpool.ContentReply
+	23, // [23:34] is the sub-list for method output_type
+	12, // [12:23] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_txpool_txpool_proto_init() }
@@ -1135,7 +1262,7 @@ func file_txpool_txpool_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_txpool_txpool_proto_rawDesc), len(file_txpool_txpool_proto_rawDesc)),
 			NumEnums:      2,
-			NumMessages:   18,
+			NumMessages:   20,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -31,6 +31,7 @@ const (
 	Txpool_Status_FullMethodName       = "/txpool.Txpool/Status"
 	Txpool_Nonce_FullMethodName        = "/txpool.Txpool/Nonce"
 	Txpool_GetBlobs_FullMethodName     = "/txpool.Txpool/GetBlobs"
+	Txpool_Content_FullMethodName      = "/txpool.Txpool/Content"
 )
 
 // TxpoolClient is the client API for Txpool service.
@@ -58,6 +59,8 @@ type TxpoolClient interface {
 	Nonce(ctx context.Context, in *NonceRequest, opts ...grpc.CallOption) (*NonceReply, error)
 	// returns the list of blobs and proofs for a given list of blob hashes
 	GetBlobs(ctx context.Context, in *GetBlobsRequest, opts ...grpc.CallOption) (*GetBlobsReply, error)
+	// returns a page of the transactions of the pool, grouped by sender in nonce order
+	Content(ctx context.Context, in *ContentRequest, opts ...grpc.CallOption) (*ContentReply, error)
 }
 
 type txpoolClient struct {
@@ -177,6 +180,16 @@ func (c *txpoolClient) GetBlobs(ctx context.Context, in *GetBlobsRequest, opts .
 	return out, nil
 }
 
+func (c *txpoolClient) Content(ctx context.Context, in *ContentRequest, opts ...grpc.CallOption) (*ContentReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ContentReply)
+	err := c.cc.Invoke(ctx, Txpool_Content_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TxpoolServer is the server API for Txpool service.
 // All implementations must embed UnimplementedTxpoolServer
 // for forward compatibility.
@@ -202,6 +215,8 @@ type TxpoolServer interface {
 	Nonce(context.Context, *NonceRequest) (*NonceReply, error)
 	// returns the list of blobs and proofs for a given list of blob hashes
 	GetBlobs(context.Context, *GetBlobsRequest) (*GetBlobsReply, error)
+	// returns a page of the transactions of the pool, grouped by sender in nonce order
+	Content(context.Context, *ContentRequest) (*ContentReply, error)
 	mustEmbedUnimplementedTxpoolServer()
 }
 
@@ -242,6 +257,9 @@ func (UnimplementedTxpoolServer) Nonce(context.Context, *NonceRequest) (*NonceRe
 func (UnimplementedTxpoolServer) GetBlobs(context.Context, *GetBlobsRequest) (*GetBlobsReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBlobs not implemented")
 }
+func (UnimplementedTxpoolServer) Content(context.Context, *ContentRequest) (*ContentReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Content not implemented")
+}
 func (UnimplementedTxpoolServer) mustEmbedUnimplementedTxpoolServer() {}
 func (UnimplementedTxpoolServer) testEmbeddedByValue()                {}
 
@@ -436,6 +454,24 @@ func _Txpool_GetBlobs_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Txpool_Content_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxpoolServer).Content(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Txpool_Content_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxpoolServer).Content(ctx, req.(*ContentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Txpool_ServiceDesc is the grpc.ServiceDesc for Txpool service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -479,6 +515,10 @@ var Txpool_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBlobs",
 			Handler:    _Txpool_GetBlobs_Handler,
 		},
+		{
+			MethodName: "Content",
+			Handler:    _Txpool_Content_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
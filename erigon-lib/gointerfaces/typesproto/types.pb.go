@@ -908,8 +908,16 @@ type PeerInfo struct {
 	ConnIsInbound  bool                   `protobuf:"varint,8,opt,name=conn_is_inbound,json=connIsInbound,proto3" json:"conn_is_inbound,omitempty"`
 	ConnIsTrusted  bool                   `protobuf:"varint,9,opt,name=conn_is_trusted,json=connIsTrusted,proto3" json:"conn_is_trusted,omitempty"`
 	ConnIsStatic   bool                   `protobuf:"varint,10,opt,name=conn_is_static,json=connIsStatic,proto3" json:"conn_is_static,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// best block announced by the peer, known to the core only: the sentries leave them unset
+	HeadHash        *H256 `protobuf:"bytes,11,opt,name=head_hash,json=headHash,proto3" json:"head_hash,omitempty"`
+	TotalDifficulty *H256 `protobuf:"bytes,12,opt,name=total_difficulty,json=totalDifficulty,proto3" json:"total_difficulty,omitempty"`
+	// fork ID advertised by the peer in its eth handshake
+	ForkHash []byte `protobuf:"bytes,13,opt,name=fork_hash,json=forkHash,proto3" json:"fork_hash,omitempty"`
+	ForkNext uint64 `protobuf:"varint,14,opt,name=fork_next,json=forkNext,proto3" json:"fork_next,omitempty"`
+	// why the fork ID of the peer is incompatible with our chain, known to the core only: the sentries leave it unset
+	ForkIncompatibility string `protobuf:"bytes,15,opt,name=fork_incompatibility,json=forkIncompatibility,proto3" json:"fork_incompatibility,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *PeerInfo) Reset() {
@@ -1012,6 +1020,41 @@ func (x *PeerInfo) GetConnIsStatic() bool {
 	return false
 }
 
+func (x *PeerInfo) GetHeadHash() *H256 {
+	if x != nil {
+		return x.HeadHash
+	}
+	return nil
+}
+
+func (x *PeerInfo) GetTotalDifficulty() *H256 {
+	if x != nil {
+		return x.TotalDifficulty
+	}
+	return nil
+}
+
+func (x *PeerInfo) GetForkHash() []byte {
+	if x != nil {
+		return x.ForkHash
+	}
+	return nil
+}
+
+func (x *PeerInfo) GetForkNext() uint64 {
+	if x != nil {
+		return x.ForkNext
+	}
+	return 0
+}
+
+func (x *PeerInfo) GetForkIncompatibility() string {
+	if x != nil {
+		return x.ForkIncompatibility
+	}
+	return ""
+}
+
 type ExecutionPayloadBodyV1 struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Transactions  [][]byte               `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
@@ -1442,7 +1485,7 @@ const file_types_types_proto_rawDesc = "" +
 	"\x03enr\x18\x04 \x01(\tR\x03enr\x12*\n" +
 	"\x05ports\x18\x05 \x01(\v2\x14.types.NodeInfoPortsR\x05ports\x12#\n" +
 	"\rlistener_addr\x18\x06 \x01(\tR\flistenerAddr\x12\x1c\n" +
-	"\tprotocols\x18\a \x01(\fR\tprotocols\"\xb2\x02\n" +
+	"\tprotocols\x18\a \x01(\fR\tprotocols\"\x81\x04\n" +
 	"\bPeerInfo\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
@@ -1454,7 +1497,12 @@ const file_types_types_proto_rawDesc = "" +
 	"\x0fconn_is_inbound\x18\b \x01(\bR\rconnIsInbound\x12&\n" +
 	"\x0fconn_is_trusted\x18\t \x01(\bR\rconnIsTrusted\x12$\n" +
 	"\x0econn_is_static\x18\n" +
-	" \x01(\bR\fconnIsStatic\"q\n" +
+	" \x01(\bR\fconnIsStatic\x12(\n" +
+	"\thead_hash\x18\v \x01(\v2\v.types.H256R\bheadHash\x126\n" +
+	"\x10total_difficulty\x18\f \x01(\v2\v.types.H256R\x0ftotalDifficulty\x12\x1b\n" +
+	"\tfork_hash\x18\r \x01(\fR\bforkHash\x12\x1b\n" +
+	"\tfork_next\x18\x0e \x01(\x04R\bforkNext\x121\n" +
+	"\x14fork_incompatibility\x18\x0f \x01(\tR\x13forkIncompatibility\"q\n" +
 	"\x16ExecutionPayloadBodyV1\x12\"\n" +
 	"\ftransactions\x18\x01 \x03(\fR\ftransactions\x123\n" +
 	"\vwithdrawals\x18\x02 \x03(\v2\x11.types.WithdrawalR\vwithdrawals\"\xb5\x05\n" +
@@ -1544,16 +1592,18 @@ var file_types_types_proto_depIdxs = []int32{
 	8,  // 17: types.ExecutionPayload.withdrawals:type_name -> types.Withdrawal
 	1,  // 18: types.Withdrawal.address:type_name -> types.H160
 	11, // 19: types.NodeInfoReply.ports:type_name -> types.NodeInfoPorts
-	8,  // 20: types.ExecutionPayloadBodyV1.withdrawals:type_name -> types.Withdrawal
-	16, // 21: types.AccountAbstractionTransaction.authorizations:type_name -> types.Authorization
-	17, // 22: types.service_major_version:extendee -> google.protobuf.FileOptions
-	17, // 23: types.service_minor_version:extendee -> google.protobuf.FileOptions
-	17, // 24: types.service_patch_version:extendee -> google.protobuf.FileOptions
-	25, // [25:25] is the sub-list for method output_type
-	25, // [25:25] is the sub-list for method input_type
-	25, // [25:25] is the sub-list for extension type_name
-	22, // [22:25] is the sub-list for extension extendee
-	0,  // [0:22] is the sub-list for field type_name
+	2,  // 20: types.PeerInfo.head_hash:type_name -> types.H256
+	2,  // 21: types.PeerInfo.total_difficulty:type_name -> types.H256
+	8,  // 22: types.ExecutionPayloadBodyV1.withdrawals:type_name -> types.Withdrawal
+	16, // 23: types.AccountAbstractionTransaction.authorizations:type_name -> types.Authorization
+	17, // 24: types.service_major_version:extendee -> google.protobuf.FileOptions
+	17, // 25: types.service_minor_version:extendee -> google.protobuf.FileOptions
+	17, // 26: types.service_patch_version:extendee -> google.protobuf.FileOptions
+	27, // [27:27] is the sub-list for method output_type
+	27, // [27:27] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	24, // [24:27] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
 }
 
 func init() { file_types_types_proto_init() }
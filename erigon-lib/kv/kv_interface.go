@@ -218,16 +218,18 @@ type DBVerbosityLvl int8
 type Label string
 
 const (
-	ChainDB         = "chaindata"
-	TxPoolDB        = "txpool"
-	SentryDB        = "sentry"
-	ConsensusDB     = "consensus"
-	DownloaderDB    = "downloader"
-	HeimdallDB      = "heimdall"
-	DiagnosticsDB   = "diagnostics"
-	PolygonBridgeDB = "polygon-bridge"
-	CaplinDB        = "caplin"
-	TemporaryDB     = "temporary"
+	ChainDB          = "chaindata"
+	TxPoolDB         = "txpool"
+	SentryDB         = "sentry"
+	ConsensusDB      = "consensus"
+	DownloaderDB     = "downloader"
+	HeimdallDB       = "heimdall"
+	DiagnosticsDB    = "diagnostics"
+	PolygonBridgeDB  = "polygon-bridge"
+	ReceiptsCacheDB  = "receipts-cache"
+	PeerReputationDB = "peer-reputation"
+	CaplinDB         = "caplin"
+	TemporaryDB      = "temporary"
 )
 
 type GetPut interface {
@@ -99,6 +99,8 @@ const (
 
 	// Progress of sync stages: stageName -> stageData
 	SyncStageProgress = "SyncStage"
+	// Last forward run of sync stages: stageName -> duration_u64 + finishedAt_u64 + error message (empty if none)
+	SyncStageRun = "SyncStageRun"
 
 	CliqueSeparate     = "CliqueSeparate"
 	CliqueLastSnapshot = "CliqueLastSnapshot"
@@ -283,6 +285,13 @@ const (
 	//Diagnostics tables
 	DiagSystemInfo = "DiagSystemInfo"
 	DiagSyncStages = "DiagSyncStages"
+
+	// Receipts cache tables
+	// GeneratedReceipts - optional cache of the receipts produced by re-executing blocks, pruned by depth and size
+	GeneratedReceipts = "GeneratedReceipts" // block_num_u64 + block_hash -> rlp(receipts for storage)
+
+	// PeerReputation - reputation of the p2p peers, kept across restarts and decayed over time
+	PeerReputation = "PeerReputation" // peer_pubkey_64 -> score_f64 + updated_at_unix_u64
 )
 
 // Keys
@@ -296,6 +305,7 @@ var (
 
 	DBSchemaVersionKey = []byte("dbVersion")
 	GenesisKey         = []byte("genesis")
+	ConfigOverrideKey  = []byte("chainConfigOverride")
 
 	BittorrentPeerID = "peerID"
 
@@ -328,6 +338,7 @@ var ChaindataTables = []string{
 	DatabaseInfo,
 	IncarnationMap,
 	SyncStageProgress,
+	SyncStageRun,
 	PlainState,
 	PlainContractCode,
 	ChangeSets3,
@@ -473,6 +484,12 @@ var ConsensusTables = append([]string{
 )
 var HeimdallTables = []string{}
 var PolygonBridgeTables = []string{}
+var ReceiptsCacheTables = []string{
+	GeneratedReceipts,
+}
+var PeerReputationTables = []string{
+	PeerReputation,
+}
 var DownloaderTables = []string{
 	BittorrentCompletion,
 	BittorrentInfo,
@@ -607,6 +624,8 @@ var DownloaderTablesCfg = TableCfg{}
 var DiagnosticsTablesCfg = TableCfg{}
 var HeimdallTablesCfg = TableCfg{}
 var PolygonBridgeTablesCfg = TableCfg{}
+var ReceiptsCacheTablesCfg = TableCfg{}
+var PeerReputationTablesCfg = TableCfg{}
 var ReconTablesCfg = TableCfg{
 	PlainStateD:    {Flags: DupSort},
 	CodeD:          {Flags: DupSort},
@@ -631,6 +650,10 @@ func TablesCfgByLabel(label Label) TableCfg {
 		return PolygonBridgeTablesCfg
 	case ConsensusDB:
 		return ConsensusTablesCfg
+	case ReceiptsCacheDB:
+		return ReceiptsCacheTablesCfg
+	case PeerReputationDB:
+		return PeerReputationTablesCfg
 	default:
 		panic(fmt.Sprintf("unexpected label: %s", label))
 	}
@@ -719,6 +742,18 @@ func reinit() {
 			PolygonBridgeTablesCfg[name] = TableCfgItem{}
 		}
 	}
+	for _, name := range ReceiptsCacheTables {
+		_, ok := ReceiptsCacheTablesCfg[name]
+		if !ok {
+			ReceiptsCacheTablesCfg[name] = TableCfgItem{}
+		}
+	}
+	for _, name := range PeerReputationTables {
+		_, ok := PeerReputationTablesCfg[name]
+		if !ok {
+			PeerReputationTablesCfg[name] = TableCfgItem{}
+		}
+	}
 }
 
 // Temporal
@@ -27,6 +27,8 @@ func (g Genesis) MarshalJSON() ([]byte, error) {
 		Mixhash               common.Hash                                 `json:"mixHash"`
 		Coinbase              common.Address                              `json:"coinbase"`
 		Alloc                 map[common.UnprefixedAddress]GenesisAccount `json:"alloc"      gencodec:"required"`
+		AllocFile             string                                      `json:"allocFile,omitempty"`
+		AllocFileHash         *common.Hash                                `json:"allocFileHash,omitempty"`
 		AuRaSeal              *AuRaSeal                                   `json:"seal"`
 		Number                math.HexOrDecimal64                         `json:"number"`
 		GasUsed               math.HexOrDecimal64                         `json:"gasUsed"`
@@ -52,6 +54,8 @@ func (g Genesis) MarshalJSON() ([]byte, error) {
 			enc.Alloc[common.UnprefixedAddress(k)] = v
 		}
 	}
+	enc.AllocFile = g.AllocFile
+	enc.AllocFileHash = g.AllocFileHash
 	enc.AuRaSeal = g.AuRaSeal
 	enc.Number = math.HexOrDecimal64(g.Number)
 	enc.GasUsed = math.HexOrDecimal64(g.GasUsed)
@@ -76,6 +80,8 @@ func (g *Genesis) UnmarshalJSON(input []byte) error {
 		Mixhash               *common.Hash                                `json:"mixHash"`
 		Coinbase              *common.Address                             `json:"coinbase"`
 		Alloc                 map[common.UnprefixedAddress]GenesisAccount `json:"alloc"      gencodec:"required"`
+		AllocFile             *string                                     `json:"allocFile,omitempty"`
+		AllocFileHash         *common.Hash                                `json:"allocFileHash,omitempty"`
 		AuRaSeal              *AuRaSeal                                   `json:"seal"`
 		Number                *math.HexOrDecimal64                        `json:"number"`
 		GasUsed               *math.HexOrDecimal64                        `json:"gasUsed"`
@@ -123,6 +129,12 @@ func (g *Genesis) UnmarshalJSON(input []byte) error {
 	for k, v := range dec.Alloc {
 		g.Alloc[common.Address(k)] = v
 	}
+	if dec.AllocFile != nil {
+		g.AllocFile = *dec.AllocFile
+	}
+	if dec.AllocFileHash != nil {
+		g.AllocFileHash = dec.AllocFileHash
+	}
 	if dec.AuRaSeal != nil {
 		g.AuRaSeal = dec.AuRaSeal
 	}
@@ -17,15 +17,19 @@ var _ = (*genesisAccountMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (g GenesisAccount) MarshalJSON() ([]byte, error) {
 	type GenesisAccount struct {
-		Constructor hexutil.Bytes               `json:"constructor,omitempty"`
-		Code        hexutil.Bytes               `json:"code,omitempty"`
-		Storage     map[storageJSON]storageJSON `json:"storage,omitempty"`
-		Balance     *math.HexOrDecimal256       `json:"balance" gencodec:"required"`
-		Nonce       math.HexOrDecimal64         `json:"nonce,omitempty"`
-		PrivateKey  hexutil.Bytes               `json:"secretKey,omitempty"`
+		Constructor     hexutil.Bytes               `json:"constructor,omitempty"`
+		ConstructorArgs hexutil.Bytes               `json:"constructorArgs,omitempty"`
+		ConstructorGas  math.HexOrDecimal64         `json:"constructorGas,omitempty"`
+		Code            hexutil.Bytes               `json:"code,omitempty"`
+		Storage         map[storageJSON]storageJSON `json:"storage,omitempty"`
+		Balance         *math.HexOrDecimal256       `json:"balance" gencodec:"required"`
+		Nonce           math.HexOrDecimal64         `json:"nonce,omitempty"`
+		PrivateKey      hexutil.Bytes               `json:"secretKey,omitempty"`
 	}
 	var enc GenesisAccount
 	enc.Constructor = g.Constructor
+	enc.ConstructorArgs = g.ConstructorArgs
+	enc.ConstructorGas = math.HexOrDecimal64(g.ConstructorGas)
 	enc.Code = g.Code
 	if g.Storage != nil {
 		enc.Storage = make(map[storageJSON]storageJSON, len(g.Storage))
@@ -42,12 +46,14 @@ func (g GenesisAccount) MarshalJSON() ([]byte, error) {
 // UnmarshalJSON unmarshals from JSON.
 func (g *GenesisAccount) UnmarshalJSON(input []byte) error {
 	type GenesisAccount struct {
-		Constructor *hexutil.Bytes              `json:"constructor,omitempty"`
-		Code        *hexutil.Bytes              `json:"code,omitempty"`
-		Storage     map[storageJSON]storageJSON `json:"storage,omitempty"`
-		Balance     *math.HexOrDecimal256       `json:"balance" gencodec:"required"`
-		Nonce       *math.HexOrDecimal64        `json:"nonce,omitempty"`
-		PrivateKey  *hexutil.Bytes              `json:"secretKey,omitempty"`
+		Constructor     *hexutil.Bytes              `json:"constructor,omitempty"`
+		ConstructorArgs *hexutil.Bytes              `json:"constructorArgs,omitempty"`
+		ConstructorGas  *math.HexOrDecimal64        `json:"constructorGas,omitempty"`
+		Code            *hexutil.Bytes              `json:"code,omitempty"`
+		Storage         map[storageJSON]storageJSON `json:"storage,omitempty"`
+		Balance         *math.HexOrDecimal256       `json:"balance" gencodec:"required"`
+		Nonce           *math.HexOrDecimal64        `json:"nonce,omitempty"`
+		PrivateKey      *hexutil.Bytes              `json:"secretKey,omitempty"`
 	}
 	var dec GenesisAccount
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -56,6 +62,12 @@ func (g *GenesisAccount) UnmarshalJSON(input []byte) error {
 	if dec.Constructor != nil {
 		g.Constructor = *dec.Constructor
 	}
+	if dec.ConstructorArgs != nil {
+		g.ConstructorArgs = *dec.ConstructorArgs
+	}
+	if dec.ConstructorGas != nil {
+		g.ConstructorGas = uint64(*dec.ConstructorGas)
+	}
 	if dec.Code != nil {
 		g.Code = *dec.Code
 	}
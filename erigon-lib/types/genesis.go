@@ -52,6 +52,14 @@ type Genesis struct {
 	Coinbase   common.Address `json:"coinbase"`
 	Alloc      GenesisAlloc   `json:"alloc"      gencodec:"required"`
 
+	// AllocFile is the path of a file holding the alloc in the format read by ReadGenesisAllocFile,
+	// for allocs too large to be held in memory. Alloc must then be empty. AllocFile is resolved relative to
+	// the genesis file by ResolveAllocFile.
+	AllocFile string `json:"allocFile,omitempty"`
+	// AllocFileHash is the keccak256 hash of the content of AllocFile, recorded when the genesis
+	// is written, so that a changed file is detected.
+	AllocFileHash *common.Hash `json:"allocFileHash,omitempty"`
+
 	AuRaSeal *AuRaSeal `json:"seal"`
 
 	// These fields are used for consensus tests. Please don't use them
@@ -115,12 +123,14 @@ func DecodeGenesisAlloc(i interface{}) (GenesisAlloc, error) {
 // GenesisAccount is an account in the state of the genesis block.
 // Either use "constructor" for deployment code or "code" directly for the final code.
 type GenesisAccount struct {
-	Constructor []byte                      `json:"constructor,omitempty"` // deployment code
-	Code        []byte                      `json:"code,omitempty"`        // final contract code
-	Storage     map[common.Hash]common.Hash `json:"storage,omitempty"`
-	Balance     *big.Int                    `json:"balance" gencodec:"required"`
-	Nonce       uint64                      `json:"nonce,omitempty"`
-	PrivateKey  []byte                      `json:"secretKey,omitempty"` // for tests
+	Constructor     []byte                      `json:"constructor,omitempty"`     // deployment code
+	ConstructorArgs []byte                      `json:"constructorArgs,omitempty"` // ABI-encoded arguments appended to the deployment code
+	ConstructorGas  uint64                      `json:"constructorGas,omitempty"`  // gas available to the constructor, 0 means the default cap
+	Code            []byte                      `json:"code,omitempty"`            // final contract code
+	Storage         map[common.Hash]common.Hash `json:"storage,omitempty"`
+	Balance         *big.Int                    `json:"balance" gencodec:"required"`
+	Nonce           uint64                      `json:"nonce,omitempty"`
+	PrivateKey      []byte                      `json:"secretKey,omitempty"` // for tests
 }
 
 // field type overrides for gencodec
@@ -139,12 +149,14 @@ type genesisSpecMarshaling struct {
 }
 
 type genesisAccountMarshaling struct {
-	Constructor hexutil.Bytes
-	Code        hexutil.Bytes
-	Balance     *math.HexOrDecimal256
-	Nonce       math.HexOrDecimal64
-	Storage     map[storageJSON]storageJSON
-	PrivateKey  hexutil.Bytes
+	Constructor     hexutil.Bytes
+	ConstructorArgs hexutil.Bytes
+	ConstructorGas  math.HexOrDecimal64
+	Code            hexutil.Bytes
+	Balance         *math.HexOrDecimal256
+	Nonce           math.HexOrDecimal64
+	Storage         map[storageJSON]storageJSON
+	PrivateKey      hexutil.Bytes
 }
 
 // storageJSON represents a 256 bit byte array, but allows less than 256 bits when
@@ -0,0 +1,117 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+
+	"github.com/erigontech/erigon-lib/common"
+)
+
+// ReadGenesisAllocFile streams the accounts of a genesis alloc file to fn, in file order. The file is a
+// sequence of JSON objects, usually one per line, each holding the fields of a GenesisAccount and its address,
+// in strictly ascending address order as written by WriteGenesisAllocFile:
+//
+//	{"address": "0x00000000000000000000000000000000000000aa", "balance": "0x1"}
+//	{"address": "0x00000000000000000000000000000000000000bb", "balance": "0x2", "code": "0x6000"}
+//
+// Only one account is decoded at a time, so memory use doesn't depend on the size of the file. The order makes
+// duplicates detectable without remembering the addresses seen, and the accounts are applied in the same order
+// as an inline alloc.
+func ReadGenesisAllocFile(r io.Reader, fn func(common.Address, GenesisAccount) error) error {
+	dec := json.NewDecoder(r)
+	var prev *common.Address
+	for n := 1; dec.More(); n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("alloc file entry %d: %w", n, err)
+		}
+		var entry struct {
+			Address *common.Address `json:"address"`
+		}
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return fmt.Errorf("alloc file entry %d: %w", n, err)
+		}
+		if entry.Address == nil {
+			return fmt.Errorf("alloc file entry %d: missing address", n)
+		}
+		if prev != nil && bytes.Compare(prev[:], entry.Address[:]) >= 0 {
+			if *prev == *entry.Address {
+				return fmt.Errorf("alloc file entry %d: duplicate account %x", n, *entry.Address)
+			}
+			return fmt.Errorf("alloc file entry %d: account %x is not in ascending address order", n, *entry.Address)
+		}
+		prev = entry.Address
+		var account GenesisAccount
+		if err := json.Unmarshal(raw, &account); err != nil {
+			return fmt.Errorf("alloc file entry %d (%x): %w", n, *entry.Address, err)
+		}
+		if err := fn(*entry.Address, account); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteGenesisAllocFile writes alloc in the format read by ReadGenesisAllocFile, one account per line
+// in address order.
+func WriteGenesisAllocFile(w io.Writer, alloc GenesisAlloc) error {
+	addrs := make([]common.Address, 0, len(alloc))
+	for addr := range alloc {
+		addrs = append(addrs, addr)
+	}
+	slices.SortFunc(addrs, func(a, b common.Address) int { return bytes.Compare(a[:], b[:]) })
+
+	bw := bufio.NewWriter(w)
+	for _, addr := range addrs {
+		account, err := json.Marshal(alloc[addr])
+		if err != nil {
+			return err
+		}
+		// the account always has a balance, so it is a non-empty object the address can be prepended to
+		account, _ = bytes.CutPrefix(account, []byte("{"))
+		if _, err := fmt.Fprintf(bw, "{\"address\":\"%s\",%s\n", addr.Hex(), account); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ResolveAllocFile makes the AllocFile of g absolute, resolving a relative one against the directory of the genesis
+// file it was read from. The path is stored with the genesis and used again by the execution of block 0, so it
+// must depend neither on the working directory nor on where the genesis file was.
+func ResolveAllocFile(g *Genesis, genesisPath string) error {
+	if g.AllocFile == "" {
+		return nil
+	}
+	path := g.AllocFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(genesisPath), path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("genesis alloc file: %w", err)
+	}
+	g.AllocFile = path
+	return nil
+}
@@ -43,7 +43,6 @@ import (
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/erigontech/erigon-db/downloader"
 	"github.com/erigontech/erigon-db/downloader/downloadercfg"
@@ -127,6 +126,7 @@ import (
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/contracts"
 	"github.com/erigontech/erigon/rpc/jsonrpc"
+	"github.com/erigontech/erigon/rpc/jsonrpc/receipts"
 	"github.com/erigontech/erigon/rpc/rpchelper"
 	privateapi2 "github.com/erigontech/erigon/turbo/privateapi"
 	"github.com/erigontech/erigon/turbo/services"
@@ -176,6 +176,10 @@ type Ethereum struct {
 	stateDiffClient     *direct.StateDiffClientDirect
 	rpcFilters          *rpchelper.Filters
 	rpcDaemonStateCache kvcache.Cache
+	receiptsGenerator   *receipts.Generator                  // shared by p2p and the embedded RPC daemon
+	receiptsCache       *receipts.PersistentCache            // optional, used by receiptsGenerator
+	peerReputation      *sentry_multi_client.PeerReputation  // optional, used by sentriesClient
+	messageRecorder     *sentry_multi_client.MessageRecorder // optional, used by sentriesClient
 
 	miningSealingQuit   chan struct{}
 	pendingBlocks       chan *types.Block
@@ -382,8 +386,14 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		if h != (common.Hash{}) { // fallback to db content
 			genesisSpec = nil
 		}
+		var configOverride *core.ConfigOverride
+		if config.OverrideChainConfig != "" {
+			if configOverride, err = core.ReadConfigOverrideFile(config.OverrideChainConfig); err != nil {
+				return err
+			}
+		}
 		var genesisErr error
-		chainConfig, genesis, genesisErr = core.WriteGenesisBlock(tx, genesisSpec, config.OverrideOsakaTime, dirs, logger)
+		chainConfig, genesis, genesisErr = core.WriteGenesisBlock(ctx, tx, genesisSpec, config.OverrideOsakaTime, configOverride, dirs, logger)
 		if _, ok := genesisErr.(*chain.ConfigCompatError); genesisErr != nil && !ok {
 			return genesisErr
 		}
@@ -537,37 +547,6 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			backend.sentryServers = append(backend.sentryServers, server)
 			sentries = append(sentries, direct.NewSentryClientDirect(protocol, server))
 		}
-
-		go func() {
-			logEvery := time.NewTicker(90 * time.Second)
-			defer logEvery.Stop()
-
-			var logItems []interface{}
-
-			for {
-				select {
-				case <-backend.sentryCtx.Done():
-					return
-				case <-logEvery.C:
-					logItems = logItems[:0]
-					peerCountMap := map[uint]int{}
-					for _, srv := range backend.sentryServers {
-						counts := srv.SimplePeerCount()
-						for protocol, count := range counts {
-							peerCountMap[protocol] += count
-						}
-					}
-					if len(peerCountMap) == 0 {
-						logger.Warn("[p2p] No GoodPeers")
-					} else {
-						for protocol, count := range peerCountMap {
-							logItems = append(logItems, eth.ProtocolToString[protocol], strconv.Itoa(count))
-						}
-						logger.Info("[p2p] GoodPeers", logItems...)
-					}
-				}
-			}
-		}()
 	}
 
 	// setup periodic logging and prometheus updates
@@ -618,10 +597,11 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		borConfig := consensusConfig.(*borcfg.BorConfig)
 
 		polygonBridge = bridge.NewService(bridge.ServiceConfig{
-			Store:        bridgeStore,
-			Logger:       logger,
-			BorConfig:    borConfig,
-			EventFetcher: heimdallClient,
+			Store:         bridgeStore,
+			Logger:        logger,
+			BorConfig:     borConfig,
+			EventFetcher:  heimdallClient,
+			HealEventsGap: config.PolygonBridgeHealEventsGap,
 		})
 
 		if err := heimdallStore.Milestones().Prepare(ctx); err != nil {
@@ -633,11 +613,17 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			return nil, err
 		}
 
+		var tipLagSampleInterval time.Duration
+		if !config.WithoutHeimdall {
+			tipLagSampleInterval = heimdall.DefaultTipLagSampleInterval
+		}
 		heimdallService = heimdall.NewService(heimdall.ServiceConfig{
-			Store:     heimdallStore,
-			BorConfig: borConfig,
-			Client:    heimdallClient,
-			Logger:    logger,
+			Store:                heimdallStore,
+			BorConfig:            borConfig,
+			Client:               heimdallClient,
+			Logger:               logger,
+			TipLagSampleInterval: tipLagSampleInterval,
+			EventsTip:            polygonBridge,
 		})
 
 		bridgeRPC = bridge.NewBackendServer(ctx, polygonBridge)
@@ -704,6 +690,20 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		}
 	}
 
+	// with the RPC daemon embedded, its cache config wins: it is the main consumer of receipts
+	if httpCfg := stack.Config().Http; httpCfg.Enabled {
+		backend.receiptsGenerator = receipts.NewGenerator(blockReader, backend.engine, httpCfg.EvmCallTimeout, config.Sync.RPCReceiptsCache, "rpc")
+	} else {
+		backend.receiptsGenerator = receipts.NewGenerator(blockReader, backend.engine, 5*time.Minute, config.Sync.P2PReceiptsCache, "p2p")
+	}
+	if config.Sync.PersistentReceiptsCache.Enabled {
+		if backend.receiptsCache, err = receipts.OpenPersistentCache(ctx, config.Dirs.DataDir, config.Sync.PersistentReceiptsCache, false /* readonly */, logger); err != nil {
+			return nil, err
+		}
+		backend.receiptsGenerator.SetPersistentCache(backend.receiptsCache)
+	}
+	backend.receiptsGenerator.InvalidateOnUnwind(backend.sentryCtx, backend.notifications.Events)
+
 	sentryMcDisableBlockDownload := chainConfig.Bor != nil
 	backend.sentriesClient, err = sentry_multi_client.NewMultiClient(
 		backend.chainDB,
@@ -711,6 +711,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		backend.engine,
 		sentries,
 		config.Sync,
+		backend.receiptsGenerator,
 		blockReader,
 		blockBufferSize,
 		statusDataProvider,
@@ -722,6 +723,21 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 	if err != nil {
 		return nil, err
 	}
+	backend.sentriesClient.RelayPeerEvents(backend.notifications.Events)
+	go backend.sentriesClient.LogPeerCounts(backend.sentryCtx, 90*time.Second)
+	if config.Sync.PeerReputation.Enabled {
+		if backend.peerReputation, err = sentry_multi_client.OpenPeerReputation(ctx, config.Dirs.DataDir, config.Sync.PeerReputation, logger); err != nil {
+			return nil, err
+		}
+		backend.sentriesClient.SetPeerReputation(backend.peerReputation)
+		go backend.peerReputation.Run(backend.sentryCtx)
+	}
+	if config.Sync.MessageRecording.Dir != "" {
+		if backend.messageRecorder, err = sentry_multi_client.OpenMessageRecorder(config.Sync.MessageRecording, logger); err != nil {
+			return nil, err
+		}
+		backend.sentriesClient.SetMessageRecorder(backend.messageRecorder)
+	}
 
 	var ethashApi *ethash.API
 	if casted, ok := backend.engine.(*ethash.Ethash); ok {
@@ -738,12 +754,24 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		logger,
 		latestBlockBuiltStore,
 		chainConfig,
+		privateapi2.SubscribeConfig{
+			QueueSize:   stack.Config().PrivateApiSubscribeQueueSize,
+			SlowTimeout: stack.Config().PrivateApiSlowSubscriberTimeout,
+		},
 	)
 
 	backend.stateDiffClient = direct.NewStateDiffClientDirect(kvRPC)
 	var txnProvider txnprovider.TxnProvider
 	if config.TxPool.Disable {
 		backend.txPoolGrpcServer = &txpool.GrpcDisabled{}
+		if addr := stack.Config().Http.TxPoolApiAddr; addr != "" {
+			// the external txpool doesn't get the GetPooledTransactions requests reaching our sentries
+			txPoolConn, err := grpcutil.Connect(nil, addr)
+			if err != nil {
+				return nil, fmt.Errorf("connect to the txpool at %s: %w", addr, err)
+			}
+			backend.sentriesClient.SetTxPool(txpoolproto.NewTxpoolClient(txPoolConn))
+		}
 	} else {
 		sentries := backend.sentriesClient.Sentries()
 		blockBuilderNotifyNewTxns := func() {
@@ -804,6 +832,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 			httpRpcCfg.Dirs,
 			backend.polygonBridge,
 		)
+		baseApi.SetReceiptsCache(config.Sync.RPCReceiptsCache)
 		ethApi := jsonrpc.NewEthAPI(
 			baseApi,
 			backend.chainDB,
@@ -958,6 +987,7 @@ func New(ctx context.Context, stack *node.Node, config *ethconfig.Config, logger
 		for {
 			select {
 			case b := <-backend.minedBlocks:
+				backend.sentriesClient.MarkBlockProduced(b.NumberU64(), b.Hash())
 				if !sentryMcDisableBlockDownload {
 					// Add mined header and block body before broadcast. This is because the broadcast call
 					// will trigger the staged sync which will require headers and blocks to be available
@@ -1161,6 +1191,7 @@ func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig
 	}
 	// start HTTP API
 	httpRpcCfg := stack.Config().Http
+	httpRpcCfg.Sync.RPCReceiptsCache = config.Sync.RPCReceiptsCache
 	//eth.APIBackend.gpo = gasprice.NewOracle(eth.APIBackend, gpoParams)
 	if config.Ethstats != "" {
 		var headCh chan [][]byte
@@ -1170,7 +1201,7 @@ func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig
 		}
 	}
 
-	s.apiList = jsonrpc.APIList(chainKv, s.ethRpcClient, s.txPoolRpcClient, s.miningRpcClient, s.rpcFilters, s.rpcDaemonStateCache, blockReader, &httpRpcCfg, s.engine, s.logger, s.polygonBridge, s.heimdallService)
+	s.apiList = jsonrpc.APIList(chainKv, s.ethRpcClient, s.txPoolRpcClient, s.miningRpcClient, s.rpcFilters, s.rpcDaemonStateCache, blockReader, &httpRpcCfg, s.engine, s.logger, s.polygonBridge, s.heimdallService, s.receiptsGenerator)
 
 	if config.SilkwormRpcDaemon && httpRpcCfg.Enabled {
 		interface_log_settings := silkworm.RpcInterfaceLogSettings{
@@ -1197,7 +1228,7 @@ func (s *Ethereum) Init(stack *node.Node, config *ethconfig.Config, chainConfig
 		s.silkwormRPCDaemonService = &silkwormRPCDaemonService
 	} else {
 		go func() {
-			if err := rpcdaemoncli.StartRpcServer(ctx, &httpRpcCfg, s.apiList, s.logger); err != nil {
+			if err := rpcdaemoncli.StartRpcServer(ctx, &httpRpcCfg, s.apiList, nil /* backendAPI */, s.logger); err != nil {
 				s.logger.Error("cli.StartRpcServer error", "err", err)
 			}
 		}()
@@ -1425,21 +1456,53 @@ func (s *Ethereum) RegisterMinedBlockObserver(callback func(msg *types.Block)) e
 
 func (s *Ethereum) ChainKV() kv.RwDB            { return s.chainDB }
 func (s *Ethereum) NetVersion() (uint64, error) { return s.networkID, nil }
-func (s *Ethereum) NetPeerCount() (uint64, error) {
-	var sentryPc uint64 = 0
 
-	s.logger.Trace("sentry", "peer count", sentryPc)
-	for _, sc := range s.sentriesClient.Sentries() {
-		ctx := context.Background()
-		reply, err := sc.PeerCount(ctx, &protosentry.PeerCountRequest{})
-		if err != nil {
-			s.logger.Warn("sentry", "err", err)
-			return 0, nil
-		}
-		sentryPc += reply.Count
+// SyncMode returns whether the new blocks announced by the peers are followed, or dropped in the initial cycle, empty
+// on the chains whose blocks aren't downloaded by the header downloader.
+func (s *Ethereum) SyncMode() string {
+	if s.chainConfig.Bor != nil {
+		return ""
 	}
+	return string(s.sentriesClient.SyncMode())
+}
 
-	return sentryPc, nil
+// SnapshotsDownloadProgress returns the percentage of the snapshots downloaded by the embedded downloader, false if
+// there's none or it completed.
+func (s *Ethereum) SnapshotsDownloadProgress() (float32, bool) {
+	if s.downloader == nil {
+		return 0, false
+	}
+	stats := s.downloader.Stats()
+	if stats.NumTorrents == 0 || stats.AllTorrentsComplete() {
+		return 0, false
+	}
+	if stats.BytesTotal == 0 {
+		return 0, true
+	}
+	return float32(stats.BytesCompleted) * 100 / float32(stats.BytesTotal), true
+}
+
+// NetPeerCount counts the peers connected to all the sentries. The sentries which can't count their peers are
+// skipped, it fails only if none can.
+func (s *Ethereum) NetPeerCount() (uint64, error) {
+	count, errs := s.sentriesClient.PeerCount(context.Background())
+	for _, err := range errs {
+		s.logger.Warn("[sentry] can't count the peers", "err", err)
+	}
+	if len(errs) > 0 && len(errs) == len(s.sentriesClient.Sentries()) {
+		return 0, fmt.Errorf("no sentry can count its peers: %w", errs[0])
+	}
+	return count, nil
+}
+
+// ReceiptsCacheStats returns the stats of the caches of the receipts generator shared by p2p and the RPC daemon.
+func (s *Ethereum) ReceiptsCacheStats() (blocks, txns receipts.CacheStats) {
+	return s.receiptsGenerator.CacheStats()
+}
+
+// RequestTraces returns the lifecycles of the last header and body requests to the peers, the latest first.
+func (s *Ethereum) RequestTraces() []sentry_multi_client.RequestTrace {
+	return s.sentriesClient.RequestTraces()
 }
 
 func (s *Ethereum) NodesInfo(limit int) (*remote.NodesInfoReply, error) {
@@ -1537,7 +1600,13 @@ func setUpBlockReader(ctx context.Context, db kv.RwDB, dirs datadir.Dirs, snConf
 
 	if chainConfig.Bor != nil {
 		allBorSnapshots = heimdall.NewRoSnapshots(snConfig.Snapshot, dirs.Snap, minFrozenBlock, logger)
-		bridgeStore = bridge.NewSnapshotStore(bridge.NewMdbxStore(dirs.DataDir, logger, false, int64(nodeConfig.Http.DBReadConcurrency)), allBorSnapshots, chainConfig.Bor)
+		bridgeStore = bridge.NewSnapshotStore(bridge.NewMdbxStore(dirs.DataDir, logger, false, int64(nodeConfig.Http.DBReadConcurrency)), allBorSnapshots, chainConfig.Bor,
+			bridge.WithEventsRetention(bridge.EventsRetention{
+				Blocks:   snConfig.PolygonBridgeEventsRetentionBlocks,
+				Duration: snConfig.PolygonBridgeEventsRetentionDuration,
+			}),
+			// healing a gap fetches again the events from the last frozen one, the db ones included
+			bridge.WithEventsWritePolicy(bridge.EventsWritePolicy{AllowRewrite: snConfig.PolygonBridgeHealEventsGap}))
 		heimdallStore = heimdall.NewSnapshotStore(heimdall.NewMdbxStore(logger, dirs.DataDir, false, int64(nodeConfig.Http.DBReadConcurrency)), allBorSnapshots)
 	}
 	blockReader := freezeblocks.NewBlockReader(allSnapshots, allBorSnapshots, heimdallStore, bridgeStore)
@@ -1580,16 +1649,12 @@ func setUpBlockReader(ctx context.Context, db kv.RwDB, dirs datadir.Dirs, snConf
 }
 
 func (s *Ethereum) Peers(ctx context.Context) (*remote.PeersReply, error) {
-	var reply remote.PeersReply
-	for _, sentryClient := range s.sentriesClient.Sentries() {
-		peers, err := sentryClient.Peers(ctx, &emptypb.Empty{})
-		if err != nil {
-			return nil, fmt.Errorf("ethereum backend MultiClient.Peers error: %w", err)
-		}
-		reply.Peers = append(reply.Peers, peers.Peers...)
+	peers, errs := s.sentriesClient.Peers(ctx)
+	reply := &remote.PeersReply{Peers: peers}
+	for _, err := range errs {
+		reply.Errors = append(reply.Errors, err.Error())
 	}
-
-	return &reply, nil
+	return reply, nil
 }
 
 func (s *Ethereum) AddPeer(ctx context.Context, req *remote.AddPeerRequest) (*remote.AddPeerReply, error) {
@@ -1752,6 +1817,15 @@ func (s *Ethereum) Stop() error {
 	for _, sentryServer := range s.sentryServers {
 		sentryServer.Close()
 	}
+	if s.receiptsCache != nil {
+		s.receiptsCache.Close()
+	}
+	if s.peerReputation != nil {
+		s.peerReputation.Close()
+	}
+	if s.messageRecorder != nil {
+		s.messageRecorder.Close()
+	}
 	s.chainDB.Close()
 
 	if s.silkwormRPCDaemonService != nil {
@@ -88,10 +88,23 @@ var Defaults = Config{
 		ExecWorkerCount:            dbg.Exec3Workers, //only half of CPU, other half will spend for snapshots build/merge/prune
 		BodyCacheLimit:             256 * 1024 * 1024,
 		BodyDownloadTimeoutSeconds: 2,
+		PosHeadersBacklogLimit:     64 * datasize.MB,
 		//LoopBlockLimit:             100_000,
 		ParallelStateFlushing:    true,
 		ChaosMonkey:              false,
 		AlwaysGenerateChangesets: !dbg.BatchCommitments,
+		PersistentReceiptsCache: PersistentReceiptsCache{
+			Blocks: 10_000,
+			Depth:  100_000,
+		},
+		PeerReputation: PeerReputation{
+			Threshold: -20,
+			Horizon:   24 * time.Hour,
+		},
+		MessageRecording: MessageRecording{
+			FileSize: 256 * datasize.MB,
+			Files:    8,
+		},
 	},
 	Ethash: ethashcfg.Config{
 		CachesInMem:      2,
@@ -247,6 +260,8 @@ type Config struct {
 	InternalCL bool
 
 	OverrideOsakaTime *big.Int `toml:",omitempty"`
+	// Path of a JSON file rescheduling the forks of the chain, see core.ConfigOverride
+	OverrideChainConfig string `toml:",omitempty"`
 
 	// Embedded Silkworm support
 	SilkwormExecution            bool
@@ -266,6 +281,13 @@ type Config struct {
 	PolygonPosSingleSlotFinality        bool
 	PolygonPosSingleSlotFinalityBlockAt uint64
 
+	// Fetch again the bridge events missing between the frozen files and the db
+	PolygonBridgeHealEventsGap bool
+
+	// Keep the frozen bridge events of the last blocks, or of the last duration, in the db rather than pruning them
+	PolygonBridgeEventsRetentionBlocks   uint64
+	PolygonBridgeEventsRetentionDuration time.Duration
+
 	// Account Abstraction
 	AllowAA bool
 }
@@ -277,9 +299,11 @@ type Sync struct {
 	ReconWorkerCount int
 
 	BodyCacheLimit             datasize.ByteSize
-	BodyDownloadTimeoutSeconds int // TODO: change to duration
+	BodyDownloadTimeoutSeconds int               // TODO: change to duration
+	PosHeadersBacklogLimit     datasize.ByteSize // size of the header batches not processed yet from which the PoS download pauses its requests, never if 0
 	BreakAfterStage            string
 	LoopBlockLimit             uint
+	LoopTimeBudget             time.Duration // time after which the long-running stages of a loop iteration yield, 0 means none
 	ParallelStateFlushing      bool
 
 	UploadLocation   string
@@ -290,4 +314,44 @@ type Sync struct {
 	AlwaysGenerateChangesets bool
 	KeepExecutionProofs      bool
 	PersistReceiptsCacheV2   bool
+
+	P2PReceiptsCache ReceiptsCache // receipts served to peers, unless shared with the embedded RPC daemon
+	RPCReceiptsCache ReceiptsCache // receipts served by the RPC daemon
+
+	PersistentReceiptsCache PersistentReceiptsCache
+
+	PeerReputation PeerReputation
+
+	RequestTraces int // how many of the last header and body requests to the peers are traced for the diagnostics, none if 0
+
+	MessageRecording MessageRecording
+}
+
+// ReceiptsCache configures the in-memory cache of receipts generated by re-executing blocks.
+type ReceiptsCache struct {
+	Blocks int           // amount of blocks to keep receipts for, 0 means default
+	TTL    time.Duration // how long cached receipts stay valid, 0 means until evicted
+}
+
+// PersistentReceiptsCache configures the on-disk cache of generated receipts, shared by all receipts generators.
+type PersistentReceiptsCache struct {
+	Enabled bool
+	Blocks  int    // maximum amount of blocks to keep receipts for
+	Depth   uint64 // receipts of blocks deeper than this below the head are pruned
+}
+
+// PeerReputation configures the reputation of the peers built from their penalties and useful responses, kept on disk
+// across restarts.
+type PeerReputation struct {
+	Enabled   bool
+	Enforce   bool          // kick the peers with a bad reputation when they connect, only log them otherwise
+	Threshold float64       // reputation below which a peer is bad
+	Horizon   time.Duration // the reputation decays to nothing over it, so that old penalties are forgotten
+}
+
+// MessageRecording configures the recording of the inbound p2p messages, to be replayed when debugging.
+type MessageRecording struct {
+	Dir      string            // where the messages are recorded, none are if empty
+	FileSize datasize.ByteSize // from which a file is rotated
+	Files    int               // the oldest files are removed beyond it
 }
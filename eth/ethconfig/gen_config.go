@@ -53,6 +53,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Ethstats                            string
 		InternalCL                          bool
 		OverrideOsakaTime                   *big.Int `toml:",omitempty"`
+		OverrideChainConfig                 string   `toml:",omitempty"`
 		SilkwormExecution                   bool
 		SilkwormRpcDaemon                   bool
 		SilkwormSentry                      bool
@@ -97,6 +98,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Ethstats = c.Ethstats
 	enc.InternalCL = c.InternalCL
 	enc.OverrideOsakaTime = c.OverrideOsakaTime
+	enc.OverrideChainConfig = c.OverrideChainConfig
 	enc.SilkwormExecution = c.SilkwormExecution
 	enc.SilkwormRpcDaemon = c.SilkwormRpcDaemon
 	enc.SilkwormSentry = c.SilkwormSentry
@@ -146,6 +148,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Ethstats                            *string
 		InternalCL                          *bool
 		OverrideOsakaTime                   *big.Int `toml:",omitempty"`
+		OverrideChainConfig                 *string  `toml:",omitempty"`
 		SilkwormExecution                   *bool
 		SilkwormRpcDaemon                   *bool
 		SilkwormSentry                      *bool
@@ -249,6 +252,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.OverrideOsakaTime != nil {
 		c.OverrideOsakaTime = dec.OverrideOsakaTime
 	}
+	if dec.OverrideChainConfig != nil {
+		c.OverrideChainConfig = *dec.OverrideChainConfig
+	}
 	if dec.SilkwormExecution != nil {
 		c.SilkwormExecution = *dec.SilkwormExecution
 	}
@@ -0,0 +1,54 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"testing"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/types"
+)
+
+func TestBloomMatches(t *testing.T) {
+	var (
+		addr1, addr2   = common.Address{1}, common.Address{2}
+		topic1, topic2 = common.Hash{1}, common.Hash{2}
+	)
+	bloom := types.CreateBloom(types.Receipts{{Logs: types.Logs{{Address: addr1, Topics: []common.Hash{topic1}}}}})
+
+	for _, test := range []struct {
+		name      string
+		addresses []common.Address
+		topics    [][]common.Hash
+		want      bool
+	}{
+		{"any", nil, nil, true},
+		{"address", []common.Address{addr1}, nil, true},
+		{"other address", []common.Address{addr2}, nil, false},
+		{"one of the addresses", []common.Address{addr2, addr1}, nil, true},
+		{"topic", nil, [][]common.Hash{{topic1}}, true},
+		{"other topic", nil, [][]common.Hash{{topic2}}, false},
+		{"one of the topics", nil, [][]common.Hash{{topic2, topic1}}, true},
+		{"wildcard topic", nil, [][]common.Hash{{}, {topic1}}, true},
+		{"address and topic", []common.Address{addr1}, [][]common.Hash{{topic1}}, true},
+		{"address and other topic", []common.Address{addr1}, [][]common.Hash{{topic1}, {topic2}}, false},
+	} {
+		if got := BloomMatches(bloom, test.addresses, test.topics); got != test.want {
+			t.Errorf("%s: got %t, want %t", test.name, got, test.want)
+		}
+	}
+}
@@ -20,8 +20,11 @@
 package chainspec
 
 import (
+	"fmt"
+
 	"github.com/erigontech/erigon-lib/chain/networkname"
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/p2p/enode"
 )
 
 // MainnetBootnodes are the enode URLs of the P2P bootstrap nodes running on
@@ -132,18 +135,47 @@ func KnownDNSNetwork(genesis common.Hash) string {
 	return knownDNSNetwork[genesis]
 }
 
-var bootNodeURLsByGenesisHash = make(map[common.Hash][]string)
-
+// BootnodeURLsByGenesisHash returns the bootnodes of the built-in or registered chain with the given genesis hash,
+// see BootnodesByChainName.
 func BootnodeURLsByGenesisHash(genesis common.Hash) []string {
-	return bootNodeURLsByGenesisHash[genesis]
+	name, ok := chainNameByGenesisHash[genesis]
+	if !ok {
+		return nil
+	}
+	return BootnodesByChainName(name)
 }
 
 var bootNodeURLsByChainName = make(map[string][]string)
+var bootNodeOverridesByChainName = make(map[string][]string)
 
-func BootnodeURLsOfChain(chain string) []string {
+// BootnodesByChainName returns the bootnodes of a built-in or registered chain.
+// Bootnodes set with SetBootnodes take precedence over the ones the chain was registered with.
+func BootnodesByChainName(chain string) []string {
+	if urls, ok := bootNodeOverridesByChainName[chain]; ok {
+		return urls
+	}
 	return bootNodeURLsByChainName[chain]
 }
 
+// SetBootnodes replaces the bootnodes of chain returned by BootnodesByChainName. The chain doesn't have to be
+// registered yet. Like RegisterChain, it must be called during startup.
+func SetBootnodes(chain string, urls []string) error {
+	if err := validateBootnodes(urls); err != nil {
+		return fmt.Errorf("bootnodes of chain %q: %w", chain, err)
+	}
+	bootNodeOverridesByChainName[chain] = urls
+	return nil
+}
+
+func validateBootnodes(urls []string) error {
+	for _, url := range urls {
+		if _, err := enode.ParseV4(url); err != nil {
+			return fmt.Errorf("invalid enode URL %q: %w", url, err)
+		}
+	}
+	return nil
+}
+
 func StaticPeerURLsOfChain(chain string) []string {
 	switch chain {
 	case networkname.Sepolia:
@@ -22,10 +22,12 @@ package chainspec
 import (
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"math/big"
 	"path"
+	"slices"
 
 	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/chain/networkname"
@@ -66,11 +68,27 @@ var (
 )
 
 var (
-	GnosisGenesisStateRoot = common.HexToHash("0x40cf4430ecaa733787d1a65154a3b9efb560c95d9e324a23b97f0609b539133b")
-	ChiadoGenesisStateRoot = common.HexToHash("0x9ec3eaf4e6188dfbdd6ade76eaa88289b57c63c9a2cde8d35291d5a29e143d31")
-	TestGenesisStateRoot   = common.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+	MainnetGenesisStateRoot = common.HexToHash("0xd7f8974fb5ac78d9ac099b9ad5018bedc2ce0a72dad1827a1709da30580f0544")
+	SepoliaGenesisStateRoot = common.HexToHash("0x5eb6e371a698b8d68f665192350ffcecbbbf322916f4b51bd79bb6887da3f494")
+	GnosisGenesisStateRoot  = common.HexToHash("0x40cf4430ecaa733787d1a65154a3b9efb560c95d9e324a23b97f0609b539133b")
+	ChiadoGenesisStateRoot  = common.HexToHash("0x9ec3eaf4e6188dfbdd6ade76eaa88289b57c63c9a2cde8d35291d5a29e143d31")
+	TestGenesisStateRoot    = common.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
 )
 
+var genesisStateRootByChainName = map[string]common.Hash{
+	networkname.Mainnet: MainnetGenesisStateRoot,
+	networkname.Sepolia: SepoliaGenesisStateRoot,
+	networkname.Gnosis:  GnosisGenesisStateRoot,
+	networkname.Chiado:  ChiadoGenesisStateRoot,
+}
+
+// GenesisStateRootByChainName returns the state root of the genesis block of a built-in network, if it is recorded.
+// It allows writing the genesis block without applying the alloc.
+func GenesisStateRootByChainName(chain string) (common.Hash, bool) {
+	root, ok := genesisStateRootByChainName[chain]
+	return root, ok
+}
+
 var (
 	// MainnetChainConfig is the chain parameters to run a node on the main network.
 	MainnetChainConfig = ReadChainSpec(chainspecs, "chainspecs/mainnet.json")
@@ -147,8 +165,18 @@ func GenesisHashByChainName(chain string) *common.Hash {
 
 var chainConfigByGenesisHash = make(map[common.Hash]*chain.Config)
 
-func ChainConfigByGenesisHash(genesisHash common.Hash) *chain.Config {
-	return chainConfigByGenesisHash[genesisHash]
+// ChainConfigByGenesisHash returns the config of the built-in or registered chain with the given genesis hash.
+func ChainConfigByGenesisHash(genesisHash common.Hash) (*chain.Config, bool) {
+	config, ok := chainConfigByGenesisHash[genesisHash]
+	return config, ok
+}
+
+var chainNameByGenesisHash = make(map[common.Hash]string)
+
+// ChainNameByGenesisHash returns the name of the built-in or registered chain with the given genesis hash.
+func ChainNameByGenesisHash(genesisHash common.Hash) (string, bool) {
+	name, ok := chainNameByGenesisHash[genesisHash]
+	return name, ok
 }
 
 func NetworkIDByChainName(chain string) uint64 {
@@ -194,25 +222,84 @@ func hasChainPassedTerminalTD(chainConfig *chain.Config, currentTDProvider func(
 	return (currentTD != nil) && (terminalTD.Cmp(currentTD) <= 0)
 }
 
-func RegisterChain(name string, config *chain.Config, genesis *types.Genesis, genesisHash common.Hash, bootNodes []string, dnsNetwork string) {
+// RegisterBuiltinChain registers one of the networks shipped with erigon. It is meant to be called from init functions.
+func RegisterBuiltinChain(name string, config *chain.Config, genesis *types.Genesis, genesisHash common.Hash, bootNodes []string, dnsNetwork string) {
+	builtinChains[name] = struct{}{}
+	registerChain(name, config, genesis, genesisHash, bootNodes, dnsNetwork)
+}
+
+// RegisterChain makes a custom chain resolvable by name and genesis hash through the same lookups
+// as the built-in networks (GenesisBlockByChainName, GenesisHashByChainName, BootnodesByChainName, ...).
+// Names of built-in networks are rejected, as is registering the same name, chain id or genesis hash twice.
+// It must be called before the lookups are used, typically during startup, since they are not synchronized.
+func RegisterChain(name string, config *chain.Config, genesis *types.Genesis, genesisHash common.Hash, bootNodes []string) error {
+	if name == "" {
+		return errors.New("chain name is empty")
+	}
+	if isBuiltinChain(name) {
+		return fmt.Errorf("chain name %q collides with a built-in network", name)
+	}
+	if _, ok := chainConfigByName[name]; ok {
+		return fmt.Errorf("chain %q is already registered", name)
+	}
+	if config == nil || config.ChainID == nil {
+		return fmt.Errorf("chain %q has no chain id", name)
+	}
+	if other, ok := chainNameByChainID(config.ChainID); ok {
+		return fmt.Errorf("chain %q has the chain id %v of the already registered chain %q", name, config.ChainID, other)
+	}
+	if _, ok := chainConfigByGenesisHash[genesisHash]; ok {
+		return fmt.Errorf("chain %q has the genesis hash %x of an already registered chain", name, genesisHash)
+	}
+	if err := validateBootnodes(bootNodes); err != nil {
+		return fmt.Errorf("bootnodes of chain %q: %w", name, err)
+	}
+	registerChain(name, config, genesis, genesisHash, bootNodes, "")
+	return nil
+}
+
+// AllRegistered returns the names of all built-in and custom chains that were registered, sorted.
+func AllRegistered() []string {
+	return common.SortedKeys(genesisHashByChainName)
+}
+
+var builtinChains = map[string]struct{}{networkname.Dev: {}}
+
+// chainNameByChainID looks the chain id up in the configs of all registered chains, including dev which
+// isn't in NetworkNameByID.
+func chainNameByChainID(chainID *big.Int) (string, bool) {
+	for _, name := range common.SortedKeys(chainConfigByName) {
+		if config := chainConfigByName[name]; config != nil && config.ChainID != nil && config.ChainID.Cmp(chainID) == 0 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func isBuiltinChain(name string) bool {
+	_, ok := builtinChains[name]
+	return ok || slices.Contains(networkname.All, name)
+}
+
+func registerChain(name string, config *chain.Config, genesis *types.Genesis, genesisHash common.Hash, bootNodes []string, dnsNetwork string) {
 	NetworkNameByID[config.ChainID.Uint64()] = name
 	chainConfigByName[name] = config
 	chainConfigByGenesisHash[genesisHash] = config
+	chainNameByGenesisHash[genesisHash] = name
 	genesisHashByChainName[name] = &genesisHash
 	genesisBlockByChainName[name] = genesis
 	bootNodeURLsByChainName[name] = bootNodes
-	bootNodeURLsByGenesisHash[genesisHash] = bootNodes
 	knownDNSNetwork[genesisHash] = dnsNetwork
 }
 
 func init() {
 	chainConfigByName[networkname.Dev] = AllCliqueProtocolChanges
 
-	RegisterChain(networkname.Mainnet, MainnetChainConfig, MainnetGenesisBlock(), MainnetGenesisHash, MainnetBootnodes, dnsPrefix+"all.mainnet.ethdisco.net")
-	RegisterChain(networkname.Sepolia, SepoliaChainConfig, SepoliaGenesisBlock(), SepoliaGenesisHash, SepoliaBootnodes, dnsPrefix+"all.sepolia.ethdisco.net")
-	RegisterChain(networkname.Holesky, HoleskyChainConfig, HoleskyGenesisBlock(), HoleskyGenesisHash, HoleskyBootnodes, dnsPrefix+"all.holesky.ethdisco.net")
-	RegisterChain(networkname.Hoodi, HoodiChainConfig, HoodiGenesisBlock(), HoodiGenesisHash, HoodiBootnodes, dnsPrefix+"all.hoodi.ethdisco.net")
-	RegisterChain(networkname.Gnosis, GnosisChainConfig, GnosisGenesisBlock(), GnosisGenesisHash, GnosisBootnodes, "")
-	RegisterChain(networkname.Chiado, ChiadoChainConfig, ChiadoGenesisBlock(), ChiadoGenesisHash, ChiadoBootnodes, "")
-	RegisterChain(networkname.Test, chain.TestChainConfig, TestGenesisBlock(), TestGenesisHash, nil, "")
+	RegisterBuiltinChain(networkname.Mainnet, MainnetChainConfig, MainnetGenesisBlock(), MainnetGenesisHash, MainnetBootnodes, dnsPrefix+"all.mainnet.ethdisco.net")
+	RegisterBuiltinChain(networkname.Sepolia, SepoliaChainConfig, SepoliaGenesisBlock(), SepoliaGenesisHash, SepoliaBootnodes, dnsPrefix+"all.sepolia.ethdisco.net")
+	RegisterBuiltinChain(networkname.Holesky, HoleskyChainConfig, HoleskyGenesisBlock(), HoleskyGenesisHash, HoleskyBootnodes, dnsPrefix+"all.holesky.ethdisco.net")
+	RegisterBuiltinChain(networkname.Hoodi, HoodiChainConfig, HoodiGenesisBlock(), HoodiGenesisHash, HoodiBootnodes, dnsPrefix+"all.hoodi.ethdisco.net")
+	RegisterBuiltinChain(networkname.Gnosis, GnosisChainConfig, GnosisGenesisBlock(), GnosisGenesisHash, GnosisBootnodes, "")
+	RegisterBuiltinChain(networkname.Chiado, ChiadoChainConfig, ChiadoGenesisBlock(), ChiadoGenesisHash, ChiadoBootnodes, "")
+	RegisterBuiltinChain(networkname.Test, chain.TestChainConfig, TestGenesisBlock(), TestGenesisHash, nil, "")
 }
@@ -25,8 +25,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/types"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -133,3 +137,77 @@ func TestGnosisBlobSchedule(t *testing.T) {
 	assert.Equal(t, uint64(1), c.GetTargetBlobsPerBlock(time))
 	assert.Equal(t, uint64(1112826), c.GetBlobGasPriceUpdateFraction(time))
 }
+
+func TestRegisterChain(t *testing.T) {
+	toyHash := common.HexToHash("0x70796f7470796f7470796f7470796f7470796f7470796f7470796f7470796f74")
+	toyConfig := &chain.Config{ChainName: "toy", ChainID: big.NewInt(424242), HomesteadBlock: big.NewInt(0)}
+	toyGenesis := &types.Genesis{Config: toyConfig, GasLimit: 30_000_000, Difficulty: big.NewInt(1)}
+	t.Cleanup(func() {
+		delete(NetworkNameByID, toyConfig.ChainID.Uint64())
+		delete(chainConfigByName, "toy")
+		delete(chainConfigByGenesisHash, toyHash)
+		delete(chainNameByGenesisHash, toyHash)
+		delete(genesisHashByChainName, "toy")
+		delete(genesisBlockByChainName, "toy")
+		delete(bootNodeURLsByChainName, "toy")
+		delete(knownDNSNetwork, toyHash)
+	})
+
+	bootnodes := []string{ChiadoBootnodes[0]}
+	assert.ErrorContains(t, RegisterChain("toy", toyConfig, toyGenesis, toyHash, []string{"enode://0000@127.0.0.1:30303"}), "invalid enode URL")
+	assert.Nil(t, GenesisBlockByChainName("toy"), "rejected chain must not be registered")
+	require.NoError(t, RegisterChain("toy", toyConfig, toyGenesis, toyHash, bootnodes))
+
+	assert.Same(t, toyGenesis, GenesisBlockByChainName("toy"))
+	require.NotNil(t, GenesisHashByChainName("toy"))
+	assert.Equal(t, toyHash, *GenesisHashByChainName("toy"))
+	config, ok := ChainConfigByGenesisHash(toyHash)
+	assert.True(t, ok)
+	assert.Same(t, toyConfig, config)
+	name, ok := ChainNameByGenesisHash(toyHash)
+	assert.True(t, ok)
+	assert.Equal(t, "toy", name)
+	assert.Equal(t, uint64(424242), NetworkIDByChainName("toy"))
+	assert.Equal(t, bootnodes, BootnodesByChainName("toy"))
+	assert.Equal(t, bootnodes, BootnodeURLsByGenesisHash(toyHash))
+	assert.Contains(t, AllRegistered(), "toy")
+	assert.Contains(t, AllRegistered(), networkname.Mainnet)
+
+	assert.ErrorContains(t, RegisterChain("toy", toyConfig, toyGenesis, common.Hash{1}, nil), "already registered")
+	assert.ErrorContains(t, RegisterChain(networkname.Mainnet, toyConfig, toyGenesis, common.Hash{1}, nil), "built-in")
+	assert.ErrorContains(t, RegisterChain(networkname.Dev, toyConfig, toyGenesis, common.Hash{1}, nil), "built-in")
+	toy2Config := &chain.Config{ChainName: "toy2", ChainID: big.NewInt(424243)}
+	assert.ErrorContains(t, RegisterChain("toy2", toy2Config, toyGenesis, MainnetGenesisHash, nil), "genesis hash")
+	assert.ErrorContains(t, RegisterChain("toy2", toyConfig, toyGenesis, common.Hash{2}, nil), `chain id 424242 of the already registered chain "toy"`)
+	assert.ErrorContains(t, RegisterChain("toy2", &chain.Config{ChainID: MainnetChainConfig.ChainID}, toyGenesis, common.Hash{2}, nil), `chain "mainnet"`)
+	assert.ErrorContains(t, RegisterChain("toy2", &chain.Config{ChainID: AllCliqueProtocolChanges.ChainID}, toyGenesis, common.Hash{2}, nil), `chain "dev"`)
+	assert.Nil(t, GenesisBlockByChainName("toy2"))
+	config, _ = ChainConfigByGenesisHash(MainnetGenesisHash)
+	assert.Equal(t, MainnetChainConfig, config)
+}
+
+func TestSetBootnodes(t *testing.T) {
+	t.Cleanup(func() {
+		delete(bootNodeOverridesByChainName, networkname.Sepolia)
+		delete(bootNodeOverridesByChainName, "unregistered")
+	})
+
+	assert.Equal(t, SepoliaBootnodes, BootnodesByChainName(networkname.Sepolia))
+	assert.ErrorContains(t, SetBootnodes(networkname.Sepolia, []string{MainnetBootnodes[0], "enode://nope"}), "invalid enode URL")
+	assert.ErrorContains(t, SetBootnodes(networkname.Sepolia, []string{"127.0.0.1:30303"}), "invalid enode URL")
+	assert.Equal(t, SepoliaBootnodes, BootnodesByChainName(networkname.Sepolia), "rejected override must not apply")
+
+	override := []string{MainnetBootnodes[0]}
+	require.NoError(t, SetBootnodes(networkname.Sepolia, override))
+	assert.Equal(t, override, BootnodesByChainName(networkname.Sepolia))
+	assert.Equal(t, override, BootnodeURLsByGenesisHash(SepoliaGenesisHash))
+	assert.Equal(t, MainnetBootnodes, BootnodesByChainName(networkname.Mainnet))
+
+	// an empty override disables the embedded bootnodes
+	require.NoError(t, SetBootnodes(networkname.Sepolia, []string{}))
+	assert.Empty(t, BootnodesByChainName(networkname.Sepolia))
+
+	// overrides can be set before the chain is registered
+	require.NoError(t, SetBootnodes("unregistered", override))
+	assert.Equal(t, override, BootnodesByChainName("unregistered"))
+}
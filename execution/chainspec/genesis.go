@@ -146,6 +146,12 @@ func DeveloperGenesisBlock(period uint64, faucet common.Address) *types.Genesis
 	}
 }
 
+// SystemContractsPrealloc returns the EIP-4788, EIP-2935, EIP-7002 and EIP-7251 system contracts
+// with the code and initial storage they were deployed with on mainnet.
+func SystemContractsPrealloc() types.GenesisAlloc {
+	return ReadPrealloc(allocs, "allocs/system_contracts.json")
+}
+
 var genesisBlockByChainName = make(map[string]*types.Genesis)
 
 func GenesisBlockByChainName(chain string) *types.Genesis {
@@ -0,0 +1,78 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package chainspec_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/execution/chainspec"
+	"github.com/erigontech/erigon/p2p/enode"
+	polychain "github.com/erigontech/erigon/polygon/chain"
+)
+
+func TestChainByGenesisHash(t *testing.T) {
+	genesisHashes := map[string]common.Hash{
+		networkname.Mainnet:    chainspec.MainnetGenesisHash,
+		networkname.Holesky:    chainspec.HoleskyGenesisHash,
+		networkname.Sepolia:    chainspec.SepoliaGenesisHash,
+		networkname.Hoodi:      chainspec.HoodiGenesisHash,
+		networkname.Amoy:       polychain.AmoyGenesisHash,
+		networkname.BorMainnet: polychain.BorMainnetGenesisHash,
+		networkname.BorDevnet:  polychain.BorDevnetGenesisHash,
+		networkname.Gnosis:     chainspec.GnosisGenesisHash,
+		networkname.Chiado:     chainspec.ChiadoGenesisHash,
+		networkname.Test:       chainspec.TestGenesisHash,
+	}
+	require.Len(t, genesisHashes, len(networkname.All), "every embedded network must be covered")
+
+	for _, network := range networkname.All {
+		t.Run(network, func(t *testing.T) {
+			hash, ok := genesisHashes[network]
+			require.True(t, ok)
+
+			name, ok := chainspec.ChainNameByGenesisHash(hash)
+			require.True(t, ok)
+			require.Equal(t, network, name)
+
+			config, ok := chainspec.ChainConfigByGenesisHash(hash)
+			require.True(t, ok)
+			require.Same(t, chainspec.ChainConfigByChainName(network), config)
+			require.Equal(t, hash, *chainspec.GenesisHashByChainName(network))
+		})
+	}
+
+	_, ok := chainspec.ChainNameByGenesisHash(common.Hash{})
+	require.False(t, ok)
+	_, ok = chainspec.ChainConfigByGenesisHash(common.Hash{})
+	require.False(t, ok)
+}
+
+func TestEmbeddedBootnodes(t *testing.T) {
+	for _, network := range networkname.All {
+		for _, url := range chainspec.BootnodesByChainName(network) {
+			_, err := enode.ParseV4(url)
+			require.NoError(t, err, network)
+		}
+		hash := chainspec.GenesisHashByChainName(network)
+		require.Equal(t, chainspec.BootnodesByChainName(network), chainspec.BootnodeURLsByGenesisHash(*hash), network)
+	}
+	require.Equal(t, polychain.BorMainnetBootnodes, chainspec.BootnodesByChainName(networkname.BorMainnet))
+}
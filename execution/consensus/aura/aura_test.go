@@ -17,6 +17,7 @@
 package aura_test
 
 import (
+	"context"
 	"math/big"
 	"strings"
 	"testing"
@@ -44,7 +45,7 @@ import (
 func TestEmptyBlock(t *testing.T) {
 	require := require.New(t)
 	genesis := chainspec.GnosisGenesisBlock()
-	genesisBlock, _, err := core.GenesisToBlock(genesis, datadir.New(t.TempDir()), log.Root())
+	genesisBlock, _, err := core.GenesisToBlock(context.Background(), genesis, datadir.New(t.TempDir()), log.Root())
 	require.NoError(err)
 
 	genesis.Config.TerminalTotalDifficultyPassed = false
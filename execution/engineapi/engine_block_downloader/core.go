@@ -155,3 +155,9 @@ func (e *EngineBlockDownloader) StartDownloading(requestId int, hashToDownload c
 func (e *EngineBlockDownloader) Status() headerdownload.SyncStatus {
 	return headerdownload.SyncStatus(e.status.Load().(int))
 }
+
+// AddPayloadBody offers the body of a block received through the engine API to the body download, so that it isn't
+// requested from the peers if the block is backfilled later, e.g. when newPayload arrives while syncing.
+func (e *EngineBlockDownloader) AddPayloadBody(block *types.Block) {
+	e.bd.AddToPrefetch(block.Header(), block.RawBody())
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package engine_block_downloader_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-db/rawdb"
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/chain/params"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/execution/engineapi/engine_block_downloader"
+	"github.com/erigontech/erigon/execution/stagedsync/stages"
+	"github.com/erigontech/erigon/execution/stages/bodydownload"
+	"github.com/erigontech/erigon/execution/stages/mock"
+)
+
+func TestPayloadBodiesAreNotRequested(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	m := mock.MockWithGenesis(t, &types.Genesis{
+		Config: chain.TestChainConfig,
+		Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(1000000)}},
+	}, key, false)
+	signer := types.LatestSignerForChainID(nil)
+	chainPack, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3, func(i int, b *core.BlockGen) {
+		txn, err := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{1}, uint256.NewInt(1), params.TxGas, nil, nil), *signer, key)
+		require.NoError(t, err)
+		b.AddTx(txn)
+	})
+	require.NoError(t, err)
+
+	// the headers are downloaded, the bodies aren't
+	tx, err := m.DB.BeginRw(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	for _, block := range chainPack.Blocks {
+		require.NoError(t, rawdb.WriteHeader(tx, block.Header()))
+		require.NoError(t, rawdb.WriteCanonicalHash(tx, block.Hash(), block.NumberU64()))
+	}
+	require.NoError(t, stages.SaveStageProgress(tx, stages.Headers, chainPack.TopBlock.NumberU64()))
+
+	noPropagation := func(context.Context, *types.Header, *types.RawBody, *big.Int) {}
+	bd := bodydownload.NewBodyDownload(m.Engine, 128, 1024*1024, m.BlockReader, m.Log)
+	downloader := engine_block_downloader.NewEngineBlockDownloader(m.Ctx, m.Log, nil, nil, bd, noPropagation, nil,
+		m.BlockReader, m.DB, m.ChainConfig, m.Dirs.Tmp, ethconfig.Defaults.Sync)
+	// the first two blocks came as payloads
+	downloader.AddPayloadBody(chainPack.Blocks[0])
+	downloader.AddPayloadBody(chainPack.Blocks[1])
+
+	require.NoError(t, bd.UpdateFromDb(tx))
+	req, err := bd.RequestMoreBodies(tx, m.BlockReader, 0, noPropagation)
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	require.Equal(t, []uint64{3}, req.BlockNums)
+	require.Equal(t, []common.Hash{chainPack.Blocks[2].Hash()}, req.Hashes)
+	for _, block := range chainPack.Blocks[:2] {
+		require.Equal(t, block.RawBody(), bd.GetBodyFromCache(block.NumberU64(), false))
+	}
+}
@@ -794,6 +794,13 @@ Loop:
 			return ctx.Err()
 		default:
 		}
+
+		// the time budget of the loop iteration is spent: stop at this block, its root checked and the progress saved
+		// below, for the next iteration to resume from it
+		if !parallel && !inMemExec && blockNum < maxBlockNum && stageProgress > execStage.BlockNumber && execStage.Yield() {
+			logger.Info(fmt.Sprintf("[%s] time budget spent, yielding", execStage.LogPrefix()), "block", blockNum, "to", maxBlockNum)
+			break Loop
+		}
 	}
 
 	//log.Info("Executed", "blocks", inputBlockNum.Load(), "txs", outputTxNum.Load(), "repeats", mxExecRepeats.GetValueUint64())
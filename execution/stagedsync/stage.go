@@ -76,6 +76,15 @@ func (s *StageState) LogPrefix() string {
 	return s.state.LogPrefix()
 }
 
+// Yield tells a long-running stage, at a batch boundary, that the time budget of the run is spent: the stage saves
+// its progress and returns, the next run resumes it. See Sync.RunWithBudget.
+func (s *StageState) Yield() bool {
+	if s == nil || s.state == nil {
+		return false
+	}
+	return s.state.yield()
+}
+
 func (s *StageState) SyncMode() stages.Mode {
 	if s == nil {
 		return stages.ModeUnknown
@@ -411,6 +411,10 @@ func unwindExecutionStage(u *UnwindState, s *StageState, txc wrap.TxContainer, c
 		}
 		accumulator.StartChange(header, txs, true)
 	}
+	if cfg.notifications != nil && cfg.notifications.RecentLogs != nil {
+		// logs subscribers have to be told which logs the unwind removes
+		cfg.notifications.RecentLogs.Unwind(u.UnwindPoint)
+	}
 
 	return unwindExec3(u, s, txc, ctx, cfg, accumulator, logger)
 }
@@ -0,0 +1,85 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stages
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/erigontech/erigon-lib/kv"
+)
+
+// StageStatus is the state of a sync stage, as returned by SyncStatus.
+type StageStatus struct {
+	Stage         SyncStage
+	Progress      uint64
+	PruneProgress uint64
+	LastRun       time.Time     // end of the last forward run of the stage, zero if it never ran
+	LastRunTook   time.Duration // duration of the last forward run of the stage
+	LastErr       string        // error of the last forward run of the stage, empty if it succeeded
+}
+
+// SyncStatus returns the status of all the stages, in the order of AllStages.
+func SyncStatus(tx kv.Tx) ([]StageStatus, error) {
+	status := make([]StageStatus, 0, len(AllStages))
+	for _, stage := range AllStages {
+		s := StageStatus{Stage: stage}
+		var err error
+		if s.Progress, err = GetStageProgress(tx, stage); err != nil {
+			return nil, err
+		}
+		if s.PruneProgress, err = GetStagePruneProgress(tx, stage); err != nil {
+			return nil, err
+		}
+		if s.LastRun, s.LastRunTook, s.LastErr, err = GetStageRun(tx, stage); err != nil {
+			return nil, err
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+// SaveStageRun records the last forward run of the stage, which finished at the given time after took, runErr is nil
+// if it succeeded.
+func SaveStageRun(db kv.Putter, stage SyncStage, finishedAt time.Time, took time.Duration, runErr error) error {
+	v := make([]byte, 16, 16+64)
+	binary.BigEndian.PutUint64(v, uint64(took))
+	binary.BigEndian.PutUint64(v[8:], uint64(finishedAt.UnixNano()))
+	if runErr != nil {
+		v = append(v, runErr.Error()...)
+	}
+	return db.Put(kv.SyncStageRun, []byte(stage), v)
+}
+
+// GetStageRun retrieves the last forward run of the stage recorded by SaveStageRun, finishedAt is zero if there is
+// none.
+func GetStageRun(db kv.Getter, stage SyncStage) (finishedAt time.Time, took time.Duration, runErr string, err error) {
+	v, err := db.GetOne(kv.SyncStageRun, []byte(stage))
+	if err != nil {
+		return time.Time{}, 0, "", err
+	}
+	if len(v) == 0 {
+		return time.Time{}, 0, "", nil
+	}
+	if len(v) < 16 {
+		return time.Time{}, 0, "", fmt.Errorf("stage run must be at least 16 bytes, got %d", len(v))
+	}
+	took = time.Duration(binary.BigEndian.Uint64(v))
+	finishedAt = time.Unix(0, int64(binary.BigEndian.Uint64(v[8:])))
+	return finishedAt, took, string(v[16:]), nil
+}
@@ -49,6 +49,8 @@ type Sync struct {
 	stagesIdsList []string
 	mode          stages.Mode
 	metricsCache  metricsCache
+	budgetEnd     time.Time // end of the time budget of the run, zero if none, see RunWithBudget
+	yielded       bool      // a stage yielded in the last run, its time budget spent
 }
 
 type Timing struct {
@@ -56,6 +58,8 @@ type Timing struct {
 	isPrune  bool
 	stage    stages.SyncStage
 	took     time.Duration
+	finished time.Time
+	err      error // error of the forward run of the stage
 }
 
 func (s *Sync) Len() int {
@@ -363,8 +367,20 @@ func (s *Sync) RunNoInterrupt(db kv.RwDB, txc wrap.TxContainer) error {
 }
 
 func (s *Sync) Run(db kv.RwDB, txc wrap.TxContainer, initialCycle, firstCycle bool) (bool, error) {
+	return s.RunWithBudget(db, txc, initialCycle, firstCycle, 0)
+}
+
+// RunWithBudget is Run with a time budget, 0 meaning none. Once it's spent, the long-running stages save their
+// progress at their next batch boundary and return, see StageState.Yield: the run goes on with the next stages and
+// hasMore tells to run again, resuming the stages which yielded. Only the commit granularity changes.
+func (s *Sync) RunWithBudget(db kv.RwDB, txc wrap.TxContainer, initialCycle, firstCycle bool, budget time.Duration) (bool, error) {
 	s.prevUnwindPoint = nil
 	s.timings = s.timings[:0]
+	s.yielded = false
+	if budget > 0 {
+		s.budgetEnd = time.Now().Add(budget)
+		defer func() { s.budgetEnd = time.Time{} }()
+	}
 
 	hasMore := false
 	for !s.IsDone() {
@@ -449,7 +465,20 @@ func (s *Sync) Run(db kv.RwDB, txc wrap.TxContainer, initialCycle, firstCycle bo
 	}
 
 	s.currentStage = 0
-	return hasMore, nil
+	return hasMore || s.yielded, nil
+}
+
+// Yielded tells if a stage yielded in the last run, see RunWithBudget.
+func (s *Sync) Yielded() bool {
+	return s.yielded
+}
+
+func (s *Sync) yield() bool {
+	if s.budgetEnd.IsZero() || time.Now().Before(s.budgetEnd) {
+		return false
+	}
+	s.yielded = true
+	return true
 }
 
 // Run pruning for stages as per the defined pruning order, if enabled for that stage
@@ -492,6 +521,19 @@ func (s *Sync) PrintTimings() []interface{} {
 	return logCtx
 }
 
+// SaveStageRuns records the forward runs of the stages of the last Run, or of its failed stage, see stages.SyncStatus.
+func (s *Sync) SaveStageRuns(tx kv.RwTx) error {
+	for _, t := range s.timings {
+		if t.isUnwind || t.isPrune {
+			continue
+		}
+		if err := stages.SaveStageRun(tx, t.stage, t.finished, t.took, t.err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Sync) runStage(stage *Stage, db kv.RwDB, txc wrap.TxContainer, initialCycle, firstCycle bool, badBlockUnwind bool) (err error) {
 	start := time.Now()
 	s.logger.Debug(fmt.Sprintf("[%s] Starting Stage run", s.LogPrefix()))
@@ -503,6 +545,7 @@ func (s *Sync) runStage(stage *Stage, db kv.RwDB, txc wrap.TxContainer, initialC
 	if err = stage.Forward(badBlockUnwind, stageState, s, txc, s.logger); err != nil {
 		wrappedError := fmt.Errorf("[%s] %w", s.LogPrefix(), err)
 		s.logger.Debug("Error while executing stage", "err", wrappedError)
+		s.timings = append(s.timings, Timing{stage: stage.ID, took: time.Since(start), finished: time.Now(), err: err})
 		return wrappedError
 	}
 
@@ -513,7 +556,7 @@ func (s *Sync) runStage(stage *Stage, db kv.RwDB, txc wrap.TxContainer, initialC
 	} else {
 		s.logger.Debug(fmt.Sprintf("[%s] DONE", logPrefix), "in", took)
 	}
-	s.timings = append(s.timings, Timing{stage: stage.ID, took: took})
+	s.timings = append(s.timings, Timing{stage: stage.ID, took: took, finished: time.Now()})
 	s.metricsCache.stageRunDurationSummary(stage.ID).Observe(took.Seconds())
 	return nil
 }
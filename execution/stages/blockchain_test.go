@@ -447,6 +447,78 @@ func testBadHashes(t *testing.T) {
 	}
 }
 
+// Tests that GenerateChainWithFork generates the same branches again, and that inserting the second after the first
+// reorgs 3 blocks.
+func TestGenerateChainWithForkReorg(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	m := mock.Mock(t)
+	gen := func(i int, b *core.BlockGen) { b.SetCoinbase(common.Address{1}) }
+	a, b, err := core.GenerateChainWithFork(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, 3, 3, gen, gen)
+	require.NoError(err)
+	require.Equal(5, a.Length())
+	require.Equal(5, b.Length())
+	for i := 0; i < 2; i++ {
+		require.Equal(a.Blocks[i].Hash(), b.Blocks[i].Hash(), "common block %d", i)
+	}
+	for i := 2; i < 5; i++ {
+		require.NotEqual(a.Blocks[i].Hash(), b.Blocks[i].Hash(), "forked block %d", i)
+	}
+	a2, b2, err := core.GenerateChainWithFork(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, 3, 3, gen, gen)
+	require.NoError(err)
+	require.Equal(a.TopBlock.Hash(), a2.TopBlock.Hash())
+	require.Equal(b.TopBlock.Hash(), b2.TopBlock.Hash())
+
+	require.NoError(m.InsertBranch(a))
+	require.NoError(m.InsertBranch(b))
+	require.NoError(m.DB.View(m.Ctx, func(tx kv.Tx) error {
+		require.Equal(b.TopBlock.Hash(), current(m, tx).Hash())
+		for _, block := range b.Blocks {
+			hash, err := rawdb.ReadCanonicalHash(tx, block.NumberU64())
+			require.NoError(err)
+			require.Equal(block.Hash(), hash, "block %d", block.NumberU64())
+		}
+		return nil
+	}))
+
+	_, _, err = core.GenerateChainWithFork(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, 3, 1, gen, gen)
+	require.ErrorContains(err, "higher total difficulty")
+}
+
+func TestCanonicalHashCacheReorg(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	m := mock.Mock(t)
+	gen := func(i int, b *core.BlockGen) { b.SetCoinbase(common.Address{1}) }
+	a, b, err := core.GenerateChainWithFork(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, 3, 3, gen, gen)
+	require.NoError(err)
+
+	requireCanonical := func(tx kv.Tx, branch *core.ChainPack) {
+		for _, block := range branch.Blocks {
+			hash, ok, err := m.BlockReader.CanonicalHash(m.Ctx, tx, block.NumberU64())
+			require.NoError(err)
+			require.True(ok)
+			require.Equal(block.Hash(), hash, "block %d", block.NumberU64())
+			header, err := m.BlockReader.HeaderByNumber(m.Ctx, tx, block.NumberU64())
+			require.NoError(err)
+			require.Equal(block.Hash(), header.Hash(), "block %d", block.NumberU64())
+		}
+	}
+	require.NoError(m.InsertBranch(a))
+	for i := 0; i < 2; i++ { // the second time from the cache
+		require.NoError(m.DB.View(m.Ctx, func(tx kv.Tx) error { requireCanonical(tx, a); return nil }))
+	}
+
+	beforeReorg, err := m.DB.BeginRo(m.Ctx)
+	require.NoError(err)
+	defer beforeReorg.Rollback()
+	require.NoError(m.InsertBranch(b))
+	require.NoError(m.DB.View(m.Ctx, func(tx kv.Tx) error { requireCanonical(tx, b); return nil }))
+	// a transaction of the view before the reorg still sees a, and doesn't put it back in the cache
+	requireCanonical(beforeReorg, a)
+	require.NoError(m.DB.View(m.Ctx, func(tx kv.Tx) error { requireCanonical(tx, b); return nil }))
+}
+
 // Tests that chain reorganisations handle transaction removals and reinsertions.
 func TestChainTxReorgs(t *testing.T) {
 	if testing.Short() {
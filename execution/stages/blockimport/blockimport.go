@@ -0,0 +1,194 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package blockimport imports the blocks of era1 or export files through the header and body downloaders, as if they
+// came from a peer, so that they're validated as the blocks of the peers are.
+package blockimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/execution/stagedsync/stages"
+	"github.com/erigontech/erigon/execution/stages/bodydownload"
+	"github.com/erigontech/erigon/execution/stages/headerdownload"
+	"github.com/erigontech/erigon/turbo/services"
+)
+
+// DefaultBatchSize is the number of blocks delivered before each cycle of the stages. It's at most the block buffer
+// of the body downloader, which doesn't match the bodies beyond it.
+const DefaultBatchSize = 128
+
+const logInterval = 20 * time.Second
+
+// importPeerID is the peer the blocks come from, no peer of the sentries has it.
+var importPeerID [64]byte
+
+// Target is where the blocks are imported: the downloaders, which the blocks are delivered to, and the cycle of the
+// stages, which inserts them.
+type Target struct {
+	DB          kv.RoDB
+	BlockReader services.FullBlockReader
+	Hd          *headerdownload.HeaderDownload
+	Bd          *bodydownload.BodyDownload
+	Cycle       func(ctx context.Context) error
+	BatchSize   int // DefaultBatchSize if 0
+}
+
+// Import imports the blocks of source, the ones imported already skipped, so that an interrupted import resumes where
+// it stopped. Only the proof-of-work blocks can be imported this way, the downloaders don't take the others.
+func Import(ctx context.Context, target Target, source BlockSource, logger log.Logger) (imported uint64, err error) {
+	batchSize := target.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	logEvery := time.NewTicker(logInterval)
+	defer logEvery.Stop()
+	started, lastImported := time.Now(), uint64(0)
+
+	batch := make([]*types.Block, 0, batchSize)
+	for done := false; !done; {
+		if err := ctx.Err(); err != nil {
+			return imported, err
+		}
+		batch = batch[:0]
+		for len(batch) < batchSize {
+			block, err := source.Next()
+			if errors.Is(err, io.EOF) {
+				done = true
+				break
+			}
+			if err != nil {
+				return imported, fmt.Errorf("after %d blocks: %w", imported, err)
+			}
+			if block.NumberU64() == 0 {
+				continue
+			}
+			if block.Difficulty().Sign() == 0 {
+				return imported, fmt.Errorf("block %d: proof-of-stake blocks can't be imported through the downloaders", block.NumberU64())
+			}
+			have, err := hasBlock(ctx, target, block)
+			if err != nil {
+				return imported, err
+			}
+			if !have {
+				batch = append(batch, block)
+			}
+		}
+		if len(batch) == 0 {
+			continue
+		}
+		if err := importBatch(ctx, target, batch); err != nil {
+			return imported, err
+		}
+		imported += uint64(len(batch))
+
+		select {
+		case <-logEvery.C:
+			speed := float64(imported-lastImported) / logInterval.Seconds()
+			logger.Info("[import] Importing blocks", "number", batch[len(batch)-1].NumberU64(), "imported", imported,
+				"blk/s", fmt.Sprintf("%.1f", speed))
+			lastImported = imported
+		default:
+		}
+	}
+	logger.Info("[import] Imported blocks", "imported", imported, "took", time.Since(started))
+	return imported, nil
+}
+
+// hasBlock tells whether the block was imported already: it's canonical, with its body. A different block at its
+// height is an error, the source doesn't belong to the chain.
+func hasBlock(ctx context.Context, target Target, block *types.Block) (bool, error) {
+	var have bool
+	err := target.DB.View(ctx, func(tx kv.Tx) error {
+		bodiesProgress, err := stages.GetStageProgress(tx, stages.Bodies)
+		if err != nil {
+			return err
+		}
+		if block.NumberU64() > bodiesProgress {
+			return nil
+		}
+		hash, ok, err := target.BlockReader.CanonicalHash(ctx, tx, block.NumberU64())
+		if err != nil {
+			return err
+		}
+		if ok && hash != block.Hash() {
+			return fmt.Errorf("block %d: %x in the file, %x in the chain", block.NumberU64(), block.Hash(), hash)
+		}
+		have = ok
+		return nil
+	})
+	return have, err
+}
+
+// importBatch delivers the headers and the bodies of the blocks, then runs a cycle, after which the last block is
+// expected canonical, with its body.
+func importBatch(ctx context.Context, target Target, batch []*types.Block) error {
+	segments := make([]headerdownload.ChainSegmentHeader, len(batch))
+	txs := make([][][]byte, len(batch))
+	uncles := make([][]*types.Header, len(batch))
+	withdrawals := make([]types.Withdrawals, len(batch))
+	var size uint64 // of the bodies, roughly
+	for i, block := range batch {
+		header := block.Header()
+		headerRaw, err := rlp.EncodeToBytes(header)
+		if err != nil {
+			return fmt.Errorf("encode header %d: %w", header.Number.Uint64(), err)
+		}
+		segments[i] = headerdownload.ChainSegmentHeader{
+			HeaderRaw: headerRaw,
+			Header:    header,
+			Hash:      types.RawRlpHash(headerRaw),
+			Number:    header.Number.Uint64(),
+		}
+		body := block.RawBody()
+		txs[i], uncles[i], withdrawals[i] = body.Transactions, body.Uncles, body.Withdrawals
+		for _, txn := range txs[i] {
+			size += uint64(len(txn))
+		}
+	}
+	target.Hd.ProcessHeaders(segments, false /* newBlock */, importPeerID)
+	target.Bd.DeliverBodies(txs, uncles, withdrawals, size, importPeerID)
+	if err := target.Cycle(ctx); err != nil {
+		return err
+	}
+
+	last := batch[len(batch)-1]
+	var canonical common.Hash
+	var bodiesProgress uint64
+	if err := target.DB.View(ctx, func(tx kv.Tx) (err error) {
+		if canonical, _, err = target.BlockReader.CanonicalHash(ctx, tx, last.NumberU64()); err != nil {
+			return err
+		}
+		bodiesProgress, err = stages.GetStageProgress(tx, stages.Bodies)
+		return err
+	}); err != nil {
+		return err
+	}
+	if canonical != last.Hash() || bodiesProgress < last.NumberU64() {
+		return fmt.Errorf("block %d (%x) wasn't imported: %x is canonical, bodies up to %d", last.NumberU64(), last.Hash(),
+			canonical, bodiesProgress)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package blockimport
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain/params"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/execution/stages/mock"
+)
+
+// The blocks are more than two batches.
+const importedBlocks = 2*DefaultBatchSize + 40
+
+func TestImportExported(t *testing.T) {
+	exported, blocks := exportedChain(t)
+
+	m := mock.Mock(t)
+	imported, err := Import(context.Background(), targetOf(m), NewRLPSource(bytes.NewReader(exported)), log.New())
+	require.NoError(t, err)
+	require.Equal(t, uint64(importedBlocks), imported)
+	requireCanonical(t, m, blocks)
+
+	// the blocks imported already are skipped
+	imported, err = Import(context.Background(), targetOf(m), NewRLPSource(bytes.NewReader(exported)), log.New())
+	require.NoError(t, err)
+	require.Zero(t, imported)
+	requireCanonical(t, m, blocks)
+}
+
+func TestImportEra1(t *testing.T) {
+	_, blocks := exportedChain(t)
+
+	m := mock.Mock(t)
+	imported, err := Import(context.Background(), targetOf(m), NewEra1Source(bytes.NewReader(era1Of(t, blocks))), log.New())
+	require.NoError(t, err)
+	require.Equal(t, uint64(importedBlocks), imported)
+	requireCanonical(t, m, blocks)
+}
+
+func TestImportResumes(t *testing.T) {
+	exported, blocks := exportedChain(t)
+
+	// the first batch was imported before the interruption
+	m := mock.Mock(t)
+	partial := blocks[:DefaultBatchSize+1]
+	imported, err := Import(context.Background(), targetOf(m), &sliceSource{blocks: partial}, log.New())
+	require.NoError(t, err)
+	require.Equal(t, uint64(DefaultBatchSize), imported)
+
+	imported, err = Import(context.Background(), targetOf(m), NewRLPSource(bytes.NewReader(exported)), log.New())
+	require.NoError(t, err)
+	require.Equal(t, uint64(importedBlocks-DefaultBatchSize), imported)
+	requireCanonical(t, m, blocks)
+}
+
+// exportedChain inserts a chain in a mock, then exports it: the RLP of its canonical blocks, with the genesis.
+func exportedChain(t *testing.T) ([]byte, []*types.Block) {
+	m := mock.Mock(t)
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, importedBlocks, func(i int, gen *core.BlockGen) {
+		if i%3 == 0 { // some of the blocks are empty
+			return
+		}
+		txn, err := types.SignTx(types.NewTransaction(gen.TxNonce(m.Address), common.Address{1}, uint256.NewInt(1000), params.TxGas, nil, nil), *signer, m.Key)
+		require.NoError(t, err)
+		gen.AddTx(txn)
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	var exported bytes.Buffer
+	blocks := make([]*types.Block, 0, importedBlocks+1)
+	require.NoError(t, m.DB.View(context.Background(), func(tx kv.Tx) error {
+		for number := uint64(0); number <= importedBlocks; number++ {
+			block, err := m.BlockReader.BlockByNumber(context.Background(), tx, number)
+			require.NoError(t, err)
+			require.NotNil(t, block)
+			require.NoError(t, rlp.Encode(&exported, block))
+			blocks = append(blocks, block)
+		}
+		return nil
+	}))
+	return exported.Bytes(), blocks
+}
+
+func targetOf(m *mock.MockSentry) Target {
+	return Target{
+		DB:          m.DB,
+		BlockReader: m.BlockReader,
+		Hd:          m.MultiClient().Hd,
+		Bd:          m.MultiClient().Bd,
+		Cycle:       func(context.Context) error { return m.RunCycle() },
+	}
+}
+
+func requireCanonical(t *testing.T, m *mock.MockSentry, blocks []*types.Block) {
+	require.NoError(t, m.DB.View(context.Background(), func(tx kv.Tx) error {
+		for _, block := range blocks {
+			hash, ok, err := m.BlockReader.CanonicalHash(context.Background(), tx, block.NumberU64())
+			require.NoError(t, err)
+			require.True(t, ok, block.NumberU64())
+			require.Equal(t, block.Hash(), hash, block.NumberU64())
+			body, err := m.BlockReader.BodyWithTransactions(context.Background(), tx, hash, block.NumberU64())
+			require.NoError(t, err)
+			require.Len(t, body.Transactions, len(block.Transactions()), block.NumberU64())
+		}
+		return nil
+	}))
+}
+
+type sliceSource struct {
+	blocks []*types.Block
+}
+
+func (s *sliceSource) Next() (*types.Block, error) {
+	if len(s.blocks) == 0 {
+		return nil, io.EOF
+	}
+	block := s.blocks[0]
+	s.blocks = s.blocks[1:]
+	return block, nil
+}
+
+// era1Of writes the blocks as an era1 file, with empty receipts and without the index, which the source doesn't read.
+func era1Of(t *testing.T, blocks []*types.Block) []byte {
+	var file bytes.Buffer
+	entry := func(typ uint16, data []byte) {
+		var header [e2HeaderSize]byte
+		binary.LittleEndian.PutUint16(header[0:2], typ)
+		binary.LittleEndian.PutUint32(header[2:6], uint32(len(data)))
+		file.Write(header[:])
+		file.Write(data)
+	}
+	compressed := func(val any) []byte {
+		var data bytes.Buffer
+		w := snappy.NewBufferedWriter(&data)
+		require.NoError(t, rlp.Encode(w, val))
+		require.NoError(t, w.Close())
+		return data.Bytes()
+	}
+	entry(e2Version, nil)
+	for _, block := range blocks {
+		entry(e2CompressedHeader, compressed(block.Header()))
+		entry(e2CompressedBody, compressed(block.Body()))
+		entry(e2CompressedReceipts, compressed(types.Receipts{}))
+		entry(e2TotalDifficulty, make([]byte, 32))
+	}
+	entry(e2Accumulator, make([]byte, 32))
+	return file.Bytes()
+}
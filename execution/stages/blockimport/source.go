@@ -0,0 +1,154 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package blockimport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types"
+)
+
+// BlockSource gives the blocks to import, in their order, then io.EOF.
+type BlockSource interface {
+	Next() (*types.Block, error)
+}
+
+type rlpSource struct {
+	stream *rlp.Stream
+}
+
+// NewRLPSource reads the blocks of the export format: RLP-encoded blocks, one after the other.
+func NewRLPSource(r io.Reader) BlockSource {
+	return &rlpSource{stream: rlp.NewStream(r, 0)}
+}
+
+func (s *rlpSource) Next() (*types.Block, error) {
+	var b types.Block
+	if err := s.stream.Decode(&b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// The types of the entries of the e2store files, of which era1 is one.
+const (
+	e2Version            = 0x3265
+	e2CompressedHeader   = 0x03
+	e2CompressedBody     = 0x04
+	e2CompressedReceipts = 0x05
+	e2TotalDifficulty    = 0x06
+	e2Accumulator        = 0x07
+	e2BlockIndex         = 0x3266
+
+	e2HeaderSize   = 8         // type, length and reserved
+	e2MaxEntrySize = 256 << 20 // of the entries we read, far above the size of a block
+)
+
+type era1Source struct {
+	r       *bufio.Reader
+	started bool // the version was read
+}
+
+// NewEra1Source reads the blocks of an era1 file: a version, then the tuples of a header, a body, receipts and a total
+// difficulty of each block, compressed with snappy, then an accumulator and an index, which aren't needed to import.
+func NewEra1Source(r io.Reader) BlockSource {
+	return &era1Source{r: bufio.NewReader(r)}
+}
+
+// entry reads the next entry, io.EOF at the end of the file.
+func (s *era1Source) entry() (typ uint16, data []byte, err error) {
+	var header [e2HeaderSize]byte
+	if _, err := io.ReadFull(s.r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil, fmt.Errorf("truncated entry header: %w", err)
+		}
+		return 0, nil, err
+	}
+	typ = binary.LittleEndian.Uint16(header[0:2])
+	length := binary.LittleEndian.Uint32(header[2:6])
+	if reserved := binary.LittleEndian.Uint16(header[6:8]); reserved != 0 {
+		return 0, nil, fmt.Errorf("entry %#x: reserved bytes %#x", typ, reserved)
+	}
+	if length > e2MaxEntrySize {
+		return 0, nil, fmt.Errorf("entry %#x: too large: %d", typ, length)
+	}
+	data = make([]byte, length)
+	if _, err := io.ReadFull(s.r, data); err != nil {
+		return 0, nil, fmt.Errorf("entry %#x: %w", typ, io.ErrUnexpectedEOF)
+	}
+	return typ, data, nil
+}
+
+func (s *era1Source) Next() (*types.Block, error) {
+	if !s.started {
+		typ, _, err := s.entry()
+		if err != nil {
+			return nil, err
+		}
+		if typ != e2Version {
+			return nil, fmt.Errorf("not an era1 file: first entry %#x", typ)
+		}
+		s.started = true
+	}
+	var header *types.Header
+	for {
+		typ, data, err := s.entry()
+		if err != nil {
+			if errors.Is(err, io.EOF) && header != nil {
+				return nil, fmt.Errorf("block %d without body: %w", header.Number.Uint64(), io.ErrUnexpectedEOF)
+			}
+			return nil, err
+		}
+		switch typ {
+		case e2CompressedHeader:
+			header = new(types.Header)
+			if err := decodeSnappy(data, header); err != nil {
+				return nil, fmt.Errorf("header: %w", err)
+			}
+		case e2CompressedBody:
+			if header == nil {
+				return nil, errors.New("body without header")
+			}
+			var body types.Body
+			if err := decodeSnappy(data, &body); err != nil {
+				return nil, fmt.Errorf("body of block %d: %w", header.Number.Uint64(), err)
+			}
+			return types.NewBlockFromNetwork(header, &body), nil
+		case e2CompressedReceipts, e2TotalDifficulty:
+		case e2Accumulator, e2BlockIndex: // the blocks are over
+			return nil, io.EOF
+		default:
+			return nil, fmt.Errorf("unknown entry %#x", typ)
+		}
+	}
+}
+
+func decodeSnappy(data []byte, val any) error {
+	decoded, err := io.ReadAll(snappy.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return err
+	}
+	return rlp.DecodeBytes(decoded, val)
+}
@@ -383,7 +383,12 @@ func (bd *BodyDownload) PrintPeerMap() {
 	bd.peerMap = make(map[[64]byte]int)
 }
 
+// AddToPrefetch keeps a block received from the NewBlock gossip, the engine API or mined, so that its body isn't
+// requested from the peers. It does nothing if the block download is disabled.
 func (bd *BodyDownload) AddToPrefetch(header *types.Header, body *types.RawBody) {
+	if bd.prefetchedBlocks == nil {
+		return
+	}
 	bd.prefetchedBlocks.Add(header, body)
 }
 
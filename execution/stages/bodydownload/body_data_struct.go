@@ -116,7 +116,7 @@ func NewBodyDownload(engine consensus.Engine, blockBufferSize, bodyCacheLimit in
 		deliveriesH:      make(map[uint64]*types.Header),
 		requests:         make(map[uint64]*BodyRequest),
 		peerMap:          make(map[[64]byte]int),
-		prefetchedBlocks: NewPrefetchedBlocks(),
+		prefetchedBlocks: NewPrefetchedBlocks(bodyCacheLimit), // the same byte budget, kept apart
 		// DeliveryNotify has capacity 1, and it is also used so that senders never block
 		// This makes this channel a mailbox with no more than one letter in it, meaning
 		// that there is something to collect
@@ -17,10 +17,12 @@
 package bodydownload_test
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/execution/consensus/ethash"
 	"github.com/erigontech/erigon/execution/stages/bodydownload"
 	"github.com/erigontech/erigon/execution/stages/mock"
@@ -37,3 +39,20 @@ func TestCreateBodyDownload(t *testing.T) {
 		t.Fatalf("update from db: %v", err)
 	}
 }
+
+func TestPrefetchedBlocksWithinBudget(t *testing.T) {
+	header := func(n int64) *types.Header { return &types.Header{Number: big.NewInt(n)} }
+	body := &types.RawBody{Transactions: [][]byte{make([]byte, 100)}}
+	prefetched := bodydownload.NewPrefetchedBlocks(2*body.EncodingSize() + 1)
+	prefetched.Add(header(1), body)
+	prefetched.Add(header(2), body)
+	prefetched.Add(header(2), body) // known
+	require.Equal(t, 2*body.EncodingSize(), prefetched.Size())
+	// the oldest is evicted for the newest
+	prefetched.Add(header(3), body)
+	require.Equal(t, 2*body.EncodingSize(), prefetched.Size())
+	_, evicted := prefetched.Get(header(1).Hash())
+	require.Nil(t, evicted)
+	_, kept := prefetched.Get(header(3).Hash())
+	require.Equal(t, body, kept)
+}
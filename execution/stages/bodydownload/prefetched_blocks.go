@@ -17,24 +17,36 @@
 package bodydownload
 
 import (
+	"sync"
+
 	lru "github.com/hashicorp/golang-lru/v2"
 
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/types"
 )
 
+// PrefetchedBlocks keeps the blocks received before their bodies are requested, from the NewBlock gossip, the engine
+// API payloads or mined, so that they aren't requested from the peers. It's bounded by the count of blocks and by
+// the size of their bodies.
 type PrefetchedBlocks struct {
-	blocks *lru.Cache[common.Hash, types.RawBlock]
+	lock      sync.Mutex
+	blocks    *lru.Cache[common.Hash, types.RawBlock]
+	size      int // of the bodies kept
+	sizeLimit int
 }
 
-func NewPrefetchedBlocks() *PrefetchedBlocks {
+func NewPrefetchedBlocks(sizeLimit int) *PrefetchedBlocks {
+	pb := &PrefetchedBlocks{sizeLimit: sizeLimit}
 	// Setting this to 2500 as `erigon import` imports blocks in batches of 2500
 	// and the import command makes use of PrefetchedBlocks.
-	cache, err := lru.New[common.Hash, types.RawBlock](2500)
+	cache, err := lru.NewWithEvict[common.Hash, types.RawBlock](2500, func(_ common.Hash, block types.RawBlock) {
+		pb.size -= block.Body.EncodingSize() // under pb.lock, the blocks are only evicted by Add
+	})
 	if err != nil {
 		panic("error creating prefetching cache for blocks")
 	}
-	return &PrefetchedBlocks{blocks: cache}
+	pb.blocks = cache
+	return pb
 }
 
 func (pb *PrefetchedBlocks) Get(hash common.Hash) (*types.Header, *types.RawBody) {
@@ -49,5 +61,20 @@ func (pb *PrefetchedBlocks) Add(h *types.Header, b *types.RawBody) {
 		return
 	}
 	hash := h.Hash()
-	pb.blocks.ContainsOrAdd(hash, types.RawBlock{Header: h, Body: b})
+	pb.lock.Lock()
+	defer pb.lock.Unlock()
+	if ok, _ := pb.blocks.ContainsOrAdd(hash, types.RawBlock{Header: h, Body: b}); ok {
+		return
+	}
+	pb.size += b.EncodingSize()
+	for pb.size > pb.sizeLimit && pb.blocks.Len() > 0 {
+		pb.blocks.RemoveOldest()
+	}
+}
+
+// Size returns the size of the bodies kept.
+func (pb *PrefetchedBlocks) Size() int {
+	pb.lock.Lock()
+	defer pb.lock.Unlock()
+	return pb.size
 }
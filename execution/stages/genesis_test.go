@@ -26,6 +26,7 @@ import (
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/stretchr/testify/require"
 
 	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/common"
@@ -64,6 +65,7 @@ func TestSetupGenesis(t *testing.T) {
 		wantConfig *chain.Config
 		name       string
 		wantHash   common.Hash
+		wantDiff   string // an entry expected in the ConfigDiff of a GenesisMismatchError
 	}{
 		{
 			name: "genesis without ChainConfig",
@@ -105,6 +107,7 @@ func TestSetupGenesis(t *testing.T) {
 				return core.CommitGenesisBlock(db, chainspec.SepoliaGenesisBlock(), datadir.New(tmpdir), logger)
 			},
 			wantErr:    &core.GenesisMismatchError{Stored: customghash, New: chainspec.SepoliaGenesisHash},
+			wantDiff:   "chainId: stored=1 supplied=11155111",
 			wantHash:   chainspec.SepoliaGenesisHash,
 			wantConfig: chainspec.SepoliaChainConfig,
 		},
@@ -115,6 +118,7 @@ func TestSetupGenesis(t *testing.T) {
 				return core.CommitGenesisBlock(db, polychain.BorMainnetGenesisBlock(), datadir.New(tmpdir), logger)
 			},
 			wantErr:    &core.GenesisMismatchError{Stored: customghash, New: polychain.BorMainnetGenesisHash},
+			wantDiff:   "chainId: stored=1 supplied=137",
 			wantHash:   polychain.BorMainnetGenesisHash,
 			wantConfig: polychain.BorMainnetChainConfig,
 		},
@@ -125,6 +129,7 @@ func TestSetupGenesis(t *testing.T) {
 				return core.CommitGenesisBlock(db, polychain.AmoyGenesisBlock(), datadir.New(tmpdir), logger)
 			},
 			wantErr:    &core.GenesisMismatchError{Stored: customghash, New: polychain.AmoyGenesisHash},
+			wantDiff:   "chainId: stored=1 supplied=80002",
 			wantHash:   polychain.AmoyGenesisHash,
 			wantConfig: polychain.AmoyChainConfig,
 		},
@@ -182,7 +187,9 @@ func TestSetupGenesis(t *testing.T) {
 			blockReader := freezeblocks.NewBlockReader(freezeblocks.NewRoSnapshots(freezingCfg, dirs.Snap, 0, log.New()), heimdall.NewRoSnapshots(freezingCfg, dirs.Snap, 0, log.New()), nil, nil)
 			config, genesis, err := test.fn(t, db, tmpdir)
 			// Check the return values.
-			if !reflect.DeepEqual(err, test.wantErr) {
+			if want, ok := test.wantErr.(*core.GenesisMismatchError); ok {
+				requireGenesisMismatch(t, err, want, test.wantDiff)
+			} else if !reflect.DeepEqual(err, test.wantErr) {
 				spew := spew.ConfigState{DisablePointerAddresses: true, DisableCapacities: true}
 				t.Fatalf("%s: returned error %#v, want %#v", test.name, spew.NewFormatter(err), spew.NewFormatter(test.wantErr))
 			}
@@ -215,3 +222,15 @@ func TestSetupGenesis(t *testing.T) {
 		})
 	}
 }
+
+// requireGenesisMismatch compares a GenesisMismatchError field by field: the ConfigDiff of a different chain lists
+// every field of its config, so only the entry that tells the chains apart is checked.
+func requireGenesisMismatch(t *testing.T, err error, want *core.GenesisMismatchError, wantDiff string) {
+	t.Helper()
+	var mismatch *core.GenesisMismatchError
+	require.ErrorAs(t, err, &mismatch)
+	require.Equal(t, want.Stored, mismatch.Stored)
+	require.Equal(t, want.New, mismatch.New)
+	require.Equal(t, want.SameChain, mismatch.SameChain)
+	require.Contains(t, mismatch.ConfigDiff, wantDiff)
+}
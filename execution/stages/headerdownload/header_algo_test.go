@@ -20,11 +20,19 @@ import (
 	"bytes"
 	"context"
 	"math/big"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 
 	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/crypto"
+	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/execution/stages/headerdownload"
@@ -114,6 +122,112 @@ func TestSideChainInsert(t *testing.T) {
 	}
 }
 
+func TestPoSRequestsPauseOnBacklog(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hd := headerdownload.NewHeaderDownload(512, 1024, nil, nil, log.New())
+	hd.SetPOSBacklogLimit(1000)
+	hd.SetHeaderToDownloadPoS(common.Hash{1}, 100)
+	hd.SetPOSSync(true)
+	hd.SetPosStatus(headerdownload.Syncing)
+
+	// the batches received pile up, their processing being slow
+	hd.AddToBacklog(600)
+	hd.AddToBacklog(600)
+	var requests atomic.Int64
+	var lastRequest atomic.Pointer[headerdownload.HeaderRequest]
+	hd.StartPoSDownloader(ctx, func(_ context.Context, req *headerdownload.HeaderRequest) ([64]byte, bool) {
+		lastRequest.Store(req)
+		requests.Add(1)
+		return [64]byte{}, false // not sent, so that it's requested again
+	}, func(context.Context, []headerdownload.PenaltyItem) {})
+	require.Never(t, func() bool { return requests.Load() > 0 }, 100*time.Millisecond, 5*time.Millisecond)
+
+	// the requests resume once the backlog drops under the limit
+	hd.RemoveFromBacklog(600)
+	require.Eventually(t, func() bool { return requests.Load() > 0 }, time.Second, 5*time.Millisecond)
+	require.Equal(t, common.Hash{1}, lastRequest.Load().Hash)
+	require.Equal(t, uint64(100), lastRequest.Load().Number)
+
+	// and pause again once it exceeds it
+	hd.AddToBacklog(600)
+	time.Sleep(10 * time.Millisecond) // the request being sent
+	sent := requests.Load()
+	require.Never(t, func() bool { return requests.Load() != sent }, 100*time.Millisecond, 5*time.Millisecond)
+}
+
+// reverseBatch returns the headers of a batch answering a reverse request of n headers, in the order of the answer.
+func reverseBatch(n int) []headerdownload.ChainSegmentHeader {
+	chain := createTestChain(int64(n), common.Hash{}, 1, nil)
+	batch := make([]headerdownload.ChainSegmentHeader, 0, n)
+	for i := len(chain) - 1; i >= 0; i-- {
+		headerRaw, _ := rlp.EncodeToBytes(chain[i])
+		batch = append(batch, headerdownload.ChainSegmentHeader{Header: chain[i], HeaderRaw: headerRaw, Hash: chain[i].Hash(), Number: chain[i].Number.Uint64()})
+	}
+	return batch
+}
+
+func TestSortHeadersReverse(t *testing.T) {
+	t.Parallel()
+	descending := reverseBatch(1024)
+	ascending := slices.Clone(descending)
+	slices.Reverse(ascending)
+	shuffled := slices.Clone(descending)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	// siblings at the same heights, sorted by hash
+	withSiblings := slices.Clone(descending[:10])
+	for _, h := range descending[:10] {
+		sibling := types.CopyHeader(h.Header)
+		sibling.Extra = []byte("sibling")
+		withSiblings = append(withSiblings, headerdownload.ChainSegmentHeader{Header: sibling, Hash: sibling.Hash(), Number: h.Number})
+	}
+	sort.Sort(headerdownload.HeadersReverseSort(withSiblings))
+	unsortedSiblings := slices.Clone(withSiblings)
+	unsortedSiblings[0], unsortedSiblings[1] = unsortedSiblings[1], unsortedSiblings[0]
+
+	for name, batch := range map[string][]headerdownload.ChainSegmentHeader{
+		"descending":        descending,
+		"ascending":         ascending,
+		"shuffled":          shuffled,
+		"with siblings":     withSiblings,
+		"unsorted siblings": unsortedSiblings,
+		"one":               descending[:1],
+		"none":              nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			expected, got := slices.Clone(batch), slices.Clone(batch)
+			sort.Sort(headerdownload.HeadersReverseSort(expected))
+			headerdownload.SortHeadersReverse(got)
+			require.Equal(t, len(expected), len(got))
+			for i := range expected {
+				require.Equal(t, expected[i].Number, got[i].Number)
+				require.Equal(t, expected[i].Hash, got[i].Hash)
+			}
+		})
+	}
+}
+
+func BenchmarkSortHeadersReverse(b *testing.B) {
+	batch := reverseBatch(1024)
+	headers := make([]headerdownload.ChainSegmentHeader, len(batch))
+	for _, bench := range []struct {
+		name string
+		sort func([]headerdownload.ChainSegmentHeader)
+	}{
+		{"sort", func(h []headerdownload.ChainSegmentHeader) { sort.Sort(headerdownload.HeadersReverseSort(h)) }},
+		{"checked", headerdownload.SortHeadersReverse},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				copy(headers, batch)
+				bench.sort(headers)
+			}
+		})
+	}
+}
+
 func createTestChain(length int64, parent common.Hash, diff int64, extra []byte) []*types.Header {
 	var (
 		i       int64
@@ -93,6 +93,18 @@ func (h HeadersReverseSort) Swap(i, j int) {
 	h[i], h[j] = h[j], h[i]
 }
 
+// SortHeadersReverse sorts the headers as HeadersReverseSort. A batch already in this order, e.g. the answer to a
+// reverse request of the PoS download, is only checked, in one pass without allocating.
+func SortHeadersReverse(headers []ChainSegmentHeader) {
+	h := HeadersReverseSort(headers)
+	for i := 1; i < len(h); i++ {
+		if h.Less(i, i-1) {
+			sort.Sort(h)
+			return
+		}
+	}
+}
+
 // Implements sort.Interface so we can sort the incoming header in the message by block height
 type HeadersSort []ChainSegmentHeader
 
@@ -113,7 +125,7 @@ func (h HeadersSort) Swap(i, j int) {
 }
 
 // SingleHeaderAsSegment converts message containing 1 header into one singleton chain segment
-func (hd *HeaderDownload) SingleHeaderAsSegment(headerRaw []byte, header *types.Header, penalizePoSBlocks bool) ([]ChainSegmentHeader, Penalty, error) {
+func (hd *HeaderDownload) SingleHeaderAsSegment(headerRaw []byte, header *types.Header) ([]ChainSegmentHeader, Penalty, error) {
 	hd.lock.RLock()
 	defer hd.lock.RUnlock()
 
@@ -124,9 +136,6 @@ func (hd *HeaderDownload) SingleHeaderAsSegment(headerRaw []byte, header *types.
 		hd.logger.Warn("[downloader] SingleHeaderAsSegment: Rejected header marked as bad", "hash", headerHash, "height", header.Number.Uint64())
 		return nil, BadBlockPenalty, nil
 	}
-	if penalizePoSBlocks && header.Difficulty.Sign() == 0 {
-		return nil, NewBlockGossipAfterMergePenalty, nil
-	}
 	h := ChainSegmentHeader{
 		Header:    header,
 		HeaderRaw: headerRaw,
@@ -442,6 +451,11 @@ func (hd *HeaderDownload) requestMoreHeadersForPOS(currentTime time.Time) (timeo
 		return
 	}
 
+	// Let the processing of the headers catch up, the pause not counting as a timeout of the anchor
+	if hd.posBacklogExceeded() {
+		return
+	}
+
 	// TODO: [pos-downloader-tweaks] - we could reduce this number, or config it
 	timeout = anchor.timeouts >= 3
 	if timeout {
@@ -678,6 +692,7 @@ func (hd *HeaderDownload) SetHeaderToDownloadPoS(hash common.Hash, height uint64
 		parentHash:  hash,
 		blockHeight: height + 1,
 	}
+	hd.resetBacklog()
 }
 
 func (hd *HeaderDownload) ProcessHeadersPOS(csHeaders []ChainSegmentHeader, tx kv.Getter, peerId [64]byte) ([]PenaltyItem, error) {
@@ -1172,15 +1187,11 @@ func (hd *HeaderDownload) SetHeaderReader(headerReader consensus.ChainHeaderRead
 }
 
 func (hd *HeaderDownload) AfterInitialCycle() {
-	hd.lock.Lock()
-	defer hd.lock.Unlock()
-	hd.initialCycle = false
+	hd.updateSyncMode(func() { hd.initialCycle = false })
 }
 
 func (hd *HeaderDownload) SetFetchingNew(fetching bool) {
-	hd.lock.Lock()
-	defer hd.lock.Unlock()
-	hd.fetchingNew = fetching
+	hd.updateSyncMode(func() { hd.fetchingNew = fetching })
 }
 
 func (hd *HeaderDownload) SetPosStatus(status SyncStatus) {
@@ -1266,7 +1277,7 @@ func (hd *HeaderDownload) AddMinedHeader(header *types.Header) error {
 	if err := header.EncodeRLP(buf); err != nil {
 		return err
 	}
-	segments, _, err := hd.SingleHeaderAsSegment(buf.Bytes(), header, false /* penalizePoSBlocks */)
+	segments, _, err := hd.SingleHeaderAsSegment(buf.Bytes(), header)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,106 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package headerdownload
+
+import (
+	"sync"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+var (
+	headersBacklogBatchesGauge = metrics.GetOrCreateGauge("headers_backlog_batches")
+	headersBacklogBytesGauge   = metrics.GetOrCreateGauge("headers_backlog_bytes")
+)
+
+// headersBacklog are the header batches received from the peers and not processed yet. When their processing falls
+// behind, e.g. on slow disks, the PoS download pauses its requests until they drain, see SetPOSBacklogLimit.
+type headersBacklog struct {
+	mu      sync.Mutex
+	batches int
+	bytes   uint64
+	limit   uint64 // size from which the PoS requests pause, never if 0
+	paused  bool
+}
+
+// AddToBacklog counts a header batch of size bytes received from a peer, until RemoveFromBacklog.
+func (hd *HeaderDownload) AddToBacklog(size int) {
+	b := &hd.posBacklog
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.batches++
+	b.bytes += uint64(size)
+	headersBacklogBatchesGauge.SetInt(b.batches)
+	headersBacklogBytesGauge.SetUint64(b.bytes)
+}
+
+// RemoveFromBacklog uncounts a header batch of size bytes once processed. The batches processed without having been
+// counted, e.g. after resetBacklog, are ignored.
+func (hd *HeaderDownload) RemoveFromBacklog(size int) {
+	b := &hd.posBacklog
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.batches == 0 {
+		return
+	}
+	b.batches--
+	b.bytes -= min(b.bytes, uint64(size))
+	if b.batches == 0 {
+		b.bytes = 0
+	}
+	headersBacklogBatchesGauge.SetInt(b.batches)
+	headersBacklogBytesGauge.SetUint64(b.bytes)
+}
+
+// SetPOSBacklogLimit sets the size of the backlog of header batches from which the PoS download pauses its requests,
+// never if 0.
+func (hd *HeaderDownload) SetPOSBacklogLimit(limit datasize.ByteSize) {
+	b := &hd.posBacklog
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.limit = uint64(limit)
+}
+
+// resetBacklog forgets the batches counted, e.g. the ones dropped by a stream closed before they were processed.
+func (hd *HeaderDownload) resetBacklog() {
+	b := &hd.posBacklog
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.batches, b.bytes = 0, 0
+	headersBacklogBatchesGauge.SetInt(0)
+	headersBacklogBytesGauge.SetUint64(0)
+}
+
+// posBacklogExceeded reports whether the PoS download should pause its requests. The batches keep being processed
+// meanwhile, including the response to the request in flight, so that the backlog drains and the requests resume.
+func (hd *HeaderDownload) posBacklogExceeded() bool {
+	b := &hd.posBacklog
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	exceeded := b.limit > 0 && b.bytes > b.limit
+	if exceeded != b.paused {
+		b.paused = exceeded
+		if exceeded {
+			hd.logger.Debug("[downloader] Pausing PoS header requests", "backlogBatches", b.batches, "backlogBytes", datasize.ByteSize(b.bytes).HR())
+		} else {
+			hd.logger.Debug("[downloader] Resuming PoS header requests", "backlogBatches", b.batches, "backlogBytes", datasize.ByteSize(b.bytes).HR())
+		}
+	}
+	return exceeded
+}
@@ -184,6 +184,7 @@ const (
 	TooFarPastPenalty
 	AbandonedAnchorPenalty
 	NewBlockGossipAfterMergePenalty
+	NewBlockGossipBlobEraPenalty
 )
 
 type PeerPenalty struct {
@@ -313,6 +314,8 @@ type HeaderDownload struct {
 	pendingPayloadHash  common.Hash                 // Header whose status we still should send to PayloadStatusCh
 	unsettledHeadHeight uint64                      // Height of unsettledForkChoice.headBlockHash
 	badPoSHeaders       map[common.Hash]common.Hash // Invalid Tip -> Last Valid Ancestor
+	posBacklog          headersBacklog              // Header batches received and not processed yet, pausing the PoS requests
+	onSyncModeChange    func(SyncMode)              // nil if the changes of sync mode aren't reported
 	logger              log.Logger
 }
 
@@ -351,6 +354,7 @@ func NewHeaderDownload(
 	heap.Init(&hd.persistedLinkQueue)
 	heap.Init(&hd.linkQueue)
 	heap.Init(&hd.insertQueue)
+	setSyncModeGauge(hd.syncMode())
 	return hd
 }
 
@@ -374,6 +378,8 @@ func (p Penalty) String() string {
 		return "TooFarPast"
 	case NewBlockGossipAfterMergePenalty:
 		return "NewBlockGossipAfterMerge"
+	case NewBlockGossipBlobEraPenalty:
+		return "NewBlockGossipBlobEra"
 	default:
 		return fmt.Sprintf("Unknown(%d)", p)
 	}
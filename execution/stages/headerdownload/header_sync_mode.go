@@ -0,0 +1,79 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package headerdownload
+
+import (
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+// SyncMode tells if the header downloader follows the new blocks announced by the peers. In the initial cycle it
+// doesn't, their announcements being dropped, but while the headers stage fetches the new headers.
+type SyncMode string
+
+const (
+	SyncModeInitialCycle SyncMode = "initial-cycle"
+	SyncModeTipFollowing SyncMode = "tip-following"
+)
+
+// initialCycleGauge is 1 while the header downloader is in SyncModeInitialCycle.
+var initialCycleGauge = metrics.GetOrCreateGauge("headers_download_initial_cycle")
+
+func setSyncModeGauge(mode SyncMode) {
+	if mode == SyncModeInitialCycle {
+		initialCycleGauge.SetUint64(1)
+	} else {
+		initialCycleGauge.SetUint64(0)
+	}
+}
+
+func (hd *HeaderDownload) syncMode() SyncMode {
+	if hd.initialCycle && !hd.fetchingNew {
+		return SyncModeInitialCycle
+	}
+	return SyncModeTipFollowing
+}
+
+func (hd *HeaderDownload) SyncMode() SyncMode {
+	hd.lock.RLock()
+	defer hd.lock.RUnlock()
+	return hd.syncMode()
+}
+
+// OnSyncModeChange makes the downloader call f with its new mode whenever it changes, see SyncMode.
+func (hd *HeaderDownload) OnSyncModeChange(f func(SyncMode)) {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	hd.onSyncModeChange = f
+}
+
+// updateSyncMode applies update to the state of the downloader, and reports the change of mode it makes, if any.
+func (hd *HeaderDownload) updateSyncMode(update func()) {
+	hd.lock.Lock()
+	prev := hd.syncMode()
+	update()
+	mode, onChange := hd.syncMode(), hd.onSyncModeChange
+	hd.lock.Unlock()
+
+	if mode == prev {
+		return
+	}
+	setSyncModeGauge(mode)
+	hd.logger.Debug("[downloader] Sync mode changed", "from", prev, "to", mode)
+	if onChange != nil {
+		onChange(mode)
+	}
+}
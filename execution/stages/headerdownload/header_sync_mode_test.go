@@ -0,0 +1,52 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package headerdownload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+func TestSyncModeChanges(t *testing.T) {
+	hd := NewHeaderDownload(16, 16, nil, nil, log.New())
+	require.Equal(t, SyncModeInitialCycle, hd.SyncMode())
+	require.Equal(t, uint64(1), initialCycleGauge.GetValueUint64())
+
+	var changes []SyncMode
+	hd.OnSyncModeChange(func(mode SyncMode) { changes = append(changes, mode) })
+
+	// the headers stage fetching the new headers during the initial cycle
+	hd.SetFetchingNew(true)
+	require.Equal(t, SyncModeTipFollowing, hd.SyncMode())
+	require.Equal(t, uint64(0), initialCycleGauge.GetValueUint64())
+	hd.SetFetchingNew(true)
+	hd.SetFetchingNew(false)
+	require.Equal(t, uint64(1), initialCycleGauge.GetValueUint64())
+	require.Equal(t, []SyncMode{SyncModeTipFollowing, SyncModeInitialCycle}, changes)
+
+	// past the initial cycle the mode no longer depends on the fetching of the new headers
+	hd.AfterInitialCycle()
+	hd.SetFetchingNew(true)
+	hd.SetFetchingNew(false)
+	hd.AfterInitialCycle()
+	require.Equal(t, SyncModeTipFollowing, hd.SyncMode())
+	require.Equal(t, uint64(0), initialCycleGauge.GetValueUint64())
+	require.Equal(t, []SyncMode{SyncModeTipFollowing, SyncModeInitialCycle, SyncModeTipFollowing}, changes)
+}
@@ -19,10 +19,12 @@ package mock
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/big"
 	"os"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -93,6 +95,9 @@ import (
 
 const MockInsertAsInitialCycle = false
 
+// DefaultPeerId is the id of the peer connected from the start, MockSentry.PeerId, the blocks of InsertChain come from.
+var DefaultPeerId = [64]byte{0x12, 0x34, 0x50}
+
 type MockSentry struct {
 	proto_sentry.UnimplementedSentryServer
 	Ctx                  context.Context
@@ -113,8 +118,13 @@ type MockSentry struct {
 	Genesis              *types.Block
 	SentryClient         direct.SentryClient
 	PeerId               *ptypes.H512
+	PeerInfos            map[[64]byte]*ptypes.PeerInfo // peers returned by PeerById
 	streams              map[proto_sentry.MessageId][]proto_sentry.Sentry_MessagesServer
 	sentMessages         []*proto_sentry.OutboundMessageData
+	peersLock            sync.Mutex
+	peers                [][64]byte                                       // connected simulated peers, in the order they were added
+	peerMessages         map[[64]byte][]*proto_sentry.OutboundMessageData // messages sent to each peer by id or at random
+	penalties            map[[64]byte][]proto_sentry.PenaltyKind          // penalties of each peer
 	StreamWg             sync.WaitGroup
 	ReceiveWg            sync.WaitGroup
 	Address              common.Address
@@ -165,11 +175,83 @@ func (ms *MockSentry) Send(req *proto_sentry.InboundMessage) (errs []error) {
 	return errs
 }
 
+// SendPeerEvent handles the peer event as if the sentry had streamed it.
+func (ms *MockSentry) SendPeerEvent(event *proto_sentry.PeerEvent) error {
+	return ms.sentriesClient.HandlePeerEvent(ms.Ctx, event, ms.SentryClient)
+}
+
 func (ms *MockSentry) SetStatus(context.Context, *proto_sentry.StatusData) (*proto_sentry.SetStatusReply, error) {
 	return &proto_sentry.SetStatusReply{}, nil
 }
 
-func (ms *MockSentry) PenalizePeer(context.Context, *proto_sentry.PenalizePeerRequest) (*emptypb.Empty, error) {
+// AddPeer connects a simulated peer, its PeerInfos entry filled in if it has none, and handles its Connect event.
+func (ms *MockSentry) AddPeer(id [64]byte) (*ptypes.H512, error) {
+	peerId := gointerfaces.ConvertHashToH512(id)
+	ms.peersLock.Lock()
+	if !slices.Contains(ms.peers, id) {
+		ms.peers = append(ms.peers, id)
+	}
+	if ms.PeerInfos == nil {
+		ms.PeerInfos = map[[64]byte]*ptypes.PeerInfo{}
+	}
+	if _, ok := ms.PeerInfos[id]; !ok {
+		ms.PeerInfos[id] = &ptypes.PeerInfo{Id: hex.EncodeToString(id[:]), Caps: []string{"eth/68"}}
+	}
+	ms.peersLock.Unlock()
+	return peerId, ms.SendPeerEvent(&proto_sentry.PeerEvent{PeerId: peerId, EventId: proto_sentry.PeerEvent_Connect})
+}
+
+// RemovePeer disconnects a simulated peer, and handles its Disconnect event. Its recorded messages and penalties are
+// kept.
+func (ms *MockSentry) RemovePeer(id [64]byte) error {
+	ms.peersLock.Lock()
+	ms.peers = slices.DeleteFunc(ms.peers, func(peer [64]byte) bool { return peer == id })
+	ms.peersLock.Unlock()
+	return ms.SendPeerEvent(&proto_sentry.PeerEvent{PeerId: gointerfaces.ConvertHashToH512(id), EventId: proto_sentry.PeerEvent_Disconnect})
+}
+
+// SendFromPeer sends the inbound message as if the peer had sent it.
+func (ms *MockSentry) SendFromPeer(id [64]byte, msgId proto_sentry.MessageId, data []byte) []error {
+	return ms.Send(&proto_sentry.InboundMessage{Id: msgId, Data: data, PeerId: gointerfaces.ConvertHashToH512(id)})
+}
+
+// PeerMessages returns the messages sent to the peer, by id or at random.
+func (ms *MockSentry) PeerMessages(id [64]byte) []*proto_sentry.OutboundMessageData {
+	ms.peersLock.Lock()
+	defer ms.peersLock.Unlock()
+	return slices.Clone(ms.peerMessages[id])
+}
+
+// Penalties returns the penalties of the peer.
+func (ms *MockSentry) Penalties(id [64]byte) []proto_sentry.PenaltyKind {
+	ms.peersLock.Lock()
+	defer ms.peersLock.Unlock()
+	return slices.Clone(ms.penalties[id])
+}
+
+func (ms *MockSentry) connectedPeers() [][64]byte {
+	ms.peersLock.Lock()
+	defer ms.peersLock.Unlock()
+	return slices.Clone(ms.peers)
+}
+
+func (ms *MockSentry) recordPeerMessage(id [64]byte, msg *proto_sentry.OutboundMessageData) {
+	ms.peersLock.Lock()
+	defer ms.peersLock.Unlock()
+	if ms.peerMessages == nil {
+		ms.peerMessages = map[[64]byte][]*proto_sentry.OutboundMessageData{}
+	}
+	ms.peerMessages[id] = append(ms.peerMessages[id], msg)
+}
+
+func (ms *MockSentry) PenalizePeer(_ context.Context, r *proto_sentry.PenalizePeerRequest) (*emptypb.Empty, error) {
+	ms.peersLock.Lock()
+	defer ms.peersLock.Unlock()
+	if ms.penalties == nil {
+		ms.penalties = map[[64]byte][]proto_sentry.PenaltyKind{}
+	}
+	id := gointerfaces.ConvertH512ToHash(r.PeerId)
+	ms.penalties[id] = append(ms.penalties[id], r.Penalty)
 	return nil, nil
 }
 func (ms *MockSentry) PeerMinBlock(context.Context, *proto_sentry.PeerMinBlockRequest) (*emptypb.Empty, error) {
@@ -185,11 +267,23 @@ func (ms *MockSentry) SendMessageByMinBlock(_ context.Context, r *proto_sentry.S
 }
 func (ms *MockSentry) SendMessageById(_ context.Context, r *proto_sentry.SendMessageByIdRequest) (*proto_sentry.SentPeers, error) {
 	ms.sentMessages = append(ms.sentMessages, r.Data)
-	return nil, nil
+	ms.recordPeerMessage(gointerfaces.ConvertH512ToHash(r.PeerId), r.Data)
+	return &proto_sentry.SentPeers{Peers: []*ptypes.H512{r.PeerId}}, nil
 }
+
+// SendMessageToRandomPeers sends the message to the first MaxPeers connected peers, in the order they were added.
 func (ms *MockSentry) SendMessageToRandomPeers(_ context.Context, r *proto_sentry.SendMessageToRandomPeersRequest) (*proto_sentry.SentPeers, error) {
 	ms.sentMessages = append(ms.sentMessages, r.Data)
-	return nil, nil
+	peers := ms.connectedPeers()
+	if uint64(len(peers)) > r.MaxPeers {
+		peers = peers[:r.MaxPeers]
+	}
+	reply := &proto_sentry.SentPeers{}
+	for _, id := range peers {
+		ms.recordPeerMessage(id, r.Data)
+		reply.Peers = append(reply.Peers, gointerfaces.ConvertHashToH512(id))
+	}
+	return reply, nil
 }
 func (ms *MockSentry) SendMessageToAll(_ context.Context, r *proto_sentry.OutboundMessageData) (*proto_sentry.SentPeers, error) {
 	ms.sentMessages = append(ms.sentMessages, r)
@@ -217,13 +311,23 @@ func (ms *MockSentry) Messages(req *proto_sentry.MessagesRequest, stream proto_s
 }
 
 func (ms *MockSentry) Peers(context.Context, *emptypb.Empty) (*proto_sentry.PeersReply, error) {
-	return &proto_sentry.PeersReply{}, nil
+	reply := &proto_sentry.PeersReply{}
+	ms.peersLock.Lock()
+	defer ms.peersLock.Unlock()
+	for _, id := range ms.peers {
+		if info, ok := ms.PeerInfos[id]; ok {
+			reply.Peers = append(reply.Peers, info)
+		}
+	}
+	return reply, nil
 }
 func (ms *MockSentry) PeerCount(context.Context, *proto_sentry.PeerCountRequest) (*proto_sentry.PeerCountReply, error) {
-	return &proto_sentry.PeerCountReply{Count: 0}, nil
+	return &proto_sentry.PeerCountReply{Count: uint64(len(ms.connectedPeers()))}, nil
 }
-func (ms *MockSentry) PeerById(context.Context, *proto_sentry.PeerByIdRequest) (*proto_sentry.PeerByIdReply, error) {
-	return &proto_sentry.PeerByIdReply{}, nil
+func (ms *MockSentry) PeerById(_ context.Context, req *proto_sentry.PeerByIdRequest) (*proto_sentry.PeerByIdReply, error) {
+	ms.peersLock.Lock()
+	defer ms.peersLock.Unlock()
+	return &proto_sentry.PeerByIdReply{Peer: ms.PeerInfos[gointerfaces.ConvertH512ToHash(req.PeerId)]}, nil
 }
 func (ms *MockSentry) PeerEvents(req *proto_sentry.PeerEventsRequest, server proto_sentry.Sentry_PeerEventsServer) error {
 	return nil
@@ -239,6 +343,15 @@ func MockWithGenesis(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateKey,
 	return MockWithGenesisPruneMode(tb, gspec, key, blockBufferSize, prune.MockMode, withPosDownloader)
 }
 
+// MockWithGenesisOverrides is MockWithGenesis for gspec with the header fields in overrides replaced.
+func MockWithGenesisOverrides(tb testing.TB, gspec *types.Genesis, overrides *core.GenesisOverrides, key *ecdsa.PrivateKey, withPosDownloader bool) *MockSentry {
+	gspec, err := overrides.Apply(gspec)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return MockWithGenesis(tb, gspec, key, withPosDownloader)
+}
+
 func MockWithGenesisEngine(tb testing.TB, gspec *types.Genesis, engine consensus.Engine, withPosDownloader, checkStateRoot bool) *MockSentry {
 	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 	return MockWithEverything(tb, gspec, key, prune.MockMode, engine, blockBufferSize, false, withPosDownloader, checkStateRoot)
@@ -314,15 +427,17 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 		ChainConfig:    gspec.Config,
 		Key:            key,
 		Notifications:  shards.NewNotifications(erigonGrpcServeer),
-		PeerId:         gointerfaces.ConvertHashToH512([64]byte{0x12, 0x34, 0x50}), // "12345"
+		PeerId:         gointerfaces.ConvertHashToH512(DefaultPeerId), // "12345"
+		peers:          [][64]byte{DefaultPeerId},
 		BlockSnapshots: allSnapshots,
 		BlockReader:    br,
-		ReceiptsReader: receipts.NewGenerator(br, engine, 5*time.Second),
+		ReceiptsReader: receipts.NewGenerator(br, engine, 5*time.Second, cfg.Sync.P2PReceiptsCache, "p2p"),
 		HistoryV3:      true,
 		cfg:            cfg,
 	}
 	mock.retirementStart, _ = mock.Notifications.Events.AddRetirementStartSubscription()
 	mock.retirementDone, _ = mock.Notifications.Events.AddRetirementDoneSubscription()
+	mock.ReceiptsReader.InvalidateOnUnwind(mock.Ctx, mock.Notifications.Events)
 
 	if tb != nil {
 		tb.Cleanup(mock.Close)
@@ -418,7 +533,7 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 		logger,
 	)
 
-	maxBlockBroadcastPeers := func(header *types.Header) uint { return 0 }
+	maxBlockBroadcastPeers := func(header *types.Header) uint { return 10 }
 
 	mock.sentriesClient, err = sentry_multi_client.NewMultiClient(
 		mock.DB,
@@ -426,6 +541,7 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 		mock.Engine,
 		sentries,
 		cfg.Sync,
+		nil, /* receiptsGenerator */
 		mock.BlockReader,
 		blockBufferSize,
 		statusDataProvider,
@@ -442,6 +558,7 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 		}
 	}
 	mock.sentriesClient.IsMock = true
+	mock.sentriesClient.RelayPeerEvents(mock.Notifications.Events)
 
 	var (
 		snapDownloader = proto_downloader.NewMockDownloaderClient(ctrl)
@@ -785,13 +902,19 @@ func (ms *MockSentry) insertPoWBlocks(chain *core.ChainPack) error {
 	}
 	ms.ReceiveWg.Wait() // Wait for all messages to be processed before we proceed
 
+	return ms.RunCycle()
+}
+
+// RunCycle runs an iteration of the staged sync loop, which inserts the headers and the bodies delivered to the
+// downloaders.
+func (ms *MockSentry) RunCycle() error {
 	if ms.TxPool != nil {
 		ms.ReceiveWg.Add(1)
 	}
 	initialCycle, firstCycle := MockInsertAsInitialCycle, false
 	hook := stages2.NewHook(ms.Ctx, ms.DB, ms.Notifications, ms.Sync, ms.BlockReader, ms.ChainConfig, ms.Log, nil)
 
-	if err = stages2.StageLoopIteration(ms.Ctx, ms.DB, wrap.NewTxContainer(nil, nil), ms.Sync, initialCycle, firstCycle, ms.Log, ms.BlockReader, hook); err != nil {
+	if err := stages2.StageLoopIteration(ms.Ctx, ms.DB, wrap.NewTxContainer(nil, nil), ms.Sync, initialCycle, firstCycle, ms.Log, ms.BlockReader, hook); err != nil {
 		return err
 	}
 	// Wait to know if a new background retirement has started
@@ -896,10 +1019,34 @@ func (ms *MockSentry) InsertChain(chain *core.ChainPack) error {
 	return nil
 }
 
+// InsertBranch inserts the branch, see core.GenerateChainWithFork, running the stage loop, and checks it became the
+// canonical chain: for a branch winning the fork choice, that the stage loop reorged to it.
+func (ms *MockSentry) InsertBranch(branch *core.ChainPack) error {
+	if err := ms.InsertChain(branch); err != nil {
+		return err
+	}
+	return ms.DB.View(ms.Ctx, func(tx kv.Tx) error {
+		for _, block := range branch.Blocks {
+			hash, err := rawdb.ReadCanonicalHash(tx, block.NumberU64())
+			if err != nil {
+				return err
+			}
+			if hash != block.Hash() {
+				return fmt.Errorf("block %d %x of the branch is not canonical, %x is", block.NumberU64(), block.Hash(), hash)
+			}
+		}
+		return nil
+	})
+}
+
 func (ms *MockSentry) HeaderDownload() *headerdownload.HeaderDownload {
 	return ms.sentriesClient.Hd
 }
 
+func (ms *MockSentry) MultiClient() *sentry_multi_client.MultiClient {
+	return ms.sentriesClient
+}
+
 func (ms *MockSentry) NewHistoryStateReader(blockNum uint64, tx kv.TemporalTx) state.StateReader {
 	r, err := rpchelper.CreateHistoryStateReader(tx, blockNum, 0, ms.BlockReader.TxnumReader(ms.Ctx))
 	if err != nil {
@@ -19,6 +19,7 @@ package mock_test
 import (
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
@@ -27,12 +28,14 @@ import (
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/u256"
 	sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon-lib/wrap"
 	"github.com/erigontech/erigon/core"
-	"github.com/erigontech/erigon/execution/stages"
+	"github.com/erigontech/erigon/execution/stagedsync/stages"
+	stages2 "github.com/erigontech/erigon/execution/stages"
 	"github.com/erigontech/erigon/execution/stages/mock"
 	"github.com/erigontech/erigon/p2p/protocols/eth"
 )
@@ -75,7 +78,7 @@ func TestHeaderStep(t *testing.T) {
 	m.ReceiveWg.Wait() // Wait for all messages to be processed before we proceed
 
 	initialCycle, firstCycle := mock.MockInsertAsInitialCycle, false
-	if err := stages.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
+	if err := stages2.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -114,7 +117,7 @@ func TestMineBlockWith1Tx(t *testing.T) {
 		m.ReceiveWg.Wait() // Wait for all messages to be processed before we proceeed
 
 		initialCycle, firstCycle := mock.MockInsertAsInitialCycle, false
-		if err := stages.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, log.New(), m.BlockReader, nil); err != nil {
+		if err := stages2.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, log.New(), m.BlockReader, nil); err != nil {
 			t.Fatal(err)
 		}
 	}
@@ -136,7 +139,7 @@ func TestMineBlockWith1Tx(t *testing.T) {
 	}
 	m.ReceiveWg.Wait() // Wait for all messages to be processed before we proceed
 
-	err = stages.MiningStep(m.Ctx, m.DB, m.MiningSync, "", log.Root())
+	err = stages2.MiningStep(m.Ctx, m.DB, m.MiningSync, "", log.Root())
 	require.NoError(err)
 
 	got := <-m.PendingBlocks
@@ -183,7 +186,7 @@ func TestReorg(t *testing.T) {
 	m.ReceiveWg.Wait() // Wait for all messages to be processed before we proceeed
 
 	initialCycle, firstCycle := mock.MockInsertAsInitialCycle, false
-	if err := stages.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
+	if err := stages2.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -235,7 +238,7 @@ func TestReorg(t *testing.T) {
 	}
 	m.ReceiveWg.Wait() // Wait for all messages to be processed before we proceeed
 
-	if err := stages.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
+	if err := stages2.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -278,7 +281,7 @@ func TestReorg(t *testing.T) {
 	m.ReceiveWg.Wait() // Wait for all messages to be processed before we proceeed
 
 	// This is unwind step
-	if err := stages.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
+	if err := stages2.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -314,7 +317,7 @@ func TestReorg(t *testing.T) {
 	}
 	m.ReceiveWg.Wait() // Wait for all messages to be processed before we proceeed
 
-	if err := stages.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
+	if err := stages2.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -411,7 +414,7 @@ func TestAnchorReplace(t *testing.T) {
 	m.ReceiveWg.Wait() // Wait for all messages to be processed before we proceeed
 
 	initialCycle, firstCycle := mock.MockInsertAsInitialCycle, false
-	if err := stages.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
+	if err := stages2.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, nil); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -516,8 +519,244 @@ func TestAnchorReplace2(t *testing.T) {
 	m.ReceiveWg.Wait() // Wait for all messages to be processed before we proceeed
 
 	initialCycle, firstCycle := mock.MockInsertAsInitialCycle, false
-	hook := stages.NewHook(m.Ctx, m.DB, m.Notifications, m.Sync, m.BlockReader, m.ChainConfig, m.Log, nil)
-	if err := stages.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, hook); err != nil {
+	hook := stages2.NewHook(m.Ctx, m.DB, m.Notifications, m.Sync, m.BlockReader, m.ChainConfig, m.Log, nil)
+	if err := stages2.StageLoopIteration(m.Ctx, m.DB, wrap.NewTxContainer(nil, nil), m.Sync, initialCycle, firstCycle, m.Log, m.BlockReader, hook); err != nil {
 		t.Fatal(err)
 	}
 }
+
+func TestSyncStatus(t *testing.T) {
+	t.Parallel()
+	m := mock.Mock(t)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+	})
+	require.NoError(t, err)
+
+	ch, unsubscribe := m.Notifications.Events.AddSyncStatusSubscription()
+	defer unsubscribe()
+
+	var cycles [][]stages.StageStatus
+	for i := 0; i < chain.Length(); i++ {
+		require.NoError(t, m.InsertChain(chain.Slice(i, i+1)))
+		select {
+		case status := <-ch:
+			cycles = append(cycles, status)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no sync status after cycle %d", i)
+		}
+	}
+
+	finished := map[stages.SyncStage]time.Time{}
+	for i, status := range cycles {
+		require.Len(t, status, len(stages.AllStages))
+		for _, s := range status {
+			if s.Stage != stages.Execution && s.Stage != stages.Finish {
+				continue
+			}
+			require.Equal(t, uint64(i+1), s.Progress, s.Stage)
+			require.Positive(t, s.LastRunTook, s.Stage)
+			require.Empty(t, s.LastErr, s.Stage)
+			require.True(t, s.LastRun.After(finished[s.Stage]), s.Stage)
+			finished[s.Stage] = s.LastRun
+		}
+	}
+
+	// the status published is the one recorded
+	require.NoError(t, m.DB.View(m.Ctx, func(tx kv.Tx) error {
+		status, err := stages.SyncStatus(tx)
+		require.NoError(t, err)
+		require.Equal(t, cycles[len(cycles)-1], status)
+		return nil
+	}))
+}
+
+func TestLoopTimeBudget(t *testing.T) {
+	t.Parallel()
+	unbudgeted, budgeted := mock.Mock(t), mock.Mock(t)
+	to := common.Address{2}
+	chain, err := core.GenerateChain(unbudgeted.ChainConfig, unbudgeted.Genesis, unbudgeted.Engine, unbudgeted.DB, 10, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+		txn, err := types.SignTx(types.NewTransaction(b.TxNonce(unbudgeted.Address), to, uint256.NewInt(10_000), params.TxGas, u256.Num1, nil), *types.LatestSignerForChainID(unbudgeted.ChainConfig.ChainID), unbudgeted.Key)
+		require.NoError(t, err)
+		b.AddTx(txn)
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, unbudgeted.InsertChain(chain))
+
+	// Send the blocks: NewBlock, headers, bodies
+	b, err := rlp.EncodeToBytes(&eth.NewBlockPacket{Block: chain.TopBlock, TD: big.NewInt(1)})
+	require.NoError(t, err)
+	budgeted.ReceiveWg.Add(1)
+	for _, err = range budgeted.Send(&sentry.InboundMessage{Id: sentry.MessageId_NEW_BLOCK_66, Data: b, PeerId: budgeted.PeerId}) {
+		require.NoError(t, err)
+	}
+	b, err = rlp.EncodeToBytes(&eth.BlockHeadersPacket66{RequestId: 1, BlockHeadersPacket: chain.Headers})
+	require.NoError(t, err)
+	budgeted.ReceiveWg.Add(1)
+	for _, err = range budgeted.Send(&sentry.InboundMessage{Id: sentry.MessageId_BLOCK_HEADERS_66, Data: b, PeerId: budgeted.PeerId}) {
+		require.NoError(t, err)
+	}
+	bodies := make(eth.BlockBodiesPacket, chain.Length())
+	for i, block := range chain.Blocks {
+		bodies[i] = block.Body()
+	}
+	b, err = rlp.EncodeToBytes(&eth.BlockBodiesPacket66{RequestId: 1, BlockBodiesPacket: bodies})
+	require.NoError(t, err)
+	budgeted.ReceiveWg.Add(1)
+	for _, err = range budgeted.Send(&sentry.InboundMessage{Id: sentry.MessageId_BLOCK_BODIES_66, Data: b, PeerId: budgeted.PeerId}) {
+		require.NoError(t, err)
+	}
+	budgeted.ReceiveWg.Wait()
+
+	// with a budget spent as soon as it starts, the execution yields after each block
+	var iterations int
+	var executed uint64
+	for more := true; more; iterations++ {
+		require.Less(t, iterations, 2*chain.Length(), "the budgeted runs don't complete")
+		more, err = budgeted.Sync.RunWithBudget(budgeted.DB, wrap.NewTxContainer(nil, nil), mock.MockInsertAsInitialCycle, false, time.Nanosecond)
+		require.NoError(t, err)
+		require.NoError(t, budgeted.DB.Update(budgeted.Ctx, func(tx kv.RwTx) error {
+			return budgeted.Sync.RunPrune(budgeted.DB, tx, mock.MockInsertAsInitialCycle)
+		}))
+
+		require.NoError(t, budgeted.DB.View(budgeted.Ctx, func(tx kv.Tx) error {
+			progress, err := stages.GetStageProgress(tx, stages.Execution)
+			require.NoError(t, err)
+			require.Greater(t, progress, executed, "iteration %d", iterations)
+			executed = progress
+			return nil
+		}))
+	}
+	require.Greater(t, iterations, 1)
+
+	// the budgeted iterations did the work of the unbudgeted one: the roots of all the blocks were checked on the way
+	read := func(m *mock.MockSentry) (progress map[stages.SyncStage]uint64, balance, nonce uint64) {
+		progress = map[stages.SyncStage]uint64{}
+		require.NoError(t, m.DB.ViewTemporal(m.Ctx, func(tx kv.TemporalTx) error {
+			for _, stage := range []stages.SyncStage{stages.Headers, stages.Bodies, stages.Senders, stages.Execution, stages.Finish} {
+				progress[stage], err = stages.GetStageProgress(tx, stage)
+				require.NoError(t, err)
+			}
+			acc, err := m.NewStateReader(tx).ReadAccountData(to)
+			require.NoError(t, err)
+			require.NotNil(t, acc)
+			balance = acc.Balance.Uint64()
+			acc, err = m.NewStateReader(tx).ReadAccountData(m.Address)
+			require.NoError(t, err)
+			nonce = acc.Nonce
+			return nil
+		}))
+		return progress, balance, nonce
+	}
+	wantProgress, wantBalance, wantNonce := read(unbudgeted)
+	gotProgress, gotBalance, gotNonce := read(budgeted)
+	require.Equal(t, uint64(chain.Length()), wantProgress[stages.Finish])
+	require.Equal(t, wantProgress, gotProgress)
+	require.Equal(t, wantBalance, gotBalance)
+	require.Equal(t, wantNonce, gotNonce)
+}
+
+func TestPenaltyGoesToOffendingPeer(t *testing.T) {
+	t.Parallel()
+	m := mock.Mock(t)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+	})
+	require.NoError(t, err)
+
+	honest, offending := [64]byte{1}, [64]byte{2}
+	for _, peer := range [][64]byte{honest, offending} {
+		_, err := m.AddPeer(peer)
+		require.NoError(t, err)
+	}
+	count, err := m.PeerCount(m.Ctx, &sentry.PeerCountRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), count.Count, "the default peer and the added ones")
+
+	m.HeaderDownload().ReportBadHeader(chain.Blocks[1].Hash())
+	for i, peer := range [][64]byte{honest, offending} {
+		b, err := rlp.EncodeToBytes(&eth.NewBlockPacket{Block: chain.Blocks[i], TD: big.NewInt(1)})
+		require.NoError(t, err)
+		m.ReceiveWg.Add(1)
+		for _, err = range m.SendFromPeer(peer, sentry.MessageId_NEW_BLOCK_66, b) {
+			require.NoError(t, err)
+		}
+	}
+	m.ReceiveWg.Wait()
+
+	require.Equal(t, []sentry.PenaltyKind{sentry.PenaltyKind_Kick}, m.Penalties(offending))
+	require.Empty(t, m.Penalties(honest))
+	require.Empty(t, m.Penalties(mock.DefaultPeerId))
+
+	require.NoError(t, m.RemovePeer(offending))
+	count, err = m.PeerCount(m.Ctx, &sentry.PeerCountRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), count.Count)
+}
+
+func TestBroadcastNewBlockToPeerSample(t *testing.T) {
+	t.Parallel()
+	m := mock.Mock(t)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 1, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+	})
+	require.NoError(t, err)
+	block := chain.TopBlock
+
+	source, others := [64]byte{1}, make([][64]byte, 16)
+	for i := range others {
+		others[i] = [64]byte{byte(i + 2)}
+	}
+	for _, peer := range append([][64]byte{source}, others...) {
+		_, err := m.AddPeer(peer)
+		require.NoError(t, err)
+	}
+	b, err := rlp.EncodeToBytes(&eth.NewBlockPacket{Block: block, TD: big.NewInt(1)})
+	require.NoError(t, err)
+	m.ReceiveWg.Add(1)
+	for _, err = range m.SendFromPeer(source, sentry.MessageId_NEW_BLOCK_66, b) {
+		require.NoError(t, err)
+	}
+	m.ReceiveWg.Wait()
+
+	blockMessages := func(peer [64]byte) (messages []*sentry.OutboundMessageData) {
+		for _, msg := range m.PeerMessages(peer) {
+			if msg.Id == sentry.MessageId_NEW_BLOCK_66 || msg.Id == sentry.MessageId_NEW_BLOCK_HASHES_66 {
+				messages = append(messages, msg)
+			}
+		}
+		return messages
+	}
+	td := new(big.Int).Add(m.Genesis.Difficulty(), block.Difficulty())
+	m.MultiClient().BroadcastNewBlock(m.Ctx, block.Header(), block.RawBody(), td)
+	var full, hashes int
+	for _, peer := range others {
+		messages := blockMessages(peer)
+		require.Len(t, messages, 1)
+		switch messages[0].Id {
+		case sentry.MessageId_NEW_BLOCK_66:
+			full++
+			var packet eth.NewBlockPacket
+			require.NoError(t, rlp.DecodeBytes(messages[0].Data, &packet))
+			require.Equal(t, block.Hash(), packet.Block.Hash())
+			require.Equal(t, td, packet.TD)
+		case sentry.MessageId_NEW_BLOCK_HASHES_66:
+			hashes++
+			var packet eth.NewBlockHashesPacket
+			require.NoError(t, rlp.DecodeBytes(messages[0].Data, &packet))
+			require.Equal(t, eth.NewBlockHashesPacket{{Hash: block.Hash(), Number: block.NumberU64()}}, packet)
+		}
+	}
+	require.Equal(t, 4, full, "the square root of the peers")
+	require.Equal(t, 12, hashes)
+	require.Empty(t, blockMessages(source), "the block came from it")
+
+	// past the merge the consensus layer propagates the blocks
+	m.HeaderDownload().SetFirstPoSHeight(0)
+	m.MultiClient().BroadcastNewBlock(m.Ctx, block.Header(), block.RawBody(), td)
+	for _, peer := range others {
+		require.Len(t, blockMessages(peer), 1)
+	}
+}
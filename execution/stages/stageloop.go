@@ -38,6 +38,7 @@ import (
 	"github.com/erigontech/erigon-lib/kv/membatchwithdb"
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/state"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon-lib/wrap"
@@ -115,7 +116,8 @@ func StageLoop(
 			time.Sleep(500 * time.Millisecond) // just to avoid too many similar error logs
 			continue
 		}
-		if time.Since(t) < 5*time.Minute {
+		// an iteration cut short by its time budget doesn't tell the initial cycle is over
+		if time.Since(t) < 5*time.Minute && !sync.Yielded() {
 			initialCycle = false
 		}
 		if !initialCycle {
@@ -150,7 +152,7 @@ func ProcessFrozenBlocks(ctx context.Context, db kv.RwDB, blockReader services.F
 			}
 		}
 
-		more, err := sync.Run(db, wrap.NewTxContainer(nil, nil), initialCycle, firstCycle)
+		more, err := sync.RunWithBudget(db, wrap.NewTxContainer(nil, nil), initialCycle, firstCycle, sync.Cfg().LoopTimeBudget)
 		if err != nil {
 			return err
 		}
@@ -248,8 +250,20 @@ func StageLoopIteration(ctx context.Context, db kv.RwDB, txc wrap.TxContainer, s
 	if err = hook.BeforeRun(txc.Tx, isSynced); err != nil {
 		return err
 	}
-	_, err = sync.Run(db, txc, initialCycle, firstCycle)
+	_, err = sync.RunWithBudget(db, txc, initialCycle, firstCycle, sync.Cfg().LoopTimeBudget)
 	if err != nil {
+		if canRunCycleInOneTransaction && !externalTx {
+			txc.Tx.Rollback() // the run of the failed stage is recorded out of the cycle transaction
+			txc.Tx = nil
+		}
+		if ctx.Err() == nil {
+			if errRuns := saveStageRuns(ctx, db, txc.Tx, sync); errRuns != nil {
+				logger.Warn("[sync] can't record the run of the stages", "err", errRuns)
+			}
+		}
+		return err
+	}
+	if err = saveStageRuns(ctx, db, txc.Tx, sync); err != nil {
 		return err
 	}
 	logCtx := sync.PrintTimings()
@@ -318,6 +332,14 @@ func StageLoopIteration(ctx context.Context, db kv.RwDB, txc wrap.TxContainer, s
 	return nil
 }
 
+// saveStageRuns records the runs of the stages of the cycle, in tx if not nil, see stages.SyncStatus.
+func saveStageRuns(ctx context.Context, db kv.RwDB, tx kv.RwTx, sync *stagedsync.Sync) error {
+	if tx != nil {
+		return sync.SaveStageRuns(tx)
+	}
+	return db.Update(ctx, sync.SaveStageRuns)
+}
+
 func stagesHeadersAndFinish(db kv.RoDB, tx kv.Tx) (head, polygonSync, fin uint64, gasUsed uint64, err error) {
 	if tx != nil {
 		if fin, err = stages.GetStageProgress(tx, stages.Finish); err != nil {
@@ -408,6 +430,10 @@ func (h *Hook) AfterRun(tx kv.Tx, finishProgressBefore uint64) error {
 	return h.afterRun(tx, finishProgressBefore)
 }
 func (h *Hook) afterRun(tx kv.Tx, finishProgressBefore uint64) error {
+	// before anyone hears of the new head, the cached hashes of the blocks no longer canonical go
+	if err := h.invalidateCanonicalHashes(tx); err != nil {
+		return err
+	}
 	// Update sentry status for peers to see our sync status
 	if h.updateHead != nil {
 		h.updateHead(h.ctx)
@@ -415,6 +441,28 @@ func (h *Hook) afterRun(tx kv.Tx, finishProgressBefore uint64) error {
 	return h.sendNotifications(tx, finishProgressBefore)
 
 }
+
+// invalidateCanonicalHashes invalidates the cached canonical hashes of the blocks unwound by the last run, and of the
+// blocks past the head, truncated from the canonical chain by a fork choice to an earlier block.
+func (h *Hook) invalidateCanonicalHashes(tx kv.Tx) error {
+	cache, ok := h.blockReader.(services.CanonicalHashCache)
+	if !ok {
+		return nil
+	}
+	head, err := stages.GetStageProgress(tx, stages.Finish)
+	if err != nil {
+		return err
+	}
+	from := head + 1
+	if h.sync != nil {
+		if unwindTo := h.sync.PrevUnwindPoint(); unwindTo != nil && *unwindTo < head {
+			from = *unwindTo + 1
+		}
+	}
+	cache.InvalidateCanonicalHashes(from, tx.ViewID())
+	return nil
+}
+
 func (h *Hook) sendNotifications(tx kv.Tx, finishStageBeforeSync uint64) error {
 	if h.notifications == nil {
 		return nil
@@ -458,6 +506,16 @@ func (h *Hook) sendNotifications(tx kv.Tx, finishStageBeforeSync uint64) error {
 			return nil
 		}
 		h.notifications.RecentLogs.Notify(h.notifications.Events, notifyFrom, notifyTo, isUnwind)
+		if err = h.notifyNewBlocks(tx, notifyFrom, notifyTo, isUnwind); err != nil {
+			return err
+		}
+		if h.notifications.Events.HasSyncStatusSubscriptions() {
+			status, err := stages.SyncStatus(tx)
+			if err != nil {
+				return err
+			}
+			h.notifications.Events.OnSyncStatus(status)
+		}
 	}
 
 	currentHeader := rawdb.ReadCurrentHeader(tx)
@@ -489,6 +547,89 @@ func (h *Hook) sendNotifications(tx kv.Tx, finishStageBeforeSync uint64) error {
 	return nil
 }
 
+// notifyNewBlocks sends the canonical blocks [from, to) with their bodies to the subscribers of full blocks.
+// After an unwind the first block is marked as the start of a new branch, whose parent is the common ancestor.
+func (h *Hook) notifyNewBlocks(tx kv.Tx, from, to uint64, isUnwind bool) error {
+	if from >= to {
+		return nil
+	}
+	newHead, ok, err := h.blockReader.CanonicalHash(h.ctx, tx, to-1)
+	if err != nil || !ok {
+		return err
+	}
+	oldHead := h.notifications.Events.UpdateHead(newHead)
+	if isUnwind && h.notifications.Events.HasUnwindSubscriptions() {
+		if err := h.notifyUnwind(tx, oldHead, from); err != nil {
+			return err
+		}
+	}
+	if !h.notifications.Events.HasBlockSubscriptions() {
+		return nil
+	}
+
+	blocks := make([]*shards.NewBlock, 0, to-from)
+	for blockNum := from; blockNum < to; blockNum++ {
+		block, err := h.blockReader.BlockByNumber(h.ctx, tx, blockNum)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			break
+		}
+		headerRlp, err := rlp.EncodeToBytes(block.HeaderNoCopy())
+		if err != nil {
+			return err
+		}
+		bodyRlp, err := rlp.EncodeToBytes(block.Body())
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, &shards.NewBlock{HeaderRlp: headerRlp, BodyRlp: bodyRlp, NewHead: newHead})
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	if isUnwind {
+		commonAncestor, _, err := h.blockReader.CanonicalHash(h.ctx, tx, from-1)
+		if err != nil {
+			return err
+		}
+		blocks[0].OldHead = oldHead
+		blocks[0].CommonAncestor = commonAncestor
+	}
+	h.notifications.Events.OnNewBlocks(blocks)
+	h.logger.Debug("[hook] notified new blocks", "from", from, "to", to, "reorg", isUnwind)
+	return nil
+}
+
+// notifyUnwind sends the hashes of the blocks of the old branch, from its head oldHead down to the block from, to the
+// subscribers of unwinds. These blocks are no longer canonical, but their headers are still stored.
+func (h *Hook) notifyUnwind(tx kv.Tx, oldHead common.Hash, from uint64) error {
+	var unwound []common.Hash
+	for hash := oldHead; hash != (common.Hash{}); {
+		header, err := h.blockReader.HeaderByHash(h.ctx, tx, hash)
+		if err != nil {
+			return err
+		}
+		if header == nil || header.Number.Uint64() < from {
+			break
+		}
+		canonical, ok, err := h.blockReader.CanonicalHash(h.ctx, tx, header.Number.Uint64())
+		if err != nil {
+			return err
+		}
+		if ok && canonical == hash { // the old head was re-applied, or the branches already joined
+			break
+		}
+		unwound = append(unwound, hash)
+		hash = header.ParentHash
+	}
+	if len(unwound) > 0 {
+		h.notifications.Events.OnUnwind(unwound)
+	}
+	return nil
+}
+
 func MiningStep(ctx context.Context, db kv.RwDB, mining *stagedsync.Sync, tmpDir string, logger log.Logger) (err error) {
 	defer func() {
 		if rec := recover(); rec != nil {
@@ -27,6 +27,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/c2h5oh/datasize"
 
@@ -152,6 +153,10 @@ type Config struct {
 	// empty string means not to start the listener
 	PrivateApiAddr      string
 	PrivateApiRateLimit uint32
+	// Events queued for each subscriber of the private api event streams, and how long they can stay full before
+	// the subscriber is disconnected. 0 for the defaults.
+	PrivateApiSubscribeQueueSize    int
+	PrivateApiSlowSubscriberTimeout time.Duration
 
 	staticNodesWarning  bool
 	trustedNodesWarning bool
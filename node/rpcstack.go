@@ -310,7 +310,7 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig, allowList rpc.All
 	}
 	h.wsConfig = config
 	h.wsHandler.Store(&rpcHandler{
-		Handler: srv.WebsocketHandler(config.Origins, nil, false, h.logger),
+		Handler: srv.WebsocketHandler(config.Origins, nil, rpc.WebsocketCompression{}, h.logger),
 		server:  srv,
 	})
 	return nil
@@ -538,7 +538,8 @@ type PeerInfo struct {
 		Trusted       bool   `json:"trusted"`
 		Static        bool   `json:"static"`
 	} `json:"network"`
-	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
+	Protocols map[string]interface{} `json:"protocols"`       // Sub-protocol specific metadata fields
+	Error     string                 `json:"error,omitempty"` // Set instead of the other fields for a sentry which couldn't list its peers
 }
 
 // Info gathers and returns a collection of metadata known about a peer.
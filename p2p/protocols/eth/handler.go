@@ -25,6 +25,7 @@ import (
 	"github.com/erigontech/erigon-db/rawdb"
 	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon-lib/kv"
 )
 
@@ -61,6 +62,16 @@ type NodeInfo struct {
 	Head       common.Hash   `json:"head"`       // Hex hash of the host's best owned block
 }
 
+// PeerInfo represents a short summary of the `eth` sub-protocol metadata known
+// about a connected peer.
+type PeerInfo struct {
+	Difficulty          *big.Int      `json:"difficulty,omitempty"`          // Total difficulty of the peer's blockchain
+	Head                *common.Hash  `json:"head,omitempty"`                // Hash of the peer's best owned block
+	ForkHash            hexutil.Bytes `json:"forkHash,omitempty"`            // Fork checksum advertised in the handshake
+	ForkNext            uint64        `json:"forkNext,omitempty"`            // Next fork advertised in the handshake, 0 if none
+	ForkIncompatibility string        `json:"forkIncompatibility,omitempty"` // Why the peer's fork ID is incompatible with ours
+}
+
 // ReadNodeInfo retrieves some `eth` protocol metadata about the running host node.
 func ReadNodeInfo(getter kv.Getter, config *chain.Config, genesisHash common.Hash, network uint64) *NodeInfo {
 	headHash := rawdb.ReadHeadHeaderHash(getter)
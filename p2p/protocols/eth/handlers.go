@@ -21,20 +21,293 @@ package eth
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 
 	"github.com/erigontech/erigon-db/rawdb"
 	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/empty"
+	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/dbutils"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/turbo/services"
 )
 
 func AnswerGetBlockHeadersQuery(db kv.Tx, query *GetBlockHeadersPacket, blockReader services.HeaderReader) ([]*types.Header, error) {
+	if query.Origin.Hash == (common.Hash{}) && !query.Reverse && query.Skip == 0 {
+		return answerGetBlockHeadersRange(db, query, blockReader)
+	}
+	if query.Reverse && query.Skip == 0 {
+		return answerGetBlockHeadersDescending(db, query, blockReader)
+	}
+	if query.Skip > 0 && (query.Origin.Hash == (common.Hash{}) || query.Reverse) {
+		return answerGetBlockHeadersSkip(db, query, blockReader)
+	}
+	return answerGetBlockHeadersLookups(db, query, blockReader)
+}
+
+// maxSkipQueryLookups bounds the reads of a query with skip, a canonical hash or a header each, the MaxHeadersServe
+// headers answering it taking at most three reads each.
+const maxSkipQueryLookups = 4 * MaxHeadersServe
+
+// answerGetBlockHeadersSkip answers a query with skip, the skeleton sync one, by number or, towards the genesis, by the
+// hash of a canonical header: the hash is resolved to its number once, and each hop is then a number, read from the
+// canonical hashes, instead of the ancestor walks of answerGetBlockHeadersLookups, which answers the non-canonical
+// hashes. The headers are the same as those of answerGetBlockHeadersLookups.
+func answerGetBlockHeadersSkip(db kv.Tx, query *GetBlockHeadersPacket, blockReader services.HeaderReader) ([]*types.Header, error) {
+	if query.Amount == 0 {
+		return nil, nil
+	}
+	lookups := 0
+	hashMode := query.Origin.Hash != (common.Hash{})
+	if hashMode {
+		origin, err := blockReader.HeaderByHash(context.Background(), db, query.Origin.Hash)
+		if err != nil || origin == nil {
+			return nil, err
+		}
+		number := origin.Number.Uint64()
+		canonicalOrigin, err := blockReader.HeaderByNumber(context.Background(), db, number)
+		if err != nil {
+			return nil, err
+		}
+		if canonicalOrigin == nil || canonicalOrigin.Hash() != query.Origin.Hash {
+			return answerGetBlockHeadersLookups(db, query, blockReader)
+		}
+		query.Origin.Number = number
+		lookups += 2
+	}
+
+	var (
+		bytes   common.StorageSize
+		headers []*types.Header
+	)
+	for len(headers) < int(query.Amount) && bytes < softResponseLimit && len(headers) < MaxHeadersServe &&
+		lookups < maxSkipQueryLookups {
+		number := query.Origin.Number
+		var header *types.Header
+		hash, err := rawdb.ReadCanonicalHash(db, number)
+		if err != nil {
+			return nil, err
+		}
+		lookups++
+		if hash != (common.Hash{}) {
+			header = rawdb.ReadHeader(db, hash, number)
+			lookups++
+		}
+		if header == nil { // frozen, and pruned from the db
+			if header, err = blockReader.HeaderByNumber(context.Background(), db, number); err != nil {
+				return nil, err
+			}
+			lookups++
+		}
+		if header == nil {
+			break
+		}
+		headers = append(headers, header)
+		bytes += estHeaderSize
+
+		// the hops are those of answerGetBlockHeadersLookups, on the canonical chain
+		if query.Reverse {
+			ancestor := number - (query.Skip + 1)
+			if ancestor >= number { // check for underflow
+				if hashMode {
+					query.Origin.Hash, query.Origin.Number = common.Hash{}, 0
+				}
+				return headers, nil
+			}
+			query.Origin.Number = ancestor
+		} else {
+			next := number + query.Skip + 1
+			if next <= number { // check for overflow
+				break
+			}
+			query.Origin.Number = next
+		}
+	}
+	if hashMode && len(headers) > 0 {
+		// as answerGetBlockHeadersLookups, which resolves the hash of the next header along with its number
+		hash, err := rawdb.ReadCanonicalHash(db, query.Origin.Number)
+		if err != nil {
+			return nil, err
+		}
+		if hash == (common.Hash{}) {
+			header, err := blockReader.HeaderByNumber(context.Background(), db, query.Origin.Number)
+			if err != nil {
+				return nil, err
+			}
+			if header != nil {
+				hash = header.Hash()
+			}
+		}
+		query.Origin.Hash = hash
+	}
+	return headers, nil
+}
+
+// answerGetBlockHeadersRange answers an ascending query by number without skip, the headers sync one: the canonical
+// hashes and the headers still in the db are read in one cursor walk, instead of a lookup per header, the others
+// through the blockReader. The headers are the same as those of answerGetBlockHeadersLookups.
+func answerGetBlockHeadersRange(db kv.Tx, query *GetBlockHeadersPacket, blockReader services.HeaderReader) ([]*types.Header, error) {
+	canonical, err := db.Cursor(kv.HeaderCanonical)
+	if err != nil {
+		return nil, err
+	}
+	defer canonical.Close()
+	headersC, err := db.Cursor(kv.Headers)
+	if err != nil {
+		return nil, err
+	}
+	defer headersC.Close()
+
+	var (
+		bytes   common.StorageSize
+		headers []*types.Header
+	)
+	k, v, err := canonical.Seek(hexutil.EncodeTs(query.Origin.Number))
+	if err != nil {
+		return nil, err
+	}
+	for len(headers) < int(query.Amount) && bytes < softResponseLimit && len(headers) < MaxHeadersServe {
+		number := query.Origin.Number
+		var header *types.Header
+		if k != nil && binary.BigEndian.Uint64(k) == number {
+			_, headerRLP, err := headersC.SeekExact(dbutils.HeaderKey(number, common.BytesToHash(v)))
+			if err != nil {
+				return nil, err
+			}
+			if len(headerRLP) > 0 {
+				header = new(types.Header)
+				if err := rlp.DecodeBytes(headerRLP, header); err != nil {
+					header = nil // as the blockReader, which logs it
+				}
+			}
+			if k, v, err = canonical.Next(); err != nil {
+				return nil, err
+			}
+		}
+		if header == nil { // frozen, and pruned from the db
+			if header, err = blockReader.HeaderByNumber(context.Background(), db, number); err != nil {
+				return nil, err
+			}
+		}
+		if header == nil {
+			break
+		}
+		headers = append(headers, header)
+		bytes += estHeaderSize
+		if number+1 <= number { // check for overflow
+			break
+		}
+		query.Origin.Number = number + 1
+	}
+	return headers, nil
+}
+
+// answerGetBlockHeadersDescending answers a descending query without skip, the PoS backfill one, by number or by the
+// hash of a canonical header: the canonical hashes and the headers still in the db are read in one backward cursor
+// walk, instead of a lookup per header, the others through the blockReader. The ancestors of a canonical header being
+// canonical, the headers are the same as those of answerGetBlockHeadersLookups, which answers the non-canonical hashes.
+func answerGetBlockHeadersDescending(db kv.Tx, query *GetBlockHeadersPacket, blockReader services.HeaderReader) ([]*types.Header, error) {
+	if query.Amount == 0 {
+		return nil, nil
+	}
+	hashMode := query.Origin.Hash != (common.Hash{})
+	if hashMode {
+		origin, err := blockReader.HeaderByHash(context.Background(), db, query.Origin.Hash)
+		if err != nil || origin == nil {
+			return nil, err
+		}
+		number := origin.Number.Uint64()
+		canonicalOrigin, err := blockReader.HeaderByNumber(context.Background(), db, number)
+		if err != nil {
+			return nil, err
+		}
+		if canonicalOrigin == nil || canonicalOrigin.Hash() != query.Origin.Hash {
+			return answerGetBlockHeadersLookups(db, query, blockReader)
+		}
+		query.Origin.Number = number
+	}
+
+	canonical, err := db.Cursor(kv.HeaderCanonical)
+	if err != nil {
+		return nil, err
+	}
+	defer canonical.Close()
+	headersC, err := db.Cursor(kv.Headers)
+	if err != nil {
+		return nil, err
+	}
+	defer headersC.Close()
+
+	var (
+		bytes   common.StorageSize
+		headers []*types.Header
+	)
+	// k is the highest canonical hash in the db at or below the number of the next header, nil if there is none
+	k, v, err := canonical.Seek(hexutil.EncodeTs(query.Origin.Number))
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		k, v, err = canonical.Last()
+	} else if binary.BigEndian.Uint64(k) > query.Origin.Number {
+		k, v, err = canonical.Prev()
+	}
+	if err != nil {
+		return nil, err
+	}
+	for len(headers) < int(query.Amount) && bytes < softResponseLimit && len(headers) < MaxHeadersServe {
+		number := query.Origin.Number
+		var header *types.Header
+		if k != nil && binary.BigEndian.Uint64(k) == number {
+			_, headerRLP, err := headersC.SeekExact(dbutils.HeaderKey(number, common.BytesToHash(v)))
+			if err != nil {
+				return nil, err
+			}
+			if len(headerRLP) > 0 {
+				header = new(types.Header)
+				if err := rlp.DecodeBytes(headerRLP, header); err != nil {
+					header = nil // as the blockReader, which logs it
+				}
+			}
+			if k, v, err = canonical.Prev(); err != nil {
+				return nil, err
+			}
+		}
+		if header == nil { // frozen, and pruned from the db
+			if header, err = blockReader.HeaderByNumber(context.Background(), db, number); err != nil {
+				return nil, err
+			}
+		}
+		if header == nil {
+			break
+		}
+		headers = append(headers, header)
+		bytes += estHeaderSize
+		if number == 0 { // check for underflow
+			break
+		}
+		query.Origin.Number = number - 1
+	}
+	if hashMode && len(headers) > 0 {
+		// as answerGetBlockHeadersLookups, which follows the parent hashes
+		if last := headers[len(headers)-1]; last.Number.Uint64() == 0 {
+			query.Origin.Hash, query.Origin.Number = common.Hash{}, 0
+		} else {
+			query.Origin.Hash, query.Origin.Number = last.ParentHash, last.Number.Uint64()-1
+		}
+	}
+	return headers, nil
+}
+
+// answerGetBlockHeadersLookups answers any query, looking up its headers one by one.
+func answerGetBlockHeadersLookups(db kv.Tx, query *GetBlockHeadersPacket, blockReader services.HeaderReader) ([]*types.Header, error) {
 	hashMode := query.Origin.Hash != (common.Hash{})
 	first := true
 	maxNonCanonical := uint64(100)
@@ -143,30 +416,94 @@ func AnswerGetBlockHeadersQuery(db kv.Tx, query *GetBlockHeadersPacket, blockRea
 	return headers, nil
 }
 
+// AnswerGetBlockBodiesQuery returns the bodies of the requested blocks, in the order of the request, skipping the
+// unknown ones. The runs of consecutive blocks are read at once where they're frozen, see FrozenBodiesRlp.
 func AnswerGetBlockBodiesQuery(db kv.Tx, query GetBlockBodiesPacket, blockReader services.HeaderAndBodyReader) []rlp.RawValue { //nolint:unparam
 	// Gather blocks until the fetch or network limits is reached
 	var bytes int
 	bodies := make([]rlp.RawValue, 0, len(query))
+	full := func() bool { return bytes >= softResponseLimit || len(bodies) >= MaxBodiesServe }
 
-	for lookups, hash := range query {
-		if bytes >= softResponseLimit || len(bodies) >= MaxBodiesServe ||
-			lookups >= 2*MaxBodiesServe {
-			break
-		}
-		number, _ := blockReader.HeaderNumber(context.Background(), db, hash)
-		if number == nil {
+	lookups := min(len(query), 2*MaxBodiesServe)
+	numbers := make([]*uint64, lookups)
+	for i, hash := range query[:lookups] {
+		numbers[i], _ = blockReader.HeaderNumber(context.Background(), db, hash)
+	}
+	for i := 0; i < lookups && !full(); {
+		if numbers[i] == nil {
+			i++
 			continue
 		}
-		bodyRLP, _ := blockReader.BodyRlp(context.Background(), db, hash, *number)
-		if len(bodyRLP) == 0 {
-			continue
+		run := 1
+		for i+run < lookups && numbers[i+run] != nil && *numbers[i+run] == *numbers[i]+uint64(run) {
+			run++
 		}
-		bodies = append(bodies, bodyRLP)
-		bytes += len(bodyRLP)
+		if run > 1 {
+			frozen, _ := blockReader.FrozenBodiesRlp(context.Background(), *numbers[i], min(run, MaxBodiesServe-len(bodies)))
+			for _, bodyRLP := range frozen {
+				if full() {
+					return bodies
+				}
+				bodies = append(bodies, bodyRLP)
+				bytes += len(bodyRLP)
+				i++
+			}
+			if len(frozen) > 0 {
+				continue
+			}
+		}
+		// scattered, or past the frozen blocks
+		bodyRLP, _ := blockReader.BodyRlp(context.Background(), db, query[i], *numbers[i])
+		if len(bodyRLP) > 0 {
+			bodies = append(bodies, bodyRLP)
+			bytes += len(bodyRLP)
+		}
+		i++
 	}
 	return bodies
 }
 
+// EncodeReceipts returns the encoding of the receipts of a block in the Receipts packet: a list of the receipts, the
+// typed ones wrapped in an RLP string.
+func EncodeReceipts(receipts types.Receipts) (rlp.RawValue, error) {
+	encoded, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode receipt: %w", err)
+	}
+	return encoded, nil
+}
+
+// SplitReceipts splits the receipts encoded by EncodeReceipts into their EIP-2718 consensus encodings, without
+// decoding them.
+func SplitReceipts(encoded rlp.RawValue) ([][]byte, error) {
+	content, _, err := rlp.SplitList(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var receipts [][]byte
+	for len(content) > 0 {
+		kind, value, rest, err := rlp.Split(content)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case rlp.List: // legacy receipt, the list itself
+			receipts = append(receipts, content[:len(content)-len(rest)])
+		case rlp.String: // typed receipt, the content of the string
+			receipts = append(receipts, value)
+		default:
+			return nil, fmt.Errorf("unexpected receipt encoding: %v", kind)
+		}
+		content = rest
+	}
+	return receipts, nil
+}
+
+var (
+	receiptsServedFromSnapshots = metrics.GetOrCreateCounter(`receipts_served{api="p2p",source="snapshot"}`)
+	receiptsServedRegenerated   = metrics.GetOrCreateCounter(`receipts_served{api="p2p",source="regenerated"}`)
+)
+
 type ReceiptsGetter interface {
 	GetReceipts(ctx context.Context, cfg *chain.Config, tx kv.TemporalTx, block *types.Block) (types.Receipts, error)
 	GetCachedReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, bool)
@@ -193,8 +530,8 @@ func AnswerGetReceiptsQueryCacheOnly(ctx context.Context, receiptsGetter Receipt
 			break
 		}
 		if receipts, ok := receiptsGetter.GetCachedReceipts(ctx, hash); ok {
-			if encoded, err := rlp.EncodeToBytes(receipts); err != nil {
-				return nil, needMore, fmt.Errorf("failed to encode receipt: %w", err)
+			if encoded, err := EncodeReceipts(receipts); err != nil {
+				return nil, needMore, err
 			} else {
 				receiptsList = append(receiptsList, encoded)
 				bytes += len(encoded)
@@ -227,6 +564,12 @@ func AnswerGetReceiptsQuery(ctx context.Context, cfg *chain.Config, receiptsGett
 		receipts = cachedReceipts.EncodedReceipts
 		pendingIndex = cachedReceipts.PendingIndex
 	}
+	txNumReader := rawdbv3.TxNums
+	if r, ok := br.(interface {
+		TxnumReader(ctx context.Context) rawdbv3.TxNumsReader
+	}); ok {
+		txNumReader = r.TxnumReader(ctx)
+	}
 
 	for lookups := pendingIndex; lookups < len(query); lookups++ {
 		hash := query[lookups]
@@ -247,10 +590,19 @@ func AnswerGetReceiptsQuery(ctx context.Context, cfg *chain.Config, receiptsGett
 			return nil, nil
 		}
 
-		results, err := receiptsGetter.GetReceipts(ctx, cfg, db, b)
+		// the deep blocks have their receipts in the snapshot files, no need to re-execute them
+		results, frozen, err := rawdb.ReadFrozenReceipts(db, b, txNumReader)
 		if err != nil {
 			return nil, err
 		}
+		if frozen {
+			receiptsServedFromSnapshots.Inc()
+		} else {
+			if results, err = receiptsGetter.GetReceipts(ctx, cfg, db, b); err != nil {
+				return nil, err
+			}
+			receiptsServedRegenerated.Inc()
+		}
 
 		if results == nil {
 			header, err := rawdb.ReadHeaderByHash(db, hash)
@@ -268,8 +620,8 @@ func AnswerGetReceiptsQuery(ctx context.Context, cfg *chain.Config, receiptsGett
 		//}
 
 		// If known, encode and queue for response packet
-		if encoded, err := rlp.EncodeToBytes(results); err != nil {
-			return nil, fmt.Errorf("failed to encode receipt: %w", err)
+		if encoded, err := EncodeReceipts(results); err != nil {
+			return nil, err
 		} else {
 			receipts = append(receipts, encoded)
 			bytes += len(encoded)
@@ -0,0 +1,278 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-db/rawdb"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/polygon/heimdall"
+	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
+)
+
+// headersTestTx returns a tx of a db holding a canonical chain of n headers, with a non-canonical sibling for each of
+// them, and a reader without snapshots.
+func headersTestTx(tb testing.TB, n int) (kv.RwTx, services.HeaderReader) {
+	tb.Helper()
+	dirs := datadir.New(tb.TempDir())
+	tx := memdb.BeginRw(tb, memdb.NewTestDB(tb, kv.ChainDB))
+	var parent *types.Header
+	for i := 0; i < n; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Difficulty: big.NewInt(1), GasLimit: 30_000_000, Extra: []byte("canonical")}
+		if parent != nil {
+			header.ParentHash = parent.Hash()
+		}
+		sibling := types.CopyHeader(header)
+		sibling.Extra = []byte("sibling")
+		require.NoError(tb, rawdb.WriteHeader(tx, header))
+		require.NoError(tb, rawdb.WriteHeader(tx, sibling))
+		require.NoError(tb, rawdb.WriteCanonicalHash(tx, header.Hash(), header.Number.Uint64()))
+		parent = header
+	}
+	cfg := ethconfig.Defaults.Snapshot
+	blockReader := freezeblocks.NewBlockReader(freezeblocks.NewRoSnapshots(cfg, dirs.Snap, 0, log.New()), heimdall.NewRoSnapshots(cfg, dirs.Snap, 0, log.New()), nil, nil)
+	return tx, blockReader
+}
+
+func TestAnswerGetBlockHeadersRange(t *testing.T) {
+	t.Parallel()
+	tx, blockReader := headersTestTx(t, 100)
+	for _, query := range []GetBlockHeadersPacket{
+		{Origin: HashOrNumber{Number: 0}, Amount: 50},
+		{Origin: HashOrNumber{Number: 90}, Amount: 20}, // past the head
+		{Origin: HashOrNumber{Number: 200}, Amount: 10},
+		{Origin: HashOrNumber{Number: 0}, Amount: 2 * MaxHeadersServe},
+		{Origin: HashOrNumber{Number: 10}, Amount: 0},
+	} {
+		rangeQuery, lookupsQuery := query, query
+		inRange, err := answerGetBlockHeadersRange(tx, &rangeQuery, blockReader)
+		require.NoError(t, err)
+		looked, err := answerGetBlockHeadersLookups(tx, &lookupsQuery, blockReader)
+		require.NoError(t, err)
+
+		expected, err := rlp.EncodeToBytes(looked)
+		require.NoError(t, err)
+		got, err := rlp.EncodeToBytes(inRange)
+		require.NoError(t, err)
+		require.Equal(t, expected, got, "query %+v", query)
+		require.Equal(t, lookupsQuery, rangeQuery, "query %+v", query)
+		for _, header := range inRange {
+			require.Equal(t, []byte("canonical"), header.Extra)
+		}
+	}
+}
+
+func TestAnswerGetBlockHeadersDescending(t *testing.T) {
+	t.Parallel()
+	tx, blockReader := headersTestTx(t, 100)
+	hash := func(number uint64) common.Hash {
+		hash, err := rawdb.ReadCanonicalHash(tx, number)
+		require.NoError(t, err)
+		return hash
+	}
+	header50, err := blockReader.HeaderByNumber(context.Background(), tx, 50)
+	require.NoError(t, err)
+	sibling50 := types.CopyHeader(header50)
+	sibling50.Extra = []byte("sibling")
+
+	for _, query := range []GetBlockHeadersPacket{
+		{Origin: HashOrNumber{Number: 99}, Amount: 50, Reverse: true},
+		{Origin: HashOrNumber{Number: 10}, Amount: 20, Reverse: true}, // past the genesis
+		{Origin: HashOrNumber{Number: 200}, Amount: 10, Reverse: true},
+		{Origin: HashOrNumber{Number: 99}, Amount: 2 * MaxHeadersServe, Reverse: true},
+		{Origin: HashOrNumber{Number: 10}, Amount: 0, Reverse: true},
+		{Origin: HashOrNumber{Hash: hash(50)}, Amount: 20, Reverse: true},
+		{Origin: HashOrNumber{Hash: hash(5)}, Amount: 20, Reverse: true},
+		{Origin: HashOrNumber{Hash: hash(99)}, Amount: 0, Reverse: true},
+		{Origin: HashOrNumber{Hash: sibling50.Hash()}, Amount: 10, Reverse: true}, // non-canonical
+		{Origin: HashOrNumber{Hash: common.Hash{1}}, Amount: 10, Reverse: true},
+	} {
+		descendingQuery, lookupsQuery := query, query
+		descending, err := answerGetBlockHeadersDescending(tx, &descendingQuery, blockReader)
+		require.NoError(t, err)
+		looked, err := answerGetBlockHeadersLookups(tx, &lookupsQuery, blockReader)
+		require.NoError(t, err)
+
+		expected, err := rlp.EncodeToBytes(looked)
+		require.NoError(t, err)
+		got, err := rlp.EncodeToBytes(descending)
+		require.NoError(t, err)
+		require.Equal(t, expected, got, "query %+v", query)
+		require.Equal(t, lookupsQuery, descendingQuery, "query %+v", query)
+	}
+}
+
+func TestAnswerGetBlockHeadersSkip(t *testing.T) {
+	t.Parallel()
+	tx, blockReader := headersTestTx(t, 100)
+	hash := func(number uint64) common.Hash {
+		hash, err := rawdb.ReadCanonicalHash(tx, number)
+		require.NoError(t, err)
+		return hash
+	}
+	header50, err := blockReader.HeaderByNumber(context.Background(), tx, 50)
+	require.NoError(t, err)
+	sibling50 := types.CopyHeader(header50)
+	sibling50.Extra = []byte("sibling")
+
+	var queries []GetBlockHeadersPacket
+	for _, skip := range []uint64{0, 1, 3, 9, 49, 98, 99, 100, math.MaxUint64} {
+		for _, reverse := range []bool{false, true} {
+			for _, origin := range []HashOrNumber{
+				{Number: 0},
+				{Number: 50},
+				{Number: 99},
+				{Number: 200},
+				{Hash: hash(0)},
+				{Hash: hash(50)},
+				{Hash: hash(99)},
+				{Hash: sibling50.Hash()}, // non-canonical
+				{Hash: common.Hash{1}},
+			} {
+				for _, amount := range []uint64{0, 1, 5, 2 * MaxHeadersServe} {
+					queries = append(queries, GetBlockHeadersPacket{Origin: origin, Amount: amount, Skip: skip, Reverse: reverse})
+				}
+			}
+		}
+	}
+	for _, query := range queries {
+		answerQuery, lookupsQuery := query, query
+		answer, err := AnswerGetBlockHeadersQuery(tx, &answerQuery, blockReader)
+		require.NoError(t, err)
+		looked, err := answerGetBlockHeadersLookups(tx, &lookupsQuery, blockReader)
+		require.NoError(t, err)
+
+		expected, err := rlp.EncodeToBytes(looked)
+		require.NoError(t, err)
+		got, err := rlp.EncodeToBytes(answer)
+		require.NoError(t, err)
+		require.Equal(t, expected, got, "query %+v", query)
+		require.Equal(t, lookupsQuery, answerQuery, "query %+v", query)
+	}
+
+	// a side fork origin is answered up to the fork point, towards the leaf, and from it, towards the genesis
+	query := &GetBlockHeadersPacket{Origin: HashOrNumber{Hash: sibling50.Hash()}, Amount: 5, Skip: 9}
+	headers, err := AnswerGetBlockHeadersQuery(tx, query, blockReader)
+	require.NoError(t, err)
+	require.Len(t, headers, 1)
+	require.Equal(t, sibling50.Hash(), headers[0].Hash())
+	query = &GetBlockHeadersPacket{Origin: HashOrNumber{Hash: sibling50.Hash()}, Amount: 5, Skip: 9, Reverse: true}
+	headers, err = AnswerGetBlockHeadersQuery(tx, query, blockReader)
+	require.NoError(t, err)
+	require.Len(t, headers, 5)
+	require.Equal(t, sibling50.Hash(), headers[0].Hash())
+	for i, header := range headers[1:] {
+		require.Equal(t, hash(uint64(40-10*i)), header.Hash())
+	}
+}
+
+func BenchmarkAnswerGetBlockHeadersSkip(b *testing.B) {
+	tx, blockReader := headersTestTx(b, 2*MaxHeadersServe)
+	origin, err := rawdb.ReadCanonicalHash(tx, 2*MaxHeadersServe-1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, bench := range []struct {
+		name   string
+		answer func(kv.Tx, *GetBlockHeadersPacket, services.HeaderReader) ([]*types.Header, error)
+	}{
+		{"skip", answerGetBlockHeadersSkip},
+		{"lookups", answerGetBlockHeadersLookups},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				query := &GetBlockHeadersPacket{Origin: HashOrNumber{Hash: origin}, Amount: 64, Skip: 31, Reverse: true}
+				headers, err := bench.answer(tx, query, blockReader)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(headers) != 64 {
+					b.Fatalf("got %d headers", len(headers))
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkAnswerGetBlockHeadersQuery(b *testing.B) {
+	tx, blockReader := headersTestTx(b, 2*MaxHeadersServe)
+	for _, bench := range []struct {
+		name   string
+		answer func(kv.Tx, *GetBlockHeadersPacket, services.HeaderReader) ([]*types.Header, error)
+	}{
+		{"range", answerGetBlockHeadersRange},
+		{"lookups", answerGetBlockHeadersLookups},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				query := &GetBlockHeadersPacket{Origin: HashOrNumber{Number: MaxHeadersServe}, Amount: MaxHeadersServe}
+				headers, err := bench.answer(tx, query, blockReader)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(headers) != MaxHeadersServe {
+					b.Fatalf("got %d headers", len(headers))
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkAnswerGetBlockHeadersReverse(b *testing.B) {
+	tx, blockReader := headersTestTx(b, 2*MaxHeadersServe)
+	origin, err := rawdb.ReadCanonicalHash(tx, 2*MaxHeadersServe-1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, bench := range []struct {
+		name   string
+		answer func(kv.Tx, *GetBlockHeadersPacket, services.HeaderReader) ([]*types.Header, error)
+	}{
+		{"descending", answerGetBlockHeadersDescending},
+		{"lookups", answerGetBlockHeadersLookups},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				query := &GetBlockHeadersPacket{Origin: HashOrNumber{Hash: origin}, Amount: MaxHeadersServe, Reverse: true}
+				headers, err := bench.answer(tx, query, blockReader)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(headers) != MaxHeadersServe {
+					b.Fatalf("got %d headers", len(headers))
+				}
+			}
+		})
+	}
+}
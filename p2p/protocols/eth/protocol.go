@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"strconv"
+	"strings"
 
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/direct"
@@ -126,6 +128,39 @@ var FromProto = map[uint]map[proto_sentry.MessageId]uint64{
 	},
 }
 
+// NegotiatedVersion returns the version of the eth protocol a peer negotiated with us, the highest of the versions
+// among its capabilities caps, e.g. "eth/68", which we speak.
+func NegotiatedVersion(caps []string) (version uint, ok bool) {
+	for _, c := range caps {
+		name, v, found := strings.Cut(c, "/")
+		if !found || name != ProtocolName {
+			continue
+		}
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			continue
+		}
+		if _, known := ToProto[uint(n)]; known && uint(n) > version {
+			version, ok = uint(n), true
+		}
+	}
+	return version, ok
+}
+
+// MessageID returns the id of the message msg, e.g. NewPooledTransactionHashesMsg, in the version of the protocol,
+// which selects its encoding. ok is false if the version has no such message.
+func MessageID(version uint, msg uint64) (id proto_sentry.MessageId, ok bool) {
+	id, ok = ToProto[version][msg]
+	return id, ok
+}
+
+// MessageCode returns the message of the id in the version of the protocol, which decodes it. ok is false if the
+// message isn't one of the version, e.g. NEW_POOLED_TRANSACTION_HASHES_66 in eth/68.
+func MessageCode(version uint, id proto_sentry.MessageId) (msg uint64, ok bool) {
+	msg, ok = FromProto[version][id]
+	return msg, ok
+}
+
 // Packet represents a p2p message in the `eth` protocol.
 type Packet interface {
 	Name() string // Name returns a string corresponding to the message type.
@@ -363,6 +398,25 @@ type ReceiptsRLPPacket66 struct {
 	ReceiptsRLPPacket
 }
 
+// GetPooledTransactionsPacket represents a transaction query.
+type GetPooledTransactionsPacket []common.Hash
+
+// GetPooledTransactionsPacket66 represents a transaction query over eth/66.
+type GetPooledTransactionsPacket66 struct {
+	RequestId uint64
+	GetPooledTransactionsPacket
+}
+
+// PooledTransactionsRLPPacket is used for replying to transaction queries, in cases
+// where we already have the transactions RLP-encoded.
+type PooledTransactionsRLPPacket []rlp.RawValue
+
+// PooledTransactionsRLPPacket66 is the PooledTransactionsRLPPacket over eth/66
+type PooledTransactionsRLPPacket66 struct {
+	RequestId uint64
+	PooledTransactionsRLPPacket
+}
+
 func (*StatusPacket) Name() string { return "Status" }
 func (*StatusPacket) Kind() byte   { return StatusMsg }
 
@@ -389,3 +443,9 @@ func (*GetReceiptsPacket) Kind() byte   { return GetReceiptsMsg }
 
 func (*ReceiptsPacket) Name() string { return "Receipts" }
 func (*ReceiptsPacket) Kind() byte   { return ReceiptsMsg }
+
+func (*GetPooledTransactionsPacket) Name() string { return "GetPooledTransactions" }
+func (*GetPooledTransactionsPacket) Kind() byte   { return GetPooledTransactionsMsg }
+
+func (*PooledTransactionsRLPPacket) Name() string { return "PooledTransactions" }
+func (*PooledTransactionsRLPPacket) Kind() byte   { return PooledTransactionsMsg }
@@ -24,7 +24,11 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/direct"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types"
 )
@@ -230,3 +234,39 @@ func TestEth66Messages(t *testing.T) {
 		}
 	}
 }
+
+func TestNegotiatedVersionEncodings(t *testing.T) {
+	for _, tt := range []struct {
+		caps    []string
+		version uint
+		ok      bool
+	}{
+		{caps: []string{"eth/67"}, version: direct.ETH67, ok: true},
+		{caps: []string{"eth/67", "eth/68", "snap/1"}, version: direct.ETH68, ok: true},
+		{caps: []string{"eth/68", "eth/70"}, version: direct.ETH68, ok: true}, // not spoken by us
+		{caps: []string{"snap/1", "eth/x"}},
+		{},
+	} {
+		version, ok := NegotiatedVersion(tt.caps)
+		require.Equal(t, tt.ok, ok, tt.caps)
+		require.Equal(t, tt.version, version, tt.caps)
+	}
+
+	// the announcements of the transactions are encoded per version
+	id, ok := MessageID(direct.ETH67, NewPooledTransactionHashesMsg)
+	require.True(t, ok)
+	require.Equal(t, proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_66, id)
+	id, ok = MessageID(direct.ETH68, NewPooledTransactionHashesMsg)
+	require.True(t, ok)
+	require.Equal(t, proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68, id)
+	_, ok = MessageID(direct.ETH66, NewPooledTransactionHashesMsg)
+	require.False(t, ok)
+
+	msg, ok := MessageCode(direct.ETH68, proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68)
+	require.True(t, ok)
+	require.Equal(t, uint64(NewPooledTransactionHashesMsg), msg)
+	_, ok = MessageCode(direct.ETH68, proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_66)
+	require.False(t, ok)
+	_, ok = MessageCode(direct.ETH67, proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68)
+	require.False(t, ok)
+}
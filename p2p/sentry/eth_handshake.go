@@ -19,8 +19,10 @@ package sentry
 import (
 	"fmt"
 
+	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/gointerfaces"
 	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon/execution/chainspec"
 	"github.com/erigontech/erigon/p2p"
 	"github.com/erigontech/erigon/p2p/forkid"
 	"github.com/erigontech/erigon/p2p/protocols/eth"
@@ -76,7 +78,7 @@ func checkPeerStatusCompatibility(
 ) error {
 	networkID := status.NetworkId
 	if reply.NetworkID != networkID {
-		return fmt.Errorf("network id does not match: theirs %d, ours %d", reply.NetworkID, networkID)
+		return fmt.Errorf("network id does not match: theirs %s, ours %s", describeNetworkID(reply.NetworkID), describeNetworkID(networkID))
 	}
 
 	if uint(reply.ProtocolVersion) > version {
@@ -88,9 +90,25 @@ func checkPeerStatusCompatibility(
 
 	genesisHash := gointerfaces.ConvertH256ToHash(status.ForkData.Genesis)
 	if reply.Genesis != genesisHash {
-		return fmt.Errorf("genesis hash does not match: theirs %x, ours %x", reply.Genesis, genesisHash)
+		return fmt.Errorf("genesis hash does not match: peer is on %s, we are on %s", describeGenesis(reply.Genesis), describeGenesis(genesisHash))
 	}
 
 	forkFilter := forkid.NewFilterFromForks(status.ForkData.HeightForks, status.ForkData.TimeForks, genesisHash, status.MaxBlockHeight, status.MaxBlockTime)
 	return forkFilter(reply.ForkID)
 }
+
+// describeNetworkID returns the network id along with the name of the known chain using it, if any.
+func describeNetworkID(networkID uint64) string {
+	if name, ok := chainspec.NetworkNameByID[networkID]; ok {
+		return fmt.Sprintf("%d (%s)", networkID, name)
+	}
+	return fmt.Sprintf("%d", networkID)
+}
+
+// describeGenesis returns the name of the known chain with the given genesis hash, or the hash itself.
+func describeGenesis(hash common.Hash) string {
+	if name, ok := chainspec.ChainNameByGenesisHash(hash); ok {
+		return fmt.Sprintf("%s (%x)", name, hash)
+	}
+	return fmt.Sprintf("%x", hash)
+}
@@ -88,6 +88,21 @@ func TestCheckPeerStatusCompatibility(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "genesis")
 	})
+	t.Run("genesis mismatch names known chains", func(t *testing.T) {
+		reply := goodReply
+		reply.Genesis = chainspec.SepoliaGenesisHash
+		err := checkPeerStatusCompatibility(&reply, &status, version, version)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "peer is on sepolia")
+		assert.Contains(t, err.Error(), "we are on mainnet")
+	})
+	t.Run("network mismatch names known chains", func(t *testing.T) {
+		reply := goodReply
+		reply.NetworkID = chainspec.SepoliaChainID
+		err := checkPeerStatusCompatibility(&reply, &status, version, version)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "theirs 11155111 (sepolia), ours 1 (mainnet)")
+	})
 	t.Run("fork mismatch", func(t *testing.T) {
 		reply := goodReply
 		reply.ForkID = forkid.ID{}
@@ -79,6 +79,7 @@ type PeerInfo struct {
 	height        uint64
 	rw            p2p.MsgReadWriter
 	protocol      uint
+	forkID        forkid.ID // advertised in the handshake
 
 	ctx       context.Context
 	ctxCancel context.CancelFunc
@@ -298,7 +299,7 @@ func handShake(
 	rw p2p.MsgReadWriter,
 	version uint,
 	minVersion uint,
-) (*common.Hash, *p2p.PeerError) {
+) (*eth.StatusPacket, *p2p.PeerError) {
 	// Send out own handshake in a new thread
 	errChan := make(chan *p2p.PeerError, 2)
 	resultChan := make(chan *eth.StatusPacket, 1)
@@ -353,8 +354,7 @@ func handShake(
 		}
 	}
 
-	peerStatus := <-resultChan
-	return &peerStatus.Head, nil
+	return <-resultChan, nil
 }
 
 func runPeer(
@@ -636,10 +636,11 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 				return p2p.NewPeerError(p2p.PeerErrorLocalStatusNeeded, p2p.DiscProtocolError, nil, "could not get status message from core")
 			}
 
-			peerBestHash, err := handShake(ctx, status, rw, protocol, protocol)
+			peerStatus, err := handShake(ctx, status, rw, protocol, protocol)
 			if err != nil {
 				return err
 			}
+			peerInfo.forkID = peerStatus.ForkID
 
 			// handshake is successful
 			logger.Trace("[p2p] Received status message OK", "peerId", printablePeerID, "name", peer.Name())
@@ -647,7 +648,7 @@ func NewGrpcServer(ctx context.Context, dialCandidates func() enode.Iterator, re
 			ss.GoodPeers.Store(peerID, peerInfo)
 			ss.sendNewPeerToClients(gointerfaces.ConvertHashToH512(peerID))
 			defer ss.sendGonePeerToClients(gointerfaces.ConvertHashToH512(peerID))
-			getBlockHeadersErr := ss.getBlockHeaders(ctx, *peerBestHash, peerID)
+			getBlockHeadersErr := ss.getBlockHeaders(ctx, peerStatus.Head, peerID)
 			if getBlockHeadersErr != nil {
 				return p2p.NewPeerError(p2p.PeerErrorFirstMessageSend, p2p.DiscNetworkError, getBlockHeadersErr, "p2p.Protocol.Run getBlockHeaders failure")
 			}
@@ -1086,6 +1087,11 @@ func (ss *GrpcServer) Peers(_ context.Context, _ *emptypb.Empty) (*proto_sentry.
 			ConnIsTrusted:  peer.Network.Trusted,
 			ConnIsStatic:   peer.Network.Static,
 		}
+		if pubKey, err := hex.DecodeString(peer.ID); err == nil && len(pubKey) == 64 {
+			if sentryPeer := ss.getPeer([64]byte(pubKey)); sentryPeer != nil {
+				rpcPeer.ForkHash, rpcPeer.ForkNext = sentryPeer.forkID.Hash[:], sentryPeer.forkID.Next
+			}
+		}
 		reply.Peers = append(reply.Peers, &rpcPeer)
 	}
 
@@ -1130,6 +1136,8 @@ func (ss *GrpcServer) PeerById(_ context.Context, req *proto_sentry.PeerByIdRequ
 			ConnIsInbound:  peer.Network.Inbound,
 			ConnIsTrusted:  peer.Network.Trusted,
 			ConnIsStatic:   peer.Network.Static,
+			ForkHash:       sentryPeer.forkID.Hash[:],
+			ForkNext:       sentryPeer.forkID.Next,
 		}
 	}
 
@@ -19,12 +19,18 @@ package sentry_multi_client
 import (
 	"context"
 	"errors"
+	"math"
 	"math/big"
+	"math/rand/v2"
 	"strings"
 	"syscall"
 
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
 	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/rlp"
@@ -35,74 +41,173 @@ import (
 )
 
 func (cs *MultiClient) PropagateNewBlockHashes(ctx context.Context, announces []headerdownload.Announce) {
-	typedRequest := make(eth.NewBlockHashesPacket, len(announces))
-	for i := range announces {
-		typedRequest[i].Hash = announces[i].Hash
-		typedRequest[i].Number = announces[i].Number
-	}
-
-	data, err := rlp.EncodeToBytes(&typedRequest)
+	req66, err := newBlockHashesMessage(announces)
 	if err != nil {
 		log.Error("propagateNewBlockHashes", "err", err)
 		return
 	}
 
-	req66 := proto_sentry.OutboundMessageData{
-		Id:   proto_sentry.MessageId_NEW_BLOCK_HASHES_66,
-		Data: data,
+	hashes := make([]common.Hash, len(announces))
+	for i := range announces {
+		hashes[i] = announces[i].Hash
 	}
-
-	for _, sentry := range cs.sentries {
+	for i, sentry := range cs.sentries {
 		if ready, ok := sentry.(interface{ Ready() bool }); ok && !ready.Ready() {
 			continue
 		}
 
-		_, err = sentry.SendMessageToAll(ctx, &req66, &grpc.EmptyCallOption{})
+		_, err = sentry.SendMessageToAll(ctx, req66, &grpc.EmptyCallOption{})
 		if err != nil {
 			log.Error("propagateNewBlockHashes", "err", err)
+			continue
 		}
+		cs.blockPropagation.sent(hashes, i, cs.logger)
 	}
+	cs.blockPropagation.finished(hashes, cs.logger)
 }
 
+func newBlockHashesMessage(announces []headerdownload.Announce) (*proto_sentry.OutboundMessageData, error) {
+	typedRequest := make(eth.NewBlockHashesPacket, len(announces))
+	for i := range announces {
+		typedRequest[i].Hash = announces[i].Hash
+		typedRequest[i].Number = announces[i].Number
+	}
+
+	data, err := rlp.EncodeToBytes(&typedRequest)
+	if err != nil {
+		return nil, err
+	}
+	return &proto_sentry.OutboundMessageData{
+		Id:   proto_sentry.MessageId_NEW_BLOCK_HASHES_66,
+		Data: data,
+	}, nil
+}
+
+// BroadcastNewBlock propagates the block to the peers of the sentries the way of the eth protocol: the full block, in a
+// NewBlock message, to the square root of the peers, at most maxBlockBroadcastPeers of them unless it's 0, and its hash
+// to the rest, as far as the gossip of its era allows, see propagation. The peers which sent us the block, whose head it
+// is, are skipped, as are the peers whose version of the eth protocol has neither message.
 func (cs *MultiClient) BroadcastNewBlock(ctx context.Context, header *types.Header, body *types.RawBody, td *big.Int) {
+	propagateNewBlock, propagateHashes := cs.propagation(header)
+	if !propagateNewBlock && !propagateHashes {
+		return
+	}
 	block, err := types.RawBlock{Header: header, Body: body}.AsBlock()
-
 	if err != nil {
 		log.Error("broadcastNewBlock", "err", err)
+		return
 	}
 
 	data, err := rlp.EncodeToBytes(&eth.NewBlockPacket{
 		Block: block,
 		TD:    td,
 	})
-
 	if err != nil {
 		log.Error("broadcastNewBlock", "err", err)
 		return
 	}
-
-	req66 := proto_sentry.SendMessageToRandomPeersRequest{
-		MaxPeers: uint64(cs.maxBlockBroadcastPeers(header)),
-		Data: &proto_sentry.OutboundMessageData{
-			Id:   proto_sentry.MessageId_NEW_BLOCK_66,
-			Data: data,
-		},
+	hashes, err := newBlockHashesMessage([]headerdownload.Announce{{Number: block.NumberU64(), Hash: block.Hash()}})
+	if err != nil {
+		log.Error("broadcastNewBlock", "err", err)
+		return
 	}
 
-	for _, sentry := range cs.sentries {
+	sent := map[[64]byte]struct{}{} // a peer connected to several sentries gets the block once
+	defer cs.blockPropagation.finished([]common.Hash{block.Hash()}, cs.logger)
+	for sentryIndex, sentry := range cs.sentries {
 		if ready, ok := sentry.(interface{ Ready() bool }); ok && !ready.Ready() {
 			continue
 		}
 
-		_, err = sentry.SendMessageToRandomPeers(ctx, &req66, &grpc.EmptyCallOption{})
+		reply, err := sentry.Peers(ctx, &emptypb.Empty{})
 		if err != nil {
-			if isPeerNotFoundErr(err) || networkTemporaryErr(err) {
-				log.Debug("broadcastNewBlock", "err", err)
+			log.Debug("broadcastNewBlock", "err", err)
+			continue
+		}
+		peers := make([]broadcastPeer, 0, len(reply.Peers))
+		for _, peer := range reply.Peers {
+			peerID, ok := peerIDFromHex(peer.Id)
+			if !ok {
 				continue
 			}
-			log.Error("broadcastNewBlock", "err", err)
+			if _, ok := sent[peerID]; ok {
+				continue
+			}
+			if head, ok := cs.peerHeads.Load(peerID); ok && head.(peerHead).hash == block.Hash() {
+				continue
+			}
+			version, ok := eth.NegotiatedVersion(peer.Caps)
+			if !ok {
+				if version, ok = cs.peerVersion(peerID); !ok {
+					version = direct.ETH67
+				}
+			}
+			newBlockID, hasNewBlock := eth.MessageID(version, eth.NewBlockMsg)
+			hashesID, hasHashes := eth.MessageID(version, eth.NewBlockHashesMsg)
+			if !hasNewBlock && !hasHashes { // a version without the block gossip
+				continue
+			}
+			peers = append(peers, broadcastPeer{peerID, newBlockID, hasNewBlock, hashesID, hasHashes})
 		}
+		rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+
+		full := 0
+		if propagateNewBlock {
+			full = cs.newBlockPeers(header, len(peers))
+		}
+		announced := false
+		for i, peer := range peers {
+			if i >= full && !propagateHashes {
+				break
+			}
+			var msg *proto_sentry.OutboundMessageData
+			switch {
+			case i < full && peer.hasNewBlock:
+				msg = &proto_sentry.OutboundMessageData{Id: peer.newBlockID, Data: data}
+			case propagateHashes && peer.hasHashes:
+				msg = &proto_sentry.OutboundMessageData{Id: peer.hashesID, Data: hashes.Data}
+			default:
+				continue
+			}
+			sent[peer.id] = struct{}{}
+			req := &proto_sentry.SendMessageByIdRequest{PeerId: gointerfaces.ConvertHashToH512(peer.id), Data: msg}
+			if _, err = sentry.SendMessageById(ctx, req, &grpc.EmptyCallOption{}); err != nil {
+				if isPeerNotFoundErr(err) || networkTemporaryErr(err) {
+					log.Debug("broadcastNewBlock", "err", err)
+					continue
+				}
+				log.Error("broadcastNewBlock", "err", err)
+				continue
+			}
+			announced = true
+		}
+		if announced {
+			cs.blockPropagation.sent([]common.Hash{block.Hash()}, sentryIndex, cs.logger)
+		}
+	}
+}
+
+// broadcastPeer is a peer BroadcastNewBlock propagates a block to, with the ids of the messages of the version of the
+// eth protocol it negotiated.
+type broadcastPeer struct {
+	id          [64]byte
+	newBlockID  proto_sentry.MessageId
+	hasNewBlock bool
+	hashesID    proto_sentry.MessageId
+	hasHashes   bool
+}
+
+// newBlockPeers returns how many of the peers get the full block of header, the others get its hash.
+func (cs *MultiClient) newBlockPeers(header *types.Header, peers int) int {
+	full := int(math.Sqrt(float64(peers)))
+	if cs.maxBlockBroadcastPeers == nil {
+		return full
+	}
+	maxPeers := int(cs.maxBlockBroadcastPeers(header))
+	if maxPeers == 0 { // 0 means all
+		return peers
 	}
+	return min(full, maxPeers)
 }
 
 func networkTemporaryErr(err error) bool {
@@ -0,0 +1,111 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	proto_types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
+	"github.com/erigontech/erigon-lib/metrics"
+	"github.com/erigontech/erigon/p2p/forkid"
+)
+
+// forkIncompatibility is the reason why the fork ID advertised by a peer is incompatible with ours.
+type forkIncompatibility string
+
+const (
+	// forkStale is a peer on our chain which didn't update for a fork we passed.
+	forkStale forkIncompatibility = "stale"
+	// forkFuture is a peer in our fork state announcing a next fork which we passed without being aware of it.
+	forkFuture forkIncompatibility = "future fork"
+	// forkDifferentChain is a peer whose fork checksum is none of ours, e.g. with another genesis.
+	forkDifferentChain forkIncompatibility = "different chain"
+)
+
+var forkIncompatiblePeers = map[forkIncompatibility]metrics.Counter{
+	forkStale:          metrics.GetOrCreateCounter(`p2p_fork_incompatible_peers{reason="stale"}`),
+	forkFuture:         metrics.GetOrCreateCounter(`p2p_fork_incompatible_peers{reason="future_fork"}`),
+	forkDifferentChain: metrics.GetOrCreateCounter(`p2p_fork_incompatible_peers{reason="different_chain"}`),
+}
+
+// forkIDIncompatibility validates the fork ID id advertised by a peer against the fork ID set of our chain at our head
+// in status, following EIP-2124. It returns the reason why id is incompatible, if it is.
+func forkIDIncompatibility(status *proto_sentry.StatusData, id forkid.ID) (forkIncompatibility, bool) {
+	genesis := gointerfaces.ConvertH256ToHash(status.ForkData.Genesis)
+	heightForks, timeForks := status.ForkData.HeightForks, status.ForkData.TimeForks
+	err := forkid.NewFilterFromForks(heightForks, timeForks, genesis, status.MaxBlockHeight, status.MaxBlockTime)(id)
+	switch {
+	case err == nil:
+		return "", false
+	case errors.Is(err, forkid.ErrRemoteStale):
+		return forkStale, true
+	case forkid.NewIDFromForks(heightForks, timeForks, genesis, status.MaxBlockHeight, status.MaxBlockTime).Hash == id.Hash:
+		// same checksum as ours, so it's the announced next fork which we already passed
+		return forkFuture, true
+	default:
+		return forkDifferentChain, true
+	}
+}
+
+// peerForkID returns the fork ID a peer advertised in its handshake, if its sentry reports it.
+func peerForkID(peer *proto_types.PeerInfo) (forkid.ID, bool) {
+	if peer == nil || len(peer.ForkHash) != 4 {
+		return forkid.ID{}, false
+	}
+	return forkid.ID{Hash: [4]byte(peer.ForkHash), Next: peer.ForkNext}, true
+}
+
+// checkPeerForkID checks the fork ID advertised by a connected peer against ours at our head in status. An incompatible
+// peer is logged, counted, and its reason kept until it disconnects, see Peers.
+func (cs *MultiClient) checkPeerForkID(peerID [64]byte, peer *proto_types.PeerInfo, status *proto_sentry.StatusData) {
+	id, ok := peerForkID(peer)
+	if !ok {
+		return
+	}
+	reason, incompatible := forkIDIncompatibility(status, id)
+	if !incompatible {
+		cs.peerForks.Delete(peerID)
+		return
+	}
+	cs.peerForks.Store(peerID, reason)
+	forkIncompatiblePeers[reason].Inc()
+	ours := forkid.NewIDFromForks(status.ForkData.HeightForks, status.ForkData.TimeForks,
+		gointerfaces.ConvertH256ToHash(status.ForkData.Genesis), status.MaxBlockHeight, status.MaxBlockTime)
+	cs.logger.Debug("[p2p] Peer with incompatible fork ID", "peer", peer.Id, "clientID", peer.Name, "reason", reason,
+		"forkHash", fmt.Sprintf("%x", id.Hash), "forkNext", id.Next,
+		"ourForkHash", fmt.Sprintf("%x", ours.Hash), "ourForkNext", ours.Next)
+}
+
+func (cs *MultiClient) removePeerFork(peerID [64]byte) {
+	cs.peerForks.Delete(peerID)
+}
+
+// peerForkIncompatibility returns the reason why the fork ID of a peer is incompatible with ours, if it is.
+func (cs *MultiClient) peerForkIncompatibility(id string) (forkIncompatibility, bool) {
+	peerID, ok := peerIDFromHex(id)
+	if !ok {
+		return "", false
+	}
+	reason, ok := cs.peerForks.Load(peerID)
+	if !ok {
+		return "", false
+	}
+	return reason.(forkIncompatibility), true
+}
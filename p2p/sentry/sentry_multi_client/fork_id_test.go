@@ -0,0 +1,113 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	proto_types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/p2p/forkid"
+)
+
+func TestForkIDIncompatibility(t *testing.T) {
+	genesis, otherGenesis := common.Hash{1}, common.Hash{2}
+	forks := []uint64{1, 2, 3}
+	// our head is past all the forks
+	status := &proto_sentry.StatusData{
+		ForkData:       &proto_sentry.Forks{Genesis: gointerfaces.ConvertHashToH256(genesis), HeightForks: forks},
+		MaxBlockHeight: 10,
+	}
+	ours := forkid.NewIDFromForks(forks, nil, genesis, 10, 0)
+
+	// a peer announcing the next fork at block 5, which we passed without being aware of it
+	futureFork := ours
+	futureFork.Next = 5
+
+	for _, tt := range []struct {
+		name   string
+		id     forkid.ID
+		reason forkIncompatibility
+	}{
+		{name: "compatible", id: ours},
+		// a peer which didn't update for the fork at block 2 stays after the fork at block 1 for good
+		{name: "same chain, old fork", id: forkid.NewIDFromForks(forks[:1], nil, genesis, 10, 0), reason: forkStale},
+		// a peer still syncing, to which the fork at block 2 is next, is compatible
+		{name: "same chain, syncing", id: forkid.NewIDFromForks(forks, nil, genesis, 1, 0)},
+		{name: "future fork", id: futureFork, reason: forkFuture},
+		{name: "different genesis", id: forkid.NewIDFromForks(forks, nil, otherGenesis, 10, 0), reason: forkDifferentChain},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, incompatible := forkIDIncompatibility(status, tt.id)
+			require.Equal(t, tt.reason != "", incompatible)
+			require.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestPeersShowForkIncompatibility(t *testing.T) {
+	genesis := common.Hash{1}
+	status := &proto_sentry.StatusData{
+		ForkData:       &proto_sentry.Forks{Genesis: gointerfaces.ConvertHashToH256(genesis), HeightForks: []uint64{1, 2}},
+		MaxBlockHeight: 10,
+	}
+	compatible := forkid.NewIDFromForks([]uint64{1, 2}, nil, genesis, 10, 0)
+	stale := forkid.NewIDFromForks([]uint64{1}, nil, genesis, 10, 0)
+	peer1, peer2, peer3 := [64]byte{1}, [64]byte{2}, [64]byte{3}
+	peerInfo := func(id [64]byte, forkID *forkid.ID) *proto_types.PeerInfo {
+		peer := &proto_types.PeerInfo{Id: hex.EncodeToString(id[:]), Name: "erigon", Caps: []string{"eth/68"}}
+		if forkID != nil {
+			peer.ForkHash, peer.ForkNext = forkID.Hash[:], forkID.Next
+		}
+		return peer
+	}
+	// peer 3 has a sentry which doesn't report the fork IDs
+	peers := []*proto_types.PeerInfo{peerInfo(peer1, &compatible), peerInfo(peer2, &stale), peerInfo(peer3, nil)}
+
+	ctrl := gomock.NewController(t)
+	sentry := direct.NewMockSentryClient(ctrl)
+	sentry.EXPECT().Ready().Return(true).AnyTimes()
+	sentry.EXPECT().Peers(gomock.Any(), gomock.Any()).Return(&proto_sentry.PeersReply{Peers: peers}, nil).AnyTimes()
+	cs := &MultiClient{sentries: []proto_sentry.SentryClient{sentry}, logger: log.New()}
+	cs.checkPeerForkID(peer1, peers[0], status)
+	cs.checkPeerForkID(peer2, peers[1], status)
+	cs.checkPeerForkID(peer3, peers[2], status)
+
+	listed, errs := cs.Peers(context.Background())
+	require.Empty(t, errs)
+	require.Len(t, listed, 3)
+	require.Empty(t, listed[0].ForkIncompatibility)
+	require.Equal(t, string(forkStale), listed[1].ForkIncompatibility)
+	require.Empty(t, listed[2].ForkIncompatibility)
+
+	// the incompatibility of a peer is forgotten once it disconnects
+	require.NoError(t, cs.HandlePeerEvent(context.Background(), &proto_sentry.PeerEvent{
+		PeerId:  gointerfaces.ConvertHashToH512(peer2),
+		EventId: proto_sentry.PeerEvent_Disconnect,
+	}, sentry))
+	_, ok := cs.peerForkIncompatibility(hex.EncodeToString(peer2[:]))
+	require.False(t, ok)
+}
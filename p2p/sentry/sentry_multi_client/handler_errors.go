@@ -0,0 +1,143 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/metrics"
+	"github.com/erigontech/erigon-lib/rlp"
+)
+
+// The failures of the handlers of the messages, which HandleInboundMessage acts upon, see handlerErrorKindOf.
+
+// PeerMisbehaviorError is a failure caused by the peer, e.g. a message which doesn't decode: the peer is kicked.
+type PeerMisbehaviorError struct{ Err error }
+
+func (e *PeerMisbehaviorError) Error() string { return e.Err.Error() }
+func (e *PeerMisbehaviorError) Unwrap() error { return e.Err }
+
+// TransientLocalError is a failure of ours which may go away, e.g. the database or a sentry being busy: the handler is
+// retried, the peer isn't blamed.
+type TransientLocalError struct{ Err error }
+
+func (e *TransientLocalError) Error() string { return e.Err.Error() }
+func (e *TransientLocalError) Unwrap() error { return e.Err }
+
+// InternalError is a failure of ours which won't go away by itself, e.g. a message we can't encode: it's logged as an
+// error and counted, for the alerts, the peer isn't blamed.
+type InternalError struct{ Err error }
+
+func (e *InternalError) Error() string { return e.Err.Error() }
+func (e *InternalError) Unwrap() error { return e.Err }
+
+func peerMisbehavior(format string, args ...any) error {
+	return &PeerMisbehaviorError{Err: fmt.Errorf(format, args...)}
+}
+
+func transientLocal(format string, args ...any) error {
+	return &TransientLocalError{Err: fmt.Errorf(format, args...)}
+}
+
+func internalFailure(format string, args ...any) error {
+	return &InternalError{Err: fmt.Errorf(format, args...)}
+}
+
+type handlerErrorKind string
+
+const (
+	misbehaviorError  handlerErrorKind = "misbehavior"
+	transientError    handlerErrorKind = "transient"
+	internalError     handlerErrorKind = "internal"
+	unclassifiedError handlerErrorKind = "unclassified"
+)
+
+// handlerErrorKindOf classifies a failure of a handler. The invalid RLP, wherever it's found, is the peer's doing.
+func handlerErrorKindOf(err error) handlerErrorKind {
+	var misbehaviorErr *PeerMisbehaviorError
+	var transientErr *TransientLocalError
+	var internalErr *InternalError
+	switch {
+	case errors.As(err, &misbehaviorErr), rlp.IsInvalidRLPError(err):
+		return misbehaviorError
+	case errors.As(err, &transientErr):
+		return transientError
+	case errors.As(err, &internalErr):
+		return internalError
+	default:
+		return unclassifiedError
+	}
+}
+
+func handlerErrorsCounter(id proto_sentry.MessageId, kind handlerErrorKind) metrics.Counter {
+	return metrics.GetOrCreateCounter(fmt.Sprintf(`p2p_inbound_message_errors{msg=%q,kind=%q}`, id.String(), kind))
+}
+
+const (
+	transientRetries    = 2                     // of the handlers failing with a TransientLocalError
+	transientRetryDelay = 50 * time.Millisecond // before each retry
+)
+
+// handleWithRetries runs the handler, again after the TransientLocalErrors, a few times, unless ctx is done.
+func handleWithRetries(ctx context.Context, handle InboundMessageHandler, inreq *proto_sentry.InboundMessage, sentryClient proto_sentry.SentryClient) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = handle(ctx, inreq, sentryClient); err == nil || attempt == transientRetries {
+			return err
+		}
+		var transientErr *TransientLocalError
+		if !errors.As(err, &transientErr) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(transientRetryDelay):
+		}
+	}
+}
+
+// onHandlerError acts upon a failure of the handler of a message: kicks the peer which misbehaved, and logs the
+// failures of ours, the internal ones as errors.
+func (cs *MultiClient) onHandlerError(ctx context.Context, message *proto_sentry.InboundMessage, sentryClient proto_sentry.SentryClient, err error) {
+	kind := handlerErrorKindOf(err)
+	handlerErrorsCounter(message.Id, kind).Inc()
+	switch kind {
+	case misbehaviorError:
+		if message.PeerId == nil {
+			return
+		}
+		cs.logger.Debug("[p2p] Kicking peer for a bad message", "msg", message.Id.String(), "err", err)
+		cs.reputation.Record(gointerfaces.ConvertH512ToHash(message.PeerId), reputationPenalty)
+		penalizeRequest := proto_sentry.PenalizePeerRequest{
+			PeerId:  message.PeerId,
+			Penalty: proto_sentry.PenaltyKind_Kick, // TODO: Extend penalty kinds
+		}
+		if err1 := cs.send(ctx, sentryClient, sendPriorityHigh, "penalty", penalizePeer(&penalizeRequest)); err1 != nil {
+			cs.logger.Error("Could not send penalty", "err", err1)
+		}
+	case transientError:
+		cs.logger.Debug("[p2p] Could not handle message, after retries", "msg", message.Id.String(), "err", err)
+	case internalError:
+		cs.logger.Error("[p2p] Could not handle message", "msg", message.Id.String(), "err", err)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+)
+
+func TestHandlerErrorActions(t *testing.T) {
+	const id = proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68
+	peer := [64]byte{1}
+	for _, tt := range []struct {
+		name    string
+		errs    []error // returned by the handler, in turn, then nil
+		kind    handlerErrorKind
+		calls   int
+		kicked  bool
+		errLogs int
+	}{
+		{name: "misbehavior", errs: []error{peerMisbehavior("decode: %w", errors.New("bad"))}, kind: misbehaviorError, calls: 1, kicked: true},
+		{name: "invalid rlp", errs: []error{fmt.Errorf("decode: %w", rlp.ErrExpectedList)}, kind: misbehaviorError, calls: 1, kicked: true},
+		{name: "transient, recovered", errs: []error{transientLocal("begin tx: busy"), transientLocal("begin tx: busy")}, calls: 3},
+		{name: "transient", errs: []error{transientLocal("send: busy"), transientLocal("send: busy"), transientLocal("send: busy")}, kind: transientError, calls: 1 + transientRetries},
+		{name: "internal", errs: []error{internalFailure("encode: broken")}, kind: internalError, calls: 1, errLogs: 1},
+		{name: "unclassified", errs: []error{errors.New("unknown")}, kind: unclassifiedError, calls: 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sentry := direct.NewMockSentryClient(gomock.NewController(t))
+			var kicked [][64]byte
+			sentry.EXPECT().PenalizePeer(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, req *proto_sentry.PenalizePeerRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+					require.Equal(t, proto_sentry.PenaltyKind_Kick, req.Penalty)
+					kicked = append(kicked, gointerfaces.ConvertH512ToHash(req.PeerId))
+					return &emptypb.Empty{}, nil
+				}).AnyTimes()
+			errLogs := 0
+			logger := log.New()
+			logger.SetHandler(log.FilterHandler(func(r *log.Record) bool {
+				if r.Lvl == log.LvlError {
+					errLogs++
+				}
+				return false
+			}, log.DiscardHandler()))
+			cs := &MultiClient{logger: logger}
+			calls := 0
+			cs.RegisterHandler(id, func(context.Context, *proto_sentry.InboundMessage, proto_sentry.SentryClient) error {
+				calls++
+				if calls > len(tt.errs) {
+					return nil
+				}
+				return tt.errs[calls-1]
+			})
+
+			var counted float64
+			if tt.kind != "" {
+				counted = handlerErrorsCounter(id, tt.kind).GetValue()
+			}
+			err := cs.HandleInboundMessage(context.Background(), &proto_sentry.InboundMessage{Id: id, PeerId: gointerfaces.ConvertHashToH512(peer)}, sentry)
+			require.Equal(t, tt.calls, calls)
+			if tt.kind == "" {
+				require.NoError(t, err)
+				require.Empty(t, kicked)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tt.kind, handlerErrorKindOf(err))
+			require.Equal(t, counted+1, handlerErrorsCounter(id, tt.kind).GetValue())
+			if tt.kicked {
+				require.Equal(t, [][64]byte{peer}, kicked)
+			} else {
+				require.Empty(t, kicked)
+			}
+			require.Equal(t, tt.errLogs, errLogs)
+		})
+	}
+}
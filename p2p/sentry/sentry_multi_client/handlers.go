@@ -0,0 +1,122 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"slices"
+
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+)
+
+// InboundMessageHandler handles a message of a peer, received through sentryClient.
+type InboundMessageHandler func(ctx context.Context, inreq *proto_sentry.InboundMessage, sentryClient proto_sentry.SentryClient) error
+
+// messageStream is the stream of the sentries a message is subscribed to, see StartStreamLoops.
+type messageStream uint8
+
+const (
+	noStream            messageStream = iota // handled if given to HandleInboundMessage, but not subscribed to
+	messagesStream                           // RecvMessageLoop
+	uploadStream                             // RecvUploadMessageLoop
+	uploadHeadersStream                      // RecvUploadHeadersMessageLoop
+)
+
+// streamOf returns the stream of the messages of a registered handler: the requests of the peers go to the upload
+// streams, so that answering them doesn't hold the block download, the other messages to the main one.
+func streamOf(id proto_sentry.MessageId) messageStream {
+	switch id {
+	case proto_sentry.MessageId_GET_BLOCK_HEADERS_66:
+		return uploadHeadersStream
+	case proto_sentry.MessageId_GET_BLOCK_BODIES_66, proto_sentry.MessageId_GET_NODE_DATA_66,
+		proto_sentry.MessageId_GET_RECEIPTS_66, proto_sentry.MessageId_GET_POOLED_TRANSACTIONS_66:
+		return uploadStream
+	default:
+		return messagesStream
+	}
+}
+
+type inboundHandler struct {
+	handle InboundMessageHandler
+	stream messageStream
+}
+
+// blockDownloadMessages are the messages of the block download, not handled with disableBlockDownload of
+// NewMultiClient.
+var blockDownloadMessages = []proto_sentry.MessageId{
+	proto_sentry.MessageId_NEW_BLOCK_HASHES_66,
+	proto_sentry.MessageId_BLOCK_HEADERS_66,
+	proto_sentry.MessageId_NEW_BLOCK_66,
+	proto_sentry.MessageId_BLOCK_BODIES_66,
+}
+
+// registerDefaultHandlers registers the handlers of the messages of the eth protocol, but GetPooledTransactions,
+// which is only handled with a txpool, see SetTxPool.
+func (cs *MultiClient) registerDefaultHandlers() {
+	cs.handlersLock.Lock()
+	defer cs.handlersLock.Unlock()
+	cs.handlers = map[proto_sentry.MessageId]inboundHandler{
+		proto_sentry.MessageId_NEW_BLOCK_HASHES_66:  {cs.newBlockHashes66, messagesStream},
+		proto_sentry.MessageId_BLOCK_HEADERS_66:     {cs.blockHeaders66, messagesStream},
+		proto_sentry.MessageId_NEW_BLOCK_66:         {cs.newBlock66, messagesStream},
+		proto_sentry.MessageId_BLOCK_BODIES_66:      {cs.blockBodies66, messagesStream},
+		proto_sentry.MessageId_GET_BLOCK_HEADERS_66: {cs.getBlockHeaders66, uploadHeadersStream},
+		proto_sentry.MessageId_GET_BLOCK_BODIES_66:  {cs.getBlockBodies66, uploadStream},
+		proto_sentry.MessageId_GET_RECEIPTS_66:      {cs.getReceipts66, uploadStream},
+		proto_sentry.MessageId_RECEIPTS_66:          {cs.receipts66, noStream},
+	}
+}
+
+// RegisterHandler makes MultiClient handle the messages id with handle, instead of its own handler if it has one, and
+// subscribe to them. It must be called before StartStreamLoops, or the subscription waits for the streams to
+// reconnect.
+func (cs *MultiClient) RegisterHandler(id proto_sentry.MessageId, handle InboundMessageHandler) {
+	cs.handlersLock.Lock()
+	defer cs.handlersLock.Unlock()
+	if cs.handlers == nil {
+		cs.handlers = map[proto_sentry.MessageId]inboundHandler{}
+	}
+	cs.handlers[id] = inboundHandler{handle: handle, stream: streamOf(id)}
+}
+
+// UnregisterHandler makes MultiClient ignore the messages id, and unsubscribe from them, see RegisterHandler.
+func (cs *MultiClient) UnregisterHandler(id proto_sentry.MessageId) {
+	cs.handlersLock.Lock()
+	defer cs.handlersLock.Unlock()
+	delete(cs.handlers, id)
+}
+
+func (cs *MultiClient) handler(id proto_sentry.MessageId) (InboundMessageHandler, bool) {
+	cs.handlersLock.RLock()
+	defer cs.handlersLock.RUnlock()
+	h, ok := cs.handlers[id]
+	return h.handle, ok
+}
+
+// subscribedMessages returns the messages of the registered handlers of stream, in the order of their ids.
+func (cs *MultiClient) subscribedMessages(stream messageStream) []proto_sentry.MessageId {
+	cs.handlersLock.RLock()
+	defer cs.handlersLock.RUnlock()
+	var ids []proto_sentry.MessageId
+	for id, h := range cs.handlers {
+		if h.stream == stream {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
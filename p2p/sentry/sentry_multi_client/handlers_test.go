@@ -0,0 +1,88 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/erigontech/erigon-lib/direct"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+func TestDefaultHandlersSubscriptions(t *testing.T) {
+	cs := &MultiClient{logger: log.New()}
+	cs.registerDefaultHandlers()
+	require.Equal(t, []proto_sentry.MessageId{
+		proto_sentry.MessageId_NEW_BLOCK_HASHES_66,
+		proto_sentry.MessageId_NEW_BLOCK_66,
+		proto_sentry.MessageId_BLOCK_HEADERS_66,
+		proto_sentry.MessageId_BLOCK_BODIES_66,
+	}, cs.subscribedMessages(messagesStream))
+	require.Equal(t, []proto_sentry.MessageId{
+		proto_sentry.MessageId_GET_BLOCK_BODIES_66,
+		proto_sentry.MessageId_GET_RECEIPTS_66,
+	}, cs.subscribedMessages(uploadStream))
+	require.Equal(t, []proto_sentry.MessageId{proto_sentry.MessageId_GET_BLOCK_HEADERS_66}, cs.subscribedMessages(uploadHeadersStream))
+
+	// handled, not subscribed to
+	sentry := direct.NewMockSentryClient(gomock.NewController(t))
+	require.NoError(t, cs.HandleInboundMessage(context.Background(), &proto_sentry.InboundMessage{Id: proto_sentry.MessageId_RECEIPTS_66}, sentry))
+
+	// as with disableBlockDownload
+	for _, id := range blockDownloadMessages {
+		cs.UnregisterHandler(id)
+	}
+	require.Empty(t, cs.subscribedMessages(messagesStream))
+	require.Len(t, cs.subscribedMessages(uploadStream), 2)
+	require.Error(t, cs.HandleInboundMessage(context.Background(), &proto_sentry.InboundMessage{Id: proto_sentry.MessageId_NEW_BLOCK_66}, sentry))
+}
+
+func TestRegisterHandler(t *testing.T) {
+	cs := &MultiClient{logger: log.New()}
+	cs.registerDefaultHandlers()
+	sentry := direct.NewMockSentryClient(gomock.NewController(t))
+	var handled []proto_sentry.MessageId
+	handle := func(_ context.Context, inreq *proto_sentry.InboundMessage, _ proto_sentry.SentryClient) error {
+		handled = append(handled, inreq.Id)
+		return nil
+	}
+	send := func(id proto_sentry.MessageId) error {
+		return cs.HandleInboundMessage(context.Background(), &proto_sentry.InboundMessage{Id: id, Data: []byte{0xc0}}, sentry)
+	}
+
+	// an override, in the stream of the message it overrides
+	cs.RegisterHandler(proto_sentry.MessageId_GET_BLOCK_HEADERS_66, handle)
+	require.NoError(t, send(proto_sentry.MessageId_GET_BLOCK_HEADERS_66))
+	require.Equal(t, []proto_sentry.MessageId{proto_sentry.MessageId_GET_BLOCK_HEADERS_66}, cs.subscribedMessages(uploadHeadersStream))
+
+	// a new message
+	require.Error(t, send(proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68))
+	cs.RegisterHandler(proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68, handle)
+	require.NoError(t, send(proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68))
+	require.Contains(t, cs.subscribedMessages(messagesStream), proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68)
+	require.Equal(t, []proto_sentry.MessageId{proto_sentry.MessageId_GET_BLOCK_HEADERS_66, proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68}, handled)
+
+	cs.UnregisterHandler(proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68)
+	require.Error(t, send(proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68))
+	require.NotContains(t, cs.subscribedMessages(messagesStream), proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68)
+	require.Len(t, handled, 2)
+}
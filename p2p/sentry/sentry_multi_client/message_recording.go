@@ -0,0 +1,272 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/eth/ethconfig"
+)
+
+// A file of recorded messages is a header: magic | version, then a frame of each message, in the order they came:
+// payload length (uvarint) | payload | crc32 of the payload, the payload being: message id (uvarint) | peer id (64
+// bytes) | unix time in nanoseconds (8 bytes) | data.
+var recordedMessagesMagic = [8]byte{'p', '2', 'p', 'r', 'e', 'c', 'r', 'd'}
+
+const (
+	recordedMessagesVersion = 1
+	// maxRecordedMessageSize bounds the payload of a frame read, beyond the largest eth message.
+	maxRecordedMessageSize = 64 << 20
+	recordedMessagesPrefix = "messages-"
+	recordedMessagesExt    = ".rec"
+)
+
+var ErrInvalidRecordedMessages = errors.New("invalid recorded p2p messages")
+
+// RecordedMessage is an inbound message as it came, from PeerId at Time.
+type RecordedMessage struct {
+	Id     proto_sentry.MessageId
+	PeerId [64]byte
+	Time   time.Time
+	Data   []byte
+}
+
+func (m *RecordedMessage) inbound() *proto_sentry.InboundMessage {
+	return &proto_sentry.InboundMessage{Id: m.Id, PeerId: gointerfaces.ConvertHashToH512(m.PeerId), Data: m.Data}
+}
+
+func appendRecordedMessageFrame(buf []byte, m *RecordedMessage) []byte {
+	var payload [binary.MaxVarintLen64 + 64 + 8]byte
+	n := binary.PutUvarint(payload[:], uint64(m.Id))
+	n += copy(payload[n:], m.PeerId[:])
+	binary.BigEndian.PutUint64(payload[n:], uint64(m.Time.UnixNano()))
+	n += 8
+
+	buf = binary.AppendUvarint(buf, uint64(n+len(m.Data)))
+	buf = append(buf, payload[:n]...)
+	buf = append(buf, m.Data...)
+	checksum := crc32.Update(crc32.ChecksumIEEE(payload[:n]), crc32.IEEETable, m.Data)
+	return binary.BigEndian.AppendUint32(buf, checksum)
+}
+
+// MessageRecorder appends the inbound messages to the files of a dir, rotating them, for them to be replayed by
+// ReplayMessages when debugging. The failure to record a message is logged, and ends the recording.
+type MessageRecorder struct {
+	dir      string
+	fileSize int64
+	files    int
+	logger   log.Logger
+
+	lock    sync.Mutex
+	file    *os.File // nil once closed
+	written int64    // to file
+	seq     uint64   // of file
+	buf     []byte
+}
+
+// OpenMessageRecorder starts recording the messages to a new file in cfg.Dir, after the files of the previous
+// recordings, if any.
+func OpenMessageRecorder(cfg ethconfig.MessageRecording, logger log.Logger) (*MessageRecorder, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	r := &MessageRecorder{dir: cfg.Dir, fileSize: int64(cfg.FileSize), files: max(cfg.Files, 1), logger: logger}
+	files, err := RecordedMessageFiles(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		if r.seq, err = recordedMessagesSeq(files[len(files)-1]); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.rotate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RecordedMessageFiles returns the files of recorded messages of dir, the oldest first.
+func RecordedMessageFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, recordedMessagesPrefix+"*"+recordedMessagesExt))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func recordedMessagesSeq(file string) (uint64, error) {
+	var seq uint64
+	if _, err := fmt.Sscanf(filepath.Base(file), recordedMessagesPrefix+"%d"+recordedMessagesExt, &seq); err != nil {
+		return 0, fmt.Errorf("file of recorded messages %s: %w", file, err)
+	}
+	return seq, nil
+}
+
+// rotate closes the file, opens the next one, and removes the oldest beyond the files kept.
+func (r *MessageRecorder) rotate() error {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			return err
+		}
+		r.file = nil
+	}
+	r.seq++
+	file, err := os.OpenFile(filepath.Join(r.dir, fmt.Sprintf("%s%08d%s", recordedMessagesPrefix, r.seq, recordedMessagesExt)),
+		os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(recordedMessagesMagic[:], recordedMessagesVersion)); err != nil {
+		file.Close()
+		return err
+	}
+	r.file, r.written = file, int64(len(recordedMessagesMagic)+1)
+
+	files, err := RecordedMessageFiles(r.dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > r.files {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// Record appends the message, come at, to the recording, nothing if it's nil or ended.
+func (r *MessageRecorder) Record(message *proto_sentry.InboundMessage, at time.Time) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.file == nil {
+		return
+	}
+
+	m := RecordedMessage{Id: message.Id, Time: at, Data: message.Data}
+	if message.PeerId != nil {
+		m.PeerId = gointerfaces.ConvertH512ToHash(message.PeerId)
+	}
+	r.buf = appendRecordedMessageFrame(r.buf[:0], &m)
+	if r.written > int64(len(recordedMessagesMagic)+1) && r.written+int64(len(r.buf)) > r.fileSize {
+		if err := r.rotate(); err != nil {
+			r.fail(err)
+			return
+		}
+	}
+	if _, err := r.file.Write(r.buf); err != nil {
+		r.fail(err)
+		return
+	}
+	r.written += int64(len(r.buf))
+}
+
+func (r *MessageRecorder) fail(err error) {
+	r.logger.Warn("[p2p] Could not record the message, recording ended", "dir", r.dir, "err", err)
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+func (r *MessageRecorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// SetMessageRecorder makes HandleInboundMessage record the messages to r, before handling them.
+func (cs *MultiClient) SetMessageRecorder(r *MessageRecorder) { cs.recorder = r }
+
+// RecordedMessagesReader reads the messages of a file of recorded messages, in order.
+type RecordedMessagesReader struct {
+	r *bufio.Reader
+}
+
+func NewRecordedMessagesReader(r io.Reader) (*RecordedMessagesReader, error) {
+	br := bufio.NewReader(r)
+	var header [len(recordedMessagesMagic) + 1]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrInvalidRecordedMessages, err)
+	}
+	if !bytes.Equal(header[:len(recordedMessagesMagic)], recordedMessagesMagic[:]) {
+		return nil, fmt.Errorf("%w: not a file of recorded messages", ErrInvalidRecordedMessages)
+	}
+	if version := header[len(recordedMessagesMagic)]; version != recordedMessagesVersion {
+		return nil, fmt.Errorf("%w: version %d, want %d", ErrInvalidRecordedMessages, version, recordedMessagesVersion)
+	}
+	return &RecordedMessagesReader{r: br}, nil
+}
+
+// Next returns the next message, io.EOF after the last one, and io.ErrUnexpectedEOF for a last frame cut short, the
+// recording having been interrupted while writing it.
+func (rr *RecordedMessagesReader) Next() (*RecordedMessage, error) {
+	size, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxRecordedMessageSize {
+		return nil, fmt.Errorf("%w: frame of %d bytes", ErrInvalidRecordedMessages, size)
+	}
+	frame := make([]byte, size+4)
+	if _, err := io.ReadFull(rr.r, frame); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	payload := frame[:size]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(frame[size:]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidRecordedMessages)
+	}
+
+	var m RecordedMessage
+	id, n := binary.Uvarint(payload)
+	if n <= 0 || len(payload) < n+64+8 {
+		return nil, fmt.Errorf("%w: frame of %d bytes", ErrInvalidRecordedMessages, size)
+	}
+	m.Id = proto_sentry.MessageId(id)
+	payload = payload[n:]
+	copy(m.PeerId[:], payload)
+	m.Time = time.Unix(0, int64(binary.BigEndian.Uint64(payload[64:])))
+	m.Data = payload[64+8:]
+	return &m, nil
+}
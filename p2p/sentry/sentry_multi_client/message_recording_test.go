@@ -0,0 +1,163 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/execution/stages/headerdownload"
+	"github.com/erigontech/erigon/p2p/protocols/eth"
+)
+
+func TestRecordAndReplayMessages(t *testing.T) {
+	ctx, dir := context.Background(), t.TempDir()
+	peer := [64]byte{1}
+
+	headers := make([]*types.Header, 8)
+	for i := range headers {
+		headers[i] = &types.Header{Number: big.NewInt(int64(i + 1)), Difficulty: big.NewInt(1)}
+		if i > 0 {
+			headers[i].ParentHash = headers[i-1].Hash()
+		}
+	}
+	headersMessage := func(requestID uint64, headers []*types.Header) *proto_sentry.InboundMessage {
+		data, err := rlp.EncodeToBytes(&eth.BlockHeadersPacket66{RequestId: requestID, BlockHeadersPacket: headers})
+		require.NoError(t, err)
+		return &proto_sentry.InboundMessage{Id: proto_sentry.MessageId_BLOCK_HEADERS_66, Data: data, PeerId: gointerfaces.ConvertHashToH512(peer)}
+	}
+	session := []*proto_sentry.InboundMessage{
+		headersMessage(1, headers[:4]),
+		{Id: proto_sentry.MessageId_BLOCK_HEADERS_66, Data: []byte{0x01}, PeerId: gointerfaces.ConvertHashToH512(peer)},
+		headersMessage(2, headers[4:]),
+		headersMessage(3, headers[2:5]), // duplicates
+	}
+
+	newClient := func(t *testing.T) (*MultiClient, proto_sentry.SentryClient) {
+		sentry := direct.NewMockSentryClient(gomock.NewController(t))
+		sentry.EXPECT().PeerMinBlock(gomock.Any(), gomock.Any(), gomock.Any()).Return(&emptypb.Empty{}, nil).AnyTimes()
+		sentry.EXPECT().PenalizePeer(gomock.Any(), gomock.Any(), gomock.Any()).Return(&emptypb.Empty{}, nil).Times(1)
+		cs := &MultiClient{
+			sentries: []proto_sentry.SentryClient{sentry},
+			Hd:       headerdownload.NewHeaderDownload(16, 16, nil, nil, log.New()),
+			logger:   log.New(),
+		}
+		cs.registerDefaultHandlers()
+		return cs, sentry
+	}
+	downloaderState := func(cs *MultiClient) (headerdownload.Stats, []bool) {
+		links := make([]bool, len(headers))
+		for i, header := range headers {
+			links[i] = cs.Hd.HasLink(header.Hash())
+		}
+		return cs.Hd.ExtractStats(), links
+	}
+
+	// the session, recorded in files of a couple of messages
+	recorder, err := OpenMessageRecorder(ethconfig.MessageRecording{Dir: dir, FileSize: 1200, Files: 100}, log.New())
+	require.NoError(t, err)
+	recorded, sentry := newClient(t)
+	recorded.SetMessageRecorder(recorder)
+	for _, message := range session {
+		_ = recorded.HandleInboundMessage(ctx, message, sentry)
+	}
+	require.NoError(t, recorder.Close())
+	recorded.recorder.Record(session[0], time.Now()) // not recorded once closed
+	wantStats, wantLinks := downloaderState(recorded)
+	require.Equal(t, []bool{true, true, true, true, true, true, true, true}, wantLinks)
+	require.Equal(t, 3, wantStats.Responses)
+	require.Equal(t, 3, wantStats.Duplicates)
+
+	files, err := RecordedMessageFiles(dir)
+	require.NoError(t, err)
+	require.Greater(t, len(files), 1)
+	var messages []*RecordedMessage
+	for _, file := range files {
+		require.NoError(t, replayFile(file, func(m *RecordedMessage) error {
+			messages = append(messages, m)
+			return nil
+		}))
+	}
+	require.Len(t, messages, len(session))
+	for i, m := range messages {
+		require.Equal(t, session[i].Id, m.Id)
+		require.Equal(t, peer, m.PeerId)
+		require.Equal(t, session[i].Data, m.Data)
+		if i > 0 {
+			require.False(t, m.Time.Before(messages[i-1].Time))
+		}
+	}
+
+	wantSummary := ReplaySummary{
+		Messages: len(session),
+		Outcomes: map[proto_sentry.MessageId]map[string]int{
+			proto_sentry.MessageId_BLOCK_HEADERS_66: {replayHandled: 3, string(misbehaviorError): 1},
+		},
+	}
+	for _, speed := range []float64{0, 1000} {
+		replayed, sentry := newClient(t)
+		summary, err := replayed.ReplayMessages(ctx, files, sentry, ReplayOptions{Speed: speed})
+		require.NoError(t, err)
+		require.Equal(t, wantSummary, summary)
+		stats, links := downloaderState(replayed)
+		require.Equal(t, wantStats, stats)
+		require.Equal(t, wantLinks, links)
+	}
+
+	// a recording interrupted in the middle of its last message
+	last := files[len(files)-1]
+	info, err := os.Stat(last)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(last, info.Size()-2))
+	replayed, sentry := newClient(t)
+	summary, err := replayed.ReplayMessages(ctx, files, sentry, ReplayOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, summary.Truncated)
+	require.Equal(t, len(session)-1, summary.Messages)
+
+	// a recording after it goes on in a new file, the oldest being removed beyond the files kept
+	recorder, err = OpenMessageRecorder(ethconfig.MessageRecording{Dir: dir, FileSize: 1200, Files: 2}, log.New())
+	require.NoError(t, err)
+	require.NoError(t, recorder.Close())
+	newFiles, err := RecordedMessageFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, newFiles, 2)
+	require.Equal(t, last, newFiles[0])
+
+	// the files of another version aren't replayed
+	corrupted := newFiles[1]
+	data, err := os.ReadFile(corrupted)
+	require.NoError(t, err)
+	data[len(recordedMessagesMagic)]++
+	require.NoError(t, os.WriteFile(corrupted, data, 0o644))
+	_, err = replayed.ReplayMessages(ctx, []string{corrupted}, sentry, ReplayOptions{})
+	require.ErrorIs(t, err, ErrInvalidRecordedMessages)
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+)
+
+// replayHandled is the outcome of a message replayed which was handled without error.
+const replayHandled = "ok"
+
+// ReplayOptions configures ReplayMessages.
+type ReplayOptions struct {
+	// Speed divides the time between the messages: 1 replays them at their original timing, 10 ten times faster, and 0
+	// as fast as they're handled.
+	Speed float64
+}
+
+// ReplaySummary is the outcome of the messages replayed by ReplayMessages.
+type ReplaySummary struct {
+	Messages int
+	// Outcomes counts the messages by id and outcome: "ok", or the kind of failure of the handler.
+	Outcomes map[proto_sentry.MessageId]map[string]int
+	// Truncated is the amount of files ending with a message cut short, the recording having been interrupted.
+	Truncated int
+}
+
+func (s *ReplaySummary) add(id proto_sentry.MessageId, outcome string) {
+	if s.Outcomes == nil {
+		s.Outcomes = map[proto_sentry.MessageId]map[string]int{}
+	}
+	if s.Outcomes[id] == nil {
+		s.Outcomes[id] = map[string]int{}
+	}
+	s.Outcomes[id][outcome]++
+	s.Messages++
+}
+
+// ReplayMessages feeds the messages recorded in files, see MessageRecorder, in order, through HandleInboundMessage, as
+// if they came from sentryClient, at their original timing or faster. It's meant for a MultiClient set up for the
+// replay, e.g. over a copy of the db of the node which recorded them, which answers to sentryClient.
+func (cs *MultiClient) ReplayMessages(ctx context.Context, files []string, sentryClient proto_sentry.SentryClient, opts ReplayOptions) (ReplaySummary, error) {
+	var summary ReplaySummary
+	var start, firstAt time.Time
+	for _, file := range files {
+		err := replayFile(file, func(m *RecordedMessage) error {
+			if opts.Speed > 0 {
+				if start.IsZero() {
+					start, firstAt = time.Now(), m.Time
+				}
+				due := start.Add(time.Duration(float64(m.Time.Sub(firstAt)) / opts.Speed))
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Until(due)):
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			outcome := replayHandled
+			if err := cs.HandleInboundMessage(ctx, m.inbound(), sentryClient); err != nil {
+				outcome = string(handlerErrorKindOf(err))
+			}
+			summary.add(m.Id, outcome)
+			return nil
+		})
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			summary.Truncated++
+			continue
+		}
+		if err != nil {
+			return summary, fmt.Errorf("replay %s: %w", file, err)
+		}
+	}
+	cs.logger.Info("[p2p] Replayed the recorded messages", "files", len(files), "messages", summary.Messages,
+		"truncated", summary.Truncated)
+	return summary, nil
+}
+
+func replayFile(file string, replay func(m *RecordedMessage) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rr, err := NewRecordedMessagesReader(f)
+	if err != nil {
+		return err
+	}
+	for {
+		m, err := rr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := replay(m); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/common/dbg"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+// repanicInboundMessages makes HandleInboundMessage panic again, instead of turning the panics of the handlers into
+// errors, so that they fail the tests and the development builds loudly.
+var repanicInboundMessages = dbg.EnvBool("P2P_REPANIC", false)
+
+const (
+	recoveredPanicsKept  = 32          // the last panics kept, see MultiClient.RecoveredPanics
+	panicStackSnippetLen = 2048        // of the stacks kept
+	panicsLogWindow      = time.Minute // the panics of a message recurring within it are logged as errors, once per window
+)
+
+func recoveredPanicsCounter(id proto_sentry.MessageId) metrics.Counter {
+	return metrics.GetOrCreateCounter(fmt.Sprintf(`p2p_inbound_message_panics{msg=%q}`, id.String()))
+}
+
+// RecoveredPanic is a panic of the handler of a message, recovered by HandleInboundMessage.
+type RecoveredPanic struct {
+	MessageID string    `json:"messageId"`
+	At        time.Time `json:"at"`
+	Panic     string    `json:"panic"`
+	Stack     string    `json:"stack"` // its top
+}
+
+type messagePanics struct {
+	last        time.Time // of the last panic
+	logged      time.Time // of the last error logged
+	sinceLogged int       // the panics since
+}
+
+// recoveredPanics keeps the last panics recovered, and logs the recurring ones. Its zero value is ready to use.
+type recoveredPanics struct {
+	lock  sync.Mutex
+	ring  []RecoveredPanic
+	next  int // the slot of the next panic, once the ring is full
+	byMsg map[proto_sentry.MessageId]*messagePanics
+	now   func() time.Time // time.Now if nil
+}
+
+func (p *recoveredPanics) record(id proto_sentry.MessageId, rec any, stack string, logger log.Logger) {
+	recoveredPanicsCounter(id).Inc()
+	if len(stack) > panicStackSnippetLen {
+		stack = stack[:panicStackSnippetLen]
+	}
+
+	p.lock.Lock()
+	now := time.Now()
+	if p.now != nil {
+		now = p.now()
+	}
+	recovered := RecoveredPanic{MessageID: id.String(), At: now, Panic: fmt.Sprint(rec), Stack: stack}
+	if len(p.ring) < recoveredPanicsKept {
+		p.ring = append(p.ring, recovered)
+	} else {
+		p.ring[p.next] = recovered
+		p.next = (p.next + 1) % recoveredPanicsKept
+	}
+	if p.byMsg == nil {
+		p.byMsg = map[proto_sentry.MessageId]*messagePanics{}
+	}
+	msg, ok := p.byMsg[id]
+	if !ok {
+		msg = &messagePanics{}
+		p.byMsg[id] = msg
+	}
+	recurring := !msg.last.IsZero() && now.Sub(msg.last) < panicsLogWindow
+	msg.last = now
+	msg.sinceLogged++
+	logError := recurring && now.Sub(msg.logged) >= panicsLogWindow
+	panics := msg.sinceLogged
+	if logError {
+		msg.logged, msg.sinceLogged = now, 0
+	}
+	p.lock.Unlock()
+
+	if logError {
+		logger.Error("[p2p] Recurring panics handling messages", "msg", id.String(), "panics", panics, "err", recovered.Panic, "stack", stack)
+		return
+	}
+	logger.Debug("[p2p] Recovered panic handling message", "msg", id.String(), "err", recovered.Panic, "stack", stack)
+}
+
+// list returns the panics kept, the latest first.
+func (p *recoveredPanics) list() []RecoveredPanic {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	list := make([]RecoveredPanic, 0, len(p.ring))
+	for i := range p.ring {
+		list = append(list, p.ring[(p.next+len(p.ring)-1-i)%len(p.ring)])
+	}
+	return list
+}
+
+// RecoveredPanics returns the last panics of the handlers of the messages, recovered by HandleInboundMessage, the
+// latest first.
+func (cs *MultiClient) RecoveredPanics() []RecoveredPanic { return cs.panics.list() }
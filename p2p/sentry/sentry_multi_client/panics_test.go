@@ -0,0 +1,107 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/erigontech/erigon-lib/direct"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+func TestRecoveredPanics(t *testing.T) {
+	var errorLogs []*log.Record
+	logger := log.New()
+	logger.SetHandler(log.FilterHandler(func(r *log.Record) bool {
+		if r.Lvl == log.LvlError {
+			errorLogs = append(errorLogs, r)
+		}
+		return false
+	}, log.DiscardHandler()))
+	now := time.Unix(1_700_000_000, 0)
+	cs := &MultiClient{logger: logger}
+	cs.panics.now = func() time.Time { return now }
+	panics := 0
+	cs.RegisterHandler(proto_sentry.MessageId_NEW_BLOCK_66, func(context.Context, *proto_sentry.InboundMessage, proto_sentry.SentryClient) error {
+		panics++
+		panic(fmt.Sprintf("boom %d", panics))
+	})
+	cs.RegisterHandler(proto_sentry.MessageId_BLOCK_BODIES_66, func(context.Context, *proto_sentry.InboundMessage, proto_sentry.SentryClient) error {
+		panic("bang")
+	})
+	sentry := direct.NewMockSentryClient(gomock.NewController(t))
+	send := func(id proto_sentry.MessageId, after time.Duration) {
+		now = now.Add(after)
+		require.Error(t, cs.HandleInboundMessage(context.Background(), &proto_sentry.InboundMessage{Id: id}, sentry))
+	}
+	counter := recoveredPanicsCounter(proto_sentry.MessageId_NEW_BLOCK_66)
+	counted := counter.GetValue()
+
+	send(proto_sentry.MessageId_NEW_BLOCK_66, 0)
+	require.Empty(t, errorLogs, "a single panic")
+	send(proto_sentry.MessageId_NEW_BLOCK_66, 10*time.Second)
+	require.Len(t, errorLogs, 1, "a recurring panic")
+	send(proto_sentry.MessageId_NEW_BLOCK_66, 10*time.Second)
+	send(proto_sentry.MessageId_BLOCK_BODIES_66, 0)
+	require.Len(t, errorLogs, 1, "rate limited, and the first panic of another message")
+	send(proto_sentry.MessageId_NEW_BLOCK_66, 50*time.Second)
+	require.Len(t, errorLogs, 2, "a window later")
+	require.Equal(t, "[p2p] Recurring panics handling messages", errorLogs[1].Msg)
+	require.Contains(t, errorLogs[1].Ctx, 2, "the panics since the last error")
+	send(proto_sentry.MessageId_NEW_BLOCK_66, 2*time.Minute)
+	require.Len(t, errorLogs, 2, "not recurring anymore")
+
+	require.Equal(t, counted+5, counter.GetValue())
+	recovered := cs.RecoveredPanics()
+	require.Len(t, recovered, 6)
+	require.Equal(t, "boom 5", recovered[0].Panic)
+	require.Equal(t, proto_sentry.MessageId_NEW_BLOCK_66.String(), recovered[0].MessageID)
+	require.Equal(t, now, recovered[0].At)
+	require.NotEmpty(t, recovered[0].Stack)
+	require.LessOrEqual(t, len(recovered[0].Stack), panicStackSnippetLen)
+	require.Equal(t, "bang", recovered[2].Panic)
+	require.Equal(t, "boom 1", recovered[5].Panic)
+
+	// only the last ones are kept
+	for range recoveredPanicsKept {
+		send(proto_sentry.MessageId_NEW_BLOCK_66, time.Second)
+	}
+	recovered = cs.RecoveredPanics()
+	require.Len(t, recovered, recoveredPanicsKept)
+	require.Equal(t, fmt.Sprintf("boom %d", panics), recovered[0].Panic)
+	require.Equal(t, fmt.Sprintf("boom %d", panics-recoveredPanicsKept+1), recovered[recoveredPanicsKept-1].Panic)
+}
+
+func TestRepanicInboundMessages(t *testing.T) {
+	repanic := repanicInboundMessages
+	repanicInboundMessages = true
+	t.Cleanup(func() { repanicInboundMessages = repanic })
+	cs := &MultiClient{logger: log.New()}
+	cs.RegisterHandler(proto_sentry.MessageId_NEW_BLOCK_66, func(context.Context, *proto_sentry.InboundMessage, proto_sentry.SentryClient) error {
+		panic("boom")
+	})
+	require.PanicsWithValue(t, "boom", func() {
+		_ = cs.HandleInboundMessage(context.Background(), &proto_sentry.InboundMessage{Id: proto_sentry.MessageId_NEW_BLOCK_66}, direct.NewMockSentryClient(gomock.NewController(t)))
+	})
+}
@@ -0,0 +1,240 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/holiman/uint256"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	proto_types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
+	"github.com/erigontech/erigon-lib/metrics"
+	"github.com/erigontech/erigon/p2p/protocols/eth"
+)
+
+// peerHead is the best block announced by a peer in its last NewBlock message.
+type peerHead struct {
+	hash common.Hash
+	td   *big.Int
+}
+
+func (cs *MultiClient) setPeerHead(peerID [64]byte, hash common.Hash, td *big.Int) {
+	cs.peerHeads.Store(peerID, peerHead{hash: hash, td: td})
+}
+
+func (cs *MultiClient) removePeerHead(peerID [64]byte) {
+	cs.peerHeads.Delete(peerID)
+}
+
+// Peers lists the peers connected to all the sentries, with the head each peer announced when we know it, and why its
+// fork ID is incompatible with ours if it is.
+// A peer connected to several sentries is listed once. The sentries which can't list their peers are reported in
+// errs, one error each, and the peers of the other sentries are listed anyway.
+func (cs *MultiClient) Peers(ctx context.Context) (peers []*proto_types.PeerInfo, errs []error) {
+	sentries := cs.Sentries()
+	replies := make([][]*proto_types.PeerInfo, len(sentries))
+	sentryErrs := make([]error, len(sentries))
+	var wg sync.WaitGroup
+	for i, sentry := range sentries {
+		if ready, ok := sentry.(interface{ Ready() bool }); ok && !ready.Ready() {
+			sentryErrs[i] = errors.New("not ready")
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply, err := sentry.Peers(ctx, &emptypb.Empty{})
+			if err != nil {
+				sentryErrs[i] = err
+				return
+			}
+			replies[i] = reply.Peers
+		}()
+	}
+	wg.Wait()
+
+	byID := map[string]*proto_types.PeerInfo{}
+	for i := range sentries {
+		if sentryErrs[i] != nil {
+			errs = append(errs, fmt.Errorf("sentry %d: %w", i, sentryErrs[i]))
+			continue
+		}
+		for _, peer := range replies[i] {
+			if known, ok := byID[peer.Id]; ok {
+				for _, c := range peer.Caps {
+					if !slices.Contains(known.Caps, c) {
+						known.Caps = append(known.Caps, c)
+					}
+				}
+				continue
+			}
+			byID[peer.Id] = peer
+			peers = append(peers, peer)
+			if head, ok := cs.peerHead(peer.Id); ok {
+				peer.HeadHash = gointerfaces.ConvertHashToH256(head.hash)
+				if head.td != nil {
+					td, _ := uint256.FromBig(head.td)
+					peer.TotalDifficulty = gointerfaces.ConvertUint256IntToH256(td)
+				}
+			}
+			if reason, ok := cs.peerForkIncompatibility(peer.Id); ok {
+				peer.ForkIncompatibility = string(reason)
+			}
+		}
+	}
+	return peers, errs
+}
+
+// PeerCount counts the peers connected to all the sentries. The sentries which can't count their peers are reported
+// in errs, one error each, and the peers of the other sentries are counted anyway.
+func (cs *MultiClient) PeerCount(ctx context.Context) (count uint64, errs []error) {
+	counts, sentryErrs := cs.sentryPeerCounts(ctx)
+	for i := range counts {
+		if sentryErrs[i] != nil {
+			errs = append(errs, fmt.Errorf("sentry %d: %w", i, sentryErrs[i]))
+			continue
+		}
+		count += counts[i]
+	}
+	return count, errs
+}
+
+// SentryPeers is the count of the peers of a sentry, see PeerCounts.
+type SentryPeers struct {
+	Sentry   int    // its index
+	Protocol string // of its handshake, e.g. "eth68", empty if it didn't shake hands yet
+	Count    uint64
+	Err      error // if the sentry is unavailable, Count is unknown then
+}
+
+// PeerCounts counts the peers connected to each of the sentries, in their order.
+func (cs *MultiClient) PeerCounts(ctx context.Context) []SentryPeers {
+	counts, errs := cs.sentryPeerCounts(ctx)
+	sentries := make([]SentryPeers, len(counts))
+	for i, sentry := range cs.Sentries() {
+		sentries[i] = SentryPeers{Sentry: i, Count: counts[i], Err: errs[i]}
+		if p, ok := sentry.(interface{ Protocol() uint }); ok {
+			sentries[i].Protocol = eth.ProtocolToString[p.Protocol()]
+		}
+	}
+	return sentries
+}
+
+// sentryPeerCounts asks the sentries for the counts of their peers, in parallel.
+func (cs *MultiClient) sentryPeerCounts(ctx context.Context) (counts []uint64, errs []error) {
+	sentries := cs.Sentries()
+	counts = make([]uint64, len(sentries))
+	errs = make([]error, len(sentries))
+	var wg sync.WaitGroup
+	for i, sentry := range sentries {
+		if ready, ok := sentry.(interface{ Ready() bool }); ok && !ready.Ready() {
+			errs[i] = errors.New("not ready")
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply, err := sentry.PeerCount(ctx, &proto_sentry.PeerCountRequest{})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			counts[i] = reply.Count
+		}()
+	}
+	wg.Wait()
+	return counts, errs
+}
+
+// LogPeerCounts logs the counts of the peers of each of the sentries every period, until ctx is done, and keeps them
+// in p2p_sentry_peers. The sentries which can't count their peers are logged as unavailable, and p2p_sentry_available
+// tells them apart in the metrics.
+func (cs *MultiClient) LogPeerCounts(ctx context.Context, every time.Duration) {
+	logEvery := time.NewTicker(every)
+	defer logEvery.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-logEvery.C:
+			cs.logPeerCounts(ctx)
+		}
+	}
+}
+
+func (cs *MultiClient) logPeerCounts(ctx context.Context) {
+	var total uint64
+	logItems := make([]interface{}, 0, 2*len(cs.sentries))
+	for _, sentry := range cs.PeerCounts(ctx) {
+		protocol := sentry.Protocol
+		if protocol == "" {
+			protocol = "unknown"
+		}
+		labels := fmt.Sprintf(`{sentry="%d",protocol="%s"}`, sentry.Sentry, protocol)
+		name := fmt.Sprintf("sentry%d/%s", sentry.Sentry, protocol)
+		if sentry.Err != nil {
+			metrics.GetOrCreateGauge("p2p_sentry_available" + labels).SetUint32(0)
+			logItems = append(logItems, name, "unavailable")
+			cs.logger.Debug("[p2p] Sentry unavailable", "sentry", sentry.Sentry, "protocol", protocol, "err", sentry.Err)
+			continue
+		}
+		metrics.GetOrCreateGauge("p2p_sentry_available" + labels).SetUint32(1)
+		metrics.GetOrCreateGauge("p2p_sentry_peers" + labels).SetUint64(sentry.Count)
+		logItems = append(logItems, name, strconv.FormatUint(sentry.Count, 10))
+		total += sentry.Count
+	}
+	if cs.Hd != nil {
+		logItems = append(logItems, "syncMode", cs.Hd.SyncMode())
+	}
+	if total == 0 {
+		cs.logger.Warn("[p2p] No GoodPeers", logItems...)
+		return
+	}
+	cs.logger.Info("[p2p] GoodPeers", logItems...)
+}
+
+func (cs *MultiClient) peerHead(id string) (peerHead, bool) {
+	peerID, ok := peerIDFromHex(id)
+	if !ok {
+		return peerHead{}, false
+	}
+	head, ok := cs.peerHeads.Load(peerID)
+	if !ok {
+		return peerHead{}, false
+	}
+	return head.(peerHead), true
+}
+
+// peerIDFromHex returns the peer ID of the hex id of a PeerInfo.
+func peerIDFromHex(id string) ([64]byte, bool) {
+	b := common.FromHex(id)
+	if len(b) != 64 {
+		return [64]byte{}, false
+	}
+	return [64]byte(b), true
+}
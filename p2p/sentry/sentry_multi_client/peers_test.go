@@ -0,0 +1,153 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	proto_types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+func TestPeersMergesSentries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	stubSentry := func(peers []*proto_types.PeerInfo, err error) *direct.MockSentryClient {
+		sentry := direct.NewMockSentryClient(ctrl)
+		sentry.EXPECT().Ready().Return(true).AnyTimes()
+		sentry.EXPECT().Peers(gomock.Any(), gomock.Any()).Return(&proto_sentry.PeersReply{Peers: peers}, err).AnyTimes()
+		return sentry
+	}
+	peer1, peer2, peer3 := [64]byte{1}, [64]byte{2}, [64]byte{3}
+	peerInfo := func(id [64]byte, caps ...string) *proto_types.PeerInfo {
+		return &proto_types.PeerInfo{Id: hex.EncodeToString(id[:]), Name: "erigon", Caps: caps}
+	}
+
+	// peer 2 is connected to both sentries, with a different protocol version on each
+	cs := &MultiClient{
+		sentries: []proto_sentry.SentryClient{
+			stubSentry([]*proto_types.PeerInfo{peerInfo(peer1, "eth/68"), peerInfo(peer2, "eth/68")}, nil),
+			stubSentry([]*proto_types.PeerInfo{peerInfo(peer2, "eth/67"), peerInfo(peer3, "eth/67")}, nil),
+			stubSentry(nil, errors.New("connection refused")),
+		},
+		logger: log.New(),
+	}
+	cs.setPeerHead(peer3, common.Hash{3}, big.NewInt(1000))
+
+	peers, errs := cs.Peers(context.Background())
+	require.Len(t, peers, 3)
+	require.Equal(t, hex.EncodeToString(peer1[:]), peers[0].Id)
+	require.Equal(t, hex.EncodeToString(peer2[:]), peers[1].Id)
+	require.Equal(t, []string{"eth/68", "eth/67"}, peers[1].Caps)
+	require.Nil(t, peers[1].HeadHash)
+	require.Equal(t, hex.EncodeToString(peer3[:]), peers[2].Id)
+	require.Equal(t, common.Hash{3}, common.Hash(gointerfaces.ConvertH256ToHash(peers[2].HeadHash)))
+	require.Equal(t, uint64(1000), gointerfaces.ConvertH256ToUint256Int(peers[2].TotalDifficulty).Uint64())
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], "sentry 2: connection refused")
+
+	// the head of a peer is forgotten once it disconnects
+	require.NoError(t, cs.HandlePeerEvent(context.Background(), &proto_sentry.PeerEvent{
+		PeerId:  gointerfaces.ConvertHashToH512(peer3),
+		EventId: proto_sentry.PeerEvent_Disconnect,
+	}, cs.sentries[1]))
+	_, ok := cs.peerHead(hex.EncodeToString(peer3[:]))
+	require.False(t, ok)
+}
+
+func TestPeerCountSumsSentries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	stubSentry := func(ready bool, count uint64, err error) *direct.MockSentryClient {
+		sentry := direct.NewMockSentryClient(ctrl)
+		sentry.EXPECT().Ready().Return(ready).AnyTimes()
+		sentry.EXPECT().PeerCount(gomock.Any(), gomock.Any()).Return(&proto_sentry.PeerCountReply{Count: count}, err).AnyTimes()
+		return sentry
+	}
+
+	cs := &MultiClient{
+		sentries: []proto_sentry.SentryClient{
+			stubSentry(true, 3, nil),
+			stubSentry(true, 0, errors.New("connection refused")),
+			stubSentry(false, 5, nil),
+			stubSentry(true, 4, nil),
+		},
+		logger: log.New(),
+	}
+
+	count, errs := cs.PeerCount(context.Background())
+	require.Equal(t, uint64(7), count)
+	require.Len(t, errs, 2)
+	require.EqualError(t, errs[0], "sentry 1: connection refused")
+	require.EqualError(t, errs[1], "sentry 2: not ready")
+}
+
+func TestPeerCountsPerSentry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	stubSentry := func(protocol uint, count uint64, err error) *direct.MockSentryClient {
+		sentry := direct.NewMockSentryClient(ctrl)
+		sentry.EXPECT().Ready().Return(true).AnyTimes()
+		sentry.EXPECT().Protocol().Return(protocol).AnyTimes()
+		sentry.EXPECT().PeerCount(gomock.Any(), gomock.Any()).Return(&proto_sentry.PeerCountReply{Count: count}, err).AnyTimes()
+		return sentry
+	}
+	var logged []*log.Record
+	logger := log.New()
+	logger.SetHandler(log.FilterHandler(func(r *log.Record) bool {
+		logged = append(logged, r)
+		return false
+	}, log.DiscardHandler()))
+	cs := &MultiClient{
+		sentries: []proto_sentry.SentryClient{
+			stubSentry(direct.ETH68, 25, nil),
+			stubSentry(direct.ETH67, 0, errors.New("connection refused")),
+			stubSentry(direct.ETH65, 3, nil), // before its handshake
+		},
+		logger: logger,
+	}
+
+	counts := cs.PeerCounts(context.Background())
+	require.Len(t, counts, 3)
+	require.Equal(t, SentryPeers{Sentry: 0, Protocol: "eth68", Count: 25}, counts[0])
+	require.Equal(t, "eth67", counts[1].Protocol)
+	require.EqualError(t, counts[1].Err, "connection refused")
+	require.Equal(t, SentryPeers{Sentry: 2, Count: 3}, counts[2])
+
+	cs.logPeerCounts(context.Background())
+	var goodPeers *log.Record
+	for _, r := range logged {
+		if r.Msg == "[p2p] GoodPeers" {
+			goodPeers = r
+		}
+	}
+	require.NotNil(t, goodPeers)
+	require.Equal(t, log.LvlInfo, goodPeers.Lvl)
+	require.Equal(t, []interface{}{"sentry0/eth68", "25", "sentry1/eth67", "unavailable", "sentry2/unknown", "3"}, goodPeers.Ctx)
+	require.Equal(t, float64(25), metrics.GetOrCreateGauge(`p2p_sentry_peers{sentry="0",protocol="eth68"}`).GetValue())
+	require.Equal(t, float64(0), metrics.GetOrCreateGauge(`p2p_sentry_available{sentry="1",protocol="eth67"}`).GetValue())
+	require.Equal(t, float64(1), metrics.GetOrCreateGauge(`p2p_sentry_available{sentry="2",protocol="unknown"}`).GetValue())
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/gointerfaces/txpoolproto"
+	proto_types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon/p2p/protocols/eth"
+)
+
+// The caps of the answers to GetPooledTransactions, the same as the embedded txpool's.
+const (
+	maxPooledTransactionsServe      = 256
+	pooledTransactionsResponseLimit = 100 * 1024
+)
+
+// pooledTransactionsTimeout bounds the query of the txpool, so that an unresponsive pool doesn't hold the upload stream.
+const pooledTransactionsTimeout = 5 * time.Second
+
+// SetTxPool makes MultiClient answer the GetPooledTransactions requests of the peers from txPool, for an external
+// txpool, which doesn't get these requests from our sentries itself. It must be called before StartStreamLoops.
+func (cs *MultiClient) SetTxPool(txPool txpoolproto.TxpoolClient) {
+	cs.txPool = txPool
+	cs.RegisterHandler(proto_sentry.MessageId_GET_POOLED_TRANSACTIONS_66, cs.getPooledTransactions66)
+}
+
+func (cs *MultiClient) getPooledTransactions66(ctx context.Context, inreq *proto_sentry.InboundMessage, sentryClient proto_sentry.SentryClient) error {
+	var query eth.GetPooledTransactionsPacket66
+	if err := rlp.DecodeBytes(inreq.Data, &query); err != nil {
+		return peerMisbehavior("decoding getPooledTransactions66: %w, data: %x", err, inreq.Data)
+	}
+	b, err := rlp.EncodeToBytes(&eth.PooledTransactionsRLPPacket66{
+		RequestId:                   query.RequestId,
+		PooledTransactionsRLPPacket: cs.pooledTransactions(ctx, query.GetPooledTransactionsPacket),
+	})
+	if err != nil {
+		return internalFailure("encode pooled transactions response: %w", err)
+	}
+	msgID, ok := cs.messageIDFor(inreq.PeerId, eth.PooledTransactionsMsg)
+	if !ok {
+		return nil
+	}
+	outreq := proto_sentry.SendMessageByIdRequest{
+		PeerId: inreq.PeerId,
+		Data: &proto_sentry.OutboundMessageData{
+			Id:   msgID,
+			Data: b,
+		},
+	}
+	if err = cs.send(ctx, sentryClient, sendPriorityLow, "pooled transactions response", sendMessageById(&outreq)); err != nil {
+		if isPeerNotFoundErr(err) {
+			return nil
+		}
+		return transientLocal("send pooled transactions response: %w", err)
+	}
+	return nil
+}
+
+// pooledTransactions returns the transactions of the txpool among hashes, in their order, without the unknown ones,
+// and within the caps of an answer. It returns none if the txpool is unreachable: an empty answer is still an answer.
+func (cs *MultiClient) pooledTransactions(ctx context.Context, hashes eth.GetPooledTransactionsPacket) []rlp.RawValue {
+	hashes = hashes[:min(len(hashes), maxPooledTransactionsServe)]
+	if len(hashes) == 0 {
+		return []rlp.RawValue{}
+	}
+	req := &txpoolproto.TransactionsRequest{Hashes: make([]*proto_types.H256, len(hashes))}
+	for i := range hashes {
+		req.Hashes[i] = gointerfaces.ConvertHashToH256(hashes[i])
+	}
+	ctx, cancel := context.WithTimeout(ctx, pooledTransactionsTimeout)
+	defer cancel()
+	reply, err := cs.txPool.Transactions(ctx, req)
+	if err != nil {
+		cs.logger.Debug("[p2p] Could not get the pooled transactions from the txpool", "err", err)
+		return []rlp.RawValue{}
+	}
+
+	txns := make([]rlp.RawValue, 0, len(reply.RlpTxs))
+	bytes := 0
+	for _, txn := range reply.RlpTxs {
+		if bytes >= pooledTransactionsResponseLimit {
+			break
+		}
+		if len(txn) == 0 { // unknown
+			continue
+		}
+		if _, _, isLegacy, err := rlp.Prefix(txn, 0); err == nil && !isLegacy {
+			// a typed transaction, whose envelope travels as an RLP string
+			wrapped := make([]byte, rlp.StringLen(txn))
+			rlp.EncodeString2(txn, wrapped)
+			txn = wrapped
+		}
+		txns = append(txns, txn)
+		bytes += len(txn)
+	}
+	return txns
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/gointerfaces/txpoolproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon/p2p/protocols/eth"
+)
+
+// stubTxPool is a txpool with the transactions of txns, unreachable if down.
+type stubTxPool struct {
+	txpoolproto.TxpoolClient
+	txns     map[common.Hash][]byte
+	down     bool
+	requests int
+}
+
+func (p *stubTxPool) Transactions(_ context.Context, in *txpoolproto.TransactionsRequest, _ ...grpc.CallOption) (*txpoolproto.TransactionsReply, error) {
+	p.requests++
+	if p.down {
+		return nil, errors.New("connection refused")
+	}
+	reply := &txpoolproto.TransactionsReply{RlpTxs: make([][]byte, len(in.Hashes))}
+	for i, hash := range in.Hashes {
+		reply.RlpTxs[i] = append([]byte{}, p.txns[gointerfaces.ConvertH256ToHash(hash)]...)
+	}
+	return reply, nil
+}
+
+func TestGetPooledTransactions(t *testing.T) {
+	legacy := []byte{0xc1, 0x01}      // an RLP list
+	typed := []byte{0x02, 0xc1, 0x02} // a type byte, then an RLP list
+	pool := &stubTxPool{txns: map[common.Hash][]byte{{1}: legacy, {2}: typed}}
+	peer := [64]byte{1}
+
+	query := func(t *testing.T, hashes ...common.Hash) eth.PooledTransactionsRLPPacket66 {
+		sentry := direct.NewMockSentryClient(gomock.NewController(t))
+		var reply eth.PooledTransactionsRLPPacket66
+		sentry.EXPECT().SendMessageById(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req *proto_sentry.SendMessageByIdRequest, _ ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+				require.Equal(t, proto_sentry.MessageId_POOLED_TRANSACTIONS_66, req.Data.Id)
+				require.Equal(t, peer, gointerfaces.ConvertH512ToHash(req.PeerId))
+				require.NoError(t, rlp.DecodeBytes(req.Data.Data, &reply))
+				return &proto_sentry.SentPeers{}, nil
+			})
+		cs := &MultiClient{logger: log.New()}
+		cs.registerDefaultHandlers()
+		cs.SetTxPool(pool)
+		data, err := rlp.EncodeToBytes(&eth.GetPooledTransactionsPacket66{RequestId: 42, GetPooledTransactionsPacket: hashes})
+		require.NoError(t, err)
+		require.NoError(t, cs.HandleInboundMessage(context.Background(), &proto_sentry.InboundMessage{
+			Id:     proto_sentry.MessageId_GET_POOLED_TRANSACTIONS_66,
+			Data:   data,
+			PeerId: gointerfaces.ConvertHashToH512(peer),
+		}, sentry))
+		require.Equal(t, uint64(42), reply.RequestId)
+		return reply
+	}
+
+	t.Run("known", func(t *testing.T) {
+		reply := query(t, common.Hash{2}, common.Hash{1})
+		// in the order requested, the typed transaction as an RLP string
+		require.Equal(t, eth.PooledTransactionsRLPPacket{{0x83, 0x02, 0xc1, 0x02}, legacy}, reply.PooledTransactionsRLPPacket)
+	})
+	t.Run("unknown", func(t *testing.T) {
+		reply := query(t, common.Hash{3}, common.Hash{1}, common.Hash{4})
+		require.Equal(t, eth.PooledTransactionsRLPPacket{legacy}, reply.PooledTransactionsRLPPacket)
+	})
+	t.Run("capped", func(t *testing.T) {
+		hashes := make([]common.Hash, 2*maxPooledTransactionsServe)
+		for i := range hashes {
+			hashes[i] = common.Hash{1}
+		}
+		reply := query(t, hashes...)
+		require.Len(t, reply.PooledTransactionsRLPPacket, maxPooledTransactionsServe)
+	})
+	t.Run("pool down", func(t *testing.T) {
+		pool.down = true
+		defer func() { pool.down = false }()
+		requests := pool.requests
+		reply := query(t, common.Hash{1})
+		require.Empty(t, reply.PooledTransactionsRLPPacket)
+		require.Equal(t, requests+1, pool.requests)
+	})
+}
+
+func TestGetPooledTransactionsWithoutTxPool(t *testing.T) {
+	// neither subscribed to nor answered, no txpool to answer from
+	sentry := direct.NewMockSentryClient(gomock.NewController(t))
+	cs := &MultiClient{logger: log.New()}
+	cs.registerDefaultHandlers()
+	require.NotContains(t, cs.subscribedMessages(uploadStream), proto_sentry.MessageId_GET_POOLED_TRANSACTIONS_66)
+	require.Error(t, cs.HandleInboundMessage(context.Background(), &proto_sentry.InboundMessage{
+		Id:     proto_sentry.MessageId_GET_POOLED_TRANSACTIONS_66,
+		PeerId: gointerfaces.ConvertHashToH512([64]byte{1}),
+	}, sentry))
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/execution/stages/headerdownload"
+)
+
+// blockGossip is how the blocks of an era travel as gossip of the eth protocol.
+type blockGossip struct {
+	era      string
+	in       func(config *chain.Config, header *types.Header) bool // whether the block of header is of the era
+	newBlock bool                                                  // the full block is propagated in NewBlock
+	hashes   bool                                                  // the block is announced in NewBlockHashes
+	penalty  headerdownload.Penalty                                // of the peers sending a block of the era in NewBlock
+}
+
+// blockGossipPolicy is the gossip of the blocks per era, latest first: a block is of the first era it's in. A fork
+// changing the gossip adds its era on top.
+var blockGossipPolicy = []blockGossip{
+	// the blocks referencing blobs are propagated by the consensus layer, along with their blobs
+	{era: "cancun", in: blobEra, penalty: headerdownload.NewBlockGossipBlobEraPenalty},
+	{era: "proof-of-stake", in: proofOfStake, penalty: headerdownload.NewBlockGossipAfterMergePenalty},
+	{era: "proof-of-work", in: func(*chain.Config, *types.Header) bool { return true }, newBlock: true, hashes: true},
+}
+
+// blobEra reports whether the block of header is past Cancun: by the chain config, or by the blob fields of its header
+// for a chain config unaware of the fork.
+func blobEra(config *chain.Config, header *types.Header) bool {
+	return header.BlobGasUsed != nil || header.ExcessBlobGas != nil || config.IsCancun(header.Time)
+}
+
+func proofOfStake(_ *chain.Config, header *types.Header) bool {
+	return header.Difficulty.Sign() == 0
+}
+
+// blockGossipOf returns the gossip of the block of header, per blockGossipPolicy.
+func blockGossipOf(config *chain.Config, header *types.Header) blockGossip {
+	for _, gossip := range blockGossipPolicy {
+		if gossip.in(config, header) {
+			return gossip
+		}
+	}
+	return blockGossip{}
+}
+
+// propagation reports how the block of header is propagated to the peers, as NewBlock and NewBlockHashes: per the
+// gossip of its era, and not at all past the merge, where the consensus layer propagates the blocks.
+func (cs *MultiClient) propagation(header *types.Header) (newBlock, hashes bool) {
+	if cs.ChainConfig.TerminalTotalDifficultyPassed {
+		return false, false
+	}
+	if firstPosSeen := cs.Hd.FirstPoSHeight(); firstPosSeen != nil && *firstPosSeen < header.Number.Uint64() {
+		return false, false
+	}
+	gossip := blockGossipOf(cs.ChainConfig, header)
+	return gossip.newBlock, gossip.hashes
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+// The delays from a block coming, from a peer or produced by us, to its announcements leaving through a sentry.
+var (
+	peerBlockPropagationSeconds     = metrics.GetOrCreateHistogram(`p2p_block_propagation_seconds{origin="peer"}`)
+	producedBlockPropagationSeconds = metrics.GetOrCreateHistogram(`p2p_block_propagation_seconds{origin="produced"}`)
+)
+
+const (
+	blockPropagationKept  = 256 // the blocks kept, the oldest are forgotten
+	producedDelaysRolling = 16  // the last produced blocks whose delays are averaged in the log
+)
+
+type blockCame struct {
+	number    uint64
+	at        time.Time
+	produced  bool
+	announced bool // through all the sentries, its later announcements aren't measured
+}
+
+// blockPropagation measures how long the blocks take to be announced to the peers. Its zero value is ready to use.
+type blockPropagation struct {
+	lock           sync.Mutex
+	blocks         map[common.Hash]blockCame
+	order          []common.Hash   // of the blocks, the oldest first
+	producedDelays []time.Duration // of the last produced blocks, see producedDelaysRolling
+	now            func() time.Time
+	peerDelay      metrics.Histogram // peerBlockPropagationSeconds if nil
+	producedDelay  metrics.Histogram // producedBlockPropagationSeconds if nil
+}
+
+func (p *blockPropagation) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// came records when a block came, unless it came already: the first NewBlock or NewBlockHashes of it is what counts.
+// A block we produced always counts from its production.
+func (p *blockPropagation) came(number uint64, hash common.Hash, produced bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.blocks == nil {
+		p.blocks = map[common.Hash]blockCame{}
+	}
+	came, ok := p.blocks[hash]
+	if ok && (came.produced || came.announced || !produced) {
+		return
+	}
+	if !ok {
+		p.order = append(p.order, hash)
+	}
+	p.blocks[hash] = blockCame{number: number, at: p.clock(), produced: produced}
+	for len(p.order) > blockPropagationKept {
+		delete(p.blocks, p.order[0])
+		p.order = p.order[1:]
+	}
+}
+
+// sent observes the delays of the blocks of hashes, whose announcements left through the sentry.
+func (p *blockPropagation) sent(hashes []common.Hash, sentry int, logger log.Logger) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.clock()
+	for _, hash := range hashes {
+		came, ok := p.blocks[hash]
+		if !ok || came.announced {
+			continue
+		}
+		delay := now.Sub(came.at)
+		histogram := p.peerDelay
+		if histogram == nil {
+			histogram = peerBlockPropagationSeconds
+		}
+		if came.produced {
+			if histogram = p.producedDelay; histogram == nil {
+				histogram = producedBlockPropagationSeconds
+			}
+		}
+		histogram.Observe(delay.Seconds())
+		logger.Trace("[p2p] Block announced", "number", came.number, "hash", hash, "sentry", sentry,
+			"produced", came.produced, "delay", delay)
+	}
+}
+
+// finished marks the blocks of hashes announced through all the sentries, so that their later announcements aren't
+// measured, and logs the ones we produced with the average delay of the last ones.
+func (p *blockPropagation) finished(hashes []common.Hash, logger log.Logger) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.clock()
+	for _, hash := range hashes {
+		came, ok := p.blocks[hash]
+		if !ok || came.announced {
+			continue
+		}
+		came.announced = true
+		p.blocks[hash] = came
+		if !came.produced {
+			continue
+		}
+		delay := now.Sub(came.at)
+		if len(p.producedDelays) == producedDelaysRolling {
+			p.producedDelays = p.producedDelays[1:]
+		}
+		p.producedDelays = append(p.producedDelays, delay)
+		var total time.Duration
+		for _, d := range p.producedDelays {
+			total += d
+		}
+		logger.Info("[p2p] Produced block announced", "number", came.number, "hash", hash, "delay", delay,
+			"avgDelay", total/time.Duration(len(p.producedDelays)), "blocks", len(p.producedDelays))
+	}
+}
+
+// MarkBlockProduced makes the announcements of a block we produced measured from now, see
+// p2p_block_propagation_seconds{origin="produced"}. The block producers call it before handing the block over.
+func (cs *MultiClient) MarkBlockProduced(number uint64, hash common.Hash) {
+	cs.blockPropagation.came(number, hash, true)
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/direct"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+	"github.com/erigontech/erigon/execution/stages/headerdownload"
+)
+
+type observedHistogram struct {
+	metrics.Histogram
+	observed []float64
+}
+
+func (h *observedHistogram) Observe(v float64) { h.observed = append(h.observed, v) }
+
+func TestBlockPropagationDelays(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	// each sentry takes its time to send the announcements
+	sentryDelays := []time.Duration{100 * time.Millisecond, 250 * time.Millisecond}
+	sentries := make([]proto_sentry.SentryClient, len(sentryDelays))
+	for i, delay := range sentryDelays {
+		sentry := direct.NewMockSentryClient(gomock.NewController(t))
+		sentry.EXPECT().Ready().Return(true).AnyTimes()
+		sentry.EXPECT().SendMessageToAll(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, *proto_sentry.OutboundMessageData, ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+				now = now.Add(delay)
+				return &proto_sentry.SentPeers{}, nil
+			}).AnyTimes()
+		sentries[i] = sentry
+	}
+	peerDelay, producedDelay := &observedHistogram{}, &observedHistogram{}
+	var logged []string
+	logger := log.New()
+	logger.SetHandler(log.FilterHandler(func(r *log.Record) bool {
+		if r.Lvl == log.LvlInfo {
+			logged = append(logged, r.Msg)
+		}
+		return false
+	}, log.DiscardHandler()))
+	cs := &MultiClient{sentries: sentries, logger: logger}
+	cs.blockPropagation.now = func() time.Time { return now }
+	cs.blockPropagation.peerDelay, cs.blockPropagation.producedDelay = peerDelay, producedDelay
+
+	// a block from a peer, announced after 2s of processing
+	cs.blockPropagation.came(10, common.Hash{10}, false)
+	now = now.Add(2 * time.Second)
+	cs.blockPropagation.came(10, common.Hash{10}, false) // the later receipts don't count
+	cs.PropagateNewBlockHashes(context.Background(), []headerdownload.Announce{{Number: 10, Hash: common.Hash{10}}})
+	require.InDeltaSlice(t, []float64{2.1, 2.35}, peerDelay.observed, 1e-9)
+	require.Empty(t, producedDelay.observed)
+	require.Empty(t, logged)
+
+	// its later announcements aren't measured
+	cs.PropagateNewBlockHashes(context.Background(), []headerdownload.Announce{{Number: 10, Hash: common.Hash{10}}})
+	require.Len(t, peerDelay.observed, 2)
+
+	// a block we produced, announced after 500ms, and logged
+	cs.MarkBlockProduced(11, common.Hash{11})
+	now = now.Add(500 * time.Millisecond)
+	cs.PropagateNewBlockHashes(context.Background(), []headerdownload.Announce{{Number: 11, Hash: common.Hash{11}}})
+	require.InDeltaSlice(t, []float64{0.6, 0.85}, producedDelay.observed, 1e-9)
+	require.Len(t, peerDelay.observed, 2)
+	require.Equal(t, []string{"[p2p] Produced block announced"}, logged)
+
+	// the blocks never announced are forgotten eventually
+	for i := 0; i < blockPropagationKept+1; i++ {
+		cs.blockPropagation.came(uint64(100+i), common.Hash{byte(i), 1}, false)
+	}
+	require.Len(t, cs.blockPropagation.blocks, blockPropagationKept)
+}
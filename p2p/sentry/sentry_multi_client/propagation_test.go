@@ -0,0 +1,122 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/direct"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	proto_types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/execution/stages/headerdownload"
+)
+
+const testCancunTime = 1000
+
+func testGossipHeader(time uint64, difficulty int64, blobFields bool) *types.Header {
+	header := &types.Header{Number: big.NewInt(1), Time: time, Difficulty: big.NewInt(difficulty)}
+	if blobFields {
+		blobGasUsed, excessBlobGas := uint64(0), uint64(0)
+		header.BlobGasUsed, header.ExcessBlobGas = &blobGasUsed, &excessBlobGas
+	}
+	return header
+}
+
+func TestBlockGossipPolicy(t *testing.T) {
+	cancunTime := uint64(testCancunTime)
+	config := &chain.Config{CancunTime: &cancunTime}
+	noCancun := &chain.Config{}
+
+	for _, tt := range []struct {
+		name     string
+		config   *chain.Config
+		header   *types.Header
+		era      string
+		newBlock bool
+		penalty  headerdownload.Penalty
+	}{
+		{name: "pre-cancun pow", config: config, header: testGossipHeader(testCancunTime-1, 1, false), era: "proof-of-work", newBlock: true},
+		{name: "pre-cancun pos", config: config, header: testGossipHeader(testCancunTime-1, 0, false), era: "proof-of-stake", penalty: headerdownload.NewBlockGossipAfterMergePenalty},
+		{name: "cancun activation", config: config, header: testGossipHeader(testCancunTime, 0, true), era: "cancun", penalty: headerdownload.NewBlockGossipBlobEraPenalty},
+		{name: "post-cancun", config: config, header: testGossipHeader(testCancunTime+12, 0, true), era: "cancun", penalty: headerdownload.NewBlockGossipBlobEraPenalty},
+		// at the boundary, either the chain config or the header fields place the block past cancun
+		{name: "cancun activation without blob fields", config: config, header: testGossipHeader(testCancunTime, 0, false), era: "cancun", penalty: headerdownload.NewBlockGossipBlobEraPenalty},
+		{name: "blob fields before cancun", config: config, header: testGossipHeader(testCancunTime-1, 0, true), era: "cancun", penalty: headerdownload.NewBlockGossipBlobEraPenalty},
+		{name: "blob fields, chain without cancun", config: noCancun, header: testGossipHeader(testCancunTime, 1, true), era: "cancun", penalty: headerdownload.NewBlockGossipBlobEraPenalty},
+		{name: "chain without cancun", config: noCancun, header: testGossipHeader(testCancunTime, 1, false), era: "proof-of-work", newBlock: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			gossip := blockGossipOf(tt.config, tt.header)
+			require.Equal(t, tt.era, gossip.era)
+			require.Equal(t, tt.newBlock, gossip.newBlock)
+			require.Equal(t, tt.newBlock, gossip.hashes)
+			require.Equal(t, tt.penalty, gossip.penalty)
+		})
+	}
+}
+
+func TestBroadcastNewBlockFollowsGossipPolicy(t *testing.T) {
+	cancunTime := uint64(testCancunTime)
+	peers := make([]*proto_types.PeerInfo, 4)
+	for i := range peers {
+		id := [64]byte{byte(i + 1)}
+		peers[i] = &proto_types.PeerInfo{Id: hex.EncodeToString(id[:])}
+	}
+	broadcast := func(t *testing.T, header *types.Header) map[proto_sentry.MessageId]int {
+		sentry := direct.NewMockSentryClient(gomock.NewController(t))
+		sentry.EXPECT().Ready().Return(true).AnyTimes()
+		sentry.EXPECT().Peers(gomock.Any(), gomock.Any()).Return(&proto_sentry.PeersReply{Peers: peers}, nil).AnyTimes()
+		sent := map[proto_sentry.MessageId]int{}
+		sentry.EXPECT().SendMessageById(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req *proto_sentry.SendMessageByIdRequest, _ ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+				sent[req.Data.Id]++
+				return &proto_sentry.SentPeers{}, nil
+			}).AnyTimes()
+		cs := &MultiClient{
+			sentries:    []proto_sentry.SentryClient{sentry},
+			ChainConfig: &chain.Config{CancunTime: &cancunTime},
+			Hd:          headerdownload.NewHeaderDownload(16, 16, nil, nil, log.New()),
+			logger:      log.New(),
+		}
+		cs.BroadcastNewBlock(context.Background(), header, &types.RawBody{}, big.NewInt(1))
+		return sent
+	}
+
+	t.Run("pre-cancun", func(t *testing.T) {
+		sent := broadcast(t, testGossipHeader(testCancunTime-1, 1, false))
+		require.Equal(t, map[proto_sentry.MessageId]int{
+			proto_sentry.MessageId_NEW_BLOCK_66:        2,
+			proto_sentry.MessageId_NEW_BLOCK_HASHES_66: 2,
+		}, sent)
+	})
+	t.Run("transition boundary", func(t *testing.T) {
+		require.Empty(t, broadcast(t, testGossipHeader(testCancunTime, 1, false)))
+	})
+	t.Run("post-cancun", func(t *testing.T) {
+		require.Empty(t, broadcast(t, testGossipHeader(testCancunTime+12, 1, true)))
+	})
+}
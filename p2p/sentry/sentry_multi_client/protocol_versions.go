@@ -0,0 +1,85 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"encoding/hex"
+
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	proto_types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
+	"github.com/erigontech/erigon-lib/metrics"
+	"github.com/erigontech/erigon/p2p/protocols/eth"
+)
+
+var inboundMessagesNotInProtocol = metrics.GetOrCreateCounter("p2p_inbound_messages_not_in_protocol")
+
+// setPeerVersion keeps the version of the eth protocol a connected peer negotiated, from its capabilities.
+func (cs *MultiClient) setPeerVersion(peerID [64]byte, caps []string) {
+	if version, ok := eth.NegotiatedVersion(caps); ok {
+		cs.peerVersions.Store(peerID, version)
+	}
+}
+
+func (cs *MultiClient) removePeerVersion(peerID [64]byte) {
+	cs.peerVersions.Delete(peerID)
+}
+
+// peerVersion returns the version of the eth protocol a connected peer negotiated, if we know it.
+func (cs *MultiClient) peerVersion(peerID [64]byte) (uint, bool) {
+	version, ok := cs.peerVersions.Load(peerID)
+	if !ok {
+		return 0, false
+	}
+	return version.(uint), true
+}
+
+// messageIDFor returns the id of the message msg to send to a peer, which selects its encoding, in the version of the
+// eth protocol the peer negotiated, eth/67 if we don't know it. ok is false if the version has no such message: the
+// peer must be skipped.
+func (cs *MultiClient) messageIDFor(peerId *proto_types.H512, msg uint64) (proto_sentry.MessageId, bool) {
+	version := uint(direct.ETH67)
+	if peerId != nil {
+		if v, ok := cs.peerVersion(gointerfaces.ConvertH512ToHash(peerId)); ok {
+			version = v
+		}
+	}
+	return eth.MessageID(version, msg)
+}
+
+// checkInboundVersion rejects a message which isn't one of the version of the eth protocol its peer negotiated, e.g.
+// an eth/67 announcement of transactions from an eth/68 peer, as the peer's misbehavior. The messages of the peers whose
+// version we don't know are let through.
+func (cs *MultiClient) checkInboundVersion(inreq *proto_sentry.InboundMessage) error {
+	if inreq.PeerId == nil {
+		return nil
+	}
+	peerID := gointerfaces.ConvertH512ToHash(inreq.PeerId)
+	version, ok := cs.peerVersion(peerID)
+	if !ok {
+		return nil
+	}
+	if _, ok := eth.MessageCode(version, inreq.Id); ok {
+		return nil
+	}
+
+	inboundMessagesNotInProtocol.Inc()
+	cs.logger.Debug("[p2p] Message not in the protocol of the peer", "peer", hex.EncodeToString(peerID[:]),
+		"msg", inreq.Id.String(), "version", version)
+	return peerMisbehavior("message %s not in eth/%d of the peer", inreq.Id, version)
+}
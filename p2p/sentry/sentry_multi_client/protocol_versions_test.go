@@ -0,0 +1,143 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	proto_types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/execution/stages/headerdownload"
+	"github.com/erigontech/erigon/p2p/protocols/eth"
+)
+
+func TestPeerVersions(t *testing.T) {
+	peer67, peer68, unknown := [64]byte{1}, [64]byte{2}, [64]byte{3}
+	caps := map[[64]byte][]string{
+		peer67: {"eth/67", "snap/1"},
+		peer68: {"eth/67", "eth/68"},
+	}
+	sentry := direct.NewMockSentryClient(gomock.NewController(t))
+	sentry.EXPECT().PeerById(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *proto_sentry.PeerByIdRequest, _ ...grpc.CallOption) (*proto_sentry.PeerByIdReply, error) {
+			return &proto_sentry.PeerByIdReply{Peer: &proto_types.PeerInfo{Caps: caps[gointerfaces.ConvertH512ToHash(req.PeerId)]}}, nil
+		}).AnyTimes()
+	var kicked [][64]byte
+	sentry.EXPECT().PenalizePeer(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *proto_sentry.PenalizePeerRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+			require.Equal(t, proto_sentry.PenaltyKind_Kick, req.Penalty)
+			kicked = append(kicked, gointerfaces.ConvertH512ToHash(req.PeerId))
+			return &emptypb.Empty{}, nil
+		}).AnyTimes()
+	cs := &MultiClient{logger: log.New()}
+	cs.registerDefaultHandlers()
+	peerEvent := func(peerID [64]byte, event proto_sentry.PeerEvent_PeerEventId) {
+		require.NoError(t, cs.HandlePeerEvent(context.Background(), &proto_sentry.PeerEvent{
+			PeerId:  gointerfaces.ConvertHashToH512(peerID),
+			EventId: event,
+		}, sentry))
+	}
+	peerEvent(peer67, proto_sentry.PeerEvent_Connect)
+	peerEvent(peer68, proto_sentry.PeerEvent_Connect)
+
+	// the encoding of the announcements of the transactions follows the version of the peer, eth/67 if unknown
+	for peerID, want := range map[[64]byte]proto_sentry.MessageId{
+		peer67:  proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_66,
+		peer68:  proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_68,
+		unknown: proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_66,
+	} {
+		id, ok := cs.messageIDFor(gointerfaces.ConvertHashToH512(peerID), eth.NewPooledTransactionHashesMsg)
+		require.True(t, ok)
+		require.Equal(t, want, id)
+	}
+	id, ok := cs.messageIDFor(nil, eth.BlockHeadersMsg)
+	require.True(t, ok)
+	require.Equal(t, proto_sentry.MessageId_BLOCK_HEADERS_66, id)
+
+	// the messages out of the version of the peer are rejected, and the peer kicked
+	var handled [][64]byte
+	cs.RegisterHandler(proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_66, func(_ context.Context, inreq *proto_sentry.InboundMessage, _ proto_sentry.SentryClient) error {
+		handled = append(handled, gointerfaces.ConvertH512ToHash(inreq.PeerId))
+		return nil
+	})
+	announce := func(peerID [64]byte) error {
+		return cs.HandleInboundMessage(context.Background(), &proto_sentry.InboundMessage{
+			Id:     proto_sentry.MessageId_NEW_POOLED_TRANSACTION_HASHES_66,
+			Data:   []byte{0xc0},
+			PeerId: gointerfaces.ConvertHashToH512(peerID),
+		}, sentry)
+	}
+	rejected := inboundMessagesNotInProtocol.GetValue()
+	require.NoError(t, announce(peer67))
+	require.Error(t, announce(peer68))
+	require.NoError(t, announce(unknown))
+	require.Equal(t, [][64]byte{peer67, unknown}, handled)
+	require.Equal(t, [][64]byte{peer68}, kicked)
+	require.Equal(t, rejected+1, inboundMessagesNotInProtocol.GetValue())
+
+	// the version of a peer is forgotten once it disconnects
+	peerEvent(peer68, proto_sentry.PeerEvent_Disconnect)
+	_, ok = cs.peerVersion(peer68)
+	require.False(t, ok)
+	require.NoError(t, announce(peer68))
+}
+
+func TestBroadcastNewBlockToMixedVersions(t *testing.T) {
+	peers := make([]*proto_types.PeerInfo, 4)
+	for i := range peers {
+		id := [64]byte{byte(i + 1)}
+		peers[i] = &proto_types.PeerInfo{Id: hex.EncodeToString(id[:]), Caps: []string{"eth/67"}}
+		if i%2 == 1 {
+			peers[i].Caps = []string{"eth/67", "eth/68"}
+		}
+	}
+	sentry := direct.NewMockSentryClient(gomock.NewController(t))
+	sentry.EXPECT().Ready().Return(true).AnyTimes()
+	sentry.EXPECT().Peers(gomock.Any(), gomock.Any()).Return(&proto_sentry.PeersReply{Peers: peers}, nil).AnyTimes()
+	sent := map[proto_sentry.MessageId]int{}
+	sentry.EXPECT().SendMessageById(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *proto_sentry.SendMessageByIdRequest, _ ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+			sent[req.Data.Id]++
+			return &proto_sentry.SentPeers{}, nil
+		}).AnyTimes()
+	cancunTime := uint64(testCancunTime)
+	cs := &MultiClient{
+		sentries:    []proto_sentry.SentryClient{sentry},
+		ChainConfig: &chain.Config{CancunTime: &cancunTime},
+		Hd:          headerdownload.NewHeaderDownload(16, 16, nil, nil, log.New()),
+		logger:      log.New(),
+	}
+	cs.BroadcastNewBlock(context.Background(), testGossipHeader(testCancunTime-1, 1, false), &types.RawBody{}, big.NewInt(1))
+	// both versions have the block gossip, with the same encodings
+	require.Equal(t, map[proto_sentry.MessageId]int{
+		proto_sentry.MessageId_NEW_BLOCK_66:        2,
+		proto_sentry.MessageId_NEW_BLOCK_HASHES_66: 2,
+	}, sent)
+}
@@ -0,0 +1,262 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/mdbx"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+	"github.com/erigontech/erigon/eth/ethconfig"
+)
+
+// The reputation a peer earns or loses on each of its signals.
+const (
+	reputationPenalty = -10.0 // e.g. an invalid header or message
+	reputationUseless = -1.0  // e.g. an empty bodies response
+	reputationUseful  = 1.0   // e.g. a headers response
+)
+
+const peerReputationFlushEvery = time.Minute
+
+var (
+	badReputationPeersObserved = metrics.GetOrCreateCounter(`p2p_bad_reputation_peers{action="observed"}`)
+	badReputationPeersKicked   = metrics.GetOrCreateCounter(`p2p_bad_reputation_peers{action="kicked"}`)
+)
+
+type reputation struct {
+	score   float64
+	updated time.Time
+}
+
+// decayed returns the score at now: it decays linearly to nothing over horizon since its last update.
+func (r reputation) decayed(now time.Time, horizon time.Duration) float64 {
+	elapsed := now.Sub(r.updated)
+	if elapsed >= horizon {
+		return 0
+	}
+	if elapsed <= 0 {
+		return r.score
+	}
+	return r.score * (1 - float64(elapsed)/float64(horizon))
+}
+
+// PeerReputation keeps the reputation of the peers, built from their penalties and useful responses, in the
+// kv.PeerReputation table of a db of its own, kv.PeerReputationDB, so that after a restart we don't reconnect to the
+// same bad peers first. The reputations live in memory and are written in the background, see Run. A nil
+// PeerReputation keeps nothing.
+type PeerReputation struct {
+	db     kv.RwDB
+	cfg    ethconfig.PeerReputation
+	logger log.Logger
+	now    func() time.Time
+
+	lock  sync.Mutex
+	peers map[[64]byte]reputation
+	dirty map[[64]byte]struct{} // changed since the last flush
+}
+
+// OpenPeerReputation opens the peer reputation db in dataDir, creating it if needed, and loads the reputations.
+func OpenPeerReputation(ctx context.Context, dataDir string, cfg ethconfig.PeerReputation, logger log.Logger) (*PeerReputation, error) {
+	db, err := mdbx.New(kv.PeerReputationDB, logger).
+		Path(filepath.Join(dataDir, kv.PeerReputationDB)).
+		WithTableCfg(func(_ kv.TableCfg) kv.TableCfg { return kv.PeerReputationTablesCfg }).
+		MapSize(1 * datasize.GB).
+		GrowthStep(2 * datasize.MB).
+		Open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("open peer reputation: %w", err)
+	}
+	r, err := NewPeerReputation(ctx, db, cfg, logger)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewPeerReputation loads the reputations stored in db, which must have the kv.PeerReputationTablesCfg tables.
+func NewPeerReputation(ctx context.Context, db kv.RwDB, cfg ethconfig.PeerReputation, logger log.Logger) (*PeerReputation, error) {
+	r := &PeerReputation{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+		peers:  map[[64]byte]reputation{},
+		dirty:  map[[64]byte]struct{}{},
+	}
+	if err := db.View(ctx, func(tx kv.Tx) error {
+		return tx.ForEach(kv.PeerReputation, nil, func(k, v []byte) error {
+			if len(k) != 64 || len(v) != 16 {
+				return nil
+			}
+			r.peers[[64]byte(k)] = reputation{
+				score:   math.Float64frombits(binary.BigEndian.Uint64(v)),
+				updated: time.Unix(int64(binary.BigEndian.Uint64(v[8:])), 0),
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("load peer reputation: %w", err)
+	}
+	return r, nil
+}
+
+// Record adds delta to the reputation of a peer.
+func (r *PeerReputation) Record(peerID [64]byte, delta float64) {
+	if r == nil {
+		return
+	}
+	now := r.now()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.peers[peerID] = reputation{score: r.peers[peerID].decayed(now, r.cfg.Horizon) + delta, updated: now}
+	r.dirty[peerID] = struct{}{}
+}
+
+// Score returns the reputation of a peer, 0 if it has none.
+func (r *PeerReputation) Score(peerID [64]byte) float64 {
+	if r == nil {
+		return 0
+	}
+	now := r.now()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.peers[peerID].decayed(now, r.cfg.Horizon)
+}
+
+// Bad reports whether the reputation of a peer is below the threshold.
+func (r *PeerReputation) Bad(peerID [64]byte) (score float64, bad bool) {
+	if r == nil {
+		return 0, false
+	}
+	score = r.Score(peerID)
+	return score, score < r.cfg.Threshold
+}
+
+// Flush writes the reputations changed since the last flush, and deletes the ones decayed to nothing.
+func (r *PeerReputation) Flush(ctx context.Context) error {
+	now := r.now()
+	r.lock.Lock()
+	changed := make(map[[64]byte]reputation, len(r.dirty))
+	for peerID := range r.dirty {
+		changed[peerID] = r.peers[peerID]
+	}
+	var expired [][64]byte
+	for peerID, rep := range r.peers {
+		if now.Sub(rep.updated) >= r.cfg.Horizon {
+			expired = append(expired, peerID)
+			delete(r.peers, peerID)
+			delete(changed, peerID)
+		}
+	}
+	clear(r.dirty)
+	r.lock.Unlock()
+
+	if len(changed) == 0 && len(expired) == 0 {
+		return nil
+	}
+	err := r.db.Update(ctx, func(tx kv.RwTx) error {
+		v := make([]byte, 16)
+		for peerID, rep := range changed {
+			binary.BigEndian.PutUint64(v, math.Float64bits(rep.score))
+			binary.BigEndian.PutUint64(v[8:], uint64(rep.updated.Unix()))
+			if err := tx.Put(kv.PeerReputation, peerID[:], v); err != nil {
+				return err
+			}
+		}
+		for _, peerID := range expired {
+			if err := tx.Delete(kv.PeerReputation, peerID[:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// written by the next flush
+		r.lock.Lock()
+		for peerID := range changed {
+			if _, ok := r.peers[peerID]; ok {
+				r.dirty[peerID] = struct{}{}
+			}
+		}
+		r.lock.Unlock()
+	}
+	return err
+}
+
+// Run flushes the reputations periodically until ctx is done.
+func (r *PeerReputation) Run(ctx context.Context) {
+	ticker := time.NewTicker(peerReputationFlushEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Flush(ctx); err != nil {
+				r.logger.Warn("[p2p] Failed to write the peer reputation", "err", err)
+			}
+		}
+	}
+}
+
+// Close flushes the reputations, then closes the db.
+func (r *PeerReputation) Close() {
+	if err := r.Flush(context.Background()); err != nil {
+		r.logger.Warn("[p2p] Failed to write the peer reputation", "err", err)
+	}
+	r.db.Close()
+}
+
+// SetPeerReputation makes MultiClient keep the reputation of the peers in r, and check it when they connect.
+func (cs *MultiClient) SetPeerReputation(r *PeerReputation) { cs.reputation = r }
+
+// checkPeerReputation logs a connected peer with a bad reputation, and kicks it if the reputation is enforced.
+func (cs *MultiClient) checkPeerReputation(ctx context.Context, peerID [64]byte, sentryClient proto_sentry.SentryClient) {
+	score, bad := cs.reputation.Bad(peerID)
+	if !bad {
+		return
+	}
+	if !cs.reputation.cfg.Enforce {
+		badReputationPeersObserved.Inc()
+		cs.logger.Debug("[p2p] Peer with bad reputation connected", "peer", hex.EncodeToString(peerID[:]), "reputation", score)
+		return
+	}
+	badReputationPeersKicked.Inc()
+	cs.logger.Debug("[p2p] Kicking peer with bad reputation", "peer", hex.EncodeToString(peerID[:]), "reputation", score)
+	penalizeRequest := proto_sentry.PenalizePeerRequest{
+		PeerId:  gointerfaces.ConvertHashToH512(peerID),
+		Penalty: proto_sentry.PenaltyKind_Kick,
+	}
+	if err := cs.send(ctx, sentryClient, sendPriorityHigh, "penalty", penalizePeer(&penalizeRequest)); err != nil {
+		cs.logger.Error("Could not send penalty", "err", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/execution/stages/headerdownload"
+)
+
+func newTestPeerReputation(t *testing.T, db kv.RwDB, cfg ethconfig.PeerReputation, now *time.Time) *PeerReputation {
+	r, err := NewPeerReputation(context.Background(), db, cfg, log.New())
+	require.NoError(t, err)
+	r.now = func() time.Time { return *now }
+	return r
+}
+
+func TestPeerReputationAccumulatesAndPersists(t *testing.T) {
+	db := memdb.NewTestDB(t, kv.PeerReputationDB)
+	cfg := ethconfig.PeerReputation{Threshold: -20, Horizon: time.Hour}
+	now := time.Unix(1_700_000_000, 0)
+	r := newTestPeerReputation(t, db, cfg, &now)
+	bad, good := [64]byte{1}, [64]byte{2}
+
+	sentry := direct.NewMockSentryClient(gomock.NewController(t))
+	sentry.EXPECT().Ready().Return(true).AnyTimes()
+	sentry.EXPECT().PenalizePeer(gomock.Any(), gomock.Any(), gomock.Any()).Return(&emptypb.Empty{}, nil).Times(2)
+	cs := &MultiClient{sentries: []proto_sentry.SentryClient{sentry}, reputation: r, logger: log.New()}
+	cs.Penalize(context.Background(), []headerdownload.PenaltyItem{{PeerID: bad}, {PeerID: bad}})
+	r.Record(bad, reputationUseful)
+	r.Record(good, reputationUseful)
+	r.Record(good, reputationUseful)
+	r.Record(good, reputationUseless)
+	require.Equal(t, -19.0, r.Score(bad))
+	require.Equal(t, 1.0, r.Score(good))
+	require.Zero(t, r.Score([64]byte{3}))
+	_, isBad := r.Bad(bad)
+	require.False(t, isBad)
+	r.Record(bad, reputationUseless)
+	score, isBad := r.Bad(bad)
+	require.True(t, isBad)
+	require.Equal(t, -20.0, score)
+
+	// a restart loads the reputations flushed
+	require.NoError(t, r.Flush(context.Background()))
+	r = newTestPeerReputation(t, db, cfg, &now)
+	require.Equal(t, -20.0, r.Score(bad))
+	require.Equal(t, 1.0, r.Score(good))
+}
+
+func TestPeerReputationDecays(t *testing.T) {
+	db := memdb.NewTestDB(t, kv.PeerReputationDB)
+	cfg := ethconfig.PeerReputation{Threshold: -20, Horizon: time.Hour}
+	now := time.Unix(1_700_000_000, 0)
+	r := newTestPeerReputation(t, db, cfg, &now)
+	bad, returning := [64]byte{1}, [64]byte{2}
+
+	r.Record(bad, 4*reputationPenalty)
+	now = now.Add(cfg.Horizon / 4)
+	require.Equal(t, -30.0, r.Score(bad))
+	// the new penalties add to the decayed reputation, and restart the decay
+	r.Record(bad, reputationPenalty)
+	now = now.Add(cfg.Horizon / 2)
+	require.Equal(t, -20.0, r.Score(bad))
+	r.Record(returning, reputationPenalty)
+	require.NoError(t, r.Flush(context.Background()))
+
+	// old sins are forgotten after the horizon, on disk too
+	now = now.Add(cfg.Horizon)
+	require.Zero(t, r.Score(bad))
+	r.Record(returning, reputationUseful)
+	require.NoError(t, r.Flush(context.Background()))
+	require.NoError(t, db.View(context.Background(), func(tx kv.Tx) error {
+		count, err := tx.Count(kv.PeerReputation)
+		require.Equal(t, uint64(1), count)
+		return err
+	}))
+}
+
+func TestPeerReputationOnConnect(t *testing.T) {
+	db := memdb.NewTestDB(t, kv.PeerReputationDB)
+	now := time.Unix(1_700_000_000, 0)
+	bad, good := [64]byte{1}, [64]byte{2}
+	connect := func(cs *MultiClient, sentry proto_sentry.SentryClient, peerID [64]byte) {
+		require.NoError(t, cs.HandlePeerEvent(context.Background(), &proto_sentry.PeerEvent{
+			PeerId:  gointerfaces.ConvertHashToH512(peerID),
+			EventId: proto_sentry.PeerEvent_Connect,
+		}, sentry))
+	}
+
+	t.Run("observe only", func(t *testing.T) {
+		r := newTestPeerReputation(t, db, ethconfig.PeerReputation{Threshold: -20, Horizon: time.Hour}, &now)
+		r.Record(bad, 3*reputationPenalty)
+		// no penalty is sent to the sentry
+		sentry := direct.NewMockSentryClient(gomock.NewController(t))
+		sentry.EXPECT().PeerById(gomock.Any(), gomock.Any(), gomock.Any()).Return(&proto_sentry.PeerByIdReply{}, nil).AnyTimes()
+		cs := &MultiClient{reputation: r, logger: log.New()}
+		observed := badReputationPeersObserved.GetValue()
+		connect(cs, sentry, bad)
+		connect(cs, sentry, good)
+		require.Equal(t, observed+1, badReputationPeersObserved.GetValue())
+	})
+
+	t.Run("enforced", func(t *testing.T) {
+		r := newTestPeerReputation(t, db, ethconfig.PeerReputation{Enforce: true, Threshold: -20, Horizon: time.Hour}, &now)
+		r.Record(bad, 3*reputationPenalty)
+		sentry := direct.NewMockSentryClient(gomock.NewController(t))
+		sentry.EXPECT().PeerById(gomock.Any(), gomock.Any(), gomock.Any()).Return(&proto_sentry.PeerByIdReply{}, nil).AnyTimes()
+		var kicked [][64]byte
+		sentry.EXPECT().PenalizePeer(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req *proto_sentry.PenalizePeerRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+				require.Equal(t, proto_sentry.PenaltyKind_Kick, req.Penalty)
+				kicked = append(kicked, gointerfaces.ConvertH512ToHash(req.PeerId))
+				return &emptypb.Empty{}, nil
+			}).AnyTimes()
+		cs := &MultiClient{reputation: r, logger: log.New()}
+		connect(cs, sentry, bad)
+		connect(cs, sentry, good)
+		require.Equal(t, [][64]byte{bad}, kicked)
+	})
+}
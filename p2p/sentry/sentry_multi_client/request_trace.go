@@ -0,0 +1,153 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"encoding/hex"
+	"sync/atomic"
+	"time"
+)
+
+// The kinds of the requests traced.
+const (
+	headersRequest = "headers"
+	bodiesRequest  = "bodies"
+)
+
+// The outcomes of the requests traced.
+const (
+	requestPending   = "pending"   // not answered, yet or ever
+	requestAnswered  = "answered"  // answered with something
+	requestEmpty     = "empty"     // answered with nothing
+	requestPenalized = "penalized" // answered, and the peer which answered penalized for it
+)
+
+// RequestTrace is the lifecycle of a request to the peers, identified by the RequestId of the eth protocol, which is
+// also in the debug logs of erigon and the sentry.
+type RequestTrace struct {
+	RequestID  uint64    `json:"requestId"`
+	Kind       string    `json:"kind"`
+	Peers      []string  `json:"peers"` // the request was sent to
+	SentAt     time.Time `json:"sentAt"`
+	AnsweredBy string    `json:"answeredBy,omitempty"` // the first of the peers to answer
+	AnsweredAt time.Time `json:"answeredAt,omitzero"`
+	Outcome    string    `json:"outcome"`
+}
+
+// requestTraces is a ring of the last request lifecycles. It's lock-free: the traces are immutable, and replaced as a
+// whole in their slot. A nil requestTraces traces nothing.
+type requestTraces struct {
+	slots []atomic.Pointer[RequestTrace]
+	next  atomic.Uint64 // count of the requests traced, the next one goes in the slot next % len(slots)
+	now   func() time.Time
+}
+
+// newRequestTraces returns a ring of the last size request lifecycles, nil if size is 0.
+func newRequestTraces(size int) *requestTraces {
+	if size <= 0 {
+		return nil
+	}
+	return &requestTraces{slots: make([]atomic.Pointer[RequestTrace], size), now: time.Now}
+}
+
+// sent traces a request sent to peers, overwriting the oldest trace once the ring is full.
+func (t *requestTraces) sent(requestID uint64, kind string, peers ...[64]byte) {
+	if t == nil {
+		return
+	}
+	trace := &RequestTrace{RequestID: requestID, Kind: kind, Peers: make([]string, len(peers)), SentAt: t.now(), Outcome: requestPending}
+	for i, peerID := range peers {
+		trace.Peers[i] = hex.EncodeToString(peerID[:])
+	}
+	i := t.next.Add(1) - 1
+	t.slots[i%uint64(len(t.slots))].Store(trace)
+}
+
+// answered records the answer of a peer to a request, unless another peer answered it first.
+func (t *requestTraces) answered(requestID uint64, peerID [64]byte, empty bool) {
+	if t == nil {
+		return
+	}
+	now := t.now()
+	t.update(requestID, func(trace *RequestTrace) bool {
+		if trace.AnsweredBy != "" {
+			return false
+		}
+		trace.AnsweredBy, trace.AnsweredAt, trace.Outcome = hex.EncodeToString(peerID[:]), now, requestAnswered
+		if empty {
+			trace.Outcome = requestEmpty
+		}
+		return true
+	})
+}
+
+// penalized records the penalty of the peer which answered a request, for its answer.
+func (t *requestTraces) penalized(requestID uint64, peerID [64]byte) {
+	if t == nil {
+		return
+	}
+	peer := hex.EncodeToString(peerID[:])
+	t.update(requestID, func(trace *RequestTrace) bool {
+		if trace.AnsweredBy != peer {
+			return false
+		}
+		trace.Outcome = requestPenalized
+		return true
+	})
+}
+
+// update applies change to a copy of the trace of a request, if it's still in the ring, and replaces the trace with
+// the copy if change returns true. The ring is searched from the latest request, the likeliest to be answered.
+func (t *requestTraces) update(requestID uint64, change func(*RequestTrace) bool) {
+	next, size := t.next.Load(), uint64(len(t.slots))
+	for i := next; i > 0 && next-i < size; i-- {
+		slot := &t.slots[(i-1)%size]
+		for {
+			trace := slot.Load()
+			if trace == nil || trace.RequestID != requestID {
+				break
+			}
+			changed := *trace
+			if !change(&changed) {
+				return
+			}
+			if slot.CompareAndSwap(trace, &changed) {
+				return
+			}
+			// changed or overwritten meanwhile, try again
+		}
+	}
+}
+
+// list returns the traces in the ring, the latest first.
+func (t *requestTraces) list() []RequestTrace {
+	if t == nil {
+		return nil
+	}
+	next, size := t.next.Load(), uint64(len(t.slots))
+	traces := make([]RequestTrace, 0, min(next, size))
+	for i := next; i > 0 && next-i < size; i-- {
+		if trace := t.slots[(i-1)%size].Load(); trace != nil {
+			traces = append(traces, *trace)
+		}
+	}
+	return traces
+}
+
+// RequestTraces returns the lifecycles of the last header and body requests to the peers, the latest first, none if
+// the requests aren't traced, see ethconfig.Sync.RequestTraces.
+func (cs *MultiClient) RequestTraces() []RequestTrace { return cs.requestTraces.list() }
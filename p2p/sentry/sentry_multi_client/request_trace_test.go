@@ -0,0 +1,140 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	proto_types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/execution/stages/bodydownload"
+	"github.com/erigontech/erigon/execution/stages/headerdownload"
+	"github.com/erigontech/erigon/p2p/protocols/eth"
+)
+
+func TestRequestTracesRing(t *testing.T) {
+	require.Nil(t, newRequestTraces(0))
+	var disabled *requestTraces
+	disabled.sent(1, headersRequest, [64]byte{1})
+	disabled.answered(1, [64]byte{1}, false)
+	require.Empty(t, disabled.list())
+
+	traces := newRequestTraces(3)
+	peer1, peer2 := [64]byte{1}, [64]byte{2}
+	for id := uint64(1); id <= 4; id++ {
+		traces.sent(id, headersRequest, peer1, peer2)
+	}
+	// the oldest request is overwritten, so its answer is dropped
+	traces.answered(1, peer1, false)
+	traces.answered(3, peer2, false)
+	traces.answered(3, peer1, true) // answered by peer 2 first
+	traces.answered(4, peer1, true)
+	traces.penalized(4, peer2) // didn't answer
+
+	list := traces.list()
+	require.Len(t, list, 3)
+	require.Equal(t, []uint64{4, 3, 2}, []uint64{list[0].RequestID, list[1].RequestID, list[2].RequestID})
+	require.Equal(t, requestEmpty, list[0].Outcome)
+	require.Equal(t, hex.EncodeToString(peer1[:]), list[0].AnsweredBy)
+	require.Equal(t, requestAnswered, list[1].Outcome)
+	require.Equal(t, hex.EncodeToString(peer2[:]), list[1].AnsweredBy)
+	require.Equal(t, []string{hex.EncodeToString(peer1[:]), hex.EncodeToString(peer2[:])}, list[1].Peers)
+	require.Equal(t, requestPending, list[2].Outcome)
+	require.Empty(t, list[2].AnsweredBy)
+	require.True(t, list[2].AnsweredAt.IsZero())
+
+	traces.penalized(3, peer2)
+	require.Equal(t, requestPenalized, traces.list()[1].Outcome)
+}
+
+func TestRequestTraceLifecycle(t *testing.T) {
+	peer := [64]byte{1}
+	sentAt := time.Unix(1_700_000_000, 0)
+	now := sentAt
+
+	var sent []*proto_sentry.OutboundMessageData
+	sentry := direct.NewMockSentryClient(gomock.NewController(t))
+	sentry.EXPECT().Ready().Return(true).AnyTimes()
+	sentry.EXPECT().SendMessageByMinBlock(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *proto_sentry.SendMessageByMinBlockRequest, _ ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+			sent = append(sent, req.Data)
+			return &proto_sentry.SentPeers{Peers: []*proto_types.H512{gointerfaces.ConvertHashToH512(peer)}}, nil
+		}).AnyTimes()
+	sentry.EXPECT().PeerMinBlock(gomock.Any(), gomock.Any(), gomock.Any()).Return(&emptypb.Empty{}, nil).AnyTimes()
+	cs := &MultiClient{
+		sentries:      []proto_sentry.SentryClient{sentry},
+		Hd:            headerdownload.NewHeaderDownload(16, 16, nil, nil, log.New()),
+		Bd:            bodydownload.NewBodyDownload(nil, 16, 0, nil, log.New()),
+		logger:        log.New(),
+		requestTraces: newRequestTraces(16),
+	}
+	cs.requestTraces.now = func() time.Time { return now }
+	cs.registerDefaultHandlers()
+	respond := func(id proto_sentry.MessageId, packet any) {
+		data, err := rlp.EncodeToBytes(packet)
+		require.NoError(t, err)
+		require.NoError(t, cs.HandleInboundMessage(context.Background(), &proto_sentry.InboundMessage{Id: id, Data: data, PeerId: gointerfaces.ConvertHashToH512(peer)}, sentry))
+	}
+
+	// a body request answered with a body
+	_, ok := cs.SendBodyRequest(context.Background(), &bodydownload.BodyRequest{BlockNums: []uint64{1}, Hashes: []common.Hash{{1}}})
+	require.True(t, ok)
+	var bodiesReq eth.GetBlockBodiesPacket66
+	require.NoError(t, rlp.DecodeBytes(sent[0].Data, &bodiesReq))
+	now = now.Add(time.Second)
+	respond(proto_sentry.MessageId_BLOCK_BODIES_66, &eth.BlockRawBodiesPacket66{
+		RequestId:            bodiesReq.RequestId,
+		BlockRawBodiesPacket: eth.BlockRawBodiesPacket{&types.RawBody{Transactions: [][]byte{{1}}}},
+	})
+
+	// a header request answered with no header
+	_, ok = cs.SendHeaderRequest(context.Background(), &headerdownload.HeaderRequest{Number: 1, Length: 1})
+	require.True(t, ok)
+	var headersReq eth.GetBlockHeadersPacket66
+	require.NoError(t, rlp.DecodeBytes(sent[1].Data, &headersReq))
+	now = now.Add(time.Second)
+	respond(proto_sentry.MessageId_BLOCK_HEADERS_66, &eth.BlockHeadersPacket66{RequestId: headersReq.RequestId})
+
+	// a header request never answered
+	_, ok = cs.SendHeaderRequest(context.Background(), &headerdownload.HeaderRequest{Number: 2, Length: 1})
+	require.True(t, ok)
+	var unansweredReq eth.GetBlockHeadersPacket66
+	require.NoError(t, rlp.DecodeBytes(sent[2].Data, &unansweredReq))
+
+	peerHex := hex.EncodeToString(peer[:])
+	require.Equal(t, []RequestTrace{
+		{RequestID: unansweredReq.RequestId, Kind: headersRequest, Peers: []string{peerHex}, SentAt: sentAt.Add(2 * time.Second), Outcome: requestPending},
+		{RequestID: headersReq.RequestId, Kind: headersRequest, Peers: []string{peerHex}, SentAt: sentAt.Add(time.Second),
+			AnsweredBy: peerHex, AnsweredAt: sentAt.Add(2 * time.Second), Outcome: requestEmpty},
+		{RequestID: bodiesReq.RequestId, Kind: bodiesRequest, Peers: []string{peerHex}, SentAt: sentAt,
+			AnsweredBy: peerHex, AnsweredAt: sentAt.Add(time.Second), Outcome: requestAnswered},
+	}, cs.RequestTraces())
+}
@@ -0,0 +1,199 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+// sendPriority is the class of an outbound message in the send queue of a sentry, the high ones going out first.
+type sendPriority int
+
+const (
+	sendPriorityHigh sendPriority = iota // the control messages, penalties and min blocks, and the headers
+	sendPriorityLow                      // the bodies, receipts and pooled transactions, which may be heavy
+	sendPriorities
+)
+
+func (p sendPriority) String() string {
+	if p == sendPriorityHigh {
+		return "high"
+	}
+	return "low"
+}
+
+// sendQueueSize bounds the messages waiting to be sent to a sentry, both priorities together.
+const sendQueueSize = 1024
+
+// errSendDropped is returned for a message dropped by the send queue of a sentry, full of high priority ones.
+var errSendDropped = errors.New("send queue full, message dropped")
+
+var sendsDropped = [sendPriorities]metrics.Counter{
+	sendPriorityHigh: metrics.GetOrCreateCounter(`p2p_outbound_sends_dropped{priority="high"}`),
+	sendPriorityLow:  metrics.GetOrCreateCounter(`p2p_outbound_sends_dropped{priority="low"}`),
+}
+
+// outboundSend is a message waiting in a send queue, what naming it in the logs.
+type outboundSend struct {
+	what string
+	send func(ctx context.Context, sentryClient proto_sentry.SentryClient) error
+}
+
+// sendQueue holds the messages to a sentry until its sender sends them, the high priority ones first, each priority in
+// order. When it's full the oldest low priority message is dropped for the new one, a high priority one being dropped
+// only if there is no low priority one to drop.
+type sendQueue struct {
+	lock    sync.Mutex
+	pending [sendPriorities][]outboundSend
+	size    int
+	maxSize int
+	ready   chan struct{} // signalled when a message is queued
+	running atomic.Bool   // while the sender runs, the messages being sent synchronously otherwise
+}
+
+func newSendQueue(maxSize int) *sendQueue {
+	return &sendQueue{maxSize: maxSize, ready: make(chan struct{}, 1)}
+}
+
+// push queues the message, and returns false if it's the one dropped.
+func (q *sendQueue) push(priority sendPriority, msg outboundSend) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.size >= q.maxSize {
+		if len(q.pending[sendPriorityLow]) == 0 {
+			sendsDropped[priority].Inc()
+			return false
+		}
+		q.pending[sendPriorityLow][0] = outboundSend{}
+		q.pending[sendPriorityLow] = q.pending[sendPriorityLow][1:]
+		q.size--
+		sendsDropped[sendPriorityLow].Inc()
+	}
+	q.pending[priority] = append(q.pending[priority], msg)
+	q.size++
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// pop returns the oldest message of the highest priority, false if there is none.
+func (q *sendQueue) pop() (outboundSend, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for priority := range q.pending {
+		if pending := q.pending[priority]; len(pending) > 0 {
+			msg := pending[0]
+			pending[0] = outboundSend{}
+			q.pending[priority] = pending[1:]
+			q.size--
+			return msg, true
+		}
+	}
+	return outboundSend{}, false
+}
+
+// run sends the queued messages to sentryClient until ctx is done, logging the failures but the ones to the peers
+// gone.
+func (q *sendQueue) run(ctx context.Context, sentryClient proto_sentry.SentryClient, logger log.Logger) {
+	defer q.running.Store(false)
+
+	for {
+		msg, ok := q.pop()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.ready:
+				continue
+			}
+		}
+		if err := msg.send(ctx, sentryClient); err != nil && !isPeerNotFoundErr(err) {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Debug("[p2p] Could not send", "msg", msg.what, "err", err)
+		}
+	}
+}
+
+// startSenders starts the sender of each sentry, the messages to it being queued from then on.
+func (cs *MultiClient) startSenders(ctx context.Context) {
+	for i, q := range cs.sendQueues {
+		q.running.Store(true)
+		go q.run(ctx, cs.sentries[i], cs.logger)
+	}
+}
+
+// send queues a message to sentryClient with the priority, or sends it synchronously when no sender of the sentry
+// runs, e.g. for the mock sentries, the error being returned then. A queued message is sent in the ctx of the sender,
+// errSendDropped being returned if the queue drops it rather than an older one.
+func (cs *MultiClient) send(ctx context.Context, sentryClient proto_sentry.SentryClient, priority sendPriority, what string, send func(ctx context.Context, sentryClient proto_sentry.SentryClient) error) error {
+	if q := cs.sendQueue(sentryClient); q != nil && q.running.Load() {
+		if !q.push(priority, outboundSend{what: what, send: send}) {
+			return errSendDropped
+		}
+		return nil
+	}
+	return send(ctx, sentryClient)
+}
+
+func (cs *MultiClient) sendQueue(sentryClient proto_sentry.SentryClient) *sendQueue {
+	if len(cs.sendQueues) != len(cs.sentries) {
+		return nil
+	}
+	for i, sentry := range cs.sentries {
+		if sentry == sentryClient {
+			return cs.sendQueues[i]
+		}
+	}
+	return nil
+}
+
+func sendMessageById(req *proto_sentry.SendMessageByIdRequest) func(context.Context, proto_sentry.SentryClient) error {
+	return func(ctx context.Context, sentryClient proto_sentry.SentryClient) error {
+		_, err := sentryClient.SendMessageById(ctx, req, &grpc.EmptyCallOption{})
+		return err
+	}
+}
+
+func peerMinBlock(req *proto_sentry.PeerMinBlockRequest) func(context.Context, proto_sentry.SentryClient) error {
+	return func(ctx context.Context, sentryClient proto_sentry.SentryClient) error {
+		_, err := sentryClient.PeerMinBlock(ctx, req, &grpc.EmptyCallOption{})
+		return err
+	}
+}
+
+func penalizePeer(req *proto_sentry.PenalizePeerRequest) func(context.Context, proto_sentry.SentryClient) error {
+	return func(ctx context.Context, sentryClient proto_sentry.SentryClient) error {
+		_, err := sentryClient.PenalizePeer(ctx, req, &grpc.EmptyCallOption{})
+		return err
+	}
+}
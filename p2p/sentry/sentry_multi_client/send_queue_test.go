@@ -0,0 +1,145 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+
+	"github.com/erigontech/erigon-lib/direct"
+	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+func TestSendQueuePriorities(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// the sender holds the first message until all the others are queued
+	sent, release := make(chan string, 100), make(chan struct{})
+	sentry := direct.NewMockSentryClient(gomock.NewController(t))
+	sentry.EXPECT().SendMessageById(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *proto_sentry.SendMessageByIdRequest, _ ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+			if string(req.Data.Data) == "first" {
+				<-release
+			}
+			sent <- string(req.Data.Data)
+			return &proto_sentry.SentPeers{}, nil
+		}).AnyTimes()
+	cs := &MultiClient{
+		sentries:   []proto_sentry.SentryClient{sentry},
+		sendQueues: []*sendQueue{newSendQueue(8)},
+		logger:     log.New(),
+	}
+	cs.startSenders(ctx)
+
+	send := func(priority sendPriority, name string) {
+		outreq := &proto_sentry.SendMessageByIdRequest{Data: &proto_sentry.OutboundMessageData{Data: []byte(name)}}
+		require.NoError(t, cs.send(ctx, sentry, priority, name, sendMessageById(outreq)))
+	}
+	send(sendPriorityHigh, "first")
+	require.Eventually(t, func() bool {
+		q := cs.sendQueues[0]
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		return q.size == 0
+	}, 5*time.Second, time.Millisecond)
+
+	droppedHigh, droppedLow := sendsDropped[sendPriorityHigh].GetValueUint64(), sendsDropped[sendPriorityLow].GetValueUint64()
+	// the low priority class saturating the queue, with the high priority messages among
+	var low int
+	sendLows := func(n int) {
+		for range n {
+			send(sendPriorityLow, fmt.Sprintf("low %d", low))
+			low++
+		}
+	}
+	sendLows(6)
+	send(sendPriorityHigh, "high 0")
+	sendLows(6)
+	send(sendPriorityHigh, "high 1")
+	send(sendPriorityHigh, "high 2")
+	sendLows(8)
+	send(sendPriorityHigh, "high 3")
+	close(release)
+
+	var got []string
+	for range 9 {
+		select {
+		case name := <-sent:
+			got = append(got, name)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("sent %v", got)
+		}
+	}
+	// the high priority messages go out first, in order, and the oldest low priority ones are dropped for them
+	require.Equal(t, []string{"first", "high 0", "high 1", "high 2", "high 3", "low 16", "low 17", "low 18", "low 19"}, got)
+	require.Equal(t, droppedHigh, sendsDropped[sendPriorityHigh].GetValueUint64())
+	require.Equal(t, droppedLow+16, sendsDropped[sendPriorityLow].GetValueUint64())
+
+	// a queue full of high priority messages drops the new ones
+	q := newSendQueue(2)
+	for i := range 3 {
+		require.Equal(t, i < 2, q.push(sendPriorityHigh, outboundSend{what: fmt.Sprintf("high %d", i)}))
+	}
+	require.False(t, q.push(sendPriorityLow, outboundSend{what: "low"}))
+	require.Equal(t, droppedHigh+1, sendsDropped[sendPriorityHigh].GetValueUint64())
+	require.Equal(t, droppedLow+17, sendsDropped[sendPriorityLow].GetValueUint64())
+	for i := range 2 {
+		msg, ok := q.pop()
+		require.True(t, ok)
+		require.Equal(t, fmt.Sprintf("high %d", i), msg.what)
+	}
+	_, ok := q.pop()
+	require.False(t, ok)
+}
+
+func TestSendWithoutSender(t *testing.T) {
+	// with no sender running, e.g. for a mock sentry, the message is sent synchronously, the error being returned
+	sentry := direct.NewMockSentryClient(gomock.NewController(t))
+	sentry.EXPECT().PenalizePeer(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("unavailable"))
+	cs := &MultiClient{
+		sentries:   []proto_sentry.SentryClient{sentry},
+		sendQueues: []*sendQueue{newSendQueue(8)},
+		logger:     log.New(),
+	}
+	require.ErrorContains(t, cs.send(context.Background(), sentry, sendPriorityHigh, "penalty", penalizePeer(&proto_sentry.PenalizePeerRequest{})), "unavailable")
+	require.Zero(t, cs.sendQueues[0].size)
+}
+
+func TestSendDropped(t *testing.T) {
+	// the sender of the sentry marked running isn't started: the messages stay queued
+	sentry := direct.NewMockSentryClient(gomock.NewController(t))
+	cs := &MultiClient{
+		sentries:   []proto_sentry.SentryClient{sentry},
+		sendQueues: []*sendQueue{newSendQueue(1)},
+		logger:     log.New(),
+	}
+	cs.sendQueues[0].running.Store(true)
+
+	penalty := penalizePeer(&proto_sentry.PenalizePeerRequest{})
+	require.NoError(t, cs.send(context.Background(), sentry, sendPriorityHigh, "penalty", penalty))
+	require.ErrorIs(t, cs.send(context.Background(), sentry, sendPriorityHigh, "penalty", penalty), errSendDropped)
+	require.ErrorIs(t, cs.send(context.Background(), sentry, sendPriorityLow, "bodies response", penalty), errSendDropped)
+	require.Equal(t, 1, cs.sendQueues[0].size)
+}
@@ -100,12 +100,13 @@ func (cs *MultiClient) SendBodyRequest(ctx context.Context, req *bodydownload.Bo
 			)
 			continue
 		}
-		if cs.logger.Enabled(ctx, log.LvlTrace) {
+		cs.requestTraces.sent(packet.RequestId, bodiesRequest, sentPeerIDs(sentPeers)...)
+		if cs.logger.Enabled(ctx, log.LvlDebug) {
 			fromNum, toNum := req.FromBlockNum(), req.ToBlockNum()
 			fromHash, toHash := req.FromBlockHash(), req.ToBlockHash()
 			for _, p := range sentPeers.Peers {
 				pid := sentry.ConvertH512ToPeerID(p)
-				cs.logger.Trace(
+				cs.logger.Debug(
 					"body request sent to peer",
 					"reqId", packet.RequestId,
 					"fromNum", fromNum,
@@ -171,10 +172,11 @@ func (cs *MultiClient) SendHeaderRequest(ctx context.Context, req *headerdownloa
 			)
 			continue
 		}
-		if cs.logger.Enabled(ctx, log.LvlTrace) {
+		cs.requestTraces.sent(reqData.RequestId, headersRequest, sentPeerIDs(sentPeers)...)
+		if cs.logger.Enabled(ctx, log.LvlDebug) {
 			for _, p := range sentPeers.Peers {
 				pid := sentry.ConvertH512ToPeerID(p)
-				cs.logger.Trace(
+				cs.logger.Debug(
 					"header request sent to peer",
 					"reqId", reqData.RequestId,
 					"height", req.Number,
@@ -190,6 +192,14 @@ func (cs *MultiClient) SendHeaderRequest(ctx context.Context, req *headerdownloa
 	return [64]byte{}, false
 }
 
+func sentPeerIDs(sentPeers *proto_sentry.SentPeers) [][64]byte {
+	peerIDs := make([][64]byte, len(sentPeers.Peers))
+	for i, p := range sentPeers.Peers {
+		peerIDs[i] = sentry.ConvertH512ToPeerID(p)
+	}
+	return peerIDs
+}
+
 func (cs *MultiClient) randSentryIndex() (int, bool, func() (int, bool)) {
 	var i int
 	if len(cs.sentries) > 1 {
@@ -205,6 +215,7 @@ func (cs *MultiClient) randSentryIndex() (int, bool, func() (int, bool)) {
 // sending list of penalties to all sentries
 func (cs *MultiClient) Penalize(ctx context.Context, penalties []headerdownload.PenaltyItem) {
 	for i := range penalties {
+		cs.reputation.Record(penalties[i].PeerID, reputationPenalty)
 		outreq := proto_sentry.PenalizePeerRequest{
 			PeerId:  gointerfaces.ConvertHashToH512(penalties[i].PeerID),
 			Penalty: proto_sentry.PenaltyKind_Kick, // TODO: Extend penalty kinds
@@ -214,7 +225,7 @@ func (cs *MultiClient) Penalize(ctx context.Context, penalties []headerdownload.
 				continue
 			}
 
-			if _, err1 := cs.sentries[i].PenalizePeer(ctx, &outreq, &grpc.EmptyCallOption{}); err1 != nil {
+			if err1 := cs.send(ctx, cs.sentries[i], sendPriorityHigh, "penalty", penalizePeer(&outreq)); err1 != nil {
 				cs.logger.Error("Could not send penalty", "err", err1)
 			}
 		}
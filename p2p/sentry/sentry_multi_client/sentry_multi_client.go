@@ -37,7 +37,9 @@ import (
 	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/common/dbg"
 	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
 	proto_sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/gointerfaces/txpoolproto"
 	proto_types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/log/v3"
@@ -52,6 +54,7 @@ import (
 	"github.com/erigontech/erigon/p2p/sentry"
 	"github.com/erigontech/erigon/rpc/jsonrpc/receipts"
 	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/shards"
 )
 
 // StartStreamLoops starts message processing loops for all sentries.
@@ -59,8 +62,10 @@ import (
 // RecvMessage - processing incoming headers/bodies
 // RecvUploadMessage - sending bodies/receipts - may be heavy, it's ok to not process this messages enough fast, it's also ok to drop some of these messages if we can't process.
 // RecvUploadHeadersMessage - sending headers - dedicated stream because headers propagation speed important for network health
-// PeerEventsLoop - logging peer connect/disconnect events
+// PeerEventsLoop - logging peer connect/disconnect events and relaying them to the subscribers of peer events
+// The messages to a sentry are queued, and sent by a sender per sentry, see send.
 func (cs *MultiClient) StartStreamLoops(ctx context.Context) {
+	cs.startSenders(ctx)
 	sentries := cs.Sentries()
 	for i := range sentries {
 		sentry := sentries[i]
@@ -76,12 +81,8 @@ func (cs *MultiClient) RecvUploadMessageLoop(
 	sentry proto_sentry.SentryClient,
 	wg *sync.WaitGroup,
 ) {
-	ids := []proto_sentry.MessageId{
-		eth.ToProto[direct.ETH67][eth.GetBlockBodiesMsg],
-		eth.ToProto[direct.ETH67][eth.GetReceiptsMsg],
-	}
 	streamFactory := func(streamCtx context.Context, sentry proto_sentry.SentryClient) (grpc.ClientStream, error) {
-		return sentry.Messages(streamCtx, &proto_sentry.MessagesRequest{Ids: ids}, grpc.WaitForReady(true))
+		return sentry.Messages(streamCtx, &proto_sentry.MessagesRequest{Ids: cs.subscribedMessages(uploadStream)}, grpc.WaitForReady(true))
 	}
 
 	libsentry.ReconnectAndPumpStreamLoop(ctx, sentry, cs.makeStatusData, "RecvUploadMessage", streamFactory, MakeInboundMessage, cs.HandleInboundMessage, wg, cs.logger)
@@ -92,11 +93,8 @@ func (cs *MultiClient) RecvUploadHeadersMessageLoop(
 	sentry proto_sentry.SentryClient,
 	wg *sync.WaitGroup,
 ) {
-	ids := []proto_sentry.MessageId{
-		eth.ToProto[direct.ETH67][eth.GetBlockHeadersMsg],
-	}
 	streamFactory := func(streamCtx context.Context, sentry proto_sentry.SentryClient) (grpc.ClientStream, error) {
-		return sentry.Messages(streamCtx, &proto_sentry.MessagesRequest{Ids: ids}, grpc.WaitForReady(true))
+		return sentry.Messages(streamCtx, &proto_sentry.MessagesRequest{Ids: cs.subscribedMessages(uploadHeadersStream)}, grpc.WaitForReady(true))
 	}
 
 	libsentry.ReconnectAndPumpStreamLoop(ctx, sentry, cs.makeStatusData, "RecvUploadHeadersMessage", streamFactory, MakeInboundMessage, cs.HandleInboundMessage, wg, cs.logger)
@@ -107,14 +105,12 @@ func (cs *MultiClient) RecvMessageLoop(
 	sentry proto_sentry.SentryClient,
 	wg *sync.WaitGroup,
 ) {
-	ids := []proto_sentry.MessageId{
-		eth.ToProto[direct.ETH67][eth.BlockHeadersMsg],
-		eth.ToProto[direct.ETH67][eth.BlockBodiesMsg],
-		eth.ToProto[direct.ETH67][eth.NewBlockHashesMsg],
-		eth.ToProto[direct.ETH67][eth.NewBlockMsg],
-	}
 	streamFactory := func(streamCtx context.Context, sentry proto_sentry.SentryClient) (grpc.ClientStream, error) {
-		return sentry.Messages(streamCtx, &proto_sentry.MessagesRequest{Ids: ids}, grpc.WaitForReady(true))
+		stream, err := sentry.Messages(streamCtx, &proto_sentry.MessagesRequest{Ids: cs.subscribedMessages(messagesStream)}, grpc.WaitForReady(true))
+		if err != nil {
+			return nil, err
+		}
+		return &headersBacklogStream{ClientStream: stream, hd: cs.Hd}, nil
 	}
 
 	libsentry.ReconnectAndPumpStreamLoop(ctx, sentry, cs.makeStatusData, "RecvMessage", streamFactory, MakeInboundMessage, cs.HandleInboundMessage, wg, cs.logger)
@@ -151,13 +147,24 @@ type MultiClient struct {
 	sendHeaderRequestsToMultiplePeers bool
 	maxBlockBroadcastPeers            func(*types.Header) uint
 
-	// disableBlockDownload is meant to be used temporarily for astrid until work to
-	// decouple sentry multi client from header and body downloading logic is done
-	disableBlockDownload bool
-
 	logger                           log.Logger
 	getReceiptsActiveGoroutineNumber *semaphore.Weighted
 	ethApiWrapper                    eth.ReceiptsGetter
+	events                           *shards.Events           // nil if the peer events and the sync mode changes aren't relayed
+	peerHeads                        sync.Map                 // peer ID -> peerHead, for the peers which sent a NewBlock
+	peerForks                        sync.Map                 // peer ID -> forkIncompatibility, for the peers with a fork ID incompatible with ours
+	peerVersions                     sync.Map                 // peer ID -> version of the eth protocol negotiated, see setPeerVersion
+	reputation                       *PeerReputation          // nil if the reputation of the peers isn't kept
+	requestTraces                    *requestTraces           // nil if the requests aren't traced
+	txPool                           txpoolproto.TxpoolClient // nil if the pooled transactions requests aren't answered
+	sendQueues                       []*sendQueue             // of the messages to each sentry, in the order of sentries
+	recorder                         *MessageRecorder         // nil if the inbound messages aren't recorded
+
+	handlersLock sync.RWMutex
+	handlers     map[proto_sentry.MessageId]inboundHandler // of the messages subscribed to, see RegisterHandler
+	panics       recoveredPanics                           // of the handlers, see RecoveredPanics
+
+	blockPropagation blockPropagation // of the blocks announced, see MarkBlockProduced
 }
 
 var _ eth.ReceiptsGetter = new(receipts.Generator) // compile-time interface-check
@@ -168,6 +175,7 @@ func NewMultiClient(
 	engine consensus.Engine,
 	sentries []proto_sentry.SentryClient,
 	syncCfg ethconfig.Sync,
+	receiptsGenerator *receipts.Generator, // nil for one dedicated to p2p
 	blockReader services.FullBlockReader,
 	blockBufferSize int,
 	statusDataProvider *sentry.StatusDataProvider,
@@ -189,6 +197,7 @@ func NewMultiClient(
 		if chainConfig.TerminalTotalDifficultyPassed {
 			hd.SetPOSSync(true)
 		}
+		hd.SetPOSBacklogLimit(syncCfg.PosHeadersBacklogLimit)
 		if err := hd.RecoverFromDb(db); err != nil {
 			return nil, fmt.Errorf("recovery from DB failed: %w", err)
 		}
@@ -209,6 +218,10 @@ func NewMultiClient(
 		bd = &bodydownload.BodyDownload{}
 	}
 
+	if receiptsGenerator == nil {
+		receiptsGenerator = receipts.NewGenerator(blockReader, engine, 5*time.Minute, syncCfg.P2PReceiptsCache, "p2p")
+	}
+
 	cs := &MultiClient{
 		Hd:                                hd,
 		Bd:                                bd,
@@ -221,10 +234,23 @@ func NewMultiClient(
 		logPeerInfo:                       logPeerInfo,
 		sendHeaderRequestsToMultiplePeers: chainConfig.TerminalTotalDifficultyPassed,
 		maxBlockBroadcastPeers:            maxBlockBroadcastPeers,
-		disableBlockDownload:              disableBlockDownload,
 		logger:                            logger,
 		getReceiptsActiveGoroutineNumber:  semaphore.NewWeighted(1),
-		ethApiWrapper:                     receipts.NewGenerator(blockReader, engine, 5*time.Minute),
+		ethApiWrapper:                     receiptsGenerator,
+		requestTraces:                     newRequestTraces(syncCfg.RequestTraces),
+	}
+	for range sentries {
+		cs.sendQueues = append(cs.sendQueues, newSendQueue(sendQueueSize))
+	}
+	cs.registerDefaultHandlers()
+	// disableBlockDownload is meant to be used temporarily for astrid until work to
+	// decouple sentry multi client from header and body downloading logic is done
+	if disableBlockDownload {
+		for _, id := range blockDownloadMessages {
+			cs.UnregisterHandler(id)
+		}
+	} else {
+		hd.OnSyncModeChange(cs.onSyncModeChange)
 	}
 
 	return cs, nil
@@ -232,27 +258,38 @@ func NewMultiClient(
 
 func (cs *MultiClient) Sentries() []proto_sentry.SentryClient { return cs.sentries }
 
-func (cs *MultiClient) newBlockHashes66(ctx context.Context, req *proto_sentry.InboundMessage, sentry proto_sentry.SentryClient) error {
-	if cs.disableBlockDownload {
-		return nil
+// RelayPeerEvents makes HandlePeerEvent send the peer events to the subscribers of events, and the header downloader
+// its changes of sync mode.
+func (cs *MultiClient) RelayPeerEvents(events *shards.Events) { cs.events = events }
+
+// SyncMode tells if the new blocks announced by the peers are followed, or dropped in the initial cycle.
+func (cs *MultiClient) SyncMode() headerdownload.SyncMode { return cs.Hd.SyncMode() }
+
+func (cs *MultiClient) onSyncModeChange(mode headerdownload.SyncMode) {
+	if cs.events != nil {
+		cs.events.OnSyncMode(string(mode))
 	}
+}
 
+func (cs *MultiClient) newBlockHashes66(ctx context.Context, req *proto_sentry.InboundMessage, sentry proto_sentry.SentryClient) error {
 	if cs.Hd.InitialCycle() && !cs.Hd.FetchingNew() {
 		return nil
 	}
 	//cs.logger.Info(fmt.Sprintf("NewBlockHashes from [%s]", ConvertH256ToPeerID(req.PeerId)))
 	var request eth.NewBlockHashesPacket
 	if err := rlp.DecodeBytes(req.Data, &request); err != nil {
-		return fmt.Errorf("decode NewBlockHashes66: %w", err)
+		return peerMisbehavior("decode NewBlockHashes66: %w", err)
 	}
 	for _, announce := range request {
+		cs.blockPropagation.came(announce.Number, announce.Hash, false)
 		cs.Hd.SaveExternalAnnounce(announce.Hash)
 		if cs.Hd.HasLink(announce.Hash) {
 			continue
 		}
 		//cs.logger.Info(fmt.Sprintf("Sending header request {hash: %x, height: %d, length: %d}", announce.Hash, announce.Number, 1))
+		requestID := rand.Uint64() // nolint: gosec
 		b, err := rlp.EncodeToBytes(&eth.GetBlockHeadersPacket66{
-			RequestId: rand.Uint64(), // nolint: gosec
+			RequestId: requestID,
 			GetBlockHeadersPacket: &eth.GetBlockHeadersPacket{
 				Amount:  1,
 				Reverse: false,
@@ -261,22 +298,30 @@ func (cs *MultiClient) newBlockHashes66(ctx context.Context, req *proto_sentry.I
 			},
 		})
 		if err != nil {
-			return fmt.Errorf("encode header request: %w", err)
+			return internalFailure("encode header request: %w", err)
+		}
+		msgID, ok := cs.messageIDFor(req.PeerId, eth.GetBlockHeadersMsg)
+		if !ok {
+			continue
 		}
 		outreq := proto_sentry.SendMessageByIdRequest{
 			PeerId: req.PeerId,
 			Data: &proto_sentry.OutboundMessageData{
-				Id:   proto_sentry.MessageId_GET_BLOCK_HEADERS_66,
+				Id:   msgID,
 				Data: b,
 			},
 		}
 
-		if _, err = sentry.SendMessageById(ctx, &outreq, &grpc.EmptyCallOption{}); err != nil {
+		if err = cs.send(ctx, sentry, sendPriorityHigh, "header request", sendMessageById(&outreq)); err != nil {
 			if isPeerNotFoundErr(err) {
 				continue
 			}
-			return fmt.Errorf("send header request: %w", err)
+			return transientLocal("send header request: %w", err)
 		}
+		peerID := gointerfaces.ConvertH512ToHash(req.PeerId)
+		cs.requestTraces.sent(requestID, headersRequest, peerID)
+		cs.logger.Debug("header request sent to peer", "reqId", requestID, "height", announce.Number, "hash", announce.Hash,
+			"length", 1, "peer", hex.EncodeToString(peerID[:]))
 	}
 	return nil
 }
@@ -285,34 +330,34 @@ func (cs *MultiClient) blockHeaders66(ctx context.Context, in *proto_sentry.Inbo
 	// Parse the entire packet from scratch
 	var pkt eth.BlockHeadersPacket66
 	if err := rlp.DecodeBytes(in.Data, &pkt); err != nil {
-		return fmt.Errorf("decode 1 BlockHeadersPacket66: %w", err)
+		return peerMisbehavior("decode 1 BlockHeadersPacket66: %w", err)
 	}
 
 	// Prepare to extract raw headers from the block
 	rlpStream := rlp.NewStream(bytes.NewReader(in.Data), uint64(len(in.Data)))
 	if _, err := rlpStream.List(); err != nil { // Now stream is at the beginning of 66 object
-		return fmt.Errorf("decode 1 BlockHeadersPacket66: %w", err)
+		return peerMisbehavior("decode 1 BlockHeadersPacket66: %w", err)
 	}
 	if _, err := rlpStream.Uint(); err != nil { // Now stream is at the requestID field
-		return fmt.Errorf("decode 2 BlockHeadersPacket66: %w", err)
+		return peerMisbehavior("decode 2 BlockHeadersPacket66: %w", err)
 	}
 	// Now stream is at the BlockHeadersPacket, which is list of headers
+	peerID := gointerfaces.ConvertH512ToHash(in.PeerId)
+	cs.requestTraces.answered(pkt.RequestId, peerID, len(pkt.BlockHeadersPacket) == 0)
+	cs.logger.Debug("header response received", "reqId", pkt.RequestId, "headers", len(pkt.BlockHeadersPacket),
+		"peer", hex.EncodeToString(peerID[:]))
 
-	return cs.blockHeaders(ctx, pkt.BlockHeadersPacket, rlpStream, in.PeerId, sentry)
+	return cs.blockHeaders(ctx, pkt.RequestId, pkt.BlockHeadersPacket, rlpStream, in.PeerId, sentry)
 }
 
-func (cs *MultiClient) blockHeaders(ctx context.Context, pkt eth.BlockHeadersPacket, rlpStream *rlp.Stream, peerID *proto_types.H512, sentryClient proto_sentry.SentryClient) error {
-	if cs.disableBlockDownload {
-		return nil
-	}
-
+func (cs *MultiClient) blockHeaders(ctx context.Context, requestID uint64, pkt eth.BlockHeadersPacket, rlpStream *rlp.Stream, peerID *proto_types.H512, sentryClient proto_sentry.SentryClient) error {
 	if len(pkt) == 0 {
 		// No point processing empty response
 		return nil
 	}
 	// Stream is at the BlockHeadersPacket, which is list of headers
 	if _, err := rlpStream.List(); err != nil {
-		return fmt.Errorf("decode 2 BlockHeadersPacket66: %w", err)
+		return peerMisbehavior("decode 2 BlockHeadersPacket66: %w", err)
 	}
 	// Extract headers from the block
 	//var blockNums []int
@@ -321,7 +366,7 @@ func (cs *MultiClient) blockHeaders(ctx context.Context, pkt eth.BlockHeadersPac
 	for _, header := range pkt {
 		headerRaw, err := rlpStream.Raw()
 		if err != nil {
-			return fmt.Errorf("decode 3 BlockHeadersPacket66: %w", err)
+			return peerMisbehavior("decode 3 BlockHeadersPacket66: %w", err)
 		}
 		hRaw := append([]byte{}, headerRaw...)
 		number := header.Number.Uint64()
@@ -339,15 +384,22 @@ func (cs *MultiClient) blockHeaders(ctx context.Context, pkt eth.BlockHeadersPac
 	//sort.Ints(blockNums)
 	//cs.logger.Debug("Delivered headers", "peer",  fmt.Sprintf("%x", ConvertH512ToPeerID(peerID))[:8], "blockNums", fmt.Sprintf("%d", blockNums))
 	if cs.Hd.POSSync() {
-		sort.Sort(headerdownload.HeadersReverseSort(csHeaders)) // Sorting by reverse order of block heights
+		headerdownload.SortHeadersReverse(csHeaders) // Sorting by reverse order of block heights
 		tx, err := cs.db.BeginTemporalRo(ctx)
 		if err != nil {
-			return err
+			return transientLocal("begin tx: %w", err)
 		}
 		defer tx.Rollback()
 		penalties, err := cs.Hd.ProcessHeadersPOS(csHeaders, tx, sentry.ConvertH512ToPeerID(peerID))
 		if err != nil {
-			return err
+			return internalFailure("process headers: %w", err)
+		}
+		for _, penalty := range penalties {
+			if penalty.PeerID == sentry.ConvertH512ToPeerID(peerID) {
+				cs.requestTraces.penalized(requestID, penalty.PeerID)
+				cs.logger.Debug("[p2p] Penalizing peer for header response", "reqId", requestID,
+					"peer", hex.EncodeToString(penalty.PeerID[:]), "penalty", penalty.Penalty)
+			}
 		}
 		if len(penalties) > 0 {
 			cs.Penalize(ctx, penalties)
@@ -370,56 +422,53 @@ func (cs *MultiClient) blockHeaders(ctx context.Context, pkt eth.BlockHeadersPac
 			}
 		}
 	}
+	cs.reputation.Record(sentry.ConvertH512ToPeerID(peerID), reputationUseful)
 	outreq := proto_sentry.PeerMinBlockRequest{
 		PeerId:   peerID,
 		MinBlock: highestBlock,
 	}
-	if _, err1 := sentryClient.PeerMinBlock(ctx, &outreq, &grpc.EmptyCallOption{}); err1 != nil {
+	if err1 := cs.send(ctx, sentryClient, sendPriorityHigh, "min block", peerMinBlock(&outreq)); err1 != nil {
 		cs.logger.Error("Could not send min block for peer", "err", err1)
 	}
 	return nil
 }
 
 func (cs *MultiClient) newBlock66(ctx context.Context, inreq *proto_sentry.InboundMessage, sentryClient proto_sentry.SentryClient) error {
-	if cs.disableBlockDownload {
-		return nil
-	}
-
 	// Extract header from the block
 	rlpStream := rlp.NewStream(bytes.NewReader(inreq.Data), uint64(len(inreq.Data)))
 	_, err := rlpStream.List() // Now stream is at the beginning of the block record
 	if err != nil {
-		return fmt.Errorf("decode 1 NewBlockMsg: %w", err)
+		return peerMisbehavior("decode 1 NewBlockMsg: %w", err)
 	}
 	_, err = rlpStream.List() // Now stream is at the beginning of the header
 	if err != nil {
-		return fmt.Errorf("decode 2 NewBlockMsg: %w", err)
+		return peerMisbehavior("decode 2 NewBlockMsg: %w", err)
 	}
 	var headerRaw []byte
 	if headerRaw, err = rlpStream.Raw(); err != nil {
-		return fmt.Errorf("decode 3 NewBlockMsg: %w", err)
+		return peerMisbehavior("decode 3 NewBlockMsg: %w", err)
 	}
 	// Parse the entire request from scratch
 	request := &eth.NewBlockPacket{}
 	if err := rlp.DecodeBytes(inreq.Data, &request); err != nil {
-		return fmt.Errorf("decode 4 NewBlockMsg: %w", err)
+		return peerMisbehavior("decode 4 NewBlockMsg: %w", err)
 	}
 	if err := request.SanityCheck(); err != nil {
-		return fmt.Errorf("newBlock66: %w", err)
+		return peerMisbehavior("newBlock66: %w", err)
 	}
 	if err := request.Block.HashCheck(true); err != nil {
-		return fmt.Errorf("newBlock66: %w", err)
+		return peerMisbehavior("newBlock66: %w", err)
 	}
+	cs.setPeerHead(sentry.ConvertH512ToPeerID(inreq.PeerId), request.Block.Hash(), request.TD)
+	cs.blockPropagation.came(request.Block.NumberU64(), request.Block.Hash(), false)
 
-	if segments, penalty, err := cs.Hd.SingleHeaderAsSegment(headerRaw, request.Block.Header(), true /* penalizePoSBlocks */); err == nil {
+	if segments, penalty, err := cs.Hd.SingleHeaderAsSegment(headerRaw, request.Block.Header()); err == nil {
 		if penalty == headerdownload.NoPenalty {
-			propagate := !cs.ChainConfig.TerminalTotalDifficultyPassed
-			// Do not propagate blocks who are post TTD
-			firstPosSeen := cs.Hd.FirstPoSHeight()
-			if firstPosSeen != nil && propagate {
-				propagate = *firstPosSeen >= segments[0].Number
-			}
-			if !cs.IsMock && propagate {
+			// the blocks of the eras gossiped by the consensus layer have no business in NewBlock
+			penalty = blockGossipOf(cs.ChainConfig, request.Block.Header()).penalty
+		}
+		if penalty == headerdownload.NoPenalty {
+			if _, propagateHashes := cs.propagation(request.Block.Header()); !cs.IsMock && propagateHashes {
 				cs.PropagateNewBlockHashes(ctx, []headerdownload.Announce{
 					{
 						Number: segments[0].Number,
@@ -430,6 +479,10 @@ func (cs *MultiClient) newBlock66(ctx context.Context, inreq *proto_sentry.Inbou
 
 			cs.Hd.ProcessHeaders(segments, true /* newBlock */, sentry.ConvertH512ToPeerID(inreq.PeerId)) // There is only one segment in this case
 		} else {
+			peerID := sentry.ConvertH512ToPeerID(inreq.PeerId)
+			cs.logger.Debug("[p2p] Penalizing peer for NewBlock", "peer", hex.EncodeToString(peerID[:]),
+				"block", request.Block.NumberU64(), "penalty", penalty)
+			cs.reputation.Record(peerID, reputationPenalty)
 			outreq := proto_sentry.PenalizePeerRequest{
 				PeerId:  inreq.PeerId,
 				Penalty: proto_sentry.PenaltyKind_Kick, // TODO: Extend penalty kinds
@@ -439,20 +492,20 @@ func (cs *MultiClient) newBlock66(ctx context.Context, inreq *proto_sentry.Inbou
 				if directSentry, ok := sentry.(direct.SentryClient); ok && !directSentry.Ready() {
 					continue
 				}
-				if _, err1 := sentry.PenalizePeer(ctx, &outreq, &grpc.EmptyCallOption{}); err1 != nil {
+				if err1 := cs.send(ctx, sentry, sendPriorityHigh, "penalty", penalizePeer(&outreq)); err1 != nil {
 					cs.logger.Error("Could not send penalty", "err", err1)
 				}
 			}
 		}
 	} else {
-		return fmt.Errorf("singleHeaderAsSegment failed: %w", err)
+		return internalFailure("singleHeaderAsSegment failed: %w", err)
 	}
 	cs.Bd.AddToPrefetch(request.Block.Header(), request.Block.RawBody())
 	outreq := proto_sentry.PeerMinBlockRequest{
 		PeerId:   inreq.PeerId,
 		MinBlock: request.Block.NumberU64(),
 	}
-	if _, err1 := sentryClient.PeerMinBlock(ctx, &outreq, &grpc.EmptyCallOption{}); err1 != nil {
+	if err1 := cs.send(ctx, sentryClient, sendPriorityHigh, "min block", peerMinBlock(&outreq)); err1 != nil {
 		cs.logger.Error("Could not send min block for peer", "err", err1)
 	}
 	cs.logger.Trace(fmt.Sprintf("NewBlockMsg{blockNumber: %d} from [%s]", request.Block.NumberU64(), sentry.ConvertH512ToPeerID(inreq.PeerId)))
@@ -460,20 +513,22 @@ func (cs *MultiClient) newBlock66(ctx context.Context, inreq *proto_sentry.Inbou
 }
 
 func (cs *MultiClient) blockBodies66(ctx context.Context, inreq *proto_sentry.InboundMessage, sentryClient proto_sentry.SentryClient) error {
-	if cs.disableBlockDownload {
-		return nil
-	}
-
 	var request eth.BlockRawBodiesPacket66
 	if err := rlp.DecodeBytes(inreq.Data, &request); err != nil {
-		return fmt.Errorf("decode BlockBodiesPacket66: %w", err)
+		return peerMisbehavior("decode BlockBodiesPacket66: %w", err)
 	}
 	txs, uncles, withdrawals := request.BlockRawBodiesPacket.Unpack()
-	if len(txs) == 0 && len(uncles) == 0 && len(withdrawals) == 0 {
+	empty := len(txs) == 0 && len(uncles) == 0 && len(withdrawals) == 0
+	peerID := sentry.ConvertH512ToPeerID(inreq.PeerId)
+	cs.requestTraces.answered(request.RequestId, peerID, empty)
+	cs.logger.Debug("body response received", "reqId", request.RequestId, "bodies", len(txs), "peer", hex.EncodeToString(peerID[:]))
+	if empty {
 		// No point processing empty response
+		cs.reputation.Record(peerID, reputationUseless)
 		return nil
 	}
-	cs.Bd.DeliverBodies(txs, uncles, withdrawals, uint64(len(inreq.Data)), sentry.ConvertH512ToPeerID(inreq.PeerId))
+	cs.reputation.Record(peerID, reputationUseful)
+	cs.Bd.DeliverBodies(txs, uncles, withdrawals, uint64(len(inreq.Data)), peerID)
 	return nil
 }
 
@@ -484,7 +539,7 @@ func (cs *MultiClient) receipts66(_ context.Context, _ *proto_sentry.InboundMess
 func (cs *MultiClient) getBlockHeaders66(ctx context.Context, inreq *proto_sentry.InboundMessage, sentry proto_sentry.SentryClient) error {
 	var query eth.GetBlockHeadersPacket66
 	if err := rlp.DecodeBytes(inreq.Data, &query); err != nil {
-		return fmt.Errorf("decoding getBlockHeaders66: %w, data: %x", err, inreq.Data)
+		return peerMisbehavior("decoding getBlockHeaders66: %w, data: %x", err, inreq.Data)
 	}
 
 	var headers []*types.Header
@@ -495,7 +550,7 @@ func (cs *MultiClient) getBlockHeaders66(ctx context.Context, inreq *proto_sentr
 		}
 		return nil
 	}); err != nil {
-		return fmt.Errorf("querying BlockHeaders: %w", err)
+		return transientLocal("querying BlockHeaders: %w", err)
 	}
 
 	// Even if we get empty headers list from db, we'll respond with that. Nodes
@@ -509,19 +564,23 @@ func (cs *MultiClient) getBlockHeaders66(ctx context.Context, inreq *proto_sentr
 		BlockHeadersPacket: headers,
 	})
 	if err != nil {
-		return fmt.Errorf("encode header response: %w", err)
+		return internalFailure("encode header response: %w", err)
+	}
+	msgID, ok := cs.messageIDFor(inreq.PeerId, eth.BlockHeadersMsg)
+	if !ok {
+		return nil
 	}
 	outreq := proto_sentry.SendMessageByIdRequest{
 		PeerId: inreq.PeerId,
 		Data: &proto_sentry.OutboundMessageData{
-			Id:   proto_sentry.MessageId_BLOCK_HEADERS_66,
+			Id:   msgID,
 			Data: b,
 		},
 	}
-	_, err = sentry.SendMessageById(ctx, &outreq, &grpc.EmptyCallOption{})
+	err = cs.send(ctx, sentry, sendPriorityHigh, "header response", sendMessageById(&outreq))
 	if err != nil {
 		if !isPeerNotFoundErr(err) {
-			return fmt.Errorf("send header response 66: %w", err)
+			return transientLocal("send header response 66: %w", err)
 		}
 		return fmt.Errorf("send header response 66: %w", err)
 	}
@@ -532,11 +591,11 @@ func (cs *MultiClient) getBlockHeaders66(ctx context.Context, inreq *proto_sentr
 func (cs *MultiClient) getBlockBodies66(ctx context.Context, inreq *proto_sentry.InboundMessage, sentry proto_sentry.SentryClient) error {
 	var query eth.GetBlockBodiesPacket66
 	if err := rlp.DecodeBytes(inreq.Data, &query); err != nil {
-		return fmt.Errorf("decoding getBlockBodies66: %w, data: %x", err, inreq.Data)
+		return peerMisbehavior("decoding getBlockBodies66: %w, data: %x", err, inreq.Data)
 	}
 	tx, err := cs.db.BeginRo(ctx)
 	if err != nil {
-		return err
+		return transientLocal("begin tx: %w", err)
 	}
 	defer tx.Rollback()
 	response := eth.AnswerGetBlockBodiesQuery(tx, query.GetBlockBodiesPacket, cs.blockReader)
@@ -546,21 +605,25 @@ func (cs *MultiClient) getBlockBodies66(ctx context.Context, inreq *proto_sentry
 		BlockBodiesRLPPacket: response,
 	})
 	if err != nil {
-		return fmt.Errorf("encode header response: %w", err)
+		return internalFailure("encode header response: %w", err)
+	}
+	msgID, ok := cs.messageIDFor(inreq.PeerId, eth.BlockBodiesMsg)
+	if !ok {
+		return nil
 	}
 	outreq := proto_sentry.SendMessageByIdRequest{
 		PeerId: inreq.PeerId,
 		Data: &proto_sentry.OutboundMessageData{
-			Id:   proto_sentry.MessageId_BLOCK_BODIES_66,
+			Id:   msgID,
 			Data: b,
 		},
 	}
-	_, err = sentry.SendMessageById(ctx, &outreq, &grpc.EmptyCallOption{})
+	err = cs.send(ctx, sentry, sendPriorityLow, "bodies response", sendMessageById(&outreq))
 	if err != nil {
 		if isPeerNotFoundErr(err) {
 			return nil
 		}
-		return fmt.Errorf("send bodies response: %w", err)
+		return transientLocal("send bodies response: %w", err)
 	}
 	//cs.logger.Info(fmt.Sprintf("[%s] GetBlockBodiesMsg responseLen %d", ConvertH512ToPeerID(inreq.PeerId), len(b)))
 	return nil
@@ -569,11 +632,11 @@ func (cs *MultiClient) getBlockBodies66(ctx context.Context, inreq *proto_sentry
 func (cs *MultiClient) getReceipts66(ctx context.Context, inreq *proto_sentry.InboundMessage, sentryClient proto_sentry.SentryClient) error {
 	var query eth.GetReceiptsPacket66
 	if err := rlp.DecodeBytes(inreq.Data, &query); err != nil {
-		return fmt.Errorf("decoding getReceipts66: %w, data: %x", err, inreq.Data)
+		return peerMisbehavior("decoding getReceipts66: %w, data: %x", err, inreq.Data)
 	}
 	cachedReceipts, needMore, err := eth.AnswerGetReceiptsQueryCacheOnly(ctx, cs.ethApiWrapper, query.GetReceiptsPacket)
 	if err != nil {
-		return err
+		return transientLocal("receipts from the cache: %w", err)
 	}
 	receiptsList := []rlp.RawValue{}
 	if cachedReceipts != nil {
@@ -588,12 +651,12 @@ func (cs *MultiClient) getReceipts66(ctx context.Context, inreq *proto_sentry.In
 
 		tx, err := cs.db.BeginTemporalRo(ctx)
 		if err != nil {
-			return err
+			return transientLocal("begin tx: %w", err)
 		}
 		defer tx.Rollback()
 		receiptsList, err = eth.AnswerGetReceiptsQuery(ctx, cs.ChainConfig, cs.ethApiWrapper, cs.blockReader, tx, query.GetReceiptsPacket, cachedReceipts)
 		if err != nil {
-			return err
+			return internalFailure("receipts: %w", err)
 		}
 
 	}
@@ -602,26 +665,50 @@ func (cs *MultiClient) getReceipts66(ctx context.Context, inreq *proto_sentry.In
 		ReceiptsRLPPacket: receiptsList,
 	})
 	if err != nil {
-		return fmt.Errorf("encode header response: %w", err)
+		return internalFailure("encode header response: %w", err)
+	}
+	msgID, ok := cs.messageIDFor(inreq.PeerId, eth.ReceiptsMsg)
+	if !ok {
+		return nil
 	}
 	outreq := proto_sentry.SendMessageByIdRequest{
 		PeerId: inreq.PeerId,
 		Data: &proto_sentry.OutboundMessageData{
-			Id:   proto_sentry.MessageId_RECEIPTS_66,
+			Id:   msgID,
 			Data: b,
 		},
 	}
-	_, err = sentryClient.SendMessageById(ctx, &outreq, &grpc.OnFinishCallOption{})
+	err = cs.send(ctx, sentryClient, sendPriorityLow, "receipts response", func(ctx context.Context, sentryClient proto_sentry.SentryClient) error {
+		_, err := sentryClient.SendMessageById(ctx, &outreq, &grpc.OnFinishCallOption{})
+		return err
+	})
 	if err != nil {
 		if isPeerNotFoundErr(err) {
 			return nil
 		}
-		return fmt.Errorf("send receipts response: %w", err)
+		return transientLocal("send receipts response: %w", err)
 	}
 	//println(fmt.Sprintf("[%s] GetReceipts responseLen %d", sentry.ConvertH512ToPeerID(inreq.PeerId), len(b)))
 	return nil
 }
 
+// headersBacklogStream counts the header batches it receives in the backlog of the header downloader, until they are
+// processed by HandleInboundMessage.
+type headersBacklogStream struct {
+	grpc.ClientStream
+	hd *headerdownload.HeaderDownload
+}
+
+func (s *headersBacklogStream) RecvMsg(m any) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(*proto_sentry.InboundMessage); ok && msg.Id == proto_sentry.MessageId_BLOCK_HEADERS_66 {
+		s.hd.AddToBacklog(len(msg.Data))
+	}
+	return nil
+}
+
 func MakeInboundMessage() *proto_sentry.InboundMessage {
 	return new(proto_sentry.InboundMessage)
 }
@@ -629,56 +716,56 @@ func MakeInboundMessage() *proto_sentry.InboundMessage {
 func (cs *MultiClient) HandleInboundMessage(ctx context.Context, message *proto_sentry.InboundMessage, sentry proto_sentry.SentryClient) (err error) {
 	defer func() {
 		if rec := recover(); rec != nil {
-			err = fmt.Errorf("%+v, msgID=%s, trace: %s", rec, message.Id.String(), dbg.Stack())
+			if repanicInboundMessages {
+				panic(rec)
+			}
+			stack := dbg.Stack()
+			cs.panics.record(message.Id, rec, stack, cs.logger)
+			err = fmt.Errorf("%+v, msgID=%s, trace: %s", rec, message.Id.String(), stack)
 		}
 	}() // avoid crash because Erigon's core does many things
+	cs.recorder.Record(message, time.Now())
 	err = cs.handleInboundMessage(ctx, message, sentry)
 
-	if (err != nil) && rlp.IsInvalidRLPError(err) {
-		cs.logger.Debug("Kick peer for invalid RLP", "err", err)
-		penalizeRequest := proto_sentry.PenalizePeerRequest{
-			PeerId:  message.PeerId,
-			Penalty: proto_sentry.PenaltyKind_Kick, // TODO: Extend penalty kinds
-		}
-		if _, err1 := sentry.PenalizePeer(ctx, &penalizeRequest, &grpc.EmptyCallOption{}); err1 != nil {
-			cs.logger.Error("Could not send penalty", "err", err1)
-		}
+	if err != nil {
+		cs.onHandlerError(ctx, message, sentry, err)
 	}
 
 	return err
 }
 
 func (cs *MultiClient) handleInboundMessage(ctx context.Context, inreq *proto_sentry.InboundMessage, sentry proto_sentry.SentryClient) error {
-	switch inreq.Id {
-	// ========= eth 66 ==========
-
-	case proto_sentry.MessageId_NEW_BLOCK_HASHES_66:
-		return cs.newBlockHashes66(ctx, inreq, sentry)
-	case proto_sentry.MessageId_BLOCK_HEADERS_66:
-		return cs.blockHeaders66(ctx, inreq, sentry)
-	case proto_sentry.MessageId_NEW_BLOCK_66:
-		return cs.newBlock66(ctx, inreq, sentry)
-	case proto_sentry.MessageId_BLOCK_BODIES_66:
-		return cs.blockBodies66(ctx, inreq, sentry)
-	case proto_sentry.MessageId_GET_BLOCK_HEADERS_66:
-		return cs.getBlockHeaders66(ctx, inreq, sentry)
-	case proto_sentry.MessageId_GET_BLOCK_BODIES_66:
-		return cs.getBlockBodies66(ctx, inreq, sentry)
-	case proto_sentry.MessageId_RECEIPTS_66:
-		return cs.receipts66(ctx, inreq, sentry)
-	case proto_sentry.MessageId_GET_RECEIPTS_66:
-		return cs.getReceipts66(ctx, inreq, sentry)
-	default:
+	if inreq.Id == proto_sentry.MessageId_BLOCK_HEADERS_66 {
+		// added by headersBacklogStream, whichever the handler
+		defer cs.Hd.RemoveFromBacklog(len(inreq.Data))
+	}
+	if err := cs.checkInboundVersion(inreq); err != nil {
+		return err
+	}
+	handle, ok := cs.handler(inreq.Id)
+	if !ok {
 		return fmt.Errorf("not implemented for message Id: %s", inreq.Id)
 	}
+	return handleWithRetries(ctx, handle, inreq, sentry)
 }
 
 func (cs *MultiClient) HandlePeerEvent(ctx context.Context, event *proto_sentry.PeerEvent, sentryClient proto_sentry.SentryClient) error {
 	eventID := event.EventId.String()
 	peerID := sentry.ConvertH512ToPeerID(event.PeerId)
 	peerIDStr := hex.EncodeToString(peerID[:])
+	if event.EventId == proto_sentry.PeerEvent_Disconnect {
+		cs.removePeerHead(peerID)
+		cs.removePeerFork(peerID)
+		cs.removePeerVersion(peerID)
+	}
+	if event.EventId == proto_sentry.PeerEvent_Connect {
+		cs.checkPeerReputation(ctx, peerID, sentryClient)
+	}
 
-	if !cs.logPeerInfo {
+	relay := cs.events != nil && cs.events.HasPeerEventSubscriptions()
+	checkForkID := event.EventId == proto_sentry.PeerEvent_Connect && cs.statusDataProvider != nil
+	// the connected peers are always looked up, for the version of the eth protocol they negotiated
+	if !cs.logPeerInfo && !relay && event.EventId != proto_sentry.PeerEvent_Connect {
 		cs.logger.Trace("[p2p] Sentry peer did", "eventID", eventID, "peer", peerIDStr)
 		return nil
 	}
@@ -695,11 +782,28 @@ func (cs *MultiClient) HandlePeerEvent(ctx context.Context, event *proto_sentry.
 			nodeURL = reply.Peer.Enode
 			clientID = reply.Peer.Name
 			capabilities = reply.Peer.Caps
+			cs.setPeerVersion(peerID, capabilities)
+			if _, ok := peerForkID(reply.Peer); ok && checkForkID {
+				if status, err := cs.makeStatusData(ctx); err != nil {
+					cs.logger.Debug("[p2p] Fork ID check of peer failed", "peer", peerIDStr, "err", err)
+				} else {
+					cs.checkPeerForkID(peerID, reply.Peer, status)
+				}
+			}
 		}
 	}
 
 	cs.logger.Trace("[p2p] Sentry peer did", "eventID", eventID, "peer", peerIDStr,
 		"nodeURL", nodeURL, "clientID", clientID, "capabilities", capabilities)
+	if relay {
+		cs.events.OnPeerEvent(&shards.PeerEvent{
+			EventId: uint64(event.EventId),
+			PeerId:  peerID,
+			Enode:   nodeURL,
+			Name:    clientID,
+			Caps:    capabilities,
+		})
+	}
 	return nil
 }
 
@@ -0,0 +1,49 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry_multi_client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/execution/stages/headerdownload"
+	"github.com/erigontech/erigon/turbo/shards"
+)
+
+func TestSyncModeEvents(t *testing.T) {
+	cs := &MultiClient{
+		Hd:     headerdownload.NewHeaderDownload(16, 16, nil, nil, log.New()),
+		logger: log.New(),
+	}
+	cs.Hd.OnSyncModeChange(cs.onSyncModeChange)
+	cs.Hd.SetFetchingNew(true) // not relayed yet
+
+	events := shards.NewEvents()
+	cs.RelayPeerEvents(events)
+	modes, unsubscribe := events.AddSyncModeSubscription()
+	defer unsubscribe()
+
+	cs.Hd.SetFetchingNew(false)
+	cs.Hd.AfterInitialCycle()
+	cs.Hd.SetFetchingNew(true)
+	require.Equal(t, headerdownload.SyncModeTipFollowing, cs.SyncMode())
+	require.Len(t, modes, 2)
+	require.Equal(t, string(headerdownload.SyncModeInitialCycle), <-modes)
+	require.Equal(t, string(headerdownload.SyncModeTipFollowing), <-modes)
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrEventsGap = errors.New("gap between the frozen and the db events")
+
+// ConsistencyResult is the outcome of SnapshotStore.ConsistencyCheck: ConsistencyOK, GapBetweenFrozenAndDb or
+// OverlapDetected.
+type ConsistencyResult interface {
+	fmt.Stringer
+	consistencyResult()
+}
+
+// ConsistencyOK is the db events following the frozen ones, or either of them being empty.
+type ConsistencyOK struct{}
+
+// GapBetweenFrozenAndDb is the events [From, To] missing from both the frozen files and the db, e.g. after an
+// incomplete migration. Fetching from the last db event would never fetch them.
+type GapBetweenFrozenAndDb struct {
+	From, To uint64
+}
+
+// OverlapDetected is the events [From, To] both in the frozen files and in the db. The db ones are pruned eventually,
+// the frozen ones take precedence meanwhile.
+type OverlapDetected struct {
+	From, To uint64
+}
+
+func (ConsistencyOK) consistencyResult()         {}
+func (GapBetweenFrozenAndDb) consistencyResult() {}
+func (OverlapDetected) consistencyResult()       {}
+
+func (ConsistencyOK) String() string { return "ok" }
+
+func (r GapBetweenFrozenAndDb) String() string {
+	return fmt.Sprintf("events %d-%d missing between the frozen and the db ones", r.From, r.To)
+}
+
+func (r OverlapDetected) String() string {
+	return fmt.Sprintf("events %d-%d both frozen and in the db", r.From, r.To)
+}
+
+// eventsConsistency compares the range of the db events with the one of the frozen events, 0s if empty.
+func eventsConsistency(dbFirst, dbLast, frozenFirst, frozenLast uint64) ConsistencyResult {
+	switch {
+	case dbLast == 0 || frozenLast == 0:
+		return ConsistencyOK{}
+	case dbFirst > frozenLast+1:
+		return GapBetweenFrozenAndDb{From: frozenLast + 1, To: dbFirst - 1}
+	case dbFirst <= frozenLast && dbLast >= frozenFirst:
+		return OverlapDetected{From: max(dbFirst, frozenFirst), To: min(dbLast, frozenLast)}
+	default:
+		return ConsistencyOK{}
+	}
+}
+
+// ConsistencyCheck compares the first and last event ids of the db with the frozen ones, see ConsistencyResult.
+func (s *SnapshotStore) ConsistencyCheck(ctx context.Context) (ConsistencyResult, error) {
+	firstEventIdStore, ok := s.Store.(interface {
+		FirstEventId(ctx context.Context) (uint64, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("can't check the events consistency of %T", s.Store)
+	}
+	dbFirst, err := firstEventIdStore.FirstEventId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dbLast, err := s.Store.LastEventId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return eventsConsistency(dbFirst, dbLast, s.FirstFrozenEventId(), s.LastFrozenEventId()), nil
+}
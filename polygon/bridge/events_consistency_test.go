@@ -0,0 +1,122 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/testlog"
+	"github.com/erigontech/erigon/polygon/heimdall"
+)
+
+// storeWithEvents is a db store holding the events [first, last], none if 0.
+func storeWithEvents(t *testing.T, first, last uint64) *MdbxStore {
+	ctx := context.Background()
+	store := NewMdbxStore(t.TempDir(), testlog.Logger(t, log.LvlDebug), false, 1)
+	require.NoError(t, store.Prepare(ctx))
+	t.Cleanup(store.Close)
+	if last == 0 {
+		return store
+	}
+	var events []*heimdall.EventRecordWithTime
+	for id := first; id <= last; id++ {
+		events = append(events, &heimdall.EventRecordWithTime{
+			EventRecord: heimdall.EventRecord{ID: id, ChainID: "80002"},
+			Time:        time.Unix(int64(id), 0),
+		})
+	}
+	require.NoError(t, store.PutEvents(ctx, events))
+	return store
+}
+
+func TestEventsConsistency(t *testing.T) {
+	for _, tt := range []struct {
+		name                    string
+		dbFirst, dbLast         uint64
+		frozenFirst, frozenLast uint64
+		want                    ConsistencyResult
+	}{
+		{name: "empty db", frozenFirst: 1, frozenLast: 100, want: ConsistencyOK{}},
+		{name: "nothing frozen", dbFirst: 1, dbLast: 10, want: ConsistencyOK{}},
+		{name: "db following frozen", dbFirst: 101, dbLast: 120, frozenFirst: 1, frozenLast: 100, want: ConsistencyOK{}},
+		{name: "gap", dbFirst: 151, dbLast: 160, frozenFirst: 1, frozenLast: 100, want: GapBetweenFrozenAndDb{From: 101, To: 150}},
+		{name: "overlap", dbFirst: 91, dbLast: 120, frozenFirst: 1, frozenLast: 100, want: OverlapDetected{From: 91, To: 100}},
+		{name: "db behind frozen", dbFirst: 1, dbLast: 40, frozenFirst: 1, frozenLast: 100, want: OverlapDetected{From: 1, To: 40}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := storeWithEvents(t, tt.dbFirst, tt.dbLast)
+			dbFirst, err := store.FirstEventId(ctx)
+			require.NoError(t, err)
+			dbLast, err := store.LastEventId(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.dbFirst, dbFirst)
+			require.Equal(t, tt.dbLast, dbLast)
+			require.Equal(t, tt.want, eventsConsistency(dbFirst, dbLast, tt.frozenFirst, tt.frozenLast))
+		})
+	}
+}
+
+// checkedStore is a store whose consistency check finds result.
+type checkedStore struct {
+	Store
+	result ConsistencyResult
+}
+
+func (s checkedStore) ConsistencyCheck(context.Context) (ConsistencyResult, error) {
+	return s.result, nil
+}
+
+func TestServiceEventsGap(t *testing.T) {
+	gap := GapBetweenFrozenAndDb{From: 101, To: 150}
+
+	t.Run("refused", func(t *testing.T) {
+		b := NewService(ServiceConfig{
+			Store:        checkedStore{storeWithEvents(t, 151, 160), gap},
+			Logger:       testlog.Logger(t, log.LvlDebug),
+			BorConfig:    &defaultBorConfig,
+			EventFetcher: heimdall.NewMockClient(gomock.NewController(t)),
+		})
+		require.ErrorIs(t, b.Run(context.Background()), ErrEventsGap)
+	})
+
+	t.Run("healed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		heimdallClient := heimdall.NewMockClient(gomock.NewController(t))
+		b := NewService(ServiceConfig{
+			Store:         checkedStore{storeWithEvents(t, 151, 160), gap},
+			Logger:        testlog.Logger(t, log.LvlDebug),
+			BorConfig:     &defaultBorConfig,
+			EventFetcher:  heimdallClient,
+			HealEventsGap: true,
+		})
+		// the fetching continues after the last frozen event, rather than the last db one
+		heimdallClient.EXPECT().FetchStateSyncEvents(gomock.Any(), gap.From, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, uint64, time.Time, int) ([]*heimdall.EventRecordWithTime, error) {
+				cancel()
+				return nil, nil
+			}).Times(1)
+		require.ErrorIs(t, b.Run(ctx), context.Canceled)
+	})
+}
@@ -0,0 +1,303 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/order"
+	"github.com/erigontech/erigon/polygon/heimdall"
+)
+
+// The events are exported as a header: magic | version | chain id length (uvarint) | chain id | first id | last id |
+// sha256 of the records, then a record of each event, in id order: length (uvarint) | encoded event.
+var eventsExportMagic = [8]byte{'b', 'o', 'r', 'e', 'v', 'n', 't', 's'}
+
+const (
+	eventsExportVersion     = 1
+	eventsImportBatchSize   = 1_000
+	maxExportedEventSize    = 1 << 20
+	maxExportedChainIdBytes = 64
+)
+
+var ErrInvalidEventsExport = errors.New("invalid bridge events export")
+
+// EventsExportHeader describes the events [FromId, ToId] of an export, all of the chain ChainId.
+type EventsExportHeader struct {
+	ChainId  string
+	FromId   uint64
+	ToId     uint64
+	Checksum [sha256.Size]byte
+}
+
+func (h EventsExportHeader) write(w io.Writer) error {
+	buf := append(eventsExportMagic[:], eventsExportVersion)
+	buf = binary.AppendUvarint(buf, uint64(len(h.ChainId)))
+	buf = append(buf, h.ChainId...)
+	buf = binary.BigEndian.AppendUint64(buf, h.FromId)
+	buf = binary.BigEndian.AppendUint64(buf, h.ToId)
+	buf = append(buf, h.Checksum[:]...)
+	_, err := w.Write(buf)
+	return err
+}
+
+func readEventsExportHeader(r *bufio.Reader) (EventsExportHeader, error) {
+	var h EventsExportHeader
+	var magic [len(eventsExportMagic) + 1]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return h, fmt.Errorf("%w: header: %w", ErrInvalidEventsExport, err)
+	}
+	if !bytes.Equal(magic[:len(eventsExportMagic)], eventsExportMagic[:]) {
+		return h, fmt.Errorf("%w: not an events export", ErrInvalidEventsExport)
+	}
+	if version := magic[len(eventsExportMagic)]; version != eventsExportVersion {
+		return h, fmt.Errorf("%w: version %d, want %d", ErrInvalidEventsExport, version, eventsExportVersion)
+	}
+	chainIdLen, err := binary.ReadUvarint(r)
+	if err != nil || chainIdLen > maxExportedChainIdBytes {
+		return h, fmt.Errorf("%w: chain id of %d bytes: %v", ErrInvalidEventsExport, chainIdLen, err)
+	}
+	rest := make([]byte, chainIdLen+8+8+sha256.Size)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return h, fmt.Errorf("%w: header: %w", ErrInvalidEventsExport, err)
+	}
+	h.ChainId = string(rest[:chainIdLen])
+	rest = rest[chainIdLen:]
+	h.FromId, h.ToId = binary.BigEndian.Uint64(rest), binary.BigEndian.Uint64(rest[8:])
+	copy(h.Checksum[:], rest[16:])
+	if h.FromId == 0 || h.ToId < h.FromId {
+		return h, fmt.Errorf("%w: events %d-%d", ErrInvalidEventsExport, h.FromId, h.ToId)
+	}
+	return h, nil
+}
+
+// eventsExportRecords walks the records of the events [fromId, toId] of the db, with their encoded events.
+func eventsExportRecords(ctx context.Context, tx kv.Tx, fromId, toId uint64, f func(record, event []byte) error) error {
+	var from, to [8]byte
+	binary.BigEndian.PutUint64(from[:], fromId)
+	binary.BigEndian.PutUint64(to[:], toId+1)
+	it, err := tx.Range(kv.BorEvents, from[:], to[:], order.Asc, kv.Unlim)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	next := fromId
+	var record []byte
+	for it.HasNext() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		k, v, err := it.Next()
+		if err != nil {
+			return err
+		}
+		if eventId := binary.BigEndian.Uint64(k); eventId != next {
+			return fmt.Errorf("event %d missing from the db, before event %d", next, eventId)
+		}
+		next++
+		record = binary.AppendUvarint(record[:0], uint64(len(v)))
+		n := len(record)
+		record = append(record, v...)
+		if err := f(record, record[n:]); err != nil {
+			return err
+		}
+	}
+	if next != toId+1 {
+		return fmt.Errorf("event %d missing from the db", next)
+	}
+	return nil
+}
+
+// ExportEvents writes the events [fromId, toId] of the db to w, toId being clamped to the last one, to be imported by
+// ImportEvents on another node. The frozen events, copied with the files, aren't exported: an export of many events
+// can be split in ranges, each imported in turn, the import of a range starting again being harmless.
+func (s *SnapshotStore) ExportEvents(ctx context.Context, w io.Writer, fromId, toId uint64) error {
+	return s.Store.(interface {
+		exportEvents(ctx context.Context, w io.Writer, fromId, toId uint64) error
+	}).exportEvents(ctx, w, fromId, toId)
+}
+
+func (s *MdbxStore) exportEvents(ctx context.Context, w io.Writer, fromId, toId uint64) error {
+	tx, err := s.db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	return txStore{tx}.exportEvents(ctx, w, fromId, toId)
+}
+
+func (s txStore) exportEvents(ctx context.Context, w io.Writer, fromId, toId uint64) error {
+	lastEventId, err := s.LastEventId(ctx)
+	if err != nil {
+		return err
+	}
+	toId = min(toId, lastEventId)
+	if fromId == 0 || toId < fromId {
+		return fmt.Errorf("no events %d-%d in the db, the last being %d", fromId, toId, lastEventId)
+	}
+
+	// the checksum goes before the records, which are walked twice in the same tx
+	header := EventsExportHeader{FromId: fromId, ToId: toId}
+	checksum := sha256.New()
+	if err := eventsExportRecords(ctx, s.tx, fromId, toId, func(record, encodedEvent []byte) error {
+		if header.ChainId == "" {
+			var event heimdall.EventRecordWithTime
+			if err := event.UnmarshallBytes(encodedEvent); err != nil {
+				return fmt.Errorf("event %d: %w", fromId, err)
+			}
+			header.ChainId = event.ChainID
+		}
+		checksum.Write(record)
+		return nil
+	}); err != nil {
+		return err
+	}
+	checksum.Sum(header.Checksum[:0])
+
+	bw := bufio.NewWriter(w)
+	if err := header.write(bw); err != nil {
+		return err
+	}
+	if err := eventsExportRecords(ctx, s.tx, fromId, toId, func(record, _ []byte) error {
+		_, err := bw.Write(record)
+		return err
+	}); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ImportEvents writes the events of an export of ExportEvents, checking they're of the chain of the db events, if
+// any, and extend them, see EventsWritePolicy. They're written in one tx, committed once the checksum is checked, so
+// that an interrupted or corrupted import writes nothing. The events already stored, frozen or not, are skipped, so
+// that importing again, or a range overlapping the stored events, is harmless.
+func (s *SnapshotStore) ImportEvents(ctx context.Context, r io.Reader) error {
+	return s.Store.(interface {
+		importEvents(ctx context.Context, r io.Reader, lastFrozenEventId uint64) error
+	}).importEvents(ctx, r, s.LastFrozenEventId())
+}
+
+func (s *MdbxStore) importEvents(ctx context.Context, r io.Reader, lastFrozenEventId uint64) error {
+	tx, err := s.db.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := (txStore{tx}).importEvents(ctx, r, lastFrozenEventId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s txStore) importEvents(ctx context.Context, r io.Reader, lastFrozenEventId uint64) error {
+	br := bufio.NewReader(r)
+	header, err := readEventsExportHeader(br)
+	if err != nil {
+		return err
+	}
+
+	lastEventId, err := s.LastEventId(ctx)
+	if err != nil {
+		return err
+	}
+	if lastEventId > 0 {
+		v, err := s.tx.GetOne(kv.BorEvents, binary.BigEndian.AppendUint64(nil, lastEventId))
+		if err != nil {
+			return err
+		}
+		var last heimdall.EventRecordWithTime
+		if err := last.UnmarshallBytes(v); err != nil {
+			return fmt.Errorf("event %d: %w", lastEventId, err)
+		}
+		if last.ChainID != header.ChainId {
+			return fmt.Errorf("%w: events of chain %s, the db ones being of chain %s", ErrInvalidEventsExport,
+				header.ChainId, last.ChainID)
+		}
+	}
+	lastEventId = max(lastEventId, lastFrozenEventId)
+
+	checksum := sha256.New()
+	var batch []*heimdall.EventRecordWithTime
+	for eventId := header.FromId; eventId <= header.ToId; eventId++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		event, err := readEventsExportRecord(br, checksum)
+		if err != nil {
+			return fmt.Errorf("%w: event %d: %w", ErrInvalidEventsExport, eventId, err)
+		}
+		if event.ID != eventId || event.ChainID != header.ChainId {
+			return fmt.Errorf("%w: event %d of chain %s in place of event %d", ErrInvalidEventsExport, event.ID,
+				event.ChainID, eventId)
+		}
+		if eventId <= lastEventId {
+			continue
+		}
+		if batch = append(batch, event); len(batch) == eventsImportBatchSize {
+			if err := s.putEvents(ctx, batch, EventsWritePolicy{}, lastEventId); err != nil {
+				return err
+			}
+			lastEventId, batch = batch[len(batch)-1].ID, batch[:0]
+		}
+	}
+	if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: trailing data after event %d", ErrInvalidEventsExport, header.ToId)
+	}
+	if !bytes.Equal(checksum.Sum(nil), header.Checksum[:]) {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidEventsExport)
+	}
+	return s.putEvents(ctx, batch, EventsWritePolicy{}, lastEventId)
+}
+
+func readEventsExportRecord(r *bufio.Reader, checksum hash.Hash) (*heimdall.EventRecordWithTime, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxExportedEventSize {
+		return nil, fmt.Errorf("record of %d bytes", size)
+	}
+	record := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+int(size)), size)
+	n := len(record)
+	record = record[:n+int(size)]
+	if _, err := io.ReadFull(r, record[n:]); err != nil {
+		return nil, err
+	}
+	checksum.Write(record)
+
+	var event heimdall.EventRecordWithTime
+	if len(record[n:]) < 4+32 {
+		return nil, fmt.Errorf("record of %d bytes", size)
+	}
+	if err := event.UnmarshallBytes(record[n:]); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
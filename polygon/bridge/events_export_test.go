@@ -0,0 +1,143 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+	"github.com/erigontech/erigon/polygon/heimdall"
+)
+
+func newEventsStore(t *testing.T, events []*heimdall.EventRecordWithTime) *SnapshotStore {
+	store := NewSnapshotStore(NewDbStore(memdb.NewTestDB(t, kv.ChainDB)), nil, nil)
+	require.NoError(t, store.PutEvents(context.Background(), events))
+	return store
+}
+
+func exportEvents(t *testing.T, store *SnapshotStore, fromId, toId uint64) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, store.ExportEvents(context.Background(), &buf, fromId, toId))
+	return buf.Bytes()
+}
+
+// requireSameEvents checks the stores have the same events [1, last].
+func requireSameEvents(t *testing.T, want, have *SnapshotStore, last uint64) {
+	lastEventId, err := have.LastEventId(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, last, lastEventId)
+	require.Equal(t, exportEvents(t, want, 1, last), exportEvents(t, have, 1, last))
+}
+
+// failingReader fails once it has read n bytes of r.
+type failingReader struct {
+	r io.Reader
+	n int
+}
+
+var errInterrupted = errors.New("interrupted")
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		return 0, errInterrupted
+	}
+	n, err := r.r.Read(p[:min(len(p), r.n)])
+	r.n -= n
+	return n, err
+}
+
+func TestExportImportEvents(t *testing.T) {
+	ctx := context.Background()
+	source := newEventsStore(t, eventsBatch(1, 2_500))
+	export := exportEvents(t, source, 1, 10_000)
+
+	t.Run("round trip", func(t *testing.T) {
+		target := newEventsStore(t, nil)
+		require.NoError(t, target.ImportEvents(ctx, bytes.NewReader(export)))
+		requireSameEvents(t, source, target, 2_500)
+		// importing again changes nothing
+		require.NoError(t, target.ImportEvents(ctx, bytes.NewReader(export)))
+		requireSameEvents(t, source, target, 2_500)
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		target := newEventsStore(t, nil)
+		err := target.ImportEvents(ctx, &failingReader{r: bytes.NewReader(export), n: len(export) / 2})
+		require.ErrorIs(t, err, errInterrupted)
+		lastEventId, err := target.LastEventId(ctx)
+		require.NoError(t, err)
+		require.Zero(t, lastEventId)
+
+		require.NoError(t, target.ImportEvents(ctx, bytes.NewReader(export)))
+		requireSameEvents(t, source, target, 2_500)
+	})
+
+	t.Run("in overlapping ranges", func(t *testing.T) {
+		target := newEventsStore(t, nil)
+		require.NoError(t, target.ImportEvents(ctx, bytes.NewReader(exportEvents(t, source, 1, 1_200))))
+		require.NoError(t, target.ImportEvents(ctx, bytes.NewReader(exportEvents(t, source, 1_000, 2_000))))
+		// a range not following the stored events is refused
+		err := target.ImportEvents(ctx, bytes.NewReader(exportEvents(t, source, 2_100, 2_500)))
+		require.ErrorIs(t, err, ErrEventsNotExtending)
+		require.NoError(t, target.ImportEvents(ctx, bytes.NewReader(exportEvents(t, source, 2_001, 2_500))))
+		requireSameEvents(t, source, target, 2_500)
+	})
+
+	t.Run("on the stored events", func(t *testing.T) {
+		target := newEventsStore(t, eventsBatch(1, 100))
+		require.NoError(t, target.ImportEvents(ctx, bytes.NewReader(export)))
+		requireSameEvents(t, source, target, 2_500)
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		for _, offset := range []int{
+			len(eventsExportMagic) + 1 + 1 + len("80002") + 16, // the checksum
+			len(export) - 1, // the last record
+		} {
+			corrupted := common.Copy(export)
+			corrupted[offset] ^= 1
+			target := newEventsStore(t, nil)
+			err := target.ImportEvents(ctx, bytes.NewReader(corrupted))
+			require.ErrorIs(t, err, ErrInvalidEventsExport, offset)
+			lastEventId, err := target.LastEventId(ctx)
+			require.NoError(t, err)
+			require.Zero(t, lastEventId, offset)
+		}
+
+		err := newEventsStore(t, nil).ImportEvents(ctx, bytes.NewReader(append(common.Copy(export), 0)))
+		require.ErrorIs(t, err, ErrInvalidEventsExport)
+		require.ErrorContains(t, err, "trailing data")
+	})
+
+	t.Run("other chain", func(t *testing.T) {
+		events := eventsBatch(1, 10)
+		for _, event := range events {
+			event.ChainID = "137"
+		}
+		err := newEventsStore(t, events).ImportEvents(ctx, bytes.NewReader(export))
+		require.ErrorIs(t, err, ErrInvalidEventsExport)
+		require.ErrorContains(t, err, "events of chain 80002, the db ones being of chain 137")
+	})
+}
@@ -0,0 +1,182 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon/core/state"
+)
+
+// ErrEventsPruned is returned for the blocks whose events were pruned from the db and aren't in the frozen files either.
+var ErrEventsPruned = fmt.Errorf("bor events: %w", state.PrunedError)
+
+// EventsRetention is the window of the last blocks whose events are kept in the db once frozen, the events of the
+// blocks before it being pruned. The events of a block are kept if it's within either of Blocks or Duration, so the
+// larger window of the two applies. The zero value keeps none, the frozen events being all pruned.
+type EventsRetention struct {
+	Blocks   uint64        // the blocks up to the last processed one
+	Duration time.Duration // the blocks of the last duration, up to the time of the last processed block
+}
+
+func (r EventsRetention) Enabled() bool {
+	return r.Blocks > 0 || r.Duration > 0
+}
+
+// WithEventsRetention keeps the events of the retention window in the db when pruning the frozen ones, and makes the
+// reads of the blocks whose events were pruned, and aren't in the files, fail with ErrEventsPruned.
+func WithEventsRetention(retention EventsRetention) SnapshotStoreOption {
+	return func(s *SnapshotStore) {
+		s.eventsRetention = retention
+	}
+}
+
+// EventsRetainedFrom returns the first block of the retention window, the processed blocks of the db ending it. All the
+// blocks are retained if none was processed.
+func EventsRetainedFrom(tx kv.Tx, retention EventsRetention) (uint64, error) {
+	cursor, err := tx.Cursor(kv.BorEventProcessedBlocks)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+
+	k, v, err := cursor.Last()
+	if err != nil {
+		return 0, err
+	}
+	if len(k) == 0 {
+		return 0, nil
+	}
+	var tip ProcessedBlockInfo
+	tip.UnmarshallBytes(k, v)
+
+	retainedFrom := tip.BlockNum + 1
+	if retention.Blocks > 0 {
+		retainedFrom = min(retainedFrom, tip.BlockNum+1-min(retention.Blocks, tip.BlockNum+1))
+	}
+	if retention.Duration > 0 {
+		seconds := uint64(retention.Duration / time.Second)
+		cutoff := tip.BlockTime - min(seconds, tip.BlockTime)
+
+		k, _, err = cursor.First()
+		if err != nil {
+			return 0, err
+		}
+		// the times of the processed blocks go up with their numbers: search the first block at or after the cutoff
+		lo, hi := binary.BigEndian.Uint64(k), tip.BlockNum
+		var key [8]byte
+		for lo < hi {
+			mid := lo + (hi-lo)/2
+			binary.BigEndian.PutUint64(key[:], mid)
+			k, v, err = cursor.Seek(key[:])
+			if err != nil {
+				return 0, err
+			}
+			var info ProcessedBlockInfo
+			info.UnmarshallBytes(k, v)
+			if info.BlockTime >= cutoff {
+				hi = mid
+			} else {
+				lo = info.BlockNum + 1
+			}
+		}
+		retainedFrom = min(retainedFrom, lo)
+	}
+	return retainedFrom, nil
+}
+
+// firstProcessedBlockNum returns the first block of the db, the events of the blocks before it having been pruned.
+func firstProcessedBlockNum(tx kv.Tx) (uint64, bool, error) {
+	cursor, err := tx.Cursor(kv.BorEventProcessedBlocks)
+	if err != nil {
+		return 0, false, err
+	}
+	defer cursor.Close()
+
+	k, _, err := cursor.First()
+	if err != nil || len(k) == 0 {
+		return 0, false, err
+	}
+	return binary.BigEndian.Uint64(k), true, nil
+}
+
+// PruneEvents prunes the events of the blocks before blocksTo, which are frozen, keeping the ones of the retention
+// window.
+func (s *SnapshotStore) PruneEvents(ctx context.Context, blocksTo uint64, blocksDeleteLimit int) (int, error) {
+	if s.eventsRetention.Enabled() {
+		retainedFrom, err := s.Store.(interface {
+			eventsRetainedFrom(context.Context, EventsRetention) (uint64, error)
+		}).eventsRetainedFrom(ctx, s.eventsRetention)
+		if err != nil {
+			return 0, err
+		}
+		blocksTo = min(blocksTo, retainedFrom)
+	}
+
+	return s.Store.PruneEvents(ctx, blocksTo, blocksDeleteLimit)
+}
+
+// checkEventsRetained fails with ErrEventsPruned for a block, which isn't in the files, from before the db.
+func (s *SnapshotStore) checkEventsRetained(ctx context.Context, blockNum uint64) error {
+	if !s.eventsRetention.Enabled() {
+		return nil
+	}
+
+	firstBlockNum, ok, err := s.Store.(interface {
+		firstProcessedBlockNum(context.Context) (uint64, bool, error)
+	}).firstProcessedBlockNum(ctx)
+	if err != nil {
+		return err
+	}
+	if ok && blockNum < firstBlockNum {
+		return fmt.Errorf("block %d, the events being retained from block %d: %w", blockNum, firstBlockNum,
+			ErrEventsPruned)
+	}
+	return nil
+}
+
+func (s *MdbxStore) eventsRetainedFrom(ctx context.Context, retention EventsRetention) (uint64, error) {
+	tx, err := s.db.BeginRo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	return txStore{tx}.eventsRetainedFrom(ctx, retention)
+}
+
+func (s txStore) eventsRetainedFrom(ctx context.Context, retention EventsRetention) (uint64, error) {
+	return EventsRetainedFrom(s.tx, retention)
+}
+
+func (s *MdbxStore) firstProcessedBlockNum(ctx context.Context) (uint64, bool, error) {
+	tx, err := s.db.BeginRo(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	defer tx.Rollback()
+
+	return txStore{tx}.firstProcessedBlockNum(ctx)
+}
+
+func (s txStore) firstProcessedBlockNum(ctx context.Context) (uint64, bool, error) {
+	return firstProcessedBlockNum(s.tx)
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/testlog"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/polygon/heimdall"
+)
+
+// newProcessedEventsStore is a db store having processed the sprint blocks of [from, to), each of two events, and of
+// the time of twice its number.
+func newProcessedEventsStore(t *testing.T, from, to uint64) *MdbxStore {
+	ctx := context.Background()
+	store := NewDbStore(memdb.NewTestDB(t, kv.ChainDB))
+
+	var events []*heimdall.EventRecordWithTime
+	blockNumToEventId := map[uint64]uint64{}
+	var processed []ProcessedBlockInfo
+	eventId := uint64(1)
+	for _, blockNum := range sprintBlocks(from, to) {
+		for i := 0; i < 2; i++ {
+			events = append(events, &heimdall.EventRecordWithTime{
+				EventRecord: heimdall.EventRecord{ID: eventId, ChainID: "80002"},
+				Time:        time.Unix(int64(blockNum*2), 0),
+			})
+			eventId++
+		}
+		blockNumToEventId[blockNum] = eventId - 1
+		processed = append(processed, ProcessedBlockInfo{BlockNum: blockNum, BlockTime: blockNum * 2})
+	}
+	require.NoError(t, store.PutEvents(ctx, events))
+	require.NoError(t, store.PutBlockNumToEventId(ctx, blockNumToEventId))
+	require.NoError(t, store.PutProcessedBlockInfo(ctx, processed))
+	return store
+}
+
+func TestEventsRetainedFrom(t *testing.T) {
+	ctx := context.Background()
+
+	// the blocks 16 to 1600, the last one of the time 3200
+	store := newProcessedEventsStore(t, 16, 1_601)
+	for _, tt := range []struct {
+		name      string
+		retention EventsRetention
+		want      uint64
+	}{
+		{name: "blocks", retention: EventsRetention{Blocks: 160}, want: 1_441},
+		{name: "blocks beyond the first", retention: EventsRetention{Blocks: 10_000}, want: 0},
+		// the block 1440 is of the time 2880, the one at the cutoff
+		{name: "duration at a block", retention: EventsRetention{Duration: 320 * time.Second}, want: 1_425},
+		{name: "duration a second short", retention: EventsRetention{Duration: 319 * time.Second}, want: 1_441},
+		{name: "duration of a fraction of a second", retention: EventsRetention{Duration: time.Second / 2}, want: 1_585},
+		{name: "duration beyond the first", retention: EventsRetention{Duration: time.Hour}, want: 16},
+		{name: "the larger window", retention: EventsRetention{Blocks: 160, Duration: 320 * time.Second}, want: 1_425},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			retainedFrom, err := store.eventsRetainedFrom(ctx, tt.retention)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, retainedFrom)
+		})
+	}
+
+	// all the blocks are retained before any is processed
+	retainedFrom, err := NewDbStore(memdb.NewTestDB(t, kv.ChainDB)).eventsRetainedFrom(ctx, EventsRetention{Blocks: 1})
+	require.NoError(t, err)
+	require.Zero(t, retainedFrom)
+}
+
+func TestSnapshotStorePruneEventsRetention(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name              string
+		blocksTo          uint64 // the blocks before it are frozen
+		retention         EventsRetention
+		wantFirstEventId  uint64
+		wantFirstBlockNum uint64
+	}{
+		{name: "no retention", blocksTo: 1_600, wantFirstEventId: 199, wantFirstBlockNum: 1_600},
+		// the events of the blocks 16 to 1440 are pruned, the ones of the block 1456 on are retained
+		{name: "frozen beyond the window", blocksTo: 1_600, retention: EventsRetention{Blocks: 160}, wantFirstEventId: 181, wantFirstBlockNum: 1_456},
+		{name: "frozen up to the window", blocksTo: 1_441, retention: EventsRetention{Blocks: 160}, wantFirstEventId: 181, wantFirstBlockNum: 1_456},
+		// the events before the window which aren't frozen yet are kept until they are
+		{name: "frozen before the window", blocksTo: 800, retention: EventsRetention{Blocks: 160}, wantFirstEventId: 99, wantFirstBlockNum: 800},
+		{name: "frozen beyond the duration", blocksTo: 1_600, retention: EventsRetention{Duration: 320 * time.Second}, wantFirstEventId: 179, wantFirstBlockNum: 1_440},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			base := newProcessedEventsStore(t, 16, 1_601)
+			store := NewSnapshotStore(base, nil, nil, WithEventsRetention(tt.retention))
+			_, err := store.PruneEvents(ctx, tt.blocksTo, 1_000)
+			require.NoError(t, err)
+
+			firstEventId, err := base.FirstEventId(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantFirstEventId, firstEventId)
+			firstBlockNum, ok, err := base.firstProcessedBlockNum(ctx)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, tt.wantFirstBlockNum, firstBlockNum)
+		})
+	}
+}
+
+func TestBlockEventIdsRangePruned(t *testing.T) {
+	ctx, dir := context.Background(), t.TempDir()
+	logger := testlog.Logger(t, log.LvlInfo)
+
+	// the files of the blocks before 500_000 were removed, and the db retains the events from the block 400_000
+	createEventsSegment(t, dir, 500_000, 1_000_000, sprintBlocks(500_000, 504_000), 2)
+	snapshots := heimdall.NewRoSnapshots(ethconfig.BlocksFreezing{ChainName: networkname.BorMainnet}, dir, 0, logger)
+	t.Cleanup(snapshots.Close)
+	require.NoError(t, snapshots.OpenFolder())
+	base := newProcessedEventsStore(t, 400_000, 401_600)
+
+	store := NewSnapshotStore(base, snapshots, nil, WithEventsRetention(EventsRetention{Duration: 90 * 24 * time.Hour}))
+	for _, blockNum := range []uint64{0, 399_984, 399_999} {
+		_, _, _, err := store.BlockEventIdsRange(ctx, common.Hash{}, blockNum)
+		require.ErrorIs(t, err, ErrEventsPruned, blockNum)
+		require.ErrorIs(t, err, state.PrunedError, blockNum)
+		_, err = store.EventsByBlock(ctx, common.Hash{}, blockNum)
+		require.ErrorIs(t, err, ErrEventsPruned, blockNum)
+		_, err = store.BorStartEventId(ctx, common.Hash{}, blockNum)
+		require.ErrorIs(t, err, ErrEventsPruned, blockNum)
+	}
+	// the first block retained, and the ones of the files
+	for _, blockNum := range []uint64{400_000, 401_584, 500_000, 503_984} {
+		_, _, ok, err := store.BlockEventIdsRange(ctx, common.Hash{}, blockNum)
+		require.NoError(t, err)
+		require.True(t, ok, blockNum)
+	}
+	// the blocks retained without events
+	for _, blockNum := range []uint64{400_001, 499_999, 500_001} {
+		_, _, ok, err := store.BlockEventIdsRange(ctx, common.Hash{}, blockNum)
+		require.NoError(t, err)
+		require.False(t, ok, blockNum)
+	}
+
+	// without a retention window the blocks pruned have no events, as before
+	store = NewSnapshotStore(base, snapshots, nil)
+	_, _, ok, err := store.BlockEventIdsRange(ctx, common.Hash{}, 399_984)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/erigontech/erigon-lib/kv"
+)
+
+// TableStats is the size of a bridge table: its entries, the bytes of their keys and values, and the part of them
+// which is of frozen events, so prunable.
+type TableStats struct {
+	Table         string
+	Count         uint64
+	Bytes         uint64
+	PrunableCount uint64
+	PrunableBytes uint64
+}
+
+// PrunableFraction is the fraction of the bytes of the table which are prunable, 0 if the table is empty.
+func (s TableStats) PrunableFraction() float64 {
+	if s.Bytes == 0 {
+		return 0
+	}
+	return float64(s.PrunableBytes) / float64(s.Bytes)
+}
+
+// EventsStats is the size of the event tables, the entries of the events up to LastFrozenEventId, or of their blocks
+// up to LastFrozenEventBlockNum, being prunable.
+type EventsStats struct {
+	LastFrozenEventId       uint64
+	LastFrozenEventBlockNum uint64
+	Tables                  []TableStats
+}
+
+// EventTablesStats walks the event tables, to quantify what pruning the frozen events would free before enabling it.
+func EventTablesStats(ctx context.Context, tx kv.Tx, lastFrozenEventId, lastFrozenEventBlockNum uint64) (EventsStats, error) {
+	eventFrozen := func(eventId uint64) bool { return eventId <= lastFrozenEventId }
+	blockFrozen := func(blockNum uint64) bool { return lastFrozenEventBlockNum > 0 && blockNum <= lastFrozenEventBlockNum }
+	uint64Field := func(b []byte) (uint64, error) {
+		if len(b) < 8 {
+			return 0, fmt.Errorf("%w: got %d bytes, want 8", errMalformedEvent, len(b))
+		}
+		return binary.BigEndian.Uint64(b), nil
+	}
+
+	tables := []struct {
+		table    string
+		prunable func(k, v []byte) (bool, error)
+	}{
+		{kv.BorEvents, func(k, _ []byte) (bool, error) { // event id -> event
+			eventId, err := uint64Field(k)
+			return eventFrozen(eventId), err
+		}},
+		{kv.BorEventTimes, func(_, v []byte) (bool, error) { // event time -> event id
+			eventId, err := uint64Field(v)
+			return eventFrozen(eventId), err
+		}},
+		{kv.BorEventNums, func(k, _ []byte) (bool, error) { // block num -> last event id of the block
+			blockNum, err := uint64Field(k)
+			return blockFrozen(blockNum), err
+		}},
+		{kv.BorEventProcessedBlocks, func(k, _ []byte) (bool, error) { // block num -> processed block info
+			blockNum, err := uint64Field(k)
+			return blockFrozen(blockNum), err
+		}},
+		{kv.BorTxLookup, func(_, v []byte) (bool, error) { // bor txn hash -> block num
+			return blockFrozen(new(big.Int).SetBytes(v).Uint64()), nil
+		}},
+	}
+
+	stats := EventsStats{LastFrozenEventId: lastFrozenEventId, LastFrozenEventBlockNum: lastFrozenEventBlockNum}
+	for _, t := range tables {
+		tableStats := TableStats{Table: t.table}
+		if err := tx.ForEach(t.table, nil, func(k, v []byte) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			size := uint64(len(k) + len(v))
+			tableStats.Count++
+			tableStats.Bytes += size
+			prunable, err := t.prunable(k, v)
+			if err != nil {
+				return fmt.Errorf("%s: %x: %w", t.table, k, err)
+			}
+			if prunable {
+				tableStats.PrunableCount++
+				tableStats.PrunableBytes += size
+			}
+			return nil
+		}); err != nil {
+			return EventsStats{}, err
+		}
+		stats.Tables = append(stats.Tables, tableStats)
+	}
+	return stats, nil
+}
+
+// EventTablesStats is the size of the event tables of the base store, see EventTablesStats.
+func (s *SnapshotStore) EventTablesStats(ctx context.Context) (EventsStats, error) {
+	statsStore, ok := s.Store.(interface {
+		eventTablesStats(ctx context.Context, lastFrozenEventId, lastFrozenEventBlockNum uint64) (EventsStats, error)
+	})
+	if !ok {
+		return EventsStats{}, fmt.Errorf("can't report the event tables of %T", s.Store)
+	}
+	return statsStore.eventTablesStats(ctx, s.LastFrozenEventId(), s.LastFrozenEventBlockNum())
+}
+
+func (s *MdbxStore) eventTablesStats(ctx context.Context, lastFrozenEventId, lastFrozenEventBlockNum uint64) (EventsStats, error) {
+	tx, err := s.db.BeginRo(ctx)
+	if err != nil {
+		return EventsStats{}, err
+	}
+	defer tx.Rollback()
+
+	return txStore{tx}.eventTablesStats(ctx, lastFrozenEventId, lastFrozenEventBlockNum)
+}
+
+func (s txStore) eventTablesStats(ctx context.Context, lastFrozenEventId, lastFrozenEventBlockNum uint64) (EventsStats, error) {
+	return EventTablesStats(ctx, s.tx, lastFrozenEventId, lastFrozenEventBlockNum)
+}
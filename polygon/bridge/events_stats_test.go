@@ -0,0 +1,95 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+	bortypes "github.com/erigontech/erigon/polygon/bor/types"
+	"github.com/erigontech/erigon/polygon/heimdall"
+)
+
+func TestEventTablesStats(t *testing.T) {
+	ctx := context.Background()
+	db := memdb.NewTestDB(t, kv.ChainDB)
+	store := NewDbStore(db)
+
+	// events 1 to 10, two of each of the blocks 16 to 80
+	var events []*heimdall.EventRecordWithTime
+	var eventsBytes, frozenEventsBytes uint64
+	for id := uint64(1); id <= 10; id++ {
+		event := &heimdall.EventRecordWithTime{
+			EventRecord: heimdall.EventRecord{ID: id, ChainID: "80002"},
+			Time:        time.Unix(int64(id), 0),
+		}
+		payload, err := event.MarshallBytes()
+		require.NoError(t, err)
+		eventsBytes += uint64(8 + len(payload))
+		if id <= 6 {
+			frozenEventsBytes += uint64(8 + len(payload))
+		}
+		events = append(events, event)
+	}
+	require.NoError(t, store.PutEvents(ctx, events))
+	blockNumToEventId := map[uint64]uint64{}
+	txnToBlockNum := map[common.Hash]uint64{}
+	var processed []ProcessedBlockInfo
+	for blockNum := uint64(16); blockNum <= 80; blockNum += 16 {
+		blockNumToEventId[blockNum] = blockNum / 8
+		txnToBlockNum[bortypes.ComputeBorTxHash(blockNum, common.Hash{})] = blockNum
+		processed = append(processed, ProcessedBlockInfo{BlockNum: blockNum, BlockTime: blockNum * 2})
+	}
+	require.NoError(t, store.PutBlockNumToEventId(ctx, blockNumToEventId))
+	require.NoError(t, store.PutEventTxnToBlockNum(ctx, txnToBlockNum))
+	require.NoError(t, store.PutProcessedBlockInfo(ctx, processed))
+
+	// the events 1 to 6, of the blocks 16 to 48, are frozen
+	var stats EventsStats
+	require.NoError(t, db.View(ctx, func(tx kv.Tx) (err error) {
+		stats, err = EventTablesStats(ctx, tx, 6, 48)
+		return err
+	}))
+	require.Equal(t, EventsStats{
+		LastFrozenEventId:       6,
+		LastFrozenEventBlockNum: 48,
+		Tables: []TableStats{
+			{Table: kv.BorEvents, Count: 10, Bytes: eventsBytes, PrunableCount: 6, PrunableBytes: frozenEventsBytes},
+			{Table: kv.BorEventTimes, Count: 10, Bytes: 10 * 16, PrunableCount: 6, PrunableBytes: 6 * 16},
+			{Table: kv.BorEventNums, Count: 5, Bytes: 5 * 16, PrunableCount: 3, PrunableBytes: 3 * 16},
+			{Table: kv.BorEventProcessedBlocks, Count: 5, Bytes: 5 * 16, PrunableCount: 3, PrunableBytes: 3 * 16},
+			// the block numbers are big ints, of a byte
+			{Table: kv.BorTxLookup, Count: 5, Bytes: 5 * 33, PrunableCount: 3, PrunableBytes: 3 * 33},
+		},
+	}, stats)
+	require.InDelta(t, 0.6, stats.Tables[1].PrunableFraction(), 1e-9)
+
+	// nothing is prunable without frozen events
+	stats, err := NewSnapshotStore(store, nil, nil).EventTablesStats(ctx)
+	require.NoError(t, err)
+	for _, table := range stats.Tables {
+		require.NotZero(t, table.Count, table.Table)
+		require.Zero(t, table.PrunableCount, table.Table)
+		require.Zero(t, table.PrunableFraction(), table.Table)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/metrics"
+	"github.com/erigontech/erigon/polygon/heimdall"
+)
+
+// ErrEventsNotExtending is returned for a batch of events which doesn't extend the stored ones, nothing of it being
+// written.
+var ErrEventsNotExtending = errors.New("bridge events don't extend the stored ones")
+
+var invalidEventsWrites = metrics.GetOrCreateCounter("bridge_events_invalid_writes")
+
+// EventsWritePolicy is what a batch of events written to the store is checked against. By default its first event is
+// the one after the last stored, its ids are consecutive and its times don't decrease, the events having no block
+// numbers but being looked up by time.
+type EventsWritePolicy struct {
+	// AllowRewrite lets the batch begin with stored events, e.g. when fetching again the events missing before the db
+	// ones, as long as the ones in the db are written again unchanged.
+	AllowRewrite bool
+	// ForceWrite writes the batch unchecked, for repair tooling.
+	ForceWrite bool
+}
+
+// WithEventsWritePolicy sets the policy of the events written, their first one extending the stored ones, frozen
+// included.
+func WithEventsWritePolicy(policy EventsWritePolicy) SnapshotStoreOption {
+	return func(s *SnapshotStore) {
+		s.eventsWritePolicy = policy
+	}
+}
+
+// validateEventsExtension checks the events extend the stored ones up to lastEventId, any being accepted if there
+// are none. stored returns the encoding of a stored event, nil if it isn't in the db.
+func validateEventsExtension(events []*heimdall.EventRecordWithTime, lastEventId uint64, policy EventsWritePolicy, stored func(eventId uint64) ([]byte, error)) error {
+	err := eventsExtension(events, lastEventId, policy, stored)
+	if errors.Is(err, ErrEventsNotExtending) {
+		invalidEventsWrites.Inc()
+	}
+	return err
+}
+
+func eventsExtension(events []*heimdall.EventRecordWithTime, lastEventId uint64, policy EventsWritePolicy, stored func(eventId uint64) ([]byte, error)) error {
+	if policy.ForceWrite || len(events) == 0 {
+		return nil
+	}
+
+	first := events[0]
+	switch {
+	case lastEventId == 0:
+	case first.ID > lastEventId+1:
+		return fmt.Errorf("%w: event %d after the stored event %d, missing the ones between", ErrEventsNotExtending,
+			first.ID, lastEventId)
+	case first.ID <= lastEventId && !policy.AllowRewrite:
+		return fmt.Errorf("%w: event %d after the stored event %d, overlapping it", ErrEventsNotExtending, first.ID,
+			lastEventId)
+	}
+
+	for i, event := range events {
+		if i > 0 {
+			prev := events[i-1]
+			if event.ID != prev.ID+1 {
+				return fmt.Errorf("%w: event %d follows event %d", ErrEventsNotExtending, event.ID, prev.ID)
+			}
+			if event.Time.Before(prev.Time) {
+				return fmt.Errorf("%w: event %d of %s follows event %d of %s", ErrEventsNotExtending, event.ID,
+					event.Time, prev.ID, prev.Time)
+			}
+		}
+		if event.ID > lastEventId {
+			continue
+		}
+		v, err := stored(event.ID)
+		if err != nil {
+			return err
+		}
+		if v == nil { // frozen, or missing before the db ones
+			continue
+		}
+		encoded, err := event.MarshallBytes()
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(v, encoded) {
+			return fmt.Errorf("%w: event %d differs from the stored one", ErrEventsNotExtending, event.ID)
+		}
+	}
+	return nil
+}
+
+// eventsWriter is a store writing events checked against an EventsWritePolicy, reading the last event id of its db in
+// the transaction writing them.
+type eventsWriter interface {
+	putEvents(ctx context.Context, events []*heimdall.EventRecordWithTime, policy EventsWritePolicy, lastFrozenEventId uint64) error
+}
+
+// PutEvents writes events extending the stored ones, frozen included, see WithEventsWritePolicy.
+func (s *SnapshotStore) PutEvents(ctx context.Context, events []*heimdall.EventRecordWithTime) error {
+	w, ok := s.Store.(eventsWriter)
+	if !ok {
+		return fmt.Errorf("can't check the events written to the store %T", s.Store)
+	}
+	return w.putEvents(ctx, events, s.eventsWritePolicy, s.LastFrozenEventId())
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/testlog"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/polygon/heimdall"
+)
+
+// eventsBatch is the events [first, last], each of the time of its id.
+func eventsBatch(first, last uint64) []*heimdall.EventRecordWithTime {
+	var events []*heimdall.EventRecordWithTime
+	for id := first; id <= last; id++ {
+		events = append(events, &heimdall.EventRecordWithTime{
+			EventRecord: heimdall.EventRecord{ID: id, ChainID: "80002"},
+			Time:        time.Unix(int64(id), 0),
+		})
+	}
+	return events
+}
+
+func TestPutEventsExtension(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name    string
+		events  []*heimdall.EventRecordWithTime
+		policy  EventsWritePolicy
+		wantErr string
+	}{
+		{name: "exact extension", events: eventsBatch(11, 20)},
+		{name: "gap", events: eventsBatch(12, 20), wantErr: "event 12 after the stored event 10, missing the ones between"},
+		{name: "overlap", events: eventsBatch(10, 20), wantErr: "event 10 after the stored event 10, overlapping it"},
+		{name: "restart from the first", events: eventsBatch(1, 20), wantErr: "event 1 after the stored event 10, overlapping it"},
+		{name: "overlap rewritten unchanged", events: eventsBatch(5, 20), policy: EventsWritePolicy{AllowRewrite: true}},
+		{name: "gap when rewriting", events: eventsBatch(12, 20), policy: EventsWritePolicy{AllowRewrite: true}, wantErr: "missing the ones between"},
+		{name: "overlap rewritten changed", policy: EventsWritePolicy{AllowRewrite: true}, wantErr: "event 7 differs from the stored one",
+			events: func() []*heimdall.EventRecordWithTime {
+				events := eventsBatch(5, 20)
+				events[2].ChainID = "137"
+				return events
+			}()},
+		{name: "ids not consecutive", wantErr: "event 14 follows event 12",
+			events: append(eventsBatch(11, 12), eventsBatch(14, 20)...)},
+		{name: "time going back", wantErr: "event 13 of",
+			events: func() []*heimdall.EventRecordWithTime {
+				events := eventsBatch(11, 20)
+				events[2].Time = time.Unix(5, 0)
+				return events
+			}()},
+		{name: "forced", events: eventsBatch(1, 20), policy: EventsWritePolicy{ForceWrite: true}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db := memdb.NewTestDB(t, kv.ChainDB)
+			store := NewDbStore(db)
+			require.NoError(t, store.PutEvents(ctx, eventsBatch(1, 10)))
+			invalidWrites := invalidEventsWrites.GetValueUint64()
+
+			err := store.putEvents(ctx, tt.events, tt.policy, 0)
+			lastEventId, lastErr := store.LastEventId(ctx)
+			require.NoError(t, lastErr)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.Equal(t, uint64(20), lastEventId)
+				require.Equal(t, invalidWrites, invalidEventsWrites.GetValueUint64())
+				return
+			}
+			require.ErrorIs(t, err, ErrEventsNotExtending)
+			require.ErrorContains(t, err, tt.wantErr)
+			// nothing of the batch is written
+			require.Equal(t, uint64(10), lastEventId)
+			require.Equal(t, invalidWrites+1, invalidEventsWrites.GetValueUint64())
+		})
+	}
+
+	// an empty db takes events from any id, the frozen ones being unknown to it
+	store := NewDbStore(memdb.NewTestDB(t, kv.ChainDB))
+	require.NoError(t, store.PutEvents(ctx, eventsBatch(101, 110)))
+	require.ErrorIs(t, store.PutEvents(ctx, eventsBatch(101, 110)), ErrEventsNotExtending)
+}
+
+func TestSnapshotStorePutEventsAfterFrozen(t *testing.T) {
+	ctx, dir := context.Background(), t.TempDir()
+	// the events 1 to 20 are frozen, the db has none
+	createEventsSegment(t, dir, 0, 500_000, sprintBlocks(16, 176), 2)
+	logger := testlog.Logger(t, log.LvlInfo)
+	snapshots := heimdall.NewRoSnapshots(ethconfig.BlocksFreezing{ChainName: networkname.BorMainnet}, dir, 0, logger)
+	t.Cleanup(snapshots.Close)
+	require.NoError(t, snapshots.OpenFolder())
+
+	store := NewSnapshotStore(NewDbStore(memdb.NewTestDB(t, kv.ChainDB)), snapshots, nil)
+	require.ErrorIs(t, store.PutEvents(ctx, eventsBatch(1, 30)), ErrEventsNotExtending)
+	require.ErrorIs(t, store.PutEvents(ctx, eventsBatch(22, 30)), ErrEventsNotExtending)
+	require.NoError(t, store.PutEvents(ctx, eventsBatch(21, 30)))
+	require.NoError(t, store.PutEvents(ctx, eventsBatch(31, 40)))
+	lastEventId, err := store.LastEventId(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(40), lastEventId)
+
+	// fetching again from the frozen ones writes the events missing from the db, and the db ones unchanged
+	store = NewSnapshotStore(store.Store, snapshots, nil, WithEventsWritePolicy(EventsWritePolicy{AllowRewrite: true}))
+	require.NoError(t, store.PutEvents(ctx, eventsBatch(15, 45)))
+	firstEventId, err := store.Store.(*MdbxStore).FirstEventId(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(15), firstEventId)
+
+	// a store which can't check the events doesn't write them unchecked
+	store = NewSnapshotStore(struct{ Store }{store.Store}, snapshots, nil)
+	require.ErrorContains(t, store.PutEvents(ctx, eventsBatch(46, 50)), "can't check the events written")
+}
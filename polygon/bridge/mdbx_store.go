@@ -107,6 +107,17 @@ func (s *MdbxStore) LastEventId(ctx context.Context) (uint64, error) {
 	return txStore{tx}.LastEventId(ctx)
 }
 
+// FirstEventId the earliest state sync event Id in given DB, 0 if DB is empty
+func (s *MdbxStore) FirstEventId(ctx context.Context) (uint64, error) {
+	tx, err := s.db.BeginRo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	return txStore{tx}.FirstEventId(ctx)
+}
+
 // LastProcessedEventId gets the last seen event Id in the BorEventNums table
 func (s *MdbxStore) LastProcessedEventId(ctx context.Context) (uint64, error) {
 	tx, err := s.db.BeginRo(ctx)
@@ -184,6 +195,16 @@ func (s *MdbxStore) EventTxnToBlockNum(ctx context.Context, borTxHash common.Has
 	return txStore{tx}.EventTxnToBlockNum(ctx, borTxHash)
 }
 
+func (s *MdbxStore) EventTxnsToBlockNums(ctx context.Context, borTxHashes []common.Hash) (map[common.Hash]uint64, error) {
+	tx, err := s.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return txStore{tx}.EventTxnsToBlockNums(ctx, borTxHashes)
+}
+
 // LastEventIdWithinWindow gets the last event id where event.Id >= fromId and event.Time < toTime.
 func (s *MdbxStore) LastEventIdWithinWindow(ctx context.Context, fromId uint64, toTime time.Time) (uint64, error) {
 	tx, err := s.db.BeginRo(ctx)
@@ -249,6 +270,20 @@ func (s *MdbxStore) PutEvents(ctx context.Context, events []*heimdall.EventRecor
 	return tx.Commit()
 }
 
+func (s *MdbxStore) putEvents(ctx context.Context, events []*heimdall.EventRecordWithTime, policy EventsWritePolicy, lastFrozenEventId uint64) error {
+	tx, err := s.db.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = (txStore{tx}).putEvents(ctx, events, policy, lastFrozenEventId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (s *MdbxStore) EventsByTimeframe(ctx context.Context, timeFrom, timeTo uint64) ([][]byte, []uint64, error) {
 	tx, err := s.db.BeginRo(ctx)
 	if err != nil {
@@ -331,8 +366,19 @@ func (s *MdbxStore) EventsByBlock(ctx context.Context, hash common.Hash, blockHe
 	return txStore{tx}.EventsByBlock(ctx, hash, blockHeight)
 }
 
-func (s *MdbxStore) EventsByIdFromSnapshot(from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
-	return nil, false, nil
+// EventsByIdFromSnapshot is SnapshotStore.EventsByIdFromSnapshot of the db events, the same ones for the same events.
+func (s *MdbxStore) EventsByIdFromSnapshot(ctx context.Context, from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
+	return s.EventsByIdFromSnapshotUnixMilli(ctx, from, to.UnixMilli(), limit)
+}
+
+func (s *MdbxStore) EventsByIdFromSnapshotUnixMilli(ctx context.Context, from uint64, toUnixMilli int64, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
+	tx, err := s.db.BeginRo(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	return txStore{tx}.EventsByIdFromSnapshotUnixMilli(ctx, from, toUnixMilli, limit)
 }
 
 func (s *MdbxStore) PruneEvents(ctx context.Context, blocksTo uint64, blocksDeleteLimit int) (deleted int, err error) {
@@ -342,7 +388,12 @@ func (s *MdbxStore) PruneEvents(ctx context.Context, blocksTo uint64, blocksDele
 	}
 	defer tx.Rollback()
 
-	return txStore{tx}.PruneEvents(ctx, blocksTo, blocksDeleteLimit)
+	deleted, err = txStore{tx}.PruneEvents(ctx, blocksTo, blocksDeleteLimit)
+	if err != nil {
+		return deleted, err
+	}
+
+	return deleted, tx.Commit()
 }
 
 func NewTxStore(tx kv.Tx) txStore {
@@ -377,6 +428,26 @@ func (s txStore) LastEventId(ctx context.Context) (uint64, error) {
 	return binary.BigEndian.Uint64(k), err
 }
 
+// FirstEventId the earliest state sync event Id in given DB, 0 if DB is empty
+func (s txStore) FirstEventId(ctx context.Context) (uint64, error) {
+	cursor, err := s.tx.Cursor(kv.BorEvents)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+
+	k, _, err := cursor.First()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(k) == 0 {
+		return 0, nil
+	}
+
+	return binary.BigEndian.Uint64(k), err
+}
+
 // LastProcessedEventId gets the last seen event Id in the BorEventNums table
 func (s txStore) LastProcessedEventId(ctx context.Context) (uint64, error) {
 	cursor, err := s.tx.Cursor(kv.BorEventNums)
@@ -478,18 +549,52 @@ func (s txStore) EventTxnToBlockNum(ctx context.Context, borTxHash common.Hash)
 	return blockNum, true, nil
 }
 
+func (s txStore) EventTxnsToBlockNums(ctx context.Context, borTxHashes []common.Hash) (map[common.Hash]uint64, error) {
+	blockNums := make(map[common.Hash]uint64, len(borTxHashes))
+	for _, borTxHash := range borTxHashes {
+		v, err := s.tx.GetOne(kv.BorTxLookup, borTxHash.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			blockNums[borTxHash] = new(big.Int).SetBytes(v).Uint64()
+		}
+	}
+	return blockNums, nil
+}
+
 // LastEventIdWithinWindow gets the last event id where event.Id >= fromId and event.Time < toTime.
 func (s txStore) LastEventIdWithinWindow(ctx context.Context, fromId uint64, toTime time.Time) (uint64, error) {
 	return lastEventIdWithinWindow(s.tx, fromId, toTime)
 }
 
+// PutEvents writes events extending the ones of the db, see EventsWritePolicy.
 func (s txStore) PutEvents(ctx context.Context, events []*heimdall.EventRecordWithTime) error {
+	return s.putEvents(ctx, events, EventsWritePolicy{}, 0)
+}
+
+// putEvents writes events extending the ones of the db, and the frozen ones up to lastFrozenEventId.
+func (s txStore) putEvents(ctx context.Context, events []*heimdall.EventRecordWithTime, policy EventsWritePolicy, lastFrozenEventId uint64) error {
 	tx, ok := s.tx.(kv.RwTx)
 
 	if !ok {
 		return errors.New("expected RW tx")
 	}
 
+	lastEventId, err := s.LastEventId(ctx)
+	if err != nil {
+		return err
+	}
+	lastEventId = max(lastEventId, lastFrozenEventId)
+
+	if err := validateEventsExtension(events, lastEventId, policy, func(eventId uint64) ([]byte, error) {
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], eventId)
+		return tx.GetOne(kv.BorEvents, k[:])
+	}); err != nil {
+		return err
+	}
+
 	for _, event := range events {
 		v, err := event.MarshallBytes()
 		if err != nil {
@@ -559,8 +664,12 @@ func (s txStore) events(ctx context.Context, start, end uint64) ([][]byte, error
 	if err != nil {
 		return nil, err
 	}
+	defer it.Close()
 
-	for it.HasNext() {
+	for records := 0; it.HasNext(); records++ {
+		if err := scanCanceled(ctx, records, "EventsByBlock"); err != nil {
+			return nil, err
+		}
 		_, v, err := it.Next()
 		if err != nil {
 			return nil, err
@@ -670,8 +779,39 @@ func (s txStore) EventsByBlock(ctx context.Context, hash common.Hash, blockHeigh
 	return result, nil
 }
 
-func (s txStore) EventsByIdFromSnapshot(from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
-	return nil, false, nil
+func (s txStore) EventsByIdFromSnapshot(ctx context.Context, from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
+	return s.EventsByIdFromSnapshotUnixMilli(ctx, from, to.UnixMilli(), limit)
+}
+
+func (s txStore) EventsByIdFromSnapshotUnixMilli(ctx context.Context, from uint64, toUnixMilli int64, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, from)
+
+	it, err := s.tx.Range(kv.BorEvents, k, nil, order.Asc, kv.Unlim)
+	if err != nil {
+		return nil, false, err
+	}
+	defer it.Close()
+
+	events := eventsUntil{from: from, toUnixMilli: toUnixMilli, limit: limit}
+	for records := 0; it.HasNext(); records++ {
+		if err := scanCanceled(ctx, records, "EventsByIdFromSnapshot"); err != nil {
+			return nil, false, err
+		}
+		_, v, err := it.Next()
+		if err != nil {
+			return nil, false, err
+		}
+		more, err := events.add(v)
+		if err != nil {
+			return nil, false, err
+		}
+		if !more {
+			break
+		}
+	}
+
+	return events.result, events.limitedByTime, nil
 }
 
 func (s txStore) PruneEvents(ctx context.Context, blocksTo uint64, blocksDeleteLimit int) (deleted int, err error) {
@@ -148,6 +148,11 @@ func (r *Reader) EventTxnLookup(ctx context.Context, borTxHash common.Hash) (uin
 	return r.store.EventTxnToBlockNum(ctx, borTxHash)
 }
 
+// EventTxnsLookup is EventTxnLookup of a batch of hashes, the ones not found being absent from the result.
+func (r *Reader) EventTxnsLookup(ctx context.Context, borTxHashes []common.Hash) (map[common.Hash]uint64, error) {
+	return r.store.EventTxnsToBlockNums(ctx, borTxHashes)
+}
+
 func (r *Reader) Close() {
 	r.store.Close()
 }
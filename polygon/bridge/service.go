@@ -44,6 +44,9 @@ type ServiceConfig struct {
 	Logger       log.Logger
 	BorConfig    *borcfg.BorConfig
 	EventFetcher eventFetcher
+	// HealEventsGap makes the service fetch again the events missing between the frozen and the db ones, rather than
+	// refusing to start, see GapBetweenFrozenAndDb.
+	HealEventsGap bool
 }
 
 func NewService(config ServiceConfig) *Service {
@@ -54,6 +57,7 @@ func NewService(config ServiceConfig) *Service {
 		eventFetcher:        config.EventFetcher,
 		reader:              NewReader(config.Store, config.Logger, config.BorConfig.StateReceiverContractAddress()),
 		transientErrors:     heimdall.TransientErrors,
+		healEventsGap:       config.HealEventsGap,
 		fetchedEventsSignal: make(chan struct{}),
 	}
 }
@@ -65,6 +69,7 @@ type Service struct {
 	eventFetcher    eventFetcher
 	reader          *Reader
 	transientErrors []error
+	healEventsGap   bool
 	// internal state
 	reachedTip             atomic.Bool
 	fetchedEventsSignal    chan struct{}
@@ -124,6 +129,17 @@ func (s *Service) Ready(ctx context.Context) <-chan error {
 	return errc
 }
 
+// LastEventId returns the id of the last event fetched, once the service is ready.
+func (s *Service) LastEventId(ctx context.Context) (uint64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-s.ready.On():
+	}
+
+	return s.store.LastEventId(ctx)
+}
+
 func (s *Service) Run(ctx context.Context) error {
 	defer func() {
 		if s.fetchedEventsSignal != nil {
@@ -144,6 +160,10 @@ func (s *Service) Run(ctx context.Context) error {
 		return err
 	}
 
+	if lastFetchedEventId, err = s.checkEventsConsistency(ctx, lastFetchedEventId); err != nil {
+		return err
+	}
+
 	lastProcessedEventId, err := s.store.LastProcessedEventId(ctx)
 	if err != nil {
 		return err
@@ -265,6 +285,34 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 }
 
+// checkEventsConsistency checks the db events against the frozen ones, if the store has both, and returns the last
+// event id the fetching continues after: lastFetchedEventId, or the last frozen one to fetch again the events of a
+// gap, if healEventsGap.
+func (s *Service) checkEventsConsistency(ctx context.Context, lastFetchedEventId uint64) (uint64, error) {
+	checker, ok := s.store.(interface {
+		ConsistencyCheck(ctx context.Context) (ConsistencyResult, error)
+	})
+	if !ok {
+		return lastFetchedEventId, nil
+	}
+	result, err := checker.ConsistencyCheck(ctx)
+	if err != nil {
+		return 0, err
+	}
+	switch result := result.(type) {
+	case GapBetweenFrozenAndDb:
+		if !s.healEventsGap {
+			return 0, fmt.Errorf("%w: %s, --polygon.bridge.heal-events-gap fetches them again", ErrEventsGap, result)
+		}
+		s.logger.Warn(bridgeLogPrefix("fetching again the events missing between the frozen and the db ones"),
+			"from", result.From, "to", result.To)
+		return result.From - 1, nil
+	case OverlapDetected:
+		s.logger.Info(bridgeLogPrefix("db events overlap the frozen ones, until pruned"), "from", result.From, "to", result.To)
+	}
+	return lastFetchedEventId, nil
+}
+
 func (s *Service) Close() {
 	s.store.Close()
 }
@@ -19,33 +19,89 @@ package bridge
 import (
 	"bytes"
 	"context"
-	"encoding/binary"
 	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
 	"time"
 
+	"github.com/RoaringBitmap/roaring/v2"
+
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon-lib/common/length"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/recsplit"
 	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/seg"
 	"github.com/erigontech/erigon-lib/snaptype"
 	"github.com/erigontech/erigon/polygon/bor/types"
 	"github.com/erigontech/erigon/polygon/heimdall"
 	"github.com/erigontech/erigon/turbo/snapshotsync"
 )
 
+// Bor event snapshot entries are laid out as: txn hash | block num | event id | rlp encoded event.
+const (
+	eventBlockNumOffset = length.Hash
+	eventIdOffset       = eventBlockNumOffset + length.BlockNum
+	eventPayloadOffset  = eventIdOffset + 8
+)
+
+var errMalformedEvent = errors.New("malformed bor event")
+
+// decodeEventField decodes the big endian number at offset of a bor event snapshot entry.
+// The length is checked before slicing, so that a truncated entry is an error rather than a panic.
+func decodeEventField(buf []byte, offset int) (uint64, error) {
+	if len(buf) < offset+8 {
+		return 0, fmt.Errorf("%w: got %d bytes, want at least %d", errMalformedEvent, len(buf), offset+8)
+	}
+	return hexutil.DecodeTs(buf[offset:])
+}
+
+func decodeEventId(buf []byte) (uint64, error) {
+	return decodeEventField(buf, eventIdOffset)
+}
+
+func decodeEventBlockNumAndId(buf []byte) (blockNum uint64, eventId uint64, err error) {
+	if blockNum, err = decodeEventField(buf, eventBlockNumOffset); err != nil {
+		return 0, 0, err
+	}
+	if eventId, err = decodeEventId(buf); err != nil {
+		return 0, 0, err
+	}
+	return blockNum, eventId, nil
+}
+
 type SnapshotStore struct {
 	Store
 	snapshots              *heimdall.RoSnapshots
 	sprintLengthCalculator sprintLengthCalculator
+	wrapRangeExtractor     func(snaptype.RangeExtractor) snaptype.RangeExtractor
+	eventsRetention        EventsRetention
+	eventsWritePolicy      EventsWritePolicy
+}
+
+type SnapshotStoreOption func(*SnapshotStore)
+
+// WithRangeExtractorWrapper wraps the range extractor of the events segments, e.g. with ValidateRangeExtractor, so that
+// the records going into the segments built from the store can be checked.
+func WithRangeExtractorWrapper(wrap func(snaptype.RangeExtractor) snaptype.RangeExtractor) SnapshotStoreOption {
+	return func(s *SnapshotStore) {
+		s.wrapRangeExtractor = wrap
+	}
 }
 
 type sprintLengthCalculator interface {
 	CalculateSprintLength(number uint64) uint64
 }
 
-func NewSnapshotStore(base Store, snapshots *heimdall.RoSnapshots, sprintLengthCalculator sprintLengthCalculator) *SnapshotStore {
-	return &SnapshotStore{base, snapshots, sprintLengthCalculator}
+func NewSnapshotStore(base Store, snapshots *heimdall.RoSnapshots, sprintLengthCalculator sprintLengthCalculator, opts ...SnapshotStoreOption) *SnapshotStore {
+	s := &SnapshotStore{Store: base, snapshots: snapshots, sprintLengthCalculator: sprintLengthCalculator}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *SnapshotStore) Prepare(ctx context.Context) error {
@@ -57,7 +113,8 @@ func (s *SnapshotStore) Prepare(ctx context.Context) error {
 }
 
 func (s *SnapshotStore) WithTx(tx kv.Tx) Store {
-	return &SnapshotStore{txStore{tx: tx}, s.snapshots, s.sprintLengthCalculator}
+	return &SnapshotStore{txStore{tx: tx}, s.snapshots, s.sprintLengthCalculator, s.wrapRangeExtractor, s.eventsRetention,
+		s.eventsWritePolicy}
 }
 
 func (s *SnapshotStore) RangeExtractor() snaptype.RangeExtractor {
@@ -65,10 +122,14 @@ func (s *SnapshotStore) RangeExtractor() snaptype.RangeExtractor {
 		RangeExtractor() snaptype.RangeExtractor
 	}
 
+	rangeExtractor := heimdall.Events.RangeExtractor()
 	if extractableStore, ok := s.Store.(extractableStore); ok {
-		return extractableStore.RangeExtractor()
+		rangeExtractor = extractableStore.RangeExtractor()
+	}
+	if s.wrapRangeExtractor != nil {
+		return s.wrapRangeExtractor(rangeExtractor)
 	}
-	return heimdall.Events.RangeExtractor()
+	return rangeExtractor
 }
 
 func (s *SnapshotStore) LastFrozenEventBlockNum() uint64 {
@@ -102,7 +163,11 @@ func (s *SnapshotStore) LastFrozenEventBlockNum() uint64 {
 	gg := lastSegment.Src().MakeGetter()
 	for gg.HasNext() {
 		buf, _ = gg.Next(buf[:0])
-		lastBlockNum = binary.BigEndian.Uint64(buf[length.Hash : length.Hash+length.BlockNum])
+		blockNum, err := decodeEventField(buf, eventBlockNumOffset)
+		if err != nil {
+			panic(fmt.Errorf("%s: %w", lastSegment.Src().FileName(), err))
+		}
+		lastBlockNum = blockNum
 	}
 
 	return lastBlockNum
@@ -177,11 +242,43 @@ func (s *SnapshotStore) LastFrozenEventId() uint64 {
 	var buf []byte
 	for gg.HasNext() {
 		buf, _ = gg.Next(buf[:0])
-		lastEventId = binary.BigEndian.Uint64(buf[length.Hash+length.BlockNum : length.Hash+length.BlockNum+8])
+		eventId, err := decodeEventId(buf)
+		if err != nil {
+			panic(fmt.Errorf("%s: %w", lastSegment.Src().FileName(), err))
+		}
+		lastEventId = eventId
 	}
 	return lastEventId
 }
 
+func (s *SnapshotStore) FirstFrozenEventId() uint64 {
+	if s.snapshots == nil {
+		return 0
+	}
+
+	tx := s.snapshots.ViewType(heimdall.Events)
+	defer tx.Close()
+	segments := tx.Segments
+
+	// find the first segment which has a built non-empty index
+	for _, segment := range segments {
+		if segment.Src().Index() == nil {
+			continue
+		}
+		gg := segment.Src().MakeGetter()
+		if !gg.HasNext() {
+			continue
+		}
+		buf, _ := gg.Next(nil)
+		eventId, err := decodeEventId(buf)
+		if err != nil {
+			panic(fmt.Errorf("%s: %w", segment.Src().FileName(), err))
+		}
+		return eventId
+	}
+	return 0
+}
+
 func (s *SnapshotStore) LastProcessedEventId(ctx context.Context) (uint64, error) {
 	lastEventId, err := s.Store.LastProcessedEventId(ctx)
 
@@ -218,17 +315,34 @@ func (s *SnapshotStore) EventTxnToBlockNum(ctx context.Context, txnHash common.H
 }
 
 func (s *SnapshotStore) BlockEventIdsRange(ctx context.Context, blockHash common.Hash, blockNum uint64) (uint64, uint64, bool, error) {
-	maxBlockNumInFiles := s.snapshots.VisibleBlocksAvailable(heimdall.Events.Enum())
-	if maxBlockNumInFiles == 0 || blockNum > maxBlockNumInFiles {
+	dbBlockEventIdsRange := func() (uint64, uint64, bool, error) {
 		return s.Store.(interface {
 			blockEventIdsRange(context.Context, common.Hash, uint64, uint64) (uint64, uint64, bool, error)
 		}).blockEventIdsRange(ctx, blockHash, blockNum, s.LastFrozenEventId())
 	}
 
+	maxBlockNumInFiles := s.snapshots.VisibleBlocksAvailable(heimdall.Events.Enum())
+	if maxBlockNumInFiles == 0 || blockNum > maxBlockNumInFiles {
+		if maxBlockNumInFiles == 0 {
+			if err := s.checkEventsRetained(ctx, blockNum); err != nil {
+				return 0, 0, false, err
+			}
+		}
+		return dbBlockEventIdsRange()
+	}
+
 	tx := s.snapshots.ViewType(heimdall.Events)
 	defer tx.Close()
 	segments := tx.Segments
 
+	// the blocks before the files, which were removed, are only in the db, if they weren't pruned from it
+	if len(segments) > 0 && blockNum < segments[0].From() {
+		if err := s.checkEventsRetained(ctx, blockNum); err != nil {
+			return 0, 0, false, err
+		}
+		return dbBlockEventIdsRange()
+	}
+
 	for i := len(segments) - 1; i >= 0; i-- {
 		sn := segments[i]
 		if sn.From() > blockNum {
@@ -243,6 +357,15 @@ func (s *SnapshotStore) BlockEventIdsRange(ctx context.Context, blockHash common
 			continue
 		}
 
+		// most of the blocks have no events, which would be scanned for through the whole segment
+		withEvents, err := blocksWithEvents(sn)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if !withEvents.Contains(uint32(blockNum - sn.From())) {
+			continue
+		}
+
 		reader := recsplit.NewIndexReader(idxBorTxnHash)
 		txnHash := types.ComputeBorTxHash(blockNum, blockHash)
 		blockEventId, exists := reader.Lookup(txnHash[:])
@@ -258,17 +381,31 @@ func (s *SnapshotStore) BlockEventIdsRange(ctx context.Context, blockHash common
 		}
 
 		var buf []byte
-		for gg.HasNext() {
+		for records := 0; gg.HasNext(); records++ {
+			if err := scanCanceled(ctx, records, "BlockEventIdsRange"); err != nil {
+				return 0, 0, false, err
+			}
 			buf, _ = gg.Next(buf[:0])
-			if blockNum == binary.BigEndian.Uint64(buf[length.Hash:length.Hash+length.BlockNum]) {
-				start := binary.BigEndian.Uint64(buf[length.Hash+length.BlockNum : length.Hash+length.BlockNum+8])
+			eventBlockNum, start, err := decodeEventBlockNumAndId(buf)
+			if err != nil {
+				return 0, 0, false, err
+			}
+			if blockNum == eventBlockNum {
 				end := start
 				for gg.HasNext() {
+					records++
+					if err := scanCanceled(ctx, records, "BlockEventIdsRange"); err != nil {
+						return 0, 0, false, err
+					}
 					buf, _ = gg.Next(buf[:0])
-					if blockNum != binary.BigEndian.Uint64(buf[length.Hash:length.Hash+length.BlockNum]) {
+					eventBlockNum, eventId, err := decodeEventBlockNumAndId(buf)
+					if err != nil {
+						return 0, 0, false, err
+					}
+					if blockNum != eventBlockNum {
 						break
 					}
-					end = binary.BigEndian.Uint64(buf[length.Hash+length.BlockNum : length.Hash+length.BlockNum+8])
+					end = eventId
 				}
 				return start, end, true, nil
 			}
@@ -278,6 +415,50 @@ func (s *SnapshotStore) BlockEventIdsRange(ctx context.Context, blockHash common
 	return 0, 0, false, nil
 }
 
+// scanCtxCheckStride is the number of records a scan reads between the checks of its ctx, so that the scan of a
+// cancelled request stops without the cost of a check per record.
+const scanCtxCheckStride = 1 << 10
+
+// scanCanceled returns the error of ctx, wrapped with the method scanning, once every scanCtxCheckStride records.
+func scanCanceled(ctx context.Context, records int, method string) error {
+	if records%scanCtxCheckStride != 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	return nil
+}
+
+const blocksWithEventsKey = "bor-events-blocks"
+
+// blocksWithEvents returns the blocks of the segment having events, as offsets from its first block. It's built by the
+// first call, from a scan of the segment, and cached with it.
+func blocksWithEvents(sn *snapshotsync.VisibleSegment) (*roaring.Bitmap, error) {
+	derived, err := sn.Src().Derived(blocksWithEventsKey, func() (any, error) {
+		blocks := roaring.New()
+		gg := sn.Src().MakeGetter()
+		var buf []byte
+		for gg.HasNext() {
+			buf, _ = gg.Next(buf[:0])
+			blockNum, err := decodeEventField(buf, eventBlockNumOffset)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", sn.Src().FileName(), err)
+			}
+			if blockNum < sn.From() || blockNum-sn.From() > math.MaxUint32 { // never looked up in this segment
+				continue
+			}
+			blocks.Add(uint32(blockNum - sn.From()))
+		}
+		blocks.RunOptimize()
+		return blocks, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return derived.(*roaring.Bitmap), nil
+}
+
 func (s *SnapshotStore) events(ctx context.Context, start, end, blockNumber uint64) ([][]byte, error) {
 	tx := s.snapshots.ViewType(heimdall.Events)
 	defer tx.Close()
@@ -301,15 +482,25 @@ func (s *SnapshotStore) events(ctx context.Context, start, end, blockNumber uint
 		}
 
 		buf0, _ := gg0.Next(nil)
-		if end <= binary.BigEndian.Uint64(buf0[length.Hash+length.BlockNum:length.Hash+length.BlockNum+8]) {
+		firstEventId, err := decodeEventId(buf0)
+		if err != nil {
+			return nil, err
+		}
+		if end <= firstEventId {
 			continue
 		}
 
 		gg0.Reset(0)
-		for gg0.HasNext() {
+		for records := 0; gg0.HasNext(); records++ {
+			if err := scanCanceled(ctx, records, "EventsByBlock"); err != nil {
+				return nil, err
+			}
 			buf, _ = gg0.Next(buf[:0])
 
-			eventId := binary.BigEndian.Uint64(buf[length.Hash+length.BlockNum : length.Hash+length.BlockNum+8])
+			eventId, err := decodeEventId(buf)
+			if err != nil {
+				return nil, err
+			}
 
 			if eventId < start {
 				continue
@@ -319,7 +510,7 @@ func (s *SnapshotStore) events(ctx context.Context, start, end, blockNumber uint
 				return result, nil
 			}
 
-			result = append(result, bytes.Clone(buf[length.Hash+length.BlockNum+8:]))
+			result = append(result, bytes.Clone(buf[eventPayloadOffset:]))
 		}
 	}
 
@@ -337,25 +528,82 @@ func (s *SnapshotStore) borBlockByEventHash(txnHash common.Hash, segments []*sna
 		if idxBorTxnHash.KeyCount() == 0 {
 			continue
 		}
-		reader := recsplit.NewIndexReader(idxBorTxnHash)
-		blockEventId, exists := reader.Lookup(txnHash[:])
-		if !exists {
-			continue
+		blockNum, ok, err = segmentBorBlockByEventHash(sn, recsplit.NewIndexReader(idxBorTxnHash), sn.Src().MakeGetter(), txnHash, buf)
+		if err != nil || ok {
+			return
 		}
-		offset := idxBorTxnHash.OrdinalLookup(blockEventId)
-		gg := sn.Src().MakeGetter()
-		gg.Reset(offset)
-		if !gg.MatchPrefix(txnHash[:]) {
-			continue
-		}
-		buf, _ = gg.Next(buf[:0])
-		blockNum = binary.BigEndian.Uint64(buf[length.Hash:])
-		ok = true
-		return
 	}
 	return
 }
 
+// segmentBorBlockByEventHash looks the txn hash up in the segment, with its index reader and a getter of it.
+func segmentBorBlockByEventHash(sn *snapshotsync.VisibleSegment, reader *recsplit.IndexReader, gg *seg.Getter, txnHash common.Hash, buf []byte) (blockNum uint64, ok bool, err error) {
+	blockEventId, exists := reader.Lookup(txnHash[:])
+	if !exists {
+		return 0, false, nil
+	}
+	offset := sn.Src().Index().OrdinalLookup(blockEventId)
+	gg.Reset(offset)
+	if !gg.MatchPrefix(txnHash[:]) {
+		return 0, false, nil
+	}
+	buf, _ = gg.Next(buf[:0])
+	blockNum, err = decodeEventField(buf, eventBlockNumOffset)
+	if err != nil {
+		return 0, false, fmt.Errorf("malformed bor event in %s: %w", sn.Src().FileName(), err)
+	}
+	return blockNum, true, nil
+}
+
+// EventTxnsToBlockNums resolves a batch of txn hashes with one view of the segments, each segment probed for all the
+// hashes left, from the last one, then the db for the ones not frozen. The hashes not found are absent from the result.
+func (s *SnapshotStore) EventTxnsToBlockNums(ctx context.Context, txnHashes []common.Hash) (map[common.Hash]uint64, error) {
+	blockNums := make(map[common.Hash]uint64, len(txnHashes))
+	remaining := slices.Clone(txnHashes)
+
+	if s.snapshots != nil {
+		tx := s.snapshots.ViewType(heimdall.Events)
+		defer tx.Close()
+		segments := tx.Segments
+
+		var buf []byte
+		for i := len(segments) - 1; i >= 0 && len(remaining) > 0; i-- {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			sn := segments[i]
+			idxBorTxnHash := sn.Src().Index()
+			if idxBorTxnHash == nil || idxBorTxnHash.KeyCount() == 0 {
+				continue
+			}
+			reader, gg := recsplit.NewIndexReader(idxBorTxnHash), sn.Src().MakeGetter()
+			left := remaining[:0]
+			for _, txnHash := range remaining {
+				blockNum, ok, err := segmentBorBlockByEventHash(sn, reader, gg, txnHash, buf)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					blockNums[txnHash] = blockNum
+				} else {
+					left = append(left, txnHash)
+				}
+			}
+			remaining = left
+		}
+	}
+
+	if len(remaining) == 0 {
+		return blockNums, nil
+	}
+	dbBlockNums, err := s.Store.EventTxnsToBlockNums(ctx, remaining)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(blockNums, dbBlockNums)
+	return blockNums, nil
+}
+
 func (s *SnapshotStore) BorStartEventId(ctx context.Context, hash common.Hash, blockHeight uint64) (uint64, error) {
 	startEventId, _, ok, err := s.BlockEventIdsRange(ctx, hash, blockHeight)
 	if !ok || err != nil {
@@ -389,15 +637,50 @@ func (s *SnapshotStore) EventsByBlock(ctx context.Context, hash common.Hash, blo
 	return result, nil
 }
 
-// EventsByIdFromSnapshot returns the list of records limited by time, or the number of records along with a bool value to signify if the records were limited by time
-func (s *SnapshotStore) EventsByIdFromSnapshot(from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
+// eventsUntil collects the events from an id up to a time, the one of EventsByIdFromSnapshot, so that the db and the
+// snapshots compare the same way: the events, of whole seconds, at the cutoff are included, whatever the zone or the
+// precision of the cutoff.
+type eventsUntil struct {
+	from          uint64
+	toUnixMilli   int64
+	limit         int // unlimited if 0
+	result        []*heimdall.EventRecordWithTime
+	limitedByTime bool
+}
+
+// add decodes the next event, the events being in id order, telling whether more are wanted.
+func (c *eventsUntil) add(raw []byte) (more bool, err error) {
+	var event heimdall.EventRecordWithTime
+	if err := event.UnmarshallBytes(raw); err != nil {
+		return false, err
+	}
+	if event.ID < c.from {
+		return true, nil
+	}
+	if event.Time.UnixMilli() > c.toUnixMilli {
+		c.limitedByTime = true
+		return false, nil
+	}
+	event.Time = event.Time.UTC()
+	c.result = append(c.result, &event)
+	return len(c.result) != c.limit, nil
+}
+
+// EventsByIdFromSnapshot returns the list of records limited by time, or the number of records along with a bool value to signify if the records were limited by time.
+// The records at to are included, see eventsUntil.
+func (s *SnapshotStore) EventsByIdFromSnapshot(ctx context.Context, from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
+	return s.EventsByIdFromSnapshotUnixMilli(ctx, from, to.UnixMilli(), limit)
+}
+
+// EventsByIdFromSnapshotUnixMilli is EventsByIdFromSnapshot of a cutoff in unix milliseconds, sparing the conversions.
+func (s *SnapshotStore) EventsByIdFromSnapshotUnixMilli(ctx context.Context, from uint64, toUnixMilli int64, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
 	tx := s.snapshots.ViewType(heimdall.Events)
 	defer tx.Close()
 	segments := tx.Segments
 
 	var buf []byte
-	var result []*heimdall.EventRecordWithTime
-	maxTime := false
+	var records int
+	events := eventsUntil{from: from, toUnixMilli: toUnixMilli, limit: limit}
 
 	for _, sn := range segments {
 		idxBorTxnHash := sn.Src().Index()
@@ -409,30 +692,21 @@ func (s *SnapshotStore) EventsByIdFromSnapshot(from uint64, to time.Time, limit
 		offset := idxBorTxnHash.OrdinalLookup(0)
 		gg := sn.Src().MakeGetter()
 		gg.Reset(offset)
-		for gg.HasNext() {
-			buf, _ = gg.Next(buf[:0])
-
-			raw := rlp.RawValue(common.Copy(buf[length.Hash+length.BlockNum+8:]))
-			var event heimdall.EventRecordWithTime
-			if err := event.UnmarshallBytes(raw); err != nil {
+		for ; gg.HasNext(); records++ {
+			if err := scanCanceled(ctx, records, "EventsByIdFromSnapshot"); err != nil {
 				return nil, false, err
 			}
+			buf, _ = gg.Next(buf[:0])
 
-			if event.ID < from {
-				continue
-			}
-			if event.Time.After(to) {
-				maxTime = true
-				return result, maxTime, nil
+			more, err := events.add(common.Copy(buf[eventPayloadOffset:]))
+			if err != nil {
+				return nil, false, err
 			}
-
-			result = append(result, &event)
-
-			if len(result) == limit {
-				return result, maxTime, nil
+			if !more {
+				return events.result, events.limitedByTime, nil
 			}
 		}
 	}
 
-	return result, maxTime, nil
+	return events.result, events.limitedByTime, nil
 }
@@ -0,0 +1,393 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/recsplit"
+	"github.com/erigontech/erigon-lib/seg"
+	"github.com/erigontech/erigon-lib/snaptype"
+	"github.com/erigontech/erigon-lib/testlog"
+	"github.com/erigontech/erigon-lib/version"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	bortypes "github.com/erigontech/erigon/polygon/bor/types"
+	"github.com/erigontech/erigon/polygon/heimdall"
+)
+
+func TestDecodeEventBlockNumAndId(t *testing.T) {
+	buf := make([]byte, eventPayloadOffset, eventPayloadOffset+3)
+	binary.BigEndian.PutUint64(buf[eventBlockNumOffset:], 100)
+	binary.BigEndian.PutUint64(buf[eventIdOffset:], 7)
+	buf = append(buf, 0xc2, 0x01, 0x02)
+
+	blockNum, eventId, err := decodeEventBlockNumAndId(buf)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), blockNum)
+	require.Equal(t, uint64(7), eventId)
+
+	// truncated entries, including ones shorter than the offset of the field, are errors rather than panics
+	for _, n := range []int{0, eventBlockNumOffset - 1, eventBlockNumOffset + 4, eventIdOffset, eventPayloadOffset - 1} {
+		_, _, err := decodeEventBlockNumAndId(buf[:n])
+		require.ErrorIs(t, err, errMalformedEvent, "length %d", n)
+	}
+}
+
+// createEventsSegment writes the events segment of [from, to), with eventsPerBlock events of each of blocks, whose
+// hashes are zero, and indexes it as the frozen events are. A spans segment of the same range goes with it.
+func createEventsSegment(tb testing.TB, dir string, from, to uint64, blocks []uint64, eventsPerBlock int) {
+	createEventsSegmentWithPayload(tb, dir, from, to, blocks, eventsPerBlock, func(uint64) []byte { return []byte{0xc0} })
+}
+
+// createEventsSegmentWithPayload is createEventsSegment, the payloads of the events given by their ids.
+func createEventsSegmentWithPayload(tb testing.TB, dir string, from, to uint64, blocks []uint64, eventsPerBlock int, payload func(eventId uint64) []byte) {
+	ctx, logger := context.Background(), log.New()
+	compressCfg := seg.DefaultCfg
+	compressCfg.MinPatternScore = 100
+	fileName := snaptype.SegmentFileName(version.V1_0, from, to, heimdall.Enums.Events)
+	c, err := seg.NewCompressor(ctx, "test", filepath.Join(dir, fileName), dir, compressCfg, log.LvlDebug, logger)
+	require.NoError(tb, err)
+	defer c.Close()
+	c.DisableFsync()
+	eventId := uint64(1)
+	for _, blockNum := range blocks {
+		txnHash := bortypes.ComputeBorTxHash(blockNum, common.Hash{})
+		for i := 0; i < eventsPerBlock; i++ {
+			word := make([]byte, eventPayloadOffset)
+			copy(word, txnHash[:])
+			binary.BigEndian.PutUint64(word[eventBlockNumOffset:], blockNum)
+			binary.BigEndian.PutUint64(word[eventIdOffset:], eventId)
+			require.NoError(tb, c.AddWord(append(word, payload(eventId)...)))
+			eventId++
+		}
+	}
+	require.NoError(tb, c.Compress())
+	info, _, ok := snaptype.ParseFileName(dir, fileName)
+	require.True(tb, ok)
+	require.NoError(tb, heimdall.Events.BuildIndexes(ctx, info, nil, nil, dir, nil, log.LvlDebug, logger))
+
+	spans, err := seg.NewCompressor(ctx, "test", filepath.Join(dir, snaptype.SegmentFileName(version.V1_0, from, to, heimdall.Enums.Spans)), dir, compressCfg, log.LvlDebug, logger)
+	require.NoError(tb, err)
+	defer spans.Close()
+	spans.DisableFsync()
+	require.NoError(tb, spans.AddWord([]byte{1}))
+	require.NoError(tb, spans.Compress())
+	idx, err := recsplit.NewRecSplit(recsplit.RecSplitArgs{
+		KeyCount:   1,
+		BucketSize: 10,
+		TmpDir:     dir,
+		IndexFile:  filepath.Join(dir, snaptype.IdxFileName(version.V1_0, from, to, heimdall.Enums.Spans.String())),
+		LeafSize:   8,
+	}, logger)
+	require.NoError(tb, err)
+	defer idx.Close()
+	idx.DisableFsync()
+	require.NoError(tb, idx.AddKey([]byte{1}, 0))
+	require.NoError(tb, idx.Build(ctx))
+}
+
+func newFrozenEventsStore(tb testing.TB, dir string) *SnapshotStore {
+	logger := testlog.Logger(tb, log.LvlInfo)
+	snapshots := heimdall.NewRoSnapshots(ethconfig.BlocksFreezing{ChainName: networkname.BorMainnet}, dir, 0, logger)
+	tb.Cleanup(snapshots.Close)
+	require.NoError(tb, snapshots.OpenFolder())
+	return NewSnapshotStore(NewMdbxStore(tb.TempDir(), logger, false, 1), snapshots, nil)
+}
+
+// sprintBlocks are the sprint boundaries of [from, to), the only blocks having events.
+func sprintBlocks(from, to uint64) []uint64 {
+	var blocks []uint64
+	for blockNum := from; blockNum < to; blockNum += 16 {
+		blocks = append(blocks, blockNum)
+	}
+	return blocks
+}
+
+func TestBlockEventIdsRangeFrozen(t *testing.T) {
+	ctx, dir := context.Background(), t.TempDir()
+	createEventsSegment(t, dir, 0, 500_000, sprintBlocks(16, 4_000), 2)
+	store := newFrozenEventsStore(t, dir)
+
+	// the events of block 16 are 1 and 2, of block 32 3 and 4, ...
+	for _, blockNum := range []uint64{16, 32, 3_984} {
+		start, end, ok, err := store.BlockEventIdsRange(ctx, common.Hash{}, blockNum)
+		require.NoError(t, err)
+		require.True(t, ok, blockNum)
+		require.Equal(t, blockNum/16*2-1, start, blockNum)
+		require.Equal(t, blockNum/16*2, end, blockNum)
+	}
+	for _, blockNum := range []uint64{0, 15, 17, 3_985, 10_000} {
+		_, _, ok, err := store.BlockEventIdsRange(ctx, common.Hash{}, blockNum)
+		require.NoError(t, err)
+		require.False(t, ok, blockNum)
+	}
+}
+
+func BenchmarkBlockEventIdsRangeNoEvents(b *testing.B) {
+	ctx, dir := context.Background(), b.TempDir()
+	createEventsSegment(b, dir, 0, 500_000, sprintBlocks(16, 500_000), 1)
+	store := newFrozenEventsStore(b, dir)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// the blocks between the sprint boundaries, as queried by the logs of the receipts
+		blockNum := uint64(i%499_000) | 1
+		if _, _, ok, err := store.BlockEventIdsRange(ctx, common.Hash{}, blockNum); err != nil || ok {
+			b.Fatal(blockNum, ok, err)
+		}
+	}
+}
+
+func TestEventsByIdFromSnapshotBoundary(t *testing.T) {
+	ctx, dir := context.Background(), t.TempDir()
+	// the events 1 to 10, several of them of the same second
+	times := []int64{100, 100, 101, 102, 102, 102, 103, 104, 105, 105}
+	events := make([]*heimdall.EventRecordWithTime, len(times))
+	for i, sec := range times {
+		events[i] = &heimdall.EventRecordWithTime{
+			EventRecord: heimdall.EventRecord{ID: uint64(i + 1), ChainID: "80002"},
+			Time:        time.Unix(sec, 0).UTC(),
+		}
+	}
+
+	dbStore := NewMdbxStore(t.TempDir(), testlog.Logger(t, log.LvlDebug), false, 1)
+	require.NoError(t, dbStore.Prepare(ctx))
+	t.Cleanup(dbStore.Close)
+	require.NoError(t, dbStore.PutEvents(ctx, events))
+
+	createEventsSegmentWithPayload(t, dir, 0, 500_000, sprintBlocks(16, 96), 2, func(eventId uint64) []byte {
+		payload, err := events[eventId-1].MarshallBytes()
+		require.NoError(t, err)
+		return payload
+	})
+	snapshotStore := newFrozenEventsStore(t, dir)
+
+	for _, tt := range []struct {
+		name          string
+		from          uint64
+		to            time.Time
+		limit         int
+		want          []uint64
+		limitedByTime bool
+	}{
+		{name: "at the cutoff", from: 1, to: time.Unix(102, 0), want: []uint64{1, 2, 3, 4, 5, 6}, limitedByTime: true},
+		{name: "other zone", from: 1, to: time.Unix(102, 0).In(time.FixedZone("UTC+5", 5*3600)), want: []uint64{1, 2, 3, 4, 5, 6}, limitedByTime: true},
+		{name: "sub-second cutoff", from: 1, to: time.Unix(102, 999_000_000), want: []uint64{1, 2, 3, 4, 5, 6}, limitedByTime: true},
+		{name: "a millisecond before", from: 1, to: time.UnixMilli(101_999), want: []uint64{1, 2, 3}, limitedByTime: true},
+		{name: "from an event at the cutoff", from: 5, to: time.Unix(102, 0), want: []uint64{5, 6}, limitedByTime: true},
+		{name: "limited", from: 1, to: time.Unix(102, 0), limit: 2, want: []uint64{1, 2}},
+		{name: "after all", from: 1, to: time.Unix(105, 0), want: []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{name: "before all", from: 1, to: time.Unix(99, 0), limitedByTime: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dbEvents, dbLimited, err := dbStore.EventsByIdFromSnapshot(ctx, tt.from, tt.to, tt.limit)
+			require.NoError(t, err)
+			snapshotEvents, snapshotLimited, err := snapshotStore.EventsByIdFromSnapshot(ctx, tt.from, tt.to, tt.limit)
+			require.NoError(t, err)
+			require.Equal(t, dbEvents, snapshotEvents)
+			require.Equal(t, dbLimited, snapshotLimited)
+
+			var ids []uint64
+			for _, event := range snapshotEvents {
+				ids = append(ids, event.ID)
+				require.Equal(t, time.UTC, event.Time.Location())
+			}
+			require.Equal(t, tt.want, ids)
+			require.Equal(t, tt.limitedByTime, snapshotLimited)
+
+			milliEvents, milliLimited, err := snapshotStore.EventsByIdFromSnapshotUnixMilli(ctx, tt.from, tt.to.UnixMilli(), tt.limit)
+			require.NoError(t, err)
+			require.Equal(t, snapshotEvents, milliEvents)
+			require.Equal(t, snapshotLimited, milliLimited)
+		})
+	}
+}
+
+// createEventsSegments writes n events segments of 500_000 blocks from 0, each with an event of each of the sprint
+// blocks of its first perSegment*16 blocks, and returns the bor txn hashes of the events with their blocks.
+func createEventsSegments(tb testing.TB, dir string, n int, perSegment uint64) map[common.Hash]uint64 {
+	want := map[common.Hash]uint64{}
+	for i := uint64(0); i < uint64(n); i++ {
+		from := i * 500_000
+		blocks := sprintBlocks(from+16, from+16+perSegment*16)
+		createEventsSegment(tb, dir, from, from+500_000, blocks, 1)
+		for _, blockNum := range blocks {
+			want[bortypes.ComputeBorTxHash(blockNum, common.Hash{})] = blockNum
+		}
+	}
+	return want
+}
+
+func TestEventTxnsToBlockNums(t *testing.T) {
+	ctx, dir := context.Background(), t.TempDir()
+	want := createEventsSegments(t, dir, 4, 10)
+	logger := testlog.Logger(t, log.LvlInfo)
+	snapshots := heimdall.NewRoSnapshots(ethconfig.BlocksFreezing{ChainName: networkname.BorMainnet}, dir, 0, logger)
+	t.Cleanup(snapshots.Close)
+	require.NoError(t, snapshots.OpenFolder())
+	base := NewDbStore(memdb.NewTestDB(t, kv.ChainDB))
+	store := NewSnapshotStore(base, snapshots, nil)
+
+	// the events of the block after the files are in the db only
+	dbTxnHash := bortypes.ComputeBorTxHash(2_000_016, common.Hash{})
+	require.NoError(t, base.PutEventTxnToBlockNum(ctx, map[common.Hash]uint64{dbTxnHash: 2_000_016}))
+	want[dbTxnHash] = 2_000_016
+
+	var txnHashes []common.Hash
+	for txnHash := range want {
+		txnHashes = append(txnHashes, txnHash)
+	}
+	// a duplicate, and blocks without events
+	missing := []common.Hash{
+		bortypes.ComputeBorTxHash(17, common.Hash{}),
+		bortypes.ComputeBorTxHash(16, common.Hash{1}),
+		bortypes.ComputeBorTxHash(2_000_032, common.Hash{}),
+	}
+	txnHashes = append(append(txnHashes, txnHashes[0]), missing...)
+
+	blockNums, err := store.EventTxnsToBlockNums(ctx, txnHashes)
+	require.NoError(t, err)
+	require.Equal(t, want, blockNums)
+	// the same as the single lookups
+	for _, txnHash := range txnHashes {
+		blockNum, ok, err := store.EventTxnToBlockNum(ctx, txnHash)
+		require.NoError(t, err)
+		wantBlockNum, wantOk := want[txnHash]
+		require.Equal(t, wantOk, ok)
+		require.Equal(t, wantBlockNum, blockNum)
+	}
+
+	blockNums, err = store.EventTxnsToBlockNums(ctx, missing)
+	require.NoError(t, err)
+	require.Empty(t, blockNums)
+}
+
+func BenchmarkEventTxnsToBlockNums(b *testing.B) {
+	ctx, dir := context.Background(), b.TempDir()
+	want := createEventsSegments(b, dir, 10, 100)
+	store := newFrozenEventsStore(b, dir)
+	require.NoError(b, store.Store.Prepare(ctx))
+	b.Cleanup(store.Close)
+	txnHashes := make([]common.Hash, 0, len(want))
+	for txnHash := range want {
+		txnHashes = append(txnHashes, txnHash)
+	}
+
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			blockNums, err := store.EventTxnsToBlockNums(ctx, txnHashes)
+			if err != nil || len(blockNums) != len(txnHashes) {
+				b.Fatal(len(blockNums), err)
+			}
+		}
+	})
+	b.Run("single", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, txnHash := range txnHashes {
+				if _, ok, err := store.EventTxnToBlockNum(ctx, txnHash); err != nil || !ok {
+					b.Fatal(txnHash, err)
+				}
+			}
+		}
+	})
+}
+
+// cancelAfterChecks is a ctx cancelled once checked checks times, so that a scan is cancelled mid-way.
+type cancelAfterChecks struct {
+	context.Context
+	checks int
+	n      int
+}
+
+func (c *cancelAfterChecks) Err() error {
+	if c.n++; c.n > c.checks {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestScansCancelled(t *testing.T) {
+	ctx, dir := context.Background(), t.TempDir()
+	// a segment below the merge limit, whose views are counted, of the events 1 to 24_996
+	blocks := sprintBlocks(16, 100_000)
+	createEventsSegmentWithPayload(t, dir, 0, 100_000, blocks, 4, func(eventId uint64) []byte {
+		payload, err := eventsBatch(eventId, eventId)[0].MarshallBytes()
+		require.NoError(t, err)
+		return payload
+	})
+	store := newFrozenEventsStore(t, dir)
+	require.NoError(t, store.Store.Prepare(ctx))
+	t.Cleanup(store.Close)
+	db := newProcessedEventsStore(t, 16, 100_000)
+
+	requireCancelled := func(t *testing.T, ctx *cancelAfterChecks, err error, method string) {
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorContains(t, err, method)
+		// the scan stops at the first check once cancelled
+		require.Equal(t, ctx.checks+1, ctx.n)
+
+		view := store.snapshots.ViewType(heimdall.Events)
+		segments := view.Segments
+		view.Close()
+		require.NotEmpty(t, segments)
+		for _, sn := range segments {
+			require.Zero(t, sn.Src().Refcount(), sn.Src().FileName())
+		}
+	}
+
+	t.Run("EventsByBlock", func(t *testing.T) {
+		// the events of the last block, scanned for from the first of the segment
+		ctx := &cancelAfterChecks{Context: ctx, checks: 3}
+		_, err := store.EventsByBlock(ctx, common.Hash{}, blocks[len(blocks)-1])
+		requireCancelled(t, ctx, err, "EventsByBlock")
+	})
+	t.Run("EventsByIdFromSnapshot", func(t *testing.T) {
+		ctx := &cancelAfterChecks{Context: ctx, checks: 3}
+		_, _, err := store.EventsByIdFromSnapshot(ctx, 1, time.Unix(math.MaxInt32, 0), 0)
+		requireCancelled(t, ctx, err, "EventsByIdFromSnapshot")
+	})
+	t.Run("db EventsByIdFromSnapshot", func(t *testing.T) {
+		ctx := &cancelAfterChecks{Context: ctx, checks: 3}
+		_, _, err := db.EventsByIdFromSnapshot(ctx, 1, time.Unix(math.MaxInt32, 0), 0)
+		requireCancelled(t, ctx, err, "EventsByIdFromSnapshot")
+	})
+	t.Run("cancelled before", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := store.EventsByBlock(ctx, common.Hash{}, blocks[len(blocks)-1])
+		require.ErrorIs(t, err, context.Canceled)
+		_, _, err = store.EventsByIdFromSnapshot(ctx, 1, time.Unix(math.MaxInt32, 0), 0)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	// a scan which isn't cancelled reads all the events
+	events, _, err := store.EventsByIdFromSnapshot(ctx, 1, time.Unix(math.MaxInt32, 0), 0)
+	require.NoError(t, err)
+	require.Len(t, events, 4*len(blocks))
+}
@@ -37,6 +37,7 @@ type Store interface {
 	LastFrozenEventBlockNum() uint64
 
 	EventTxnToBlockNum(ctx context.Context, borTxHash common.Hash) (uint64, bool, error)
+	EventTxnsToBlockNums(ctx context.Context, borTxHashes []common.Hash) (map[common.Hash]uint64, error)                           // the hashes not found are absent
 	BlockEventIdsRange(ctx context.Context, blockHash common.Hash, blockNum uint64) (start uint64, end uint64, ok bool, err error) // [start,end)
 	EventsByTimeframe(ctx context.Context, timeFrom, timeTo uint64) ([][]byte, []uint64, error)                                    // [timeFrom, timeTo)
 
@@ -50,6 +51,6 @@ type Store interface {
 	// block reader compatibility
 	BorStartEventId(ctx context.Context, hash common.Hash, blockHeight uint64) (uint64, error)
 	EventsByBlock(ctx context.Context, hash common.Hash, blockNum uint64) ([]rlp.RawValue, error)
-	EventsByIdFromSnapshot(from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error)
+	EventsByIdFromSnapshot(ctx context.Context, from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error)
 	PruneEvents(ctx context.Context, blocksTo uint64, blocksDeleteLimit int) (deleted int, err error)
 }
@@ -0,0 +1,105 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/snaptype"
+	"github.com/erigontech/erigon/polygon/heimdall"
+)
+
+var ErrInvalidEventRecord = errors.New("invalid bor event record")
+
+// ValidatingRangeExtractor checks the records extracted by Base before they're collected: the event ids are
+// consecutive, the block numbers don't decrease, and the payload decodes to the event of the record's id. An invalid
+// record aborts the extraction, so that no segment is built of it, unless CollectOnly, which logs it and goes on.
+type ValidatingRangeExtractor struct {
+	Base        snaptype.RangeExtractor
+	CollectOnly bool
+}
+
+// ValidateRangeExtractor is a wrapper for WithRangeExtractorWrapper, see ValidatingRangeExtractor.
+func ValidateRangeExtractor(collectOnly bool) func(snaptype.RangeExtractor) snaptype.RangeExtractor {
+	return func(base snaptype.RangeExtractor) snaptype.RangeExtractor {
+		return ValidatingRangeExtractor{Base: base, CollectOnly: collectOnly}
+	}
+}
+
+func (e ValidatingRangeExtractor) Extract(ctx context.Context, blockFrom, blockTo uint64, firstKey snaptype.FirstKeyGetter, db kv.RoDB, chainConfig *chain.Config, collect func([]byte) error, workers int, lvl log.Lvl, logger log.Logger, hashResolver snaptype.BlockHashResolver) (uint64, error) {
+	var validator eventRecordValidator
+	var problems int
+	lastEventId, err := e.Base.Extract(ctx, blockFrom, blockTo, firstKey, db, chainConfig, func(record []byte) error {
+		if err := validator.validate(record); err != nil {
+			if !e.CollectOnly {
+				return fmt.Errorf("blocks %d-%d: %w", blockFrom, blockTo, err)
+			}
+			problems++
+			logger.Warn("[bor snapshots] Invalid event record", "blockFrom", blockFrom, "blockTo", blockTo, "err", err)
+		}
+		return collect(record)
+	}, workers, lvl, logger, hashResolver)
+	if problems > 0 {
+		logger.Warn("[bor snapshots] Extracted invalid event records", "blockFrom", blockFrom, "blockTo", blockTo,
+			"invalid", problems)
+	}
+	return lastEventId, err
+}
+
+// eventRecordValidator checks each record against the previous one.
+type eventRecordValidator struct {
+	started                   bool
+	lastEventId, lastBlockNum uint64
+}
+
+func (v *eventRecordValidator) validate(record []byte) error {
+	blockNum, eventId, err := decodeEventBlockNumAndId(record)
+	if err != nil {
+		return fmt.Errorf("%w: after event %d: %w", ErrInvalidEventRecord, v.lastEventId, err)
+	}
+	// the state is updated whatever the outcome, so that an event out of place is reported alone when collecting
+	started, lastEventId, lastBlockNum := v.started, v.lastEventId, v.lastBlockNum
+	v.started, v.lastEventId, v.lastBlockNum = true, eventId, blockNum
+	if started {
+		if eventId != lastEventId+1 {
+			return fmt.Errorf("%w: event %d follows event %d", ErrInvalidEventRecord, eventId, lastEventId)
+		}
+		if blockNum < lastBlockNum {
+			return fmt.Errorf("%w: event %d of block %d follows block %d", ErrInvalidEventRecord, eventId, blockNum,
+				lastBlockNum)
+		}
+	}
+
+	payload := record[eventPayloadOffset:]
+	// the payload is the commitState call: a method id, then the abi encoded time and record
+	if len(payload) < 4+32 {
+		return fmt.Errorf("%w: event %d: payload of %d bytes", ErrInvalidEventRecord, eventId, len(payload))
+	}
+	var event heimdall.EventRecordWithTime
+	if err := event.UnmarshallBytes(payload); err != nil {
+		return fmt.Errorf("%w: event %d: %w", ErrInvalidEventRecord, eventId, err)
+	}
+	if event.ID != eventId {
+		return fmt.Errorf("%w: event %d: payload of event %d", ErrInvalidEventRecord, eventId, event.ID)
+	}
+	return nil
+}
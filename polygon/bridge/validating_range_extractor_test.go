@@ -0,0 +1,148 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package bridge
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/snaptype"
+	"github.com/erigontech/erigon-lib/testlog"
+	bortypes "github.com/erigontech/erigon/polygon/bor/types"
+	"github.com/erigontech/erigon/polygon/heimdall"
+)
+
+// eventRecords are the snapshot records of the events 1 to n, two of each sprint block.
+func eventRecords(t *testing.T, n int) [][]byte {
+	records := make([][]byte, n)
+	for i := range records {
+		eventId, blockNum := uint64(i+1), uint64(i/2+1)*16
+		event := heimdall.EventRecordWithTime{
+			EventRecord: heimdall.EventRecord{ID: eventId, ChainID: "80002"},
+			Time:        time.Unix(int64(eventId), 0),
+		}
+		payload, err := event.MarshallBytes()
+		require.NoError(t, err)
+		txnHash := bortypes.ComputeBorTxHash(blockNum, common.Hash{})
+		record := make([]byte, eventPayloadOffset, eventPayloadOffset+len(payload))
+		copy(record, txnHash[:])
+		binary.BigEndian.PutUint64(record[eventBlockNumOffset:], blockNum)
+		binary.BigEndian.PutUint64(record[eventIdOffset:], eventId)
+		records[i] = append(record, payload...)
+	}
+	return records
+}
+
+// sliceExtractor extracts the records, in their order.
+func sliceExtractor(records [][]byte) snaptype.RangeExtractor {
+	return snaptype.RangeExtractorFunc(func(ctx context.Context, blockFrom, blockTo uint64, firstKey snaptype.FirstKeyGetter, db kv.RoDB, chainConfig *chain.Config, collect func([]byte) error, workers int, lvl log.Lvl, logger log.Logger, hashResolver snaptype.BlockHashResolver) (uint64, error) {
+		var lastEventId uint64
+		for _, record := range records {
+			if err := collect(record); err != nil {
+				return 0, err
+			}
+			lastEventId, _ = decodeEventId(record)
+		}
+		return lastEventId, nil
+	})
+}
+
+func extract(t *testing.T, e snaptype.RangeExtractor) (collected int, err error) {
+	_, err = e.Extract(context.Background(), 0, 500_000, nil, nil, nil, func([]byte) error {
+		collected++
+		return nil
+	}, 1, log.LvlDebug, testlog.Logger(t, log.LvlDebug), nil)
+	return collected, err
+}
+
+func TestValidatingRangeExtractor(t *testing.T) {
+	records := eventRecords(t, 50)
+
+	collected, err := extract(t, ValidatingRangeExtractor{Base: sliceExtractor(records)})
+	require.NoError(t, err)
+	require.Equal(t, len(records), collected)
+
+	shuffled := append([][]byte(nil), records...)
+	rand.New(rand.NewPCG(1, 2)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	// the first event out of place is the one named
+	var misplaced, previous uint64
+	for i := 1; i < len(shuffled); i++ {
+		previous, _ = decodeEventId(shuffled[i-1])
+		misplaced, _ = decodeEventId(shuffled[i])
+		if misplaced != previous+1 {
+			break
+		}
+	}
+	require.NotEqual(t, previous+1, misplaced)
+
+	t.Run("abort", func(t *testing.T) {
+		collected, err := extract(t, ValidatingRangeExtractor{Base: sliceExtractor(shuffled)})
+		require.ErrorIs(t, err, ErrInvalidEventRecord)
+		require.ErrorContains(t, err, fmt.Sprintf("event %d follows event %d", misplaced, previous))
+		require.Less(t, collected, len(shuffled))
+	})
+
+	t.Run("collect only", func(t *testing.T) {
+		collected, err := extract(t, ValidatingRangeExtractor{Base: sliceExtractor(shuffled), CollectOnly: true})
+		require.NoError(t, err)
+		require.Equal(t, len(shuffled), collected)
+	})
+
+	t.Run("undecodable payload", func(t *testing.T) {
+		broken := append([][]byte(nil), records...)
+		broken[7] = append(common.Copy(records[7][:eventPayloadOffset]), 0xc0)
+		_, err := extract(t, ValidatingRangeExtractor{Base: sliceExtractor(broken)})
+		require.ErrorIs(t, err, ErrInvalidEventRecord)
+		require.ErrorContains(t, err, "event 8:")
+	})
+
+	t.Run("block going back", func(t *testing.T) {
+		broken := append([][]byte(nil), records...)
+		broken[7] = common.Copy(records[7])
+		binary.BigEndian.PutUint64(broken[7][eventBlockNumOffset:], 16)
+		_, err := extract(t, ValidatingRangeExtractor{Base: sliceExtractor(broken)})
+		require.ErrorIs(t, err, ErrInvalidEventRecord)
+		require.ErrorContains(t, err, "event 8 of block 16")
+	})
+}
+
+func TestSnapshotStoreRangeExtractorWrapper(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlDebug)
+
+	// the default is the extractor of the base store, unwrapped
+	store := NewSnapshotStore(NewMdbxStore(t.TempDir(), logger, false, 1), nil, nil)
+	require.IsType(t, heimdall.EventRangeExtractor{}, store.RangeExtractor())
+
+	store = NewSnapshotStore(NewMdbxStore(t.TempDir(), logger, false, 1), nil, nil,
+		WithRangeExtractorWrapper(ValidateRangeExtractor(false)))
+	validating, ok := store.RangeExtractor().(ValidatingRangeExtractor)
+	require.True(t, ok)
+	require.IsType(t, heimdall.EventRangeExtractor{}, validating.Base)
+	require.False(t, validating.CollectOnly)
+}
@@ -41,6 +41,10 @@ type ServiceConfig struct {
 	BorConfig *borcfg.BorConfig
 	Client    Client
 	Logger    log.Logger
+	// TipLagSampleInterval is the interval of the samples of the lag behind Heimdall, 0 disabling them.
+	TipLagSampleInterval time.Duration
+	// EventsTip is the last event stored by the bridge, for the events lag, which isn't sampled if it's nil.
+	EventsTip EventsTip
 }
 
 type Service struct {
@@ -52,6 +56,7 @@ type Service struct {
 	spanScraper               *Scraper[*Span]
 	spanBlockProducersTracker *spanBlockProducersTracker
 	client                    Client
+	tipLagSampler             *tipLagSampler
 	ready                     ready
 }
 
@@ -97,6 +102,12 @@ func NewService(config ServiceConfig) *Service {
 		logger,
 	)
 
+	var lagSampler *tipLagSampler
+	if config.TipLagSampleInterval > 0 {
+		lagSampler = newTipLagSampler(client, config.EventsTip, store.Spans(), store.Checkpoints(),
+			config.TipLagSampleInterval, logger)
+	}
+
 	return &Service{
 		logger:                    logger,
 		store:                     store,
@@ -106,6 +117,7 @@ func NewService(config ServiceConfig) *Service {
 		spanScraper:               spanScraper,
 		spanBlockProducersTracker: newSpanBlockProducersTracker(logger, borConfig, store.SpanBlockProducerSelections()),
 		client:                    client,
+		tipLagSampler:             lagSampler,
 	}
 }
 
@@ -366,6 +378,15 @@ func (s *Service) Run(ctx context.Context) error {
 
 		return nil
 	})
+	if s.tipLagSampler != nil {
+		eg.Go(func() error {
+			if err := s.tipLagSampler.Run(ctx); err != nil {
+				return fmt.Errorf("tip lag sampler failed: %w", err)
+			}
+
+			return nil
+		})
+	}
 	return eg.Wait()
 }
 
@@ -176,6 +176,11 @@ func (s *SpanSnapshotStore) Entity(ctx context.Context, id uint64) (*Span, bool,
 		return &span, true, nil
 	}
 
+	// the files may lack the span, e.g. while its index is being built, which the db has still until it's pruned
+	if span, ok, err := s.EntityStore.Entity(ctx, id); err != nil || ok {
+		return span, ok, err
+	}
+
 	return nil, false, fmt.Errorf("span %d: %w (snapshots)", id, ErrSpanNotFound)
 }
 
@@ -389,7 +394,8 @@ func (s *CheckpointSnapshotStore) Entity(ctx context.Context, id uint64) (*Check
 		sn := segments[i]
 		index := sn.Src().Index()
 
-		if index == nil || index.KeyCount() == 0 || id < index.BaseDataID() {
+		// the ids after the frozen ones aren't in the last segment either
+		if index == nil || index.KeyCount() == 0 || id < index.BaseDataID() || id >= index.BaseDataID()+index.KeyCount() {
 			continue
 		}
 
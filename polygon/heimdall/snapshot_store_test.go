@@ -0,0 +1,146 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package heimdall
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/seg"
+	"github.com/erigontech/erigon-lib/snaptype"
+	"github.com/erigontech/erigon-lib/testlog"
+	"github.com/erigontech/erigon-lib/version"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/polygon/bor/borcfg"
+	bortypes "github.com/erigontech/erigon/polygon/bor/types"
+	polychain "github.com/erigontech/erigon/polygon/chain"
+)
+
+// createSegment writes the words as the segment of snapType of [from, to), and indexes it.
+func createSegment(t *testing.T, dir string, snapType snaptype.Type, from, to uint64, words [][]byte) {
+	ctx, logger := context.Background(), log.New()
+	compressCfg := seg.DefaultCfg
+	compressCfg.MinPatternScore = 100
+	fileName := snaptype.SegmentFileName(version.V1_0, from, to, snapType.Enum())
+	c, err := seg.NewCompressor(ctx, "test", filepath.Join(dir, fileName), dir, compressCfg, log.LvlDebug, logger)
+	require.NoError(t, err)
+	defer c.Close()
+	c.DisableFsync()
+	for _, word := range words {
+		require.NoError(t, c.AddWord(word))
+	}
+	require.NoError(t, c.Compress())
+	info, _, ok := snaptype.ParseFileName(dir, fileName)
+	require.True(t, ok)
+	require.NoError(t, snapType.BuildIndexes(ctx, info, nil, nil, dir, nil, log.LvlDebug, logger))
+}
+
+func TestSpanSnapshotStoreFrozenOnly(t *testing.T) {
+	ctx, dir := context.Background(), t.TempDir()
+	logger := testlog.Logger(t, log.LvlCrit)
+	const spansDir, proposerSequencesDir = "testdata/amoy/spans", "testdata/amoy/getSnapshotProposerSequence"
+
+	// the spans of the blocks [0, 1_500_000) are frozen, the db has none of them
+	const segmentTo = 1_500_000
+	lastFrozenSpanId := uint64(SpanIdAt(segmentTo)) - 1
+	var spans [][]byte
+	for id := uint64(0); id <= lastFrozenSpanId; id++ {
+		span, err := os.ReadFile(fmt.Sprintf("%s/span_%d.json", spansDir, id))
+		require.NoError(t, err)
+		spans = append(spans, span)
+	}
+	createSegment(t, dir, Spans, 0, segmentTo, spans)
+	// the files of all the types are visible up to the same block
+	event := make([]byte, length.Hash+length.BlockNum+8, length.Hash+length.BlockNum+8+1)
+	txnHash := bortypes.ComputeBorTxHash(16, common.Hash{})
+	copy(event, txnHash[:])
+	binary.BigEndian.PutUint64(event[length.Hash:], 16)
+	binary.BigEndian.PutUint64(event[length.Hash+length.BlockNum:], 1)
+	createSegment(t, dir, Events, 0, segmentTo, [][]byte{append(event, 0xc0)})
+
+	snapshots := NewRoSnapshots(ethconfig.BlocksFreezing{ChainName: networkname.Amoy}, dir, 0, logger)
+	t.Cleanup(snapshots.Close)
+	require.NoError(t, snapshots.OpenFolder())
+	base := NewMdbxStore(logger, t.TempDir(), false, 1)
+	require.NoError(t, base.Prepare(ctx))
+	t.Cleanup(base.Close)
+	store := NewSnapshotStore(base, snapshots)
+
+	_, ok, err := base.Spans().LastEntityId(ctx)
+	require.NoError(t, err)
+	require.False(t, ok)
+	lastSpanId, ok, err := store.Spans().LastEntityId(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, lastFrozenSpanId, lastSpanId)
+
+	for _, id := range []uint64{0, 168, lastFrozenSpanId} {
+		span, ok, err := store.Spans().Entity(ctx, id)
+		require.NoError(t, err)
+		require.True(t, ok, id)
+		require.Equal(t, readEntityFromFile[Span](t, fmt.Sprintf("%s/span_%d.json", spansDir, id)), span, id)
+	}
+	_, ok, err = store.Spans().Entity(ctx, lastFrozenSpanId+1)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	spansFrom, err := store.Spans().RangeFromBlockNum(ctx, 1_069_056)
+	require.NoError(t, err)
+	require.NotEmpty(t, spansFrom)
+	require.Equal(t, SpanId(168), spansFrom[0].Id)
+	require.Equal(t, SpanId(lastFrozenSpanId), spansFrom[len(spansFrom)-1].Id)
+
+	// the producers of the blocks of the frozen spans resolve as they do with the spans in the db
+	borConfig := polychain.AmoyChainConfig.Bor.(*borcfg.BorConfig)
+	service := NewService(ServiceConfig{
+		Store:     store,
+		BorConfig: borConfig,
+		Client:    NewMockClient(gomock.NewController(t)),
+		Logger:    logger,
+	})
+	require.NoError(t, service.replayUntrackedSpans(ctx))
+	for _, blockNum := range []uint64{1, 255, 1_062_656, 1_069_056, 1_075_455} {
+		b, err := os.ReadFile(fmt.Sprintf("%s/blockNum_%d.json", proposerSequencesDir, blockNum))
+		require.NoError(t, err)
+		var proposerSequenceResponse getSnapshotProposerSequenceResponse
+		require.NoError(t, json.Unmarshal(b, &proposerSequenceResponse))
+		wantProducers := proposerSequenceResponse.Result
+
+		haveProducers, err := service.Producers(ctx, blockNum)
+		require.NoError(t, err)
+		require.Len(t, haveProducers.Validators, len(wantProducers.Signers), blockNum)
+		for _, signer := range wantProducers.Signers {
+			_, producer := haveProducers.GetByAddress(signer.Signer)
+			require.NotNil(t, producer, blockNum)
+			haveDifficulty, err := haveProducers.Difficulty(producer.Address)
+			require.NoError(t, err)
+			require.Equal(t, signer.Difficulty, haveDifficulty, blockNum)
+		}
+	}
+}
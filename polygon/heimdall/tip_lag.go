@@ -0,0 +1,174 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package heimdall
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+const (
+	DefaultTipLagSampleInterval = 30 * time.Second
+
+	// tipLagLogEvery is the least time between the logs of the failures to sample the lag, the others being debug ones.
+	tipLagLogEvery = 10 * time.Minute
+	// maxTipLagEvents bounds the events fetched to count the events lag, a larger lag being reported as it.
+	maxTipLagEvents = 10 * StateEventsFetchLimit
+)
+
+var (
+	tipLagEventsGauge      = metrics.GetOrCreateGauge("heimdall_tip_lag_events")
+	tipLagSpansGauge       = metrics.GetOrCreateGauge("heimdall_tip_lag_spans")
+	tipLagCheckpointsGauge = metrics.GetOrCreateGauge("heimdall_tip_lag_checkpoints")
+	// tipLagStaleGauge is 1 while the lags are the ones of a past sample, Heimdall being unreachable since.
+	tipLagStaleGauge = metrics.GetOrCreateGauge("heimdall_tip_lag_stale")
+	// tipLagSampledAtGauge is the unix time of the last sample.
+	tipLagSampledAtGauge = metrics.GetOrCreateGauge("heimdall_tip_lag_sampled_timestamp")
+)
+
+// EventsTip is the last event stored locally, by the bridge, compared with the last one of Heimdall.
+type EventsTip interface {
+	LastEventId(ctx context.Context) (uint64, error)
+}
+
+type entityIdTip interface {
+	LastEntityId(ctx context.Context) (uint64, bool, error)
+}
+
+type tipLag struct {
+	events      uint64
+	spans       uint64
+	checkpoints uint64
+}
+
+// tipLagSampler compares periodically the tip of Heimdall, its last event, span and checkpoint, with the ones stored
+// locally, and exports the lags. When Heimdall can't be reached the lags of the last sample are kept and marked stale.
+type tipLagSampler struct {
+	client            Client
+	events            EventsTip // nil if the events aren't sampled
+	spans             entityIdTip
+	checkpoints       entityIdTip
+	interval          time.Duration
+	logger            log.Logger
+	lastFailureLogged time.Time
+}
+
+func newTipLagSampler(client Client, events EventsTip, spans, checkpoints entityIdTip, interval time.Duration, logger log.Logger) *tipLagSampler {
+	return &tipLagSampler{
+		client:      client,
+		events:      events,
+		spans:       spans,
+		checkpoints: checkpoints,
+		interval:    interval,
+		logger:      logger,
+	}
+}
+
+func (s *tipLagSampler) Run(ctx context.Context) error {
+	// there's no sample yet
+	tipLagStaleGauge.SetUint64(1)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.update(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *tipLagSampler) update(ctx context.Context) {
+	sampleCtx, cancel := context.WithTimeout(ctx, s.interval)
+	defer cancel()
+
+	lag, err := s.sample(sampleCtx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+
+		tipLagStaleGauge.SetUint64(1)
+		if now := time.Now(); now.Sub(s.lastFailureLogged) >= tipLagLogEvery {
+			s.lastFailureLogged = now
+			s.logger.Warn(heimdallLogPrefix("can't sample the lag behind heimdall, marking it stale"), "err", err)
+		} else {
+			s.logger.Debug(heimdallLogPrefix("can't sample the lag behind heimdall"), "err", err)
+		}
+		return
+	}
+
+	if s.events != nil {
+		tipLagEventsGauge.SetUint64(lag.events)
+	}
+	tipLagSpansGauge.SetUint64(lag.spans)
+	tipLagCheckpointsGauge.SetUint64(lag.checkpoints)
+	tipLagSampledAtGauge.SetUint64(uint64(time.Now().Unix()))
+	tipLagStaleGauge.SetUint64(0)
+}
+
+func (s *tipLagSampler) sample(ctx context.Context) (tipLag, error) {
+	var lag tipLag
+
+	if s.events != nil {
+		lastEventId, err := s.events.LastEventId(ctx)
+		if err != nil {
+			return lag, fmt.Errorf("last event: %w", err)
+		}
+		events, err := s.client.FetchStateSyncEvents(ctx, lastEventId+1, time.Now(), maxTipLagEvents)
+		if err != nil {
+			return lag, fmt.Errorf("heimdall events from %d: %w", lastEventId+1, err)
+		}
+		lag.events = uint64(min(len(events), maxTipLagEvents))
+	}
+
+	latestSpan, err := s.client.FetchLatestSpan(ctx)
+	if err != nil {
+		return lag, fmt.Errorf("heimdall latest span: %w", err)
+	}
+	lastSpanId, ok, err := s.spans.LastEntityId(ctx)
+	if err != nil {
+		return lag, fmt.Errorf("last span: %w", err)
+	}
+	// the spans start at 0
+	lag.spans = uint64(latestSpan.Id) + 1
+	if ok {
+		lag.spans -= min(lastSpanId+1, lag.spans)
+	}
+
+	checkpointCount, err := s.client.FetchCheckpointCount(ctx)
+	if err != nil {
+		return lag, fmt.Errorf("heimdall checkpoint count: %w", err)
+	}
+	lastCheckpointId, _, err := s.checkpoints.LastEntityId(ctx)
+	if err != nil {
+		return lag, fmt.Errorf("last checkpoint: %w", err)
+	}
+	// the checkpoints start at 1
+	lag.checkpoints = uint64(max(checkpointCount, 0))
+	lag.checkpoints -= min(lastCheckpointId, lag.checkpoints)
+
+	return lag, nil
+}
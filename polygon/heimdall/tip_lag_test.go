@@ -0,0 +1,139 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package heimdall
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/testlog"
+)
+
+// stubHeimdall serves the events [1, lastEventId], the spans [0, lastSpanId] and checkpointCount checkpoints, failing
+// every request while down.
+type stubHeimdall struct {
+	lastEventId     atomic.Uint64
+	lastSpanId      atomic.Uint64
+	checkpointCount atomic.Int64
+	down            atomic.Bool
+}
+
+func (h *stubHeimdall) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.down.Load() {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var response any
+	switch r.URL.Path {
+	case "/clerk/event-record/list":
+		fromId, err := strconv.ParseUint(r.URL.Query().Get("from-id"), 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		limit, err := strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events := []*EventRecordWithTime{}
+		for id := fromId; id <= h.lastEventId.Load() && id < fromId+limit; id++ {
+			events = append(events, &EventRecordWithTime{EventRecord: EventRecord{ID: id}, Time: time.Unix(int64(id), 0)})
+		}
+		response = StateSyncEventsResponseV1{Result: events}
+	case "/bor/latest-span":
+		response = SpanResponseV1{Result: Span{Id: SpanId(h.lastSpanId.Load())}}
+	case "/checkpoints/count":
+		response = CheckpointCountResponseV1{Result: CheckpointCount{Result: h.checkpointCount.Load()}}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+type eventsTipStub uint64
+
+func (s eventsTipStub) LastEventId(context.Context) (uint64, error) {
+	return uint64(s), nil
+}
+
+type entityIdTipStub uint64
+
+func (s entityIdTipStub) LastEntityId(context.Context) (uint64, bool, error) {
+	return uint64(s), true, nil
+}
+
+func TestTipLagSampler(t *testing.T) {
+	ctx := context.Background()
+	logger := testlog.Logger(t, log.LvlDebug)
+
+	stub := &stubHeimdall{}
+	stub.lastEventId.Store(120)
+	stub.lastSpanId.Store(10)
+	stub.checkpointCount.Store(25)
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	client := NewHttpClient(server.URL, logger, WithHttpRetryBackOff(time.Millisecond), WithHttpMaxRetries(1))
+	t.Cleanup(client.Close)
+
+	sampler := newTipLagSampler(client, eventsTipStub(100), entityIdTipStub(7), entityIdTipStub(20), time.Second, logger)
+	tipLagStaleGauge.SetUint64(1)
+	sampler.update(ctx)
+	require.Equal(t, uint64(20), tipLagEventsGauge.GetValueUint64())
+	require.Equal(t, uint64(3), tipLagSpansGauge.GetValueUint64())
+	require.Equal(t, uint64(5), tipLagCheckpointsGauge.GetValueUint64())
+	require.Zero(t, tipLagStaleGauge.GetValueUint64())
+	sampledAt := tipLagSampledAtGauge.GetValueUint64()
+	require.InDelta(t, time.Now().Unix(), sampledAt, 5)
+
+	// heimdall unreachable: the lags of the last sample are kept, marked stale, rather than zeroed
+	stub.down.Store(true)
+	tipLagSampledAtGauge.SetUint64(sampledAt - 1)
+	sampler.update(ctx)
+	failureLogged := sampler.lastFailureLogged
+	require.False(t, failureLogged.IsZero())
+	// the failures following are logged at debug
+	sampler.update(ctx)
+	require.Equal(t, failureLogged, sampler.lastFailureLogged)
+	require.Equal(t, uint64(20), tipLagEventsGauge.GetValueUint64())
+	require.Equal(t, uint64(3), tipLagSpansGauge.GetValueUint64())
+	require.Equal(t, uint64(5), tipLagCheckpointsGauge.GetValueUint64())
+	require.Equal(t, uint64(1), tipLagStaleGauge.GetValueUint64())
+	require.Equal(t, sampledAt-1, tipLagSampledAtGauge.GetValueUint64())
+
+	// reachable again, the node having caught up, and heimdall gone far ahead in events
+	stub.down.Store(false)
+	stub.lastEventId.Store(100 + 2*maxTipLagEvents)
+	sampler.spans, sampler.checkpoints = entityIdTipStub(10), entityIdTipStub(25)
+	sampler.update(ctx)
+	require.Equal(t, uint64(maxTipLagEvents), tipLagEventsGauge.GetValueUint64())
+	require.Zero(t, tipLagSpansGauge.GetValueUint64())
+	require.Zero(t, tipLagCheckpointsGauge.GetValueUint64())
+	require.Zero(t, tipLagStaleGauge.GetValueUint64())
+	require.GreaterOrEqual(t, tipLagSampledAtGauge.GetValueUint64(), sampledAt)
+}
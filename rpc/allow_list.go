@@ -16,7 +16,10 @@
 
 package rpc
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"strings"
+)
 
 type AllowList map[string]struct{}
 
@@ -50,8 +53,35 @@ func (a *AllowList) MarshalJSON() ([]byte, error) {
 	return json.Marshal(keys)
 }
 
-type ForbiddenList map[string]struct{}
+// contains returns true if the method is in the list, or its namespace is: as "<namespace>_*".
+func (a AllowList) contains(method string) bool {
+	if _, ok := a[method]; ok {
+		return true
+	}
+	namespace, _, ok := strings.Cut(method, serviceMethodSeparator)
+	if !ok {
+		return false
+	}
+	_, ok = a[namespace+serviceMethodSeparator+"*"]
+	return ok
+}
+
+// AccessList is the method-by-method access to the methods of a server: the methods in Deny are never served, and if
+// Allow is not empty only the methods in it are. The denied methods are reported as not found, like the methods
+// which don't exist. The *_subscribe methods are only subject to Deny, so that the allow lists which don't list
+// them keep serving the subscriptions, as they did before the access list applied to them.
+type AccessList struct {
+	Allow AllowList `json:"allow"`
+	Deny  AllowList `json:"deny"`
+}
+
+func (l *AccessList) allowed(method string) bool {
+	if l.denied(method) {
+		return false
+	}
+	return l == nil || len(l.Allow) == 0 || l.Allow.contains(method)
+}
 
-func newForbiddenList() ForbiddenList {
-	return ForbiddenList{}
+func (l *AccessList) denied(method string) bool {
+	return l != nil && l.Deny.contains(method)
 }
@@ -17,11 +17,16 @@
 package rpc
 
 import (
+	"context"
 	"encoding/json"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/log/v3"
 )
 
 func TestAllowListMarshaling(t *testing.T) {
@@ -38,3 +43,90 @@ func TestAllowListUnmarshaling(t *testing.T) {
 	m := map[string]struct{}{"one": {}, "two": {}, "three": {}}
 	assert.Equal(t, allowList, AllowList(m))
 }
+
+func TestAccessListAllowed(t *testing.T) {
+	access := &AccessList{
+		Allow: AllowList{"eth_*": {}, "debug_traceTransaction": {}},
+		Deny:  AllowList{"eth_sendRawTransaction": {}},
+	}
+	assert.True(t, access.allowed("eth_call"))
+	assert.True(t, access.allowed("debug_traceTransaction"))
+	assert.False(t, access.allowed("eth_sendRawTransaction"))
+	assert.False(t, access.allowed("debug_setHead"))
+	assert.False(t, access.allowed("eth"))
+
+	assert.False(t, access.denied("eth_subscribe"))
+	assert.True(t, access.denied("eth_sendRawTransaction"))
+
+	access = &AccessList{Deny: AllowList{"debug_*": {}}}
+	assert.True(t, access.allowed("eth_call"))
+	assert.False(t, access.allowed("debug_traceTransaction"))
+
+	access = nil
+	assert.True(t, access.allowed("debug_setHead"))
+	assert.False(t, access.denied("debug_setHead"))
+}
+
+func TestSetAccessListWhileServing(t *testing.T) {
+	logger := log.New()
+	srv := newTestServer(logger)
+	httpsrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, WebsocketCompression{}, logger))
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	ws, err := DialWebsocket(context.Background(), "ws:"+strings.TrimPrefix(httpsrv.URL, "http:"), "", logger)
+	require.NoError(t, err)
+	defer ws.Close()
+	inproc := DialInProc(srv, logger)
+	defer inproc.Close()
+
+	callEcho := func(client *Client) error {
+		var result echoResult
+		return client.Call(&result, "test_echo", "x", 1)
+	}
+	// the denied methods can't be told from those which don't exist
+	var notFound Error
+	require.ErrorAs(t, inproc.Call(nil, "test_unknown"), &notFound)
+	requireNotFound := func(client *Client, method string) {
+		t.Helper()
+		var result echoResult
+		err := client.Call(&result, method, "x", 1)
+		var rpcErr Error
+		require.ErrorAs(t, err, &rpcErr)
+		require.Equal(t, notFound.ErrorCode(), rpcErr.ErrorCode())
+		require.Equal(t, strings.Replace(notFound.Error(), "test_unknown", method, 1), rpcErr.Error())
+	}
+
+	for _, client := range []*Client{ws, inproc} {
+		require.NoError(t, callEcho(client))
+	}
+
+	srv.SetAccessList(&AccessList{Deny: AllowList{"test_echo": {}}})
+	for _, client := range []*Client{ws, inproc} {
+		requireNotFound(client, "test_echo")
+		require.NoError(t, client.Call(nil, "test_noArgsRets"))
+	}
+
+	srv.SetAccessList(&AccessList{Allow: AllowList{"test_*": {}}, Deny: AllowList{"test_noArgsRets": {}}})
+	for _, client := range []*Client{ws, inproc} {
+		require.NoError(t, callEcho(client))
+		requireNotFound(client, "test_noArgsRets")
+		// the subscriptions are only subject to the deny list
+		sub, err := client.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 0, 1)
+		require.NoError(t, err)
+		sub.Unsubscribe()
+	}
+
+	srv.SetAccessList(&AccessList{Deny: AllowList{"nftest_*": {}}})
+	for _, client := range []*Client{ws, inproc} {
+		_, err := client.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 0, 1)
+		require.Error(t, err)
+		require.NoError(t, callEcho(client))
+	}
+
+	srv.SetAccessList(nil)
+	for _, client := range []*Client{ws, inproc} {
+		require.NoError(t, callEcho(client))
+		require.NoError(t, client.Call(nil, "test_noArgsRets"))
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/common/hexutil"
+)
+
+// batchGasMethods are the methods whose gas counts towards the gas limit of a batch.
+var batchGasMethods = map[string]struct{}{
+	"eth_call":             {},
+	"eth_estimateGas":      {},
+	"eth_createAccessList": {},
+	"debug_traceCall":      {},
+	"trace_call":           {},
+}
+
+// batchLimits bounds the batches served by a server. The items of a batch beyond its limits are answered with a
+// batchLimitError, the others are executed by the workers shared by all the connections: the items wait for a
+// worker in arrival order, so the items of concurrent batches interleave.
+type batchLimits struct {
+	maxItems int           // items of a batch, unlimited if 0
+	maxGas   uint64        // cumulative gas of the call-type items of a batch, unlimited if 0
+	callGas  uint64        // gas counted for the call-type items not setting it
+	workers  chan struct{} // slots of the workers executing the items
+
+	scope        RequestScope // scope shared by the items of a batch, or by the calls of a connection, nil if none
+	scopePerConn bool         // whether scope is begun per connection rather than per batch
+
+	labelMethods bool // whether the calls are labelled with their method in their context, see MethodFromContext
+}
+
+// RequestScope begins a scope shared by several calls, like the read transaction of the database they observe. It
+// returns the context of the calls and the function ending the scope once they are done.
+type RequestScope func(ctx context.Context) (context.Context, func())
+
+func newBatchLimits(workers uint) *batchLimits {
+	return &batchLimits{workers: make(chan struct{}, workers)}
+}
+
+// exceeded returns the error answering each of the given items of a batch beyond the limits, nil for the others.
+func (b *batchLimits) exceeded(msgs []*jsonrpcMessage) []error {
+	var errs []error
+	var gas uint64
+	var gasExceeded bool
+	for i, msg := range msgs {
+		var err error
+		if b.maxItems > 0 && i >= b.maxItems {
+			err = &batchLimitError{fmt.Sprintf("batch limit %d exceeded (can increase by --rpc.batch.limit)", b.maxItems)}
+		} else if msgGas, ok := b.gas(msg); ok && b.maxGas > 0 {
+			gasExceeded = gasExceeded || gas+msgGas < gas || gas+msgGas > b.maxGas
+			if gasExceeded {
+				err = &batchLimitError{fmt.Sprintf("batch gas limit %d exceeded (can increase by --rpc.batch.gaslimit)", b.maxGas)}
+			} else {
+				gas += msgGas
+			}
+		}
+		if err != nil {
+			if errs == nil {
+				errs = make([]error, len(msgs))
+			}
+			errs[i] = err
+		}
+	}
+	return errs
+}
+
+// gas returns the gas of a call-type item of a batch, false for the other methods.
+func (b *batchLimits) gas(msg *jsonrpcMessage) (uint64, bool) {
+	if _, ok := batchGasMethods[msg.Method]; !ok {
+		return 0, false
+	}
+	var params []json.RawMessage
+	if err := json.Unmarshal(msg.Params, &params); err != nil || len(params) == 0 {
+		return b.callGas, true
+	}
+	var call struct {
+		Gas *hexutil.Uint64 `json:"gas"`
+	}
+	if err := json.Unmarshal(params[0], &call); err != nil || call.Gas == nil {
+		return b.callGas, true
+	}
+	return uint64(*call.Gas), true
+}
@@ -2,9 +2,12 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/erigontech/erigon-lib/log/v3"
 )
@@ -18,7 +21,7 @@ func TestBatchLimit_WebSocket_Exceeded(t *testing.T) {
 	srv.SetBatchLimit(10) // Set limit to 10
 
 	// Start HTTP server with WebSocket support
-	httpsrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, false, logger))
+	httpsrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, WebsocketCompression{}, logger))
 	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
 	defer srv.Stop()
 	defer httpsrv.Close()
@@ -30,34 +33,231 @@ func TestBatchLimit_WebSocket_Exceeded(t *testing.T) {
 	}
 	defer client.Close()
 
-	// Create batch exceeding limit (20 > 10)
-	var batch []BatchElem
-	for i := 0; i < 20; i++ {
-		batch = append(batch, BatchElem{
+	requireBatchLimited(t, client, 20, 10)
+
+	// The connection stays usable
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "test", 1); err != nil {
+		t.Fatalf("call after the limited batch failed: %v", err)
+	}
+}
+
+func TestBatchLimit_HTTP_Exceeded(t *testing.T) {
+	t.Parallel()
+	logger := log.New()
+
+	srv := newTestServer(logger)
+	srv.SetBatchLimit(10)
+	httpsrv := httptest.NewServer(srv)
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	client, err := DialHTTP(httpsrv.URL, logger)
+	if err != nil {
+		t.Fatalf("failed to dial http: %v", err)
+	}
+	defer client.Close()
+
+	requireBatchLimited(t, client, 20, 10)
+}
+
+// requireBatchLimited sends a batch of size echo calls and checks that the ones beyond limit, and only them, are
+// answered with a batch limit error.
+func requireBatchLimited(t *testing.T, client *Client, size, limit int) {
+	t.Helper()
+	batch := make([]BatchElem, size)
+	for i := range batch {
+		batch[i] = BatchElem{
 			Method: "test_echo",
-			Args:   []interface{}{"hello"},
+			Args:   []interface{}{"hello", i},
 			Result: new(echoResult),
-		})
+		}
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	for i, elem := range batch {
+		if i < limit {
+			if elem.Error != nil {
+				t.Fatalf("item %d failed: %v", i, elem.Error)
+			}
+			if result := elem.Result.(*echoResult); result.String != "hello" || result.Int != i {
+				t.Fatalf("item %d: wrong result %+v", i, result)
+			}
+			continue
+		}
+		var rpcErr Error
+		if !errors.As(elem.Error, &rpcErr) || rpcErr.ErrorCode() != -32005 {
+			t.Fatalf("item %d: expected a batch limit error, got %v", i, elem.Error)
+		}
+		if !strings.Contains(elem.Error.Error(), "batch limit 10 exceeded") {
+			t.Fatalf("item %d: wrong error %v", i, elem.Error)
+		}
+	}
+}
+
+func TestBatchGasLimit(t *testing.T) {
+	t.Parallel()
+
+	limits := newBatchLimits(1)
+	limits.maxGas = 100
+	limits.callGas = 60
+	call := func(method, params string) *jsonrpcMessage {
+		return &jsonrpcMessage{Version: vsn, ID: []byte("1"), Method: method, Params: []byte(params)}
+	}
+	msgs := []*jsonrpcMessage{
+		call("eth_call", `[{"gas":"0x1e"},"latest"]`), // 30
+		call("eth_blockNumber", `[]`),                 // not counted
+		call("eth_estimateGas", `[{}]`),               // callGas: 90
+		call("eth_call", `[{"gas":"0x14"},"latest"]`), // 110, exceeded
+		call("eth_call", `[{"gas":"0x1"},"latest"]`),  // exceeded, as the ones after it
+		call("eth_chainId", `[]`),                     // not counted
+	}
+	errs := limits.exceeded(msgs)
+	for i, want := range []bool{false, false, false, true, true, false} {
+		if exceeded := errs[i] != nil; exceeded != want {
+			t.Fatalf("item %d: exceeded %t, want %t", i, exceeded, want)
+		}
+	}
+	if code := errs[3].(Error).ErrorCode(); code != -32005 {
+		t.Fatalf("wrong error code %d", code)
+	}
+
+	limits.maxGas = 0
+	if errs := limits.exceeded(msgs); errs != nil {
+		t.Fatalf("unexpected errors without limit: %v", errs)
+	}
+}
+
+// This test checks that a large batch doesn't starve the requests of the other connections.
+func TestBatchInterleaving(t *testing.T) {
+	t.Parallel()
+	logger := log.New()
+
+	srv := NewServer(1, false /* traceRequests */, false /* debugSingleRequests */, true, logger, 100)
+	if err := srv.RegisterName("test", new(testService)); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(srv)
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	dial := func() *Client {
+		client, err := DialHTTP(httpsrv.URL, logger)
+		if err != nil {
+			t.Fatalf("failed to dial http: %v", err)
+		}
+		t.Cleanup(client.Close)
+		return client
+	}
+	sleeps := func(n int, d time.Duration) []BatchElem {
+		batch := make([]BatchElem, n)
+		for i := range batch {
+			batch[i] = BatchElem{Method: "test_sleep", Args: []interface{}{d}, Result: new(interface{})}
+		}
+		return batch
 	}
 
-	// Send batch request
-	err = client.BatchCall(batch)
+	// the large batch takes a second with its single worker
+	var largeDone atomic.Bool
+	largeErr := make(chan error, 1)
+	go func() {
+		largeErr <- dial().BatchCall(sleeps(50, 20*time.Millisecond))
+		largeDone.Store(true)
+	}()
+	time.Sleep(100 * time.Millisecond)
 
-	// With the current implementation, conn.close is called on batch limit exceeded
-	// This should result in a connection error (websocket close)
-	if err == nil {
-		t.Fatal("expected connection error due to batch limit exceeded, got nil")
+	small := sleeps(2, time.Millisecond)
+	if err := dial().BatchCall(small); err != nil {
+		t.Fatal(err)
+	}
+	for _, elem := range small {
+		if elem.Error != nil {
+			t.Fatal(elem.Error)
+		}
+	}
+	var result echoResult
+	if err := dial().Call(&result, "test_echo", "single", 1); err != nil {
+		t.Fatal(err)
+	}
+	if largeDone.Load() {
+		t.Fatal("the requests waited for the large batch")
 	}
+	if err := <-largeErr; err != nil {
+		t.Fatal(err)
+	}
+}
+
+type requestScopeKey struct{}
 
-	// The error should be a websocket close error or EOF
-	// The specific batch limit error message is not propagated through the websocket close
-	if !strings.Contains(err.Error(), "websocket:") && !strings.Contains(err.Error(), "EOF") {
-		t.Fatalf("expected websocket close error, got: %v", err)
+// scopeService reports the request scope of the calls, and the highest number of calls executed concurrently.
+type scopeService struct {
+	inflight, maxInflight atomic.Int32
+}
+
+func (s *scopeService) Scope(ctx context.Context) int {
+	n := s.inflight.Add(1)
+	defer s.inflight.Add(-1)
+	for m := s.maxInflight.Load(); n > m && !s.maxInflight.CompareAndSwap(m, n); m = s.maxInflight.Load() {
 	}
+	time.Sleep(5 * time.Millisecond)
+	return ctx.Value(requestScopeKey{}).(int)
+}
+
+func TestRequestScope(t *testing.T) {
+	t.Parallel()
+	for _, perConnection := range []bool{false, true} {
+		logger := log.New()
+		var begun, ended atomic.Int32
+		srv := NewServer(4, false /* traceRequests */, false /* debugSingleRequests */, true, logger, 100)
+		srv.SetRequestScope(func(ctx context.Context) (context.Context, func()) {
+			return context.WithValue(ctx, requestScopeKey{}, int(begun.Add(1))), func() { ended.Add(1) }
+		}, perConnection)
+		service := new(scopeService)
+		if err := srv.RegisterName("test", service); err != nil {
+			t.Fatal(err)
+		}
+		httpsrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, WebsocketCompression{}, logger))
+		client, err := DialWebsocket(context.Background(), "ws:"+strings.TrimPrefix(httpsrv.URL, "http:"), "", logger)
+		if err != nil {
+			t.Fatalf("failed to dial websocket: %v", err)
+		}
+
+		var scopes []int
+		for range 2 {
+			batch := make([]BatchElem, 3)
+			for i := range batch {
+				batch[i] = BatchElem{Method: "test_scope", Result: new(int)}
+			}
+			if err := client.BatchCall(batch); err != nil {
+				t.Fatal(err)
+			}
+			for _, elem := range batch {
+				if elem.Error != nil {
+					t.Fatal(elem.Error)
+				}
+				if scope := *elem.Result.(*int); scope != *batch[0].Result.(*int) {
+					t.Fatalf("per connection %t: items of a batch in scopes %d and %d", perConnection, *batch[0].Result.(*int), scope)
+				}
+			}
+			scopes = append(scopes, *batch[0].Result.(*int))
+		}
+		if sameScope := scopes[0] == scopes[1]; sameScope != perConnection {
+			t.Fatalf("per connection %t: batches in scopes %v", perConnection, scopes)
+		}
+		if n := service.maxInflight.Load(); n != 1 {
+			t.Fatalf("per connection %t: %d items executed concurrently", perConnection, n)
+		}
 
-	// Verify the connection is closed by trying another request
-	err2 := client.Call(nil, "test_echo", "test")
-	if err2 == nil {
-		t.Fatal("expected error on closed connection, got nil")
+		client.Close()
+		httpsrv.Close()
+		srv.Stop()
+		deadline := time.Now().Add(5 * time.Second)
+		for ended.Load() != begun.Load() {
+			if time.Now().After(deadline) {
+				t.Fatalf("per connection %t: %d scopes begun, %d ended", perConnection, begun.Load(), ended.Load())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
 	}
 }
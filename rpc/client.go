@@ -77,11 +77,15 @@ type BatchElem struct {
 
 // Client represents a connection to an RPC server.
 type Client struct {
-	idgen           func() ID // for subscriptions
-	isHTTP          bool
-	services        *serviceRegistry
-	methodAllowList AllowList
-	batchLimit      int // batch size limit
+	idgen       func() ID // for subscriptions
+	isHTTP      bool
+	services    *serviceRegistry
+	access      *atomic.Pointer[AccessList] // nil if every method is allowed
+	batchLimits *batchLimits
+
+	// statistics and slow log threshold of a connection served by a Server
+	stats            *connStats
+	slowLogThreshold time.Duration
 
 	idCounter uint32
 
@@ -103,9 +107,18 @@ type Client struct {
 	reqInit     chan *requestOp  // register response IDs, takes write lock
 	reqSent     chan error       // signals write completion, releases write lock
 	reqTimeout  chan *requestOp  // removes response IDs when call timeout expires
+	drainReq    chan drainOp     // drains the server subscriptions of the connection
 	logger      log.Logger
 }
 
+// drainOp asks dispatch to drain the server subscriptions of the connection until ctx is done, see
+// handler.drainServerSubscriptions. done is closed once drained.
+type drainOp struct {
+	ctx  context.Context
+	err  error
+	done chan struct{}
+}
+
 type reconnectFunc func(ctx context.Context) (ServerCodec, error)
 
 type clientContextKey struct{}
@@ -118,7 +131,8 @@ type clientConn struct {
 func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	ctx := context.WithValue(context.Background(), clientContextKey{}, c)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
-	handler := newHandler(ctx, conn, c.idgen, c.services, c.methodAllowList, 50, false /* traceRequests */, c.logger, 0)
+	handler := newHandler(ctx, conn, c.idgen, c.services, c.access, c.batchLimits, false /* traceRequests */, c.logger, c.slowLogThreshold)
+	handler.stats = c.stats
 	return &clientConn{conn, handler}
 }
 
@@ -203,18 +217,19 @@ func newClient(initctx context.Context, connect reconnectFunc, logger log.Logger
 	if err != nil {
 		return nil, err
 	}
-	c := initClient(conn, randomIDGenerator(), &serviceRegistry{logger: logger}, 0, logger)
+	c := initClient(conn, randomIDGenerator(), &serviceRegistry{logger: logger}, nil, newBatchLimits(50), nil, 0, logger)
 	c.reconnectFunc = connect
 	return c, nil
 }
 
-func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, batchLimit int, logger log.Logger) *Client {
+func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, access *atomic.Pointer[AccessList], batchLimits *batchLimits, stats *connStats, slowLogThreshold time.Duration, logger log.Logger) *Client {
 	_, isHTTP := conn.(*httpConn)
 	c := &Client{
 		idgen:       idgen,
 		isHTTP:      isHTTP,
 		services:    services,
-		batchLimit:  batchLimit,
+		access:      access,
+		batchLimits: batchLimits,
 		writeConn:   conn,
 		close:       make(chan struct{}),
 		closing:     make(chan struct{}),
@@ -225,7 +240,11 @@ func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, ba
 		reqInit:     make(chan *requestOp),
 		reqSent:     make(chan error, 1),
 		reqTimeout:  make(chan *requestOp),
+		drainReq:    make(chan drainOp),
 		logger:      logger,
+
+		stats:            stats,
+		slowLogThreshold: slowLogThreshold,
 	}
 	if !isHTTP {
 		go c.dispatch(conn)
@@ -268,6 +287,19 @@ func (c *Client) Close() {
 	}
 }
 
+// drain drains the server subscriptions of the connection until ctx is done, closing the remaining ones with err.
+func (c *Client) drain(ctx context.Context, err error) {
+	if c.isHTTP {
+		return
+	}
+	op := drainOp{ctx: ctx, err: err, done: make(chan struct{})}
+	select {
+	case c.drainReq <- op:
+		<-op.done
+	case <-c.didClose:
+	}
+}
+
 // SetHeader adds a custom HTTP header to the client's requests.
 // This method only works for clients using HTTP, it doesn't have
 // any effect for clients using another transport.
@@ -567,20 +599,6 @@ func (c *Client) dispatch(codec ServerCodec) {
 		// Read path:
 		case op := <-c.readOp:
 			if op.batch {
-				if c.batchLimit > 0 && len(op.msgs) > c.batchLimit {
-					batchErr := &invalidRequestError{
-						fmt.Sprintf("batch limit %d exceeded (can increase by --rpc.batch.limit). Requested: %d",
-							c.batchLimit, len(op.msgs)),
-					}
-					// Log the error
-					conn.handler.logger.Warn("[rpc] batch limit exceeded", "limit", c.batchLimit, "requested", len(op.msgs))
-					// Send error response
-					errMsg := errorMessage(batchErr)
-					_ = conn.codec.WriteJSON(context.Background(), errMsg)
-					// Then close the connection
-					conn.close(batchErr, lastOp)
-					continue
-				}
 				conn.handler.handleBatch(op.msgs)
 			} else {
 				conn.handler.handleMsg(op.msgs[0], nil)
@@ -629,6 +647,10 @@ func (c *Client) dispatch(codec ServerCodec) {
 
 		case op := <-c.reqTimeout:
 			conn.handler.removeRequestOp(op)
+
+		case op := <-c.drainReq:
+			conn.handler.drainServerSubscriptions(op.ctx, op.err)
+			close(op.done)
 		}
 	}
 }
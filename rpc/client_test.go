@@ -537,7 +537,7 @@ func TestClientReconnect(t *testing.T) {
 		if err != nil {
 			t.Fatal("can't listen:", err)
 		}
-		go http.Serve(l, srv.WebsocketHandler([]string{"*"}, nil, false, logger))
+		go http.Serve(l, srv.WebsocketHandler([]string{"*"}, nil, WebsocketCompression{}, logger))
 		return srv, l
 	}
 
@@ -603,7 +603,7 @@ func httpTestClient(srv *Server, transport string, fl *flakeyListener) (*Client,
 	var hs *httptest.Server
 	switch transport {
 	case "ws":
-		hs = httptest.NewUnstartedServer(srv.WebsocketHandler([]string{"*"}, nil, false, logger))
+		hs = httptest.NewUnstartedServer(srv.WebsocketHandler([]string{"*"}, nil, WebsocketCompression{}, logger))
 	case "http":
 		hs = httptest.NewUnstartedServer(srv)
 	default:
@@ -54,7 +54,7 @@ func (b DirectBackend) CallContract(ctx context.Context, callMsg ethereum.CallMs
 	blockNumberOrHash := BlockNumArg(blockNum)
 	var blockNumberOrHashRef *rpc.BlockNumberOrHash = &blockNumberOrHash
 
-	return b.api.Call(ctx, CallArgsFromCallMsg(callMsg), blockNumberOrHashRef, nil)
+	return b.api.Call(ctx, CallArgsFromCallMsg(callMsg), blockNumberOrHashRef, nil, nil)
 }
 
 func (b DirectBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
@@ -81,7 +81,7 @@ func (b DirectBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 
 func (b DirectBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
 	callArgs := CallArgsFromCallMsg(call)
-	gas, err := b.api.EstimateGas(ctx, &callArgs, nil, nil)
+	gas, err := b.api.EstimateGas(ctx, &callArgs, nil, nil, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -129,6 +129,9 @@ func (b DirectBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.F
 				close(closec)
 				return nil
 			case res := <-resc:
+				if err, ok := res.(error); ok { // closed by the server
+					return err
+				}
 				log, ok := res.(*types.Log)
 				if !ok {
 					return fmt.Errorf("unexpected type %T in SubscribeFilterLogs", res)
@@ -70,6 +70,13 @@ func (e *invalidMessageError) ErrorCode() int { return -32700 }
 
 func (e *invalidMessageError) Error() string { return e.message }
 
+// batch item beyond the limits of its batch
+type batchLimitError struct{ message string }
+
+func (e *batchLimitError) ErrorCode() int { return -32005 }
+
+func (e *batchLimitError) Error() string { return e.message }
+
 // unable to decode supplied params, or invalid parameters
 type InvalidParamsError struct{ Message string }
 
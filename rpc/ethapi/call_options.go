@@ -0,0 +1,82 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon/rpc"
+)
+
+// JSON error codes of the calls which didn't complete, telling a timeout from running out of gas.
+const (
+	ErrCodeTimeout  = -32002
+	ErrCodeOutOfGas = -32015
+)
+
+// CallOptions are the limits of a single eth_call or eth_estimateGas, lowering those of the server: --rpc.gascap and
+// --rpc.evmtimeout.
+type CallOptions struct {
+	GasCap  *hexutil.Uint64 `json:"gasCap"`
+	Timeout *string         `json:"timeout"` // e.g. "500ms", see time.ParseDuration
+}
+
+// Limits returns the gas cap and the timeout of the call, those of the options clamped to the given maxima of the
+// server, 0 meaning unlimited.
+func (o *CallOptions) Limits(maxGasCap uint64, maxTimeout time.Duration) (gasCap uint64, timeout time.Duration, err error) {
+	gasCap, timeout = maxGasCap, maxTimeout
+	if o == nil {
+		return gasCap, timeout, nil
+	}
+	if o.GasCap != nil && *o.GasCap > 0 && (gasCap == 0 || uint64(*o.GasCap) < gasCap) {
+		gasCap = uint64(*o.GasCap)
+	}
+	if o.Timeout != nil {
+		requested, err := time.ParseDuration(*o.Timeout)
+		if err != nil || requested <= 0 {
+			return 0, 0, &rpc.InvalidParamsError{Message: fmt.Sprintf("invalid call timeout %q", *o.Timeout)}
+		}
+		if timeout == 0 || requested < timeout {
+			timeout = requested
+		}
+	}
+	return gasCap, timeout, nil
+}
+
+// TimeoutError is the error of a call aborted once its timeout elapsed.
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("execution aborted (timeout = %v)", e.Timeout)
+}
+
+func (e *TimeoutError) ErrorCode() int { return ErrCodeTimeout }
+
+// OutOfGasError is the error of a call running out of the gas it was given.
+type OutOfGasError struct {
+	Err error
+}
+
+func (e *OutOfGasError) Error() string { return e.Err.Error() }
+
+func (e *OutOfGasError) Unwrap() error { return e.Err }
+
+func (e *OutOfGasError) ErrorCode() int { return ErrCodeOutOfGas }
@@ -29,6 +29,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/erigontech/erigon-lib/jsonstream"
@@ -70,17 +71,22 @@ type handler struct {
 	logger         log.Logger
 	allowSubscribe bool
 
-	allowList     AllowList // a list of explicitly allowed methods, if empty -- everything is allowed
-	forbiddenList ForbiddenList
+	access *atomic.Pointer[AccessList] // the methods allowed and denied, nil if every method is allowed
 
-	subLock             sync.Mutex
-	serverSubs          map[ID]*Subscription
-	maxBatchConcurrency uint
-	traceRequests       bool
+	subLock       sync.Mutex
+	serverSubs    map[ID]*Subscription
+	draining      bool // set by drainServerSubscriptions, new subscriptions are refused
+	batchLimits   *batchLimits
+	traceRequests bool
 
 	//slow requests
 	slowLogThreshold time.Duration
 	slowLogBlacklist []string
+
+	stats    *connStats      // statistics of the connection, nil if it isn't tracked
+	streamed *countingWriter // bytes written to the stream of an HTTP request, nil for the other connections
+
+	endScope func() // ends the request scope of the connection, nil if the scope isn't per connection
 }
 
 type callProc struct {
@@ -119,10 +125,12 @@ func HandleError(err error, stream jsonstream.Stream) {
 	}
 }
 
-func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, allowList AllowList, maxBatchConcurrency uint, traceRequests bool, logger log.Logger, rpcSlowLogThreshold time.Duration) *handler {
+func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, access *atomic.Pointer[AccessList], batchLimits *batchLimits, traceRequests bool, logger log.Logger, rpcSlowLogThreshold time.Duration) *handler {
 	rootCtx, cancelRoot := context.WithCancel(connCtx)
-	forbiddenList := newForbiddenList()
-
+	var endScope func()
+	if batchLimits.scope != nil && batchLimits.scopePerConn {
+		rootCtx, endScope = batchLimits.scope(rootCtx)
+	}
 	h := &handler{
 		reg:            reg,
 		idgen:          idgen,
@@ -134,14 +142,15 @@ func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *
 		allowSubscribe: true,
 		serverSubs:     make(map[ID]*Subscription),
 		logger:         logger,
-		allowList:      allowList,
-		forbiddenList:  forbiddenList,
+		access:         access,
 
-		maxBatchConcurrency: maxBatchConcurrency,
-		traceRequests:       traceRequests,
+		batchLimits:   batchLimits,
+		traceRequests: traceRequests,
 
 		slowLogThreshold: rpcSlowLogThreshold,
 		slowLogBlacklist: rpccfg.SlowLogBlackList,
+
+		endScope: endScope,
 	}
 
 	if conn.remoteAddr() != "" {
@@ -176,21 +185,37 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	if len(calls) == 0 {
 		return
 	}
+	exceeded := h.batchLimits.exceeded(calls)
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
+		// The items sharing a scope are executed one after another.
+		sequential := h.batchLimits.scope != nil
+		if sequential && !h.batchLimits.scopePerConn {
+			var endScope func()
+			cp.ctx, endScope = h.batchLimits.scope(cp.ctx)
+			defer endScope()
+		}
 		// All goroutines will place results right to this array. Because requests order must match reply orders.
-		answersWithNils := make([]interface{}, len(msgs))
-		// Bounded parallelism pattern explanation https://blog.golang.org/pipelines#TOC_9.
-		boundedConcurrency := make(chan struct{}, h.maxBatchConcurrency)
-		defer close(boundedConcurrency)
+		answersWithNils := make([]interface{}, len(calls))
 		wg := sync.WaitGroup{}
-		wg.Add(len(msgs))
 		for i := range calls {
-			boundedConcurrency <- struct{}{}
-			go func(i int) {
+			if exceeded != nil && exceeded[i] != nil {
+				if !calls[i].isNotification() {
+					answersWithNils[i] = calls[i].errorResponse(exceeded[i])
+				}
+				continue
+			}
+			// The workers are shared by all the connections, so that a large batch doesn't starve the others.
+			select {
+			case h.batchLimits.workers <- struct{}{}:
+			case <-cp.ctx.Done():
+				continue
+			}
+			wg.Add(1)
+			call := func(i int) {
 				defer func() {
 					wg.Done()
-					<-boundedConcurrency
+					<-h.batchLimits.workers
 				}()
 
 				select {
@@ -202,16 +227,24 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 				buf := bytes.NewBuffer(nil)
 				stream := jsonstream.New(buf)
 				if res := h.handleCallMsg(cp, calls[i], stream); res != nil {
-					answersWithNils[i] = res
+					answer, _ := json.Marshal(res)
+					answersWithNils[i] = json.RawMessage(answer)
+					h.observeResponse(calls[i], len(answer))
 				}
 				_ = stream.Flush()
 				if buf.Len() > 0 && answersWithNils[i] == nil {
 					answersWithNils[i] = json.RawMessage(buf.Bytes())
+					h.observeResponse(calls[i], buf.Len())
 				}
-			}(i)
+			}
+			if sequential {
+				call(i)
+			} else {
+				go call(i)
+			}
 		}
 		wg.Wait()
-		answers := make([]interface{}, 0, len(msgs))
+		answers := make([]interface{}, 0, len(calls))
 		for _, answer := range answersWithNils {
 			if answer != nil {
 				answers = append(answers, answer)
@@ -245,9 +278,13 @@ func (h *handler) handleMsg(msg *jsonrpcMessage, stream jsonstream.Stream) {
 			stream.Write(buffer)
 		}
 		if needWriteStream {
+			h.observeResponse(msg, len(stream.Buffer()))
 			h.conn.WriteJSON(cp.ctx, json.RawMessage(stream.Buffer()))
 		} else {
 			stream.Write([]byte("\n"))
+			if h.streamed != nil {
+				h.observeResponse(msg, h.streamed.n)
+			}
 		}
 		for _, n := range cp.notifiers {
 			n.activate()
@@ -262,6 +299,9 @@ func (h *handler) close(err error, inflightReq *requestOp) {
 	h.callWG.Wait()
 	h.cancelRoot()
 	h.cancelServerSubscriptions(err)
+	if h.endScope != nil {
+		h.endScope()
+	}
 }
 
 // addRequestOp registers a request operation.
@@ -324,6 +364,47 @@ func (h *handler) cancelServerSubscriptions(err error) {
 	}
 }
 
+// isDraining returns true once drainServerSubscriptions was called.
+func (h *handler) isDraining() bool {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+	return h.draining
+}
+
+// drainServerSubscriptions refuses new subscriptions and waits until ctx is done for the owners of the server
+// subscriptions to end them, which lets them send their pending notifications first. The subscriptions still
+// active when ctx is done are closed with err as their last notification.
+func (h *handler) drainServerSubscriptions(ctx context.Context, err error) {
+	h.subLock.Lock()
+	h.draining = true
+	h.subLock.Unlock()
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		h.subLock.Lock()
+		subs := make([]*Subscription, 0, len(h.serverSubs))
+		for _, s := range h.serverSubs {
+			subs = append(subs, s)
+		}
+		h.subLock.Unlock()
+		if len(subs) == 0 {
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			for _, s := range subs {
+				if s.notifier != nil {
+					s.notifier.CloseWithError(s.ID, err) //nolint:errcheck
+				}
+			}
+			return
+		}
+	}
+}
+
 // startCallProc runs fn in a new goroutine and starts tracking it in the h.calls wait group.
 func (h *handler) startCallProc(fn func(*callProc)) {
 	h.callWG.Add(1)
@@ -360,9 +441,17 @@ func (h *handler) handleSubscriptionResult(msg *jsonrpcMessage) {
 		h.logger.Trace("Dropping invalid subscription message")
 		return
 	}
-	if h.clientSubs[result.ID] != nil {
-		h.clientSubs[result.ID].deliver(result.Result)
+	sub := h.clientSubs[result.ID]
+	if sub == nil {
+		return
+	}
+	if result.Error != nil {
+		// the server closed the subscription
+		delete(h.clientSubs, result.ID)
+		sub.quitWithError(false, result.Error)
+		return
 	}
+	sub.deliver(result.Result)
 }
 
 // handleResponse processes method call responses.
@@ -423,7 +512,9 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage, stream jsons
 		if doSlowLog {
 			requestDuration := time.Since(start)
 			if requestDuration > h.slowLogThreshold {
-				h.logger.Info("[rpc.slow] finished", "method", msg.Method, "reqid", idForLog(msg.ID), "duration", requestDuration)
+				h.logger.Info("[rpc.slow] finished", "method", msg.Method, "reqid", idForLog(msg.ID), "duration", requestDuration,
+					"paramsSize", len(msg.Params), "remoteAddr", h.conn.remoteAddr())
+				slowQueries.add(msg.Method, len(msg.Params), requestDuration, h.conn.remoteAddr())
 			}
 		}
 
@@ -449,18 +540,25 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage, stream jsons
 }
 
 func (h *handler) isMethodAllowedByGranularControl(method string) bool {
-	_, isForbidden := h.forbiddenList[method]
-	if len(h.allowList) == 0 {
-		return !isForbidden
+	if h.access == nil {
+		return true
 	}
+	return h.access.Load().allowed(method)
+}
 
-	_, ok := h.allowList[method]
-	return ok
+func (h *handler) isSubscriptionDeniedByGranularControl(method string) bool {
+	if h.access == nil {
+		return false
+	}
+	return h.access.Load().denied(method)
 }
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage, stream jsonstream.Stream) *jsonrpcMessage {
 	if msg.isSubscribe() {
+		if h.isSubscriptionDeniedByGranularControl(msg.Method) {
+			return msg.errorResponse(&methodNotFoundError{method: msg.Method})
+		}
 		return h.handleSubscribe(cp, msg, stream)
 	}
 	var callb *callback
@@ -477,7 +575,9 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage, stream jsonstrea
 		return msg.errorResponse(&InvalidParamsError{err.Error()})
 	}
 	start := time.Now()
+	h.stats.begin(len(msg.Params))
 	answer := h.runMethod(cp.ctx, msg, callb, args, stream)
+	h.stats.end()
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -487,15 +587,30 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage, stream jsonstrea
 			failedReqeustGauge.Inc()
 		}
 		newRPCServingTimerMS(msg.Method, answer == nil || answer.Error == nil).ObserveDuration(start)
+		m := getMethodMetrics(msg.Method)
+		m.requests.Inc()
+		m.duration.ObserveDuration(start)
+		m.bytesIn.AddInt(len(msg.Params))
 	}
 	return answer
 }
 
+// observeResponse counts the size of the response to msg, in the metrics of its method if it is a registered one.
+func (h *handler) observeResponse(msg *jsonrpcMessage, size int) {
+	h.stats.addBytesOut(size)
+	if msg.isCall() && !msg.isSubscribe() && !msg.isUnsubscribe() && h.reg.callback(msg.Method) != nil {
+		getMethodMetrics(msg.Method).bytesOut.AddInt(size)
+	}
+}
+
 // handleSubscribe processes *_subscribe method calls.
 func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage, stream jsonstream.Stream) *jsonrpcMessage {
 	if !h.allowSubscribe {
 		return msg.errorResponse(ErrNotificationsUnsupported)
 	}
+	if h.isDraining() {
+		return msg.errorResponse(ErrServerShuttingDown)
+	}
 
 	// Subscription method name is first argument.
 	name, err := parseSubscriptionName(msg.Params)
@@ -526,6 +641,9 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage, stream json
 
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value, stream jsonstream.Stream) *jsonrpcMessage {
+	if h.batchLimits.labelMethods {
+		ctx = context.WithValue(ctx, methodContextKey{}, msg.Method)
+	}
 	if !callb.streamable {
 		result, err := callb.call(ctx, msg.Method, args, stream)
 		if err != nil {
@@ -38,7 +38,6 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 
 	"github.com/erigontech/erigon-lib/common"
-	"github.com/erigontech/erigon-lib/jsonstream"
 	"github.com/erigontech/erigon-lib/log/v3"
 
 	"github.com/erigontech/erigon-lib/common/dbg"
@@ -279,12 +278,12 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("content-type", contentType)
 	codec := newHTTPServerConn(r, w)
 	defer codec.Close()
-	var stream jsonstream.Stream
+	var out io.Writer
 	if !s.disableStreaming {
-		stream = jsonstream.New(w)
+		out = w
 	}
 
-	errorMsg := s.serveSingleRequest(ctx, codec, stream)
+	errorMsg := s.serveSingleRequest(ctx, codec, out)
 	if errorMsg != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		codec.WriteJSON(ctx, errorMsg)
@@ -47,6 +47,7 @@ var null = json.RawMessage("null")
 type subscriptionResult struct {
 	ID     string          `json:"subscription"`
 	Result json.RawMessage `json:"result,omitempty"`
+	Error  *jsonError      `json:"error,omitempty"` // set on the last notification of a subscription closed by the server
 }
 
 // A value of this type can a JSON-RPC request, notification, successful response or
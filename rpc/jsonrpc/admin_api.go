@@ -21,8 +21,11 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/erigontech/erigon-lib/common/debug"
 	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
+	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/p2p"
+	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/rpchelper"
 )
 
@@ -37,17 +40,22 @@ type AdminAPI interface {
 
 	// AddPeer requests connecting to a remote node.
 	AddPeer(ctx context.Context, url string) (bool, error)
+
+	// PeerEvents sends a notification each time a peer connects to or disconnects from one of the sentries.
+	PeerEvents(ctx context.Context) (*rpc.Subscription, error)
 }
 
 // AdminAPIImpl data structure to store things needed for admin_* commands.
 type AdminAPIImpl struct {
 	ethBackend rpchelper.ApiBackend
+	filters    *rpchelper.Filters
 }
 
 // NewAdminAPI returns AdminAPIImpl instance.
-func NewAdminAPI(eth rpchelper.ApiBackend) *AdminAPIImpl {
+func NewAdminAPI(eth rpchelper.ApiBackend, filters *rpchelper.Filters) *AdminAPIImpl {
 	return &AdminAPIImpl{
 		ethBackend: eth,
+		filters:    filters,
 	}
 }
 
@@ -78,3 +86,40 @@ func (api *AdminAPIImpl) AddPeer(ctx context.Context, url string) (bool, error)
 	}
 	return result.Success, nil
 }
+
+// PeerEvents implements admin_subscribe("peerEvents").
+func (api *AdminAPIImpl) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
+	if api.filters == nil {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	events, id := api.filters.SubscribePeerEvents(64)
+
+	go func() {
+		defer debug.LogPanic()
+		defer api.filters.UnsubscribePeerEvents(id)
+		for {
+			select {
+			case e, ok := <-events:
+				if e != nil {
+					if err := notifier.Notify(rpcSub.ID, e); err != nil {
+						log.Warn("[rpc] error while notifying subscription", "err", err)
+					}
+				}
+				if !ok {
+					endSubscription(notifier, rpcSub, api.filters.PeerEventsSubErr(id), "peer events")
+					return
+				}
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
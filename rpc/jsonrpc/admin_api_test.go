@@ -0,0 +1,74 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	"github.com/erigontech/erigon-lib/gointerfaces/typesproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cmd/rpcdaemon/rpcservices"
+	"github.com/erigontech/erigon/execution/builder"
+	"github.com/erigontech/erigon/execution/stages/mock"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/rpc/rpchelper"
+	"github.com/erigontech/erigon/turbo/privateapi"
+)
+
+func TestAdminSubscribePeerEvents(t *testing.T) {
+	m, require := mock.Mock(t), require.New(t)
+	peerID := [64]byte{1, 2, 3}
+	m.PeerInfos = map[[64]byte]*typesproto.PeerInfo{
+		peerID: {Id: hex.EncodeToString(peerID[:]), Name: "erigon/v3.1.0", Enode: "enode://0102@127.0.0.1:30303", Caps: []string{"eth/68"}},
+	}
+
+	logger := log.New()
+	backendServer := privateapi.NewEthBackendServer(m.Ctx, nil, m.DB, m.Notifications, m.BlockReader, logger, builder.NewLatestBlockBuiltStore(), nil, privateapi.SubscribeConfig{})
+	backend := rpcservices.NewRemoteBackend(direct.NewEthBackendClientDirect(backendServer), m.DB, m.BlockReader)
+	ff := rpchelper.New(m.Ctx, rpchelper.DefaultFiltersConfig, backend, nil, nil, func() {}, m.Log)
+	server := rpc.NewServer(50, false /* traceRequests */, false /* debugSingleRequests */, true, logger, 100)
+	require.NoError(server.RegisterName("admin", NewAdminAPI(backend, ff)))
+	client := rpc.DialInProc(server, logger)
+	defer client.Close()
+
+	events := make(chan json.RawMessage, 4)
+	sub, err := client.Subscribe(m.Ctx, "admin", events, "peerEvents")
+	require.NoError(err)
+	defer sub.Unsubscribe()
+	// the first subscription opens the stream: wait for it to be registered, otherwise we could miss the events
+	require.Eventually(m.Notifications.Events.HasPeerEventSubscriptions, 10*time.Second, 10*time.Millisecond)
+
+	require.NoError(m.SendPeerEvent(&sentry.PeerEvent{PeerId: gointerfaces.ConvertHashToH512(peerID), EventId: sentry.PeerEvent_Connect}))
+	require.JSONEq(`{
+		"type": "connect",
+		"id": "`+hex.EncodeToString(peerID[:])+`",
+		"enode": "enode://0102@127.0.0.1:30303",
+		"name": "erigon/v3.1.0",
+		"caps": ["eth/68"]
+	}`, string(<-events))
+
+	require.NoError(m.SendPeerEvent(&sentry.PeerEvent{PeerId: gointerfaces.ConvertHashToH512(peerID), EventId: sentry.PeerEvent_Disconnect}))
+	require.JSONEq(`{"type": "disconnect", "id": "`+hex.EncodeToString(peerID[:])+`"}`, string(<-events))
+}
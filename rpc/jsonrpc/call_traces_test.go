@@ -17,7 +17,10 @@
 package jsonrpc
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io"
 	"sync"
 	"testing"
 
@@ -34,6 +37,7 @@ import (
 	"github.com/erigontech/erigon/cmd/rpcdaemon/cli/httpcfg"
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/execution/stages/mock"
+	"github.com/erigontech/erigon/rpc"
 )
 
 func blockNumbersFromTraces(t *testing.T, b []byte) []int {
@@ -281,3 +285,126 @@ func TestFilterAddressIntersection(t *testing.T) {
 		require.Empty(t, blockNumbersFromTraces(t, stream.Buffer()))
 	})
 }
+
+// mockWithTransfers creates a chain of blocks with txns transfers each, mined by common.Address{1}.
+func mockWithTransfers(t *testing.T, blocks, txns int) *mock.MockSentry {
+	m := mock.Mock(t)
+	signer := types.LatestSignerForChainID(nil)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, blocks, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(common.Address{1})
+		for j := 0; j < txns; j++ {
+			txn, err := types.SignTx(types.NewTransaction(gen.TxNonce(m.Address), common.Address{2, byte(j)}, uint256.NewInt(1), 21_000, uint256.NewInt(1), nil), *signer, m.Key)
+			require.NoError(t, err)
+			gen.AddTx(txn)
+		}
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+	return m
+}
+
+// filterTraces returns the raw traces of trace_filter, the response streamed to out if not nil.
+func filterTraces(t *testing.T, api *TraceAPIImpl, req TraceFilterRequest, out io.Writer) []json.RawMessage {
+	t.Helper()
+	var buf bytes.Buffer
+	stream := jsonstream.New(&buf)
+	if out != nil {
+		stream = jsonstream.New(io.MultiWriter(&buf, out))
+	}
+	require.NoError(t, api.Filter(context.Background(), req, new(bool), nil, stream))
+	var traces []json.RawMessage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &traces))
+	return traces
+}
+
+// traceKey is what identifies a trace in the responses of trace_filter and trace_block.
+type traceKey struct {
+	BlockNumber         uint64  `json:"blockNumber"`
+	TransactionHash     *string `json:"transactionHash"`
+	TransactionPosition *uint64 `json:"transactionPosition"`
+	Type                string  `json:"type"`
+	TraceAddress        []int   `json:"traceAddress"`
+}
+
+func TestFilterStreamedMatchesBlocks(t *testing.T) {
+	m := mockWithTransfers(t, 5, 3)
+	api := NewTraceAPI(newBaseApiForTest(m), m.DB, &httpcfg.HttpCfg{})
+	fromBlock, toBlock := hexutil.Uint64(1), hexutil.Uint64(5)
+	req := TraceFilterRequest{FromBlock: &fromBlock, ToBlock: &toBlock}
+	streamed := filterTraces(t, api, req, io.Discard)
+
+	var want []traceKey
+	for blockNum := 1; blockNum <= 5; blockNum++ {
+		traces, err := api.Block(context.Background(), rpc.BlockNumber(blockNum), nil, nil)
+		require.NoError(t, err)
+		b, err := json.Marshal(traces)
+		require.NoError(t, err)
+		var keys []traceKey
+		require.NoError(t, json.Unmarshal(b, &keys))
+		want = append(want, keys...)
+	}
+	got := make([]traceKey, len(streamed))
+	for i, trace := range streamed {
+		require.NoError(t, json.Unmarshal(trace, &got[i]))
+	}
+	require.Len(t, got, 5*(3+1))
+	require.Equal(t, want, got)
+
+	// after and count page the same traces
+	for _, page := range []struct{ after, count uint64 }{{0, 3}, {3, 5}, {14, 10}, {20, 1}} {
+		req := TraceFilterRequest{FromBlock: &fromBlock, ToBlock: &toBlock, After: &page.after, Count: &page.count}
+		end := min(page.after+page.count, uint64(len(streamed)))
+		wantPage := streamed[min(page.after, end):end]
+		if len(wantPage) == 0 {
+			wantPage = nil
+		}
+		require.Equal(t, wantPage, filterTraces(t, api, req, nil), "after %d count %d", page.after, page.count)
+	}
+}
+
+// maxWriteWriter records the largest write, the buffered response flushed at once.
+type maxWriteWriter struct {
+	maxWrite, total int
+}
+
+func (w *maxWriteWriter) Write(p []byte) (int, error) {
+	w.maxWrite = max(w.maxWrite, len(p))
+	w.total += len(p)
+	return len(p), nil
+}
+
+func TestFilterStreamsBoundedMemory(t *testing.T) {
+	m := mockWithTransfers(t, 100, 5)
+	api := NewTraceAPI(newBaseApiForTest(m), m.DB, &httpcfg.HttpCfg{})
+	fromBlock, toBlock := hexutil.Uint64(1), hexutil.Uint64(100)
+
+	out := &maxWriteWriter{}
+	traces := filterTraces(t, api, TraceFilterRequest{FromBlock: &fromBlock, ToBlock: &toBlock}, out)
+	require.Len(t, traces, 100*(5+1))
+	// the response is several times the size of the buffer, which never held more than a trace past the flush size
+	require.Greater(t, out.total, 3*traceFilterFlushSize)
+	require.Less(t, out.maxWrite, traceFilterFlushSize+4096)
+}
+
+func TestFilterMaxBytes(t *testing.T) {
+	m := mockWithTransfers(t, 5, 3)
+	api := NewTraceAPI(newBaseApiForTest(m), m.DB, &httpcfg.HttpCfg{TraceFilterMaxBytes: 2000})
+	fromBlock, toBlock := hexutil.Uint64(1), hexutil.Uint64(5)
+
+	traces := filterTraces(t, api, TraceFilterRequest{FromBlock: &fromBlock, ToBlock: &toBlock}, nil)
+	require.Greater(t, len(traces), 1)
+	require.Less(t, len(traces), 5*(3+1))
+	size := 0
+	for _, trace := range traces[:len(traces)-1] {
+		require.NotContains(t, string(trace), `"error"`)
+		size += len(trace)
+	}
+	require.LessOrEqual(t, size, 2000)
+	var last struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(traces[len(traces)-1], &last))
+	require.Contains(t, last.Error.Message, "trace_filter response exceeds 2000 bytes")
+}
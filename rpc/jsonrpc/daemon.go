@@ -26,6 +26,8 @@ import (
 	"github.com/erigontech/erigon/execution/consensus/clique"
 	"github.com/erigontech/erigon/polygon/bor"
 	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/rpc/jsonrpc/receipts"
+	"github.com/erigontech/erigon/rpc/rpccfg"
 	"github.com/erigontech/erigon/rpc/rpchelper"
 	"github.com/erigontech/erigon/turbo/services"
 )
@@ -34,10 +36,29 @@ import (
 func APIList(db kv.TemporalRoDB, eth rpchelper.ApiBackend, txPool txpool.TxpoolClient, mining txpool.MiningClient,
 	filters *rpchelper.Filters, stateCache kvcache.Cache,
 	blockReader services.FullBlockReader, cfg *httpcfg.HttpCfg, engine consensus.EngineReader,
-	logger log.Logger, bridgeReader bridgeReader, spanProducersReader spanProducersReader,
+	logger log.Logger, bridgeReader bridgeReader, spanProducersReader spanProducersReader, receiptsGenerator *receipts.Generator,
 ) (list []rpc.API) {
+	if cfg.RpcTxReuse != "" && cfg.RpcTxReuse != rpchelper.TxReuseOff {
+		db = rpchelper.NewScopedDB(db, cfg.RpcTxReuseMaxAge)
+	}
+	if cfg.RpcKvStats {
+		// outermost, the transaction lent by a scope being counted for the request borrowing it
+		db = rpchelper.NewKvStatsDB(db)
+	}
 	base := NewBaseApi(filters, stateCache, blockReader, cfg.WithDatadir, cfg.EvmCallTimeout, engine, cfg.Dirs, bridgeReader)
+	if receiptsGenerator != nil {
+		base.receiptsGenerator = receiptsGenerator
+	} else {
+		base.SetReceiptsCache(cfg.Sync.RPCReceiptsCache)
+	}
+	base.getLogsCfg = rpccfg.GetLogsConfig{
+		Workers:    int(cfg.RpcBatchConcurrency),
+		MaxResults: cfg.RpcFiltersConfig.RpcSubscriptionFiltersMaxLogs,
+	}
 	ethImpl := NewEthAPI(base, db, eth, txPool, mining, cfg.Gascap, cfg.Feecap, cfg.ReturnDataLimit, cfg.AllowUnprotectedTxs, cfg.MaxGetProofRewindBlockCount, cfg.WebsocketSubscribeLogsChannelSize, logger)
+	if filters != nil {
+		filters.SetChainReader(&filtersChainReader{api: ethImpl})
+	}
 	erigonImpl := NewErigonAPI(base, db, eth)
 	txpoolImpl := NewTxPoolAPI(base, db, txPool)
 	netImpl := NewNetAPIImpl(eth)
@@ -45,7 +66,7 @@ func APIList(db kv.TemporalRoDB, eth rpchelper.ApiBackend, txPool txpool.TxpoolC
 	traceImpl := NewTraceAPI(base, db, cfg)
 	web3Impl := NewWeb3APIImpl(eth)
 	dbImpl := NewDBAPIImpl() /* deprecated */
-	adminImpl := NewAdminAPI(eth)
+	adminImpl := NewAdminAPI(eth, filters)
 	parityImpl := NewParityAPIImpl(base, db)
 
 	var borImpl *BorImpl
@@ -86,6 +107,11 @@ func APIList(db kv.TemporalRoDB, eth rpchelper.ApiBackend, txPool txpool.TxpoolC
 				Public:    true,
 				Service:   EthAPI(ethImpl),
 				Version:   "1.0",
+			}, rpc.API{
+				Namespace: "eth",
+				Public:    true,
+				Service:   NewEthSyncingAPI(ethImpl),
+				Version:   "1.0",
 			})
 		case "debug":
 			list = append(list, rpc.API{
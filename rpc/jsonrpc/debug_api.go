@@ -32,10 +32,12 @@ import (
 	"github.com/erigontech/erigon-lib/kv/order"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon-lib/types/accounts"
 	"github.com/erigontech/erigon/core/state"
 	tracersConfig "github.com/erigontech/erigon/eth/tracers/config"
 	"github.com/erigontech/erigon/execution/stagedsync/stages"
+	"github.com/erigontech/erigon/p2p/protocols/eth"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/ethapi"
 	"github.com/erigontech/erigon/rpc/rpchelper"
@@ -62,7 +64,7 @@ type PrivateDebugAPI interface {
 	AccountAt(ctx context.Context, blockHash common.Hash, txIndex uint64, account common.Address) (*AccountResult, error)
 	GetRawHeader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error)
 	GetRawBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error)
-	GetRawReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]hexutil.Bytes, error)
+	GetRawReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, excludeStateSync *bool) ([]hexutil.Bytes, error)
 	GetBadBlocks(ctx context.Context) ([]map[string]interface{}, error)
 	GetRawTransaction(ctx context.Context, hash common.Hash) (hexutil.Bytes, error)
 	FreeOSMemory()
@@ -362,14 +364,14 @@ func (api *DebugAPIImpl) GetRawHeader(ctx context.Context, blockNrOrHash rpc.Blo
 	if err != nil {
 		return nil, err
 	}
-	header, err := api._blockReader.Header(ctx, tx, h, n)
+	header, err := api._blockReader.HeaderRLP(ctx, tx, h, n)
 	if err != nil {
 		return nil, err
 	}
 	if header == nil {
 		return nil, errors.New("header not found")
 	}
-	return rlp.EncodeToBytes(header)
+	return hexutil.Bytes(header), nil
 }
 
 // Implements debug_getRawBlock - Returns an RLP-encoded block
@@ -383,18 +385,32 @@ func (api *DebugAPIImpl) GetRawBlock(ctx context.Context, blockNrOrHash rpc.Bloc
 	if err != nil {
 		return nil, err
 	}
-	block, err := api.blockWithSenders(ctx, tx, h, n)
+	header, err := api._blockReader.HeaderRLP(ctx, tx, h, n)
 	if err != nil {
 		return nil, err
 	}
-	if block == nil {
+	body, err := api._blockReader.BodyRlp(ctx, tx, h, n)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil || body == nil {
 		return nil, errors.New("block not found")
 	}
-	return rlp.EncodeToBytes(block)
+	// the block is the list of the header followed by the fields of the body
+	bodyFields, _, err := rlp.SplitList(body)
+	if err != nil {
+		return nil, err
+	}
+	payloadSize := len(header) + len(bodyFields)
+	block := make([]byte, rlp.ListPrefixLen(payloadSize), rlp.ListPrefixLen(payloadSize)+payloadSize)
+	rlp.EncodeListPrefix(payloadSize, block)
+	block = append(append(block, header...), bodyFields...)
+	return block, nil
 }
 
-// GetRawReceipts implements debug_getRawReceipts - retrieves and returns an array of EIP-2718 binary-encoded receipts of a single block
-func (api *DebugAPIImpl) GetRawReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]hexutil.Bytes, error) {
+// GetRawReceipts implements debug_getRawReceipts - retrieves and returns an array of EIP-2718 binary-encoded receipts of a single block.
+// On bor the receipt of the state sync transactions comes last, unless excludeStateSync is set.
+func (api *DebugAPIImpl) GetRawReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, excludeStateSync *bool) ([]hexutil.Bytes, error) {
 	tx, err := api.db.BeginTemporalRo(ctx)
 	if err != nil {
 		return nil, err
@@ -420,7 +436,7 @@ func (api *DebugAPIImpl) GetRawReceipts(ctx context.Context, blockNrOrHash rpc.B
 	if err != nil {
 		return nil, err
 	}
-	if chainConfig.Bor != nil {
+	if chainConfig.Bor != nil && (excludeStateSync == nil || !*excludeStateSync) {
 		events, err := api.stateSyncEvents(ctx, tx, block.Hash(), blockNum, chainConfig)
 		if err != nil {
 			return nil, err
@@ -433,14 +449,23 @@ func (api *DebugAPIImpl) GetRawReceipts(ctx context.Context, blockNrOrHash rpc.B
 			receipts = append(receipts, borReceipt)
 		}
 	}
+	return rawReceipts(receipts)
+}
 
-	result := make([]hexutil.Bytes, len(receipts))
-	for i, receipt := range receipts {
-		b, err := receipt.MarshalBinary()
-		if err != nil {
-			return nil, err
-		}
-		result[i] = b
+// rawReceipts returns the consensus encodings of the receipts, taken from their encoding in the Receipts packet of
+// the eth protocol.
+func rawReceipts(receipts types.Receipts) ([]hexutil.Bytes, error) {
+	encoded, err := eth.EncodeReceipts(receipts)
+	if err != nil {
+		return nil, err
+	}
+	split, err := eth.SplitReceipts(encoded)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]hexutil.Bytes, len(split))
+	for i, receipt := range split {
+		result[i] = receipt
 	}
 	return result, nil
 }
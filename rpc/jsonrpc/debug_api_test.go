@@ -25,11 +25,14 @@ import (
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/holiman/uint256"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/require"
 
 	"github.com/erigontech/erigon-db/rawdb"
+	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon-lib/common/u256"
 	"github.com/erigontech/erigon-lib/crypto"
 	"github.com/erigontech/erigon-lib/jsonstream"
@@ -39,11 +42,14 @@ import (
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/kv/stream"
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/eth/ethconfig"
 	tracersConfig "github.com/erigontech/erigon/eth/tracers/config"
 	"github.com/erigontech/erigon/execution/chainspec"
+	"github.com/erigontech/erigon/execution/stages/mock"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/ethapi"
 	"github.com/erigontech/erigon/rpc/rpccfg"
@@ -636,3 +642,104 @@ func TestGetRawTransaction(t *testing.T) {
 	}
 	require.True(testedOnce, "Test flow didn't touch the target flow")
 }
+
+func TestGetRawBlockHeaderReceipts(t *testing.T) {
+	m := mock.MockWithGenesis(t, &types.Genesis{
+		Config: chain.TestChainConfig,
+		Alloc:  types.GenesisAlloc{testAddr: {Balance: big.NewInt(1000000)}},
+	}, testKey, false)
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	chainPack, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 3, func(i int, block *core.BlockGen) {
+		to := common.Address{0xcc}
+		legacy := types.NewTransaction(block.TxNonce(testAddr), to, uint256.NewInt(1), 21_000, uint256.NewInt(1), nil)
+		accessList := &types.AccessListTx{
+			LegacyTx: types.LegacyTx{
+				CommonTx: types.CommonTx{Nonce: block.TxNonce(testAddr) + 1, GasLimit: 30_000, To: &to, Value: uint256.NewInt(1)},
+				GasPrice: uint256.NewInt(1),
+			},
+			ChainID:    uint256.MustFromBig(m.ChainConfig.ChainID),
+			AccessList: types.AccessList{{Address: to, StorageKeys: []common.Hash{{0x01}}}},
+		}
+		txns := []types.Transaction{legacy, accessList}
+		if i == 0 {
+			txns = txns[:1]
+		}
+		for _, txn := range txns {
+			signed, err := types.SignTx(txn, *signer, testKey)
+			require.NoError(t, err)
+			block.AddTx(signed)
+		}
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chainPack))
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 5000000)
+
+	for i, block := range chainPack.Blocks {
+		require := require.New(t)
+		blockNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(block.NumberU64()))
+
+		wantHeader, err := rlp.EncodeToBytes(block.Header())
+		require.NoError(err)
+		header, err := api.GetRawHeader(m.Ctx, blockNrOrHash)
+		require.NoError(err)
+		require.Equal(hexutil.Bytes(wantHeader), header)
+
+		wantBlock, err := rlp.EncodeToBytes(block)
+		require.NoError(err)
+		raw, err := api.GetRawBlock(m.Ctx, rpc.BlockNumberOrHashWithHash(block.Hash(), true))
+		require.NoError(err)
+		require.Equal(hexutil.Bytes(wantBlock), raw)
+
+		wantReceipts := make([]hexutil.Bytes, len(chainPack.Receipts[i]))
+		for j := range chainPack.Receipts[i] {
+			var buf bytes.Buffer
+			chainPack.Receipts[i].EncodeIndex(j, &buf)
+			wantReceipts[j] = buf.Bytes()
+		}
+		receipts, err := api.GetRawReceipts(m.Ctx, blockNrOrHash, nil)
+		require.NoError(err)
+		require.Equal(wantReceipts, receipts)
+		require.Len(receipts, len(block.Transactions()))
+		if i > 0 {
+			require.Equal(byte(types.AccessListTxType), receipts[1][0])
+		}
+
+		// there are no state sync receipts to exclude off bor
+		excludeStateSync := true
+		receipts, err = api.GetRawReceipts(m.Ctx, blockNrOrHash, &excludeStateSync)
+		require.NoError(err)
+		require.Equal(wantReceipts, receipts)
+	}
+
+	_, err = api.GetRawBlock(m.Ctx, rpc.BlockNumberOrHashWithHash(common.Hash{0x01}, false))
+	require.Error(t, err)
+}
+
+func TestRawReceiptsStateSync(t *testing.T) {
+	// a bor block: a dynamic fee transaction followed by the state sync receipt, legacy typed, with the logs of the
+	// state receiver contract
+	stateSyncLog := &types.Log{
+		Address: common.HexToAddress("0x0000000000000000000000000000000000001001"),
+		Topics:  []common.Hash{common.HexToHash("0x5a22725590b0a51c923940223f7458512164b1113359a735e86e7f27f44791ee"), {0x01}},
+		Data:    []byte{0x02, 0x03},
+	}
+	receipts := types.Receipts{
+		{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21_000, Logs: []*types.Log{}},
+		{Type: types.LegacyTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21_000, Logs: []*types.Log{stateSyncLog}},
+	}
+	for _, r := range receipts {
+		r.Bloom = types.CreateBloom(types.Receipts{r})
+	}
+
+	for _, n := range []int{len(receipts), len(receipts) - 1} {
+		want := make([]hexutil.Bytes, n)
+		for i := range want {
+			var buf bytes.Buffer
+			receipts.EncodeIndex(i, &buf)
+			want[i] = buf.Bytes()
+		}
+		raw, err := rawReceipts(receipts[:n])
+		require.NoError(t, err)
+		require.Equal(t, want, raw)
+	}
+}
@@ -44,25 +44,22 @@ const GetLatestLogMaxBlockCount = 1000
 
 // GetLogsByHash implements erigon_getLogsByHash. Returns an array of arrays of logs generated by the transactions in the block given by the block's hash.
 func (api *ErigonImpl) GetLogsByHash(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
-	receipts, ok := api.getCachedReceipts(ctx, hash)
-	if !ok {
-		tx, err := api.db.BeginTemporalRo(ctx)
-		if err != nil {
-			return nil, err
-		}
-		defer tx.Rollback()
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-		block, err := api.blockByHashWithSenders(ctx, tx, hash)
-		if err != nil {
-			return nil, err
-		}
-		if block == nil {
-			return nil, nil
-		}
-		receipts, err = api.getReceipts(ctx, tx, block)
-		if err != nil {
-			return nil, fmt.Errorf("getReceipts error: %w", err)
-		}
+	block, err := api.blockByHashWithSenders(ctx, tx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+	receipts, err := api.getReceipts(ctx, tx, block)
+	if err != nil {
+		return nil, fmt.Errorf("getReceipts error: %w", err)
 	}
 	logs := make([][]*types.Log, len(receipts))
 	for i, receipt := range receipts {
@@ -123,7 +120,7 @@ func (api *ErigonImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria)
 		return nil, fmt.Errorf("end (%d) > MaxUint32", end)
 	}
 
-	return api.getLogsV3(ctx, tx, begin, end, crit)
+	return api.getLogsV3(ctx, api.db, tx, begin, end, crit)
 }
 
 // GetLatestLogs implements erigon_getLatestLogs.
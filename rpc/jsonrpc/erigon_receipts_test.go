@@ -19,8 +19,11 @@ package jsonrpc
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"math/big"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
@@ -39,7 +42,10 @@ import (
 	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/eth/filters"
 	"github.com/erigontech/erigon/execution/stages/mock"
+	"github.com/erigontech/erigon/p2p/protocols/eth"
 	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/rpc/jsonrpc/receipts"
+	"github.com/erigontech/erigon/rpc/rpccfg"
 )
 
 func TestGetLogs(t *testing.T) {
@@ -236,17 +242,244 @@ func TestGetBlockReceiptsByBlockHash(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGetBlockReceiptsCached(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+	blockNum := rpc.BlockNumberOrHashWithNumber(10)
+
+	tx, err := m.DB.BeginTemporalRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	block, err := m.BlockReader.BlockByNumber(m.Ctx, tx, 10)
+	require.NoError(t, err)
+	query := eth.GetReceiptsPacket{block.Hash()}
+
+	cached, needMore, err := eth.AnswerGetReceiptsQueryCacheOnly(m.Ctx, api.receiptsGenerator, query)
+	require.NoError(t, err)
+	require.True(t, needMore)
+	require.Empty(t, cached.EncodedReceipts)
+
+	generated, err := api.GetBlockReceipts(m.Ctx, blockNum)
+	require.NoError(t, err)
+	require.NotEmpty(t, generated)
+
+	// p2p serves the receipts generated for rpc out of the shared cache
+	cached, needMore, err = eth.AnswerGetReceiptsQueryCacheOnly(m.Ctx, api.receiptsGenerator, query)
+	require.NoError(t, err)
+	require.False(t, needMore)
+	require.Len(t, cached.EncodedReceipts, 1)
+
+	// and they are the ones p2p generates without the cache
+	uncached := receipts.NewGenerator(m.BlockReader, m.Engine, time.Minute, ethconfig.ReceiptsCache{}, "test")
+	encoded, err := eth.AnswerGetReceiptsQuery(m.Ctx, m.ChainConfig, uncached, m.BlockReader, tx, query, nil)
+	require.NoError(t, err)
+	require.Equal(t, encoded, cached.EncodedReceipts)
+
+	fromCache, err := api.GetBlockReceipts(m.Ctx, blockNum)
+	require.NoError(t, err)
+	a, err := json.Marshal(generated)
+	require.NoError(t, err)
+	b, err := json.Marshal(fromCache)
+	require.NoError(t, err)
+	require.JSONEq(t, string(a), string(b))
+}
+
+func BenchmarkGetBlockReceiptsHotBlock(b *testing.B) {
+	signer := types.LatestSignerForChainID(nil)
+	m := mockWithGenerator(b, 1, func(i int, block *core.BlockGen) {
+		for j := 0; j < 16; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testAddr), common.Address{0x1}, uint256.NewInt(1000), params.TxGas, nil, nil), *signer, testKey)
+			block.AddTx(tx)
+		}
+	})
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+	blockNum := rpc.BlockNumberOrHashWithNumber(1)
+	if _, err := api.GetBlockReceipts(m.Ctx, blockNum); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := api.GetBlockReceipts(m.Ctx, blockNum); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGetLogsMaxResults(t *testing.T) {
+	m := mockWithLogs(t, 8, 4)
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+	crit := filters.FilterCriteria{FromBlock: big.NewInt(1), ToBlock: big.NewInt(8)}
+
+	api.getLogsCfg = rpccfg.GetLogsConfig{Workers: 4}
+	logs, err := api.GetLogs(m.Ctx, crit)
+	require.NoError(t, err)
+	require.Len(t, logs, 8*4*logsPerTxn)
+	for i := 1; i < len(logs); i++ {
+		prev, cur := logs[i-1], logs[i]
+		require.True(t, prev.BlockNumber < cur.BlockNumber || (prev.BlockNumber == cur.BlockNumber && prev.Index < cur.Index))
+	}
+
+	api.getLogsCfg.MaxResults = 10
+	_, err = api.GetLogs(m.Ctx, crit)
+	require.ErrorContains(t, err, "query returned more than 10 results")
+
+	ctx, cancel := context.WithCancel(m.Ctx)
+	cancel()
+	_, err = api.GetLogs(ctx, crit)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// cancelWhen is a context canceled when its Err is checked once cond holds, e.g. in the middle of a query.
+type cancelWhen struct {
+	context.Context
+	cancel context.CancelFunc
+	cond   func() bool
+}
+
+func (c *cancelWhen) Err() error {
+	if c.cond() {
+		c.cancel()
+	}
+	return c.Context.Err()
+}
+
+func TestGetLogsCancel(t *testing.T) {
+	const blocks, txnsPerBlock = 64, 16
+	m := mockWithLogs(t, blocks, txnsPerBlock)
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+	api.getLogsCfg = rpccfg.GetLogsConfig{Workers: 4}
+	crit := filters.FilterCriteria{FromBlock: big.NewInt(1), ToBlock: big.NewInt(blocks)}
+
+	// every receipt of the query is cached once its transaction is processed
+	processed := func() int {
+		_, txns := api.receiptsGenerator.CacheStats()
+		return txns.Len
+	}
+	ctx, cancel := context.WithCancel(m.Ctx)
+	defer cancel()
+	_, err := api.GetLogs(&cancelWhen{Context: ctx, cancel: cancel, cond: func() bool { return processed() >= 2*txnsPerBlock }}, crit)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the workers were stopped long before the end of the range, and none of them is running once GetLogs returns
+	n := processed()
+	require.Less(t, n, blocks*txnsPerBlock/2)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, n, processed())
+}
+
+// This test checks that the bloom prefilter keeps every block with a log matching the query.
+func TestGetLogsBloomPrefilter(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+	latest, err := api.BlockNumber(m.Ctx)
+	require.NoError(t, err)
+	allLogs, err := api.GetLogs(m.Ctx, filters.FilterCriteria{FromBlock: big.NewInt(0), ToBlock: new(big.Int).SetUint64(uint64(latest))})
+	require.NoError(t, err)
+	require.NotEmpty(t, allLogs)
+
+	tx, err := m.DB.BeginTemporalRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	var queries, kept, falsePositives int
+	for _, l := range allLogs {
+		for _, crit := range []filters.FilterCriteria{
+			{Addresses: []common.Address{l.Address}},
+			{Topics: [][]common.Hash{l.Topics[:1]}},
+			{Addresses: []common.Address{l.Address}, Topics: [][]common.Hash{l.Topics[:1]}},
+		} {
+			// every block is a candidate, as when the indices can't narrow the range
+			blocks := make([]*logsBlock, latest+1)
+			for i := range blocks {
+				blocks[i] = &logsBlock{blockNum: uint64(i), txns: []logsCandidate{{}}}
+			}
+			blocks, err = api.bloomPrefilter(m.Ctx, tx, blocks, crit, false)
+			require.NoError(t, err)
+
+			matching := make(map[uint64]struct{})
+			for _, other := range allLogs {
+				if len(types.Logs{other}.Filter(addressSet(crit.Addresses), crit.Topics, 0)) > 0 {
+					matching[other.BlockNumber] = struct{}{}
+				}
+			}
+			for blockNum := range matching {
+				require.True(t, slices.ContainsFunc(blocks, func(b *logsBlock) bool { return b.blockNum == blockNum }), "block %d skipped", blockNum)
+			}
+			queries++
+			kept += len(blocks)
+			falsePositives += len(blocks) - len(matching)
+		}
+	}
+	require.Less(t, kept, queries*int(latest+1), "no block skipped")
+	t.Logf("%d queries over %d blocks: %d blocks kept, %d false positives", queries, latest+1, kept, falsePositives)
+}
+
+func addressSet(addresses []common.Address) map[common.Address]struct{} {
+	set := make(map[common.Address]struct{}, len(addresses))
+	for _, addr := range addresses {
+		set[addr] = struct{}{}
+	}
+	return set
+}
+
+func BenchmarkGetLogs(b *testing.B) {
+	m := mockWithLogs(b, 256, 16)
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+	crit := filters.FilterCriteria{FromBlock: big.NewInt(1), ToBlock: big.NewInt(256)}
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			api.getLogsCfg = rpccfg.GetLogsConfig{Workers: workers}
+			for i := 0; i < b.N; i++ {
+				if _, err := api.GetLogs(m.Ctx, crit); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+const logsPerTxn = 4
+
+// mockWithLogs creates a chain of blocks with txnsPerBlock calls each to a contract emitting logsPerTxn logs.
+func mockWithLogs(tb testing.TB, blocks, txnsPerBlock int) *mock.MockSentry {
+	// PUSH1 0x01 PUSH1 0x20 PUSH1 0x00 LOG1, logsPerTxn times
+	var code []byte
+	for i := 0; i < logsPerTxn; i++ {
+		code = append(code, 0x60, 0x01, 0x60, 0x20, 0x60, 0x00, 0xa1)
+	}
+	contract := common.Address{0xaa}
+	m := mock.MockWithGenesis(tb, &types.Genesis{
+		Config: chain.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			testAddr: {Balance: big.NewInt(1000000)},
+			contract: {Balance: big.NewInt(0), Code: code},
+		},
+	}, testKey, false)
+	signer := types.LatestSignerForChainID(nil)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, blocks, func(i int, block *core.BlockGen) {
+		for j := 0; j < txnsPerBlock; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testAddr), contract, uint256.NewInt(0), 100_000, nil, nil), *signer, testKey)
+			block.AddTx(tx)
+		}
+	})
+	require.NoError(tb, err)
+	require.NoError(tb, m.InsertChain(chain))
+	return m
+}
+
 // newTestBackend creates a chain with a number of explicitly defined blocks and
 // wraps it into a mock backend.
-func mockWithGenerator(t *testing.T, blocks int, generator func(int, *core.BlockGen)) *mock.MockSentry {
-	m := mock.MockWithGenesis(t, &types.Genesis{
+func mockWithGenerator(tb testing.TB, blocks int, generator func(int, *core.BlockGen)) *mock.MockSentry {
+	m := mock.MockWithGenesis(tb, &types.Genesis{
 		Config: chain.TestChainConfig,
 		Alloc:  types.GenesisAlloc{testAddr: {Balance: big.NewInt(1000000)}},
 	}, testKey, false)
 	if blocks > 0 {
 		chain, _ := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, blocks, generator)
 		err := m.InsertChain(chain)
-		require.NoError(t, err)
+		require.NoError(tb, err)
 	}
 	return m
 }
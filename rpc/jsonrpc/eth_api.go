@@ -42,14 +42,17 @@ import (
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon-lib/types/accounts"
 	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/eth/filters"
 	"github.com/erigontech/erigon/execution/consensus"
 	"github.com/erigontech/erigon/execution/consensus/misc"
 	"github.com/erigontech/erigon/polygon/bor/borcfg"
+	bortypes "github.com/erigontech/erigon/polygon/bor/types"
 	"github.com/erigontech/erigon/polygon/bridge"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/ethapi"
 	"github.com/erigontech/erigon/rpc/jsonrpc/receipts"
+	"github.com/erigontech/erigon/rpc/rpccfg"
 	"github.com/erigontech/erigon/rpc/rpchelper"
 	"github.com/erigontech/erigon/turbo/services"
 )
@@ -82,10 +85,11 @@ type EthAPI interface {
 	GetUncleCountByBlockHash(ctx context.Context, hash common.Hash) (*hexutil.Uint, error)
 
 	// Filter related (see ./eth_filters.go)
-	NewPendingTransactionFilter(_ context.Context) (string, error)
+	NewPendingTransactionFilter(_ context.Context, fullTx *bool) (string, error)
 	NewBlockFilter(_ context.Context) (string, error)
 	NewFilter(_ context.Context, crit filters.FilterCriteria) (string, error)
 	UninstallFilter(_ context.Context, index string) (bool, error)
+	KeepAliveFilter(_ context.Context, index string) (bool, error)
 	GetFilterChanges(_ context.Context, index string) ([]any, error)
 	GetFilterLogs(_ context.Context, index string) ([]*types.Log, error)
 	Logs(ctx context.Context, crit filters.FilterCriteria) (*rpc.Subscription, error)
@@ -106,8 +110,8 @@ type EthAPI interface {
 	Config(ctx context.Context, timeArg *hexutil.Uint64) (*EthConfigResp, error)
 
 	// Sending related (see ./eth_call.go)
-	Call(ctx context.Context, args ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi.StateOverrides) (hexutil.Bytes, error)
-	EstimateGas(ctx context.Context, argsOrNil *ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi.StateOverrides) (hexutil.Uint64, error)
+	Call(ctx context.Context, args ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi.StateOverrides, options *ethapi.CallOptions) (hexutil.Bytes, error)
+	EstimateGas(ctx context.Context, argsOrNil *ethapi.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi.StateOverrides, options *ethapi.CallOptions) (hexutil.Uint64, error)
 	SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error)
 	SendTransaction(_ context.Context, txObject interface{}) (common.Hash, error)
 	Sign(ctx context.Context, _ common.Address, _ hexutil.Bytes) (hexutil.Bytes, error)
@@ -146,6 +150,7 @@ type BaseAPI struct {
 	dirs                datadir.Dirs
 	receiptsGenerator   *receipts.Generator
 	borReceiptGenerator *receipts.BorGenerator
+	getLogsCfg          rpccfg.GetLogsConfig
 }
 
 func NewBaseApi(f *rpchelper.Filters, stateCache kvcache.Cache, blockReader services.FullBlockReader, singleNodeMode bool, evmCallTimeout time.Duration, engine consensus.EngineReader, dirs datadir.Dirs, bridgeReader bridgeReader) *BaseAPI {
@@ -170,7 +175,7 @@ func NewBaseApi(f *rpchelper.Filters, stateCache kvcache.Cache, blockReader serv
 		_txNumReader:        blockReader.TxnumReader(context.Background()),
 		evmCallTimeout:      evmCallTimeout,
 		_engine:             engine,
-		receiptsGenerator:   receipts.NewGenerator(blockReader, engine, evmCallTimeout),
+		receiptsGenerator:   receipts.NewGenerator(blockReader, engine, evmCallTimeout, ethconfig.ReceiptsCache{}, "rpc"),
 		borReceiptGenerator: receipts.NewBorGenerator(blockReader, engine),
 		dirs:                dirs,
 		useBridgeReader:     bridgeReader != nil && !reflect.ValueOf(bridgeReader).IsNil(), // needed for interface nil caveat
@@ -178,6 +183,11 @@ func NewBaseApi(f *rpchelper.Filters, stateCache kvcache.Cache, blockReader serv
 	}
 }
 
+// SetReceiptsCache replaces the receipts generator with one whose cache is configured by cfg.
+func (api *BaseAPI) SetReceiptsCache(cfg ethconfig.ReceiptsCache) {
+	api.receiptsGenerator = receipts.NewGenerator(api._blockReader, api._engine, api.evmCallTimeout, cfg, "rpc")
+}
+
 func (api *BaseAPI) chainConfig(ctx context.Context, tx kv.Tx) (*chain.Config, error) {
 	cfg, _, err := api.chainConfigWithGenesis(ctx, tx)
 	return cfg, err
@@ -364,6 +374,47 @@ func (api *BaseAPI) stateSyncEvents(ctx context.Context, tx kv.Tx, blockHash com
 	return stateSyncEvents, nil
 }
 
+// borStateSyncTxn returns the state-sync transaction of the block and its hash, nil if the block has no state-sync
+// events. The events are looked up in the bridge store, those frozen into snapshots included.
+func (api *BaseAPI) borStateSyncTxn(ctx context.Context, tx kv.Tx, blockHash common.Hash, blockNum uint64) (types.Transaction, common.Hash, error) {
+	txnHash := bortypes.ComputeBorTxHash(blockNum, blockHash)
+	var ok bool
+	var err error
+	if api.useBridgeReader {
+		_, ok, err = api.bridgeReader.EventTxnLookup(ctx, txnHash)
+	} else {
+		_, ok, err = api._blockReader.EventLookup(ctx, tx, txnHash)
+	}
+	if err != nil || !ok {
+		return nil, common.Hash{}, err
+	}
+	return bortypes.NewBorTransaction(), txnHash, nil
+}
+
+// borEventTxnsLookup resolves a batch of bor txn hashes to their blocks, the hashes of the blocks without state-sync
+// events being absent. It's false if the reader resolves them only one at a time, as the remote ones do.
+func (api *BaseAPI) borEventTxnsLookup(ctx context.Context, tx kv.Tx, txnHashes []common.Hash) (map[common.Hash]uint64, bool, error) {
+	if api.useBridgeReader {
+		batchReader, ok := api.bridgeReader.(interface {
+			EventTxnsLookup(context.Context, []common.Hash) (map[common.Hash]uint64, error)
+		})
+		if !ok {
+			return nil, false, nil
+		}
+		blockNums, err := batchReader.EventTxnsLookup(ctx, txnHashes)
+		return blockNums, err == nil, err
+	}
+
+	batchReader, ok := api._blockReader.(interface {
+		EventTxnsLookup(context.Context, kv.Tx, []common.Hash) (map[common.Hash]uint64, error)
+	})
+	if !ok {
+		return nil, false, nil
+	}
+	blockNums, err := batchReader.EventTxnsLookup(ctx, tx, txnHashes)
+	return blockNums, err == nil, err
+}
+
 // checks the pruning state to see if we would hold information about this
 // block in state history or not.  Some strange issues arise getting account
 // history for blocks that have been pruned away giving nonce too low errors
@@ -39,6 +39,7 @@ import (
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/ethapi"
 	"github.com/erigontech/erigon/rpc/rpccfg"
+	"github.com/erigontech/erigon/rpc/rpchelper"
 	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
 )
 
@@ -225,7 +226,7 @@ func TestCall_ByBlockHash_WithRequireCanonicalDefault_NonCanonicalBlock(t *testi
 	if _, err := api.Call(context.Background(), ethapi.CallArgs{
 		From: &from,
 		To:   &to,
-	}, blockNumberOrHashRef, nil); err != nil {
+	}, blockNumberOrHashRef, nil, nil); err != nil {
 		if fmt.Sprintf("%v", err) != fmt.Sprintf("hash %s is not currently canonical", orphanedBlock.Hash().String()[2:]) {
 			/* Not sure. Here https://github.com/ethereum/EIPs/blob/master/EIPS/eip-1898.md it is not explicitly said that
 			   eth_call should only work with canonical blocks.
@@ -250,7 +251,7 @@ func TestCall_ByBlockHash_WithRequireCanonicalTrue_NonCanonicalBlock(t *testing.
 	if _, err := api.Call(context.Background(), ethapi.CallArgs{
 		From: &from,
 		To:   &to,
-	}, blockNumberOrHashRef, nil); err != nil {
+	}, blockNumberOrHashRef, nil, nil); err != nil {
 		if fmt.Sprintf("%v", err) != fmt.Sprintf("hash %s is not currently canonical", orphanedBlock.Hash().String()[2:]) {
 			t.Errorf("wrong error: %v", err)
 		}
@@ -280,3 +281,61 @@ func (m mockBridgeReader) Events(context.Context, common.Hash, uint64) ([]*types
 func (m mockBridgeReader) EventTxnLookup(context.Context, common.Hash) (uint64, bool, error) {
 	panic("mock")
 }
+
+// commitService commits a block when called, in the middle of a batch.
+type commitService struct {
+	commit func() error
+}
+
+func (s *commitService) Commit() error { return s.commit() }
+
+func TestBatchTxReuse(t *testing.T) {
+	m := mock.Mock(t)
+	coinbase := common.Address{1}
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 2, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(coinbase)
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain.Slice(0, 1)))
+
+	base := NewBaseApi(nil, kvcache.NewDummy(), m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil)
+	db := rpchelper.NewScopedDB(m.DB, time.Minute)
+	api := NewEthAPI(base, db, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, m.Log)
+	server := rpc.NewServer(4, false /* traceRequests */, false /* debugSingleRequests */, true, m.Log, 100)
+	server.SetRequestScope(rpchelper.TxScope, false)
+	require.NoError(t, server.RegisterName("eth", api))
+	require.NoError(t, server.RegisterName("test", &commitService{commit: func() error {
+		return m.InsertChain(chain.Slice(1, 2))
+	}}))
+	client := rpc.DialInProc(server, m.Log)
+	defer client.Close()
+
+	var balanceBefore, balanceAfter hexutil.Big
+	var blockBefore, blockAfter hexutil.Uint64
+	batch := []rpc.BatchElem{
+		{Method: "eth_getBalance", Args: []any{coinbase, "latest"}, Result: &balanceBefore},
+		{Method: "eth_blockNumber", Result: &blockBefore},
+		{Method: "test_commit", Result: new(any)},
+		{Method: "eth_getBalance", Args: []any{coinbase, "latest"}, Result: &balanceAfter},
+		{Method: "eth_blockNumber", Result: &blockAfter},
+	}
+	require.NoError(t, client.BatchCall(batch))
+	for _, elem := range batch {
+		require.NoError(t, elem.Error, elem.Method)
+	}
+	// the items after the commit observe the state of the items before it
+	require.Equal(t, hexutil.Uint64(1), blockBefore)
+	require.Equal(t, blockBefore, blockAfter)
+	require.Equal(t, balanceBefore.String(), balanceAfter.String())
+
+	// the next batch observes the committed block
+	var balance hexutil.Big
+	var block hexutil.Uint64
+	batch = []rpc.BatchElem{
+		{Method: "eth_getBalance", Args: []any{coinbase, "latest"}, Result: &balance},
+		{Method: "eth_blockNumber", Result: &block},
+	}
+	require.NoError(t, client.BatchCall(batch))
+	require.Equal(t, hexutil.Uint64(2), block)
+	require.Equal(t, 1, balance.ToInt().Cmp(balanceBefore.ToInt()))
+}
@@ -33,7 +33,6 @@ import (
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/vm"
 	bortypes "github.com/erigontech/erigon/polygon/bor/types"
-	borrawdb "github.com/erigontech/erigon/polygon/rawdb"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/ethapi"
 	"github.com/erigontech/erigon/rpc/rpchelper"
@@ -230,21 +229,8 @@ func (api *APIImpl) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber
 	var borTx types.Transaction
 	var borTxHash common.Hash
 	if chainConfig.Bor != nil {
-		if api.useBridgeReader {
-			possibleBorTxnHash := bortypes.ComputeBorTxHash(b.NumberU64(), b.Hash())
-			_, ok, err := api.bridgeReader.EventTxnLookup(ctx, possibleBorTxnHash)
-			if err != nil {
-				return nil, err
-			}
-			if ok {
-				borTx = bortypes.NewBorTransaction()
-				borTxHash = possibleBorTxnHash
-			}
-		} else {
-			borTx = borrawdb.ReadBorTransactionForBlock(tx, b.NumberU64())
-			if borTx != nil {
-				borTxHash = bortypes.ComputeBorTxHash(b.NumberU64(), b.Hash())
-			}
+		if borTx, borTxHash, err = api.borStateSyncTxn(ctx, tx, b.Hash(), b.NumberU64()); err != nil {
+			return nil, err
 		}
 	}
 
@@ -296,21 +282,8 @@ func (api *APIImpl) GetBlockByHash(ctx context.Context, numberOrHash rpc.BlockNu
 	var borTx types.Transaction
 	var borTxHash common.Hash
 	if chainConfig.Bor != nil {
-		if api.useBridgeReader {
-			possibleBorTxnHash := bortypes.ComputeBorTxHash(block.NumberU64(), block.Hash())
-			_, ok, err := api.bridgeReader.EventTxnLookup(ctx, possibleBorTxnHash)
-			if err != nil {
-				return nil, err
-			}
-			if ok {
-				borTx = bortypes.NewBorTransaction()
-				borTxHash = possibleBorTxnHash
-			}
-		} else {
-			borTx = borrawdb.ReadBorTransactionForBlock(tx, number)
-			if borTx != nil {
-				borTxHash = bortypes.ComputeBorTxHash(number, block.Hash())
-			}
+		if borTx, borTxHash, err = api.borStateSyncTxn(ctx, tx, block.Hash(), number); err != nil {
+			return nil, err
 		}
 	}
 
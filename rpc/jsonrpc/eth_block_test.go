@@ -33,6 +33,7 @@ import (
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/execution/stages/mock"
 	"github.com/erigontech/erigon/rpc"
@@ -52,6 +53,18 @@ func TestGetBlockByNumberWithLatestTag(t *testing.T) {
 	assert.Equal(t, expected, b["hash"])
 }
 
+func BenchmarkGetBlockByNumberLatest(b *testing.B) {
+	m := mockWithGenerator(b, 16, func(i int, block *core.BlockGen) {})
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := api.GetBlockByNumber(m.Ctx, rpc.LatestBlockNumber, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestGetBlockByNumberWithLatestTag_WithHeadHashInDb(t *testing.T) {
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
 	ctx := context.Background()
@@ -57,7 +57,11 @@ import (
 var latestNumOrHash = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
 
 // Call implements eth_call. Executes a new message call immediately without creating a transaction on the block chain.
-func (api *APIImpl) Call(ctx context.Context, args ethapi2.CallArgs, requestedBlock *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides) (hexutil.Bytes, error) {
+func (api *APIImpl) Call(ctx context.Context, args ethapi2.CallArgs, requestedBlock *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides, options *ethapi2.CallOptions) (hexutil.Bytes, error) {
+	gasCap, callTimeout, err := options.Limits(api.GasCap, api.evmCallTimeout)
+	if err != nil {
+		return nil, err
+	}
 	tx, err := api.db.BeginTemporalRo(ctx)
 	if err != nil {
 		return nil, err
@@ -77,7 +81,7 @@ func (api *APIImpl) Call(ctx context.Context, args ethapi2.CallArgs, requestedBl
 	engine := api.engine()
 
 	if args.Gas == nil || uint64(*args.Gas) == 0 {
-		args.Gas = (*hexutil.Uint64)(&api.GasCap)
+		args.Gas = (*hexutil.Uint64)(&gasCap)
 	}
 
 	header, _, err := headerByNumberOrHash(ctx, tx, blockNrOrHash, api)
@@ -92,7 +96,7 @@ func (api *APIImpl) Call(ctx context.Context, args ethapi2.CallArgs, requestedBl
 	if err != nil {
 		return nil, err
 	}
-	result, err := transactions.DoCall(ctx, engine, args, tx, blockNrOrHash, header, overrides, api.GasCap, chainConfig, stateReader, api._blockReader, api.evmCallTimeout)
+	result, err := transactions.DoCall(ctx, engine, args, tx, blockNrOrHash, header, overrides, gasCap, chainConfig, stateReader, api._blockReader, callTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +109,9 @@ func (api *APIImpl) Call(ctx context.Context, args ethapi2.CallArgs, requestedBl
 	if len(result.Revert()) > 0 {
 		return nil, ethapi2.NewRevertError(result)
 	}
+	if errors.Is(result.Err, vm.ErrOutOfGas) {
+		return nil, &ethapi2.OutOfGasError{Err: result.Err}
+	}
 
 	return result.Return(), result.Err
 }
@@ -133,7 +140,11 @@ func headerByNumberOrHash(ctx context.Context, tx kv.Tx, blockNrOrHash rpc.Block
 }
 
 // EstimateGas implements eth_estimateGas. Returns an estimate of how much gas is necessary to allow the transaction to complete. The transaction will not be added to the blockchain.
-func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides) (hexutil.Uint64, error) {
+func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides, options *ethapi2.CallOptions) (hexutil.Uint64, error) {
+	gasCap, callTimeout, err := options.Limits(api.GasCap, api.evmCallTimeout)
+	if err != nil {
+		return 0, err
+	}
 	var args ethapi2.CallArgs
 	// if we actually get CallArgs here, we use them
 	if argsOrNil != nil {
@@ -203,9 +214,9 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 		hi = header.GasLimit
 	}
 	// Recap the highest gas allowance with specified gascap.
-	if hi > api.GasCap {
-		log.Warn("Caller gas above allowance, capping", "requested", hi, "cap", api.GasCap)
-		hi = api.GasCap
+	if hi > gasCap {
+		log.Warn("Caller gas above allowance, capping", "requested", hi, "cap", gasCap)
+		hi = gasCap
 	}
 
 	var feeCap *big.Int
@@ -250,7 +261,7 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 		}
 	}
 
-	caller, err := transactions.NewReusableCaller(engine, stateReader, overrides, header, args, api.GasCap, *blockNrOrHash, dbtx, api._blockReader, chainConfig, api.evmCallTimeout)
+	caller, err := transactions.NewReusableCaller(engine, stateReader, overrides, header, args, gasCap, *blockNrOrHash, dbtx, api._blockReader, chainConfig, callTimeout)
 	if err != nil {
 		return 0, err
 	}
@@ -268,7 +279,7 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 			return 0, result.Err
 		}
 		// Otherwise, the specified gas cap is too low
-		return 0, fmt.Errorf("gas required exceeds allowance (%d)", hi)
+		return 0, &ethapi2.OutOfGasError{Err: fmt.Errorf("gas required exceeds allowance (%d)", hi)}
 	}
 	// Assuming a contract can freely run all the instructions, we have
 	// the true amount of gas it wants to consume to execute fully.
@@ -30,7 +30,6 @@ import (
 	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon-lib/common/math"
 	"github.com/erigontech/erigon-lib/log/v3"
-	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/vm"
@@ -88,12 +87,11 @@ func blockHeaderOverride(blockCtx *evmtypes.BlockContext, blockOverride BlockOve
 
 func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateContext StateContext, stateOverride *ethapi.StateOverrides, timeoutMilliSecondsPtr *int64) ([][]map[string]interface{}, error) {
 	var (
-		hash               common.Hash
-		replayTransactions types.Transactions
-		evm                *vm.EVM
-		blockCtx           evmtypes.BlockContext
-		txCtx              evmtypes.TxContext
-		overrideBlockHash  map[uint64]common.Hash
+		hash              common.Hash
+		evm               *vm.EVM
+		blockCtx          evmtypes.BlockContext
+		txCtx             evmtypes.TxContext
+		overrideBlockHash map[uint64]common.Hash
 	)
 
 	overrideBlockHash = make(map[uint64]common.Hash)
@@ -133,7 +131,7 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 	}
 
 	// -1 is a default value for transaction index.
-	// If it's -1, we will try to replay every single transaction in that block
+	// If it's -1, the bundles are executed after every single transaction in that block
 	transactionIndex := -1
 
 	if simulateContext.TransactionIndex != nil {
@@ -144,10 +142,7 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 		transactionIndex = len(block.Transactions())
 	}
 
-	replayTransactions = block.Transactions()[:transactionIndex]
-
-	stateReader, err := rpchelper.CreateStateReader(ctx, tx, api._blockReader, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(blockNum-1)), 0, api.filters, api.stateCache, api._txNumReader)
-
+	stateReader, err := rpchelper.CreateHistoryStateReaderAtTxn(tx, blockNum, transactionIndex-1, api._txNumReader)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +170,6 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 
 	// Get a new instance of the EVM
 	evm = vm.NewEVM(blockCtx, txCtx, st, chainConfig, vm.Config{})
-	signer := types.MakeSigner(chainConfig, blockNum, blockCtx.Time)
 	rules := chainConfig.Rules(blockNum, blockCtx.Time)
 
 	timeoutMilliSeconds := int64(5000)
@@ -207,29 +201,7 @@ func (api *APIImpl) CallMany(ctx context.Context, bundles []Bundle, simulateCont
 	// Setup the gas pool (also for unmetered requests)
 	// and apply the message.
 	gp := new(core.GasPool).AddGas(math.MaxUint64).AddBlobGas(math.MaxUint64)
-	for idx, txn := range replayTransactions {
-		st.SetTxContext(blockNum, idx)
-		msg, err := txn.AsMessage(*signer, block.BaseFee(), rules)
-		if err != nil {
-			return nil, err
-		}
-		txCtx = core.NewEVMTxContext(msg)
-		evm = vm.NewEVM(blockCtx, txCtx, evm.IntraBlockState(), chainConfig, vm.Config{})
-		// Execute the transaction message
-		_, err = core.ApplyMessage(evm, msg, gp, true /* refunds */, false /* gasBailout */, api.engine())
-		if err != nil {
-			return nil, err
-		}
-
-		_ = st.FinalizeTx(rules, state.NewNoopWriter())
-
-		// If the timer caused an abort, return an appropriate error message
-		if evm.Cancelled() {
-			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
-		}
-	}
 
-	// after replaying the txns, we want to overload the state
 	// overload state
 	if stateOverride != nil {
 		err = stateOverride.Override(evm.IntraBlockState())
@@ -42,6 +42,7 @@ import (
 	"github.com/erigontech/erigon/cmd/rpcdaemon/rpcdaemontest"
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/execution/stages/mock"
 	"github.com/erigontech/erigon/rpc"
@@ -62,7 +63,7 @@ func TestEstimateGas(t *testing.T) {
 	if _, err := api.EstimateGas(context.Background(), &ethapi.CallArgs{
 		From: &from,
 		To:   &to,
-	}, nil, nil); err != nil {
+	}, nil, nil, nil); err != nil {
 		t.Errorf("calling EstimateGas: %v", err)
 	}
 }
@@ -79,7 +80,7 @@ func TestEthCallNonCanonical(t *testing.T) {
 	if _, err := api.Call(context.Background(), ethapi.CallArgs{
 		From: &from,
 		To:   &to,
-	}, blockNumberOrHashRef, nil); err != nil {
+	}, blockNumberOrHashRef, nil, nil); err != nil {
 		if fmt.Sprintf("%v", err) != "hash 3fcb7c0d4569fddc89cbea54b42f163e0c789351d98810a513895ab44b47020b is not currently canonical" {
 			t.Errorf("wrong error: %v", err)
 		}
@@ -104,11 +105,118 @@ func TestEthCallToPrunedBlock(t *testing.T) {
 		From: &bankAddress,
 		To:   &contractAddress,
 		Data: &callDataBytes,
-	}, blockNumberOrHashRef, nil); err != nil {
+	}, blockNumberOrHashRef, nil, nil); err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
 
+// mockWithInfiniteLoop deploys a contract looping forever, JUMPDEST PUSH1 0 JUMP, and returns its address.
+func mockWithInfiniteLoop(t *testing.T) (*mock.MockSentry, common.Address) {
+	// PUSH4 <code> PUSH1 0 MSTORE PUSH1 4 PUSH1 28 RETURN
+	initCode := hexutil.MustDecode("0x635b6000566000526004601cf3")
+	var loop common.Address
+	signer := types.LatestSignerForChainID(nil)
+	m := mockWithGenerator(t, 1, func(i int, block *core.BlockGen) {
+		nonce := block.TxNonce(testAddr)
+		txn, err := types.SignTx(types.NewContractCreation(nonce, new(uint256.Int), 100_000, new(uint256.Int), initCode), *signer, testKey)
+		require.NoError(t, err)
+		block.AddTx(txn)
+		loop = crypto.CreateAddress(testAddr, nonce)
+	})
+	return m, loop
+}
+
+func TestEthCallLimits(t *testing.T) {
+	m, loop := mockWithInfiniteLoop(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	// the server allows calls of a whole hour, with a gas cap they can't exhaust meanwhile
+	api := NewEthAPI(NewBaseApi(nil, stateCache, m.BlockReader, false, time.Hour, m.Engine, m.Dirs, nil), m.DB, nil, nil, nil, 1<<62, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+	args := ethapi.CallArgs{From: &testAddr, To: &loop}
+	code, err := api.GetCode(m.Ctx, loop, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Bytes{0x5b, 0x60, 0x00, 0x56}, code)
+
+	errorCode := func(t *testing.T, err error) int {
+		var coded interface{ ErrorCode() int }
+		require.ErrorAs(t, err, &coded)
+		return coded.ErrorCode()
+	}
+	timeout := "100ms"
+
+	t.Run("timeout", func(t *testing.T) {
+		start := time.Now()
+		_, err := api.Call(m.Ctx, args, nil, nil, &ethapi.CallOptions{Timeout: &timeout})
+		require.Less(t, time.Since(start), 10*time.Second)
+		require.Equal(t, ethapi.ErrCodeTimeout, errorCode(t, err))
+		require.EqualError(t, err, "execution aborted (timeout = 100ms)")
+
+		// above the block gas limit, for the estimation not to run out of gas before the timeout
+		gas := hexutil.Uint64(1 << 62)
+		estimateArgs := args
+		estimateArgs.Gas = &gas
+		start = time.Now()
+		_, err = api.EstimateGas(m.Ctx, &estimateArgs, nil, nil, &ethapi.CallOptions{Timeout: &timeout})
+		require.Less(t, time.Since(start), 10*time.Second)
+		require.Equal(t, ethapi.ErrCodeTimeout, errorCode(t, err))
+	})
+
+	t.Run("out of gas", func(t *testing.T) {
+		gasCap := hexutil.Uint64(1_000_000)
+		_, err := api.Call(m.Ctx, args, nil, nil, &ethapi.CallOptions{GasCap: &gasCap})
+		require.Equal(t, ethapi.ErrCodeOutOfGas, errorCode(t, err))
+		require.ErrorIs(t, err, vm.ErrOutOfGas)
+
+		_, err = api.EstimateGas(m.Ctx, &args, nil, nil, &ethapi.CallOptions{GasCap: &gasCap})
+		require.Equal(t, ethapi.ErrCodeOutOfGas, errorCode(t, err))
+		require.EqualError(t, err, "gas required exceeds allowance (1000000)")
+	})
+
+	t.Run("cancelled request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(m.Ctx)
+		time.AfterFunc(100*time.Millisecond, cancel)
+		start := time.Now()
+		_, err := api.Call(ctx, args, nil, nil, nil)
+		require.Less(t, time.Since(start), 10*time.Second)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("server maxima", func(t *testing.T) {
+		api := NewEthAPI(NewBaseApi(nil, stateCache, m.BlockReader, false, 100*time.Millisecond, m.Engine, m.Dirs, nil), m.DB, nil, nil, nil, 1<<62, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+		hour := "1h"
+		gasCap := hexutil.Uint64(1 << 63)
+		_, err := api.Call(m.Ctx, args, nil, nil, &ethapi.CallOptions{GasCap: &gasCap, Timeout: &hour})
+		require.EqualError(t, err, "execution aborted (timeout = 100ms)")
+
+		invalid := "-1s"
+		_, err = api.Call(m.Ctx, args, nil, nil, &ethapi.CallOptions{Timeout: &invalid})
+		require.Equal(t, -32602, errorCode(t, err))
+	})
+}
+
+func TestCallOptionsLimits(t *testing.T) {
+	gasCap, timeout := hexutil.Uint64(1000), "1s"
+	for _, tc := range []struct {
+		name        string
+		options     *ethapi.CallOptions
+		maxGas      uint64
+		maxTimeout  time.Duration
+		wantGas     uint64
+		wantTimeout time.Duration
+	}{
+		{"no options", nil, 5000, time.Minute, 5000, time.Minute},
+		{"below the maxima", &ethapi.CallOptions{GasCap: &gasCap, Timeout: &timeout}, 5000, time.Minute, 1000, time.Second},
+		{"above the maxima", &ethapi.CallOptions{GasCap: &gasCap, Timeout: &timeout}, 500, time.Millisecond, 500, time.Millisecond},
+		{"unlimited server", &ethapi.CallOptions{GasCap: &gasCap, Timeout: &timeout}, 0, 0, 1000, time.Second},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gas, timeout, err := tc.options.Limits(tc.maxGas, tc.maxTimeout)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantGas, gas)
+			require.Equal(t, tc.wantTimeout, timeout)
+		})
+	}
+}
+
 func TestGetProof(t *testing.T) {
 	var maxGetProofRewindBlockCount = 1 // Note, this is unsafe for parallel tests, but, this test is the only consumer for now
 
@@ -592,3 +700,64 @@ func doPrune(t *testing.T, db kv.RwDB, pruneTo uint64) {
 	err = tx.Commit()
 	require.NoError(t, err)
 }
+
+func TestCreateHistoryStateReaderAtTxn(t *testing.T) {
+	var (
+		signer      = types.LatestSignerForChainID(nil)
+		bankKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		bankAddress = crypto.PubkeyToAddress(bankKey.PublicKey)
+		recipient   = common.Address{1}
+		gspec       = &types.Genesis{
+			Config: chain.TestChainConfig,
+			Alloc:  types.GenesisAlloc{bankAddress: {Balance: big.NewInt(1e9)}},
+		}
+	)
+	m := mock.MockWithGenesis(t, gspec, bankKey, false)
+
+	// block 1 sends 1, 2 and 3 wei to the recipient, block 2 sends 10 wei
+	values := [][]uint64{{1, 2, 3}, {10}}
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, len(values), func(i int, block *core.BlockGen) {
+		for _, value := range values[i] {
+			txn, err := types.SignTx(types.NewTransaction(block.TxNonce(bankAddress), recipient, uint256.NewInt(value), 21000, new(uint256.Int), nil), *signer, bankKey)
+			require.NoError(t, err)
+			block.AddTx(txn)
+		}
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	tx, err := m.DB.BeginTemporalRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	requireState := func(blockNum uint64, txnIndex int, balance, nonce uint64) {
+		t.Helper()
+		stateReader, err := rpchelper.CreateHistoryStateReaderAtTxn(tx, blockNum, txnIndex, m.BlockReader.TxnumReader(m.Ctx))
+		require.NoError(t, err)
+		st := state.New(stateReader)
+		gotBalance, err := st.GetBalance(recipient)
+		require.NoError(t, err)
+		require.Equal(t, balance, gotBalance.Uint64(), "balance at block %d txn %d", blockNum, txnIndex)
+		gotNonce, err := st.GetNonce(bankAddress)
+		require.NoError(t, err)
+		require.Equal(t, nonce, gotNonce, "nonce at block %d txn %d", blockNum, txnIndex)
+	}
+	// replay the transfers, the final system txn of a block leaves the state unchanged
+	var balance, nonce uint64
+	for i, blockValues := range values {
+		blockNum := uint64(i + 1)
+		requireState(blockNum, -1, balance, nonce)
+		for txnIndex, value := range blockValues {
+			balance, nonce = balance+value, nonce+1
+			requireState(blockNum, txnIndex, balance, nonce)
+		}
+		requireState(blockNum, len(blockValues), balance, nonce)
+
+		_, err := rpchelper.CreateHistoryStateReaderAtTxn(tx, blockNum, len(blockValues)+1, m.BlockReader.TxnumReader(m.Ctx))
+		require.ErrorContains(t, err, "out of range")
+		_, err = rpchelper.CreateHistoryStateReaderAtTxn(tx, blockNum, -2, m.BlockReader.TxnumReader(m.Ctx))
+		require.ErrorContains(t, err, "out of range")
+	}
+	_, err = rpchelper.CreateHistoryStateReaderAtTxn(tx, uint64(len(values)+1), -1, m.BlockReader.TxnumReader(m.Ctx))
+	require.ErrorContains(t, err, "not found")
+}
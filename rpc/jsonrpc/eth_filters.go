@@ -18,57 +18,111 @@ package jsonrpc
 
 import (
 	"context"
-	"errors"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/erigontech/erigon-db/rawdb"
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/debug"
+	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/eth/filters"
 	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/rpc/ethapi"
 	"github.com/erigontech/erigon/rpc/rpchelper"
 )
 
-// NewPendingTransactionFilter new transaction filter
-func (api *APIImpl) NewPendingTransactionFilter(_ context.Context) (string, error) {
+// NewPendingTransactionFilter implements eth_newPendingTransactionFilter. Creates a filter in the node, to notify when
+// transactions are added to the txpool. By default eth_getFilterChanges returns the hashes of the transactions; with
+// fullTx set it returns the transactions in the format of eth_getTransactionByHash, like the subscription variant.
+func (api *APIImpl) NewPendingTransactionFilter(ctx context.Context, fullTx *bool) (string, error) {
 	if api.filters == nil {
 		return "", rpc.ErrNotificationsUnsupported
 	}
-	txsCh, id := api.filters.SubscribePendingTxs(32)
-	go func() {
-		for txs := range txsCh {
-			api.filters.AddPendingTxs(id, txs)
-		}
-	}()
+	id, err := api.filters.InstallPendingTxsFilter(filterOwner(ctx), fullTx != nil && *fullTx)
+	if err != nil {
+		return "", err
+	}
 	return "0x" + string(id), nil
 }
 
 // NewBlockFilter implements eth_newBlockFilter. Creates a filter in the node, to notify when a new block arrives.
-func (api *APIImpl) NewBlockFilter(_ context.Context) (string, error) {
+// eth_getFilterChanges returns the hashes of the blocks added to the canonical chain since the last poll, including
+// the blocks replacing the ones reported before a reorg.
+func (api *APIImpl) NewBlockFilter(ctx context.Context) (string, error) {
 	if api.filters == nil {
 		return "", rpc.ErrNotificationsUnsupported
 	}
-	ch, id := api.filters.SubscribeNewHeads(32)
-	go func() {
-		for block := range ch {
-			api.filters.AddPendingBlock(id, block)
-		}
-	}()
-	return "0x" + string(id), nil
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	head, err := rpchelper.GetLatestBlockNumber(tx)
+	if err != nil {
+		return "", err
+	}
+	headHash, _, err := api._blockReader.CanonicalHash(ctx, tx, head)
+	if err != nil {
+		return "", err
+	}
+	id, err := api.filters.InstallBlockFilter(filterOwner(ctx), head, headHash)
+	if err != nil {
+		return "", err
+	}
+	return "0x" + id, nil
+}
+
+// blockFilterChanges returns the changes of a block filter, read from the canonical chain.
+func (api *APIImpl) blockFilterChanges(ctx context.Context, id string) ([]any, error) {
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	head, err := rpchelper.GetLatestBlockNumber(tx)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := api.filters.BlockFilterChanges(id, head, func(blockNum uint64) (common.Hash, bool, error) {
+		return api._blockReader.CanonicalHash(ctx, tx, blockNum)
+	})
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]any, 0, len(hashes))
+	for _, hash := range hashes {
+		changes = append(changes, hash)
+	}
+	return changes, nil
 }
 
 // NewFilter implements eth_newFilter. Creates an arbitrary filter object, based on filter options, to notify when the state changes (logs).
-func (api *APIImpl) NewFilter(_ context.Context, crit filters.FilterCriteria) (string, error) {
+func (api *APIImpl) NewFilter(ctx context.Context, crit filters.FilterCriteria) (string, error) {
 	if api.filters == nil {
 		return "", rpc.ErrNotificationsUnsupported
 	}
-	logs, id := api.filters.SubscribeLogs(256, crit)
-	go func() {
-		for lg := range logs {
-			api.filters.AddLogs(id, lg)
-		}
-	}()
-	return "0x" + string(id), nil
+	id, err := api.filters.InstallFilter(filterOwner(ctx), func() string {
+		logs, id := api.filters.SubscribeLogsInternal(256, crit)
+		go func() {
+			for lg := range logs {
+				api.filters.AddLogs(id, lg)
+			}
+		}()
+		return string(id)
+	})
+	if err != nil {
+		return "", err
+	}
+	return "0x" + id, nil
+}
+
+// filterOwner returns the client the filters installed by the request of ctx are counted for.
+func filterOwner(ctx context.Context) string {
+	return rpchelper.FilterOwner(rpc.PeerInfoFromContext(ctx).RemoteAddr)
 }
 
 // UninstallFilter new transaction filter
@@ -78,42 +132,36 @@ func (api *APIImpl) UninstallFilter(_ context.Context, index string) (isDeleted
 	}
 	// remove 0x
 	cutIndex := strings.TrimPrefix(index, "0x")
-	if ok := api.filters.UnsubscribeHeads(rpchelper.HeadsSubID(cutIndex)); ok {
-		isDeleted = true
-	}
-	if ok := api.filters.UnsubscribePendingTxs(rpchelper.PendingTxsSubID(cutIndex)); ok {
-		isDeleted = true
-	}
-	if ok := api.filters.UnsubscribeLogs(rpchelper.LogsSubID(cutIndex)); ok {
-		isDeleted = true
+	return api.filters.UninstallFilter(cutIndex), nil
+}
+
+// KeepAliveFilter implements eth_keepAliveFilter. Refreshes the TTL of a previously created filter without
+// consuming its changes, returns false if the filter doesn't exist or has expired.
+func (api *APIImpl) KeepAliveFilter(_ context.Context, index string) (bool, error) {
+	if api.filters == nil {
+		return false, rpc.ErrNotificationsUnsupported
 	}
-	return
+	return api.filters.PollFilter(strings.TrimPrefix(index, "0x")), nil
 }
 
 // GetFilterChanges implements eth_getFilterChanges.
 // Polling method for a previously created filter
 // returns an array of logs, block headers, or pending transactions which have occurred since the last poll.
-func (api *APIImpl) GetFilterChanges(_ context.Context, index string) ([]any, error) {
+func (api *APIImpl) GetFilterChanges(ctx context.Context, index string) ([]any, error) {
 	if api.filters == nil {
 		return nil, rpc.ErrNotificationsUnsupported
 	}
 	stub := make([]any, 0)
 	// remove 0x
 	cutIndex := strings.TrimPrefix(index, "0x")
-	if blocks, ok := api.filters.ReadPendingBlocks(rpchelper.HeadsSubID(cutIndex)); ok {
-		for _, v := range blocks {
-			stub = append(stub, v.Hash())
-		}
-		return stub, nil
+	if !api.filters.PollFilter(cutIndex) {
+		return nil, rpchelper.ErrFilterNotFound
+	}
+	if api.filters.IsBlockFilter(cutIndex) {
+		return api.blockFilterChanges(ctx, cutIndex)
 	}
 	if txs, ok := api.filters.ReadPendingTxs(rpchelper.PendingTxsSubID(cutIndex)); ok {
-		if len(txs) > 0 {
-			for _, txn := range txs[0] {
-				stub = append(stub, txn.Hash())
-			}
-			return stub, nil
-		}
-		return stub, nil
+		return api.pendingTxsChanges(ctx, cutIndex, txs)
 	}
 	if logs, ok := api.filters.ReadLogs(rpchelper.LogsSubID(cutIndex)); ok {
 		for _, v := range logs {
@@ -121,7 +169,38 @@ func (api *APIImpl) GetFilterChanges(_ context.Context, index string) ([]any, er
 		}
 		return stub, nil
 	}
-	return nil, errors.New("filter not found")
+	// the filter is installed, but nothing happened since the last poll
+	return stub, nil
+}
+
+// pendingTxsChanges returns the transactions buffered by a pending transactions filter since the last poll, as hashes
+// or in the format of eth_getTransactionByHash depending on the filter.
+func (api *APIImpl) pendingTxsChanges(ctx context.Context, id string, batches [][]types.Transaction) ([]any, error) {
+	var (
+		cc        *chain.Config
+		curHeader *types.Header
+		err       error
+	)
+	full := api.filters.FullTxFilter(id)
+	if full {
+		if cc, curHeader, err = api.pendingTxsHead(ctx); err != nil {
+			return nil, err
+		}
+	}
+	changes := make([]any, 0)
+	for _, txs := range batches {
+		for _, txn := range txs {
+			if txn == nil {
+				continue
+			}
+			if full {
+				changes = append(changes, newRPCPendingTransaction(txn, curHeader, cc))
+			} else {
+				changes = append(changes, txn.Hash())
+			}
+		}
+	}
+	return changes, nil
 }
 
 // GetFilterLogs implements eth_getFilterLogs.
@@ -132,13 +211,18 @@ func (api *APIImpl) GetFilterLogs(_ context.Context, index string) ([]*types.Log
 		return nil, rpc.ErrNotificationsUnsupported
 	}
 	cutIndex := strings.TrimPrefix(index, "0x")
+	if !api.filters.PollFilter(cutIndex) {
+		return nil, rpchelper.ErrFilterNotFound
+	}
 	if logs, ok := api.filters.ReadLogs(rpchelper.LogsSubID(cutIndex)); ok {
 		return logs, nil
 	}
-	return nil, errors.New("filter not found")
+	return []*types.Log{}, nil
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
+// The header is in the format of eth_getBlockByNumber, so it has all the fields of the forks active at the block,
+// and its hash.
 func (api *APIImpl) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 	if api.filters == nil {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -149,22 +233,22 @@ func (api *APIImpl) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 	}
 
 	rpcSub := notifier.CreateSubscription()
+	headers, id := api.filters.SubscribeNewHeads(32)
 
 	go func() {
 		defer debug.LogPanic()
-		headers, id := api.filters.SubscribeNewHeads(32)
 		defer api.filters.UnsubscribeHeads(id)
 		for {
 			select {
 			case h, ok := <-headers:
 				if h != nil {
-					err := notifier.Notify(rpcSub.ID, h)
+					err := notifier.Notify(rpcSub.ID, ethapi.RPCMarshalHeader(h))
 					if err != nil {
 						log.Warn("[rpc] error while notifying subscription", "err", err)
 					}
 				}
 				if !ok {
-					log.Warn("[rpc] new heads channel was closed")
+					endSubscription(notifier, rpcSub, api.filters.HeadsSubErr(id), "new heads")
 					return
 				}
 			case <-rpcSub.Err():
@@ -176,7 +260,22 @@ func (api *APIImpl) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 	return rpcSub, nil
 }
 
+// endSubscription is called once the filter channel behind rpcSub is closed. If the filter closed it because
+// the client didn't keep up (err is set), the client is sent the error before the subscription is dropped.
+func endSubscription(notifier rpc.Notifier, rpcSub *rpc.Subscription, err error, channel string) {
+	if err == nil {
+		log.Warn("[rpc] " + channel + " channel was closed")
+		return
+	}
+	log.Debug("[rpc] closing subscription", "id", rpcSub.ID, "err", err)
+	if err := notifier.CloseWithError(rpcSub.ID, err); err != nil {
+		log.Warn("[rpc] error while closing subscription", "err", err)
+	}
+}
+
 // NewPendingTransactions send a notification each time when a transaction had added into mempool.
+// By default the notification is the transaction hash; with fullTx set it is the transaction
+// in the format of eth_getTransactionByHash, so that subscribers don't have to look it up.
 func (api *APIImpl) NewPendingTransactions(ctx context.Context, fullTx *bool) (*rpc.Subscription, error) {
 	if api.filters == nil {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -186,32 +285,62 @@ func (api *APIImpl) NewPendingTransactions(ctx context.Context, fullTx *bool) (*
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
 
+	full := fullTx != nil && *fullTx
+	// in full mode transactions are formatted against the latest head, which is tracked through the new heads
+	// feed instead of being read from the db for every batch
+	var (
+		cc        *chain.Config
+		curHeader *types.Header
+		heads     <-chan *types.Header
+		headsID   rpchelper.HeadsSubID
+	)
+	if full {
+		// the heads only keep curHeader up to date, they are not sent to the client
+		heads, headsID = api.filters.SubscribeNewHeadsInternal(8)
+		var err error
+		if cc, curHeader, err = api.pendingTxsHead(ctx); err != nil {
+			api.filters.UnsubscribeHeads(headsID)
+			return &rpc.Subscription{}, err
+		}
+	}
+
 	rpcSub := notifier.CreateSubscription()
+	// subscribe before returning, so that no transaction added after the subscription is acknowledged is missed
+	txsCh, id := api.filters.SubscribePendingTxs(256)
 
 	go func() {
 		defer debug.LogPanic()
-		txsCh, id := api.filters.SubscribePendingTxs(256)
 		defer api.filters.UnsubscribePendingTxs(id)
+		if full {
+			defer api.filters.UnsubscribeHeads(headsID)
+		}
 
 		for {
 			select {
+			case h, ok := <-heads:
+				if h != nil {
+					curHeader = h
+				}
+				if !ok {
+					// keep formatting against the last head seen rather than dropping the subscription
+					heads = nil
+				}
 			case txs, ok := <-txsCh:
+				txs = api.filters.LimitPendingTxs(txs)
 				for _, t := range txs {
-					if t != nil {
-						var err error
-						if fullTx != nil && *fullTx {
-							err = notifier.Notify(rpcSub.ID, t)
-						} else {
-							err = notifier.Notify(rpcSub.ID, t.Hash())
-						}
-
-						if err != nil {
-							log.Warn("[rpc] error while notifying subscription", "err", err)
-						}
+					if t == nil {
+						continue
+					}
+					var notification any = t.Hash()
+					if full {
+						notification = newRPCPendingTransaction(t, curHeader, cc)
+					}
+					if err := notifier.Notify(rpcSub.ID, notification); err != nil {
+						log.Warn("[rpc] error while notifying subscription", "err", err)
 					}
 				}
 				if !ok {
-					log.Warn("[rpc] new pending transactions channel was closed")
+					endSubscription(notifier, rpcSub, api.filters.PendingTxsSubErr(id), "new pending transactions")
 					return
 				}
 			case <-rpcSub.Err():
@@ -223,6 +352,20 @@ func (api *APIImpl) NewPendingTransactions(ctx context.Context, fullTx *bool) (*
 	return rpcSub, nil
 }
 
+// pendingTxsHead returns the chain config and the current header that pending transactions are formatted against.
+func (api *APIImpl) pendingTxsHead(ctx context.Context) (*chain.Config, *types.Header, error) {
+	tx, err := api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+	cc, err := api.chainConfig(ctx, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cc, rawdb.ReadCurrentHeader(tx), nil
+}
+
 // NewPendingTransactionsWithBody send a notification each time when a transaction had added into mempool.
 func (api *APIImpl) NewPendingTransactionsWithBody(ctx context.Context) (*rpc.Subscription, error) {
 	if api.filters == nil {
@@ -252,7 +395,7 @@ func (api *APIImpl) NewPendingTransactionsWithBody(ctx context.Context) (*rpc.Su
 					}
 				}
 				if !ok {
-					log.Warn("[rpc] new pending transactions channel was closed")
+					endSubscription(notifier, rpcSub, api.filters.PendingTxsSubErr(id), "new pending transactions")
 					return
 				}
 			case <-rpcSub.Err():
@@ -275,25 +418,118 @@ func (api *APIImpl) Logs(ctx context.Context, crit filters.FilterCriteria) (*rpc
 	}
 
 	rpcSub := notifier.CreateSubscription()
+	logs, truncated, id := api.filters.SubscribeLogsNotifications(api.SubscribeLogsChannelSize, crit)
 
 	go func() {
 		defer debug.LogPanic()
-		logs, id := api.filters.SubscribeLogs(api.SubscribeLogsChannelSize, crit)
 		defer api.filters.UnsubscribeLogs(id)
 
+		notify := func(h *types.Log) {
+			if err := notifier.Notify(rpcSub.ID, h); err != nil {
+				log.Warn("[rpc] error while notifying subscription", "err", err)
+			}
+		}
 		for {
 			select {
 			case h, ok := <-logs:
 				if h != nil {
-					err := notifier.Notify(rpcSub.ID, h)
-					if err != nil {
-						log.Warn("[rpc] error while notifying subscription", "err", err)
+					notify(h)
+				}
+				if !ok {
+					endSubscription(notifier, rpcSub, api.filters.LogsSubErr(id), "log")
+					return
+				}
+			case notice := <-truncated:
+				// the logs of the block which were kept are queued before the notice, send them first
+			drain:
+				for {
+					select {
+					case h, ok := <-logs:
+						if !ok {
+							break drain
+						}
+						if h != nil {
+							notify(h)
+						}
+					default:
+						break drain
 					}
 				}
+				if err := notifier.Notify(rpcSub.ID, notice); err != nil {
+					log.Warn("[rpc] error while notifying subscription", "err", err)
+				}
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// syncingProgressInterval is how often subscribers of "syncing" get the sync progress while the node is syncing.
+const syncingProgressInterval = 10 * time.Second
+
+// EthSyncingAPI provides the "syncing" subscription of the eth namespace. It's a service of its own
+// because the Syncing method of APIImpl implements eth_syncing.
+// All the subscribers share one poller of the sync status, which runs while there is at least one subscriber.
+type EthSyncingAPI struct {
+	api *APIImpl
+
+	mu         sync.Mutex
+	subs       map[rpc.ID]*syncingSub
+	poll       chan struct{} // asks the poller for the current status, e.g. for a new subscriber
+	stopPoller context.CancelFunc
+}
+
+// syncingSub receives the sync status polled for a subscriber, the latest one replacing any pending one.
+type syncingSub struct {
+	ch  chan syncingUpdate
+	err error // why the poller closed ch, set before closing it
+}
+
+type syncingUpdate struct {
+	reply        *remote.SyncingReply
+	withProgress bool
+}
+
+func NewEthSyncingAPI(api *APIImpl) *EthSyncingAPI {
+	return &EthSyncingAPI{api: api, subs: map[rpc.ID]*syncingSub{}}
+}
+
+// Syncing sends the sync status in the format of eth_syncing when subscribed, then each time the node
+// starts or stops syncing. While syncing, the progress is also sent every syncingProgressInterval.
+func (s *EthSyncingAPI) Syncing(ctx context.Context) (*rpc.Subscription, error) {
+	if s.api.filters == nil || s.api.ethBackend == nil {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	sub := s.subscribe(rpcSub.ID)
+
+	go func() {
+		defer debug.LogPanic()
+		defer s.unsubscribe(rpcSub.ID)
+
+		var notified, syncing bool
+		for {
+			select {
+			case u, ok := <-sub.ch:
 				if !ok {
-					log.Warn("[rpc] log channel was closed")
+					endSubscription(notifier, rpcSub, sub.err, "syncing")
 					return
 				}
+				if notified && u.reply.Syncing == syncing && !(u.withProgress && syncing) {
+					continue
+				}
+				notified, syncing = true, u.reply.Syncing
+				if err := notifier.Notify(rpcSub.ID, syncingStatus(u.reply)); err != nil {
+					log.Warn("[rpc] error while notifying subscription", "err", err)
+				}
 			case <-rpcSub.Err():
 				return
 			}
@@ -302,3 +538,141 @@ func (api *APIImpl) Logs(ctx context.Context, crit filters.FilterCriteria) (*rpc
 
 	return rpcSub, nil
 }
+
+// subscribe adds a subscriber, starting the poller for the first one, and asks for the current status.
+func (s *EthSyncingAPI) subscribe(id rpc.ID) *syncingSub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub := &syncingSub{ch: make(chan syncingUpdate, 1)}
+	s.subs[id] = sub
+	if s.stopPoller == nil {
+		var ctx context.Context
+		ctx, s.stopPoller = context.WithCancel(context.Background())
+		s.poll = make(chan struct{}, 1)
+		go s.runPoller(ctx, s.poll)
+	}
+	select {
+	case s.poll <- struct{}{}:
+	default: // a poll is already pending
+	}
+	return sub
+}
+
+// unsubscribe removes a subscriber, stopping the poller after the last one.
+func (s *EthSyncingAPI) unsubscribe(id rpc.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[id]; !ok {
+		return
+	}
+	delete(s.subs, id)
+	if len(s.subs) == 0 && s.stopPoller != nil {
+		s.stopPoller()
+		s.stopPoller = nil
+	}
+}
+
+// runPoller reads the sync status when asked to, when a new head is notified, which is at the end of each
+// iteration of the stage loop and so when syncing may have ended, and every syncingProgressInterval.
+func (s *EthSyncingAPI) runPoller(ctx context.Context, poll <-chan struct{}) {
+	defer debug.LogPanic()
+	headers, id := s.api.filters.SubscribeNewHeadsInternal(32)
+	defer s.api.filters.UnsubscribeHeads(id)
+	progress := time.NewTicker(syncingProgressInterval)
+	defer progress.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll:
+			s.broadcast(ctx, false)
+		case _, ok := <-headers:
+			if !ok {
+				s.closeAll(ctx, s.api.filters.HeadsSubErr(id))
+				return
+			}
+			s.broadcast(ctx, false)
+		case <-progress.C:
+			s.broadcast(ctx, true)
+		}
+	}
+}
+
+func (s *EthSyncingAPI) broadcast(ctx context.Context, withProgress bool) {
+	reply, err := s.api.ethBackend.Syncing(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Warn("[rpc] error while reading sync status", "err", err)
+		}
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ctx.Err() != nil { // stopped meanwhile, the subscribers may already belong to another poller
+		return
+	}
+	for _, sub := range s.subs {
+		u := syncingUpdate{reply: reply, withProgress: withProgress}
+		select {
+		case pending := <-sub.ch:
+			// the subscriber still has to see the progress it was due
+			u.withProgress = u.withProgress || pending.withProgress
+		default:
+		}
+		sub.ch <- u
+	}
+}
+
+// closeAll drops all the subscribers with err and stops the poller.
+func (s *EthSyncingAPI) closeAll(ctx context.Context, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ctx.Err() != nil { // stopped meanwhile, the subscribers may already belong to another poller
+		return
+	}
+	for id, sub := range s.subs {
+		sub.err = err
+		close(sub.ch)
+		delete(s.subs, id)
+	}
+	s.stopPoller()
+	s.stopPoller = nil
+}
+
+// filtersChainReader reads the chain for the subscriptions to catch up with the blocks they missed while the filters
+// weren't subscribed to the backend.
+type filtersChainReader struct {
+	api *APIImpl
+}
+
+func (r *filtersChainReader) Head(ctx context.Context) (uint64, error) {
+	tx, err := r.api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	return rpchelper.GetLatestBlockNumber(tx)
+}
+
+func (r *filtersChainReader) CanonicalHeader(ctx context.Context, blockNum uint64) (*types.Header, error) {
+	tx, err := r.api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return r.api._blockReader.HeaderByNumber(ctx, tx, blockNum)
+}
+
+func (r *filtersChainReader) Header(ctx context.Context, hash common.Hash, blockNum uint64) (*types.Header, error) {
+	tx, err := r.api.db.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return r.api._blockReader.Header(ctx, tx, hash, blockNum)
+}
+
+func (r *filtersChainReader) Logs(ctx context.Context, crit filters.FilterCriteria) (types.Logs, error) {
+	return r.api.GetLogs(ctx, crit)
+}
@@ -22,17 +22,23 @@ import (
 	"testing"
 	"time"
 
+	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/erigontech/erigon-lib/chain/params"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/length"
 	txpool "github.com/erigontech/erigon-lib/gointerfaces/txpoolproto"
 	"github.com/erigontech/erigon-lib/kv/kvcache"
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/cmd/rpcdaemon/rpcdaemontest"
+	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/eth/filters"
 	"github.com/erigontech/erigon/execution/stages/mock"
+	"github.com/erigontech/erigon/rpc/ethapi"
 	"github.com/erigontech/erigon/rpc/rpccfg"
 	"github.com/erigontech/erigon/rpc/rpchelper"
 )
@@ -46,7 +52,7 @@ func TestNewFilters(t *testing.T) {
 	ff := rpchelper.New(ctx, rpchelper.DefaultFiltersConfig, nil, nil, mining, func() {}, m.Log)
 	api := NewEthAPI(NewBaseApi(ff, stateCache, m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
 
-	ptf, err := api.NewPendingTransactionFilter(ctx)
+	ptf, err := api.NewPendingTransactionFilter(ctx, nil)
 	assert.NoError(err)
 
 	nf, err := api.NewFilter(ctx, filters.FilterCriteria{})
@@ -68,6 +74,169 @@ func TestNewFilters(t *testing.T) {
 	assert.True(ok)
 }
 
+func TestFiltersExpiry(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	ctx, conn := rpcdaemontest.CreateTestGrpcConn(t, mock.Mock(t))
+	mining := txpool.NewMiningClient(conn)
+	config := rpchelper.DefaultFiltersConfig
+	config.RpcFiltersTTL = 200 * time.Millisecond
+	ff := rpchelper.New(ctx, config, nil, nil, mining, func() {}, m.Log)
+	api := NewEthAPI(NewBaseApi(ff, stateCache, m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+
+	polled, err := api.NewFilter(ctx, filters.FilterCriteria{})
+	require.NoError(t, err)
+	keptAlive, err := api.NewBlockFilter(ctx)
+	require.NoError(t, err)
+	idle, err := api.NewPendingTransactionFilter(ctx, nil)
+	require.NoError(t, err)
+
+	// a live filter with no changes is answered with no changes
+	changes, err := api.GetFilterChanges(ctx, polled)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	for i := 0; i < 10; i++ {
+		time.Sleep(50 * time.Millisecond)
+		_, err = api.GetFilterLogs(ctx, polled)
+		require.NoError(t, err)
+		ok, err := api.KeepAliveFilter(ctx, keptAlive)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	_, err = api.GetFilterChanges(ctx, idle)
+	require.ErrorIs(t, err, rpchelper.ErrFilterNotFound)
+	ok, err := api.KeepAliveFilter(ctx, idle)
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = api.UninstallFilter(ctx, idle)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = api.UninstallFilter(ctx, keptAlive)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// once no longer polled, the filter expires too
+	time.Sleep(500 * time.Millisecond)
+	_, err = api.GetFilterLogs(ctx, polled)
+	require.ErrorIs(t, err, rpchelper.ErrFilterNotFound)
+}
+
+func TestPendingTransactionFilter(t *testing.T) {
+	m, require := mock.Mock(t), require.New(t)
+	config := rpchelper.DefaultFiltersConfig
+	config.RpcSubscriptionFiltersMaxTxs = 3
+	ff := rpchelper.New(m.Ctx, config, nil, nil, nil, func() {}, m.Log)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(ff, stateCache, m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+
+	hashesFilter, err := api.NewPendingTransactionFilter(m.Ctx, nil)
+	require.NoError(err)
+	fullTx := true
+	bodiesFilter, err := api.NewPendingTransactionFilter(m.Ctx, &fullTx)
+	require.NoError(err)
+
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	var txs types.Transactions
+	for nonce := uint64(0); nonce < 5; nonce++ {
+		txn, err := types.SignTx(types.NewTransaction(nonce, common.Address{1}, uint256.NewInt(1), params.TxGas, uint256.NewInt(common.GWei), nil), *signer, m.Key)
+		require.NoError(err)
+		txs = append(txs, txn)
+	}
+	addTxs := func(txs types.Transactions) {
+		rlpTxs, err := types.MarshalTransactionsBinary(txs)
+		require.NoError(err)
+		ff.OnNewTx(&txpool.OnAddReply{RplTxs: rlpTxs})
+	}
+	// polls the filter until it returns n changes
+	poll := func(id string, n int) []any {
+		var changes []any
+		require.Eventually(func() bool {
+			polled, err := api.GetFilterChanges(m.Ctx, id)
+			if err != nil {
+				return false
+			}
+			changes = append(changes, polled...)
+			return len(changes) >= n
+		}, 10*time.Second, 10*time.Millisecond)
+		require.Len(changes, n)
+		return changes
+	}
+
+	// the changes are returned once, across batches
+	addTxs(txs[:1])
+	addTxs(txs[1:2])
+	require.Equal([]any{txs[0].Hash(), txs[1].Hash()}, poll(hashesFilter, 2))
+	bodies := poll(bodiesFilter, 2)
+	for i, body := range bodies {
+		require.Equal(txs[i].Hash(), body.(*ethapi.RPCTransaction).Hash)
+		require.Equal(m.Address, body.(*ethapi.RPCTransaction).From)
+	}
+	changes, err := api.GetFilterChanges(m.Ctx, hashesFilter)
+	require.NoError(err)
+	require.Empty(changes)
+
+	// only the newest transactions are buffered between polls
+	addTxs(txs)
+	require.Equal([]any{txs[2].Hash(), txs[3].Hash(), txs[4].Hash()}, poll(hashesFilter, 3))
+
+	ok, err := api.UninstallFilter(m.Ctx, hashesFilter)
+	require.NoError(err)
+	require.True(ok)
+	_, err = api.GetFilterChanges(m.Ctx, hashesFilter)
+	require.ErrorIs(err, rpchelper.ErrFilterNotFound)
+}
+
+func TestBlockFilterReorg(t *testing.T) {
+	m, require := mock.Mock(t), require.New(t)
+	ff := rpchelper.New(m.Ctx, rpchelper.DefaultFiltersConfig, nil, nil, nil, func() {}, m.Log)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(ff, stateCache, m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, log.New())
+
+	// both branches share block 1
+	generate := func(n int, coinbase common.Address) *core.ChainPack {
+		branch, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, n, func(i int, b *core.BlockGen) {
+			if i > 0 {
+				b.SetCoinbase(coinbase)
+			}
+		})
+		require.NoError(err)
+		return branch
+	}
+	oldBranch, newBranch := generate(3, common.Address{1}), generate(4, common.Address{2})
+	require.Equal(oldBranch.Blocks[0].Hash(), newBranch.Blocks[0].Hash())
+	hashes := func(blocks []*types.Block) []any {
+		var hashes []any
+		for _, block := range blocks {
+			hashes = append(hashes, block.Hash())
+		}
+		return hashes
+	}
+
+	id, err := api.NewBlockFilter(m.Ctx)
+	require.NoError(err)
+	changes, err := api.GetFilterChanges(m.Ctx, id)
+	require.NoError(err)
+	require.Empty(changes)
+
+	require.NoError(m.InsertChain(oldBranch))
+	changes, err = api.GetFilterChanges(m.Ctx, id)
+	require.NoError(err)
+	require.Equal(hashes(oldBranch.Blocks), changes)
+
+	// the blocks replacing the reported ones are reported from the fork point, in order
+	require.NoError(m.InsertChain(newBranch))
+	changes, err = api.GetFilterChanges(m.Ctx, id)
+	require.NoError(err)
+	require.Equal(hashes(newBranch.Blocks[1:]), changes)
+
+	changes, err = api.GetFilterChanges(m.Ctx, id)
+	require.NoError(err)
+	require.Empty(changes)
+}
+
 func TestLogsSubscribeAndUnsubscribe_WithoutConcurrentMapIssue(t *testing.T) {
 	m := mock.Mock(t)
 	ctx, conn := rpcdaemontest.CreateTestGrpcConn(t, m)
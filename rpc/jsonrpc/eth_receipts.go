@@ -20,8 +20,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sync/atomic"
+
 	"github.com/RoaringBitmap/roaring/v2"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/erigontech/erigon-db/rawdb"
 	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/kv"
@@ -29,16 +34,25 @@ import (
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/kv/stream"
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/eth/ethutils"
 	"github.com/erigontech/erigon/eth/filters"
-	"github.com/erigontech/erigon/execution/exec3"
 	bortypes "github.com/erigontech/erigon/polygon/bor/types"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/rpchelper"
 )
 
+var (
+	getLogsBloomChecked        = metrics.GetOrCreateCounter("rpc_getlogs_bloom_checked")
+	getLogsBloomSkipped        = metrics.GetOrCreateCounter("rpc_getlogs_bloom_skipped")
+	getLogsBloomFalsePositives = metrics.GetOrCreateCounter("rpc_getlogs_bloom_false_positives")
+
+	receiptsServedFromSnapshots = metrics.GetOrCreateCounter(`receipts_served{api="rpc",source="snapshot"}`)
+	receiptsServedRegenerated   = metrics.GetOrCreateCounter(`receipts_served{api="rpc",source="regenerated"}`)
+)
+
 // getReceipts - checking in-mem cache, or else fallback to db, or else fallback to re-exec of block to re-gen receipts
 func (api *BaseAPI) getReceipts(ctx context.Context, tx kv.TemporalTx, block *types.Block) (types.Receipts, error) {
 	chainConfig, err := api.chainConfig(ctx, tx)
@@ -61,10 +75,6 @@ func (api *BaseAPI) getCachedReceipt(ctx context.Context, hash common.Hash) (*ty
 	return api.receiptsGenerator.GetCachedReceipt(ctx, hash)
 }
 
-func (api *BaseAPI) getCachedReceipts(ctx context.Context, hash common.Hash) (types.Receipts, bool) {
-	return api.receiptsGenerator.GetCachedReceipts(ctx, hash)
-}
-
 // GetLogs implements eth_getLogs. Returns an array of logs matching a given filter object.
 func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) (types.Logs, error) {
 	var begin, end uint64
@@ -141,7 +151,7 @@ func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) (t
 		end = latest
 	}
 
-	erigonLogs, err := api.getLogsV3(ctx, tx, begin, end, crit)
+	erigonLogs, err := api.getLogsV3(ctx, api.db, tx, begin, end, crit)
 	if err != nil {
 		return nil, err
 	}
@@ -229,9 +239,9 @@ func applyFiltersV3(txNumsReader rawdbv3.TxNumsReader, tx kv.TemporalTx, begin,
 	return out, nil
 }
 
-func (api *BaseAPI) getLogsV3(ctx context.Context, tx kv.TemporalTx, begin, end uint64, crit filters.FilterCriteria) ([]*types.ErigonLog, error) {
-	logs := []*types.ErigonLog{} //nolint
-
+// getLogsV3 returns the logs matching crit in [begin, end]. The candidate blocks found by the indices whose logs bloom
+// may match are processed by up to getLogsCfg.Workers workers and their logs are returned in order.
+func (api *BaseAPI) getLogsV3(ctx context.Context, db kv.TemporalRoDB, tx kv.TemporalTx, begin, end uint64, crit filters.FilterCriteria) ([]*types.ErigonLog, error) {
 	addrMap := make(map[common.Address]struct{}, len(crit.Addresses))
 	for _, v := range crit.Addresses {
 		addrMap[v] = struct{}{}
@@ -241,105 +251,321 @@ func (api *BaseAPI) getLogsV3(ctx context.Context, tx kv.TemporalTx, begin, end
 	if err != nil {
 		return nil, err
 	}
-	exec := exec3.NewTraceWorker(tx, chainConfig, api.engine(), api._blockReader, nil)
-	defer exec.Close()
-
-	//var blockHash common.Hash
-	var header *types.Header
 
 	txNumbers, err := applyFiltersV3(api._txNumReader, tx, begin, end, crit, order.Asc)
 	if err != nil {
-		return logs, err
+		return []*types.ErigonLog{}, err
 	}
 
 	it := rawdbv3.TxNums2BlockNums(tx, api._txNumReader, txNumbers, order.Asc)
 	defer it.Close()
 
-	for it.HasNext() {
-		if err = ctx.Err(); err != nil {
+	var blocks []*logsBlock
+	if err := forEachLogsBlock(ctx, it, func(block *logsBlock) error {
+		blocks = append(blocks, block)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if blocks, err = api.bloomPrefilter(ctx, tx, blocks, crit, chainConfig.Bor != nil); err != nil {
+		return nil, err
+	}
+	if chainConfig.Bor != nil {
+		if blocks, err = api.borEventsPrefilter(ctx, tx, blocks); err != nil {
 			return nil, err
 		}
-		txNum, blockNum, txIndex, isFinalTxn, blockNumChanged, err := it.Next()
+	}
+
+	q := &logsQuery{chainConfig: chainConfig, addrMap: addrMap, topics: crit.Topics, limiter: newLogsLimiter(api.getLogsCfg.MaxResults)}
+	if workers := min(api.getLogsCfg.Workers, len(blocks)); workers > 1 && db != nil {
+		err = api.parallelBlockLogs(ctx, db, tx, q, blocks, workers)
+	} else {
+		for _, block := range blocks {
+			if err = api.blockLogs(ctx, tx, q, block); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(crit.Addresses) > 0 || len(crit.Topics) > 0 {
+		for _, block := range blocks {
+			if len(block.logs) == 0 {
+				getLogsBloomFalsePositives.Inc()
+			}
+		}
+	}
+	return concatBlockLogs(blocks), nil
+}
+
+// bloomPrefilter reads the headers of the candidate blocks and drops the ones whose logs bloom can't match crit, so
+// that their receipts aren't read. On Polygon the logs of the state sync events aren't in the bloom, so the final
+// txn of a block is kept.
+func (api *BaseAPI) bloomPrefilter(ctx context.Context, tx kv.TemporalTx, blocks []*logsBlock, crit filters.FilterCriteria, bor bool) ([]*logsBlock, error) {
+	filtered := blocks[:0]
+	for _, block := range blocks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		header, err := api._blockReader.HeaderByNumber(ctx, tx, block.blockNum)
 		if err != nil {
 			return nil, err
 		}
-		if isFinalTxn {
-			if chainConfig.Bor != nil {
-				if header == nil {
-					header, err = api._blockReader.HeaderByNumber(ctx, tx, blockNum)
-					if err != nil {
-						return nil, err
-					}
+		block.header = header
+		if header != nil && (len(crit.Addresses) > 0 || len(crit.Topics) > 0) {
+			getLogsBloomChecked.Inc()
+			if !filters.BloomMatches(header.Bloom, crit.Addresses, crit.Topics) {
+				getLogsBloomSkipped.Inc()
+				if !bor {
+					continue
 				}
-				// check for state sync event logs
-				events, err := api.stateSyncEvents(ctx, tx, header.Hash(), blockNum, chainConfig)
-				if err != nil {
-					return logs, err
+				block.txns = slices.DeleteFunc(block.txns, func(c logsCandidate) bool { return !c.isFinalTxn })
+				if len(block.txns) == 0 {
+					continue
 				}
+			}
+		}
+		filtered = append(filtered, block)
+	}
+	return filtered, nil
+}
 
-				if len(events) == 0 {
+// borEventsPrefilter drops the final txn of the candidate blocks without state sync events, resolving their bor txn
+// hashes in one batch, so that the events of each block aren't read to find most of them empty.
+func (api *BaseAPI) borEventsPrefilter(ctx context.Context, tx kv.TemporalTx, blocks []*logsBlock) ([]*logsBlock, error) {
+	isFinalTxn := func(c logsCandidate) bool { return c.isFinalTxn }
+	txnHashes := make([]common.Hash, len(blocks))
+	var batch []common.Hash
+	for i, block := range blocks {
+		// the blocks without a header are left as they are, to be reported when processed
+		if block.header != nil && slices.ContainsFunc(block.txns, isFinalTxn) {
+			txnHashes[i] = bortypes.ComputeBorTxHash(block.blockNum, block.header.Hash())
+			batch = append(batch, txnHashes[i])
+		}
+	}
+	if len(batch) == 0 {
+		return blocks, nil
+	}
+	withEvents, ok, err := api.borEventTxnsLookup(ctx, tx, batch)
+	if err != nil || !ok {
+		return blocks, err
+	}
+
+	filtered := blocks[:0]
+	for i, block := range blocks {
+		if txnHashes[i] != (common.Hash{}) {
+			if _, ok := withEvents[txnHashes[i]]; !ok {
+				block.txns = slices.DeleteFunc(block.txns, isFinalTxn)
+				if len(block.txns) == 0 {
 					continue
 				}
+			}
+		}
+		filtered = append(filtered, block)
+	}
+	return filtered, nil
+}
+
+// parallelBlockLogs processes blocks with workers goroutines. The calling goroutine is one of them and uses the
+// transaction of the query, so the query progresses even when no other read transaction is available: the other
+// workers wait for theirs only until all the blocks are taken. A block that isn't canonical any more in the view of
+// the transaction of a worker, because of a reorg since the query began, is left to the calling goroutine.
+func (api *BaseAPI) parallelBlockLogs(ctx context.Context, db kv.TemporalRoDB, tx kv.TemporalTx, q *logsQuery, blocks []*logsBlock, workers int) error {
+	for _, block := range blocks {
+		hash, ok, err := api._blockReader.CanonicalHash(ctx, tx, block.blockNum)
+		if err != nil {
+			return err
+		}
+		if ok {
+			block.hash = hash
+		}
+	}
 
-				borLogs, err := api.borReceiptGenerator.GenerateBorLogs(ctx, events, api._txNumReader, tx, header, chainConfig, txIndex, txNum)
+	g, gctx := errgroup.WithContext(ctx)
+	beginCtx, stopBegin := context.WithCancel(gctx)
+	defer stopBegin()
+	var next atomic.Int64
+	take := func() *logsBlock {
+		if i := next.Add(1) - 1; i < int64(len(blocks)) && gctx.Err() == nil {
+			return blocks[i]
+		}
+		return nil
+	}
+
+	stale := make([][]*logsBlock, workers)
+	for w := 1; w < workers; w++ {
+		g.Go(func() error {
+			wtx, err := db.BeginTemporalRo(beginCtx)
+			if err != nil {
+				return nil // the other workers take its share
+			}
+			defer wtx.Rollback()
+			for block := take(); block != nil; block = take() {
+				hash, ok, err := api._blockReader.CanonicalHash(gctx, wtx, block.blockNum)
 				if err != nil {
-					return logs, err
+					return err
 				}
-
-				borLogs = borLogs.Filter(addrMap, crit.Topics, 0)
-				for _, filteredLog := range borLogs {
-					logs = append(logs, &types.ErigonLog{
-						Address:     filteredLog.Address,
-						Topics:      filteredLog.Topics,
-						Data:        filteredLog.Data,
-						BlockNumber: filteredLog.BlockNumber,
-						TxHash:      filteredLog.TxHash,
-						TxIndex:     filteredLog.TxIndex,
-						BlockHash:   filteredLog.BlockHash,
-						Index:       filteredLog.Index,
-						Removed:     filteredLog.Removed,
-						Timestamp:   header.Time,
-					})
+				if !ok || hash != block.hash {
+					stale[w] = append(stale[w], block)
+					continue
+				}
+				if err := api.blockLogs(gctx, wtx, q, block); err != nil {
+					return err
 				}
 			}
+			return nil
+		})
+	}
 
-			continue
+	var err error
+	for block := take(); block != nil && err == nil; block = take() {
+		err = api.blockLogs(ctx, tx, q, block)
+	}
+	stopBegin()
+	if werr := g.Wait(); werr != nil && err == nil {
+		err = werr
+	}
+	if err != nil {
+		return err
+	}
+	// take gives up on the blocks left when ctx is canceled, even if no worker noticed it
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, staleBlocks := range stale {
+		for _, block := range staleBlocks {
+			if err := api.blockLogs(ctx, tx, q, block); err != nil {
+				return err
+			}
 		}
+	}
+	return nil
+}
 
-		// if block number changed, calculate all related field
+// logsQuery is what the workers of a getLogs query share.
+type logsQuery struct {
+	chainConfig *chain.Config
+	addrMap     map[common.Address]struct{}
+	topics      [][]common.Hash
+	limiter     *logsLimiter
+}
 
-		if blockNumChanged {
-			if header, err = api._blockReader.HeaderByNumber(ctx, tx, blockNum); err != nil {
-				return nil, err
-			}
-			if header == nil {
-				log.Warn("[rpc] header is nil", "blockNum", blockNum)
-				continue
-			}
-			//blockHash = header.Hash()
-			exec.ChangeBlock(header)
-		}
+// logsBlock is a candidate block of a getLogs query: the candidate transactions found in it by the indices, and the
+// logs of them matching the query once processed.
+type logsBlock struct {
+	blockNum uint64
+	hash     common.Hash   // canonical hash in the view of the transaction of the query, set for parallel processing
+	header   *types.Header // read by the bloom prefilter
+	txns     []logsCandidate
+	logs     []*types.ErigonLog
+}
+
+type logsCandidate struct {
+	txNum      uint64
+	txIndex    int
+	isFinalTxn bool
+}
 
-		//fmt.Printf("txNum=%d, blockNum=%d, txIndex=%d, maxTxNumInBlock=%d,mixTxNumInBlock=%d\n", txNum, blockNum, txIndex, maxTxNumInBlock, minTxNumInBlock)
-		txn, err := api._txnReader.TxnByIdxInBlock(ctx, tx, blockNum, txIndex)
+// forEachLogsBlock groups the candidate transactions of it by block, in order.
+func forEachLogsBlock(ctx context.Context, it *rawdbv3.MapTxNum2BlockNumIter, f func(*logsBlock) error) error {
+	var block *logsBlock
+	for it.HasNext() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		txNum, blockNum, txIndex, isFinalTxn, _, err := it.Next()
 		if err != nil {
-			return nil, err
+			return err
 		}
-		if txn == nil {
-			continue
+		if block != nil && block.blockNum != blockNum {
+			if err := f(block); err != nil {
+				return err
+			}
+			block = nil
+		}
+		if block == nil {
+			block = &logsBlock{blockNum: blockNum}
 		}
+		block.txns = append(block.txns, logsCandidate{txNum: txNum, txIndex: txIndex, isFinalTxn: isFinalTxn})
+	}
+	if block != nil {
+		return f(block)
+	}
+	return nil
+}
 
-		r, err := api.receiptsGenerator.GetReceipt(ctx, chainConfig, tx, header, txn, txIndex, txNum)
-		if err != nil {
-			return nil, err
+func concatBlockLogs(blocks []*logsBlock) []*types.ErigonLog {
+	var n int
+	for _, block := range blocks {
+		n += len(block.logs)
+	}
+	logs := make([]*types.ErigonLog, 0, n)
+	for _, block := range blocks {
+		logs = append(logs, block.logs...)
+	}
+	return logs
+}
+
+// blockLogs collects the logs of the candidate transactions of block matching the query.
+func (api *BaseAPI) blockLogs(ctx context.Context, tx kv.TemporalTx, q *logsQuery, block *logsBlock) error {
+	header := block.header
+	if header == nil {
+		var err error
+		if header, err = api._blockReader.HeaderByNumber(ctx, tx, block.blockNum); err != nil {
+			return err
 		}
-		if r == nil {
-			return nil, err
+	}
+	if header == nil {
+		log.Warn("[rpc] header is nil", "blockNum", block.blockNum)
+		return nil
+	}
+
+	for _, c := range block.txns {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var filtered types.Logs
+		if c.isFinalTxn {
+			if q.chainConfig.Bor == nil {
+				continue
+			}
+			// check for state sync event logs
+			events, err := api.stateSyncEvents(ctx, tx, header.Hash(), block.blockNum, q.chainConfig)
+			if err != nil {
+				return err
+			}
+			if len(events) == 0 {
+				continue
+			}
+			borLogs, err := api.borReceiptGenerator.GenerateBorLogs(ctx, events, api._txNumReader, tx, header, q.chainConfig, c.txIndex, c.txNum)
+			if err != nil {
+				return err
+			}
+			filtered = borLogs.Filter(q.addrMap, q.topics, 0)
+		} else {
+			txn, err := api._txnReader.TxnByIdxInBlock(ctx, tx, block.blockNum, c.txIndex)
+			if err != nil {
+				return err
+			}
+			if txn == nil {
+				continue
+			}
+			r, err := api.receiptsGenerator.GetReceipt(ctx, q.chainConfig, tx, header, txn, c.txIndex, c.txNum)
+			if err != nil {
+				return err
+			}
+			if r == nil {
+				return fmt.Errorf("no receipt for txn %d of block %d", c.txIndex, block.blockNum)
+			}
+			filtered = r.Logs.Filter(q.addrMap, q.topics, 0)
 		}
-		filtered := r.Logs.Filter(addrMap, crit.Topics, 0)
 
+		if err := q.limiter.add(filtered); err != nil {
+			return err
+		}
 		for _, filteredLog := range filtered {
-			logs = append(logs, &types.ErigonLog{
+			block.logs = append(block.logs, &types.ErigonLog{
 				Address:     filteredLog.Address,
 				Topics:      filteredLog.Topics,
 				Data:        filteredLog.Data,
@@ -353,8 +579,25 @@ func (api *BaseAPI) getLogsV3(ctx context.Context, tx kv.TemporalTx, begin, end
 			})
 		}
 	}
+	return nil
+}
 
-	return logs, nil
+// logsLimiter enforces the maximum number of results across the workers of a query. The limit applies to the
+// whole result, so the order in which the blocks are processed doesn't matter.
+type logsLimiter struct {
+	maxResults int64
+	results    atomic.Int64
+}
+
+func newLogsLimiter(maxResults int) *logsLimiter {
+	return &logsLimiter{maxResults: int64(maxResults)}
+}
+
+func (l *logsLimiter) add(logs types.Logs) error {
+	if l.maxResults > 0 && len(logs) > 0 && l.results.Add(int64(len(logs))) > l.maxResults {
+		return fmt.Errorf("query returned more than %d results", l.maxResults)
+	}
+	return nil
 }
 
 // The Topic list restricts matches to particular event topics. Each event has a list
@@ -529,9 +772,18 @@ func (api *APIImpl) GetBlockReceipts(ctx context.Context, numberOrHash rpc.Block
 	if err != nil {
 		return nil, err
 	}
-	receipts, err := api.getReceipts(ctx, tx, block)
+	// the deep blocks have their receipts in the snapshot files, no need to re-execute them
+	receipts, frozen, err := rawdb.ReadFrozenReceipts(tx, block, api._txNumReader)
 	if err != nil {
-		return nil, fmt.Errorf("getReceipts error: %w", err)
+		return nil, err
+	}
+	if frozen {
+		receiptsServedFromSnapshots.Inc()
+	} else {
+		if receipts, err = api.getReceipts(ctx, tx, block); err != nil {
+			return nil, fmt.Errorf("getReceipts error: %w", err)
+		}
+		receiptsServedRegenerated.Inc()
 	}
 	result := make([]map[string]interface{}, 0, len(receipts))
 	for _, receipt := range receipts {
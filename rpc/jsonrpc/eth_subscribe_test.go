@@ -18,23 +18,46 @@ package jsonrpc
 
 import (
 	"context"
+	"encoding/json"
+	"math/big"
+	"net"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/chain/params"
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/empty"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/crypto"
 	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	"github.com/erigontech/erigon-lib/gointerfaces/grpcutil"
+	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
 	sentry "github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
+	txpool "github.com/erigontech/erigon-lib/gointerfaces/txpoolproto"
+	"github.com/erigontech/erigon-lib/kv/kvcache"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon-lib/wrap"
 	"github.com/erigontech/erigon/cmd/rpcdaemon/rpcservices"
 	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/eth/filters"
 	"github.com/erigontech/erigon/execution/builder"
 	"github.com/erigontech/erigon/execution/stages"
 	"github.com/erigontech/erigon/execution/stages/mock"
 	"github.com/erigontech/erigon/p2p/protocols/eth"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/rpc/ethapi"
+	"github.com/erigontech/erigon/rpc/rpccfg"
 	"github.com/erigontech/erigon/rpc/rpchelper"
 	"github.com/erigontech/erigon/turbo/privateapi"
 )
@@ -60,7 +83,7 @@ func TestEthSubscribe(t *testing.T) {
 
 	ctx := context.Background()
 	logger := log.New()
-	backendServer := privateapi.NewEthBackendServer(ctx, nil, m.DB, m.Notifications, m.BlockReader, logger, builder.NewLatestBlockBuiltStore(), nil)
+	backendServer := privateapi.NewEthBackendServer(ctx, nil, m.DB, m.Notifications, m.BlockReader, logger, builder.NewLatestBlockBuiltStore(), nil, privateapi.SubscribeConfig{})
 	backendClient := direct.NewEthBackendClientDirect(backendServer)
 	backend := rpcservices.NewRemoteBackend(backendClient, m.DB, m.BlockReader)
 	// Creating a new filter will set up new internal subscription channels actively managed by subscription tasks.
@@ -90,3 +113,580 @@ func TestEthSubscribe(t *testing.T) {
 		require.Equal(i, header.Number.Uint64())
 	}
 }
+
+func TestEthSubscribeBackendRestart(t *testing.T) {
+	m, require := mock.Mock(t), require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := log.New()
+	backendServer := privateapi.NewEthBackendServer(ctx, nil, m.DB, m.Notifications, m.BlockReader, logger, builder.NewLatestBlockBuiltStore(), nil, privateapi.SubscribeConfig{})
+
+	serve := func(addr string) (*grpc.Server, string) {
+		lis, err := net.Listen("tcp", addr)
+		require.NoError(err)
+		server := grpc.NewServer()
+		remote.RegisterETHBACKENDServer(server, backendServer)
+		go server.Serve(lis) //nolint:errcheck
+		return server, lis.Addr().String()
+	}
+	server, addr := serve("127.0.0.1:0")
+
+	conn, err := grpcutil.Connect(nil, addr)
+	require.NoError(err)
+	defer conn.Close()
+	backend := rpcservices.NewRemoteBackend(remote.NewETHBACKENDClient(conn), m.DB, m.BlockReader)
+	backend.SetConnection(conn)
+
+	snapshots := make(chan struct{}, 8)
+	onNewSnapshot := func() {
+		select {
+		case snapshots <- struct{}{}:
+		default:
+		}
+	}
+	ff := rpchelper.New(ctx, rpchelper.DefaultFiltersConfig, backend, nil, nil, onNewSnapshot, m.Log)
+	<-snapshots
+
+	newHeads, id := ff.SubscribeNewHeads(16)
+	defer ff.UnsubscribeHeads(id)
+
+	sendHeader := func(number int64) {
+		headerRlp, err := rlp.EncodeToBytes(&types.Header{Number: big.NewInt(number)})
+		require.NoError(err)
+		m.Notifications.Events.OnNewHeader([][]byte{headerRlp})
+	}
+	sendHeader(1)
+	require.Equal(uint64(1), (<-newHeads).Number.Uint64())
+	require.True(backend.Health(ctx).Connected)
+
+	server.Stop()
+	require.Eventually(func() bool { return !backend.Health(ctx).Connected }, 10*time.Second, 50*time.Millisecond)
+	_, err = backend.Syncing(ctx)
+	require.ErrorIs(err, rpchelper.ErrBackendUnavailable)
+
+	// the filters resubscribe once the backend is back, which replays the snapshot notification
+	server, _ = serve(addr)
+	defer server.Stop()
+	select {
+	case <-snapshots:
+	case <-time.After(30 * time.Second):
+		t.Fatal("no resubscription after the backend restart")
+	}
+	require.True(backend.Health(ctx).Connected)
+
+	sendHeader(2)
+	require.Equal(uint64(2), (<-newHeads).Number.Uint64())
+}
+
+// This test checks that a heads subscription gets the blocks inserted while the backend was down, once the filters
+// resubscribe to it.
+func TestEthSubscribeBackendRestartCatchUp(t *testing.T) {
+	m, require := mock.Mock(t), require.New(t)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 4, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+	})
+	require.NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := log.New()
+	backendServer := privateapi.NewEthBackendServer(ctx, nil, m.DB, m.Notifications, m.BlockReader, logger, builder.NewLatestBlockBuiltStore(), nil, privateapi.SubscribeConfig{})
+
+	serve := func(addr string) (*grpc.Server, string) {
+		lis, err := net.Listen("tcp", addr)
+		require.NoError(err)
+		server := grpc.NewServer()
+		remote.RegisterETHBACKENDServer(server, backendServer)
+		go server.Serve(lis) //nolint:errcheck
+		return server, lis.Addr().String()
+	}
+	server, addr := serve("127.0.0.1:0")
+
+	conn, err := grpcutil.Connect(nil, addr)
+	require.NoError(err)
+	defer conn.Close()
+	backend := rpcservices.NewRemoteBackend(remote.NewETHBACKENDClient(conn), m.DB, m.BlockReader)
+	backend.SetConnection(conn)
+
+	snapshots := make(chan struct{}, 8)
+	onNewSnapshot := func() {
+		select {
+		case snapshots <- struct{}{}:
+		default:
+		}
+	}
+	ff := rpchelper.New(ctx, rpchelper.DefaultFiltersConfig, backend, nil, nil, onNewSnapshot, m.Log)
+	<-snapshots
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(ff, stateCache, m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, logger)
+	ff.SetChainReader(&filtersChainReader{api: api})
+
+	newHeads, id := ff.SubscribeNewHeads(16)
+	defer ff.UnsubscribeHeads(id)
+	nextHead := func() *types.Header {
+		select {
+		case header := <-newHeads:
+			return header
+		case <-time.After(30 * time.Second):
+			t.Fatal("no new head")
+			return nil
+		}
+	}
+
+	require.NoError(m.InsertChain(chain.Slice(0, 1)))
+	require.Equal(uint64(1), nextHead().Number.Uint64())
+
+	server.Stop()
+	require.Eventually(func() bool { return !backend.Health(ctx).Connected }, 10*time.Second, 50*time.Millisecond)
+	require.NoError(m.InsertChain(chain.Slice(1, 3)))
+
+	server, _ = serve(addr)
+	defer server.Stop()
+	select {
+	case <-snapshots:
+	case <-time.After(30 * time.Second):
+		t.Fatal("no resubscription after the backend restart")
+	}
+	require.NoError(m.InsertChain(chain.Slice(3, 4)))
+
+	for i := 2; i <= 4; i++ {
+		header := nextHead()
+		require.Equal(uint64(i), header.Number.Uint64())
+		require.Equal(chain.Headers[i-1].Hash(), header.Hash())
+	}
+	select {
+	case header := <-newHeads:
+		t.Fatalf("unexpected head %d", header.Number.Uint64())
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestEthSubscribeNewHeadsCancunFields(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	gspec := &types.Genesis{
+		Config:     chain.AllProtocolChanges,
+		GasLimit:   30_000_000,
+		Difficulty: big.NewInt(1),
+		Alloc:      types.GenesisAlloc{crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(common.Ether)}},
+	}
+	m, require := mock.MockWithGenesis(t, gspec, key, false), require.New(t)
+	logger := log.New()
+
+	backendServer := privateapi.NewEthBackendServer(m.Ctx, nil, m.DB, m.Notifications, m.BlockReader, logger, builder.NewLatestBlockBuiltStore(), nil, privateapi.SubscribeConfig{})
+	backend := rpcservices.NewRemoteBackend(direct.NewEthBackendClientDirect(backendServer), m.DB, m.BlockReader)
+	subscriptionReadyWg := sync.WaitGroup{}
+	subscriptionReadyWg.Add(1)
+	ff := rpchelper.New(m.Ctx, rpchelper.DefaultFiltersConfig, backend, nil, nil, subscriptionReadyWg.Done, m.Log)
+	subscriptionReadyWg.Wait()
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(ff, stateCache, m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, logger)
+
+	server := rpc.NewServer(50, false /* traceRequests */, false /* debugSingleRequests */, true, logger, 100)
+	require.NoError(server.RegisterName("eth", api))
+	client := rpc.DialInProc(server, logger)
+	defer client.Close()
+	heads := make(chan map[string]any, 2)
+	sub, err := client.EthSubscribe(m.Ctx, heads, "newHeads")
+	require.NoError(err)
+	defer sub.Unsubscribe()
+
+	// a post-Cancun header, built on the genesis of the mock chain
+	genesis := m.Genesis.Header()
+	require.NotNil(genesis.ExcessBlobGas)
+	cancun := core.MakeEmptyHeader(genesis, m.ChainConfig, genesis.Time+12, nil)
+	withdrawalsRoot := empty.RootHash
+	cancun.WithdrawalsHash = &withdrawalsRoot
+	cancun.ParentBeaconBlockRoot = &common.Hash{0xbe}
+	*cancun.BlobGasUsed = 3 * params.GasPerBlob
+	frontier := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1)}
+	for _, header := range []*types.Header{cancun, frontier} {
+		headerRlp, err := rlp.EncodeToBytes(header)
+		require.NoError(err)
+		m.Notifications.Events.OnNewHeader([][]byte{headerRlp})
+	}
+
+	notified := <-heads
+	require.Equal(cancun.Hash().Hex(), notified["hash"])
+	require.Equal(empty.RootHash.Hex(), notified["withdrawalsRoot"])
+	require.Equal(hexutil.Uint64(*cancun.BlobGasUsed).String(), notified["blobGasUsed"])
+	require.Equal(hexutil.Uint64(*cancun.ExcessBlobGas).String(), notified["excessBlobGas"])
+	require.Equal(common.Hash{0xbe}.Hex(), notified["parentBeaconBlockRoot"])
+	require.Equal((*hexutil.Big)(cancun.BaseFee).String(), notified["baseFeePerGas"])
+
+	// the fields of the forks not active at the block are omitted
+	notified = <-heads
+	require.Equal(frontier.Hash().Hex(), notified["hash"])
+	for _, field := range []string{"baseFeePerGas", "withdrawalsRoot", "blobGasUsed", "excessBlobGas", "parentBeaconBlockRoot", "requestsHash"} {
+		require.NotContains(notified, field)
+	}
+}
+
+// countingLogsClient counts the logs the backend transmits on the logs streams.
+type countingLogsClient struct {
+	remote.ETHBACKENDClient
+	received atomic.Int64
+}
+
+func (c *countingLogsClient) SubscribeLogs(ctx context.Context, opts ...grpc.CallOption) (remote.ETHBACKEND_SubscribeLogsClient, error) {
+	stream, err := c.ETHBACKENDClient.SubscribeLogs(ctx, opts...)
+	return &countingLogsStream{stream, c}, err
+}
+
+type countingLogsStream struct {
+	remote.ETHBACKEND_SubscribeLogsClient
+	client *countingLogsClient
+}
+
+func (s *countingLogsStream) Recv() (*remote.SubscribeLogsReply, error) {
+	reply, err := s.ETHBACKEND_SubscribeLogsClient.Recv()
+	if err == nil {
+		s.client.received.Add(1)
+	}
+	return reply, err
+}
+
+func TestEthSubscribeLogsServerSideFiltering(t *testing.T) {
+	m, require := mock.Mock(t), require.New(t)
+	backendServer := privateapi.NewEthBackendServer(m.Ctx, nil, m.DB, m.Notifications, m.BlockReader, m.Log, builder.NewLatestBlockBuiltStore(), nil, privateapi.SubscribeConfig{})
+	client := &countingLogsClient{ETHBACKENDClient: direct.NewEthBackendClientDirect(backendServer)}
+	backend := rpcservices.NewRemoteBackend(client, m.DB, m.BlockReader)
+	ff := rpchelper.New(m.Ctx, rpchelper.DefaultFiltersConfig, backend, nil, nil, func() {}, m.Log)
+
+	matching, other := common.Address{0xaa}, common.Address{0xbb}
+	newLog := func(address common.Address) *remote.SubscribeLogsReply {
+		return &remote.SubscribeLogsReply{
+			Address:         gointerfaces.ConvertAddressToH160(address),
+			BlockHash:       gointerfaces.ConvertHashToH256(common.Hash{1}),
+			TransactionHash: gointerfaces.ConvertHashToH256(common.Hash{2}),
+		}
+	}
+
+	// the filter may be subscribed before the logs stream is open, the stream is sent it once open
+	logs, id := ff.SubscribeLogs(16, filters.FilterCriteria{Addresses: []common.Address{matching}})
+	require.Eventually(m.Notifications.Events.HasLogSubscriptions, 10*time.Second, 10*time.Millisecond)
+
+	m.Notifications.Events.OnLogs([]*remote.SubscribeLogsReply{newLog(other), newLog(other), newLog(matching)})
+	require.Equal(matching, (<-logs).Address)
+	require.Equal(int64(1), client.received.Load())
+
+	// the filter is updated mid-stream when the local subscriptions change
+	otherLogs, otherID := ff.SubscribeLogs(16, filters.FilterCriteria{Addresses: []common.Address{other}})
+	require.Eventually(func() bool {
+		m.Notifications.Events.OnLogs([]*remote.SubscribeLogsReply{newLog(other)})
+		select {
+		case l := <-otherLogs:
+			return l.Address == other
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Empty(logs)
+
+	// once no local subscription is left, nothing is transmitted anymore
+	ff.UnsubscribeLogs(id)
+	ff.UnsubscribeLogs(otherID)
+	require.Eventually(func() bool { return !m.Notifications.Events.HasLogSubscriptions() }, 10*time.Second, 10*time.Millisecond)
+	received := client.received.Load()
+	m.Notifications.Events.OnLogs([]*remote.SubscribeLogsReply{newLog(matching), newLog(other)})
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(received, client.received.Load())
+}
+
+func TestEthSubscribeNewPendingTransactions(t *testing.T) {
+	m, require := mock.Mock(t), require.New(t)
+	logger := log.New()
+	// only the newest transaction of a batch is notified, in both modes
+	config := rpchelper.DefaultFiltersConfig
+	config.RpcSubscriptionFiltersMaxTxs = 1
+	ff := rpchelper.New(m.Ctx, config, nil, nil, nil, func() {}, m.Log)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(ff, stateCache, m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, logger)
+
+	server := rpc.NewServer(50, false /* traceRequests */, false /* debugSingleRequests */, true, logger, 100)
+	require.NoError(server.RegisterName("eth", api))
+	client := rpc.DialInProc(server, logger)
+	defer client.Close()
+
+	hashes := make(chan common.Hash, 2)
+	hashSub, err := client.EthSubscribe(m.Ctx, hashes, "newPendingTransactions")
+	require.NoError(err)
+	defer hashSub.Unsubscribe()
+	bodies := make(chan *ethapi.RPCTransaction, 2)
+	bodySub, err := client.EthSubscribe(m.Ctx, bodies, "newPendingTransactions", true)
+	require.NoError(err)
+	defer bodySub.Unsubscribe()
+
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	var txs types.Transactions
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		txn, err := types.SignTx(types.NewTransaction(nonce, common.Address{1}, uint256.NewInt(1), params.TxGas, uint256.NewInt(common.GWei), nil), *signer, m.Key)
+		require.NoError(err)
+		txs = append(txs, txn)
+	}
+	rlpTxs, err := types.MarshalTransactionsBinary(txs)
+	require.NoError(err)
+	ff.OnNewTx(&txpool.OnAddReply{RplTxs: rlpTxs})
+
+	txn := txs[1]
+	require.Equal(txn.Hash(), <-hashes)
+	body := <-bodies
+	require.Equal(txn.Hash(), body.Hash)
+	require.Equal(m.Address, body.From)
+	require.Equal(common.Address{1}, *body.To)
+	require.Nil(body.BlockHash)
+	require.Empty(hashes)
+	require.Empty(bodies)
+}
+
+func TestEthSubscribeLogsReorg(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	emitter := common.Address{0xee}
+	gspec := &types.Genesis{
+		Config: chain.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			address: {Balance: big.NewInt(common.Ether)},
+			emitter: {Balance: common.Big0, Code: []byte{0x60, 0x00, 0x60, 0x00, 0xa0}}, // LOG0 with no data
+		},
+	}
+	m, require := mock.MockWithGenesis(t, gspec, key, false), require.New(t)
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	// both branches share block 1
+	generate := func(n int, coinbase common.Address) *core.ChainPack {
+		branch, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, n, func(i int, b *core.BlockGen) {
+			if i > 0 {
+				b.SetCoinbase(coinbase)
+			}
+			txn, err := types.SignTx(types.NewTransaction(b.TxNonce(address), emitter, uint256.NewInt(0), 50_000, uint256.NewInt(common.GWei), nil), *signer, key)
+			require.NoError(err)
+			b.AddTx(txn)
+		})
+		require.NoError(err)
+		return branch
+	}
+	oldBranch, newBranch := generate(3, common.Address{1}), generate(4, common.Address{2})
+	require.Equal(oldBranch.Blocks[0].Hash(), newBranch.Blocks[0].Hash())
+
+	ff := rpchelper.New(m.Ctx, rpchelper.DefaultFiltersConfig, nil, nil, nil, func() {}, m.Log)
+	logs, id := ff.SubscribeLogs(16, filters.FilterCriteria{})
+	defer ff.UnsubscribeLogs(id)
+	m.Notifications.Events.EmptyLogSubscription(false)
+	replies, unsubscribe := m.Notifications.Events.AddLogsSubscription()
+	defer unsubscribe()
+	go func() {
+		for reply := range replies {
+			for _, l := range reply {
+				ff.OnNewLogs(l)
+			}
+		}
+	}()
+
+	expect := func(block *types.Block, removed bool) {
+		t.Helper()
+		l := <-logs
+		require.Equal(emitter, l.Address)
+		require.Equal(block.NumberU64(), l.BlockNumber)
+		require.Equal(block.Hash(), l.BlockHash)
+		require.Equal(removed, l.Removed)
+	}
+
+	require.NoError(m.InsertChain(oldBranch))
+	for _, block := range oldBranch.Blocks {
+		expect(block, false)
+	}
+
+	// the logs of the old branch are removed before the logs of the new branch are added
+	require.NoError(m.InsertChain(newBranch))
+	for _, block := range oldBranch.Blocks[1:] {
+		expect(block, true)
+	}
+	for _, block := range newBranch.Blocks[1:] {
+		expect(block, false)
+	}
+	require.Empty(logs)
+}
+
+func TestEthSubscribeNewFullBlocks(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	gspec := &types.Genesis{
+		Config: chain.TestChainConfig,
+		Alloc:  types.GenesisAlloc{address: {Balance: big.NewInt(common.Ether)}},
+	}
+	m, require := mock.MockWithGenesis(t, gspec, key, false), require.New(t)
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	// both branches share block 1
+	generate := func(n int, coinbase common.Address) *core.ChainPack {
+		branch, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, n, func(i int, b *core.BlockGen) {
+			if i > 0 {
+				b.SetCoinbase(coinbase)
+			}
+			txn, err := types.SignTx(types.NewTransaction(b.TxNonce(address), common.Address{1}, uint256.NewInt(1), params.TxGas, uint256.NewInt(common.GWei), nil), *signer, key)
+			require.NoError(err)
+			b.AddTx(txn)
+		})
+		require.NoError(err)
+		return branch
+	}
+	oldBranch, newBranch := generate(2, common.Address{1}), generate(3, common.Address{2})
+
+	backendServer := privateapi.NewEthBackendServer(m.Ctx, nil, m.DB, m.Notifications, m.BlockReader, m.Log, builder.NewLatestBlockBuiltStore(), nil, privateapi.SubscribeConfig{})
+	backend := rpcservices.NewRemoteBackend(direct.NewEthBackendClientDirect(backendServer), m.DB, m.BlockReader)
+	ff := rpchelper.New(m.Ctx, rpchelper.DefaultFiltersConfig, backend, nil, nil, func() {}, m.Log)
+	blocks, id := ff.SubscribeNewFullBlocks(16)
+	defer ff.UnsubscribeFullBlocks(id)
+	// the first subscription opens the stream: wait for it to be registered, otherwise we could miss the blocks
+	require.Eventually(m.Notifications.Events.HasBlockSubscriptions, 10*time.Second, 10*time.Millisecond)
+
+	require.NoError(m.InsertChain(oldBranch))
+	block := <-blocks
+	expected := oldBranch.Blocks[0]
+	require.Equal(expected.Hash(), block.Block.Hash())
+	require.Len(block.Block.Transactions(), 1)
+	require.Equal(expected.Transactions()[0].Hash(), block.Block.Transactions()[0].Hash())
+	require.False(block.IsReorg())
+	require.Equal(oldBranch.TopBlock.Hash(), (<-blocks).NewHead)
+
+	// the first block of the new branch carries the reorg
+	require.NoError(m.InsertChain(newBranch))
+	block = <-blocks
+	require.Equal(newBranch.Blocks[1].Hash(), block.Block.Hash())
+	require.True(block.IsReorg())
+	require.Equal(oldBranch.TopBlock.Hash(), block.OldHead)
+	require.Equal(newBranch.TopBlock.Hash(), block.NewHead)
+	require.Equal(newBranch.Blocks[0].Hash(), block.CommonAncestor)
+	block = <-blocks
+	require.Equal(newBranch.TopBlock.Hash(), block.Block.Hash())
+	require.False(block.IsReorg())
+}
+
+func TestEthSubscribeSyncing(t *testing.T) {
+	m, require := mock.Mock(t), require.New(t)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 10, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+	})
+	require.NoError(err)
+
+	logger := log.New()
+	backendServer := privateapi.NewEthBackendServer(m.Ctx, nil, m.DB, m.Notifications, m.BlockReader, logger, builder.NewLatestBlockBuiltStore(), nil, privateapi.SubscribeConfig{})
+	backend := rpcservices.NewRemoteBackend(direct.NewEthBackendClientDirect(backendServer), m.DB, m.BlockReader)
+	subscriptionReadyWg := sync.WaitGroup{}
+	subscriptionReadyWg.Add(1)
+	ff := rpchelper.New(m.Ctx, rpchelper.DefaultFiltersConfig, backend, nil, nil, subscriptionReadyWg.Done, m.Log)
+	subscriptionReadyWg.Wait()
+
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(ff, stateCache, m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil), m.DB, backend, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, logger)
+	syncingAPI := NewEthSyncingAPI(api)
+	server := rpc.NewServer(50, false /* traceRequests */, false /* debugSingleRequests */, true, logger, 100)
+	require.NoError(server.RegisterName("eth", syncingAPI))
+	client := rpc.DialInProc(server, logger)
+	defer client.Close()
+
+	// a peer announced the tip of the chain, which is far ahead of the execution progress
+	m.Notifications.NewLastBlockSeen(chain.TopBlock.NumberU64())
+	subscribe := func() (*rpc.ClientSubscription, chan json.RawMessage) {
+		statuses := make(chan json.RawMessage, 4)
+		sub, err := client.EthSubscribe(m.Ctx, statuses, "syncing")
+		require.NoError(err)
+		return sub, statuses
+	}
+	expectSyncing := func(statuses chan json.RawMessage) {
+		var status struct {
+			CurrentBlock hexutil.Uint64 `json:"currentBlock"`
+			HighestBlock hexutil.Uint64 `json:"highestBlock"`
+		}
+		require.NoError(json.Unmarshal(<-statuses, &status))
+		require.Equal(hexutil.Uint64(0), status.CurrentBlock)
+		require.Equal(hexutil.Uint64(chain.TopBlock.NumberU64()), status.HighestBlock)
+	}
+	// each subscriber gets the status when it subscribes, also when the poller is already running
+	sub1, statuses1 := subscribe()
+	expectSyncing(statuses1)
+	sub2, statuses2 := subscribe()
+	expectSyncing(statuses2)
+	require.Empty(statuses1)
+
+	syncingAPI.mu.Lock()
+	require.Len(syncingAPI.subs, 2)
+	require.NotNil(syncingAPI.stopPoller)
+	syncingAPI.mu.Unlock()
+
+	require.NoError(m.InsertChain(chain))
+	require.JSONEq("false", string(<-statuses1))
+	require.JSONEq("false", string(<-statuses2))
+
+	// the poller stops with the last subscriber
+	sub1.Unsubscribe()
+	sub2.Unsubscribe()
+	require.Eventually(func() bool {
+		syncingAPI.mu.Lock()
+		defer syncingAPI.mu.Unlock()
+		return len(syncingAPI.subs) == 0 && syncingAPI.stopPoller == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestEthSubscribeLogsLimitExceeded(t *testing.T) {
+	m, require := mock.Mock(t), require.New(t)
+	logger := log.New()
+	config := rpchelper.DefaultFiltersConfig
+	config.RpcSubscriptionLogsMaxPerBlock = 10
+	ff := rpchelper.New(m.Ctx, config, nil, nil, nil, func() {}, m.Log)
+	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
+	api := NewEthAPI(NewBaseApi(ff, stateCache, m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil), m.DB, nil, nil, nil, 5000000, ethconfig.Defaults.RPCTxFeeCap, 100_000, false, 100_000, 128, logger)
+
+	server := rpc.NewServer(50, false /* traceRequests */, false /* debugSingleRequests */, true, logger, 100)
+	require.NoError(server.RegisterName("eth", api))
+	client := rpc.DialInProc(server, logger)
+	defer client.Close()
+
+	notifications := make(chan json.RawMessage, 100)
+	sub, err := client.EthSubscribe(m.Ctx, notifications, "logs", map[string]any{})
+	require.NoError(err)
+	defer sub.Unsubscribe()
+
+	sendLogs := func(blockNum uint64, n int) {
+		for i := 0; i < n; i++ {
+			ff.OnNewLogs(&remote.SubscribeLogsReply{
+				Address:         gointerfaces.ConvertAddressToH160(common.Address{1}),
+				BlockHash:       gointerfaces.ConvertHashToH256(common.Hash{byte(blockNum)}),
+				BlockNumber:     blockNum,
+				LogIndex:        uint64(i),
+				TransactionHash: gointerfaces.ConvertHashToH256(common.Hash{}),
+			})
+		}
+	}
+	expectLogs := func(blockNum uint64, n int) {
+		for i := 0; i < n; i++ {
+			var lg struct {
+				BlockNumber hexutil.Uint64 `json:"blockNumber"`
+				Index       hexutil.Uint   `json:"logIndex"`
+			}
+			require.NoError(json.Unmarshal(<-notifications, &lg))
+			require.Equal(hexutil.Uint64(blockNum), lg.BlockNumber)
+			require.Equal(hexutil.Uint(i), lg.Index)
+		}
+	}
+
+	// the logs of block 1 beyond the limit are replaced by a single notice, after the ones sent
+	sendLogs(1, 50)
+	expectLogs(1, 10)
+	var notice rpchelper.LogsTruncated
+	require.NoError(json.Unmarshal(<-notifications, &notice))
+	require.True(notice.Truncated)
+	require.Equal(common.Hash{1}, notice.BlockHash)
+	require.Equal(hexutil.Uint64(1), notice.BlockNumber)
+	require.Contains(notice.Reason, "more than 10 logs in block")
+
+	// the subscription is still alive
+	sendLogs(2, 3)
+	expectLogs(2, 3)
+	require.Empty(notifications)
+	select {
+	case err := <-sub.Err():
+		t.Fatalf("subscription closed: %v", err)
+	default:
+	}
+}
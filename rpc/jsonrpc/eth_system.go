@@ -32,6 +32,7 @@ import (
 	"github.com/erigontech/erigon-lib/chain/params"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/hexutil"
+	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/core/vm"
@@ -63,8 +64,13 @@ func (api *APIImpl) Syncing(ctx context.Context) (interface{}, error) {
 	if err != nil {
 		return false, err
 	}
+	return syncingStatus(reply), nil
+}
+
+// syncingStatus returns the result of eth_syncing for the given sync status: false, or the sync progress.
+func syncingStatus(reply *remote.SyncingReply) interface{} {
 	if !reply.Syncing {
-		return false, nil
+		return false
 	}
 
 	// Still sync-ing, gather the block sync stats
@@ -73,19 +79,31 @@ func (api *APIImpl) Syncing(ctx context.Context) (interface{}, error) {
 	type S struct {
 		StageName   string         `json:"stage_name"`
 		BlockNumber hexutil.Uint64 `json:"block_number"`
+		TxNum       hexutil.Uint64 `json:"tx_num"`
 	}
 	stagesMap := make([]S, len(reply.Stages))
 	for i, stage := range reply.Stages {
 		stagesMap[i].StageName = stage.StageName
 		stagesMap[i].BlockNumber = hexutil.Uint64(stage.BlockNumber)
+		stagesMap[i].TxNum = hexutil.Uint64(stage.TxNum)
 	}
 
-	return map[string]interface{}{
+	status := map[string]interface{}{
 		"startingBlock": "0x0", // 0x0 is a placeholder, I do not think it matters what we return here
 		"currentBlock":  hexutil.Uint64(currentBlock),
 		"highestBlock":  hexutil.Uint64(highestBlock),
 		"stages":        stagesMap,
-	}, nil
+	}
+	if reply.SyncMode != "" {
+		status["syncMode"] = reply.SyncMode
+	}
+	if reply.Snapshots != nil {
+		status["snapshots"] = map[string]float32{
+			"download_percent": reply.Snapshots.DownloadPercent,
+			"indexing_percent": reply.Snapshots.IndexingPercent,
+		}
+	}
+	return status
 }
 
 // ChainId implements eth_chainId. Returns the current ethereum chainId.
@@ -37,7 +37,10 @@ import (
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/execution/builder"
+	"github.com/erigontech/erigon/execution/stagedsync/stages"
 	"github.com/erigontech/erigon/execution/stages/mock"
+	"github.com/erigontech/erigon/turbo/privateapi"
 )
 
 func TestGasPrice(t *testing.T) {
@@ -209,3 +212,41 @@ func createGasPriceTestKV(t *testing.T, chainSize int) *mock.MockSentry {
 
 	return m
 }
+
+func TestSyncingStages(t *testing.T) {
+	m := mock.Mock(t)
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 10, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(common.Address{1})
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	backend := privateapi.NewEthBackendServer(m.Ctx, nil, m.DB, m.Notifications, m.BlockReader, m.Log, builder.NewLatestBlockBuiltStore(), nil, privateapi.SubscribeConfig{})
+	m.Notifications.LastNewBlockSeen.Store(100)
+	reply, err := backend.Syncing(m.Ctx, nil)
+	require.NoError(t, err)
+	require.True(t, reply.Syncing)
+	require.Nil(t, reply.Snapshots)
+
+	tx, err := m.DB.BeginRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	txNumsReader := m.BlockReader.TxnumReader(m.Ctx)
+	var executed bool
+	for _, stage := range reply.Stages {
+		if stage.BlockNumber == 0 {
+			require.Zero(t, stage.TxNum, stage.StageName)
+			continue
+		}
+		txNum, err := txNumsReader.Max(tx, stage.BlockNumber)
+		require.NoError(t, err)
+		require.Equal(t, txNum, stage.TxNum, stage.StageName)
+		executed = executed || stage.StageName == string(stages.Execution)
+	}
+	require.True(t, executed)
+
+	status, err := json.Marshal(syncingStatus(reply))
+	require.NoError(t, err)
+	require.Contains(t, string(status), `"tx_num":`)
+	require.NotContains(t, string(status), `"snapshots"`)
+}
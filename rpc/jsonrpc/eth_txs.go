@@ -30,7 +30,6 @@ import (
 	types "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
 	types2 "github.com/erigontech/erigon-lib/types"
 	bortypes "github.com/erigontech/erigon/polygon/bor/types"
-	borrawdb "github.com/erigontech/erigon/polygon/rawdb"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/ethapi"
 	"github.com/erigontech/erigon/rpc/rpchelper"
@@ -217,23 +216,13 @@ func (api *APIImpl) GetTransactionByBlockHashAndIndex(ctx context.Context, block
 		if chainConfig.Bor == nil {
 			return nil, nil // not error
 		}
-		var borTx types2.Transaction
-		if api.useBridgeReader {
-			possibleBorTxnHash := bortypes.ComputeBorTxHash(block.NumberU64(), block.Hash())
-			_, ok, err := api.bridgeReader.EventTxnLookup(ctx, possibleBorTxnHash)
-			if err != nil {
-				return nil, err
-			}
-			if ok {
-				borTx = bortypes.NewBorTransaction()
-			}
-		} else {
-			borTx = borrawdb.ReadBorTransactionForBlock(tx, block.NumberU64())
+		borTx, derivedBorTxHash, err := api.borStateSyncTxn(ctx, tx, block.Hash(), block.NumberU64())
+		if err != nil {
+			return nil, err
 		}
 		if borTx == nil {
 			return nil, nil // not error
 		}
-		derivedBorTxHash := bortypes.ComputeBorTxHash(block.NumberU64(), block.Hash())
 		return ethapi.NewRPCBorTransaction(borTx, derivedBorTxHash, block.Hash(), block.NumberU64(), uint64(txIndex), chainConfig.ChainID), nil
 	}
 
@@ -292,23 +281,13 @@ func (api *APIImpl) GetTransactionByBlockNumberAndIndex(ctx context.Context, blo
 		if chainConfig.Bor == nil {
 			return nil, nil // not error
 		}
-		var borTx types2.Transaction
-		if api.useBridgeReader {
-			possibleBorTxnHash := bortypes.ComputeBorTxHash(blockNum, hash)
-			_, ok, err := api.bridgeReader.EventTxnLookup(ctx, possibleBorTxnHash)
-			if err != nil {
-				return nil, err
-			}
-			if ok {
-				borTx = bortypes.NewBorTransaction()
-			}
-		} else {
-			borTx = borrawdb.ReadBorTransactionForBlock(tx, blockNum)
+		borTx, derivedBorTxHash, err := api.borStateSyncTxn(ctx, tx, hash, blockNum)
+		if err != nil {
+			return nil, err
 		}
 		if borTx == nil {
 			return nil, nil
 		}
-		derivedBorTxHash := bortypes.ComputeBorTxHash(blockNum, hash)
 		return ethapi.NewRPCBorTransaction(borTx, derivedBorTxHash, hash, blockNum, uint64(txIndex), chainConfig.ChainID), nil
 	}
 
@@ -43,20 +43,31 @@ import (
 )
 
 // API_LEVEL Must be incremented every time new additions are made
-const API_LEVEL = 8
+const API_LEVEL = 9
 
 type TransactionsWithReceipts struct {
 	Txs       []*ethapi.RPCTransaction `json:"txs"`
 	Receipts  []map[string]interface{} `json:"receipts"`
 	FirstPage bool                     `json:"firstPage"`
 	LastPage  bool                     `json:"lastPage"`
+
+	// Next is the cursor the search resumes from to get the following page, in the direction of the search; nil
+	// if there are no more transactions.
+	Next *SearchCursor `json:"next,omitempty"`
+}
+
+// SearchCursor is the position of a transaction in the chain, from which a search resumes: the search returns the
+// transactions following it in its direction, excluding it.
+type SearchCursor struct {
+	BlockNum hexutil.Uint64 `json:"blockNumber"`
+	TxIndex  hexutil.Uint64 `json:"transactionIndex"`
 }
 
 type OtterscanAPI interface {
 	GetApiLevel() uint8
 	GetInternalOperations(ctx context.Context, hash common.Hash) ([]*InternalOperation, error)
-	SearchTransactionsBefore(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error)
-	SearchTransactionsAfter(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error)
+	SearchTransactionsBefore(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16, cursor *SearchCursor) (*TransactionsWithReceipts, error)
+	SearchTransactionsAfter(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16, cursor *SearchCursor) (*TransactionsWithReceipts, error)
 	GetBlockDetails(ctx context.Context, number rpc.BlockNumber) (map[string]interface{}, error)
 	GetBlockDetailsByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error)
 	GetBlockTransactions(ctx context.Context, number rpc.BlockNumber, pageNumber uint8, pageSize uint8) (map[string]interface{}, error)
@@ -203,7 +214,12 @@ func (api *OtterscanAPIImpl) GetInternalOperations(ctx context.Context, hash com
 // they are just returned. But it may return a little more than pageSize if there are more txs
 // than the necessary to fill pageSize in the last found block, i.e., let's say you want pageSize == 25,
 // you already found 24 txs, the next block contains 4 matches, then this function will return 28 txs.
-func (api *OtterscanAPIImpl) SearchTransactionsBefore(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error) {
+//
+// If the cursor of a previous page is given, blockNum is ignored and the search resumes from the cursor, returning
+// exactly pageSize txs unless there are less.
+//
+// It returns state.PrunedError if the search reaches the blocks whose history is pruned.
+func (api *OtterscanAPIImpl) SearchTransactionsBefore(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16, cursor *SearchCursor) (*TransactionsWithReceipts, error) {
 	if uint64(pageSize) > api.maxPageSize {
 		return nil, fmt.Errorf("max allowed page size: %v", api.maxPageSize)
 	}
@@ -214,7 +230,7 @@ func (api *OtterscanAPIImpl) SearchTransactionsBefore(ctx context.Context, addr
 	}
 	defer dbtx.Rollback()
 
-	return api.searchTransactionsBeforeV3(dbtx, ctx, addr, blockNum, pageSize)
+	return api.searchTransactionsBeforeV3(dbtx, ctx, addr, blockNum, pageSize, cursor)
 }
 
 // Search transactions that touch a certain address.
@@ -225,7 +241,12 @@ func (api *OtterscanAPIImpl) SearchTransactionsBefore(ctx context.Context, addr
 // they are just returned. But it may return a little more than pageSize if there are more txs
 // than the necessary to fill pageSize in the last found block, i.e., let's say you want pageSize == 25,
 // you already found 24 txs, the next block contains 4 matches, then this function will return 28 txs.
-func (api *OtterscanAPIImpl) SearchTransactionsAfter(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error) {
+//
+// If the cursor of a previous page is given, blockNum is ignored and the search resumes from the cursor, returning
+// exactly pageSize txs unless there are less.
+//
+// It returns state.PrunedError if the search starts in the blocks whose history is pruned.
+func (api *OtterscanAPIImpl) SearchTransactionsAfter(ctx context.Context, addr common.Address, blockNum uint64, pageSize uint16, cursor *SearchCursor) (*TransactionsWithReceipts, error) {
 	if uint64(pageSize) > api.maxPageSize {
 		return nil, fmt.Errorf("max allowed page size: %v", api.maxPageSize)
 	}
@@ -236,7 +257,7 @@ func (api *OtterscanAPIImpl) SearchTransactionsAfter(ctx context.Context, addr c
 	}
 	defer dbtx.Rollback()
 
-	return api.searchTransactionsAfterV3(dbtx, ctx, addr, blockNum, pageSize)
+	return api.searchTransactionsAfterV3(dbtx, ctx, addr, blockNum, pageSize, cursor)
 }
 
 func (api *OtterscanAPIImpl) traceBlocks(ctx context.Context, addr common.Address, chainConfig *chain.Config, pageSize, resultCount uint16, callFromToProvider BlockProvider) ([]*TransactionsWithReceipts, bool, error) {
@@ -24,6 +24,7 @@ import (
 	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/rpc"
 )
 
@@ -86,6 +87,16 @@ func (api *OtterscanAPIImpl) getBlockDetailsImpl(ctx context.Context, tx kv.Temp
 	if err != nil {
 		return nil, err
 	}
+	// the fees are paid by the receipts, generated from the history of the state
+	if len(b.Transactions()) > 0 {
+		firstBlock, _, err := api.firstRetainedBlock(tx)
+		if err != nil {
+			return nil, err
+		}
+		if b.NumberU64() < firstBlock {
+			return nil, state.PrunedError
+		}
+	}
 	receipts, err := api.getReceipts(ctx, tx, b)
 	if err != nil {
 		return nil, fmt.Errorf("getReceipts error: %v", err)
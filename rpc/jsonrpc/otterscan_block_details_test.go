@@ -0,0 +1,48 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon/rpc"
+)
+
+func TestGetBlockDetails(t *testing.T) {
+	m, _ := mockWithBusyAddress(t, [][]common.Address{{busyAddr}, {busyAddr, {0xcc}, busyAddr}, {}})
+	api := NewOtterscanAPI(newBaseApiForTest(m), m.DB, 25)
+
+	for blockNum, txns := range []int64{0, 1, 3, 0} {
+		details, err := api.GetBlockDetails(m.Ctx, rpc.BlockNumber(blockNum))
+		require.NoError(t, err)
+		// the transfers pay a gas price of 1 wei
+		require.Equal(t, txns*21_000, details["totalFees"].(*hexutil.Big).ToInt().Int64(), "block %d", blockNum)
+		require.Contains(t, details, "issuance")
+
+		block := details["block"].(map[string]interface{})
+		require.Equal(t, int64(blockNum), block["number"].(*hexutil.Big).ToInt().Int64())
+		require.Equal(t, txns, int64(block["transactionCount"].(int)))
+
+		byHash, err := api.GetBlockDetailsByHash(m.Ctx, block["hash"].(common.Hash))
+		require.NoError(t, err)
+		require.Equal(t, details, byHash)
+	}
+}
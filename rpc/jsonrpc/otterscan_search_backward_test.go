@@ -170,7 +170,7 @@ func TestSearchTransactionsBefore(t *testing.T) {
 	addr := common.HexToAddress("0x537e697c7ab75a26f9ecf0ce810e3154dfcaaf44")
 	t.Run("small page size", func(t *testing.T) {
 		require := require.New(t)
-		results, err := api.SearchTransactionsBefore(m.Ctx, addr, 10, 2)
+		results, err := api.SearchTransactionsBefore(m.Ctx, addr, 10, 2, nil)
 		require.NoError(err)
 		require.False(results.FirstPage)
 		require.False(results.LastPage)
@@ -179,7 +179,7 @@ func TestSearchTransactionsBefore(t *testing.T) {
 	})
 	t.Run("big page size", func(t *testing.T) {
 		require := require.New(t)
-		results, err := api.SearchTransactionsBefore(m.Ctx, addr, 10, 10)
+		results, err := api.SearchTransactionsBefore(m.Ctx, addr, 10, 10, nil)
 		require.NoError(err)
 		require.False(results.FirstPage)
 		require.True(results.LastPage)
@@ -188,7 +188,7 @@ func TestSearchTransactionsBefore(t *testing.T) {
 	})
 	t.Run("filter last block", func(t *testing.T) {
 		require := require.New(t)
-		results, err := api.SearchTransactionsBefore(m.Ctx, addr, 5, 10)
+		results, err := api.SearchTransactionsBefore(m.Ctx, addr, 5, 10, nil)
 
 		require.NoError(err)
 		require.False(results.FirstPage)
@@ -169,7 +169,7 @@ func TestSearchTransactionsAfter(t *testing.T) {
 	addr := common.HexToAddress("0x537e697c7ab75a26f9ecf0ce810e3154dfcaaf44")
 	t.Run("small page size", func(t *testing.T) {
 		require := require.New(t)
-		results, err := api.SearchTransactionsAfter(m.Ctx, addr, 2, 2)
+		results, err := api.SearchTransactionsAfter(m.Ctx, addr, 2, 2, nil)
 		require.NoError(err)
 		require.False(results.FirstPage)
 		require.False(results.LastPage)
@@ -178,7 +178,7 @@ func TestSearchTransactionsAfter(t *testing.T) {
 	})
 	t.Run("big page size", func(t *testing.T) {
 		require := require.New(t)
-		results, err := api.SearchTransactionsAfter(m.Ctx, addr, 2, 10)
+		results, err := api.SearchTransactionsAfter(m.Ctx, addr, 2, 10, nil)
 		require.NoError(err)
 		require.True(results.FirstPage)
 		require.False(results.LastPage)
@@ -187,7 +187,7 @@ func TestSearchTransactionsAfter(t *testing.T) {
 	})
 	t.Run("filter last block", func(t *testing.T) {
 		require := require.New(t)
-		results, err := api.SearchTransactionsAfter(m.Ctx, addr, 3, 10)
+		results, err := api.SearchTransactionsAfter(m.Ctx, addr, 3, 10, nil)
 
 		require.NoError(err)
 		require.True(results.FirstPage)
@@ -18,33 +18,40 @@ package jsonrpc
 
 import (
 	"context"
+	"fmt"
 	"slices"
 
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/order"
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/kv/stream"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/eth/ethutils"
 	"github.com/erigontech/erigon/rpc/ethapi"
 )
 
 type txNumsIterFactory func(tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader, addr common.Address, fromTxNum int) (*rawdbv3.MapTxNum2BlockNumIter, error)
 
-func (api *OtterscanAPIImpl) buildSearchResults(ctx context.Context, tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader, iterFactory txNumsIterFactory, addr common.Address, fromTxNum int, pageSize uint16) ([]*ethapi.RPCTransaction, []map[string]interface{}, bool, error) {
+// buildSearchResults returns the txs touching addr from fromTxNum on, in the order of the iterator, and the
+// position of the last one. Once pageSize txs are found, it drains the matching txs of the last block unless
+// strict is set.
+func (api *OtterscanAPIImpl) buildSearchResults(ctx context.Context, tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader, iterFactory txNumsIterFactory, addr common.Address, fromTxNum int, pageSize uint16, strict bool) ([]*ethapi.RPCTransaction, []map[string]interface{}, *SearchCursor, bool, error) {
 	chainConfig, err := api.chainConfig(ctx, tx)
 	if err != nil {
-		return nil, nil, false, err
+		return nil, nil, nil, false, err
 	}
 
 	txNumsIter, err := iterFactory(tx, txNumsReader, addr, fromTxNum)
 	if err != nil {
-		return nil, nil, false, err
+		return nil, nil, nil, false, err
 	}
 
 	var block *types.Block
+	var last *SearchCursor
 	txs := make([]*ethapi.RPCTransaction, 0, pageSize)
 	receipts := make([]map[string]interface{}, 0, pageSize)
 	resultCount := uint16(0)
@@ -55,12 +62,12 @@ func (api *OtterscanAPIImpl) buildSearchResults(ctx context.Context, tx kv.Tempo
 	for txNumsIter.HasNext() {
 		txNum, blockNum, txIndex, isFinalTxn, blockNumChanged, err := txNumsIter.Next()
 		if err != nil {
-			return nil, nil, false, err
+			return nil, nil, nil, false, err
 		}
 
 		// Even if the desired page size is reached, drain the entire matching
-		// txs inside the block; reproduces e2 behavior. An e3/paginated-aware
-		// ots spec could improve in this area.
+		// txs inside the block; reproduces e2 behavior. The cursor of the
+		// page allows to resume from the exact txn instead.
 		if blockNumChanged && reachedPageSize {
 			hasMore = true
 			break
@@ -76,18 +83,22 @@ func (api *OtterscanAPIImpl) buildSearchResults(ctx context.Context, tx kv.Tempo
 		if isFinalTxn {
 			continue
 		}
+		if strict && reachedPageSize {
+			hasMore = true
+			break
+		}
 
 		if mustReadBlock {
 			block, err = api.blockByNumberWithSenders(ctx, tx, blockNum)
 			if err != nil {
-				return nil, nil, false, err
+				return nil, nil, nil, false, err
 			}
 			mustReadBlock = false
 		}
 
 		txn, err := api._txnReader.TxnByIdxInBlock(ctx, tx, blockNum, txIndex)
 		if err != nil {
-			return nil, nil, false, err
+			return nil, nil, nil, false, err
 		}
 		if txn == nil {
 			log.Warn("[rpc] txn not found", "blockNum", blockNum, "txIndex", txIndex)
@@ -98,12 +109,13 @@ func (api *OtterscanAPIImpl) buildSearchResults(ctx context.Context, tx kv.Tempo
 
 		receipt, err := api.receiptsGenerator.GetReceipt(ctx, chainConfig, tx, block.HeaderNoCopy(), txn, txIndex, txNum)
 		if err != nil {
-			return nil, nil, false, err
+			return nil, nil, nil, false, err
 		}
 
 		mReceipt := ethutils.MarshalReceipt(receipt, txn, chainConfig, block.HeaderNoCopy(), txn.Hash(), true)
 		mReceipt["timestamp"] = block.Time()
 		receipts = append(receipts, mReceipt)
+		last = &SearchCursor{BlockNum: hexutil.Uint64(blockNum), TxIndex: hexutil.Uint64(txIndex)}
 
 		resultCount++
 		if resultCount >= pageSize {
@@ -111,7 +123,56 @@ func (api *OtterscanAPIImpl) buildSearchResults(ctx context.Context, tx kv.Tempo
 		}
 	}
 
-	return txs, receipts, hasMore, nil
+	return txs, receipts, last, hasMore, nil
+}
+
+// firstRetainedBlock returns the first block whose history is retained, so that the txs touching an address can
+// be searched and their receipts generated: 0 unless the history is pruned.
+func (api *OtterscanAPIImpl) firstRetainedBlock(tx kv.TemporalTx) (blockNum uint64, firstTxNum uint64, err error) {
+	r := state.NewHistoryReaderV3()
+	r.SetTx(tx)
+	startTxNum := r.StateHistoryStartFrom()
+	if startTxNum == 0 {
+		return 0, 0, nil
+	}
+	blockNum, ok, err := api._txNumReader.FindBlockNum(tx, startTxNum)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		return 0, 0, fmt.Errorf("can't find block of the first retained txnID=%d", startTxNum)
+	}
+	if firstTxNum, err = api._txNumReader.Min(tx, blockNum); err != nil {
+		return 0, 0, err
+	}
+	// the block is retained only if its history is whole
+	if firstTxNum < startTxNum {
+		blockNum++
+		if firstTxNum, err = api._txNumReader.Min(tx, blockNum); err != nil {
+			return 0, 0, err
+		}
+	}
+	return blockNum, firstTxNum, nil
+}
+
+// cursorTxNum returns the txNum of the txn at the position of the cursor.
+func (api *OtterscanAPIImpl) cursorTxNum(tx kv.Tx, cursor *SearchCursor) (uint64, error) {
+	blockNum := uint64(cursor.BlockNum)
+	minTxNum, err := api._txNumReader.Min(tx, blockNum)
+	if err != nil {
+		return 0, err
+	}
+	maxTxNum, err := api._txNumReader.Max(tx, blockNum)
+	if err != nil {
+		return 0, err
+	}
+	// the first and the last txNums of a block are its system txs; the position of the first txn is valid even if
+	// the block has none, e.g. to resume from the first retained block
+	txNum := minTxNum + 1 + uint64(cursor.TxIndex)
+	if cursor.TxIndex > 0 && txNum >= maxTxNum {
+		return 0, fmt.Errorf("invalid search cursor: block %d has no transaction %d", blockNum, cursor.TxIndex)
+	}
+	return txNum, nil
 }
 
 func createBackwardTxNumIter(tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader, addr common.Address, fromTxNum int) (*rawdbv3.MapTxNum2BlockNumIter, error) {
@@ -129,32 +190,59 @@ func createBackwardTxNumIter(tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader
 	return rawdbv3.TxNums2BlockNums(tx, txNumsReader, txNums, order.Desc), nil
 }
 
-func (api *OtterscanAPIImpl) searchTransactionsBeforeV3(tx kv.TemporalTx, ctx context.Context, addr common.Address, fromBlockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error) {
+func (api *OtterscanAPIImpl) searchTransactionsBeforeV3(tx kv.TemporalTx, ctx context.Context, addr common.Address, fromBlockNum uint64, pageSize uint16, cursor *SearchCursor) (*TransactionsWithReceipts, error) {
 	isFirstPage := false
-	if fromBlockNum == 0 {
-		isFirstPage = true
-	} else {
-		// Internal search code considers blockNum [including], so adjust the value
-		fromBlockNum--
-	}
-
 	fromTxNum := -1
-	if fromBlockNum != 0 {
-		// from == 0 == magic number which means last; reproduce bug-compatibility for == 1
-		// with e2 for now
-		_txNum, err := api._txNumReader.Max(tx, fromBlockNum)
+	if cursor != nil {
+		txNum, err := api.cursorTxNum(tx, cursor)
 		if err != nil {
 			return nil, err
 		}
-		fromTxNum = int(_txNum)
+		fromTxNum = int(txNum) - 1
+	} else {
+		if fromBlockNum == 0 {
+			isFirstPage = true
+		} else {
+			// Internal search code considers blockNum [including], so adjust the value
+			fromBlockNum--
+		}
+
+		if fromBlockNum != 0 {
+			// from == 0 == magic number which means last; reproduce bug-compatibility for == 1
+			// with e2 for now
+			_txNum, err := api._txNumReader.Max(tx, fromBlockNum)
+			if err != nil {
+				return nil, err
+			}
+			fromTxNum = int(_txNum)
+		}
 	}
 
-	txs, receipts, hasMore, err := api.buildSearchResults(ctx, tx, api._txNumReader, createBackwardTxNumIter, addr, fromTxNum, pageSize)
+	firstBlock, firstTxNum, err := api.firstRetainedBlock(tx)
 	if err != nil {
 		return nil, err
 	}
+	if fromTxNum >= 0 && uint64(fromTxNum) < firstTxNum {
+		return nil, state.PrunedError
+	}
 
-	return &TransactionsWithReceipts{txs, receipts, isFirstPage, !hasMore}, nil
+	txs, receipts, last, hasMore, err := api.buildSearchResults(ctx, tx, api._txNumReader, createBackwardTxNumIter, addr, fromTxNum, pageSize, cursor != nil)
+	if err != nil {
+		return nil, err
+	}
+	var next *SearchCursor
+	if hasMore {
+		next = last
+	} else if firstBlock > 0 {
+		// the search reached the pruned blocks, which may hold more txs: the following page reports them as not
+		// available
+		if len(txs) == 0 {
+			return nil, state.PrunedError
+		}
+		hasMore, next = true, &SearchCursor{BlockNum: hexutil.Uint64(firstBlock)}
+	}
+
+	return &TransactionsWithReceipts{txs, receipts, isFirstPage, !hasMore, next}, nil
 }
 
 func createForwardTxNumIter(tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader, addr common.Address, fromTxNum int) (*rawdbv3.MapTxNum2BlockNumIter, error) {
@@ -172,11 +260,17 @@ func createForwardTxNumIter(tx kv.TemporalTx, txNumsReader rawdbv3.TxNumsReader,
 	return rawdbv3.TxNums2BlockNums(tx, txNumsReader, txNums, order.Asc), nil
 }
 
-func (api *OtterscanAPIImpl) searchTransactionsAfterV3(tx kv.TemporalTx, ctx context.Context, addr common.Address, fromBlockNum uint64, pageSize uint16) (*TransactionsWithReceipts, error) {
+func (api *OtterscanAPIImpl) searchTransactionsAfterV3(tx kv.TemporalTx, ctx context.Context, addr common.Address, fromBlockNum uint64, pageSize uint16, cursor *SearchCursor) (*TransactionsWithReceipts, error) {
 	isLastPage := false
 	fromTxNum := -1
 
-	if fromBlockNum == 0 {
+	if cursor != nil {
+		txNum, err := api.cursorTxNum(tx, cursor)
+		if err != nil {
+			return nil, err
+		}
+		fromTxNum = int(txNum) + 1
+	} else if fromBlockNum == 0 {
 		isLastPage = true
 	} else {
 		// Internal search code considers blockNum [including], so adjust the value
@@ -187,12 +281,24 @@ func (api *OtterscanAPIImpl) searchTransactionsAfterV3(tx kv.TemporalTx, ctx con
 		fromTxNum = int(_txNum)
 	}
 
-	txs, receipts, hasMore, err := api.buildSearchResults(ctx, tx, api._txNumReader, createForwardTxNumIter, addr, fromTxNum, pageSize)
+	firstBlock, firstTxNum, err := api.firstRetainedBlock(tx)
+	if err != nil {
+		return nil, err
+	}
+	if firstBlock > 0 && (fromTxNum < 0 || uint64(fromTxNum) < firstTxNum) {
+		return nil, state.PrunedError
+	}
+
+	txs, receipts, last, hasMore, err := api.buildSearchResults(ctx, tx, api._txNumReader, createForwardTxNumIter, addr, fromTxNum, pageSize, cursor != nil)
 	if err != nil {
 		return nil, err
 	}
 	slices.Reverse(txs)
 	slices.Reverse(receipts)
+	var next *SearchCursor
+	if hasMore {
+		next = last
+	}
 
-	return &TransactionsWithReceipts{txs, receipts, !hasMore, isLastPage}, nil
+	return &TransactionsWithReceipts{txs, receipts, !hasMore, isLastPage, next}, nil
 }
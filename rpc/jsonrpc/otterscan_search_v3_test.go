@@ -0,0 +1,146 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/execution/stages/mock"
+)
+
+var busyAddr = common.Address{0xbb}
+
+// mockWithBusyAddress creates a chain of blocks transferring to the given recipients, and returns the positions of
+// the transfers to busyAddr, in ascending order.
+func mockWithBusyAddress(t *testing.T, blocks [][]common.Address) (*mock.MockSentry, []SearchCursor) {
+	var busy []SearchCursor
+	signer := types.LatestSignerForChainID(nil)
+	m := mockWithGenerator(t, len(blocks), func(i int, block *core.BlockGen) {
+		for j, to := range blocks[i] {
+			txn, err := types.SignTx(types.NewTransaction(block.TxNonce(testAddr), to, uint256.NewInt(1), 21_000, uint256.NewInt(1), nil), *signer, testKey)
+			require.NoError(t, err)
+			block.AddTx(txn)
+			if to == busyAddr {
+				busy = append(busy, SearchCursor{BlockNum: hexutil.Uint64(i + 1), TxIndex: hexutil.Uint64(j)})
+			}
+		}
+	})
+	return m, busy
+}
+
+func searchPositions(results *TransactionsWithReceipts) []SearchCursor {
+	positions := make([]SearchCursor, 0, len(results.Txs))
+	for _, txn := range results.Txs {
+		positions = append(positions, SearchCursor{BlockNum: hexutil.Uint64(txn.BlockNumber.ToInt().Uint64()), TxIndex: *txn.TransactionIndex})
+	}
+	return positions
+}
+
+func TestSearchTransactionsCursor(t *testing.T) {
+	other := common.Address{0xcc}
+	m, busy := mockWithBusyAddress(t, [][]common.Address{
+		{busyAddr, other, busyAddr},
+		{other},
+		{busyAddr, busyAddr, busyAddr},
+		{other, busyAddr},
+		{},
+		{busyAddr, other, busyAddr, busyAddr},
+	})
+	api := NewOtterscanAPI(newBaseApiForTest(m), m.DB, 25)
+
+	t.Run("backward", func(t *testing.T) {
+		require := require.New(t)
+		// the first page drains the matches of its last block
+		results, err := api.SearchTransactionsBefore(m.Ctx, busyAddr, 0, 2, nil)
+		require.NoError(err)
+		require.True(results.FirstPage)
+		require.False(results.LastPage)
+		require.Equal([]SearchCursor{{6, 3}, {6, 2}, {6, 0}}, searchPositions(results))
+		require.Len(results.Receipts, 3)
+		require.Equal(&SearchCursor{6, 0}, results.Next)
+
+		// the following pages hold exactly the page size, cutting through the blocks
+		var pages [][]SearchCursor
+		found := searchPositions(results)
+		for !results.LastPage {
+			results, err = api.SearchTransactionsBefore(m.Ctx, busyAddr, 0, 2, results.Next)
+			require.NoError(err)
+			require.False(results.FirstPage)
+			pages = append(pages, searchPositions(results))
+			found = append(found, searchPositions(results)...)
+		}
+		require.Equal([][]SearchCursor{{{4, 1}, {3, 2}}, {{3, 1}, {3, 0}}, {{1, 2}, {1, 0}}}, pages)
+		require.Nil(results.Next)
+
+		want := slices.Clone(busy)
+		slices.Reverse(want)
+		require.Equal(want, found)
+	})
+
+	t.Run("forward", func(t *testing.T) {
+		require := require.New(t)
+		results, err := api.SearchTransactionsAfter(m.Ctx, busyAddr, 0, 2, nil)
+		require.NoError(err)
+		require.False(results.FirstPage)
+		require.True(results.LastPage)
+		require.Equal([]SearchCursor{{1, 2}, {1, 0}}, searchPositions(results))
+		require.Equal(&SearchCursor{1, 2}, results.Next)
+
+		// the pages are sorted descending, like the backward ones
+		var pages [][]SearchCursor
+		found := searchPositions(results)
+		slices.Reverse(found)
+		for !results.FirstPage {
+			results, err = api.SearchTransactionsAfter(m.Ctx, busyAddr, 0, 2, results.Next)
+			require.NoError(err)
+			require.False(results.LastPage)
+			page := searchPositions(results)
+			pages = append(pages, page)
+			slices.Reverse(page)
+			found = append(found, page...)
+		}
+		require.Equal([][]SearchCursor{{{3, 1}, {3, 0}}, {{4, 1}, {3, 2}}, {{6, 2}, {6, 0}}, {{6, 3}}}, pages)
+		require.Nil(results.Next)
+		require.Equal(busy, found)
+	})
+
+	t.Run("cursor in the middle of a block", func(t *testing.T) {
+		require := require.New(t)
+		results, err := api.SearchTransactionsBefore(m.Ctx, busyAddr, 0, 10, &SearchCursor{3, 1})
+		require.NoError(err)
+		require.True(results.LastPage)
+		require.Equal([]SearchCursor{{3, 0}, {1, 2}, {1, 0}}, searchPositions(results))
+
+		results, err = api.SearchTransactionsAfter(m.Ctx, busyAddr, 0, 10, &SearchCursor{3, 1})
+		require.NoError(err)
+		require.True(results.FirstPage)
+		require.Equal([]SearchCursor{{6, 3}, {6, 2}, {6, 0}, {4, 1}, {3, 2}}, searchPositions(results))
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := api.SearchTransactionsBefore(m.Ctx, busyAddr, 0, 2, &SearchCursor{2, 1})
+		require.ErrorContains(t, err, "invalid search cursor")
+	})
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package receipts
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+// CacheStats is a snapshot of the counters of one of the Generator caches.
+type CacheStats struct {
+	Len       int    `json:"len"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"` // entries dropped because of capacity or ttl
+}
+
+type ttlEntry[V any] struct {
+	value V
+	added time.Time
+}
+
+// ttlCache is an LRU cache whose entries also expire ttl after they were added.
+// A zero ttl keeps entries until they are evicted by newer ones.
+type ttlCache[K comparable, V any] struct {
+	lru *lru.Cache[K, ttlEntry[V]]
+	ttl time.Duration
+	now func() time.Time
+
+	hits, misses, evictions atomic.Uint64
+
+	hitsMetric, missesMetric, evictionsMetric metrics.Counter
+}
+
+func newTTLCache[K comparable, V any](size int, ttl time.Duration, metricsLabels string) *ttlCache[K, V] {
+	c, err := lru.New[K, ttlEntry[V]](size)
+	if err != nil {
+		panic(err)
+	}
+	return &ttlCache[K, V]{
+		lru:             c,
+		ttl:             ttl,
+		now:             time.Now,
+		hitsMetric:      metrics.GetOrCreateCounter(fmt.Sprintf(`receipts_cache_hits{%s}`, metricsLabels)),
+		missesMetric:    metrics.GetOrCreateCounter(fmt.Sprintf(`receipts_cache_misses{%s}`, metricsLabels)),
+		evictionsMetric: metrics.GetOrCreateCounter(fmt.Sprintf(`receipts_cache_evictions{%s}`, metricsLabels)),
+	}
+}
+
+func (c *ttlCache[K, V]) Get(k K) (v V, ok bool) {
+	e, ok := c.lru.Get(k)
+	if ok && c.ttl > 0 && c.now().Sub(e.added) >= c.ttl {
+		if c.lru.Remove(k) {
+			c.evicted()
+		}
+		ok = false
+	}
+	if !ok {
+		c.misses.Add(1)
+		c.missesMetric.Inc()
+		return v, false
+	}
+	c.hits.Add(1)
+	c.hitsMetric.Inc()
+	return e.value, true
+}
+
+func (c *ttlCache[K, V]) Add(k K, v V) {
+	if evicted := c.lru.Add(k, ttlEntry[V]{value: v, added: c.now()}); evicted {
+		c.evicted()
+	}
+}
+
+func (c *ttlCache[K, V]) Remove(k K) { c.lru.Remove(k) }
+
+// Pop removes the entry of k and returns its value, even if it has expired. It doesn't count as a hit or a miss.
+func (c *ttlCache[K, V]) Pop(k K) (v V, ok bool) {
+	e, ok := c.lru.Peek(k)
+	if !ok {
+		return v, false
+	}
+	c.lru.Remove(k)
+	return e.value, true
+}
+
+// RemoveIf removes the entry of k if match returns true for its value.
+func (c *ttlCache[K, V]) RemoveIf(k K, match func(V) bool) {
+	if e, ok := c.lru.Peek(k); ok && match(e.value) {
+		c.lru.Remove(k)
+	}
+}
+
+func (c *ttlCache[K, V]) evicted() {
+	c.evictions.Add(1)
+	c.evictionsMetric.Inc()
+}
+
+func (c *ttlCache[K, V]) Stats() CacheStats {
+	return CacheStats{
+		Len:       c.lru.Len(),
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
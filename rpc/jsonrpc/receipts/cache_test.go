@@ -0,0 +1,61 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package receipts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTLCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("eviction", func(t *testing.T) {
+		c := newTTLCache[int, string](2, 0, `generator="test"`)
+		c.Add(1, "a")
+		c.Add(2, "b")
+		c.Add(3, "c")
+
+		_, ok := c.Get(1)
+		require.False(t, ok)
+		v, ok := c.Get(3)
+		require.True(t, ok)
+		require.Equal(t, "c", v)
+		require.Equal(t, CacheStats{Len: 2, Hits: 1, Misses: 1, Evictions: 1}, c.Stats())
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		now := time.Unix(0, 0)
+		c := newTTLCache[int, string](2, time.Minute, `generator="test"`)
+		c.now = func() time.Time { return now }
+		c.Add(1, "a")
+
+		now = now.Add(59 * time.Second)
+		c.Add(2, "b")
+		_, ok := c.Get(1)
+		require.True(t, ok)
+
+		now = now.Add(time.Second)
+		_, ok = c.Get(1)
+		require.False(t, ok)
+		_, ok = c.Get(2)
+		require.True(t, ok)
+		require.Equal(t, CacheStats{Len: 1, Hits: 2, Misses: 1, Evictions: 1}, c.Stats())
+	})
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/execution/stages/mock"
 	"github.com/erigontech/erigon/p2p/protocols/eth"
 	"github.com/erigontech/erigon/rpc/jsonrpc/receipts"
@@ -298,7 +299,7 @@ func TestGetBlockReceipts(t *testing.T) {
 	}
 	// Assemble the test environment
 	m := mockWithGenerator(t, 4, generator)
-	receiptsGetter := receipts.NewGenerator(m.BlockReader, m.Engine, time.Minute)
+	receiptsGetter := receipts.NewGenerator(m.BlockReader, m.Engine, time.Minute, ethconfig.ReceiptsCache{}, "p2p")
 	// Collect the hashes to request, and the response to expect
 	var (
 		hashes   []common.Hash
@@ -342,17 +343,129 @@ func TestGetBlockReceipts(t *testing.T) {
 	require.Equal(t, expect, sent.Data)
 }
 
+func TestGeneratorInvalidateOnUnwind(t *testing.T) {
+	m, require := mockWithGenerator(t, 0, nil), require.New(t)
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+	// both branches share block 1
+	generate := func(n int, coinbase common.Address) *core.ChainPack {
+		branch, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, n, func(i int, b *core.BlockGen) {
+			if i > 0 {
+				b.SetCoinbase(coinbase)
+			}
+			txn, err := types.SignTx(types.NewTransaction(b.TxNonce(testAddr), common.Address{1}, uint256.NewInt(1), params.TxGas, nil, nil), *signer, testKey)
+			require.NoError(err)
+			b.AddTx(txn)
+		})
+		require.NoError(err)
+		return branch
+	}
+	oldBranch, newBranch := generate(2, common.Address{1}), generate(3, common.Address{2})
+	require.NoError(m.InsertChain(oldBranch))
+
+	generator := m.ReceiptsReader
+	tx, err := m.DB.BeginTemporalRo(m.Ctx)
+	require.NoError(err)
+	for _, block := range oldBranch.Blocks {
+		_, err := generator.GetReceipts(m.Ctx, m.ChainConfig, tx, block)
+		require.NoError(err)
+	}
+	tx.Rollback()
+
+	cached := func(block *types.Block) bool {
+		_, ok := generator.GetCachedReceipts(m.Ctx, block.Hash())
+		return ok
+	}
+	require.True(cached(oldBranch.TopBlock))
+
+	// the unwind evicts the orphaned block, the block common to both branches stays cached
+	require.NoError(m.InsertChain(newBranch))
+	require.Eventually(func() bool { return !cached(oldBranch.TopBlock) }, 10*time.Second, 10*time.Millisecond)
+	require.True(cached(oldBranch.Blocks[0]))
+}
+
+// transfersGenerator fills each block with n transfers of the test bank.
+func transfersGenerator(tb testing.TB, signer *types.Signer, n int) func(int, *core.BlockGen) {
+	return func(i int, block *core.BlockGen) {
+		for j := 0; j < n; j++ {
+			txn, err := types.SignTx(types.NewTransaction(block.TxNonce(testAddr), common.Address{byte(j + 1)}, uint256.NewInt(1), params.TxGas, nil, nil), *signer, testKey)
+			require.NoError(tb, err)
+			block.AddTx(txn)
+		}
+	}
+}
+
+func TestFrozenReceiptsEncoding(t *testing.T) {
+	m := mockWithGenerator(t, 4, transfersGenerator(t, types.LatestSignerForChainID(nil), 3))
+	receipts.DisableReceiptsCacheV2(t)
+	regenerator := receipts.NewGenerator(m.BlockReader, m.Engine, time.Minute, ethconfig.ReceiptsCache{}, "p2p")
+	tx, err := m.DB.BeginTemporalRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	txNumReader := m.BlockReader.TxnumReader(m.Ctx)
+
+	for i := uint64(1); i <= 4; i++ {
+		block, err := m.BlockReader.BlockByNumber(m.Ctx, tx, i)
+		require.NoError(t, err)
+		regenerated, err := regenerator.GetReceipts(m.Ctx, m.ChainConfig, tx, block)
+		require.NoError(t, err)
+		// the receipts cache domain, which the snapshot files are built from, holds the same consensus encoding
+		stored, err := rawdb.ReadReceiptsCacheV2(tx, block, txNumReader)
+		require.NoError(t, err)
+		require.Len(t, stored, len(block.Transactions()))
+		expect, err := rlp.EncodeToBytes(regenerated)
+		require.NoError(t, err)
+		encoded, err := rlp.EncodeToBytes(stored)
+		require.NoError(t, err)
+		require.Equal(t, expect, encoded)
+
+		// the blocks are in the db, not in files yet: their receipts are regenerated
+		_, frozen, err := rawdb.ReadFrozenReceipts(tx, block, txNumReader)
+		require.NoError(t, err)
+		require.False(t, frozen)
+	}
+}
+
+func BenchmarkReceiptsServing(b *testing.B) {
+	m := mockWithGenerator(b, 64, transfersGenerator(b, types.LatestSignerForChainID(nil), 50))
+	tx, err := m.DB.BeginTemporalRo(m.Ctx)
+	require.NoError(b, err)
+	defer tx.Rollback()
+	txNumReader := m.BlockReader.TxnumReader(m.Ctx)
+	// the deepest block, the one regenerated from the most history
+	block, err := m.BlockReader.BlockByNumber(m.Ctx, tx, 1)
+	require.NoError(b, err)
+
+	// the read of ReadFrozenReceipts, from the db here, the files being no slower
+	b.Run("snapshot", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := rawdb.ReadReceiptsCacheV2(tx, block, txNumReader); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("regenerated", func(b *testing.B) {
+		receipts.DisableReceiptsCacheV2(b)
+		for i := 0; i < b.N; i++ {
+			// a generator of its own per request, so that its in-memory cache doesn't answer
+			regenerator := receipts.NewGenerator(m.BlockReader, m.Engine, time.Minute, ethconfig.ReceiptsCache{}, "p2p")
+			if _, err := regenerator.GetReceipts(m.Ctx, m.ChainConfig, tx, block); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
 // newTestBackend creates a chain with a number of explicitly defined blocks and
 // wraps it into a mock backend.
-func mockWithGenerator(t *testing.T, blocks int, generator func(int, *core.BlockGen)) *mock.MockSentry {
-	m := mock.MockWithGenesis(t, &types.Genesis{
+func mockWithGenerator(tb testing.TB, blocks int, generator func(int, *core.BlockGen)) *mock.MockSentry {
+	m := mock.MockWithGenesis(tb, &types.Genesis{
 		Config: chain.TestChainConfig,
 		Alloc:  types.GenesisAlloc{testAddr: {Balance: big.NewInt(1000000)}},
 	}, testKey, false)
 	if blocks > 0 {
 		chain, _ := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, blocks, generator)
 		err := m.InsertChain(chain)
-		require.NoError(t, err)
+		require.NoError(tb, err)
 	}
 	return m
 }
@@ -0,0 +1,257 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package receipts
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/mdbx"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/eth/ethconfig"
+)
+
+const (
+	persistentCacheMaxPending = 64  // writes queued above this are dropped
+	persistentCachePruneEvery = 128 // amount of writes between prunes
+)
+
+type generatedReceipts struct {
+	blockHash common.Hash
+	blockNum  uint64
+	receipts  types.Receipts
+}
+
+// PersistentCache keeps the receipts generated by re-executing blocks in the kv.GeneratedReceipts table of a db
+// of its own, kv.ReceiptsCacheDB, so they survive restarts and are shared by all the generators of a node (p2p
+// and RPC). Having its own db, its writes never wait for or hold up the writes of the chaindata.
+//
+// Writes happen in the background and are best effort: a failed or dropped write only costs a re-execution.
+// A read-only cache only looks receipts up, e.g. in an RPC daemon sharing the datadir of an erigon node.
+type PersistentCache struct {
+	db       kv.RwDB
+	readonly bool
+	cfg      ethconfig.PersistentReceiptsCache
+	logger   log.Logger
+
+	lock     sync.Mutex
+	pending  []generatedReceipts
+	head     uint64 // highest head seen by Put, the receipts are pruned relatively to it
+	flushing bool
+	written  int // since the last prune
+	flushed  *sync.Cond
+}
+
+// OpenPersistentCache opens the persistent cache db in dataDir, creating it unless readonly is set.
+func OpenPersistentCache(ctx context.Context, dataDir string, cfg ethconfig.PersistentReceiptsCache, readonly bool, logger log.Logger) (*PersistentCache, error) {
+	db, err := mdbx.New(kv.ReceiptsCacheDB, logger).
+		Path(filepath.Join(dataDir, kv.ReceiptsCacheDB)).
+		WithTableCfg(func(_ kv.TableCfg) kv.TableCfg { return kv.ReceiptsCacheTablesCfg }).
+		MapSize(64 * datasize.GB).
+		GrowthStep(16 * datasize.MB).
+		Readonly(readonly).
+		Accede(readonly).
+		Open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("open receipts cache: %w", err)
+	}
+	return NewPersistentCache(db, cfg, readonly, logger), nil
+}
+
+// NewPersistentCache makes a cache over db, which must have the kv.ReceiptsCacheTablesCfg tables.
+func NewPersistentCache(db kv.RwDB, cfg ethconfig.PersistentReceiptsCache, readonly bool, logger log.Logger) *PersistentCache {
+	c := &PersistentCache{
+		db:       db,
+		readonly: readonly,
+		cfg:      cfg,
+		logger:   logger,
+	}
+	c.flushed = sync.NewCond(&c.lock)
+	return c
+}
+
+// Close waits for the queued writes, then closes the db.
+func (c *PersistentCache) Close() {
+	c.Wait()
+	c.db.Close()
+}
+
+// Get returns the stored receipts of the block, nil if there are none.
+func (c *PersistentCache) Get(ctx context.Context, block *types.Block) (receipts types.Receipts, err error) {
+	err = c.db.View(ctx, func(tx kv.Tx) error {
+		receipts, err = readGeneratedReceipts(tx, block)
+		return err
+	})
+	return receipts, err
+}
+
+// Put queues the receipts of the block to be written. head is the current head of the chain.
+func (c *PersistentCache) Put(block *types.Block, receipts types.Receipts, head uint64) {
+	if c.readonly {
+		return
+	}
+
+	// receipts are shared with the callers and the in-memory cache: write a copy
+	receiptsCopy := make(types.Receipts, len(receipts))
+	for i, r := range receipts {
+		receiptsCopy[i] = r.Copy()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.head = max(c.head, head)
+	if len(c.pending) >= persistentCacheMaxPending {
+		return
+	}
+	c.pending = append(c.pending, generatedReceipts{blockHash: block.Hash(), blockNum: block.NumberU64(), receipts: receiptsCopy})
+	if !c.flushing {
+		c.flushing = true
+		go c.flush()
+	}
+}
+
+// Wait blocks until the queued writes are done.
+func (c *PersistentCache) Wait() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for c.flushing {
+		c.flushed.Wait()
+	}
+}
+
+func (c *PersistentCache) flush() {
+	for {
+		c.lock.Lock()
+		pending, head := c.pending, c.head
+		c.pending = nil
+		if len(pending) == 0 {
+			c.flushing = false
+			c.flushed.Broadcast()
+			c.lock.Unlock()
+			return
+		}
+		c.written += len(pending)
+		prune := c.written >= persistentCachePruneEvery
+		if prune {
+			c.written = 0
+		}
+		c.lock.Unlock()
+
+		if err := c.db.Update(context.Background(), func(tx kv.RwTx) error {
+			for _, p := range pending {
+				if err := writeGeneratedReceipts(tx, p.blockHash, p.blockNum, p.receipts); err != nil {
+					return err
+				}
+			}
+			if prune {
+				return c.Prune(tx, head)
+			}
+			return nil
+		}); err != nil {
+			c.logger.Warn("[receipts] failed to write the persistent cache", "blocks", len(pending), "err", err)
+		}
+	}
+}
+
+// Prune removes the receipts of the blocks deeper than cfg.Depth below head, then the oldest ones above
+// cfg.Blocks. Keys start with the block number, so both are a range of keys from the first one.
+// Receipts of blocks which were reorged out are never looked up again, and go with their range.
+func (c *PersistentCache) Prune(tx kv.RwTx, head uint64) error {
+	var pruneTo uint64 // exclusive
+	if head > c.cfg.Depth {
+		pruneTo = head - c.cfg.Depth
+	}
+	var excess uint64
+	if c.cfg.Blocks > 0 {
+		count, err := tx.Count(kv.GeneratedReceipts)
+		if err != nil {
+			return err
+		}
+		excess = count - min(count, uint64(c.cfg.Blocks))
+	}
+
+	cursor, err := tx.RwCursor(kv.GeneratedReceipts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	for k, _, err := cursor.First(); k != nil; k, _, err = cursor.Next() {
+		if err != nil {
+			return err
+		}
+		if binary.BigEndian.Uint64(k) >= pruneTo && excess == 0 {
+			break
+		}
+		if err := cursor.DeleteCurrent(); err != nil {
+			return err
+		}
+		if excess > 0 {
+			excess--
+		}
+	}
+	return nil
+}
+
+func generatedReceiptsKey(blockHash common.Hash, blockNum uint64) []byte {
+	return append(hexutil.EncodeTs(blockNum), blockHash[:]...)
+}
+
+// readGeneratedReceipts retrieves the receipts of the block stored by writeGeneratedReceipts, nil if there are none.
+func readGeneratedReceipts(tx kv.Getter, block *types.Block) (types.Receipts, error) {
+	blockHash, blockNum := block.Hash(), block.NumberU64()
+	v, err := tx.GetOne(kv.GeneratedReceipts, generatedReceiptsKey(blockHash, blockNum))
+	if err != nil || v == nil {
+		return nil, err
+	}
+	var stored []*types.ReceiptForStorage
+	if err := rlp.DecodeBytes(v, &stored); err != nil {
+		return nil, fmt.Errorf("readGeneratedReceipts: deserialize %d, %w", blockNum, err)
+	}
+	txs := block.Transactions()
+	if len(stored) != len(txs) {
+		return nil, nil
+	}
+	receipts := make(types.Receipts, len(stored))
+	for i, r := range stored {
+		receipts[i] = (*types.Receipt)(r)
+		receipts[i].DeriveFieldsV4ForCachedReceipt(blockHash, blockNum, txs[i].Hash(), true)
+	}
+	return receipts, nil
+}
+
+// writeGeneratedReceipts stores the receipts generated by re-executing a block.
+func writeGeneratedReceipts(tx kv.Putter, blockHash common.Hash, blockNum uint64, receipts types.Receipts) error {
+	stored := make([]*types.ReceiptForStorage, len(receipts))
+	for i, r := range receipts {
+		stored[i] = (*types.ReceiptForStorage)(r)
+	}
+	v, err := rlp.EncodeToBytes(stored)
+	if err != nil {
+		return fmt.Errorf("writeGeneratedReceipts: %w", err)
+	}
+	return tx.Put(kv.GeneratedReceipts, generatedReceiptsKey(blockHash, blockNum), v)
+}
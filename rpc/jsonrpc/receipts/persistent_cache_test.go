@@ -0,0 +1,183 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package receipts_test
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/chain/params"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/rlp"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/execution/stages/mock"
+	"github.com/erigontech/erigon/rpc/jsonrpc/receipts"
+)
+
+// mockWithTransfers builds a chain whose blocks all have txsPerBlock transfers.
+func mockWithTransfers(tb testing.TB, blocks, txsPerBlock int) *mock.MockSentry {
+	signer := types.LatestSignerForChainID(nil)
+	to := common.HexToAddress("0x1000")
+	return mockWithGenerator(tb, blocks, func(i int, block *core.BlockGen) {
+		for j := 0; j < txsPerBlock; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testAddr), to, uint256.NewInt(1000), params.TxGas, nil, nil), *signer, testKey)
+			block.AddTx(tx)
+		}
+	})
+}
+
+func readBlocks(tb testing.TB, m *mock.MockSentry, tx kv.Tx, from, to uint64) []*types.Block {
+	var blocks []*types.Block
+	for i := from; i <= to; i++ {
+		block, err := m.BlockReader.BlockByNumber(m.Ctx, tx, i)
+		require.NoError(tb, err)
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func TestPersistentCache(t *testing.T) {
+	receipts.DisableReceiptsCacheV2(t)
+	m := mockWithTransfers(t, 4, 2)
+	cache, err := receipts.OpenPersistentCache(m.Ctx, t.TempDir(), ethconfig.Defaults.Sync.PersistentReceiptsCache, false /* readonly */, m.Log)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	tx, err := m.DB.BeginTemporalRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	blocks := readBlocks(t, m, tx, 1, 4)
+
+	generator := receipts.NewGenerator(m.BlockReader, m.Engine, time.Minute, ethconfig.ReceiptsCache{}, "test")
+	generator.SetPersistentCache(cache)
+	var generated [][]byte
+	for _, block := range blocks {
+		r, err := generator.GetReceipts(m.Ctx, m.ChainConfig, tx, block)
+		require.NoError(t, err)
+		require.Len(t, r, 2)
+		encoded, err := rlp.EncodeToBytes(r)
+		require.NoError(t, err)
+		generated = append(generated, encoded)
+	}
+	cache.Wait()
+	tx.Rollback()
+
+	tx, err = m.DB.BeginTemporalRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	// a new generator, e.g. after a restart or serving p2p, finds them in the db
+	generator = receipts.NewGenerator(m.BlockReader, m.Engine, time.Minute, ethconfig.ReceiptsCache{}, "test")
+	generator.SetPersistentCache(cache)
+	for i, block := range blocks {
+		stored, err := cache.Get(m.Ctx, block)
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		encoded, err := rlp.EncodeToBytes(stored)
+		require.NoError(t, err)
+		require.Equal(t, generated[i], encoded)
+
+		r, err := generator.GetReceipts(m.Ctx, m.ChainConfig, tx, block)
+		require.NoError(t, err)
+		require.Equal(t, block.Transactions()[1].Hash(), r[1].TxHash)
+		encoded, err = rlp.EncodeToBytes(r)
+		require.NoError(t, err)
+		require.Equal(t, generated[i], encoded)
+	}
+	tx.Rollback()
+
+	type entry struct {
+		blockNum  uint64
+		blockHash common.Hash
+	}
+	cached := func(tx kv.RwTx) (entries []entry) {
+		require.NoError(t, tx.ForEach(kv.GeneratedReceipts, nil, func(k, v []byte) error {
+			entries = append(entries, entry{binary.BigEndian.Uint64(k), common.BytesToHash(k[8:])})
+			return nil
+		}))
+		return entries
+	}
+	require.NoError(t, cache.DB().Update(m.Ctx, func(tx kv.RwTx) error {
+		// non-canonical, e.g. reorged out: it goes with the range of its block number
+		require.NoError(t, receipts.WriteGeneratedReceipts(tx, common.Hash{0x1}, 3, nil))
+
+		pruner := receipts.NewPersistentCache(cache.DB(), ethconfig.PersistentReceiptsCache{Enabled: true, Blocks: 10, Depth: 2}, false /* readonly */, m.Log)
+		require.NoError(t, pruner.Prune(tx, 4))
+		require.ElementsMatch(t, []entry{{2, blocks[1].Hash()}, {3, common.Hash{0x1}}, {3, blocks[2].Hash()}, {4, blocks[3].Hash()}}, cached(tx))
+
+		pruner = receipts.NewPersistentCache(cache.DB(), ethconfig.PersistentReceiptsCache{Enabled: true, Blocks: 1, Depth: 2}, false /* readonly */, m.Log)
+		require.NoError(t, pruner.Prune(tx, 4))
+		require.Equal(t, []entry{{4, blocks[3].Hash()}}, cached(tx))
+		return nil
+	}))
+}
+
+// BenchmarkGetBlockReceipts compares re-executing recent blocks with reading them from the persistent cache,
+// as eth_getBlockReceipts does when the in-memory cache misses.
+func BenchmarkGetBlockReceipts(b *testing.B) {
+	receipts.DisableReceiptsCacheV2(b)
+	m := mockWithTransfers(b, 16, 8)
+
+	tx, err := m.DB.BeginTemporalRo(m.Ctx)
+	require.NoError(b, err)
+	defer func() { tx.Rollback() }()
+	blocks := readBlocks(b, m, tx, 1, 16)
+
+	bench := func(b *testing.B, cache *receipts.PersistentCache) {
+		// the in-memory cache only fits one block: cycling through the blocks always misses it
+		generator := receipts.NewGenerator(m.BlockReader, m.Engine, time.Minute, ethconfig.ReceiptsCache{Blocks: 1}, "bench")
+		generator.SetPersistentCache(cache)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := generator.GetReceipts(m.Ctx, m.ChainConfig, tx, blocks[i%len(blocks)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("execute", func(b *testing.B) {
+		bench(b, nil)
+	})
+
+	dataDir := b.TempDir()
+	cache, err := receipts.OpenPersistentCache(m.Ctx, dataDir, ethconfig.Defaults.Sync.PersistentReceiptsCache, false /* readonly */, m.Log)
+	require.NoError(b, err)
+	generator := receipts.NewGenerator(m.BlockReader, m.Engine, time.Minute, ethconfig.ReceiptsCache{}, "bench")
+	generator.SetPersistentCache(cache)
+	for _, block := range blocks {
+		_, err := generator.GetReceipts(m.Ctx, m.ChainConfig, tx, block)
+		require.NoError(b, err)
+	}
+	cache.Close()
+	tx.Rollback()
+	tx, err = m.DB.BeginTemporalRo(m.Ctx)
+	require.NoError(b, err)
+
+	b.Run("persistent cache", func(b *testing.B) {
+		readonly, err := receipts.OpenPersistentCache(m.Ctx, dataDir, ethconfig.Defaults.Sync.PersistentReceiptsCache, true /* readonly */, m.Log)
+		require.NoError(b, err)
+		defer readonly.Close()
+		bench(b, readonly)
+	})
+}
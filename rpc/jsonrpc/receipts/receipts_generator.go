@@ -15,22 +15,24 @@ import (
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/core/vm/evmtypes"
+	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/execution/consensus"
 	"github.com/erigontech/erigon/polygon/aa"
 	"github.com/erigontech/erigon/turbo/services"
+	"github.com/erigontech/erigon/turbo/shards"
 	"github.com/erigontech/erigon/turbo/transactions"
 	"github.com/google/go-cmp/cmp"
-	lru "github.com/hashicorp/golang-lru/v2"
 )
 
 type Generator struct {
-	receiptsCache *lru.Cache[common.Hash, types.Receipts]
-	receiptCache  *lru.Cache[common.Hash, *types.Receipt]
+	receiptsCache *ttlCache[common.Hash, types.Receipts]
+	receiptCache  *ttlCache[common.Hash, *types.Receipt]
 
 	// blockExecMutex ensuring that only 1 block with given hash
 	// executed at a time - all parallel requests for same hash will wait for results
@@ -45,6 +47,11 @@ type Generator struct {
 	blockReader services.FullBlockReader
 	txNumReader rawdbv3.TxNumsReader
 	engine      consensus.EngineReader
+
+	blockGenerationTime metrics.Histogram
+	txnGenerationTime   metrics.Histogram
+
+	persistentCache *PersistentCache // optional
 }
 
 type ReceiptEnv struct {
@@ -62,16 +69,15 @@ var (
 	receiptsCacheTrace = dbg.EnvBool("R_LRU_TRACE", false)
 )
 
-func NewGenerator(blockReader services.FullBlockReader, engine consensus.EngineReader, evmTimeout time.Duration) *Generator {
-	receiptsCache, err := lru.New[common.Hash, types.Receipts](receiptsCacheLimit) //TODO: is handling both of them a good idea though...?
-	if err != nil {
-		panic(err)
-	}
-
-	receiptCache, err := lru.New[common.Hash, *types.Receipt](receiptsCacheLimit * 100) // think they should be connected in some of that way
-	if err != nil {
-		panic(err)
+// NewGenerator creates a receipts generator with its own cache configured by cacheCfg.
+// metricsLabel distinguishes the cache and generation metrics of this instance, e.g. "rpc" or "p2p".
+func NewGenerator(blockReader services.FullBlockReader, engine consensus.EngineReader, evmTimeout time.Duration, cacheCfg ethconfig.ReceiptsCache, metricsLabel string) *Generator {
+	cacheLimit := cacheCfg.Blocks
+	if cacheLimit <= 0 {
+		cacheLimit = receiptsCacheLimit
 	}
+	receiptsCache := newTTLCache[common.Hash, types.Receipts](cacheLimit, cacheCfg.TTL, fmt.Sprintf(`generator="%s",cache="blocks"`, metricsLabel))  //TODO: is handling both of them a good idea though...?
+	receiptCache := newTTLCache[common.Hash, *types.Receipt](cacheLimit*100, cacheCfg.TTL, fmt.Sprintf(`generator="%s",cache="txns"`, metricsLabel)) // think they should be connected in some of that way
 
 	txNumReader := blockReader.TxnumReader(context.Background())
 
@@ -85,6 +91,9 @@ func NewGenerator(blockReader services.FullBlockReader, engine consensus.EngineR
 		receiptCache:       receiptCache,
 		evmTimeout:         evmTimeout,
 
+		blockGenerationTime: metrics.GetOrCreateHistogram(fmt.Sprintf(`receipts_generation_seconds{generator="%s",kind="block"}`, metricsLabel)),
+		txnGenerationTime:   metrics.GetOrCreateHistogram(fmt.Sprintf(`receipts_generation_seconds{generator="%s",kind="txn"}`, metricsLabel)),
+
 		blockExecMutex: &loaderMutex[common.Hash]{},
 		txnExecMutex:   &loaderMutex[common.Hash]{},
 	}
@@ -96,6 +105,50 @@ func (g *Generator) LogStats() {
 	}
 }
 
+// CacheStats returns the current stats of the per-block and per-transaction receipts caches.
+func (g *Generator) CacheStats() (blocks, txns CacheStats) {
+	return g.receiptsCache.Stats(), g.receiptCache.Stats()
+}
+
+// SetPersistentCache makes the generator look up and store the receipts of whole blocks in c as well.
+func (g *Generator) SetPersistentCache(c *PersistentCache) {
+	g.persistentCache = c
+}
+
+// InvalidateBlocks evicts the cached receipts of the given blocks, and the cached receipts of their transactions
+// unless they were cached for another block since.
+func (g *Generator) InvalidateBlocks(hashes []common.Hash) {
+	for _, hash := range hashes {
+		receipts, ok := g.receiptsCache.Pop(hash)
+		if !ok {
+			continue
+		}
+		for _, r := range receipts {
+			if r == nil {
+				continue
+			}
+			g.receiptCache.RemoveIf(r.TxHash, func(receipt *types.Receipt) bool { return receipt.BlockHash == hash })
+		}
+	}
+}
+
+// InvalidateOnUnwind makes the generator evict the receipts of the blocks unwound by reorgs, as notified by events,
+// until ctx is done. Receipts of the orphaned blocks would otherwise be served until they expire.
+func (g *Generator) InvalidateOnUnwind(ctx context.Context, events *shards.Events) {
+	unwinds, unsubscribe := events.AddUnwindSubscription()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case hashes := <-unwinds:
+				g.InvalidateBlocks(hashes)
+			}
+		}
+	}()
+}
+
 func (g *Generator) GetCachedReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, bool) {
 	return g.receiptsCache.Get(blockHash)
 }
@@ -200,6 +253,7 @@ func (g *Generator) GetReceipt(ctx context.Context, cfg *chain.Config, tx kv.Tem
 		}
 	}
 
+	defer g.txnGenerationTime.ObserveDuration(time.Now())
 	genEnv, err := g.PrepareEnv(ctx, header, cfg, tx, index)
 	if err != nil {
 		return nil, err
@@ -303,6 +357,18 @@ func (g *Generator) GetReceipts(ctx context.Context, cfg *chain.Config, tx kv.Te
 		}
 	}
 
+	if g.persistentCache != nil {
+		receipts, err := g.persistentCache.Get(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		if receipts != nil {
+			g.addToCacheReceipts(block.HeaderNoCopy(), receipts)
+			return receipts, nil
+		}
+	}
+
+	defer g.blockGenerationTime.ObserveDuration(time.Now())
 	genEnv, err := g.PrepareEnv(ctx, block.HeaderNoCopy(), cfg, tx, 0)
 	if err != nil {
 		return nil, err
@@ -347,6 +413,13 @@ func (g *Generator) GetReceipts(ctx context.Context, cfg *chain.Config, tx kv.Te
 	}
 
 	g.addToCacheReceipts(block.HeaderNoCopy(), receipts)
+	if g.persistentCache != nil {
+		var head uint64
+		if headNum := rawdb.ReadCurrentBlockNumber(tx); headNum != nil {
+			head = *headNum
+		}
+		g.persistentCache.Put(block, receipts, head)
+	}
 	return receipts, nil
 }
 
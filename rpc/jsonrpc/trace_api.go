@@ -50,19 +50,21 @@ type TraceAPI interface {
 // TraceAPIImpl is implementation of the TraceAPI interface based on remote Db access
 type TraceAPIImpl struct {
 	*BaseAPI
-	kv            kv.TemporalRoDB
-	maxTraces     uint64
-	gasCap        uint64
-	compatibility bool // Bug for bug compatiblity with OpenEthereum
+	kv             kv.TemporalRoDB
+	maxTraces      uint64
+	filterMaxBytes uint64 // size of the traces from which trace_filter ends its response with an error, unlimited if 0
+	gasCap         uint64
+	compatibility  bool // Bug for bug compatiblity with OpenEthereum
 }
 
 // NewTraceAPI returns NewTraceAPI instance
 func NewTraceAPI(base *BaseAPI, kv kv.TemporalRoDB, cfg *httpcfg.HttpCfg) *TraceAPIImpl {
 	return &TraceAPIImpl{
-		BaseAPI:       base,
-		kv:            kv,
-		maxTraces:     cfg.MaxTraces,
-		gasCap:        cfg.Gascap,
-		compatibility: cfg.TraceCompatibility,
+		BaseAPI:        base,
+		kv:             kv,
+		maxTraces:      cfg.MaxTraces,
+		filterMaxBytes: cfg.TraceFilterMaxBytes,
+		gasCap:         cfg.Gascap,
+		compatibility:  cfg.TraceCompatibility,
 	}
 }
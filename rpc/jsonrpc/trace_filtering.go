@@ -359,22 +359,18 @@ func (api *TraceAPIImpl) filterV3(ctx context.Context, dbtx kv.TemporalTx, fromB
 	}
 	engine := api.engine()
 
-	var json = jsoniter.ConfigCompatibleWithStandardLibrary
 	stream.WriteArrayStart()
-	first := true
 	// Execute all transactions in picked blocks
 
-	count := uint64(^uint(0)) // this just makes it easier to use below
+	w := &traceFilterWriter{stream: stream, first: true, count: uint64(^uint(0)), maxBytes: api.filterMaxBytes}
 	if req.Count != nil {
-		count = *req.Count
+		w.count = *req.Count
+		w.done = w.count == 0
 	}
-	after := uint64(0) // this just makes it easier to use below
 	if req.After != nil {
-		after = *req.After
+		w.after = *req.After
 	}
 	vmConfig := vm.Config{}
-	nSeen := uint64(0)
-	nExported := uint64(0)
 	includeAll := len(fromAddresses) == 0 && len(toAddresses) == 0
 
 	var lastBlockHash common.Hash
@@ -386,41 +382,20 @@ func (api *TraceAPIImpl) filterV3(ctx context.Context, dbtx kv.TemporalTx, fromB
 	stateReader.SetTx(dbtx)
 	noop := state.NewNoopWriter()
 	isPos := false
-	for it.HasNext() {
+	for !w.done && it.HasNext() {
 		txNum, blockNum, txIndex, isFnalTxn, blockNumChanged, err := it.Next()
 		if err != nil {
-			if first {
-				first = false
-			} else {
-				stream.WriteMore()
-			}
-			stream.WriteObjectStart()
-			rpc.HandleError(err, stream)
-			stream.WriteObjectEnd()
+			w.writeError(err)
 			continue
 		}
 
 		if blockNumChanged {
 			if lastHeader, err = api._blockReader.HeaderByNumber(ctx, dbtx, blockNum); err != nil {
-				if first {
-					first = false
-				} else {
-					stream.WriteMore()
-				}
-				stream.WriteObjectStart()
-				rpc.HandleError(err, stream)
-				stream.WriteObjectEnd()
+				w.writeError(err)
 				continue
 			}
 			if lastHeader == nil {
-				if first {
-					first = false
-				} else {
-					stream.WriteMore()
-				}
-				stream.WriteObjectStart()
-				rpc.HandleError(fmt.Errorf("header not found: %d", blockNum), stream)
-				stream.WriteObjectEnd()
+				w.writeError(fmt.Errorf("header not found: %d", blockNum))
 				continue
 			}
 
@@ -444,20 +419,12 @@ func (api *TraceAPIImpl) filterV3(ctx context.Context, dbtx kv.TemporalTx, fromB
 
 			body, _, err := api._blockReader.Body(ctx, dbtx, lastBlockHash, blockNum)
 			if err != nil {
-				if first {
-					first = false
-				} else {
-					stream.WriteMore()
-				}
-				stream.WriteObjectStart()
-				rpc.HandleError(err, stream)
-				stream.WriteObjectEnd()
+				w.writeError(err)
 				continue
 			}
 			// Block reward section, handle specially
 			minerReward, uncleRewards := ethash.AccumulateRewards(chainConfig, lastHeader, body.Uncles)
 			if _, ok := toAddresses[lastHeader.Coinbase]; ok || includeAll {
-				nSeen++
 				var tr ParityTrace
 				var rewardAction = &RewardTraceAction{}
 				rewardAction.Author = lastHeader.Coinbase
@@ -470,34 +437,13 @@ func (api *TraceAPIImpl) filterV3(ctx context.Context, dbtx kv.TemporalTx, fromB
 				*tr.BlockNumber = blockNum
 				tr.Type = "reward" // nolint: goconst
 				tr.TraceAddress = []int{}
-				b, err := json.Marshal(tr)
-				if err != nil {
-					if first {
-						first = false
-					} else {
-						stream.WriteMore()
-					}
-					stream.WriteObjectStart()
-					rpc.HandleError(err, stream)
-					stream.WriteObjectEnd()
-					continue
-				}
-				if nSeen > after && nExported < count {
-					if first {
-						first = false
-					} else {
-						stream.WriteMore()
-					}
-					if _, err := stream.Write(b); err != nil {
-						return err
-					}
-					nExported++
+				if err := w.writeTrace(&tr); err != nil {
+					return err
 				}
 			}
 			for i, uncle := range body.Uncles {
 				if _, ok := toAddresses[uncle.Coinbase]; ok || includeAll {
 					if i < len(uncleRewards) {
-						nSeen++
 						var tr ParityTrace
 						rewardAction := &RewardTraceAction{}
 						rewardAction.Author = uncle.Coinbase
@@ -510,28 +456,8 @@ func (api *TraceAPIImpl) filterV3(ctx context.Context, dbtx kv.TemporalTx, fromB
 						*tr.BlockNumber = blockNum
 						tr.Type = "reward" // nolint: goconst
 						tr.TraceAddress = []int{}
-						b, err := json.Marshal(tr)
-						if err != nil {
-							if first {
-								first = false
-							} else {
-								stream.WriteMore()
-							}
-							stream.WriteObjectStart()
-							rpc.HandleError(err, stream)
-							stream.WriteObjectEnd()
-							continue
-						}
-						if nSeen > after && nExported < count {
-							if first {
-								first = false
-							} else {
-								stream.WriteMore()
-							}
-							if _, err := stream.Write(b); err != nil {
-								return err
-							}
-							nExported++
+						if err := w.writeTrace(&tr); err != nil {
+							return err
 						}
 					}
 				}
@@ -545,14 +471,7 @@ func (api *TraceAPIImpl) filterV3(ctx context.Context, dbtx kv.TemporalTx, fromB
 		//fmt.Printf("txNum=%d, blockNum=%d, txIndex=%d\n", txNum, blockNum, txIndex)
 		txn, err := api._txnReader.TxnByIdxInBlock(ctx, dbtx, blockNum, txIndex)
 		if err != nil {
-			if first {
-				first = false
-			} else {
-				stream.WriteMore()
-			}
-			stream.WriteObjectStart()
-			rpc.HandleError(err, stream)
-			stream.WriteObjectEnd()
+			w.writeError(err)
 			continue
 		}
 		if txn == nil {
@@ -561,14 +480,7 @@ func (api *TraceAPIImpl) filterV3(ctx context.Context, dbtx kv.TemporalTx, fromB
 		txHash := txn.Hash()
 		msg, err := txn.AsMessage(*lastSigner, lastHeader.BaseFee, lastRules)
 		if err != nil {
-			if first {
-				first = false
-			} else {
-				stream.WriteMore()
-			}
-			stream.WriteObjectStart()
-			rpc.HandleError(err, stream)
-			stream.WriteObjectEnd()
+			w.writeError(err)
 			continue
 		}
 
@@ -611,14 +523,7 @@ func (api *TraceAPIImpl) filterV3(ctx context.Context, dbtx kv.TemporalTx, fromB
 			if ot.Tracer() != nil && ot.Tracer().Hooks.OnTxEnd != nil {
 				ot.Tracer().OnTxEnd(nil, err)
 			}
-			if first {
-				first = false
-			} else {
-				stream.WriteMore()
-			}
-			stream.WriteObjectStart()
-			rpc.HandleError(err, stream)
-			stream.WriteObjectEnd()
+			w.writeError(err)
 			continue
 		}
 		if ot.Tracer() != nil && ot.Tracer().Hooks.OnTxEnd != nil {
@@ -626,57 +531,22 @@ func (api *TraceAPIImpl) filterV3(ctx context.Context, dbtx kv.TemporalTx, fromB
 		}
 		traceResult.Output = common.Copy(execResult.ReturnData)
 		if err = ibs.FinalizeTx(evm.ChainRules(), noop); err != nil {
-			if first {
-				first = false
-			} else {
-				stream.WriteMore()
-			}
-			stream.WriteObjectStart()
-			rpc.HandleError(err, stream)
-			stream.WriteObjectEnd()
+			w.writeError(err)
 			continue
 		}
 		if err = ibs.CommitBlock(evm.ChainRules(), cachedWriter); err != nil {
-			if first {
-				first = false
-			} else {
-				stream.WriteMore()
-			}
-			stream.WriteObjectStart()
-			rpc.HandleError(err, stream)
-			stream.WriteObjectEnd()
+			w.writeError(err)
 			continue
 		}
 		isIntersectionMode := req.Mode == TraceFilterModeIntersection
 		for _, pt := range traceResult.Trace {
 			if includeAll || filterTrace(pt, fromAddresses, toAddresses, isIntersectionMode) {
-				nSeen++
 				pt.BlockHash = &lastBlockHash
 				pt.BlockNumber = &blockNum
 				pt.TransactionHash = &txHash
 				pt.TransactionPosition = &txIndexU64
-				b, err := json.Marshal(pt)
-				if err != nil {
-					if first {
-						first = false
-					} else {
-						stream.WriteMore()
-					}
-					stream.WriteObjectStart()
-					rpc.HandleError(err, stream)
-					stream.WriteObjectEnd()
-					continue
-				}
-				if nSeen > after && nExported < count {
-					if first {
-						first = false
-					} else {
-						stream.WriteMore()
-					}
-					if _, err := stream.Write(b); err != nil {
-						return err
-					}
-					nExported++
+				if err := w.writeTrace(pt); err != nil {
+					return err
 				}
 			}
 		}
@@ -685,6 +555,71 @@ func (api *TraceAPIImpl) filterV3(ctx context.Context, dbtx kv.TemporalTx, fromB
 	return stream.Flush()
 }
 
+// traceFilterFlushSize is the size of the buffered response from which trace_filter flushes it to the client, for
+// its memory not to grow with the number of traces.
+const traceFilterFlushSize = 64 * 1024
+
+// traceFilterWriter writes the traces matching trace_filter to the array of its response, streamed as the blocks are
+// replayed: the first after traces are skipped, then count are written, up to the response size limit.
+type traceFilterWriter struct {
+	stream   jsonstream.Stream
+	after    uint64
+	count    uint64
+	maxBytes uint64 // size of the traces from which the array is ended by an error, unlimited if 0
+
+	first     bool
+	nSeen     uint64 // matching traces, skipped or written
+	nExported uint64
+	nBytes    uint64
+	done      bool // whether the traces to come aren't written anymore, the following blocks don't need replaying
+}
+
+func (w *traceFilterWriter) writeMore() {
+	if w.first {
+		w.first = false
+	} else {
+		w.stream.WriteMore()
+	}
+}
+
+// writeError writes an error object to the array.
+func (w *traceFilterWriter) writeError(err error) {
+	w.writeMore()
+	w.stream.WriteObjectStart()
+	rpc.HandleError(err, w.stream)
+	w.stream.WriteObjectEnd()
+}
+
+// writeTrace writes a matching trace to the array, unless skipped or past the count or the size limit. It returns
+// the error of flushing the response to the client.
+func (w *traceFilterWriter) writeTrace(pt *ParityTrace) error {
+	w.nSeen++
+	if w.done || w.nSeen <= w.after {
+		return nil
+	}
+	b, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(pt)
+	if err != nil {
+		w.writeError(err)
+		return nil
+	}
+	if w.maxBytes > 0 && w.nBytes+uint64(len(b)) > w.maxBytes {
+		w.writeError(fmt.Errorf("trace_filter response exceeds %d bytes (--trace.filter.maxbytes), use a narrower block range or after and count to page it", w.maxBytes))
+		w.done = true
+		return nil
+	}
+	w.writeMore()
+	if _, err := w.stream.Write(b); err != nil {
+		return err
+	}
+	w.nBytes += uint64(len(b))
+	w.nExported++
+	w.done = w.nExported >= w.count
+	if len(w.stream.Buffer()) >= traceFilterFlushSize {
+		return w.stream.Flush()
+	}
+	return nil
+}
+
 func filterTrace(pt *ParityTrace, fromAddresses map[common.Address]struct{}, toAddresses map[common.Address]struct{}, isIntersectionMode bool) bool {
 	f, t := false, false
 	switch action := pt.Action.(type) {
@@ -22,6 +22,7 @@ import (
 	"strconv"
 
 	"github.com/erigontech/erigon-db/rawdb"
+	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/hexutil"
 	"github.com/erigontech/erigon-lib/gointerfaces"
@@ -35,6 +36,7 @@ import (
 type TxPoolAPI interface {
 	Content(ctx context.Context) (map[string]map[string]map[string]*ethapi.RPCTransaction, error)
 	ContentFrom(ctx context.Context, addr common.Address) (map[string]map[string]*ethapi.RPCTransaction, error)
+	Inspect(ctx context.Context) (map[string]map[string]map[string]string, error)
 }
 
 // TxPoolAPIImpl data structure to store things needed for net_ commands
@@ -53,90 +55,88 @@ func NewTxPoolAPI(base *BaseAPI, db kv.TemporalRoDB, pool proto_txpool.TxpoolCli
 	}
 }
 
-func (api *TxPoolAPIImpl) Content(ctx context.Context) (map[string]map[string]map[string]*ethapi.RPCTransaction, error) {
-	reply, err := api.pool.All(ctx, &proto_txpool.AllRequest{})
-	if err != nil {
-		return nil, err
-	}
+// subPoolNames are the keys of the sub-pools in the replies of the txpool_ commands.
+var subPoolNames = map[proto_txpool.AllReply_TxnType]string{
+	proto_txpool.AllReply_PENDING:  "pending",
+	proto_txpool.AllReply_BASE_FEE: "baseFee",
+	proto_txpool.AllReply_QUEUED:   "queued",
+}
 
-	content := map[string]map[string]map[string]*ethapi.RPCTransaction{
-		"pending": make(map[string]map[string]*ethapi.RPCTransaction),
-		"baseFee": make(map[string]map[string]*ethapi.RPCTransaction),
-		"queued":  make(map[string]map[string]*ethapi.RPCTransaction),
+// forEachPoolTxn calls f for the transactions of the pool, or only for the ones of sender if set, in sender then
+// nonce order. The transactions are fetched a page at a time, so that large pools aren't copied at once.
+func (api *TxPoolAPIImpl) forEachPoolTxn(ctx context.Context, sender *common.Address, f func(sender common.Address, subPool string, txn types.Transaction)) error {
+	req := &proto_txpool.ContentRequest{}
+	if sender != nil {
+		req.Sender = gointerfaces.ConvertAddressToH160(*sender)
 	}
-
-	pending := make(map[common.Address][]types.Transaction, 8)
-	baseFee := make(map[common.Address][]types.Transaction, 8)
-	queued := make(map[common.Address][]types.Transaction, 8)
-	for i := range reply.Txs {
-		txn, err := types.DecodeWrappedTransaction(reply.Txs[i].RlpTx)
+	for {
+		reply, err := api.pool.Content(ctx, req)
 		if err != nil {
-			return nil, fmt.Errorf("decoding transaction from: %x: %w", reply.Txs[i].RlpTx, err)
+			return err
 		}
-		addr := gointerfaces.ConvertH160toAddress(reply.Txs[i].Sender)
-		switch reply.Txs[i].TxnType {
-		case proto_txpool.AllReply_PENDING:
-			if _, ok := pending[addr]; !ok {
-				pending[addr] = make([]types.Transaction, 0, 4)
-			}
-			pending[addr] = append(pending[addr], txn)
-		case proto_txpool.AllReply_BASE_FEE:
-			if _, ok := baseFee[addr]; !ok {
-				baseFee[addr] = make([]types.Transaction, 0, 4)
+		for _, t := range reply.Txs {
+			subPool, ok := subPoolNames[t.TxnType]
+			if !ok {
+				continue
 			}
-			baseFee[addr] = append(baseFee[addr], txn)
-		case proto_txpool.AllReply_QUEUED:
-			if _, ok := queued[addr]; !ok {
-				queued[addr] = make([]types.Transaction, 0, 4)
+			txn, err := types.DecodeWrappedTransaction(t.RlpTx)
+			if err != nil {
+				return fmt.Errorf("decoding transaction from: %x: %w", t.RlpTx, err)
 			}
-			queued[addr] = append(queued[addr], txn)
+			from := gointerfaces.ConvertH160toAddress(t.Sender)
+			txn.SetSender(from) // recovered by the pool already
+			f(from, subPool, txn)
+		}
+		if len(reply.NextPageToken) == 0 {
+			return nil
 		}
+		req.PageToken = reply.NextPageToken
 	}
+}
 
+// currentHeader returns the head the pending transactions are rendered on top of, nil if there is none.
+func (api *TxPoolAPIImpl) currentHeader(ctx context.Context) (*types.Header, *chain.Config, error) {
 	tx, err := api.db.BeginTemporalRo(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer tx.Rollback()
 	cc, err := api.chainConfig(ctx, tx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	return rawdb.ReadCurrentHeader(tx), cc, nil
+}
 
-	curHeader := rawdb.ReadCurrentHeader(tx)
-	if curHeader == nil {
-		return nil, nil
-	}
-	// Flatten the pending transactions
-	for account, txs := range pending {
-		dump := make(map[string]*ethapi.RPCTransaction, len(txs))
-		for _, txn := range txs {
-			dump[strconv.FormatUint(txn.GetNonce(), 10)] = newRPCPendingTransaction(txn, curHeader, cc)
-		}
-		content["pending"][account.Hex()] = dump
+// Content returns the transactions of the pool, grouped by sub-pool, sender and nonce.
+func (api *TxPoolAPIImpl) Content(ctx context.Context) (map[string]map[string]map[string]*ethapi.RPCTransaction, error) {
+	curHeader, cc, err := api.currentHeader(ctx)
+	if err != nil || curHeader == nil {
+		return nil, err
 	}
-	// Flatten the baseFee transactions
-	for account, txs := range baseFee {
-		dump := make(map[string]*ethapi.RPCTransaction, len(txs))
-		for _, txn := range txs {
-			dump[strconv.FormatUint(txn.GetNonce(), 10)] = newRPCPendingTransaction(txn, curHeader, cc)
-		}
-		content["baseFee"][account.Hex()] = dump
+
+	content := map[string]map[string]map[string]*ethapi.RPCTransaction{
+		"pending": make(map[string]map[string]*ethapi.RPCTransaction),
+		"baseFee": make(map[string]map[string]*ethapi.RPCTransaction),
+		"queued":  make(map[string]map[string]*ethapi.RPCTransaction),
 	}
-	// Flatten the queued transactions
-	for account, txs := range queued {
-		dump := make(map[string]*ethapi.RPCTransaction, len(txs))
-		for _, txn := range txs {
-			dump[strconv.FormatUint(txn.GetNonce(), 10)] = newRPCPendingTransaction(txn, curHeader, cc)
+	if err := api.forEachPoolTxn(ctx, nil, func(sender common.Address, subPool string, txn types.Transaction) {
+		dump, ok := content[subPool][sender.Hex()]
+		if !ok {
+			dump = make(map[string]*ethapi.RPCTransaction)
+			content[subPool][sender.Hex()] = dump
 		}
-		content["queued"][account.Hex()] = dump
+		dump[strconv.FormatUint(txn.GetNonce(), 10)] = newRPCPendingTransaction(txn, curHeader, cc)
+	}); err != nil {
+		return nil, err
 	}
 	return content, nil
 }
 
+// ContentFrom returns the transactions of the given sender in the pool, grouped by sub-pool and nonce.
 func (api *TxPoolAPIImpl) ContentFrom(ctx context.Context, addr common.Address) (map[string]map[string]*ethapi.RPCTransaction, error) {
-	reply, err := api.pool.All(ctx, &proto_txpool.AllRequest{})
-	if err != nil {
+	curHeader, cc, err := api.currentHeader(ctx)
+	if err != nil || curHeader == nil {
 		return nil, err
 	}
 
@@ -145,63 +145,41 @@ func (api *TxPoolAPIImpl) ContentFrom(ctx context.Context, addr common.Address)
 		"baseFee": make(map[string]*ethapi.RPCTransaction),
 		"queued":  make(map[string]*ethapi.RPCTransaction),
 	}
-
-	pending := make([]types.Transaction, 0, 4)
-	baseFee := make([]types.Transaction, 0, 4)
-	queued := make([]types.Transaction, 0, 4)
-	for i := range reply.Txs {
-		txn, err := types.DecodeWrappedTransaction(reply.Txs[i].RlpTx)
-		if err != nil {
-			return nil, fmt.Errorf("decoding transaction from: %x: %w", reply.Txs[i].RlpTx, err)
-		}
-		sender := gointerfaces.ConvertH160toAddress(reply.Txs[i].Sender)
-		if sender != addr {
-			continue
-		}
-
-		switch reply.Txs[i].TxnType {
-		case proto_txpool.AllReply_PENDING:
-			pending = append(pending, txn)
-		case proto_txpool.AllReply_BASE_FEE:
-			baseFee = append(baseFee, txn)
-		case proto_txpool.AllReply_QUEUED:
-			queued = append(queued, txn)
-		}
+	if err := api.forEachPoolTxn(ctx, &addr, func(_ common.Address, subPool string, txn types.Transaction) {
+		content[subPool][strconv.FormatUint(txn.GetNonce(), 10)] = newRPCPendingTransaction(txn, curHeader, cc)
+	}); err != nil {
+		return nil, err
 	}
+	return content, nil
+}
 
-	tx, err := api.db.BeginTemporalRo(ctx)
-	if err != nil {
-		return nil, err
+// Inspect returns the transactions of the pool grouped like Content, each flattened into a human-readable summary
+// of its recipient, value, gas limit and fee cap.
+func (api *TxPoolAPIImpl) Inspect(ctx context.Context) (map[string]map[string]map[string]string, error) {
+	content := map[string]map[string]map[string]string{
+		"pending": make(map[string]map[string]string),
+		"baseFee": make(map[string]map[string]string),
+		"queued":  make(map[string]map[string]string),
 	}
-	defer tx.Rollback()
-	cc, err := api.chainConfig(ctx, tx)
-	if err != nil {
+	if err := api.forEachPoolTxn(ctx, nil, func(sender common.Address, subPool string, txn types.Transaction) {
+		dump, ok := content[subPool][sender.Hex()]
+		if !ok {
+			dump = make(map[string]string)
+			content[subPool][sender.Hex()] = dump
+		}
+		dump[strconv.FormatUint(txn.GetNonce(), 10)] = inspectTxn(txn)
+	}); err != nil {
 		return nil, err
 	}
+	return content, nil
+}
 
-	curHeader := rawdb.ReadCurrentHeader(tx)
-	if curHeader == nil {
-		return nil, nil
-	}
-	// Flatten the pending transactions
-	dump := make(map[string]*ethapi.RPCTransaction, len(pending))
-	for _, txn := range pending {
-		dump[strconv.FormatUint(txn.GetNonce(), 10)] = newRPCPendingTransaction(txn, curHeader, cc)
-	}
-	content["pending"] = dump
-	// Flatten the baseFee transactions
-	dump = make(map[string]*ethapi.RPCTransaction, len(baseFee))
-	for _, txn := range baseFee {
-		dump[strconv.FormatUint(txn.GetNonce(), 10)] = newRPCPendingTransaction(txn, curHeader, cc)
+// inspectTxn flattens a transaction into the summary reported by txpool_inspect.
+func inspectTxn(txn types.Transaction) string {
+	if to := txn.GetTo(); to != nil {
+		return fmt.Sprintf("%s: %s wei + %d gas × %s wei", to.Hex(), txn.GetValue().Dec(), txn.GetGasLimit(), txn.GetFeeCap().Dec())
 	}
-	content["baseFee"] = dump
-	// Flatten the queued transactions
-	dump = make(map[string]*ethapi.RPCTransaction, len(queued))
-	for _, txn := range queued {
-		dump[strconv.FormatUint(txn.GetNonce(), 10)] = newRPCPendingTransaction(txn, curHeader, cc)
-	}
-	content["queued"] = dump
-	return content, nil
+	return fmt.Sprintf("contract creation: %s wei + %d gas × %s wei", txn.GetValue().Dec(), txn.GetGasLimit(), txn.GetFeeCap().Dec())
 }
 
 // Status returns the number of pending and queued transaction in the pool.
@@ -216,41 +194,3 @@ func (api *TxPoolAPIImpl) Status(ctx context.Context) (map[string]hexutil.Uint,
 		"queued":  hexutil.Uint(reply.QueuedCount),
 	}, nil
 }
-
-/*
-
-// Inspect retrieves the content of the transaction pool and flattens it into an
-// easily inspectable list.
-func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
-	content := map[string]map[string]map[string]string{
-		"pending": make(map[string]map[string]string),
-		"queued":  make(map[string]map[string]string),
-	}
-	pending, queue := s.b.TxPoolContent()
-
-	// Define a formatter to flatten a transaction into a string
-	var format = func(tx *types.Transaction) string {
-		if to := tx.To(); to != nil {
-			return fmt.Sprintf("%s: %v wei + %v gas × %v wei", tx.To().Hex(), tx.Value(), tx.Gas(), tx.GasPrice())
-		}
-		return fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.Value(), tx.Gas(), tx.GasPrice())
-	}
-	// Flatten the pending transactions
-	for account, txs := range pending {
-		dump := make(map[string]string)
-		for _, txn := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
-		}
-		content["pending"][account.Hex()] = dump
-	}
-	// Flatten the queued transactions
-	for account, txs := range queue {
-		dump := make(map[string]string)
-		for _, txn := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
-		}
-		content["queued"][account.Hex()] = dump
-	}
-	return content
-}
-*/
@@ -18,6 +18,9 @@ package jsonrpc
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -27,6 +30,8 @@ import (
 	"github.com/erigontech/erigon-lib/chain/params"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/direct"
+	"github.com/erigontech/erigon-lib/gointerfaces"
 	txpool "github.com/erigontech/erigon-lib/gointerfaces/txpoolproto"
 	"github.com/erigontech/erigon-lib/kv/kvcache"
 	"github.com/erigontech/erigon-lib/types"
@@ -78,3 +83,163 @@ func TestTxPoolContent(t *testing.T) {
 	require.Equal(status["pending"], hexutil.Uint(1))
 	require.Equal(status["queued"], hexutil.Uint(0))
 }
+
+// stubTxPoolServer serves the given transactions from Content, pageSize at a time. The page token is the index of
+// the first transaction of the page.
+type stubTxPoolServer struct {
+	txpool.UnimplementedTxpoolServer
+	txs      []*txpool.AllReply_Tx
+	pageSize int
+	calls    int
+}
+
+func (s *stubTxPoolServer) Content(_ context.Context, in *txpool.ContentRequest) (*txpool.ContentReply, error) {
+	s.calls++
+	txs := s.txs
+	if in.Sender != nil {
+		txs = nil
+		for _, t := range s.txs {
+			if gointerfaces.ConvertH160toAddress(t.Sender) == gointerfaces.ConvertH160toAddress(in.Sender) {
+				txs = append(txs, t)
+			}
+		}
+	}
+	var from int
+	if len(in.PageToken) > 0 {
+		from = int(binary.BigEndian.Uint64(in.PageToken))
+	}
+	to := min(from+s.pageSize, len(txs))
+	reply := &txpool.ContentReply{Txs: txs[from:to]}
+	if to < len(txs) {
+		reply.NextPageToken = binary.BigEndian.AppendUint64(nil, uint64(to))
+	}
+	return reply, nil
+}
+
+var (
+	stubSender1 = common.HexToAddress("0x26588a9301b0428d95e6fc3a5024fce8bec12d51")
+	stubSender2 = common.HexToAddress("0x0216d5032f356960cd3749c31ab34eeff21b3395")
+)
+
+// newStubTxPoolServer returns a pool of unsigned transactions, their senders being the ones the pool reports.
+func newStubTxPoolServer(t *testing.T, pageSize int) *stubTxPoolServer {
+	s := &stubTxPoolServer{pageSize: pageSize}
+	add := func(sender common.Address, subPool txpool.AllReply_TxnType, txn types.Transaction) {
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, txn.MarshalBinary(buf))
+		s.txs = append(s.txs, &txpool.AllReply_Tx{Sender: gointerfaces.ConvertAddressToH160(sender), TxnType: subPool, RlpTx: buf.Bytes()})
+	}
+	legacy := func(nonce uint64, to common.Address, value *uint256.Int, gas uint64) *types.LegacyTx {
+		txn := types.NewTransaction(nonce, to, value, gas, uint256.NewInt(20*common.GWei), nil)
+		txn.V.SetUint64(27) // not replay-protected
+		return txn
+	}
+	dai := common.HexToAddress("0x6b175474e89094c44da98b954eedeac495271d0f")
+	add(stubSender2, txpool.AllReply_PENDING, legacy(806, dai, uint256.NewInt(common.Ether), params.TxGas))
+	add(stubSender2, txpool.AllReply_PENDING, legacy(807, dai, uint256.NewInt(common.Ether), params.TxGas))
+	add(stubSender2, txpool.AllReply_QUEUED, &types.DynamicFeeTransaction{
+		CommonTx: types.CommonTx{Nonce: 810, GasLimit: 100_000, Value: uint256.NewInt(0)},
+		ChainID:  uint256.NewInt(1),
+		TipCap:   uint256.NewInt(common.GWei),
+		FeeCap:   uint256.NewInt(30 * common.GWei),
+	})
+	add(stubSender1, txpool.AllReply_PENDING, legacy(31813, common.HexToAddress("0x3375ee30428b2a71c428afa5e89e427905f95f7e"), uint256.NewInt(0), 500_000))
+	return s
+}
+
+func TestTxPoolInspect(t *testing.T) {
+	pool := newStubTxPoolServer(t, 2)
+	api := NewTxPoolAPI(nil, nil, direct.NewTxPoolClient(pool))
+
+	inspect, err := api.Inspect(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, pool.calls)
+
+	// the format of geth, with the baseFee sub-pool of erigon
+	expected := `{
+		"pending": {
+			"0x0216D5032f356960Cd3749C31Ab34eEFF21B3395": {
+				"806": "0x6B175474E89094C44Da98b954EedeAC495271d0F: 1000000000000000000 wei + 21000 gas × 20000000000 wei",
+				"807": "0x6B175474E89094C44Da98b954EedeAC495271d0F: 1000000000000000000 wei + 21000 gas × 20000000000 wei"
+			},
+			"0x26588a9301b0428d95e6Fc3A5024fcE8BEc12D51": {
+				"31813": "0x3375Ee30428b2A71c428afa5E89e427905F95F7e: 0 wei + 500000 gas × 20000000000 wei"
+			}
+		},
+		"baseFee": {},
+		"queued": {
+			"0x0216D5032f356960Cd3749C31Ab34eEFF21B3395": {
+				"810": "contract creation: 0 wei + 100000 gas × 30000000000 wei"
+			}
+		}
+	}`
+	actual, err := json.Marshal(inspect)
+	require.NoError(t, err)
+	require.JSONEq(t, expected, string(actual))
+}
+
+func TestTxPoolContentFromStub(t *testing.T) {
+	m := mock.Mock(t)
+	pool := newStubTxPoolServer(t, 1)
+	api := NewTxPoolAPI(newBaseApiForTest(m), m.DB, direct.NewTxPoolClient(pool))
+
+	content, err := api.ContentFrom(context.Background(), stubSender2)
+	require.NoError(t, err)
+	require.Equal(t, 3, pool.calls)
+	encoded, err := json.Marshal(content)
+	require.NoError(t, err)
+	var actual map[string]map[string]map[string]any
+	require.NoError(t, json.Unmarshal(encoded, &actual))
+
+	// the fields of geth which don't depend on the signatures
+	expected := `{
+		"pending": {
+			"806": {
+				"blockHash": null,
+				"blockNumber": null,
+				"from": "0x0216d5032f356960cd3749c31ab34eeff21b3395",
+				"gas": "0x5208",
+				"gasPrice": "0x4a817c800",
+				"input": "0x",
+				"nonce": "0x326",
+				"to": "0x6b175474e89094c44da98b954eedeac495271d0f",
+				"transactionIndex": null,
+				"type": "0x0",
+				"value": "0xde0b6b3a7640000"
+			},
+			"807": {
+				"nonce": "0x327"
+			}
+		},
+		"baseFee": {},
+		"queued": {
+			"810": {
+				"blockHash": null,
+				"from": "0x0216d5032f356960cd3749c31ab34eeff21b3395",
+				"gas": "0x186a0",
+				"maxFeePerGas": "0x6fc23ac00",
+				"maxPriorityFeePerGas": "0x3b9aca00",
+				"chainId": "0x1",
+				"nonce": "0x32a",
+				"to": null,
+				"type": "0x2",
+				"value": "0x0"
+			}
+		}
+	}`
+	var fixture map[string]map[string]map[string]any
+	require.NoError(t, json.Unmarshal([]byte(expected), &fixture))
+	require.Len(t, actual, len(fixture))
+	for subPool, txs := range fixture {
+		require.Len(t, actual[subPool], len(txs), subPool)
+		for nonce, fields := range txs {
+			require.Contains(t, actual[subPool], nonce)
+			for field, value := range fields {
+				require.Equal(t, value, actual[subPool][nonce][field], "%s %s %s", subPool, nonce, field)
+			}
+			for _, field := range []string{"hash", "v", "r", "s"} {
+				require.Contains(t, actual[subPool][nonce], field)
+			}
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/erigontech/erigon-lib/metrics"
 )
@@ -31,8 +32,41 @@ var (
 	rpcMetricsLabels   = map[bool]map[string]string{}
 	rpcRequestGauge    = metrics.GetOrCreateCounter("rpc_total")
 	failedReqeustGauge = metrics.GetOrCreateCounter("rpc_failure")
+	rpcInflightGauge   = metrics.GetOrCreateGauge("rpc_inflight")
+	rpcBytesInCounter  = metrics.GetOrCreateCounter("rpc_bytes_in")
+	rpcBytesOutCounter = metrics.GetOrCreateCounter("rpc_bytes_out")
+
+	rpcMethodMetrics sync.Map // method -> *methodMetrics
 )
 
+// methodMetrics are the metrics of the calls of an rpc method. The counters of the bytes are the sizes of the
+// params of the requests and of the responses.
+type methodMetrics struct {
+	requests metrics.Counter
+	duration metrics.Histogram
+	bytesIn  metrics.Counter
+	bytesOut metrics.Counter
+}
+
+func newMethodMetrics(method string) *methodMetrics {
+	return &methodMetrics{
+		requests: metrics.GetOrCreateCounter(fmt.Sprintf(`rpc_requests{method="%s"}`, method)),
+		duration: metrics.GetOrCreateHistogram(fmt.Sprintf(`rpc_duration_histogram_seconds{method="%s"}`, method)),
+		bytesIn:  metrics.GetOrCreateCounter(fmt.Sprintf(`rpc_request_bytes{method="%s"}`, method)),
+		bytesOut: metrics.GetOrCreateCounter(fmt.Sprintf(`rpc_response_bytes{method="%s"}`, method)),
+	}
+}
+
+// getMethodMetrics returns the metrics of the given method, registering them unless PreAllocateRPCMetricLabels did.
+// It must only be called for the registered methods, so that clients can't create metrics.
+func getMethodMetrics(method string) *methodMetrics {
+	if m, ok := rpcMethodMetrics.Load(method); ok {
+		return m.(*methodMetrics)
+	}
+	m, _ := rpcMethodMetrics.LoadOrStore(method, newMethodMetrics(method))
+	return m.(*methodMetrics)
+}
+
 // PreAllocateRPCMetricLabels pre-allocates labels for all rpc methods inside API List
 func PreAllocateRPCMetricLabels(apiList []API) {
 	methods := getRPCMethodNames(apiList)
@@ -50,6 +84,7 @@ func PreAllocateRPCMetricLabels(apiList []API) {
 	for _, method := range methods {
 		successMap[method] = createRPCMetricsLabel(method, true)
 		failureMap[method] = createRPCMetricsLabel(method, false)
+		rpcMethodMetrics.LoadOrStore(method, newMethodMetrics(method))
 	}
 
 	rpcMetricsLabels[true] = successMap
@@ -0,0 +1,93 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+func TestMethodMetrics(t *testing.T) {
+	logger := log.New()
+	server := newTestServer(logger)
+	defer server.Stop()
+	client := DialInProc(server, logger)
+	defer client.Close()
+
+	m := getMethodMetrics("test_echo")
+	requests, bytesIn, bytesOut := m.requests.GetValueUint64(), m.bytesIn.GetValueUint64(), m.bytesOut.GetValueUint64()
+	var result echoResult
+	require.NoError(t, client.Call(&result, "test_echo", "hello", 1))
+	require.Equal(t, requests+1, m.requests.GetValueUint64())
+	require.Equal(t, bytesIn+uint64(len(`["hello",1]`)), m.bytesIn.GetValueUint64())
+	require.Greater(t, m.bytesOut.GetValueUint64(), bytesOut)
+
+	// the connection is reported with its request
+	var found bool
+	connections.Range(func(k, _ any) bool {
+		c := k.(*connStats)
+		found = found || (c.requests.Load() == 1 && c.inflight.Load() == 0 && c.bytesOut.Load() > 0)
+		return !found
+	})
+	require.True(t, found)
+
+	// unknown methods don't create metrics
+	require.Error(t, client.Call(nil, "test_unknown"))
+	_, ok := rpcMethodMetrics.Load("test_unknown")
+	require.False(t, ok)
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	l := newSlowQueryLog(2)
+	l.add("a", 1, time.Second, "")
+	l.add("b", 1, 3*time.Second, "")
+	l.add("c", 1, 2*time.Second, "")
+	top := l.top()
+	require.Len(t, top, 2)
+	require.Equal(t, "b", top[0].Method)
+	require.Equal(t, "c", top[1].Method)
+
+	// the test server logs the calls longer than 100ns
+	logger := log.New()
+	server := newTestServer(logger)
+	defer server.Stop()
+	client := DialInProc(server, logger)
+	defer client.Close()
+	require.NoError(t, client.Call(nil, "test_sleep", 2*time.Millisecond))
+
+	httpsrv := httptest.NewServer(DebugStatsHandler())
+	defer httpsrv.Close()
+	resp, err := http.Get(httpsrv.URL + DebugStatsPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var stats struct {
+		SlowQueries []slowQuery `json:"slowQueries"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+	var found bool
+	for _, q := range stats.SlowQueries {
+		found = found || (q.Method == "test_sleep" && q.ParamsSize == len(`[2000000]`))
+	}
+	require.True(t, found)
+}
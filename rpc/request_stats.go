@@ -0,0 +1,188 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"cmp"
+	"container/heap"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DebugStatsPath is the path DebugStatsHandler is served at, on the metrics server.
+const DebugStatsPath = "/debug/rpc/stats"
+
+// slowQueriesKept is the number of the slowest calls reported by DebugStatsHandler.
+const slowQueriesKept = 100
+
+// slowQueries are the slowest calls served by the servers, which took longer than their slow log threshold.
+var slowQueries = newSlowQueryLog(slowQueriesKept)
+
+// connections are the websocket and IPC connections being served.
+var connections sync.Map // *connStats -> struct{}
+
+// connStats are the statistics of a connection. The counters of the bytes are the sizes of the params of the
+// requests and of the responses.
+type connStats struct {
+	transport  string
+	remoteAddr string
+	inflight   atomic.Int64
+	requests   atomic.Uint64
+	bytesIn    atomic.Uint64
+	bytesOut   atomic.Uint64
+}
+
+func newConnStats(info PeerInfo) *connStats {
+	return &connStats{transport: info.Transport, remoteAddr: info.RemoteAddr}
+}
+
+// begin counts a request of the connection being served, end its completion. Both are no-ops on nil stats.
+func (s *connStats) begin(paramsSize int) {
+	rpcInflightGauge.Inc()
+	rpcBytesInCounter.AddInt(paramsSize)
+	if s != nil {
+		s.inflight.Add(1)
+		s.requests.Add(1)
+		s.bytesIn.Add(uint64(paramsSize))
+	}
+}
+
+func (s *connStats) end() {
+	rpcInflightGauge.Dec()
+	if s != nil {
+		s.inflight.Add(-1)
+	}
+}
+
+// addBytesOut counts a response of the given size, a no-op on nil stats.
+func (s *connStats) addBytesOut(size int) {
+	rpcBytesOutCounter.AddInt(size)
+	if s != nil {
+		s.bytesOut.Add(uint64(size))
+	}
+}
+
+// slowQuery is a call which took longer than the slow log threshold.
+type slowQuery struct {
+	Method     string    `json:"method"`
+	ParamsSize int       `json:"paramsSize"`
+	Duration   string    `json:"duration"`
+	RemoteAddr string    `json:"remoteAddr"`
+	Time       time.Time `json:"time"`
+
+	duration time.Duration
+}
+
+// slowQueryLog keeps the slowest calls, in a min-heap so that the fastest of them is replaced by a slower one.
+type slowQueryLog struct {
+	mu      sync.Mutex
+	limit   int
+	queries slowQueryHeap
+}
+
+func newSlowQueryLog(limit int) *slowQueryLog {
+	return &slowQueryLog{limit: limit}
+}
+
+// add records a slow call, unless limit slower ones are already recorded.
+func (l *slowQueryLog) add(method string, paramsSize int, duration time.Duration, remoteAddr string) {
+	q := slowQuery{Method: method, ParamsSize: paramsSize, Duration: duration.String(), RemoteAddr: remoteAddr,
+		Time: time.Now(), duration: duration}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.queries) < l.limit {
+		heap.Push(&l.queries, q)
+	} else if len(l.queries) > 0 && duration > l.queries[0].duration {
+		l.queries[0] = q
+		heap.Fix(&l.queries, 0)
+	}
+}
+
+// top returns the recorded calls, slowest first.
+func (l *slowQueryLog) top() []slowQuery {
+	l.mu.Lock()
+	queries := slices.Clone(l.queries)
+	l.mu.Unlock()
+	slices.SortFunc(queries, func(a, b slowQuery) int { return cmp.Compare(b.duration, a.duration) })
+	return queries
+}
+
+type slowQueryHeap []slowQuery
+
+func (h slowQueryHeap) Len() int           { return len(h) }
+func (h slowQueryHeap) Less(i, j int) bool { return h[i].duration < h[j].duration }
+func (h slowQueryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *slowQueryHeap) Push(x any)        { *h = append(*h, x.(slowQuery)) }
+func (h *slowQueryHeap) Pop() any {
+	old := *h
+	q := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return q
+}
+
+// connectionStats is a connection as reported by DebugStatsHandler.
+type connectionStats struct {
+	Transport  string `json:"transport"`
+	RemoteAddr string `json:"remoteAddr"`
+	Inflight   int64  `json:"inflight"`
+	Requests   uint64 `json:"requests"`
+	BytesIn    uint64 `json:"bytesIn"`
+	BytesOut   uint64 `json:"bytesOut"`
+}
+
+// DebugStatsHandler reports the slowest calls served by the RPC servers, see --rpc.slow, and the statistics of the
+// websocket and IPC connections being served.
+func DebugStatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var stats struct {
+			SlowQueries []slowQuery       `json:"slowQueries"`
+			Connections []connectionStats `json:"connections"`
+		}
+		stats.SlowQueries = slowQueries.top()
+		connections.Range(func(k, _ any) bool {
+			c := k.(*connStats)
+			stats.Connections = append(stats.Connections, connectionStats{
+				Transport:  c.transport,
+				RemoteAddr: c.remoteAddr,
+				Inflight:   c.inflight.Load(),
+				Requests:   c.requests.Load(),
+				BytesIn:    c.bytesIn.Load(),
+				BytesOut:   c.bytesOut.Load(),
+			})
+			return true
+		})
+		w.Header().Set("content-type", contentType)
+		_ = json.NewEncoder(w).Encode(&stats)
+	})
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
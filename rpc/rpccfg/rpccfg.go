@@ -59,6 +59,13 @@ const DefaultEvmCallTimeout = 5 * time.Minute
 const DefaultOverlayGetLogsTimeout = 5 * time.Minute
 const DefaultOverlayReplayBlockTimeout = 10 * time.Second
 
+// GetLogsConfig bounds the eth_getLogs and erigon_getLogs queries. It's derived from rpc.batch.concurrency and
+// rpc.subscription.filters.maxlogs.
+type GetLogsConfig struct {
+	Workers    int // candidate blocks processed concurrently per query
+	MaxResults int // maximum number of logs per query, 0 = no limit
+}
+
 var SlowLogBlackList = []string{
 	"eth_getBlock", "eth_getBlockByNumber", "eth_getBlockByHash", "eth_blockNumber",
 	"erigon_blockNumber", "erigon_getHeaderByNumber", "erigon_getHeaderByHash", "erigon_getBlockByTimestamp",
@@ -16,14 +16,54 @@
 
 package rpchelper
 
+import (
+	"fmt"
+	"time"
+)
+
 // FiltersConfig defines the configuration settings for RPC subscription filters.
 // Each field represents a limit on the number of respective items that can be stored per subscription.
 type FiltersConfig struct {
-	RpcSubscriptionFiltersMaxLogs      int // Maximum number of logs to store per subscription. Default: 0 (no limit)
+	RpcSubscriptionFiltersMaxLogs      int // Maximum number of logs to store per subscription and to return per eth_getLogs call. Default: 0 (no limit)
 	RpcSubscriptionFiltersMaxHeaders   int // Maximum number of block headers to store per subscription. Default: 0 (no limit)
 	RpcSubscriptionFiltersMaxTxs       int // Maximum number of transactions to store per subscription. Default: 0 (no limit)
 	RpcSubscriptionFiltersMaxAddresses int // Maximum number of addresses per subscription to filter logs by. Default: 0 (no limit)
 	RpcSubscriptionFiltersMaxTopics    int // Maximum number of topics per subscription to filter logs by. Default: 0 (no limit)
+
+	RpcSubscriptionHeadsBufferSize int                // Number of headers buffered per newHeads subscription. Default: 0 (size chosen by the subscriber)
+	RpcSubscriptionLogsBufferSize  int                // Number of logs buffered per logs subscription. Default: 0 (size chosen by the subscriber)
+	RpcSubscriptionTxsBufferSize   int                // Number of transaction batches buffered per pending transactions subscription. Default: 0 (size chosen by the subscriber)
+	RpcSubscriptionSlowConsumer    SlowConsumerPolicy // What to do when a client subscription buffer is full, checked with Validate at startup. Default: SlowConsumerDropNewest
+
+	// Limits on the logs notified to a logs subscription. The logs beyond them are dropped, and the subscriber is
+	// sent a LogsTruncated notice instead, once per block.
+	// They don't apply to polling filters, whose logs are limited by RpcSubscriptionFiltersMaxLogs.
+	RpcSubscriptionLogsRateLimit       int // Maximum number of logs notified per second per subscription. Default: 0 (no limit)
+	RpcSubscriptionLogsMaxPerBlock     int // Maximum number of logs of a block notified per subscription. Default: 0 (no limit)
+	RpcSubscriptionLogsGlobalRateLimit int // Maximum number of logs notified per second across all subscriptions. Default: 0 (no limit)
+
+	// Limits on the polling filters installed by eth_newFilter, eth_newBlockFilter and eth_newPendingTransactionFilter.
+	RpcFiltersTTL          time.Duration // How long a filter lives without being polled or kept alive. Default: 5 minutes, 0 means forever
+	RpcFiltersMaxPerClient int           // Maximum number of filters installed per client IP address. Default: 0 (no limit)
+}
+
+// SlowConsumerPolicy decides what happens to a notification for a subscriber whose buffer is full,
+// i.e. which doesn't read notifications as fast as they are produced.
+type SlowConsumerPolicy string
+
+const (
+	SlowConsumerDropNewest SlowConsumerPolicy = "drop-newest" // discard the new notification
+	SlowConsumerDropOldest SlowConsumerPolicy = "drop-oldest" // discard the oldest buffered notification to make room for the new one
+	SlowConsumerDisconnect SlowConsumerPolicy = "disconnect"  // close the subscription, the client is told why with ErrSlowConsumer
+)
+
+// Validate returns an error if p is not one of the known policies. The empty policy means SlowConsumerDropNewest.
+func (p SlowConsumerPolicy) Validate() error {
+	switch p {
+	case "", SlowConsumerDropNewest, SlowConsumerDropOldest, SlowConsumerDisconnect:
+		return nil
+	}
+	return fmt.Errorf("unknown slow consumer policy %q, expected one of %s, %s, %s", p, SlowConsumerDropNewest, SlowConsumerDropOldest, SlowConsumerDisconnect)
 }
 
 // DefaultFiltersConfig defines the default settings for filter configurations.
@@ -35,4 +75,6 @@ var DefaultFiltersConfig = FiltersConfig{
 	RpcSubscriptionFiltersMaxTxs:       0, // No limit on the number of transactions per subscription
 	RpcSubscriptionFiltersMaxAddresses: 0, // No limit on the number of addresses per subscription to filter logs by
 	RpcSubscriptionFiltersMaxTopics:    0, // No limit on the number of topics per subscription to filter logs by
+	RpcSubscriptionSlowConsumer:        SlowConsumerDropNewest,
+	RpcFiltersTTL:                      5 * time.Minute, // Filters not polled for 5 minutes are uninstalled
 }
@@ -27,6 +27,8 @@ import (
 type (
 	SubscriptionID    string
 	HeadsSubID        SubscriptionID
+	FullBlocksSubID   SubscriptionID
+	PeerEventsSubID   SubscriptionID
 	PendingLogsSubID  SubscriptionID
 	PendingBlockSubID SubscriptionID
 	PendingTxsSubID   SubscriptionID
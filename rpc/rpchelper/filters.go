@@ -23,11 +23,13 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
 
 	"google.golang.org/grpc"
 
@@ -36,11 +38,13 @@ import (
 	"github.com/erigontech/erigon-lib/gointerfaces"
 	"github.com/erigontech/erigon-lib/gointerfaces/grpcutil"
 	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
+	"github.com/erigontech/erigon-lib/gointerfaces/sentryproto"
 	txpool "github.com/erigontech/erigon-lib/gointerfaces/txpoolproto"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/eth/filters"
+	"github.com/erigontech/erigon/turbo/shards"
 	txpool2 "github.com/erigontech/erigon/txnprovider/txpool"
 )
 
@@ -53,19 +57,42 @@ type Filters struct {
 	pendingBlock *types.Block
 
 	headsSubs        *concurrent.SyncMap[HeadsSubID, Sub[*types.Header]]
+	fullBlocksSubs   *concurrent.SyncMap[FullBlocksSubID, Sub[*FullBlock]]
+	peerEventsSubs   *concurrent.SyncMap[PeerEventsSubID, Sub[*PeerEvent]]
 	pendingLogsSubs  *concurrent.SyncMap[PendingLogsSubID, Sub[types.Logs]]
 	pendingBlockSubs *concurrent.SyncMap[PendingBlockSubID, Sub[*types.Block]]
 	pendingTxsSubs   *concurrent.SyncMap[PendingTxsSubID, Sub[[]types.Transaction]]
 	logsSubs         *LogsFilterAggregator
 	logsRequestor    atomic.Value
+	logsRequestMu    sync.Mutex // serializes the updates of the logs filter of the backend
 	onNewSnapshot    func()
 
+	// the subscriptions catch up with the blocks notified while the streams from the backend were closed, once
+	// they are reopened, see resyncHeads and catchUpLogs
+	chainReader        ChainReader
+	eventsResubscribed atomic.Bool // the events stream was reopened, until it sends its first NEW_SNAPSHOT event
+	logsResubscribed   atomic.Bool // the logs stream was reopened
+	headsMu            sync.Mutex
+	lastHead           *types.Header          // last header sent to the heads subscriptions, nil if none
+	replayedHeads      map[uint64]common.Hash // headers sent by the last resync, which the events stream may send again
+	logsHead           atomic.Uint64          // highest block of the logs sent by the logs stream
+
+	// the streams of full blocks and peer events are only opened by the first subscription to them
+	ctx                 context.Context
+	cancel              context.CancelFunc // stops the goroutines serving the streams, see Close
+	wg                  sync.WaitGroup     // goroutines serving the streams
+	ethBackend          ApiBackend
+	subscribeFullBlocks sync.Once
+	subscribePeerEvents sync.Once
+
 	logsStores         *concurrent.SyncMap[LogsSubID, []*types.Log]
 	pendingHeadsStores *concurrent.SyncMap[HeadsSubID, []*types.Header]
 	pendingTxsStores   *concurrent.SyncMap[PendingTxsSubID, [][]types.Transaction]
+	pollingFilters     *pollingFilters
 	logger             log.Logger
 
-	config FiltersConfig
+	config   FiltersConfig
+	logsRate *rate.Limiter // RpcSubscriptionLogsGlobalRateLimit of config, shared by the logs subscriptions
 }
 
 // New creates a new Filters instance, initializes it, and starts subscription goroutines for Ethereum events.
@@ -73,26 +100,45 @@ type Filters struct {
 // and a logger for logging events.
 func New(ctx context.Context, config FiltersConfig, ethBackend ApiBackend, txPool txpool.TxpoolClient, mining txpool.MiningClient, onNewSnapshot func(), logger log.Logger) *Filters {
 	logger.Info("rpc filters: subscribing to Erigon events")
+	ctx, cancel := context.WithCancel(ctx)
 
 	ff := &Filters{
 		headsSubs:          concurrent.NewSyncMap[HeadsSubID, Sub[*types.Header]](),
+		fullBlocksSubs:     concurrent.NewSyncMap[FullBlocksSubID, Sub[*FullBlock]](),
+		peerEventsSubs:     concurrent.NewSyncMap[PeerEventsSubID, Sub[*PeerEvent]](),
 		pendingTxsSubs:     concurrent.NewSyncMap[PendingTxsSubID, Sub[[]types.Transaction]](),
 		pendingLogsSubs:    concurrent.NewSyncMap[PendingLogsSubID, Sub[types.Logs]](),
 		pendingBlockSubs:   concurrent.NewSyncMap[PendingBlockSubID, Sub[*types.Block]](),
 		logsSubs:           NewLogsFilterAggregator(),
 		onNewSnapshot:      onNewSnapshot,
+		ctx:                ctx,
+		cancel:             cancel,
+		ethBackend:         ethBackend,
 		logsStores:         concurrent.NewSyncMap[LogsSubID, []*types.Log](),
 		pendingHeadsStores: concurrent.NewSyncMap[HeadsSubID, []*types.Header](),
 		pendingTxsStores:   concurrent.NewSyncMap[PendingTxsSubID, [][]types.Transaction](),
+		pollingFilters:     newPollingFilters(),
 		logger:             logger,
 		config:             config,
+		logsRate:           newLogsRateLimiter(config.RpcSubscriptionLogsGlobalRateLimit),
 	}
 
-	go func() {
+	if config.RpcFiltersTTL > 0 {
+		ff.spawn(func() { ff.expireFiltersLoop(ctx) })
+	}
+
+	// the subscriptions are closed once the streams are stopped, their readers get the notifications still buffered
+	ff.spawn(func() {
+		<-ctx.Done()
+		ff.closeSubscriptions(ErrFiltersClosed)
+	})
+
+	ff.spawn(func() {
 		if ethBackend == nil {
 			return
 		}
 		activeSubscriptionsLogsClientGauge.With(prometheus.Labels{clientLabelName: "ethBackend_Events"}).Inc()
+		var subscribed bool
 		for {
 			select {
 			case <-ctx.Done():
@@ -100,6 +146,10 @@ func New(ctx context.Context, config FiltersConfig, ethBackend ApiBackend, txPoo
 				return
 			default:
 			}
+			if subscribed {
+				ff.eventsResubscribed.Store(true)
+			}
+			subscribed = true
 
 			if err := ethBackend.Subscribe(ctx, ff.OnNewEvent); err != nil {
 				select {
@@ -109,19 +159,20 @@ func New(ctx context.Context, config FiltersConfig, ethBackend ApiBackend, txPoo
 				default:
 				}
 				if grpcutil.IsEndOfStream(err) || grpcutil.IsRetryLater(err) {
-					time.Sleep(3 * time.Second)
+					_ = common.Sleep(ctx, 3*time.Second)
 					continue
 				}
 				logger.Warn("rpc filters: error subscribing to events", "err", err)
 			}
 		}
-	}()
+	})
 
-	go func() {
+	ff.spawn(func() {
 		if ethBackend == nil {
 			return
 		}
 		activeSubscriptionsLogsClientGauge.With(prometheus.Labels{clientLabelName: "ethBackend_Logs"}).Inc()
+		var subscribed bool
 		for {
 			select {
 			case <-ctx.Done():
@@ -129,7 +180,11 @@ func New(ctx context.Context, config FiltersConfig, ethBackend ApiBackend, txPoo
 				return
 			default:
 			}
-			if err := ethBackend.SubscribeLogs(ctx, ff.OnNewLogs, &ff.logsRequestor); err != nil {
+			if subscribed {
+				ff.logsResubscribed.Store(true)
+			}
+			subscribed = true
+			if err := ethBackend.SubscribeLogs(ctx, ff.OnNewLogs, ff.setLogsRequester); err != nil {
 				select {
 				case <-ctx.Done():
 					activeSubscriptionsLogsClientGauge.With(prometheus.Labels{clientLabelName: "ethBackend_Logs"}).Dec()
@@ -137,16 +192,16 @@ func New(ctx context.Context, config FiltersConfig, ethBackend ApiBackend, txPoo
 				default:
 				}
 				if grpcutil.IsEndOfStream(err) || grpcutil.IsRetryLater(err) {
-					time.Sleep(3 * time.Second)
+					_ = common.Sleep(ctx, 3*time.Second)
 					continue
 				}
 				logger.Warn("rpc filters: error subscribing to logs", "err", err)
 			}
 		}
-	}()
+	})
 
 	if txPool != nil {
-		go func() {
+		ff.spawn(func() {
 			activeSubscriptionsLogsClientGauge.With(prometheus.Labels{clientLabelName: "txPool_PendingTxs"}).Inc()
 			for {
 				select {
@@ -163,16 +218,16 @@ func New(ctx context.Context, config FiltersConfig, ethBackend ApiBackend, txPoo
 					default:
 					}
 					if grpcutil.IsEndOfStream(err) || grpcutil.IsRetryLater(err) || grpcutil.ErrIs(err, txpool2.ErrPoolDisabled) {
-						time.Sleep(3 * time.Second)
+						_ = common.Sleep(ctx, 3*time.Second)
 						continue
 					}
 					logger.Warn("rpc filters: error subscribing to pending transactions", "err", err)
 				}
 			}
-		}()
+		})
 
 		if !reflect.ValueOf(mining).IsNil() { //https://groups.google.com/g/golang-nuts/c/wnH302gBa4I
-			go func() {
+			ff.spawn(func() {
 				activeSubscriptionsLogsClientGauge.With(prometheus.Labels{clientLabelName: "txPool_PendingBlock"}).Inc()
 				for {
 					select {
@@ -189,14 +244,14 @@ func New(ctx context.Context, config FiltersConfig, ethBackend ApiBackend, txPoo
 						default:
 						}
 						if grpcutil.IsEndOfStream(err) || grpcutil.IsRetryLater(err) {
-							time.Sleep(3 * time.Second)
+							_ = common.Sleep(ctx, 3*time.Second)
 							continue
 						}
 						logger.Warn("rpc filters: error subscribing to pending blocks", "err", err)
 					}
 				}
-			}()
-			go func() {
+			})
+			ff.spawn(func() {
 				activeSubscriptionsLogsClientGauge.With(prometheus.Labels{clientLabelName: "txPool_PendingLogs"}).Inc()
 				for {
 					select {
@@ -213,19 +268,76 @@ func New(ctx context.Context, config FiltersConfig, ethBackend ApiBackend, txPoo
 						default:
 						}
 						if grpcutil.IsEndOfStream(err) || grpcutil.IsRetryLater(err) {
-							time.Sleep(3 * time.Second)
+							_ = common.Sleep(ctx, 3*time.Second)
 							continue
 						}
 						logger.Warn("rpc filters: error subscribing to pending logs", "err", err)
 					}
 				}
-			}()
+			})
 		}
 	}
 
 	return ff
 }
 
+// spawn runs fn in a goroutine waited for by Close.
+func (ff *Filters) spawn(fn func()) {
+	ff.wg.Add(1)
+	go func() {
+		defer ff.wg.Done()
+		fn()
+	}()
+}
+
+// Close stops the streams from the backend and closes the subscriptions with ErrFiltersClosed, then waits for the
+// goroutines started by the Filters to return. The readers of the subscriptions get the notifications still buffered
+// before their channel is closed. The Filters are also closed once the context given to New is done.
+func (ff *Filters) Close() {
+	ff.cancel()
+	ff.wg.Wait()
+}
+
+// closeSubscriptions closes all the subscriptions with err.
+func (ff *Filters) closeSubscriptions(err error) {
+	closeSubs(ff.headsSubs, err)
+	closeSubs(ff.fullBlocksSubs, err)
+	closeSubs(ff.peerEventsSubs, err)
+	closeSubs(ff.pendingLogsSubs, err)
+	closeSubs(ff.pendingBlockSubs, err)
+	closeSubs(ff.pendingTxsSubs, err)
+	ff.closeLogsSubs(err)
+}
+
+func closeSubs[K comparable, T any](subs *concurrent.SyncMap[K, Sub[T]], err error) {
+	subs.Range(func(_ K, sub Sub[T]) error { //nolint:errcheck
+		sub.CloseWithError(err)
+		return nil
+	})
+}
+
+// FullBlock is a new canonical block sent to the newFullBlocks subscriptions. The first block of a new branch
+// also carries the reorg it completes: OldHead is the head of the old branch, CommonAncestor the last block common
+// to both branches. Both are zero otherwise.
+type FullBlock struct {
+	Block          *types.Block
+	NewHead        common.Hash // head of the chain once all the blocks of the update are applied
+	OldHead        common.Hash
+	CommonAncestor common.Hash
+}
+
+func (b *FullBlock) IsReorg() bool { return b.CommonAncestor != (common.Hash{}) }
+
+// PeerEvent is a peer connecting to or disconnecting from one of the sentries of the node, as sent to the
+// peerEvents subscriptions. Enode, Name and Caps are only set for connections, when the sentry knows them.
+type PeerEvent struct {
+	Type  string   `json:"type"` // connect or disconnect
+	ID    string   `json:"id"`
+	Enode string   `json:"enode,omitempty"`
+	Name  string   `json:"name,omitempty"`
+	Caps  []string `json:"caps,omitempty"`
+}
+
 // LastPendingBlock returns the last pending block that was received.
 func (ff *Filters) LastPendingBlock() *types.Block {
 	ff.mu.RLock()
@@ -350,12 +462,174 @@ func (ff *Filters) HandlePendingLogs(reply *txpool.OnPendingLogsReply) {
 // SubscribeNewHeads subscribes to new block headers and returns a channel to receive the headers
 // and a subscription ID to manage the subscription.
 func (ff *Filters) SubscribeNewHeads(size int) (<-chan *types.Header, HeadsSubID) {
+	return ff.subscribeNewHeads(subscriptionBufferSize(ff.config.RpcSubscriptionHeadsBufferSize, size), ff.config.RpcSubscriptionSlowConsumer)
+}
+
+// SubscribeNewHeadsInternal is SubscribeNewHeads for consumers within the node, e.g. a filter of eth_newBlockFilter.
+// The configured buffer size and slow consumer policy are for client subscriptions, an internal subscription has
+// the requested size and drops the newest header when it is full, so that it is never closed under the consumer.
+func (ff *Filters) SubscribeNewHeadsInternal(size int) (<-chan *types.Header, HeadsSubID) {
+	return ff.subscribeNewHeads(size, SlowConsumerDropNewest)
+}
+
+func (ff *Filters) subscribeNewHeads(size int, policy SlowConsumerPolicy) (<-chan *types.Header, HeadsSubID) {
 	id := HeadsSubID(generateSubscriptionID())
-	sub := newChanSub[*types.Header](size)
+	sub := newChanSubWithPolicy[*types.Header](size, policy, droppedHeadsNotificationsCounter)
 	ff.headsSubs.Put(id, sub)
 	return sub.ch, id
 }
 
+// SubscribeNewFullBlocks subscribes to the new canonical blocks with their bodies and returns a channel to receive
+// the blocks and a subscription ID to manage the subscription. The first subscription opens the stream of blocks
+// from the backend, which only reads the bodies while someone is subscribed.
+func (ff *Filters) SubscribeNewFullBlocks(size int) (<-chan *FullBlock, FullBlocksSubID) {
+	id := FullBlocksSubID(generateSubscriptionID())
+	sub := newChanSubWithPolicy[*FullBlock](subscriptionBufferSize(ff.config.RpcSubscriptionHeadsBufferSize, size), ff.config.RpcSubscriptionSlowConsumer, droppedFullBlocksNotificationsCounter)
+	ff.fullBlocksSubs.Put(id, sub)
+	ff.subscribeFullBlocks.Do(func() {
+		if ff.ethBackend != nil {
+			ff.spawn(func() { ff.subscribeToFullBlocks(ff.ctx, ff.ethBackend) })
+		}
+	})
+	return sub.ch, id
+}
+
+// FullBlocksSubErr returns ErrSlowConsumer or ErrFiltersClosed if the channel of the subscription was closed by the slow
+// consumer policy or by Close.
+func (ff *Filters) FullBlocksSubErr(id FullBlocksSubID) error {
+	if sub, ok := ff.fullBlocksSubs.Get(id); ok {
+		return sub.Err()
+	}
+	return nil
+}
+
+// UnsubscribeFullBlocks unsubscribes from new full blocks using the given subscription ID.
+// It returns true if the unsubscription was successful, otherwise false.
+func (ff *Filters) UnsubscribeFullBlocks(id FullBlocksSubID) bool {
+	ch, ok := ff.fullBlocksSubs.Get(id)
+	if !ok {
+		return false
+	}
+	ch.Close()
+	_, ok = ff.fullBlocksSubs.Delete(id)
+	return ok
+}
+
+// subscribeToFullBlocks keeps the stream of full blocks from the backend open until ctx is done.
+// It gives up if the backend doesn't support it.
+func (ff *Filters) subscribeToFullBlocks(ctx context.Context, ethBackend ApiBackend) {
+	activeSubscriptionsLogsClientGauge.With(prometheus.Labels{clientLabelName: "ethBackend_Blocks"}).Inc()
+	defer activeSubscriptionsLogsClientGauge.With(prometheus.Labels{clientLabelName: "ethBackend_Blocks"}).Dec()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if err := ethBackend.SubscribeBlocks(ctx, ff.OnNewBlock); err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+			if grpcutil.IsUnimplemented(err) {
+				ff.logger.Warn("rpc filters: the node doesn't support streaming full blocks, newFullBlocks subscriptions won't receive any", "err", err)
+				return
+			}
+			if grpcutil.IsEndOfStream(err) || grpcutil.IsRetryLater(err) {
+				_ = common.Sleep(ctx, 3*time.Second)
+				continue
+			}
+			ff.logger.Warn("rpc filters: error subscribing to full blocks", "err", err)
+		}
+	}
+}
+
+// SubscribePeerEvents subscribes to the peers connecting to and disconnecting from the sentries and returns a channel
+// to receive the events and a subscription ID to manage the subscription. The first subscription opens the stream of
+// peer events from the backend.
+func (ff *Filters) SubscribePeerEvents(size int) (<-chan *PeerEvent, PeerEventsSubID) {
+	id := PeerEventsSubID(generateSubscriptionID())
+	sub := newChanSubWithPolicy[*PeerEvent](size, ff.config.RpcSubscriptionSlowConsumer, droppedPeerEventsNotificationsCounter)
+	ff.peerEventsSubs.Put(id, sub)
+	ff.subscribePeerEvents.Do(func() {
+		if ff.ethBackend != nil {
+			ff.spawn(func() { ff.subscribeToPeerEvents(ff.ctx, ff.ethBackend) })
+		}
+	})
+	return sub.ch, id
+}
+
+// PeerEventsSubErr returns ErrSlowConsumer or ErrFiltersClosed if the channel of the subscription was closed by the slow
+// consumer policy or by Close.
+func (ff *Filters) PeerEventsSubErr(id PeerEventsSubID) error {
+	if sub, ok := ff.peerEventsSubs.Get(id); ok {
+		return sub.Err()
+	}
+	return nil
+}
+
+// UnsubscribePeerEvents unsubscribes from peer events using the given subscription ID.
+// It returns true if the unsubscription was successful, otherwise false.
+func (ff *Filters) UnsubscribePeerEvents(id PeerEventsSubID) bool {
+	ch, ok := ff.peerEventsSubs.Get(id)
+	if !ok {
+		return false
+	}
+	ch.Close()
+	_, ok = ff.peerEventsSubs.Delete(id)
+	return ok
+}
+
+// subscribeToPeerEvents keeps the stream of peer events from the backend open until ctx is done.
+// It gives up if the backend doesn't support it.
+func (ff *Filters) subscribeToPeerEvents(ctx context.Context, ethBackend ApiBackend) {
+	activeSubscriptionsLogsClientGauge.With(prometheus.Labels{clientLabelName: "ethBackend_PeerEvents"}).Inc()
+	defer activeSubscriptionsLogsClientGauge.With(prometheus.Labels{clientLabelName: "ethBackend_PeerEvents"}).Dec()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if err := ethBackend.SubscribePeerEvents(ctx, ff.OnPeerEvent); err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+			if grpcutil.IsUnimplemented(err) {
+				ff.logger.Warn("rpc filters: the node doesn't support streaming peer events, peerEvents subscriptions won't receive any", "err", err)
+				return
+			}
+			if grpcutil.IsEndOfStream(err) || grpcutil.IsRetryLater(err) {
+				_ = common.Sleep(ctx, 3*time.Second)
+				continue
+			}
+			ff.logger.Warn("rpc filters: error subscribing to peer events", "err", err)
+		}
+	}
+}
+
+// HeadsSubErr returns ErrSlowConsumer or ErrFiltersClosed if the channel of the subscription was closed by the slow
+// consumer policy or by Close.
+func (ff *Filters) HeadsSubErr(id HeadsSubID) error {
+	if sub, ok := ff.headsSubs.Get(id); ok {
+		return sub.Err()
+	}
+	return nil
+}
+
+// subscriptionBufferSize returns the configured buffer size of a subscription type, or the requested one if none is configured.
+func subscriptionBufferSize(configured, requested int) int {
+	if configured > 0 {
+		return configured
+	}
+	return requested
+}
+
 // UnsubscribeHeads unsubscribes from new block headers using the given subscription ID.
 // It returns true if the unsubscription was successful, otherwise false.
 func (ff *Filters) UnsubscribeHeads(id HeadsSubID) bool {
@@ -412,12 +686,39 @@ func (ff *Filters) UnsubscribePendingBlock(id PendingBlockSubID) {
 // SubscribePendingTxs subscribes to pending transactions and returns a channel to receive the transactions
 // and a subscription ID to manage the subscription.
 func (ff *Filters) SubscribePendingTxs(size int) (<-chan []types.Transaction, PendingTxsSubID) {
+	return ff.subscribePendingTxs(subscriptionBufferSize(ff.config.RpcSubscriptionTxsBufferSize, size), ff.config.RpcSubscriptionSlowConsumer)
+}
+
+// SubscribePendingTxsInternal is SubscribePendingTxs for consumers within the node, like SubscribeNewHeadsInternal.
+func (ff *Filters) SubscribePendingTxsInternal(size int) (<-chan []types.Transaction, PendingTxsSubID) {
+	return ff.subscribePendingTxs(size, SlowConsumerDropNewest)
+}
+
+func (ff *Filters) subscribePendingTxs(size int, policy SlowConsumerPolicy) (<-chan []types.Transaction, PendingTxsSubID) {
 	id := PendingTxsSubID(generateSubscriptionID())
-	sub := newChanSub[[]types.Transaction](size)
+	sub := newChanSubWithPolicy[[]types.Transaction](size, policy, droppedPendingTxsNotificationsCounter)
 	ff.pendingTxsSubs.Put(id, sub)
 	return sub.ch, id
 }
 
+// PendingTxsSubErr returns ErrSlowConsumer or ErrFiltersClosed if the channel of the subscription was closed by the slow
+// consumer policy or by Close.
+func (ff *Filters) PendingTxsSubErr(id PendingTxsSubID) error {
+	if sub, ok := ff.pendingTxsSubs.Get(id); ok {
+		return sub.Err()
+	}
+	return nil
+}
+
+// LimitPendingTxs keeps the newest RpcSubscriptionFiltersMaxTxs transactions of txs, the per-subscription limit
+// on buffered transactions. If no limit is configured txs is returned unchanged.
+func (ff *Filters) LimitPendingTxs(txs []types.Transaction) []types.Transaction {
+	if maxTxs := ff.config.RpcSubscriptionFiltersMaxTxs; maxTxs > 0 && len(txs) > maxTxs {
+		return txs[len(txs)-maxTxs:]
+	}
+	return txs
+}
+
 // UnsubscribePendingTxs unsubscribes from pending transactions using the given subscription ID.
 // It returns true if the unsubscription was successful, otherwise false.
 func (ff *Filters) UnsubscribePendingTxs(id PendingTxsSubID) bool {
@@ -436,8 +737,30 @@ func (ff *Filters) UnsubscribePendingTxs(id PendingTxsSubID) bool {
 // SubscribeLogs subscribes to logs using the specified filter criteria and returns a channel to receive the logs
 // and a subscription ID to manage the subscription.
 func (ff *Filters) SubscribeLogs(size int, criteria filters.FilterCriteria) (<-chan *types.Log, LogsSubID) {
-	sub := newChanSub[*types.Log](size)
-	id, f := ff.logsSubs.insertLogsFilter(sub)
+	return ff.subscribeLogs(subscriptionBufferSize(ff.config.RpcSubscriptionLogsBufferSize, size), ff.config.RpcSubscriptionSlowConsumer, criteria, nil)
+}
+
+// SubscribeLogsInternal is SubscribeLogs for consumers within the node, e.g. a filter of eth_newFilter,
+// like SubscribeNewHeadsInternal.
+func (ff *Filters) SubscribeLogsInternal(size int, criteria filters.FilterCriteria) (<-chan *types.Log, LogsSubID) {
+	return ff.subscribeLogs(size, SlowConsumerDropNewest, criteria, nil)
+}
+
+// SubscribeLogsNotifications is SubscribeLogs for a subscription notifying the logs to a client, to which
+// the logs notification limits of the config apply: the logs exceeding them are dropped, and the second
+// channel receives a LogsTruncated notice for each block whose logs were. It is nil if there are no limits.
+func (ff *Filters) SubscribeLogsNotifications(size int, criteria filters.FilterCriteria) (<-chan *types.Log, <-chan *LogsTruncated, LogsSubID) {
+	limiter := newLogsLimiter(ff.config, ff.logsRate)
+	logs, id := ff.subscribeLogs(subscriptionBufferSize(ff.config.RpcSubscriptionLogsBufferSize, size), ff.config.RpcSubscriptionSlowConsumer, criteria, limiter)
+	if limiter == nil {
+		return logs, nil, id
+	}
+	return logs, limiter.notices, id
+}
+
+func (ff *Filters) subscribeLogs(size int, policy SlowConsumerPolicy, criteria filters.FilterCriteria, limiter *logsLimiter) (<-chan *types.Log, LogsSubID) {
+	sub := newChanSubWithPolicy[*types.Log](size, policy, droppedLogsNotificationsCounter)
+	id, f := ff.logsSubs.insertLogsFilter(sub, limiter)
 
 	// Initialize address and topic maps
 	f.addrs = concurrent.NewSyncMap[common.Address, int]()
@@ -489,7 +812,17 @@ func (ff *Filters) SubscribeLogs(size int, criteria filters.FilterCriteria) (<-c
 	// Add the filter to the list of log filters
 	ff.logsSubs.addLogsFilters(f)
 
-	// Create a filter request based on the aggregated filters
+	if err := ff.updateRemoteLogsFilter(); err != nil {
+		ff.logger.Warn("Could not update remote logs filter", "err", err)
+		ff.logsSubs.removeLogsFilter(id)
+	}
+
+	return sub.ch, id
+}
+
+// logsFilterRequest returns the union of the filters of the logs subscriptions, which the backend filters the logs
+// it sends with.
+func (ff *Filters) logsFilterRequest() *remote.LogsFilterRequest {
 	lfr := ff.logsSubs.createFilterRequest()
 	addresses, topics := ff.logsSubs.getAggMaps()
 	for addr := range addresses {
@@ -498,16 +831,48 @@ func (ff *Filters) SubscribeLogs(size int, criteria filters.FilterCriteria) (<-c
 	for topic := range topics {
 		lfr.Topics = append(lfr.Topics, gointerfaces.ConvertHashToH256(topic))
 	}
+	return lfr
+}
 
+// updateRemoteLogsFilter sends the union of the filters of the logs subscriptions to the logs stream of the backend,
+// if one is open. The updates are serialized, so that the last one sent is computed from the latest subscriptions
+// even when subscriptions change concurrently. The logs in flight when the filter changes may not match it, they
+// are filtered again by the subscriptions.
+func (ff *Filters) updateRemoteLogsFilter() error {
+	ff.logsRequestMu.Lock()
+	defer ff.logsRequestMu.Unlock()
 	loaded := ff.loadLogsRequester()
-	if loaded != nil {
-		if err := loaded.(func(*remote.LogsFilterRequest) error)(lfr); err != nil {
-			ff.logger.Warn("Could not update remote logs filter", "err", err)
-			ff.logsSubs.removeLogsFilter(id)
-		}
+	if loaded == nil {
+		return nil
 	}
+	return loaded.(func(*remote.LogsFilterRequest) error)(ff.logsFilterRequest())
+}
 
-	return sub.ch, id
+// setLogsRequester is called when a logs stream of the backend is opened, with the function sending the filter
+// requests of the stream. The stream is sent the current filters right away: the backend only sends the logs matching
+// them, and knows nothing of the filters of a previous stream. If the stream is reopened, the subscriptions catch up
+// with the logs they missed before it sends any.
+func (ff *Filters) setLogsRequester(send func(*remote.LogsFilterRequest) error) {
+	ff.logsRequestMu.Lock()
+	defer ff.logsRequestMu.Unlock()
+	ff.mu.Lock()
+	ff.logsRequestor.Store(send)
+	ff.mu.Unlock()
+	if err := send(ff.logsFilterRequest()); err != nil {
+		ff.logger.Warn("Could not update remote logs filter", "err", err)
+	}
+	if ff.logsResubscribed.CompareAndSwap(true, false) {
+		ff.catchUpLogs(ff.ctx)
+	}
+}
+
+// LogsSubErr returns ErrSlowConsumer or ErrFiltersClosed if the channel of the subscription was closed by the slow
+// consumer policy or by Close.
+func (ff *Filters) LogsSubErr(id LogsSubID) error {
+	if f, ok := ff.logsSubs.logsFilters.Get(id); ok && f.sender != nil {
+		return f.sender.Err()
+	}
+	return nil
 }
 
 // loadLogsRequester loads the current logs requester and returns it.
@@ -523,22 +888,9 @@ func (ff *Filters) UnsubscribeLogs(id LogsSubID) bool {
 	isDeleted := ff.logsSubs.removeLogsFilter(id)
 	// if any filters in the aggregate need all addresses or all topics then the request to the central
 	// log subscription needs to honour this
-	lfr := ff.logsSubs.createFilterRequest()
-
-	addresses, topics := ff.logsSubs.getAggMaps()
-
-	for addr := range addresses {
-		lfr.Addresses = append(lfr.Addresses, gointerfaces.ConvertAddressToH160(addr))
-	}
-	for topic := range topics {
-		lfr.Topics = append(lfr.Topics, gointerfaces.ConvertHashToH256(topic))
-	}
-	loaded := ff.loadLogsRequester()
-	if loaded != nil {
-		if err := loaded.(func(*remote.LogsFilterRequest) error)(lfr); err != nil {
-			ff.logger.Warn("Could not update remote logs filter", "err", err)
-			return isDeleted || ff.logsSubs.removeLogsFilter(id)
-		}
+	if err := ff.updateRemoteLogsFilter(); err != nil {
+		ff.logger.Warn("Could not update remote logs filter", "err", err)
+		return isDeleted || ff.logsSubs.removeLogsFilter(id)
 	}
 
 	ff.deleteLogStore(id)
@@ -565,6 +917,10 @@ func (ff *Filters) onNewEvent(event *remote.SubscribeReply) error {
 	case remote.Event_HEADER:
 		return ff.onNewHeader(event)
 	case remote.Event_NEW_SNAPSHOT:
+		// the first event of a stream
+		if ff.eventsResubscribed.CompareAndSwap(true, false) {
+			ff.resyncHeads(ff.ctx)
+		}
 		ff.onNewSnapshot()
 		return nil
 	case remote.Event_PENDING_LOGS:
@@ -621,8 +977,66 @@ func (ff *Filters) onNewHeader(event *remote.SubscribeReply) error {
 	if err != nil {
 		return fmt.Errorf("unprocessable payload: %w", err)
 	}
-	return ff.headsSubs.Range(func(k HeadsSubID, v Sub[*types.Header]) error {
-		v.Send(&header)
+	ff.headsMu.Lock()
+	defer ff.headsMu.Unlock()
+	if ff.replayedHeads != nil {
+		if hash, ok := ff.replayedHeads[header.Number.Uint64()]; ok && hash == header.Hash() {
+			return nil
+		}
+		ff.replayedHeads = nil
+	}
+	ff.sendHeader(&header)
+	return nil
+}
+
+// sendHeader sends a header to the heads subscriptions, the caller holds ff.headsMu.
+func (ff *Filters) sendHeader(header *types.Header) {
+	ff.lastHead = header
+	ff.headsSubs.Range(func(k HeadsSubID, v Sub[*types.Header]) error { //nolint:errcheck
+		v.Send(header)
+		return nil
+	})
+}
+
+// OnNewBlock handles a new canonical block from the remote and sends it to the newFullBlocks subscriptions.
+func (ff *Filters) OnNewBlock(newBlock *shards.NewBlock) {
+	if err := ff.onNewBlock(newBlock); err != nil {
+		ff.logger.Warn("OnNewBlock Filters", "err", err)
+	}
+}
+
+func (ff *Filters) onNewBlock(newBlock *shards.NewBlock) error {
+	var header types.Header
+	if err := rlp.DecodeBytes(newBlock.HeaderRlp, &header); err != nil {
+		return fmt.Errorf("unprocessable header: %w", err)
+	}
+	var body types.Body
+	if err := rlp.DecodeBytes(newBlock.BodyRlp, &body); err != nil {
+		return fmt.Errorf("unprocessable body: %w", err)
+	}
+	block := &FullBlock{
+		Block:          types.NewBlockFromStorage(header.Hash(), &header, body.Transactions, body.Uncles, body.Withdrawals),
+		NewHead:        newBlock.NewHead,
+		OldHead:        newBlock.OldHead,
+		CommonAncestor: newBlock.CommonAncestor,
+	}
+	return ff.fullBlocksSubs.Range(func(k FullBlocksSubID, v Sub[*FullBlock]) error {
+		v.Send(block)
+		return nil
+	})
+}
+
+// OnPeerEvent handles a peer event from the remote and sends it to the peerEvents subscriptions.
+func (ff *Filters) OnPeerEvent(event *shards.PeerEvent) {
+	peerEvent := &PeerEvent{
+		Type:  strings.ToLower(sentryproto.PeerEvent_PeerEventId(event.EventId).String()),
+		ID:    hex.EncodeToString(event.PeerId[:]),
+		Enode: event.Enode,
+		Name:  event.Name,
+		Caps:  event.Caps,
+	}
+	_ = ff.peerEventsSubs.Range(func(k PeerEventsSubID, v Sub[*PeerEvent]) error {
+		v.Send(peerEvent)
 		return nil
 	})
 }
@@ -650,6 +1064,9 @@ func (ff *Filters) OnNewTx(reply *txpool.OnAddReply) {
 
 // OnNewLogs handles a new log event from the remote and processes it.
 func (ff *Filters) OnNewLogs(reply *remote.SubscribeLogsReply) {
+	if !reply.Removed && reply.BlockNumber > ff.logsHead.Load() {
+		ff.logsHead.Store(reply.BlockNumber)
+	}
 	ff.logsSubs.distributeLog(reply)
 }
 
@@ -743,28 +1160,14 @@ func (ff *Filters) AddPendingTxs(id PendingTxsSubID, txs []types.Transaction) {
 		}
 
 		maxTxs := ff.config.RpcSubscriptionFiltersMaxTxs
-		// If adding the new transactions would exceed maxTxs, remove oldest transactions
+		// If adding the new transactions would exceed maxTxs, keep the newest maxTxs transactions in a single batch
 		if maxTxs > 0 && totalTxs+len(txs) > maxTxs {
-			// Flatten st to a single slice
-			flatSt := make([]types.Transaction, 0, totalTxs)
+			flatSt := make([]types.Transaction, 0, totalTxs+len(txs))
 			for _, txBatch := range st {
 				flatSt = append(flatSt, txBatch...)
 			}
-
-			// Calculate how many transactions need to be removed
-			excessTxs := len(flatSt) + len(txs) - maxTxs
-			if excessTxs > 0 {
-				if excessTxs >= len(flatSt) {
-					// If excessTxs is greater than or equal to the length of flatSt, remove all
-					flatSt = []types.Transaction{}
-				} else {
-					// Otherwise, remove the oldest transactions
-					flatSt = flatSt[excessTxs:]
-				}
-			}
-
-			// Convert flatSt back to [][]types.Transaction with a single batch
-			st = [][]types.Transaction{flatSt}
+			flatSt = append(flatSt, txs...)
+			return [][]types.Transaction{flatSt[len(flatSt)-maxTxs:]}
 		}
 
 		// Append the new transactions as a new batch
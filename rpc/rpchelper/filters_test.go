@@ -18,15 +18,20 @@ package rpchelper
 
 import (
 	"context"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/gointerfaces"
 	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
 	types2 "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/eth/filters"
 )
@@ -504,3 +509,421 @@ func TestFilters_AddPendingTxs(t *testing.T) {
 		})
 	}
 }
+
+func TestFilters_SlowConsumerPolicy(t *testing.T) {
+	t.Parallel()
+	sendHeaders := func(ff *Filters, from, to uint64) {
+		for n := from; n <= to; n++ {
+			data, err := rlp.EncodeToBytes(&types.Header{Number: new(big.Int).SetUint64(n), Difficulty: big.NewInt(1)})
+			require.NoError(t, err)
+			ff.OnNewEvent(&remote.SubscribeReply{Type: remote.Event_HEADER, Data: data})
+		}
+	}
+	readHeaders := func(ch <-chan *types.Header) (numbers []uint64, closed bool) {
+		for {
+			select {
+			case h, ok := <-ch:
+				if !ok {
+					return numbers, true
+				}
+				numbers = append(numbers, h.Number.Uint64())
+			default:
+				return numbers, false
+			}
+		}
+	}
+
+	tests := []struct {
+		policy     SlowConsumerPolicy
+		want       []uint64
+		wantClosed bool
+	}{
+		{SlowConsumerDropNewest, []uint64{1, 2, 3, 4, 5, 6, 7, 8}, false},
+		{SlowConsumerDropOldest, []uint64{5, 6, 7, 8, 9, 10, 11, 12}, false},
+		{SlowConsumerDisconnect, []uint64{1, 2, 3, 4, 5, 6, 7, 8}, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			config := DefaultFiltersConfig
+			config.RpcSubscriptionHeadsBufferSize = 8
+			config.RpcSubscriptionSlowConsumer = tt.policy
+			ff := New(context.TODO(), config, nil, nil, nil, func() {}, log.New())
+
+			// the configured buffer size overrides the one asked for
+			ch, id := ff.SubscribeNewHeads(1)
+			defer ff.UnsubscribeHeads(id)
+			dropped := droppedHeadsNotificationsCounter.GetValueUint64()
+			sendHeaders(ff, 1, 12)
+
+			numbers, closed := readHeaders(ch)
+			require.Equal(t, tt.want, numbers)
+			require.Equal(t, tt.wantClosed, closed)
+			if tt.wantClosed {
+				require.ErrorIs(t, ff.HeadsSubErr(id), ErrSlowConsumer)
+				// only the first overflowing header is counted, later ones find the subscription closed
+				require.GreaterOrEqual(t, droppedHeadsNotificationsCounter.GetValueUint64()-dropped, uint64(1))
+			} else {
+				require.NoError(t, ff.HeadsSubErr(id))
+				require.GreaterOrEqual(t, droppedHeadsNotificationsCounter.GetValueUint64()-dropped, uint64(4))
+			}
+		})
+	}
+
+	t.Run("internal", func(t *testing.T) {
+		config := DefaultFiltersConfig
+		config.RpcSubscriptionHeadsBufferSize = 16
+		config.RpcSubscriptionSlowConsumer = SlowConsumerDisconnect
+		ff := New(context.TODO(), config, nil, nil, nil, func() {}, log.New())
+
+		// an internal subscription keeps the requested buffer size and is never closed under its consumer
+		ch, id := ff.SubscribeNewHeadsInternal(8)
+		defer ff.UnsubscribeHeads(id)
+		sendHeaders(ff, 1, 12)
+
+		numbers, closed := readHeaders(ch)
+		require.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7, 8}, numbers)
+		require.False(t, closed)
+		require.NoError(t, ff.HeadsSubErr(id))
+	})
+
+	require.Error(t, SlowConsumerPolicy("block").Validate())
+}
+
+func TestFilters_LogsNotificationLimits(t *testing.T) {
+	t.Parallel()
+	sendLogs := func(ff *Filters, block common.Hash, n int) {
+		for i := 0; i < n; i++ {
+			lg := createLog()
+			lg.BlockHash = gointerfaces.ConvertHashToH256(block)
+			lg.LogIndex = uint64(i)
+			ff.OnNewLogs(lg)
+		}
+	}
+	readLogs := func(ch <-chan *types.Log) (n int, closed bool) {
+		for {
+			select {
+			case _, ok := <-ch:
+				if !ok {
+					return n, true
+				}
+				n++
+			default:
+				return n, false
+			}
+		}
+	}
+
+	readNotices := func(ch <-chan *LogsTruncated) (blocks []common.Hash) {
+		for {
+			select {
+			case notice := <-ch:
+				blocks = append(blocks, notice.BlockHash)
+			default:
+				return blocks
+			}
+		}
+	}
+
+	t.Run("MaxPerBlock", func(t *testing.T) {
+		config := DefaultFiltersConfig
+		config.RpcSubscriptionLogsMaxPerBlock = 5
+		ff := New(context.TODO(), config, nil, nil, nil, func() {}, log.New())
+		notified, truncated, id := ff.SubscribeLogsNotifications(256, filters.FilterCriteria{})
+		defer ff.UnsubscribeLogs(id)
+		polled, pollID := ff.SubscribeLogs(256, filters.FilterCriteria{})
+		defer ff.UnsubscribeLogs(pollID)
+		heads, headsID := ff.SubscribeNewHeads(256)
+		defer ff.UnsubscribeHeads(headsID)
+		exceeded := logsPerBlockLimitExceededCounter.GetValueUint64()
+
+		sendLogs(ff, common.Hash{1}, 5)
+		sendLogs(ff, common.Hash{2}, 5)
+		n, closed := readLogs(notified)
+		require.Equal(t, 10, n)
+		require.False(t, closed)
+		require.Empty(t, readNotices(truncated))
+
+		// the dropped logs of a block are coalesced into one notice, and the subscription goes on
+		sendLogs(ff, common.Hash{3}, 100)
+		sendLogs(ff, common.Hash{4}, 3)
+		n, closed = readLogs(notified)
+		require.Equal(t, 8, n)
+		require.False(t, closed)
+		require.NoError(t, ff.LogsSubErr(id))
+		require.Equal(t, []common.Hash{{3}}, readNotices(truncated))
+		require.Equal(t, uint64(1), logsPerBlockLimitExceededCounter.GetValueUint64()-exceeded)
+
+		// polling filters and heads subscriptions aren't limited
+		n, closed = readLogs(polled)
+		require.Equal(t, 113, n)
+		require.False(t, closed)
+		for i := uint64(1); i <= 100; i++ {
+			data, err := rlp.EncodeToBytes(&types.Header{Number: new(big.Int).SetUint64(i), Difficulty: big.NewInt(1)})
+			require.NoError(t, err)
+			ff.OnNewEvent(&remote.SubscribeReply{Type: remote.Event_HEADER, Data: data})
+		}
+		require.Len(t, heads, 100)
+	})
+
+	t.Run("RateLimit", func(t *testing.T) {
+		config := DefaultFiltersConfig
+		config.RpcSubscriptionLogsRateLimit = 20
+		ff := New(context.TODO(), config, nil, nil, nil, func() {}, log.New())
+		notified, truncated, id := ff.SubscribeLogsNotifications(256, filters.FilterCriteria{})
+		defer ff.UnsubscribeLogs(id)
+		exceeded := logsRateLimitExceededCounter.GetValueUint64()
+
+		for block := byte(1); block <= 10; block++ {
+			sendLogs(ff, common.Hash{block}, 3)
+		}
+		n, closed := readLogs(notified)
+		require.Equal(t, 20, n)
+		require.False(t, closed)
+		// the burst runs out in block 7
+		require.Equal(t, []common.Hash{{7}, {8}, {9}, {10}}, readNotices(truncated))
+		require.Equal(t, uint64(4), logsRateLimitExceededCounter.GetValueUint64()-exceeded)
+	})
+
+	t.Run("GlobalRateLimit", func(t *testing.T) {
+		config := DefaultFiltersConfig
+		config.RpcSubscriptionLogsGlobalRateLimit = 20
+		ff := New(context.TODO(), config, nil, nil, nil, func() {}, log.New())
+		notified1, truncated1, id1 := ff.SubscribeLogsNotifications(256, filters.FilterCriteria{})
+		defer ff.UnsubscribeLogs(id1)
+		notified2, truncated2, id2 := ff.SubscribeLogsNotifications(256, filters.FilterCriteria{})
+		defer ff.UnsubscribeLogs(id2)
+		exceeded := logsGlobalRateLimitExceededCounter.GetValueUint64()
+
+		for block := byte(1); block <= 10; block++ {
+			sendLogs(ff, common.Hash{block}, 3)
+		}
+		// each log is sent to both subscriptions, so the shared burst runs out in block 4
+		n1, _ := readLogs(notified1)
+		n2, _ := readLogs(notified2)
+		require.Equal(t, 10, n1)
+		require.Equal(t, 10, n2)
+		truncatedBlocks := []common.Hash{{4}, {5}, {6}, {7}, {8}, {9}, {10}}
+		require.Equal(t, truncatedBlocks, readNotices(truncated1))
+		require.Equal(t, truncatedBlocks, readNotices(truncated2))
+		require.Equal(t, uint64(14), logsGlobalRateLimitExceededCounter.GetValueUint64()-exceeded)
+	})
+
+	t.Run("TruncatedBlock", func(t *testing.T) {
+		l := newLogsLimiter(FiltersConfig{RpcSubscriptionLogsRateLimit: 1}, nil)
+		require.True(t, l.allow(common.Hash{1}, 1))
+		require.False(t, l.allow(common.Hash{1}, 1))
+		// once the rate allows logs again, the rest of the truncated block is still dropped
+		l.rate.SetLimit(rate.Inf)
+		require.False(t, l.allow(common.Hash{1}, 1))
+		require.True(t, l.allow(common.Hash{2}, 2))
+		require.Len(t, l.notices, 1)
+	})
+
+	t.Run("NoLimits", func(t *testing.T) {
+		ff := New(context.TODO(), DefaultFiltersConfig, nil, nil, nil, func() {}, log.New())
+		_, truncated, id := ff.SubscribeLogsNotifications(256, filters.FilterCriteria{})
+		defer ff.UnsubscribeLogs(id)
+		require.Nil(t, truncated)
+	})
+}
+
+func TestFilters_PollingFiltersExpiry(t *testing.T) {
+	config := DefaultFiltersConfig
+	config.RpcFiltersTTL = time.Minute
+	config.RpcFiltersMaxPerClient = 2
+	ff := New(context.TODO(), config, nil, nil, nil, func() {}, log.New())
+	installLogs := func(owner string) (string, error) {
+		return ff.InstallFilter(owner, func() string {
+			_, id := ff.SubscribeLogs(256, filters.FilterCriteria{})
+			return string(id)
+		})
+	}
+	active, expired := activeFiltersGauge.GetValue(), expiredFiltersCounter.GetValueUint64()
+
+	polled, err := installLogs("10.0.0.1")
+	require.NoError(t, err)
+	idle, err := installLogs("10.0.0.1")
+	require.NoError(t, err)
+	_, err = installLogs("10.0.0.1")
+	require.ErrorContains(t, err, "too many filters installed")
+	other, err := installLogs("10.0.0.2")
+	require.NoError(t, err)
+	require.Equal(t, float64(3), activeFiltersGauge.GetValue()-active)
+
+	// only the filters not polled for the TTL expire
+	now := time.Now()
+	ff.pollingFilters.filters[polled].lastPoll = now.Add(-2 * time.Minute)
+	ff.pollingFilters.filters[idle].lastPoll = now.Add(-2 * time.Minute)
+	require.True(t, ff.PollFilter(polled))
+	ff.expireFilters(now.Add(30 * time.Second))
+	require.True(t, ff.PollFilter(polled))
+	require.False(t, ff.PollFilter(idle))
+	require.True(t, ff.PollFilter(other))
+	require.Equal(t, uint64(1), expiredFiltersCounter.GetValueUint64()-expired)
+	require.False(t, ff.UnsubscribeLogs(LogsSubID(idle)))
+
+	// the expired filter no longer counts towards the limit of its client
+	_, err = installLogs("10.0.0.1")
+	require.NoError(t, err)
+
+	require.True(t, ff.UninstallFilter(other))
+	require.False(t, ff.UninstallFilter(other))
+	require.False(t, ff.PollFilter(other))
+	require.Equal(t, float64(2), activeFiltersGauge.GetValue()-active)
+}
+
+func TestFilterOwner(t *testing.T) {
+	require.Equal(t, "10.0.0.1", FilterOwner("10.0.0.1:8545"))
+	require.Equal(t, "::1", FilterOwner("[::1]:8545"))
+	require.Equal(t, "", FilterOwner(""))
+}
+
+func TestFilters_Close(t *testing.T) {
+	t.Parallel()
+	config := DefaultFiltersConfig
+	config.RpcFiltersTTL = time.Minute
+	ff := New(context.TODO(), config, nil, nil, nil, func() {}, log.New())
+
+	ch, id := ff.SubscribeNewHeads(8)
+	for n := uint64(1); n <= 2; n++ {
+		data, err := rlp.EncodeToBytes(&types.Header{Number: new(big.Int).SetUint64(n), Difficulty: big.NewInt(1)})
+		require.NoError(t, err)
+		ff.OnNewEvent(&remote.SubscribeReply{Type: remote.Event_HEADER, Data: data})
+	}
+	ff.Close()
+
+	// the buffered headers are still delivered before the channel is closed
+	var numbers []uint64
+	for h := range ch {
+		numbers = append(numbers, h.Number.Uint64())
+	}
+	require.Equal(t, []uint64{1, 2}, numbers)
+	require.ErrorIs(t, ff.HeadsSubErr(id), ErrFiltersClosed)
+
+	// the filters are also closed with the context given to New
+	ctx, cancel := context.WithCancel(context.Background())
+	ff = New(ctx, DefaultFiltersConfig, nil, nil, nil, func() {}, log.New())
+	ch, id = ff.SubscribeNewHeads(8)
+	cancel()
+	for range ch {
+	}
+	require.ErrorIs(t, ff.HeadsSubErr(id), ErrFiltersClosed)
+	ff.Close()
+}
+
+// testChainReader is a chain of headers, canonical holding the hashes of the canonical ones by number.
+type testChainReader struct {
+	headers   map[common.Hash]*types.Header
+	canonical []common.Hash
+	logs      types.Logs
+}
+
+func (r *testChainReader) add(header *types.Header, canonical bool) *types.Header {
+	r.headers[header.Hash()] = header
+	if canonical {
+		r.canonical = append(r.canonical[:header.Number.Uint64()], header.Hash())
+	}
+	return header
+}
+
+func (r *testChainReader) Head(context.Context) (uint64, error) {
+	return uint64(len(r.canonical) - 1), nil
+}
+
+func (r *testChainReader) CanonicalHeader(_ context.Context, blockNum uint64) (*types.Header, error) {
+	if blockNum >= uint64(len(r.canonical)) {
+		return nil, nil
+	}
+	return r.headers[r.canonical[blockNum]], nil
+}
+
+func (r *testChainReader) Header(_ context.Context, hash common.Hash, _ uint64) (*types.Header, error) {
+	return r.headers[hash], nil
+}
+
+func (r *testChainReader) Logs(_ context.Context, crit filters.FilterCriteria) (types.Logs, error) {
+	var logs types.Logs
+	for _, lg := range r.logs {
+		if lg.BlockNumber >= crit.FromBlock.Uint64() && lg.BlockNumber <= crit.ToBlock.Uint64() {
+			logs = append(logs, lg)
+		}
+	}
+	return logs, nil
+}
+
+func TestMissedHeaders(t *testing.T) {
+	reader := &testChainReader{headers: make(map[common.Hash]*types.Header)}
+	child := func(parent *types.Header, extra byte, canonical bool) *types.Header {
+		return reader.add(&types.Header{Number: new(big.Int).Add(parent.Number, big.NewInt(1)), ParentHash: parent.Hash(), Extra: []byte{extra}}, canonical)
+	}
+	genesis := reader.add(&types.Header{Number: big.NewInt(0)}, true)
+	h1 := child(genesis, 0, true)
+	h2 := child(h1, 0, true)
+	h3 := child(h2, 0, true)
+	// the last header sent, reorged out by h3
+	orphan := child(child(h1, 1, false), 1, false)
+
+	numbers := func(headers []*types.Header) (nums []uint64) {
+		for _, h := range headers {
+			nums = append(nums, h.Number.Uint64())
+		}
+		return nums
+	}
+	headers, err := missedHeaders(context.Background(), reader, h1)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 3}, numbers(headers))
+
+	headers, err = missedHeaders(context.Background(), reader, orphan)
+	require.NoError(t, err)
+	require.Equal(t, []*types.Header{h2, h3}, headers)
+
+	headers, err = missedHeaders(context.Background(), reader, h3)
+	require.NoError(t, err)
+	require.Empty(t, headers)
+
+	// too many blocks missed
+	last := h3
+	for range maxResyncBlocks {
+		last = child(last, 0, true)
+	}
+	_, err = missedHeaders(context.Background(), reader, h2)
+	require.ErrorIs(t, err, ErrSubscriptionGap)
+}
+
+func TestFilters_CatchUpLogs(t *testing.T) {
+	f := New(context.Background(), DefaultFiltersConfig, nil, nil, nil, func() {}, log.New())
+	defer f.Close()
+	reader := &testChainReader{headers: make(map[common.Hash]*types.Header)}
+	for i := range 4 {
+		reader.add(&types.Header{Number: big.NewInt(int64(i))}, true)
+	}
+	f.SetChainReader(reader)
+	logs, id := f.SubscribeLogs(16, filters.FilterCriteria{})
+	defer f.UnsubscribeLogs(id)
+
+	reply := func(blockNum uint64, index uint64) *remote.SubscribeLogsReply {
+		lg := createLog()
+		lg.BlockNumber, lg.LogIndex = blockNum, index
+		return lg
+	}
+	position := func(lg *types.Log) [2]uint64 { return [2]uint64{lg.BlockNumber, uint64(lg.Index)} }
+
+	// the stream breaks in the middle of the logs of block 2
+	f.OnNewLogs(reply(1, 0))
+	require.Equal(t, [2]uint64{1, 0}, position(<-logs))
+	f.OnNewLogs(reply(2, 1))
+	require.Equal(t, [2]uint64{2, 1}, position(<-logs))
+
+	for _, p := range [][2]uint64{{1, 0}, {2, 1}, {2, 2}, {3, 3}} {
+		reader.logs = append(reader.logs, &types.Log{BlockNumber: p[0], Index: uint(p[1])})
+	}
+	f.logsResubscribed.Store(true)
+	f.setLogsRequester(func(*remote.LogsFilterRequest) error { return nil })
+	require.Equal(t, [2]uint64{2, 2}, position(<-logs))
+	require.Equal(t, [2]uint64{3, 3}, position(<-logs))
+
+	// the new stream sends the logs of the blocks caught up with again
+	f.OnNewLogs(reply(3, 3))
+	f.OnNewLogs(reply(4, 0))
+	require.Equal(t, [2]uint64{4, 0}, position(<-logs))
+}
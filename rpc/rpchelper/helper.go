@@ -177,6 +177,36 @@ func CreateHistoryStateReader(tx kv.TemporalTx, blockNumber uint64, txnIndex int
 	return r, nil
 }
 
+// CreateHistoryStateReaderAtTxn returns a reader of the state after the txn txnIndex of the block blockNum, or
+// before its txns if txnIndex is -1. The txNums of a block are its initial system txn, its txns and its final
+// system txn, which applies the state sync events on Polygon and whose index is the number of txns of the block.
+func CreateHistoryStateReaderAtTxn(tx kv.TemporalTx, blockNum uint64, txnIndex int, txNumsReader rawdbv3.TxNumsReader) (state.StateReader, error) {
+	minTxNum, err := txNumsReader.Min(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	maxTxNum, err := txNumsReader.Max(tx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if maxTxNum <= minTxNum {
+		return nil, fmt.Errorf("block %d not found", blockNum)
+	}
+	if txnCount := int(maxTxNum - minTxNum - 1); txnIndex < -1 || txnIndex > txnCount {
+		return nil, fmt.Errorf("txn index %d out of range, block %d has %d txns", txnIndex, blockNum, txnCount)
+	}
+
+	r := state.NewHistoryReaderV3()
+	r.SetTx(tx)
+	// the reader sees the changes of the txNums before its own
+	txNum := minTxNum + /* initial system txn */ 1 + uint64(txnIndex+1)
+	if txNum < r.StateHistoryStartFrom() {
+		return r, state.PrunedError
+	}
+	r.SetTxNum(txNum)
+	return r, nil
+}
+
 func NewLatestStateReader(getter kv.TemporalGetter) state.StateReader {
 	return state.NewReaderV3(getter)
 }
@@ -18,15 +18,28 @@ package rpchelper
 
 import (
 	"context"
-	"sync/atomic"
+	"errors"
+	"time"
 
 	"github.com/erigontech/erigon-lib/common"
 	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/p2p"
+	"github.com/erigontech/erigon/turbo/shards"
 )
 
+// ErrBackendUnavailable is returned when the execution backend, the node an RPC daemon relies on, can't be reached,
+// e.g. while it restarts.
+var ErrBackendUnavailable = errors.New("execution backend unavailable")
+
+// BackendHealth is the state of the connection of an ApiBackend to the execution backend.
+type BackendHealth struct {
+	Connected bool
+	LastError error     // the error which disconnected it, if any
+	Since     time.Time // when Connected last changed
+}
+
 // ApiBackend - interface which must be used by API layer
 // implementation can work with local Ethereum object or with Remote (grpc-based) one
 // this is reason why all methods are accepting context and returning error
@@ -38,10 +51,13 @@ type ApiBackend interface {
 	ProtocolVersion(ctx context.Context) (uint64, error)
 	ClientVersion(ctx context.Context) (string, error)
 	Subscribe(ctx context.Context, cb func(*remote.SubscribeReply)) error
-	SubscribeLogs(ctx context.Context, cb func(*remote.SubscribeLogsReply), requestor *atomic.Value) error
+	SubscribeBlocks(ctx context.Context, cb func(*shards.NewBlock)) error
+	SubscribePeerEvents(ctx context.Context, cb func(*shards.PeerEvent)) error
+	SubscribeLogs(ctx context.Context, cb func(*remote.SubscribeLogsReply), requestor func(send func(*remote.LogsFilterRequest) error)) error
 	BlockWithSenders(ctx context.Context, tx kv.Getter, hash common.Hash, blockHeight uint64) (block *types.Block, senders []common.Address, err error)
 	NodeInfo(ctx context.Context, limit uint32) ([]p2p.NodeInfo, error)
 	Peers(ctx context.Context) ([]*p2p.PeerInfo, error)
 	AddPeer(ctx context.Context, url *remote.AddPeerRequest) (*remote.AddPeerReply, error)
 	PendingBlock(ctx context.Context) (*types.Block, error)
+	Health(ctx context.Context) BackendHealth
 }
@@ -0,0 +1,330 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpchelper
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/order"
+	"github.com/erigontech/erigon-lib/kv/stream"
+	"github.com/erigontech/erigon-lib/metrics"
+	"github.com/erigontech/erigon/rpc"
+)
+
+// DebugKvStatsPath is the path DebugKvStatsHandler is served at, on the metrics server.
+const DebugKvStatsPath = "/debug/kv/tables"
+
+// kvStatsInterval is the interval over which DebugKvStatsHandler reports the reads.
+const kvStatsInterval = time.Minute
+
+// kvStatsTop is the default number of the tables reported by DebugKvStatsHandler.
+const kvStatsTop = 20
+
+// kvStats are the reads of the databases wrapped by NewKvStatsDB.
+var kvStats = newKvStatsLog(kvStatsInterval)
+
+// kvStatsKey is the method of the requests reading a table, a domain or an inverted index.
+type kvStatsKey struct {
+	method string
+	table  string
+}
+
+// kvStatsEntry are the reads of a table by a method. The latency of a cursor is the one of its opening, its moves
+// aren't timed.
+type kvStatsEntry struct {
+	gets     atomic.Uint64
+	ranges   atomic.Uint64
+	duration atomic.Int64
+}
+
+// kvStatsMetrics are the metrics of the reads of a table by a method.
+type kvStatsMetrics struct {
+	gets     metrics.Counter
+	ranges   metrics.Counter
+	duration metrics.Counter
+}
+
+func newKvStatsMetrics(key kvStatsKey) *kvStatsMetrics {
+	return &kvStatsMetrics{
+		gets:     metrics.GetOrCreateCounter(fmt.Sprintf(`rpc_kv_gets{method="%s",table="%s"}`, key.method, key.table)),
+		ranges:   metrics.GetOrCreateCounter(fmt.Sprintf(`rpc_kv_ranges{method="%s",table="%s"}`, key.method, key.table)),
+		duration: metrics.GetOrCreateCounter(fmt.Sprintf(`rpc_kv_read_microseconds{method="%s",table="%s"}`, key.method, key.table)),
+	}
+}
+
+// kvStatsWindow are the reads of an interval.
+type kvStatsWindow struct {
+	start   time.Time
+	entries sync.Map // kvStatsKey -> *kvStatsEntry
+}
+
+// kvStatsLog are the reads of the current interval and of the last complete one, and the metrics of all of them.
+type kvStatsLog struct {
+	interval time.Duration
+	current  atomic.Pointer[kvStatsWindow]
+	last     atomic.Pointer[kvStatsWindow]
+	metrics  sync.Map // kvStatsKey -> *kvStatsMetrics
+}
+
+func newKvStatsLog(interval time.Duration) *kvStatsLog {
+	l := &kvStatsLog{interval: interval}
+	l.current.Store(&kvStatsWindow{start: time.Now()})
+	return l
+}
+
+// window returns the window of the reads at now, beginning a new one if the current one is over.
+func (l *kvStatsLog) window(now time.Time) *kvStatsWindow {
+	w := l.current.Load()
+	if now.Sub(w.start) < l.interval {
+		return w
+	}
+	if next := (&kvStatsWindow{start: now}); l.current.CompareAndSwap(w, next) {
+		l.last.Store(w)
+	}
+	return l.current.Load()
+}
+
+// add counts a read of table by method, started at start: a get, or a range read.
+func (l *kvStatsLog) add(method, table string, get bool, start time.Time) {
+	now := time.Now()
+	duration := now.Sub(start)
+	key := kvStatsKey{method: method, table: table}
+	w := l.window(now)
+	e, ok := w.entries.Load(key)
+	if !ok {
+		e, _ = w.entries.LoadOrStore(key, &kvStatsEntry{})
+	}
+	m, ok := l.metrics.Load(key)
+	if !ok {
+		m, _ = l.metrics.LoadOrStore(key, newKvStatsMetrics(key))
+	}
+	entry, counters := e.(*kvStatsEntry), m.(*kvStatsMetrics)
+	if get {
+		entry.gets.Add(1)
+		counters.gets.Inc()
+	} else {
+		entry.ranges.Add(1)
+		counters.ranges.Inc()
+	}
+	entry.duration.Add(int64(duration))
+	counters.duration.AddInt(int(duration.Microseconds()))
+}
+
+// tableStats are the reads of a table by a method, as reported by DebugKvStatsHandler.
+type tableStats struct {
+	Method   string `json:"method"`
+	Table    string `json:"table"`
+	Gets     uint64 `json:"gets"`
+	Ranges   uint64 `json:"ranges"`
+	Duration string `json:"duration"`
+
+	duration time.Duration
+}
+
+// top returns the n tables read the longest in the last complete interval, or in the current one if none is
+// complete yet, and the start of the interval. The intervals begin with their first read, so that the last complete
+// one is the last in which the tables were read.
+func (l *kvStatsLog) top(n int) ([]tableStats, time.Time) {
+	w := l.window(time.Now())
+	if last := l.last.Load(); last != nil {
+		w = last
+	}
+	var tables []tableStats
+	w.entries.Range(func(k, v any) bool {
+		key, e := k.(kvStatsKey), v.(*kvStatsEntry)
+		duration := time.Duration(e.duration.Load())
+		tables = append(tables, tableStats{Method: key.method, Table: key.table, Gets: e.gets.Load(), Ranges: e.ranges.Load(),
+			Duration: duration.String(), duration: duration})
+		return true
+	})
+	slices.SortFunc(tables, func(a, b tableStats) int { return cmp.Compare(b.duration, a.duration) })
+	if len(tables) > n {
+		tables = tables[:n]
+	}
+	return tables, w.start
+}
+
+// DebugKvStatsHandler reports the tables the RPC requests read the longest in the last interval, per method, see
+// --rpc.kvstats. The number of the tables reported is set by the n query parameter.
+func DebugKvStatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := kvStatsTop
+		if s := r.URL.Query().Get("n"); s != "" {
+			var err error
+			if n, err = strconv.Atoi(s); err != nil || n < 0 {
+				http.Error(w, "invalid n", http.StatusBadRequest)
+				return
+			}
+		}
+		var stats struct {
+			Since  time.Time    `json:"since"`
+			Tables []tableStats `json:"tables"`
+		}
+		stats.Tables, stats.Since = kvStats.top(n)
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(&stats)
+	})
+}
+
+// kvStatsMethod is the label of the reads of the requests not labelled with their method, see rpc.MethodFromContext.
+const kvStatsMethod = "other"
+
+// KvStatsDB is a database counting the reads of its transactions per table, domain and inverted index, and per
+// method of the requests beginning them.
+type KvStatsDB struct {
+	kv.TemporalRoDB
+	stats *kvStatsLog
+}
+
+func NewKvStatsDB(db kv.TemporalRoDB) *KvStatsDB {
+	return &KvStatsDB{TemporalRoDB: db, stats: kvStats}
+}
+
+func (db *KvStatsDB) wrap(ctx context.Context, tx kv.TemporalTx) kv.TemporalTx {
+	method := rpc.MethodFromContext(ctx)
+	if method == "" {
+		method = kvStatsMethod
+	}
+	return &kvStatsTx{TemporalTx: tx, stats: db.stats, method: method}
+}
+
+func (db *KvStatsDB) BeginTemporalRo(ctx context.Context) (kv.TemporalTx, error) {
+	tx, err := db.TemporalRoDB.BeginTemporalRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return db.wrap(ctx, tx), nil
+}
+
+func (db *KvStatsDB) ViewTemporal(ctx context.Context, f func(tx kv.TemporalTx) error) error {
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return f(tx)
+}
+
+func (db *KvStatsDB) BeginRo(ctx context.Context) (kv.Tx, error) {
+	return db.BeginTemporalRo(ctx)
+}
+
+func (db *KvStatsDB) View(ctx context.Context, f func(tx kv.Tx) error) error {
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return f(tx)
+}
+
+// kvStatsTx is a transaction of a KvStatsDB, begun by a request of method.
+type kvStatsTx struct {
+	kv.TemporalTx
+	stats  *kvStatsLog
+	method string
+}
+
+func (tx *kvStatsTx) GetOne(table string, key []byte) ([]byte, error) {
+	defer tx.stats.add(tx.method, table, true, time.Now())
+	return tx.TemporalTx.GetOne(table, key)
+}
+
+func (tx *kvStatsTx) Has(table string, key []byte) (bool, error) {
+	defer tx.stats.add(tx.method, table, true, time.Now())
+	return tx.TemporalTx.Has(table, key)
+}
+
+func (tx *kvStatsTx) ForEach(table string, fromPrefix []byte, walker func(k, v []byte) error) error {
+	defer tx.stats.add(tx.method, table, false, time.Now())
+	return tx.TemporalTx.ForEach(table, fromPrefix, walker)
+}
+
+func (tx *kvStatsTx) ForAmount(table string, prefix []byte, amount uint32, walker func(k, v []byte) error) error {
+	defer tx.stats.add(tx.method, table, false, time.Now())
+	return tx.TemporalTx.ForAmount(table, prefix, amount, walker)
+}
+
+func (tx *kvStatsTx) Cursor(table string) (kv.Cursor, error) {
+	defer tx.stats.add(tx.method, table, false, time.Now())
+	return tx.TemporalTx.Cursor(table)
+}
+
+func (tx *kvStatsTx) CursorDupSort(table string) (kv.CursorDupSort, error) {
+	defer tx.stats.add(tx.method, table, false, time.Now())
+	return tx.TemporalTx.CursorDupSort(table)
+}
+
+func (tx *kvStatsTx) Range(table string, fromPrefix, toPrefix []byte, asc order.By, limit int) (stream.KV, error) {
+	defer tx.stats.add(tx.method, table, false, time.Now())
+	return tx.TemporalTx.Range(table, fromPrefix, toPrefix, asc, limit)
+}
+
+func (tx *kvStatsTx) Prefix(table string, prefix []byte) (stream.KV, error) {
+	defer tx.stats.add(tx.method, table, false, time.Now())
+	return tx.TemporalTx.Prefix(table, prefix)
+}
+
+func (tx *kvStatsTx) RangeDupSort(table string, key []byte, fromPrefix, toPrefix []byte, asc order.By, limit int) (stream.KV, error) {
+	defer tx.stats.add(tx.method, table, false, time.Now())
+	return tx.TemporalTx.RangeDupSort(table, key, fromPrefix, toPrefix, asc, limit)
+}
+
+func (tx *kvStatsTx) GetLatest(name kv.Domain, k []byte) ([]byte, uint64, error) {
+	defer tx.stats.add(tx.method, name.String(), true, time.Now())
+	return tx.TemporalTx.GetLatest(name, k)
+}
+
+func (tx *kvStatsTx) HasPrefix(name kv.Domain, prefix []byte) ([]byte, []byte, bool, error) {
+	defer tx.stats.add(tx.method, name.String(), true, time.Now())
+	return tx.TemporalTx.HasPrefix(name, prefix)
+}
+
+func (tx *kvStatsTx) GetAsOf(name kv.Domain, k []byte, ts uint64) ([]byte, bool, error) {
+	defer tx.stats.add(tx.method, name.String(), true, time.Now())
+	return tx.TemporalTx.GetAsOf(name, k, ts)
+}
+
+func (tx *kvStatsTx) HistorySeek(name kv.Domain, k []byte, ts uint64) ([]byte, bool, error) {
+	defer tx.stats.add(tx.method, name.String(), true, time.Now())
+	return tx.TemporalTx.HistorySeek(name, k, ts)
+}
+
+func (tx *kvStatsTx) RangeAsOf(name kv.Domain, fromKey, toKey []byte, ts uint64, asc order.By, limit int) (stream.KV, error) {
+	defer tx.stats.add(tx.method, name.String(), false, time.Now())
+	return tx.TemporalTx.RangeAsOf(name, fromKey, toKey, ts, asc, limit)
+}
+
+func (tx *kvStatsTx) IndexRange(name kv.InvertedIdx, k []byte, fromTs, toTs int, asc order.By, limit int) (stream.U64, error) {
+	defer tx.stats.add(tx.method, name.String(), false, time.Now())
+	return tx.TemporalTx.IndexRange(name, k, fromTs, toTs, asc, limit)
+}
+
+func (tx *kvStatsTx) HistoryRange(name kv.Domain, fromTs, toTs int, asc order.By, limit int) (stream.KV, error) {
+	defer tx.stats.add(tx.method, name.String(), false, time.Now())
+	return tx.TemporalTx.HistoryRange(name, fromTs, toTs, asc, limit)
+}
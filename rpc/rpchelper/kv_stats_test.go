@@ -0,0 +1,97 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpchelper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/order"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/rpc"
+)
+
+// kvStatsService reads the database in a known pattern.
+type kvStatsService struct {
+	db kv.TemporalRoDB
+}
+
+func (s *kvStatsService) Read(ctx context.Context, gets int) error {
+	return s.db.ViewTemporal(ctx, func(tx kv.TemporalTx) error {
+		for i := 0; i < gets; i++ {
+			if _, err := tx.GetOne(kv.HeaderCanonical, []byte{0}); err != nil {
+				return err
+			}
+		}
+		if _, _, err := tx.GetLatest(kv.AccountsDomain, []byte{1}); err != nil {
+			return err
+		}
+		it, err := tx.IndexRange(kv.LogAddrIdx, []byte{2}, 0, -1, order.Asc, -1)
+		if err != nil {
+			return err
+		}
+		it.Close()
+		return nil
+	})
+}
+
+func TestKvStatsDB(t *testing.T) {
+	logger := log.New()
+	db := NewKvStatsDB(temporaltest.NewTestDB(t, datadir.New(t.TempDir())))
+	server := rpc.NewServer(1, false /* traceRequests */, false /* debugSingleRequest */, true, logger, 0)
+	server.SetMethodLabels(true)
+	require.NoError(t, server.RegisterName("kvstats", &kvStatsService{db: db}))
+	client := rpc.DialInProc(server, logger)
+	defer client.Close()
+
+	require.NoError(t, client.Call(nil, "kvstats_read", 3))
+	require.NoError(t, client.Call(nil, "kvstats_read", 2))
+	// the reads of the requests not labelled with their method
+	require.NoError(t, (&kvStatsService{db: db}).Read(context.Background(), 1))
+
+	rec := httptest.NewRecorder()
+	DebugKvStatsHandler().ServeHTTP(rec, httptest.NewRequest("GET", DebugKvStatsPath+"?n=100", nil))
+	var stats struct {
+		Tables []struct {
+			Method string `json:"method"`
+			Table  string `json:"table"`
+			Gets   uint64 `json:"gets"`
+			Ranges uint64 `json:"ranges"`
+		} `json:"tables"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&stats))
+	reads := make(map[[2]string][2]uint64)
+	for _, table := range stats.Tables {
+		reads[[2]string{table.Method, table.Table}] = [2]uint64{table.Gets, table.Ranges}
+	}
+	require.Equal(t, [2]uint64{5, 0}, reads[[2]string{"kvstats_read", kv.HeaderCanonical}])
+	require.Equal(t, [2]uint64{2, 0}, reads[[2]string{"kvstats_read", "accounts"}])
+	require.Equal(t, [2]uint64{0, 2}, reads[[2]string{"kvstats_read", "logaddrs"}])
+	require.Equal(t, [2]uint64{1, 0}, reads[[2]string{kvStatsMethod, kv.HeaderCanonical}])
+
+	rec = httptest.NewRecorder()
+	DebugKvStatsHandler().ServeHTTP(rec, httptest.NewRequest("GET", DebugKvStatsPath+"?n=1", nil))
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&stats))
+	require.Len(t, stats.Tables, 1)
+}
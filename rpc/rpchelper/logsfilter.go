@@ -44,6 +44,11 @@ type LogsFilter struct {
 	topics         *concurrent.SyncMap[common.Hash, int]
 	topicsOriginal [][]common.Hash // Original topic filters to be applied before distributing to individual subscribers
 	sender         Sub[*types.Log] // nil for aggregate subscriber, for appropriate stream server otherwise
+	limiter        *logsLimiter    // nil if the logs sent to the subscriber aren't limited
+
+	// only used by the goroutine of the logs stream of the backend
+	last       logPosition // last log sent to the subscriber
+	caughtUpTo uint64      // last block of the catch-up after a resubscription, see Filters.catchUpLogs
 }
 
 // Send sends a log to the subscriber represented by the LogsFilter.
@@ -52,6 +57,21 @@ func (l *LogsFilter) Send(lg *types.Log) {
 	l.sender.Send(lg)
 }
 
+// send sends lg to the subscriber, unless the limiter drops it or it was sent already: the logs of the blocks a
+// catch-up went through may be sent again by the backend.
+func (l *LogsFilter) send(lg *types.Log) {
+	if !lg.Removed && lg.BlockNumber <= l.caughtUpTo && !l.last.before(lg) {
+		return
+	}
+	if l.limiter != nil && !l.limiter.allow(lg.BlockHash, lg.BlockNumber) {
+		return
+	}
+	l.sender.Send(lg)
+	if !lg.Removed {
+		l.last = logPosition{blockNum: lg.BlockNumber, index: lg.Index, set: true}
+	}
+}
+
 // Close closes the sender associated with the LogsFilter.
 // It is used to properly clean up and release resources associated with the sender.
 func (l *LogsFilter) Close() {
@@ -70,16 +90,17 @@ func NewLogsFilterAggregator() *LogsFilterAggregator {
 	}
 }
 
-// insertLogsFilter inserts a new log filter into the LogsFilterAggregator with the specified sender.
+// insertLogsFilter inserts a new log filter into the LogsFilterAggregator with the specified sender and limiter.
 // It generates a new filter ID, creates a new LogsFilter, and adds it to the logsFilters map.
-func (a *LogsFilterAggregator) insertLogsFilter(sender Sub[*types.Log]) (LogsSubID, *LogsFilter) {
+func (a *LogsFilterAggregator) insertLogsFilter(sender Sub[*types.Log], limiter *logsLimiter) (LogsSubID, *LogsFilter) {
 	a.logsFilterLock.Lock()
 	defer a.logsFilterLock.Unlock()
 	filterId := LogsSubID(generateSubscriptionID())
 	filter := &LogsFilter{
-		addrs:  concurrent.NewSyncMap[common.Address, int](),
-		topics: concurrent.NewSyncMap[common.Hash, int](),
-		sender: sender,
+		addrs:   concurrent.NewSyncMap[common.Address, int](),
+		topics:  concurrent.NewSyncMap[common.Hash, int](),
+		sender:  sender,
+		limiter: limiter,
 	}
 	a.logsFilters.Put(filterId, filter)
 	return filterId, filter
@@ -256,7 +277,7 @@ func (a *LogsFilterAggregator) distributeLog(eventLog *remote.SubscribeLogsReply
 		lg.Index = uint(eventLog.LogIndex)
 		lg.Removed = eventLog.Removed
 
-		filter.sender.Send(&lg)
+		filter.send(&lg)
 		return nil
 	})
 	return nil
@@ -0,0 +1,110 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpchelper
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+)
+
+// LogsTruncated is notified to a logs subscription, in place of the logs of a block it didn't get because they
+// exceeded RpcSubscriptionLogsRateLimit or RpcSubscriptionLogsMaxPerBlock. There is at most one per block.
+type LogsTruncated struct {
+	Truncated   bool           `json:"truncated"` // always true, tells the notice apart from a log
+	BlockHash   common.Hash    `json:"blockHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Reason      string         `json:"reason"`
+}
+
+// logsTruncatedBufferSize is the number of notices buffered per subscription, notices beyond it are dropped.
+const logsTruncatedBufferSize = 16
+
+// logsLimiter enforces the notification limits of FiltersConfig on a logs subscription.
+type logsLimiter struct {
+	lock        sync.Mutex
+	rate        *rate.Limiter // nil if the number of logs per second isn't limited
+	global      *rate.Limiter // shared by all logs subscriptions, nil if their total isn't limited
+	maxPerBlock int           // 0 if the number of logs per block isn't limited
+	block       common.Hash   // block of the last log
+	inBlock     int           // logs of block so far
+	truncated   bool          // whether logs of block were dropped, and the subscriber was told
+	notices     chan *LogsTruncated
+}
+
+// newLogsLimiter returns nil if config doesn't limit logs notifications. global is the limiter of
+// RpcSubscriptionLogsGlobalRateLimit shared by all subscriptions, see newLogsRateLimiter.
+func newLogsLimiter(config FiltersConfig, global *rate.Limiter) *logsLimiter {
+	if config.RpcSubscriptionLogsRateLimit <= 0 && config.RpcSubscriptionLogsMaxPerBlock <= 0 && global == nil {
+		return nil
+	}
+	return &logsLimiter{
+		rate:        newLogsRateLimiter(config.RpcSubscriptionLogsRateLimit),
+		global:      global,
+		maxPerBlock: max(config.RpcSubscriptionLogsMaxPerBlock, 0),
+		notices:     make(chan *LogsTruncated, logsTruncatedBufferSize),
+	}
+}
+
+// newLogsRateLimiter returns a limiter of perSecond logs per second, or nil if perSecond isn't positive.
+func newLogsRateLimiter(perSecond int) *rate.Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	// allow a burst of one second worth of logs, a block arrives all at once
+	return rate.NewLimiter(rate.Limit(perSecond), perSecond)
+}
+
+// allow reports whether a log of the given block can be sent without exceeding a limit. The first time a log of
+// a block is refused, a LogsTruncated notice is queued for the subscriber; the following ones are coalesced into it.
+func (l *logsLimiter) allow(block common.Hash, blockNum uint64) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if block != l.block {
+		l.block, l.inBlock, l.truncated = block, 0, false
+	}
+	if l.truncated {
+		// the rest of a truncated block is dropped as well, even if the rate limits allow logs again,
+		// so that the subscriber never gets logs of a block after its notice
+		return false
+	}
+	l.inBlock++
+	var reason string
+	switch {
+	case l.maxPerBlock > 0 && l.inBlock > l.maxPerBlock:
+		logsPerBlockLimitExceededCounter.Inc()
+		reason = fmt.Sprintf("more than %d logs in block", l.maxPerBlock)
+	case l.rate != nil && !l.rate.Allow():
+		logsRateLimitExceededCounter.Inc()
+		reason = fmt.Sprintf("more than %d logs per second", l.rate.Burst())
+	case l.global != nil && !l.global.Allow():
+		logsGlobalRateLimitExceededCounter.Inc()
+		reason = fmt.Sprintf("more than %d logs per second across subscriptions", l.global.Burst())
+	default:
+		return true
+	}
+	l.truncated = true
+	select {
+	case l.notices <- &LogsTruncated{Truncated: true, BlockHash: block, BlockNumber: hexutil.Uint64(blockNum), Reason: reason}:
+	default: // the subscriber has notices pending already
+	}
+	return false
+}
@@ -32,4 +32,17 @@ var (
 	activeSubscriptionsLogsAddressesGauge    = metrics.GetOrCreateGauge("subscriptions_logs_addresses")
 	activeSubscriptionsLogsTopicsGauge       = metrics.GetOrCreateGauge("subscriptions_logs_topics")
 	activeSubscriptionsLogsClientGauge       = metrics.GetOrCreateGaugeVec("subscriptions_logs_client", []string{clientLabelName}, "Current number of subscriptions by client")
+
+	droppedHeadsNotificationsCounter      = metrics.GetOrCreateCounter(`subscriptions_dropped_notifications{filter="heads"}`)
+	droppedFullBlocksNotificationsCounter = metrics.GetOrCreateCounter(`subscriptions_dropped_notifications{filter="full_blocks"}`)
+	droppedLogsNotificationsCounter       = metrics.GetOrCreateCounter(`subscriptions_dropped_notifications{filter="logs"}`)
+	droppedPeerEventsNotificationsCounter = metrics.GetOrCreateCounter(`subscriptions_dropped_notifications{filter="peer_events"}`)
+	droppedPendingTxsNotificationsCounter = metrics.GetOrCreateCounter(`subscriptions_dropped_notifications{filter="pending_txs"}`)
+
+	activeFiltersGauge    = metrics.GetOrCreateGauge("filters_active")
+	expiredFiltersCounter = metrics.GetOrCreateCounter("filters_expired")
+
+	logsRateLimitExceededCounter       = metrics.GetOrCreateCounter(`subscriptions_logs_limit_exceeded{limit="rate"}`)
+	logsPerBlockLimitExceededCounter   = metrics.GetOrCreateCounter(`subscriptions_logs_limit_exceeded{limit="block"}`)
+	logsGlobalRateLimitExceededCounter = metrics.GetOrCreateCounter(`subscriptions_logs_limit_exceeded{limit="global_rate"}`)
 )
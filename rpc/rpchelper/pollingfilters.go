@@ -0,0 +1,285 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpchelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/erigontech/erigon-lib/common"
+)
+
+// ErrFilterNotFound is returned when polling a filter which was never installed, was uninstalled or has expired.
+var ErrFilterNotFound = errors.New("filter not found")
+
+// pollingFilter is a filter installed by eth_newFilter, eth_newBlockFilter or eth_newPendingTransactionFilter,
+// which the client polls for changes.
+type pollingFilter struct {
+	owner    string          // IP address of the client which installed the filter
+	lastPoll time.Time       // when the filter was installed or last polled
+	fullTx   bool            // pending transactions filter returning the transactions rather than their hashes
+	blocks   *reportedBlocks // blocks reported by a block filter, nil for the other filters
+}
+
+// maxReportedBlocks is the number of the latest blocks reported by a block filter it remembers, to find the fork
+// point of a reorg. The blocks of deeper reorgs are reported from the oldest block remembered.
+const maxReportedBlocks = 128
+
+// reportedBlocks are the latest consecutive blocks reported by a block filter.
+type reportedBlocks struct {
+	first  uint64        // number of the first block
+	hashes []common.Hash // hashes of the blocks from first
+}
+
+// pollingFilters tracks the polling filters, to expire the ones not polled for FiltersConfig.RpcFiltersTTL and
+// to bound the number of filters installed by a client.
+type pollingFilters struct {
+	mu       sync.Mutex
+	filters  map[string]*pollingFilter
+	perOwner map[string]int
+}
+
+func newPollingFilters() *pollingFilters {
+	return &pollingFilters{
+		filters:  make(map[string]*pollingFilter),
+		perOwner: make(map[string]int),
+	}
+}
+
+// FilterOwner returns the owner of the filters installed by the client of the given request: its IP address,
+// so that the filters of an HTTP client, which opens a connection per request, are counted together.
+func FilterOwner(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
+
+// InstallFilter installs the polling filter created by subscribe for the given owner, unless the owner already
+// has FiltersConfig.RpcFiltersMaxPerClient filters installed.
+func (ff *Filters) InstallFilter(owner string, subscribe func() string) (string, error) {
+	return ff.installFilter(owner, &pollingFilter{}, subscribe)
+}
+
+// InstallPendingTxsFilter installs a polling filter for the given owner buffering the transactions added to the
+// txpool until they are read by ReadPendingTxs. At most RpcSubscriptionFiltersMaxTxs transactions are buffered,
+// the oldest ones are dropped. fullTx is the format the client asked for, reported by FullTxFilter.
+func (ff *Filters) InstallPendingTxsFilter(owner string, fullTx bool) (PendingTxsSubID, error) {
+	id, err := ff.installFilter(owner, &pollingFilter{fullTx: fullTx}, func() string {
+		txsCh, id := ff.SubscribePendingTxsInternal(32)
+		go func() {
+			for txs := range txsCh {
+				ff.AddPendingTxs(id, txs)
+			}
+		}()
+		return string(id)
+	})
+	return PendingTxsSubID(id), err
+}
+
+// InstallBlockFilter installs a polling filter for the given owner reporting the blocks added to the canonical chain
+// after the given head.
+func (ff *Filters) InstallBlockFilter(owner string, headNum uint64, headHash common.Hash) (string, error) {
+	blocks := &reportedBlocks{first: headNum, hashes: []common.Hash{headHash}}
+	return ff.installFilter(owner, &pollingFilter{blocks: blocks}, func() string {
+		return string(generateSubscriptionID())
+	})
+}
+
+// IsBlockFilter returns true if the given polling filter was installed by InstallBlockFilter.
+func (ff *Filters) IsBlockFilter(id string) bool {
+	ff.pollingFilters.mu.Lock()
+	defer ff.pollingFilters.mu.Unlock()
+	f, ok := ff.pollingFilters.filters[id]
+	return ok && f.blocks != nil
+}
+
+// BlockFilterChanges returns the hashes of the canonical blocks up to head which the given block filter hasn't
+// reported yet, canonicalHash giving the hash of the canonical block of a number. If the chain reorged below the
+// last reported block, the blocks are reported again from the fork point. At most RpcSubscriptionFiltersMaxHeaders
+// hashes are returned, the newest ones.
+func (ff *Filters) BlockFilterChanges(id string, head uint64, canonicalHash func(blockNum uint64) (common.Hash, bool, error)) ([]common.Hash, error) {
+	ff.pollingFilters.mu.Lock()
+	f, ok := ff.pollingFilters.filters[id]
+	if !ok || f.blocks == nil {
+		ff.pollingFilters.mu.Unlock()
+		return nil, ErrFilterNotFound
+	}
+	reported := *f.blocks
+	ff.pollingFilters.mu.Unlock()
+
+	// the fork point is the newest reported block which is still canonical
+	next := reported.first
+	for i := len(reported.hashes) - 1; i >= 0; i-- {
+		blockNum := reported.first + uint64(i)
+		if blockNum > head {
+			continue
+		}
+		hash, ok, err := canonicalHash(blockNum)
+		if err != nil {
+			return nil, err
+		}
+		if ok && hash == reported.hashes[i] {
+			next = blockNum + 1
+			break
+		}
+	}
+	changes := make([]common.Hash, 0)
+	for blockNum := next; blockNum <= head; blockNum++ {
+		hash, ok, err := canonicalHash(blockNum)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		changes = append(changes, hash)
+	}
+	if len(changes) == 0 {
+		return changes, nil
+	}
+
+	hashes := append(reported.hashes[:next-reported.first:next-reported.first], changes...)
+	first := reported.first
+	if len(hashes) > maxReportedBlocks {
+		first += uint64(len(hashes) - maxReportedBlocks)
+		hashes = hashes[len(hashes)-maxReportedBlocks:]
+	}
+	ff.pollingFilters.mu.Lock()
+	if f, ok := ff.pollingFilters.filters[id]; ok {
+		f.blocks = &reportedBlocks{first: first, hashes: hashes}
+	}
+	ff.pollingFilters.mu.Unlock()
+
+	if maxHeaders := ff.config.RpcSubscriptionFiltersMaxHeaders; maxHeaders > 0 && len(changes) > maxHeaders {
+		changes = changes[len(changes)-maxHeaders:]
+	}
+	return changes, nil
+}
+
+// FullTxFilter returns true if the given pending transactions filter returns the transactions rather than their
+// hashes.
+func (ff *Filters) FullTxFilter(id string) bool {
+	ff.pollingFilters.mu.Lock()
+	defer ff.pollingFilters.mu.Unlock()
+	f, ok := ff.pollingFilters.filters[id]
+	return ok && f.fullTx
+}
+
+// installFilter installs f, the polling filter created by subscribe, for the given owner.
+func (ff *Filters) installFilter(owner string, f *pollingFilter, subscribe func() string) (string, error) {
+	ff.pollingFilters.mu.Lock()
+	defer ff.pollingFilters.mu.Unlock()
+	if maxFilters := ff.config.RpcFiltersMaxPerClient; maxFilters > 0 && ff.pollingFilters.perOwner[owner] >= maxFilters {
+		return "", fmt.Errorf("too many filters installed, limit %d (can increase by --rpc.filters.maxperclient)", maxFilters)
+	}
+	id := subscribe()
+	f.owner, f.lastPoll = owner, time.Now()
+	ff.pollingFilters.filters[id] = f
+	ff.pollingFilters.perOwner[owner]++
+	activeFiltersGauge.Inc()
+	return id, nil
+}
+
+// PollFilter refreshes the TTL of the given polling filter. It returns false if the filter isn't installed.
+func (ff *Filters) PollFilter(id string) bool {
+	ff.pollingFilters.mu.Lock()
+	defer ff.pollingFilters.mu.Unlock()
+	f, ok := ff.pollingFilters.filters[id]
+	if ok {
+		f.lastPoll = time.Now()
+	}
+	return ok
+}
+
+// UninstallFilter removes the given polling filter. It returns false if no filter had this id.
+func (ff *Filters) UninstallFilter(id string) bool {
+	ff.pollingFilters.mu.Lock()
+	forgotten := ff.forgetFilter(id)
+	ff.pollingFilters.mu.Unlock()
+	return ff.unsubscribeFilter(id) || forgotten
+}
+
+// forgetFilter stops tracking the given polling filter, the caller holds ff.pollingFilters.mu. It returns false if
+// the filter wasn't tracked.
+func (ff *Filters) forgetFilter(id string) bool {
+	f, ok := ff.pollingFilters.filters[id]
+	if !ok {
+		return false
+	}
+	delete(ff.pollingFilters.filters, id)
+	if ff.pollingFilters.perOwner[f.owner]--; ff.pollingFilters.perOwner[f.owner] <= 0 {
+		delete(ff.pollingFilters.perOwner, f.owner)
+	}
+	activeFiltersGauge.Dec()
+	return true
+}
+
+func (ff *Filters) unsubscribeFilter(id string) bool {
+	var deleted bool
+	if ff.UnsubscribeHeads(HeadsSubID(id)) {
+		deleted = true
+	}
+	if ff.UnsubscribePendingTxs(PendingTxsSubID(id)) {
+		deleted = true
+	}
+	if ff.UnsubscribeLogs(LogsSubID(id)) {
+		deleted = true
+	}
+	return deleted
+}
+
+// expireFilters uninstalls the polling filters not polled since now-FiltersConfig.RpcFiltersTTL.
+func (ff *Filters) expireFilters(now time.Time) {
+	var expired []string
+	ff.pollingFilters.mu.Lock()
+	for id, f := range ff.pollingFilters.filters {
+		if now.Sub(f.lastPoll) > ff.config.RpcFiltersTTL {
+			expired = append(expired, id)
+		}
+	}
+	for _, id := range expired {
+		ff.forgetFilter(id)
+	}
+	ff.pollingFilters.mu.Unlock()
+
+	for _, id := range expired {
+		ff.unsubscribeFilter(id)
+		expiredFiltersCounter.Inc()
+	}
+	if len(expired) > 0 {
+		ff.logger.Debug("rpc filters: expired filters", "count", len(expired))
+	}
+}
+
+// expireFiltersLoop expires the polling filters until ctx is done, checking a few times per TTL.
+func (ff *Filters) expireFiltersLoop(ctx context.Context) {
+	ticker := time.NewTicker(max(ff.config.RpcFiltersTTL/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ff.expireFilters(now)
+		}
+	}
+}
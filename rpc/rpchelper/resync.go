@@ -0,0 +1,216 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpchelper
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/eth/filters"
+)
+
+// maxResyncBlocks is the number of blocks the subscriptions catch up with once the filters resubscribe to the
+// backend. The subscriptions which missed more are closed with ErrSubscriptionGap.
+const maxResyncBlocks = 1024
+
+// ErrSubscriptionGap is the reason a subscription is closed when it can't catch up with the blocks notified while
+// the filters weren't subscribed to the backend.
+var ErrSubscriptionGap = errors.New("subscription closed: missed the blocks notified while the node was unreachable")
+
+// ChainReader reads the chain, for the subscriptions to catch up with the blocks they missed while the filters
+// weren't subscribed to the backend, see Filters.SetChainReader.
+type ChainReader interface {
+	// Head returns the number of the latest executed block.
+	Head(ctx context.Context) (uint64, error)
+	// CanonicalHeader returns the canonical header of the given number, nil if there is none.
+	CanonicalHeader(ctx context.Context, blockNum uint64) (*types.Header, error)
+	// Header returns the header of the given hash and number, nil if it is unknown.
+	Header(ctx context.Context, hash common.Hash, blockNum uint64) (*types.Header, error)
+	// Logs returns the logs of the canonical blocks matching crit, whose block range is always set.
+	Logs(ctx context.Context, crit filters.FilterCriteria) (types.Logs, error)
+}
+
+// SetChainReader sets the reader of the chain the subscriptions catch up with once the filters resubscribe to the
+// backend, e.g. after it restarted. Without one the subscriptions miss the blocks notified meanwhile.
+func (ff *Filters) SetChainReader(r ChainReader) {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	ff.chainReader = r
+}
+
+func (ff *Filters) loadChainReader() ChainReader {
+	ff.mu.RLock()
+	defer ff.mu.RUnlock()
+	return ff.chainReader
+}
+
+// resyncHeads sends the heads subscriptions the headers of the blocks added to the chain since the last header they
+// were sent, once the events stream is reopened: from the fork point if that header was reorged out meanwhile.
+// The subscriptions are closed with ErrSubscriptionGap if the headers can't be read, or are too many.
+func (ff *Filters) resyncHeads(ctx context.Context) {
+	reader := ff.loadChainReader()
+	ff.headsMu.Lock()
+	defer ff.headsMu.Unlock()
+	if reader == nil || ff.lastHead == nil {
+		return
+	}
+	headers, err := missedHeaders(ctx, reader, ff.lastHead)
+	if err != nil {
+		ff.logger.Warn("rpc filters: heads subscriptions can't catch up after resubscribing", "err", err)
+		closeSubs(ff.headsSubs, ErrSubscriptionGap)
+		ff.lastHead = nil
+		return
+	}
+	if len(headers) == 0 {
+		return
+	}
+	ff.logger.Debug("rpc filters: heads subscriptions catching up after resubscribing", "blocks", len(headers))
+	ff.replayedHeads = make(map[uint64]common.Hash, len(headers))
+	for _, header := range headers {
+		ff.replayedHeads[header.Number.Uint64()] = header.Hash()
+		ff.sendHeader(header)
+	}
+}
+
+// missedHeaders returns the canonical headers following last up to the head, or following the fork point if last
+// isn't canonical anymore.
+func missedHeaders(ctx context.Context, reader ChainReader, last *types.Header) ([]*types.Header, error) {
+	fork := last
+	for depth := 0; ; depth++ {
+		canonical, err := reader.CanonicalHeader(ctx, fork.Number.Uint64())
+		if err != nil {
+			return nil, err
+		}
+		if canonical != nil && canonical.Hash() == fork.Hash() {
+			break
+		}
+		if depth >= maxResyncBlocks || fork.Number.Sign() == 0 {
+			return nil, ErrSubscriptionGap
+		}
+		if fork, err = reader.Header(ctx, fork.ParentHash, fork.Number.Uint64()-1); err != nil {
+			return nil, err
+		}
+		if fork == nil {
+			return nil, ErrSubscriptionGap
+		}
+	}
+
+	head, err := reader.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from := fork.Number.Uint64() + 1
+	if head < from {
+		return nil, nil
+	}
+	if head-from >= maxResyncBlocks {
+		return nil, ErrSubscriptionGap
+	}
+	headers := make([]*types.Header, 0, head-from+1)
+	for blockNum := from; blockNum <= head; blockNum++ {
+		header, err := reader.CanonicalHeader(ctx, blockNum)
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			break
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+// catchUpLogs sends each logs subscription the logs of the blocks it may have missed while the logs stream was
+// closed, once it's reopened: from the block of the last log received from the backend, whose logs may have been
+// partly received, up to the head. The logs sent already are skipped, here and by the new stream. The subscriptions
+// are closed with ErrSubscriptionGap if the logs can't be read, or the blocks are too many.
+func (ff *Filters) catchUpLogs(ctx context.Context) {
+	reader := ff.loadChainReader()
+	from := ff.logsHead.Load()
+	if reader == nil || from == 0 {
+		return
+	}
+	head, err := reader.Head(ctx)
+	if err == nil && head >= from && head-from >= maxResyncBlocks {
+		err = ErrSubscriptionGap
+	}
+	if err != nil {
+		ff.logger.Warn("rpc filters: logs subscriptions can't catch up after resubscribing", "err", err)
+		ff.closeLogsSubs(ErrSubscriptionGap)
+		return
+	}
+	if head < from {
+		return
+	}
+
+	ff.logsSubs.logsFilterLock.RLock()
+	defer ff.logsSubs.logsFilterLock.RUnlock()
+	ff.logsSubs.logsFilters.Range(func(_ LogsSubID, f *LogsFilter) error { //nolint:errcheck
+		logs, err := reader.Logs(ctx, f.criteria(from, head))
+		if err != nil {
+			ff.logger.Warn("rpc filters: logs subscription can't catch up after resubscribing", "err", err)
+			f.sender.CloseWithError(ErrSubscriptionGap)
+			return nil
+		}
+		f.caughtUpTo = head
+		for _, lg := range logs {
+			f.send(lg)
+		}
+		return nil
+	})
+	ff.logsHead.Store(head)
+}
+
+// closeLogsSubs closes the logs subscriptions with err.
+func (ff *Filters) closeLogsSubs(err error) {
+	ff.logsSubs.logsFilters.Range(func(_ LogsSubID, f *LogsFilter) error { //nolint:errcheck
+		if f.sender != nil {
+			f.sender.CloseWithError(err)
+		}
+		return nil
+	})
+}
+
+// criteria returns the criteria of the subscription over the given blocks.
+func (l *LogsFilter) criteria(from, to uint64) filters.FilterCriteria {
+	crit := filters.FilterCriteria{FromBlock: new(big.Int).SetUint64(from), ToBlock: new(big.Int).SetUint64(to)}
+	if l.allAddrs == 0 {
+		l.addrs.Range(func(addr common.Address, _ int) error { //nolint:errcheck
+			crit.Addresses = append(crit.Addresses, addr)
+			return nil
+		})
+	}
+	if l.allTopics == 0 {
+		crit.Topics = l.topicsOriginal
+	}
+	return crit
+}
+
+// logPosition is the position of a log in the chain.
+type logPosition struct {
+	blockNum uint64
+	index    uint
+	set      bool
+}
+
+// before returns true if the position is before the given log, or unset.
+func (p logPosition) before(lg *types.Log) bool {
+	return !p.set || p.blockNum < lg.BlockNumber || (p.blockNum == lg.BlockNumber && p.index < lg.Index)
+}
@@ -17,27 +17,47 @@
 package rpchelper
 
 import (
+	"errors"
 	"sync"
+
+	"github.com/erigontech/erigon-lib/metrics"
 )
 
+// ErrSlowConsumer is the reason a subscription is closed under SlowConsumerDisconnect.
+var ErrSlowConsumer = errors.New("subscription closed: notifications are not consumed fast enough")
+
+// ErrFiltersClosed is the reason the subscriptions are closed by Filters.Close.
+var ErrFiltersClosed = errors.New("subscription closed: the node is shutting down")
+
 // a simple interface for subscriptions for rpc helper
 type Sub[T any] interface {
 	Send(T)
 	Close()
+	CloseWithError(err error) // closes the sub, reporting err as the reason
+	Err() error               // reason the sub was closed by its slow consumer policy or by CloseWithError, nil otherwise
 }
 
 type chan_sub[T any] struct {
 	lock   sync.Mutex // protects all fileds of this struct
 	ch     chan T
 	closed bool
+
+	policy  SlowConsumerPolicy
+	dropped metrics.Counter // counts notifications lost to a full buffer, may be nil
+	err     error
 }
 
 // newChanSub - buffered channel
 func newChanSub[T any](size int) *chan_sub[T] {
+	return newChanSubWithPolicy[T](size, SlowConsumerDropNewest, nil)
+}
+
+// newChanSubWithPolicy - buffered channel which applies policy when it is full
+func newChanSubWithPolicy[T any](size int, policy SlowConsumerPolicy, dropped metrics.Counter) *chan_sub[T] {
 	if size < 8 { // set min size to 8
 		size = 8
 	}
-	o := &chan_sub[T]{}
+	o := &chan_sub[T]{policy: policy, dropped: dropped}
 	o.ch = make(chan T, size)
 	return o
 }
@@ -49,7 +69,23 @@ func (s *chan_sub[T]) Send(x T) {
 	}
 	select {
 	case s.ch <- x:
-	default: // the sub is overloaded, dispose message
+		return
+	default: // the sub is overloaded
+	}
+	if s.dropped != nil {
+		s.dropped.Inc()
+	}
+	switch s.policy {
+	case SlowConsumerDropOldest:
+		select {
+		case <-s.ch:
+		default: // drained by the reader meanwhile
+		}
+		s.ch <- x // doesn't block: only Send writes to ch, under lock
+	case SlowConsumerDisconnect:
+		s.closed, s.err = true, ErrSlowConsumer
+		close(s.ch)
+	default: // dispose message
 	}
 }
 func (s *chan_sub[T]) Close() {
@@ -61,3 +97,17 @@ func (s *chan_sub[T]) Close() {
 	s.closed = true
 	close(s.ch)
 }
+func (s *chan_sub[T]) CloseWithError(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed, s.err = true, err
+	close(s.ch)
+}
+func (s *chan_sub[T]) Err() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
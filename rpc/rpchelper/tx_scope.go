@@ -0,0 +1,210 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpchelper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erigontech/erigon-lib/kv"
+)
+
+// TxReuse is the scope of the read transactions reused by the requests, see --rpc.txreuse.
+type TxReuse string
+
+const (
+	TxReuseOff        TxReuse = "off"        // each request begins its own transactions
+	TxReuseBatch      TxReuse = "batch"      // the items of a batch share a transaction
+	TxReuseConnection TxReuse = "connection" // the requests of a websocket or IPC connection share a transaction
+)
+
+// ParseTxReuse parses the value of --rpc.txreuse.
+func ParseTxReuse(s string) (TxReuse, error) {
+	switch r := TxReuse(s); r {
+	case TxReuseOff, TxReuseBatch, TxReuseConnection:
+		return r, nil
+	case "":
+		return TxReuseOff, nil
+	default:
+		return "", fmt.Errorf("invalid tx reuse %q, expected %s, %s or %s", s, TxReuseOff, TxReuseBatch, TxReuseConnection)
+	}
+}
+
+// TxScope begins a scope in which the requests read the database of a ScopedDB through the same read transaction,
+// to be registered by rpc.Server.SetRequestScope. The transaction is begun by the first request reading the
+// database, and rolled back by the returned function ending the scope.
+//
+// The requests of a scope observe the same state of the chain, the blocks committed meanwhile being invisible to
+// them, with two exceptions: the transaction is lent to one request at a time, as it can't be used concurrently,
+// so that a request beginning a transaction while it's lent, e.g. a nested or a concurrent one, begins its own; and
+// the transaction is refreshed once older than the maxAge of the ScopedDB, so that a long batch doesn't keep the
+// database from reclaiming its pages. For the same reason, a transaction which isn't lent is rolled back once older
+// than maxAge, e.g. the one of an idle connection.
+func TxScope(ctx context.Context) (context.Context, func()) {
+	s := &txScope{ctx: ctx}
+	return context.WithValue(ctx, txScopeKey{}, s), s.end
+}
+
+type txScopeKey struct{}
+
+// txScope is the read transaction shared by the requests of a scope.
+type txScope struct {
+	ctx context.Context // context the transaction is begun with, outliving the requests
+
+	mu     sync.Mutex
+	db     kv.TemporalRoDB // database of tx
+	tx     kv.TemporalTx   // nil until a request reads the database
+	begun  time.Time       // when tx was begun
+	maxAge time.Duration   // maxAge of the ScopedDB of db
+	lent   bool            // whether tx is used by a request
+	idle   *time.Timer     // rolls back tx once older than maxAge while it isn't lent
+	ended  bool
+}
+
+// lend returns the transaction of the scope if it isn't lent already, beginning it on db if needed. It returns nil
+// if the request should begin its own transaction.
+func (s *txScope) lend(db kv.TemporalRoDB, maxAge time.Duration) (kv.TemporalTx, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lent || s.ended || (s.db != nil && s.db != db) {
+		return nil, nil
+	}
+	s.stopIdle()
+	s.maxAge = maxAge
+	if s.tx != nil && s.tooOld() {
+		s.rollback()
+	}
+	if s.tx == nil {
+		tx, err := db.BeginTemporalRo(s.ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.db, s.tx, s.begun = db, tx, time.Now()
+	}
+	s.lent = true
+	return &lentTx{TemporalTx: s.tx, scope: s}, nil
+}
+
+// giveBack makes the transaction available to the next request, rolling it back if it's older than maxAge already,
+// or once it is if no request borrows it meanwhile.
+func (s *txScope) giveBack() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lent = false
+	if s.tx == nil || s.maxAge <= 0 {
+		return
+	}
+	if s.tooOld() {
+		s.rollback()
+		return
+	}
+	s.idle = time.AfterFunc(s.maxAge-time.Since(s.begun), s.expire)
+}
+
+// expire rolls back the transaction if it's still not lent once older than maxAge.
+func (s *txScope) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the timer may fire while lend stops it, the transaction is then lent or refreshed
+	if s.tx != nil && !s.lent && s.tooOld() {
+		s.rollback()
+	}
+}
+
+func (s *txScope) tooOld() bool {
+	return s.maxAge > 0 && time.Since(s.begun) >= s.maxAge
+}
+
+func (s *txScope) rollback() {
+	s.tx.Rollback()
+	s.tx = nil
+}
+
+func (s *txScope) stopIdle() {
+	if s.idle != nil {
+		s.idle.Stop()
+		s.idle = nil
+	}
+}
+
+func (s *txScope) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended = true
+	s.stopIdle()
+	if s.tx != nil {
+		s.rollback()
+	}
+}
+
+// lentTx is the transaction of a scope lent to a request, which gives it back on Rollback.
+type lentTx struct {
+	kv.TemporalTx
+	scope    *txScope
+	returned atomic.Bool
+}
+
+func (tx *lentTx) Rollback() {
+	if tx.returned.CompareAndSwap(false, true) {
+		tx.scope.giveBack()
+	}
+}
+
+// ScopedDB is a database lending the read transaction of the scope of the context, if any, see TxScope.
+type ScopedDB struct {
+	kv.TemporalRoDB
+	maxAge time.Duration // age of the transaction of a scope from which it's refreshed, never if 0
+}
+
+func NewScopedDB(db kv.TemporalRoDB, maxAge time.Duration) *ScopedDB {
+	return &ScopedDB{TemporalRoDB: db, maxAge: maxAge}
+}
+
+func (db *ScopedDB) BeginTemporalRo(ctx context.Context) (kv.TemporalTx, error) {
+	if s, ok := ctx.Value(txScopeKey{}).(*txScope); ok {
+		tx, err := s.lend(db.TemporalRoDB, db.maxAge)
+		if err != nil || tx != nil {
+			return tx, err
+		}
+	}
+	return db.TemporalRoDB.BeginTemporalRo(ctx)
+}
+
+func (db *ScopedDB) ViewTemporal(ctx context.Context, f func(tx kv.TemporalTx) error) error {
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return f(tx)
+}
+
+func (db *ScopedDB) BeginRo(ctx context.Context) (kv.Tx, error) {
+	return db.BeginTemporalRo(ctx)
+}
+
+func (db *ScopedDB) View(ctx context.Context, f func(tx kv.Tx) error) error {
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return f(tx)
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpchelper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+)
+
+func TestTxScopeIdle(t *testing.T) {
+	t.Parallel()
+	maxAge := 50 * time.Millisecond
+	db := NewScopedDB(temporaltest.NewTestDB(t, datadir.New(t.TempDir())), maxAge)
+	ctx, end := TxScope(context.Background())
+	defer end()
+	s := ctx.Value(txScopeKey{}).(*txScope)
+	begun := func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.tx != nil
+	}
+
+	// the transaction given back by a request is rolled back once older than maxAge, e.g. on an idle connection
+	tx, err := db.BeginTemporalRo(ctx)
+	require.NoError(t, err)
+	tx.Rollback()
+	require.True(t, begun())
+	require.Eventually(t, func() bool { return !begun() }, time.Second, 5*time.Millisecond)
+
+	// a lent transaction isn't rolled back under the request, but as soon as it's given back
+	tx, err = db.BeginTemporalRo(ctx)
+	require.NoError(t, err)
+	time.Sleep(2 * maxAge)
+	require.True(t, begun())
+	tx.Rollback()
+	require.False(t, begun())
+
+	// the next request begins a new transaction
+	tx, err = db.BeginTemporalRo(ctx)
+	require.NoError(t, err)
+	require.True(t, begun())
+	tx.Rollback()
+}
@@ -21,8 +21,8 @@ package rpc
 
 import (
 	"context"
-	"fmt"
 	"io"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -34,6 +34,16 @@ import (
 
 const MetadataApi = "rpc"
 
+const (
+	// drainPollInterval is how often Shutdown checks whether the subscriptions of a connection have ended.
+	drainPollInterval = 10 * time.Millisecond
+	// shutdownCloseTimeout bounds the time Shutdown waits for the close notifications to be written once its
+	// deadline has passed, before closing the connections anyway.
+	shutdownCloseTimeout = time.Second
+	// shutdownCloseReason is the reason of the websocket close frame sent by Shutdown.
+	shutdownCloseReason = "server shutting down"
+)
+
 // CodecOption specifies which type of messages a codec supports.
 //
 // Deprecated: this option is no longer honored by Server.
@@ -49,24 +59,24 @@ const (
 
 // Server is an RPC server.
 type Server struct {
-	services        serviceRegistry
-	methodAllowList AllowList
-	idgen           func() ID
-	run             int32
-	codecs          mapset.Set // mapset.Set[ServerCodec] requires go 1.20
+	services serviceRegistry
+	access   atomic.Pointer[AccessList]
+	idgen    func() ID
+	run      int32
+	codecs   mapset.Set // mapset.Set[ServerCodec] requires go 1.20
+	clients  sync.Map   // ServerCodec -> *Client serving it, drained by Shutdown
 
-	batchConcurrency    uint
+	batchLimits         *batchLimits
 	disableStreaming    bool
 	traceRequests       bool // Whether to print requests at INFO level
 	debugSingleRequest  bool // Whether to print requests at INFO level
-	batchLimit          int  // Maximum number of requests in a batch
 	logger              log.Logger
 	rpcSlowLogThreshold time.Duration
 }
 
 // NewServer creates a new server instance with no registered handlers.
 func NewServer(batchConcurrency uint, traceRequests, debugSingleRequest, disableStreaming bool, logger log.Logger, rpcSlowLogThreshold time.Duration) *Server {
-	server := &Server{services: serviceRegistry{logger: logger}, idgen: randomIDGenerator(), codecs: mapset.NewSet(), run: 1, batchConcurrency: batchConcurrency,
+	server := &Server{services: serviceRegistry{logger: logger}, idgen: randomIDGenerator(), codecs: mapset.NewSet(), run: 1, batchLimits: newBatchLimits(batchConcurrency),
 		disableStreaming: disableStreaming, traceRequests: traceRequests, debugSingleRequest: debugSingleRequest, logger: logger, rpcSlowLogThreshold: rpcSlowLogThreshold}
 	// Register the default service providing meta information about the RPC service such
 	// as the services and methods it offers.
@@ -77,12 +87,39 @@ func NewServer(batchConcurrency uint, traceRequests, debugSingleRequest, disable
 
 // SetAllowList sets the allow list for methods that are handled by this server
 func (s *Server) SetAllowList(allowList AllowList) {
-	s.methodAllowList = allowList
+	s.SetAccessList(&AccessList{Allow: allowList})
+}
+
+// SetAccessList sets the methods allowed and denied by this server. It can be called while serving: the calls which
+// follow, including those of the open connections, are handled under the new list.
+func (s *Server) SetAccessList(access *AccessList) {
+	s.access.Store(access)
 }
 
-// SetBatchLimit sets limit of number of requests in a batch
+// SetBatchLimit sets limit of number of requests in a batch, the requests beyond it are answered with an error
 func (s *Server) SetBatchLimit(limit int) {
-	s.batchLimit = limit
+	s.batchLimits.maxItems = limit
+}
+
+// SetBatchGasLimit sets limit of the cumulative gas of the call-type requests in a batch, counting callGas for the
+// ones not setting it. The requests beyond it are answered with an error.
+func (s *Server) SetBatchGasLimit(limit, callGas uint64) {
+	s.batchLimits.maxGas = limit
+	s.batchLimits.callGas = callGas
+}
+
+// SetRequestScope sets the scope shared by the items of each batch, or by the calls of each connection if
+// perConnection is set: a single HTTP request being a connection. The items of a batch are executed one after
+// another when a scope is set, so that they don't contend for what it shares.
+func (s *Server) SetRequestScope(scope RequestScope, perConnection bool) {
+	s.batchLimits.scope = scope
+	s.batchLimits.scopePerConn = perConnection
+}
+
+// SetMethodLabels sets whether the calls are labelled with their method in their context, so that what they do, like
+// reading the database, can be attributed to it, see MethodFromContext.
+func (s *Server) SetMethodLabels(on bool) {
+	s.batchLimits.labelMethods = on
 }
 
 // RegisterName creates a service for the given receiver type under the given name. When no
@@ -110,24 +147,36 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services, s.batchLimit, s.logger)
+	stats := newConnStats(codec.peerInfo())
+	connections.Store(stats, struct{}{})
+	defer connections.Delete(stats)
+
+	c := initClient(codec, s.idgen, &s.services, &s.access, s.batchLimits, stats, s.rpcSlowLogThreshold, s.logger)
+	s.clients.Store(codec, c)
+	defer s.clients.Delete(codec)
 	<-codec.closed()
 	c.Close()
 }
 
 // serveSingleRequest reads and processes a single RPC request from the given codec. This
 // is used to serve HTTP connections. Subscriptions and reverse calls are not allowed in
-// this mode.
-func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec, stream jsonstream.Stream) *jsonrpcMessage {
+// this mode. The response is streamed to out, or written to the codec if out is nil.
+func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec, out io.Writer) *jsonrpcMessage {
 	// Don't serve if server is stopped.
 	if atomic.LoadInt32(&s.run) == 0 {
 		return nil
 	}
 
-	h := newHandler(ctx, codec, s.idgen, &s.services, s.methodAllowList, s.batchConcurrency, s.traceRequests, s.logger, s.rpcSlowLogThreshold)
+	h := newHandler(ctx, codec, s.idgen, &s.services, &s.access, s.batchLimits, s.traceRequests, s.logger, s.rpcSlowLogThreshold)
 	h.allowSubscribe = false
 	defer h.close(io.EOF, nil)
 
+	var stream jsonstream.Stream
+	if out != nil {
+		h.streamed = &countingWriter{w: out}
+		stream = jsonstream.New(h.streamed)
+	}
+
 	reqs, batch, err := codec.ReadBatch()
 	if err != nil {
 		if err != io.EOF {
@@ -136,11 +185,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec, stre
 		return nil
 	}
 	if batch {
-		if s.batchLimit > 0 && len(reqs) > s.batchLimit {
-			return errorMessage(fmt.Errorf("batch limit %d exceeded (can increase by --rpc.batch.limit). Requested batch of size: %d", s.batchLimit, len(reqs)))
-		} else {
-			h.handleBatch(reqs)
-		}
+		h.handleBatch(reqs)
 	} else {
 		h.handleMsg(reqs[0], stream)
 	}
@@ -160,6 +205,55 @@ func (s *Server) Stop() {
 	}
 }
 
+// Shutdown stops the server gracefully. Like Stop it stops reading new requests, and it refuses new subscriptions.
+// The owners of the active subscriptions can end them until ctx is done, sending their pending notifications
+// first; the subscriptions still active then are closed with ErrServerShuttingDown as their last notification.
+// The connections are closed afterwards, with a websocket close frame telling the reason to websocket clients.
+func (s *Server) Shutdown(ctx context.Context) {
+	if !atomic.CompareAndSwapInt32(&s.run, 1, 0) {
+		return
+	}
+	s.logger.Info("RPC server shutting down gracefully")
+
+	var wg sync.WaitGroup
+	s.clients.Range(func(_, c any) bool {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			c.drain(ctx, ErrServerShuttingDown)
+		}(c.(*Client))
+		return true
+	})
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		// let the close notifications be written, unless the clients don't read them
+		select {
+		case <-drained:
+		case <-time.After(shutdownCloseTimeout):
+		}
+	}
+
+	s.codecs.Each(func(c interface{}) bool {
+		if rc, ok := c.(reasonCloser); ok {
+			rc.closeWithReason(shutdownCloseReason)
+		} else {
+			c.(ServerCodec).Close()
+		}
+		return true
+	})
+}
+
+// reasonCloser is implemented by the codecs which can tell the client why the connection is closed.
+type reasonCloser interface {
+	closeWithReason(reason string)
+}
+
 // RPCService gives meta information about the server.
 // e.g. gives information about the loaded modules.
 type RPCService struct {
@@ -212,3 +306,14 @@ func PeerInfoFromContext(ctx context.Context) PeerInfo {
 	info, _ := ctx.Value(peerInfoContextKey{}).(PeerInfo)
 	return info
 }
+
+type methodContextKey struct{}
+
+// MethodFromContext returns the method of the call, if the server labels the calls with it, see
+// Server.SetMethodLabels. Use this with the context passed to RPC method handler functions.
+//
+// The empty string is returned if no method is present in ctx.
+func MethodFromContext(ctx context.Context) string {
+	method, _ := ctx.Value(methodContextKey{}).(string)
+	return method
+}
@@ -39,6 +39,8 @@ var (
 	ErrNotificationsUnsupported = errors.New("notifications not supported")
 	// ErrNotificationNotFound is returned when the notification for the given id is not found
 	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrServerShuttingDown is sent as the last notification of the subscriptions closed by Server.Shutdown
+	ErrServerShuttingDown = errors.New("server is shutting down")
 )
 
 var globalGen = randomIDGenerator()
@@ -98,6 +100,8 @@ func ContextWithNotifier(ctx context.Context, n Notifier) context.Context {
 type Notifier interface {
 	CreateSubscription() *Subscription
 	Notify(id ID, data interface{}) error
+	// CloseWithError ends the subscription from the server side, sending err to the client as its last notification.
+	CloseWithError(id ID, err error) error
 	Closed() <-chan interface{}
 }
 
@@ -147,6 +151,21 @@ func (n *LocalNotifier) Notify(id ID, data interface{}) error {
 	}
 }
 
+func (n *LocalNotifier) CloseWithError(id ID, err error) error {
+	if n.sub == nil {
+		panic("can't close before subscription is created")
+	} else if n.sub.ID != id {
+		panic("close with wrong ID")
+	}
+
+	select {
+	case <-n.closec:
+		return errDead
+	case n.resc <- err:
+		return nil
+	}
+}
+
 func (n *LocalNotifier) Closed() <-chan interface{} {
 	return n.closec
 }
@@ -159,6 +178,7 @@ type RemoteNotifier struct {
 	mu           sync.Mutex
 	sub          *Subscription
 	buffer       []json.RawMessage
+	closeErr     error // set by CloseWithError before activation
 	callReturned bool
 	activated    bool
 }
@@ -176,7 +196,7 @@ func (n *RemoteNotifier) CreateSubscription() *Subscription {
 	} else if n.callReturned {
 		panic("can't create subscription after subscribe call has returned")
 	}
-	n.sub = &Subscription{ID: n.h.idgen(), namespace: n.namespace, err: make(chan error, 1)}
+	n.sub = &Subscription{ID: n.h.idgen(), namespace: n.namespace, err: make(chan error, 1), notifier: n}
 	return n.sub
 }
 
@@ -203,6 +223,29 @@ func (n *RemoteNotifier) Notify(id ID, data interface{}) error {
 	return nil
 }
 
+// CloseWithError sends err to the client as the last notification of the subscription and drops the
+// subscription, as if the client had unsubscribed. The client sees err as the subscription error.
+func (n *RemoteNotifier) CloseWithError(id ID, err error) error {
+	n.mu.Lock()
+	if n.sub == nil {
+		n.mu.Unlock()
+		panic("can't close before subscription is created")
+	} else if n.sub.ID != id {
+		n.mu.Unlock()
+		panic("close with wrong ID")
+	}
+	if !n.activated {
+		// activate sends it after the buffered notifications
+		n.closeErr = err
+		n.mu.Unlock()
+		return nil
+	}
+	sendErr := n.sendError(n.sub, err)
+	n.mu.Unlock()
+	n.h.unsubscribe(context.Background(), id) //nolint:errcheck
+	return sendErr
+}
+
 // Closed returns a channel that is closed when the RPC connection is closed.
 // Deprecated: use subscription error channel
 func (n *RemoteNotifier) Closed() <-chan interface{} {
@@ -223,15 +266,22 @@ func (n *RemoteNotifier) takeSubscription() *Subscription {
 // the subscription ID is sent to the client.
 func (n *RemoteNotifier) activate() error {
 	n.mu.Lock()
-	defer n.mu.Unlock()
-
 	for _, data := range n.buffer {
 		if err := n.send(n.sub, data); err != nil {
+			n.mu.Unlock()
 			return err
 		}
 	}
 	n.activated = true
-	return nil
+	closeErr := n.closeErr
+	if closeErr == nil {
+		n.mu.Unlock()
+		return nil
+	}
+	err := n.sendError(n.sub, closeErr)
+	n.mu.Unlock()
+	n.h.unsubscribe(context.Background(), n.sub.ID) //nolint:errcheck
+	return err
 }
 
 func (n *RemoteNotifier) send(sub *Subscription, data json.RawMessage) error {
@@ -244,12 +294,22 @@ func (n *RemoteNotifier) send(sub *Subscription, data json.RawMessage) error {
 	})
 }
 
+func (n *RemoteNotifier) sendError(sub *Subscription, err error) error {
+	params, _ := json.Marshal(&subscriptionResult{ID: string(sub.ID), Error: errorMessage(err).Error})
+	return n.h.conn.WriteJSON(context.Background(), &jsonrpcMessage{
+		Version: vsn,
+		Method:  n.namespace + notificationMethodSuffix,
+		Params:  params,
+	})
+}
+
 // A Subscription is created by a notifier and tied to that notifier. The client can use
 // this subscription to wait for an unsubscribe request for the client, see Err().
 type Subscription struct {
 	ID        ID
 	namespace string
-	err       chan error // closed on unsubscribe
+	err       chan error      // closed on unsubscribe
+	notifier  *RemoteNotifier // notifier of a subscription of an RPC connection, nil otherwise
 }
 
 // Err returns a channel that is closed when the client send an unsubscribe request.
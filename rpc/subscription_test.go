@@ -20,6 +20,7 @@
 package rpc
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -225,3 +226,28 @@ func readAndValidateMessage(in *json.Decoder) (*subConfirmation, *subscriptionRe
 		return nil, nil, fmt.Errorf("unrecognized message: %v", msg)
 	}
 }
+
+func TestServerCloseSubscription(t *testing.T) {
+	logger := log.New()
+	server := newTestServer(logger)
+	defer server.Stop()
+	client := DialInProc(server, logger)
+	defer client.Close()
+
+	nc := make(chan int, 8)
+	sub, err := client.Subscribe(context.Background(), "nftest", nc, "closedSubscription", 3)
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	select {
+	case err := <-sub.Err():
+		if err == nil || err.Error() != "too slow" {
+			t.Fatalf("got subscription error %v, want %q", err, "too slow")
+		}
+		if ec, ok := err.(Error); !ok || ec.ErrorCode() != defaultErrorCode {
+			t.Fatalf("got error %#v, want code %d", err, defaultErrorCode)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed by the server")
+	}
+}
@@ -20,8 +20,10 @@
 package rpc
 
 import (
+	"compress/flate"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -34,6 +36,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
 )
 
 const (
@@ -44,15 +47,33 @@ const (
 	wsMessageSizeLimit = 32 * 1024 * 1024
 )
 
-var wsBufferPool = new(sync.Pool)
+var (
+	wsBufferPool = new(sync.Pool)
+
+	wsCompressedBytes   = metrics.GetOrCreateCounter("rpc_ws_compressed_bytes")
+	wsUncompressedBytes = metrics.GetOrCreateCounter("rpc_ws_uncompressed_bytes")
+)
+
+// WebsocketCompression configures the permessage-deflate compression (RFC 7692) of the messages sent to the
+// clients offering it. The messages are compressed without context takeover, so that a connection keeps no
+// compression state between them.
+type WebsocketCompression struct {
+	Enabled bool
+	Level   int // flate compression level, from flate.HuffmanOnly to flate.BestCompression
+	MinSize int // messages smaller than this are sent uncompressed
+}
 
 // WebsocketHandler returns a handler that serves JSON-RPC to WebSocket connections.
 //
 // allowedOrigins should be a comma-separated list of allowed origin URLs.
 // To allow connections with any origin, pass "*".
-func (s *Server) WebsocketHandler(allowedOrigins []string, jwtSecret []byte, compression bool, logger log.Logger) http.Handler {
+func (s *Server) WebsocketHandler(allowedOrigins []string, jwtSecret []byte, compression WebsocketCompression, logger log.Logger) http.Handler {
+	if compression.Enabled && (compression.Level < flate.HuffmanOnly || compression.Level > flate.BestCompression) {
+		logger.Warn("Invalid WebSocket compression level, using the fastest", "level", compression.Level)
+		compression.Level = flate.BestSpeed
+	}
 	upgrader := websocket.Upgrader{
-		EnableCompression: compression,
+		EnableCompression: compression.Enabled,
 		ReadBufferSize:    wsReadBuffer,
 		WriteBufferSize:   wsWriteBuffer,
 		WriteBufferPool:   wsBufferPool,
@@ -67,11 +88,29 @@ func (s *Server) WebsocketHandler(allowedOrigins []string, jwtSecret []byte, com
 			logger.Warn("WebSocket upgrade failed", "err", err)
 			return
 		}
-		codec := NewWebsocketCodec(conn, r.Host, r.Header)
-		s.ServeCodec(codec, 0)
+		if compression.Enabled && wsCompressionOffered(r.Header) {
+			conn.SetCompressionLevel(compression.Level) //nolint:errcheck
+			s.ServeCodec(newWebsocketCodec(conn, r.Host, r.Header, compression.MinSize), 0)
+			return
+		}
+		s.ServeCodec(NewWebsocketCodec(conn, r.Host, r.Header), 0)
 	})
 }
 
+// wsCompressionOffered reports whether the client offered the permessage-deflate extension, which the upgrader
+// then accepts.
+func wsCompressionOffered(header http.Header) bool {
+	for _, extensions := range header.Values("Sec-Websocket-Extensions") {
+		for _, extension := range strings.Split(extensions, ",") {
+			name, _, _ := strings.Cut(extension, ";")
+			if strings.EqualFold(strings.TrimSpace(name), "permessage-deflate") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // wsHandshakeValidator returns a handler that verifies the origin during the
 // websocket upgrade process. When a '*' is specified as an allowed origins all
 // connections are accepted.
@@ -254,9 +293,14 @@ type websocketCodec struct {
 }
 
 func NewWebsocketCodec(conn *websocket.Conn, host string, req http.Header) ServerCodec {
+	return newWebsocketCodec(conn, host, req, -1)
+}
+
+// newWebsocketCodec creates a codec compressing the messages of at least compressMinSize bytes, or none if it's
+// negative. The connection must have negotiated the compression.
+func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, compressMinSize int) *websocketCodec {
 	conn.SetReadLimit(wsMessageSizeLimit)
 	wc := &websocketCodec{
-		jsonCodec: NewFuncCodec(conn, conn.WriteJSON, conn.ReadJSON).(*jsonCodec),
 		conn:      conn,
 		pingReset: make(chan struct{}, 1),
 		info: PeerInfo{
@@ -264,6 +308,11 @@ func NewWebsocketCodec(conn *websocket.Conn, host string, req http.Header) Serve
 			RemoteAddr: conn.RemoteAddr().String(),
 		},
 	}
+	encode := conn.WriteJSON
+	if compressMinSize >= 0 {
+		encode = func(v interface{}) error { return wc.writeCompressed(v, compressMinSize) }
+	}
+	wc.jsonCodec = NewFuncCodec(conn, encode, conn.ReadJSON).(*jsonCodec)
 	// Fill in connection details.
 	wc.info.HTTP.Host = host
 	if req != nil {
@@ -276,11 +325,37 @@ func NewWebsocketCodec(conn *websocket.Conn, host string, req http.Header) Serve
 	return wc
 }
 
+// writeCompressed sends v as a text message, compressed if it has at least minSize bytes.
+func (wc *websocketCodec) writeCompressed(v interface{}, minSize int) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	compress := len(data) >= minSize
+	wc.conn.EnableWriteCompression(compress)
+	if err := wc.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return err
+	}
+	if compress {
+		wsCompressedBytes.AddInt(len(data))
+	} else {
+		wsUncompressedBytes.AddInt(len(data))
+	}
+	return nil
+}
+
 func (wc *websocketCodec) Close() {
 	wc.jsonCodec.Close()
 	wc.wg.Wait()
 }
 
+// closeWithReason sends a close frame with the given reason before closing the connection.
+func (wc *websocketCodec) closeWithReason(reason string) {
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, reason)
+	wc.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsPingWriteTimeout)) //nolint:errcheck
+	wc.Close()
+}
+
 func (wc *websocketCodec) peerInfo() PeerInfo {
 	return wc.info
 }
@@ -31,7 +31,7 @@ func BenchmarkWebsocketEmptyCall(b *testing.B) {
 
 	var (
 		srv     = newTestServer(logger)
-		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, false, logger))
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, WebsocketCompression{}, logger))
 		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
 	)
 	defer srv.Stop()
@@ -55,7 +55,7 @@ func BenchmarkWebsocket16kb(b *testing.B) {
 
 	var (
 		srv     = newTestServer(logger)
-		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, false, logger))
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, WebsocketCompression{}, logger))
 		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
 	)
 	defer srv.Stop()
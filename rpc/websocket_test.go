@@ -20,12 +20,15 @@
 package rpc
 
 import (
+	"compress/flate"
 	"context"
+	"encoding/json"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -59,7 +62,7 @@ func TestWebsocketOriginCheck(t *testing.T) {
 	logger := log.New()
 	var (
 		srv     = newTestServer(logger)
-		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"http://example.com"}, nil, false, logger))
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"http://example.com"}, nil, WebsocketCompression{}, logger))
 		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
 	)
 	defer srv.Stop()
@@ -94,7 +97,7 @@ func TestWebsocketLargeCall(t *testing.T) {
 
 	var (
 		srv     = newTestServer(logger)
-		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, false, logger))
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, WebsocketCompression{}, logger))
 		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
 	)
 	defer srv.Stop()
@@ -185,7 +188,7 @@ func TestClientWebsocketLargeMessage(t *testing.T) {
 	logger := log.New()
 	var (
 		srv     = NewServer(50, false /* traceRequests */, false /* debugSingleRequests */, true, logger, 100)
-		httpsrv = httptest.NewServer(srv.WebsocketHandler(nil, nil, false, logger))
+		httpsrv = httptest.NewServer(srv.WebsocketHandler(nil, nil, WebsocketCompression{}, logger))
 		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
 	)
 	defer srv.Stop()
@@ -312,3 +315,173 @@ func wsPingTestHandler(t *testing.T, conn *websocket.Conn, shutdown, sendPing <-
 		}
 	}
 }
+
+// countingConn counts the bytes read from a connection.
+type countingConn struct {
+	net.Conn
+	read atomic.Int64
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+// This test checks that the responses reaching the minimum size are compressed for clients offering it.
+func TestWebsocketCompression(t *testing.T) {
+	t.Parallel()
+
+	logger := log.New()
+	var (
+		srv         = newTestServer(logger)
+		compression = WebsocketCompression{Enabled: true, Level: flate.BestSpeed, MinSize: 1024}
+		httpsrv     = httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, compression, logger))
+		wsURL       = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	)
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	dial := func(t *testing.T, compress bool) (*websocket.Conn, *countingConn) {
+		t.Helper()
+		var counted *countingConn
+		dialer := websocket.Dialer{
+			EnableCompression: compress,
+			NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+				if err != nil {
+					return nil, err
+				}
+				counted = &countingConn{Conn: conn}
+				return counted, nil
+			},
+		}
+		conn, resp, err := dialer.Dial(wsURL, nil)
+		if err != nil {
+			t.Fatalf("can't dial: %v", err)
+		}
+		resp.Body.Close()
+		t.Cleanup(func() { conn.Close() })
+		if negotiated := strings.Contains(resp.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate"); negotiated != compress {
+			t.Fatalf("compression negotiated: %t, offered: %t", negotiated, compress)
+		}
+		return conn, counted
+	}
+	// echo sends a call echoing arg and returns the response with the bytes it took on the wire.
+	echo := func(t *testing.T, conn *websocket.Conn, counted *countingConn, arg string) ([]byte, int64) {
+		t.Helper()
+		request := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["` + arg + `",1]}`
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(request)); err != nil {
+			t.Fatal(err)
+		}
+		before := counted.read.Load()
+		_, response, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return response, counted.read.Load() - before
+	}
+	echoed := func(t *testing.T, response []byte) string {
+		t.Helper()
+		var msg struct{ Result echoResult }
+		if err := json.Unmarshal(response, &msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg.Result.String
+	}
+
+	t.Run("compressed", func(t *testing.T) {
+		conn, counted := dial(t, true)
+
+		large := strings.Repeat("x", 64*1024)
+		response, wire := echo(t, conn, counted, large)
+		if echoed(t, response) != large {
+			t.Fatalf("wrong response: %.100s", response)
+		}
+		if wire > int64(len(response))/10 {
+			t.Fatalf("response of %d bytes took %d bytes", len(response), wire)
+		}
+
+		small := "small"
+		response, wire = echo(t, conn, counted, small)
+		if echoed(t, response) != small {
+			t.Fatalf("wrong response: %s", response)
+		}
+		if wire < int64(len(response)) {
+			t.Fatalf("response of %d bytes under the threshold took %d bytes", len(response), wire)
+		}
+	})
+	t.Run("not offered", func(t *testing.T) {
+		conn, counted := dial(t, false)
+
+		large := strings.Repeat("x", 64*1024)
+		response, wire := echo(t, conn, counted, large)
+		if echoed(t, response) != large {
+			t.Fatalf("wrong response: %.100s", response)
+		}
+		if wire < int64(len(response)) {
+			t.Fatalf("uncompressed response of %d bytes took %d bytes", len(response), wire)
+		}
+	})
+}
+
+func TestWebsocketShutdownClosesSubscriptions(t *testing.T) {
+	t.Parallel()
+	logger := log.New()
+
+	var (
+		srv     = newTestServer(logger)
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, WebsocketCompression{}, logger))
+		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	)
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second)) //nolint:errcheck
+
+	req := `{"jsonrpc":"2.0","id":1,"method":"nftest_subscribe","params":["someSubscription",2,10]}`
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(req)); err != nil {
+		t.Fatalf("can't subscribe: %v", err)
+	}
+	// the subscription id, then the two notifications
+	for i := 0; i < 3; i++ {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			t.Fatalf("can't read message %d: %v", i, err)
+		}
+	}
+
+	// the subscription isn't ended by its owner, so it is closed once the deadline passes
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	go srv.Shutdown(ctx)
+
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("connection dropped before the close notification: %v", err)
+	}
+	var notification struct {
+		Method string `json:"method"`
+		Params struct {
+			Error *jsonError `json:"error"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(data, &notification); err != nil {
+		t.Fatalf("invalid notification %s: %v", data, err)
+	}
+	if notification.Method != "nftest_subscription" || notification.Params.Error == nil || notification.Params.Error.Message != ErrServerShuttingDown.Error() {
+		t.Fatalf("got %s, want the close notification of the subscription", data)
+	}
+
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("got %v, want a close frame", err)
+	}
+	if closeErr := err.(*websocket.CloseError); closeErr.Text != shutdownCloseReason {
+		t.Fatalf("got close reason %q, want %q", closeErr.Text, shutdownCloseReason)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/temporal"
+	"github.com/erigontech/erigon/cmd/hack/tool/fromdb"
+	"github.com/erigontech/erigon/cmd/utils"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/turbo/debug"
+)
+
+var exportGenesisAllocFlag = cli.BoolFlag{
+	Name:  "alloc.fromstate",
+	Usage: "Reconstruct the genesis alloc by iterating the genesis-time state instead of using the stored genesis spec",
+}
+
+var exportGenesisCommand = cli.Command{
+	Action:    MigrateFlags(exportGenesis),
+	Name:      "export-genesis",
+	Usage:     "Export the genesis spec the datadir was initialized with as JSON",
+	ArgsUsage: "[<outputPath>]",
+	Flags: []cli.Flag{
+		&utils.DataDirFlag,
+		&exportGenesisAllocFlag,
+	},
+	Description: `
+The export-genesis command reconstructs the genesis spec (config, block 0 header
+fields and alloc) from an existing datadir and writes it to the given file,
+or to stdout if no path is given. The output can be fed back to "erigon init".`,
+}
+
+func exportGenesis(cliCtx *cli.Context) error {
+	logger, _, _, _, err := debug.Setup(cliCtx, true /* rootLogger */)
+	if err != nil {
+		return err
+	}
+	ctx := cliCtx.Context
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+	chainDB := dbCfg(kv.ChainDB, dirs.Chaindata).MustOpen()
+	defer chainDB.Close()
+
+	cfg := ethconfig.NewSnapCfg(false, true, true, fromdb.ChainConfig(chainDB).ChainName)
+	_, _, _, blockRetire, agg, clean, err := openSnaps(ctx, cfg, dirs, chainDB, logger)
+	if err != nil {
+		return err
+	}
+	defer clean()
+	blockReader, _ := blockRetire.IO()
+
+	db, err := temporal.New(chainDB, agg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTemporalRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	genesis, err := core.ReconstructGenesis(tx, blockReader)
+	if err != nil {
+		return err
+	}
+	if cliCtx.Bool(exportGenesisAllocFlag.Name) {
+		if genesis.Alloc, err = core.ReadGenesisAlloc(tx, blockReader.TxnumReader(ctx)); err != nil {
+			return err
+		}
+	}
+
+	out := os.Stdout
+	if path := cliCtx.Args().First(); path != "" {
+		if out, err = os.Create(path); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(genesis); err != nil {
+		return err
+	}
+	if out != os.Stdout {
+		logger.Info("Exported genesis", "chain", genesis.Config.ChainName, "accounts", len(genesis.Alloc), "path", out.Name())
+	}
+	return nil
+}
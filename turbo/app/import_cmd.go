@@ -44,6 +44,7 @@ import (
 	"github.com/erigontech/erigon/execution/consensus/merge"
 	"github.com/erigontech/erigon/execution/eth1/eth1_chain_reader"
 	"github.com/erigontech/erigon/execution/stages"
+	"github.com/erigontech/erigon/execution/stages/blockimport"
 	"github.com/erigontech/erigon/turbo/debug"
 	turboNode "github.com/erigontech/erigon/turbo/node"
 	"github.com/erigontech/erigon/turbo/services"
@@ -53,6 +54,11 @@ const (
 	importBatchSize = 2500
 )
 
+var importDownloadersFlag = cli.BoolFlag{
+	Name:  "import.downloaders",
+	Usage: "Import the blocks through the header and body downloaders, validated as the blocks of the peers are, resuming after the blocks imported already. The .era1 files always are",
+}
+
 var importCommand = cli.Command{
 	Action:    MigrateFlags(importChain),
 	Name:      "import",
@@ -61,6 +67,7 @@ var importCommand = cli.Command{
 	Flags: []cli.Flag{
 		&utils.DataDirFlag,
 		&utils.ChainFlag,
+		&importDownloadersFlag,
 	},
 	//Category: "BLOCKCHAIN COMMANDS",
 	Description: `
@@ -68,7 +75,12 @@ The import command imports blocks from an RLP-encoded form. The form can be one
 with several RLP-encoded blocks, or several files can be used.
 
 If only one file is used, import error will result in failure. If several files are used,
-processing will proceed even if an individual RLP-file import failure occurs.`,
+processing will proceed even if an individual RLP-file import failure occurs.
+
+With --import.downloaders, or for era1 files, the blocks of all the files are imported
+through the header and body downloaders, as if they came from a peer. Only the
+proof-of-work blocks can be imported this way. An interrupted import resumes after
+the blocks imported already.`,
 }
 
 func importChain(cliCtx *cli.Context) error {
@@ -98,6 +110,21 @@ func importChain(cliCtx *cli.Context) error {
 		return err
 	}
 
+	throughDownloaders := cliCtx.Bool(importDownloadersFlag.Name)
+	for _, fn := range cliCtx.Args().Slice() {
+		throughDownloaders = throughDownloaders || isEra1(fn)
+	}
+	if throughDownloaders {
+		ctx, cancel := signal.NotifyContext(cliCtx.Context, syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		for _, fn := range cliCtx.Args().Slice() {
+			if err := ImportThroughDownloaders(ctx, ethereum, fn, logger); err != nil {
+				return fmt.Errorf("%s: %w", fn, err)
+			}
+		}
+		return nil
+	}
+
 	if err := ImportChain(ethereum, ethereum.ChainDB(), cliCtx.Args().First(), logger); err != nil {
 		return err
 	}
@@ -105,6 +132,47 @@ func importChain(cliCtx *cli.Context) error {
 	return nil
 }
 
+func isEra1(fn string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(fn, ".gz"), ".era1")
+}
+
+// ImportThroughDownloaders imports the blocks of an era1 or export file through the header and body downloaders, see
+// blockimport.Import.
+func ImportThroughDownloaders(ctx context.Context, ethereum *eth.Ethereum, fn string, logger log.Logger) error {
+	logger.Info("Importing blockchain through the downloaders", "file", fn)
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = fh
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+	}
+	source := blockimport.NewRLPSource(reader)
+	if isEra1(fn) {
+		source = blockimport.NewEra1Source(reader)
+	}
+
+	sentryControlServer := ethereum.SentryControlServer()
+	blockReader, _ := ethereum.BlockIO()
+	target := blockimport.Target{
+		DB:          ethereum.ChainDB(),
+		BlockReader: blockReader,
+		Hd:          sentryControlServer.Hd,
+		Bd:          sentryControlServer.Bd,
+		Cycle: func(ctx context.Context) error {
+			hook := stages.NewHook(ctx, ethereum.ChainDB(), ethereum.Notifications(), ethereum.StagedSync(), blockReader, ethereum.ChainConfig(), logger, sentryControlServer.SetStatus)
+			return stages.StageLoopIteration(ctx, ethereum.ChainDB(), wrap.NewTxContainer(nil, nil), ethereum.StagedSync(), false /* initialCycle */, false /* firstCycle */, logger, blockReader, hook)
+		},
+	}
+	_, err = blockimport.Import(ctx, target, source, logger)
+	return err
+}
+
 func ImportChain(ethereum *eth.Ethereum, chainDB kv.RwDB, fn string, logger log.Logger) error {
 	// Watch for Ctrl-C while the import is running.
 	// If a signal is received, the import will stop at the next batch.
@@ -66,14 +66,22 @@ func initGenesis(cliCtx *cli.Context) error {
 		utils.Fatalf("Must supply path to genesis JSON file")
 	}
 
-	file, err := os.Open(genesisPath)
+	data, err := os.ReadFile(genesisPath)
 	if err != nil {
 		utils.Fatalf("Failed to read genesis file: %v", err)
 	}
-	defer file.Close()
+	if problems := core.ValidateGenesisJSON(data); len(problems) > 0 {
+		for _, p := range problems {
+			logger.Error("Invalid genesis", "field", p.Field, "problem", p.Msg)
+		}
+		utils.Fatalf("invalid genesis file: %d problem(s) found", len(problems))
+	}
 
 	genesis := new(types.Genesis)
-	if err := json.NewDecoder(file).Decode(genesis); err != nil {
+	if err := json.Unmarshal(data, genesis); err != nil {
+		utils.Fatalf("invalid genesis file: %v", err)
+	}
+	if err := types.ResolveAllocFile(genesis, genesisPath); err != nil {
 		utils.Fatalf("invalid genesis file: %v", err)
 	}
 
@@ -79,6 +79,7 @@ func MakeApp(name string, action cli.ActionFunc, cliFlags []cli.Flag) *cli.App {
 	}
 	app.Commands = []*cli.Command{
 		&initCommand,
+		&exportGenesisCommand,
 		&importCommand,
 		&snapshotCommand,
 		&supportCommand,
@@ -81,6 +81,7 @@ import (
 	"github.com/erigontech/erigon/turbo/debug"
 	"github.com/erigontech/erigon/turbo/logging"
 	"github.com/erigontech/erigon/turbo/node"
+	"github.com/erigontech/erigon/turbo/snapshotsync"
 	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
 )
 
@@ -352,6 +353,27 @@ var snapshotCommand = cli.Command{
 				&cli.Uint64Flag{Name: "fromStep", Value: 0, Usage: "skip files before given step"},
 			}),
 		},
+		{
+			Name: "verify-segments",
+			Action: func(cliCtx *cli.Context) error {
+				_, l, err := datadir.New(cliCtx.String(utils.DataDirFlag.Name)).MustFlock()
+				if err != nil {
+					return err
+				}
+				defer l.Unlock()
+				if err := doVerifySegments(cliCtx); err != nil {
+					log.Error("[verify-segments]", "err", err)
+					return err
+				}
+				log.Info("[verify-segments] segments are complete")
+				return nil
+			},
+			Description: "verify the size of the block segments against their .torrent, and that all their words are readable. The segments failing verification are moved to the quarantine directory, for the downloader to fetch them again",
+			Flags: joinFlags([]cli.Flag{
+				&utils.DataDirFlag,
+				&cli.BoolFlag{Name: "failFast", Value: false, Usage: "to stop after 1st problem or print WARN log and continue check"},
+			}),
+		},
 		{
 			Name: "publishable",
 			Action: func(cliCtx *cli.Context) error {
@@ -372,6 +394,17 @@ var snapshotCommand = cli.Command{
 				&utils.DataDirFlag,
 			}),
 		},
+		{
+			Name: "bor-events-stats",
+			Action: func(cliCtx *cli.Context) error {
+				dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+				return doBorEventsStats(cliCtx, dirs)
+			},
+			Description: "Report the entries and bytes of the bor event tables of the bridge db, and how many of them are of frozen events, so prunable",
+			Flags: joinFlags([]cli.Flag{
+				&utils.DataDirFlag,
+			}),
+		},
 		{
 			Name:        "clearIndexing",
 			Action:      doClearIndexing,
@@ -1172,6 +1205,44 @@ func doBlockSnapshotsRangeCheck(snapDir string, suffix string, snapType string)
 
 }
 
+func doVerifySegments(cliCtx *cli.Context) error {
+	logger, _, _, _, err := debug.Setup(cliCtx, true /* root logger */)
+	if err != nil {
+		return err
+	}
+	ctx := cliCtx.Context
+	failFast := cliCtx.Bool("failFast")
+	dirs := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
+
+	// the segments are verified from the files in the directory, none of them is opened
+	cfg := ethconfig.BlocksFreezing{}
+	var failed int
+	for _, snaps := range []*snapshotsync.RoSnapshots{
+		&freezeblocks.NewRoSnapshots(cfg, dirs.Snap, 0, logger).RoSnapshots,
+		&heimdall.NewRoSnapshots(cfg, dirs.Snap, 0, logger).RoSnapshots,
+	} {
+		statuses, err := snaps.VerifySegments(ctx, failFast)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			if status.OK() {
+				logger.Debug("[verify-segments] ok", "file", status.FileName, "size", common.ByteCount(uint64(status.Size)))
+				continue
+			}
+			failed++
+			logger.Warn("[verify-segments] failed", "file", status.FileName, "size", status.Size, "expected", status.ExpectedSize, "quarantined", status.Quarantined, "err", status.Err)
+		}
+		if failed > 0 && failFast {
+			break
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d segments failed verification", failed)
+	}
+	return nil
+}
+
 func doPublishable(cliCtx *cli.Context) error {
 	dat := datadir.New(cliCtx.String(utils.DataDirFlag.Name))
 	// Check block snapshots sanity
@@ -1454,6 +1525,46 @@ func doLS(cliCtx *cli.Context, dirs datadir.Dirs) error {
 	return nil
 }
 
+func doBorEventsStats(cliCtx *cli.Context, dirs datadir.Dirs) error {
+	logger, _, _, _, err := debug.Setup(cliCtx, true /* rootLogger */)
+	if err != nil {
+		return err
+	}
+	ctx := cliCtx.Context
+
+	chainDB := dbCfg(kv.ChainDB, dirs.Chaindata).MustOpen()
+	defer chainDB.Close()
+	chainConfig := fromdb.ChainConfig(chainDB)
+	if chainConfig.Bor == nil {
+		return fmt.Errorf("%s isn't a bor chain", chainConfig.ChainName)
+	}
+
+	borSnaps := heimdall.NewRoSnapshots(ethconfig.NewSnapCfg(false, true, true, chainConfig.ChainName), dirs.Snap, 0, logger)
+	defer borSnaps.Close()
+	if err := borSnaps.OpenFolder(); err != nil {
+		return err
+	}
+	borSnaps.DownloadComplete() // mark as ready
+
+	bridgeStore := bridge.NewSnapshotStore(bridge.NewMdbxStore(dirs.DataDir, logger, true, 0), borSnaps, chainConfig.Bor)
+	defer bridgeStore.Close()
+	if err := bridgeStore.Prepare(ctx); err != nil {
+		return err
+	}
+
+	stats, err := bridgeStore.EventTablesStats(ctx)
+	if err != nil {
+		return err
+	}
+	logger.Info("[bor-events-stats] frozen", "lastEventId", stats.LastFrozenEventId, "lastEventBlockNum", stats.LastFrozenEventBlockNum)
+	for _, table := range stats.Tables {
+		logger.Info("[bor-events-stats] "+table.Table, "entries", table.Count, "size", common.ByteCount(table.Bytes),
+			"prunableEntries", table.PrunableCount, "prunableSize", common.ByteCount(table.PrunableBytes),
+			"prunable", fmt.Sprintf("%.1f%%", 100*table.PrunableFraction()))
+	}
+	return nil
+}
+
 func openSnaps(ctx context.Context, cfg ethconfig.BlocksFreezing, dirs datadir.Dirs, chainDB kv.RwDB, logger log.Logger) (
 	blockSnaps *freezeblocks.RoSnapshots,
 	borSnaps *heimdall.RoSnapshots,
@@ -46,9 +46,12 @@ var DefaultFlags = []cli.Flag{
 
 	&BatchSizeFlag,
 	&BodyCacheLimitFlag,
+	&HeadersBacklogLimitFlag,
 	&DatabaseVerbosityFlag,
 	&PrivateApiAddr,
 	&PrivateApiRateLimit,
+	&PrivateApiSubscribeQueueSize,
+	&PrivateApiSlowSubscriberTimeout,
 	&EtlBufferSizeFlag,
 	&TLSFlag,
 	&TLSCertFlag,
@@ -74,6 +77,8 @@ var DefaultFlags = []cli.Flag{
 	&utils.WSPortFlag,
 	&utils.WSEnabledFlag,
 	&utils.WsCompressionFlag,
+	&utils.WsCompressionLevelFlag,
+	&utils.WsCompressionMinSizeFlag,
 	&utils.HTTPTraceFlag,
 	&utils.HTTPDebugSingleFlag,
 	&utils.StateCacheFlag,
@@ -83,12 +88,17 @@ var DefaultFlags = []cli.Flag{
 	&utils.RpcAccessListFlag,
 	&utils.RpcTraceCompatFlag,
 	&utils.RpcGasCapFlag,
+	&utils.RpcTxReuseFlag,
+	&utils.RpcTxReuseMaxAgeFlag,
+	&utils.RpcKvStatsFlag,
 	&utils.RpcBatchLimit,
+	&utils.RpcBatchGasLimit,
 	&utils.RpcReturnDataLimit,
 	&utils.AllowUnprotectedTxs,
 	&utils.RPCGlobalTxFeeCapFlag,
 	&utils.TxpoolApiAddrFlag,
 	&utils.TraceMaxtracesFlag,
+	&utils.TraceFilterMaxBytesFlag,
 
 	&HTTPReadTimeoutFlag,
 	&HTTPWriteTimeoutFlag,
@@ -105,6 +115,15 @@ var DefaultFlags = []cli.Flag{
 	&RpcSubscriptionFiltersMaxTxsFlag,
 	&RpcSubscriptionFiltersMaxAddressesFlag,
 	&RpcSubscriptionFiltersMaxTopicsFlag,
+	&RpcSubscriptionHeadsBufferSizeFlag,
+	&RpcSubscriptionLogsBufferSizeFlag,
+	&RpcSubscriptionTxsBufferSizeFlag,
+	&RpcSubscriptionSlowConsumerFlag,
+	&RpcSubscriptionLogsRateLimitFlag,
+	&RpcSubscriptionLogsMaxPerBlockFlag,
+	&RpcSubscriptionLogsGlobalRateLimitFlag,
+	&RpcFiltersTTLFlag,
+	&RpcFiltersMaxPerClientFlag,
 
 	&utils.SnapKeepBlocksFlag,
 	&utils.SnapStopFlag,
@@ -177,6 +196,7 @@ var DefaultFlags = []cli.Flag{
 	&utils.AAFlag,
 	&utils.EthStatsURLFlag,
 	&utils.OverrideOsakaFlag,
+	&utils.OverrideChainConfigFlag,
 
 	&utils.CaplinDiscoveryAddrFlag,
 	&utils.CaplinDiscoveryPortFlag,
@@ -238,7 +258,23 @@ var DefaultFlags = []cli.Flag{
 	&utils.TxPoolGossipDisableFlag,
 	&SyncLoopBlockLimitFlag,
 	&SyncLoopBreakAfterFlag,
+	&SyncLoopTimeBudgetFlag,
 	&SyncParallelStateFlushing,
+	&P2PReceiptsCacheBlocksFlag,
+	&P2PReceiptsCacheTTLFlag,
+	&RPCReceiptsCacheBlocksFlag,
+	&RPCReceiptsCacheTTLFlag,
+	&PersistentReceiptsCacheFlag,
+	&PersistentReceiptsCacheBlocksFlag,
+	&PersistentReceiptsCacheDepthFlag,
+	&PeerReputationFlag,
+	&PeerReputationEnforceFlag,
+	&PeerReputationThresholdFlag,
+	&PeerReputationHorizonFlag,
+	&RequestTracesFlag,
+	&MessageRecordingDirFlag,
+	&MessageRecordingFileSizeFlag,
+	&MessageRecordingFilesFlag,
 
 	&utils.ChaosMonkeyFlag,
 
@@ -248,6 +284,9 @@ var DefaultFlags = []cli.Flag{
 
 	&utils.PolygonPosSingleSlotFinalityFlag,
 	&utils.PolygonPosSingleSlotFinalityBlockAtFlag,
+	&utils.PolygonBridgeHealEventsGapFlag,
+	&utils.PolygonBridgeEventsRetentionBlocksFlag,
+	&utils.PolygonBridgeEventsRetentionDurationFlag,
 	&utils.GDBMeFlag,
 
 	&utils.ExperimentalConcurrentCommitmentFlag,
@@ -39,6 +39,7 @@ import (
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/rpccfg"
 	"github.com/erigontech/erigon/rpc/rpchelper"
+	"github.com/erigontech/erigon/turbo/privateapi"
 )
 
 var (
@@ -62,6 +63,11 @@ var (
 		Usage: "Limit on the cache for block bodies",
 		Value: fmt.Sprintf("%d", ethconfig.Defaults.Sync.BodyCacheLimit),
 	}
+	HeadersBacklogLimitFlag = cli.StringFlag{
+		Name:  "headers.backlog",
+		Usage: "Size of the header batches received and not processed yet from which the PoS header download pauses its requests, until they drain (0 = never)",
+		Value: fmt.Sprintf("%d", ethconfig.Defaults.Sync.PosHeadersBacklogLimit),
+	}
 
 	PrivateApiAddr = cli.StringFlag{
 		Name:  "private.api.addr",
@@ -75,6 +81,18 @@ var (
 		Value: kv.ReadersLimit - 128,
 	}
 
+	PrivateApiSubscribeQueueSize = cli.IntFlag{
+		Name:  "private.api.subscribe.queue",
+		Usage: "Amount of events queued for each subscriber of the private api event streams (new headers, new blocks)",
+		Value: privateapi.DefaultSubscribeConfig.QueueSize,
+	}
+
+	PrivateApiSlowSubscriberTimeout = cli.DurationFlag{
+		Name:  "private.api.subscribe.timeout",
+		Usage: "A subscriber of the private api event streams whose queue stays full for this long is disconnected, so it can't hold up the node",
+		Value: privateapi.DefaultSubscribeConfig.SlowTimeout,
+	}
+
 	PruneModeFlag = cli.StringFlag{
 		Name: "prune.mode",
 		Usage: `Choose a pruning preset to run onto. Available values: "full", "archive", "minimal", "blocks".
@@ -136,12 +154,93 @@ var (
 		Value: 5_000,
 	}
 
+	SyncLoopTimeBudgetFlag = cli.DurationFlag{
+		Name:  "sync.loop.time.budget",
+		Usage: "Sets the time after which the execution of a sync loop iteration commits its progress and yields, to resume in the next iteration (e.g. 10m, default is none)",
+		Value: 0,
+	}
+
 	SyncParallelStateFlushing = cli.BoolFlag{
 		Name:  "sync.parallel-state-flushing",
 		Usage: "Enables parallel state flushing",
 		Value: true,
 	}
 
+	P2PReceiptsCacheBlocksFlag = cli.IntFlag{
+		Name:  "p2p.receipts.cache.blocks",
+		Usage: "Number of blocks whose generated receipts are cached for serving them to peers (0 = default)",
+		Value: ethconfig.Defaults.Sync.P2PReceiptsCache.Blocks,
+	}
+	P2PReceiptsCacheTTLFlag = cli.DurationFlag{
+		Name:  "p2p.receipts.cache.ttl",
+		Usage: "How long generated receipts served to peers stay cached (0 = until evicted)",
+		Value: ethconfig.Defaults.Sync.P2PReceiptsCache.TTL,
+	}
+	RPCReceiptsCacheBlocksFlag = cli.IntFlag{
+		Name:  "rpc.receipts.cache.blocks",
+		Usage: "Number of blocks whose generated receipts are cached by the RPC daemon (0 = default)",
+		Value: ethconfig.Defaults.Sync.RPCReceiptsCache.Blocks,
+	}
+	RPCReceiptsCacheTTLFlag = cli.DurationFlag{
+		Name:  "rpc.receipts.cache.ttl",
+		Usage: "How long generated receipts stay cached by the RPC daemon (0 = until evicted)",
+		Value: ethconfig.Defaults.Sync.RPCReceiptsCache.TTL,
+	}
+	PersistentReceiptsCacheFlag = cli.BoolFlag{
+		Name:  "receipts.persistent.cache",
+		Usage: "Store the receipts generated for RPC and p2p requests in the db, so they survive restarts",
+		Value: ethconfig.Defaults.Sync.PersistentReceiptsCache.Enabled,
+	}
+	PersistentReceiptsCacheBlocksFlag = cli.IntFlag{
+		Name:  "receipts.persistent.cache.blocks",
+		Usage: "Maximum number of blocks whose receipts are kept in the persistent cache",
+		Value: ethconfig.Defaults.Sync.PersistentReceiptsCache.Blocks,
+	}
+	PersistentReceiptsCacheDepthFlag = cli.Uint64Flag{
+		Name:  "receipts.persistent.cache.depth",
+		Usage: "Receipts of blocks deeper than this below the head are pruned from the persistent cache",
+		Value: ethconfig.Defaults.Sync.PersistentReceiptsCache.Depth,
+	}
+	PeerReputationFlag = cli.BoolFlag{
+		Name:  "p2p.reputation",
+		Usage: "Keep the reputation of the peers, built from their penalties and useful responses, across restarts, and log the peers with a bad one when they connect",
+		Value: ethconfig.Defaults.Sync.PeerReputation.Enabled,
+	}
+	PeerReputationEnforceFlag = cli.BoolFlag{
+		Name:  "p2p.reputation.enforce",
+		Usage: "Kick the peers with a bad reputation when they connect, instead of only logging them",
+		Value: ethconfig.Defaults.Sync.PeerReputation.Enforce,
+	}
+	PeerReputationThresholdFlag = cli.Float64Flag{
+		Name:  "p2p.reputation.threshold",
+		Usage: "Reputation below which a peer is bad: a penalty costs 10, a useless response 1, a useful response earns 1",
+		Value: ethconfig.Defaults.Sync.PeerReputation.Threshold,
+	}
+	PeerReputationHorizonFlag = cli.DurationFlag{
+		Name:  "p2p.reputation.horizon",
+		Usage: "Time over which the reputation of a peer decays to nothing, after which its penalties are forgotten",
+		Value: ethconfig.Defaults.Sync.PeerReputation.Horizon,
+	}
+	RequestTracesFlag = cli.IntFlag{
+		Name:  "p2p.request-traces",
+		Usage: "Trace the lifecycle of the last N header and body requests to the peers, served by the /request-traces diagnostics endpoint, 0 to disable",
+		Value: ethconfig.Defaults.Sync.RequestTraces,
+	}
+	MessageRecordingDirFlag = cli.StringFlag{
+		Name:  "p2p.record-messages",
+		Usage: "Record the inbound p2p messages to files in this directory, to be replayed when debugging, empty to disable",
+	}
+	MessageRecordingFileSizeFlag = cli.StringFlag{
+		Name:  "p2p.record-messages.file-size",
+		Usage: "Size from which a file of the recorded p2p messages is rotated",
+		Value: ethconfig.Defaults.Sync.MessageRecording.FileSize.String(),
+	}
+	MessageRecordingFilesFlag = cli.IntFlag{
+		Name:  "p2p.record-messages.files",
+		Usage: "Amount of files of the recorded p2p messages kept, the oldest being removed",
+		Value: ethconfig.Defaults.Sync.MessageRecording.Files,
+	}
+
 	UploadLocationFlag = cli.StringFlag{
 		Name:  "upload.location",
 		Usage: "Location to upload snapshot segments to",
@@ -223,7 +322,7 @@ var (
 
 	RpcSubscriptionFiltersMaxLogsFlag = cli.IntFlag{
 		Name:  "rpc.subscription.filters.maxlogs",
-		Usage: "Maximum number of logs to store per subscription.",
+		Usage: "Maximum number of logs to store per subscription, and to return per eth_getLogs call.",
 		Value: rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxLogs,
 	}
 	RpcSubscriptionFiltersMaxHeadersFlag = cli.IntFlag{
@@ -246,6 +345,51 @@ var (
 		Usage: "Maximum number of topics per subscription to filter logs by.",
 		Value: rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxTopics,
 	}
+	RpcSubscriptionHeadsBufferSizeFlag = cli.IntFlag{
+		Name:  "rpc.subscription.heads.buffer",
+		Usage: "Number of block headers buffered per newHeads subscription (0 = default of the subscription).",
+		Value: rpchelper.DefaultFiltersConfig.RpcSubscriptionHeadsBufferSize,
+	}
+	RpcSubscriptionLogsBufferSizeFlag = cli.IntFlag{
+		Name:  "rpc.subscription.logs.buffer",
+		Usage: "Number of logs buffered per logs subscription (0 = default of the subscription).",
+		Value: rpchelper.DefaultFiltersConfig.RpcSubscriptionLogsBufferSize,
+	}
+	RpcSubscriptionTxsBufferSizeFlag = cli.IntFlag{
+		Name:  "rpc.subscription.txs.buffer",
+		Usage: "Number of transaction batches buffered per pending transactions subscription (0 = default of the subscription).",
+		Value: rpchelper.DefaultFiltersConfig.RpcSubscriptionTxsBufferSize,
+	}
+	RpcSubscriptionSlowConsumerFlag = cli.StringFlag{
+		Name:  "rpc.subscription.slowconsumer",
+		Usage: "What to do when a subscriber doesn't keep up and its buffer is full: drop-newest, drop-oldest or disconnect.",
+		Value: string(rpchelper.DefaultFiltersConfig.RpcSubscriptionSlowConsumer),
+	}
+	RpcSubscriptionLogsRateLimitFlag = cli.IntFlag{
+		Name:  "rpc.subscription.logs.ratelimit",
+		Usage: "Maximum number of logs notified per second per logs subscription, the subscription is sent a truncation notice instead of the logs beyond it (0 = no limit).",
+		Value: rpchelper.DefaultFiltersConfig.RpcSubscriptionLogsRateLimit,
+	}
+	RpcSubscriptionLogsMaxPerBlockFlag = cli.IntFlag{
+		Name:  "rpc.subscription.logs.maxperblock",
+		Usage: "Maximum number of logs of a block notified per logs subscription, the subscription is sent a truncation notice instead of the logs beyond it (0 = no limit).",
+		Value: rpchelper.DefaultFiltersConfig.RpcSubscriptionLogsMaxPerBlock,
+	}
+	RpcSubscriptionLogsGlobalRateLimitFlag = cli.IntFlag{
+		Name:  "rpc.subscription.logs.globalratelimit",
+		Usage: "Maximum number of logs notified per second across all logs subscriptions, a subscription is sent a truncation notice instead of the logs beyond it (0 = no limit).",
+		Value: rpchelper.DefaultFiltersConfig.RpcSubscriptionLogsGlobalRateLimit,
+	}
+	RpcFiltersTTLFlag = cli.DurationFlag{
+		Name:  "rpc.filters.ttl",
+		Usage: "How long a filter installed by eth_newFilter, eth_newBlockFilter or eth_newPendingTransactionFilter lives without being polled or kept alive by eth_keepAliveFilter (0 = forever).",
+		Value: rpchelper.DefaultFiltersConfig.RpcFiltersTTL,
+	}
+	RpcFiltersMaxPerClientFlag = cli.IntFlag{
+		Name:  "rpc.filters.maxperclient",
+		Usage: "Maximum number of filters installed per client IP address (0 = no limit).",
+		Value: rpchelper.DefaultFiltersConfig.RpcFiltersMaxPerClient,
+	}
 )
 
 func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.Logger) {
@@ -300,6 +444,12 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 			utils.Fatalf("Invalid bodyCacheLimit provided: %v", err)
 		}
 	}
+	if ctx.String(HeadersBacklogLimitFlag.Name) != "" {
+		err := cfg.Sync.PosHeadersBacklogLimit.UnmarshalText([]byte(ctx.String(HeadersBacklogLimitFlag.Name)))
+		if err != nil {
+			utils.Fatalf("Invalid headersBacklogLimit provided: %v", err)
+		}
+	}
 
 	if ctx.String(SyncLoopThrottleFlag.Name) != "" {
 		syncLoopThrottle, err := time.ParseDuration(ctx.String(SyncLoopThrottleFlag.Name))
@@ -316,7 +466,27 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 	if limit := ctx.Uint(SyncLoopBlockLimitFlag.Name); limit > 0 {
 		cfg.Sync.LoopBlockLimit = limit
 	}
+	cfg.Sync.LoopTimeBudget = ctx.Duration(SyncLoopTimeBudgetFlag.Name)
 	cfg.Sync.ParallelStateFlushing = ctx.Bool(SyncParallelStateFlushing.Name)
+	cfg.Sync.P2PReceiptsCache.Blocks = ctx.Int(P2PReceiptsCacheBlocksFlag.Name)
+	cfg.Sync.P2PReceiptsCache.TTL = ctx.Duration(P2PReceiptsCacheTTLFlag.Name)
+	cfg.Sync.RPCReceiptsCache.Blocks = ctx.Int(RPCReceiptsCacheBlocksFlag.Name)
+	cfg.Sync.RPCReceiptsCache.TTL = ctx.Duration(RPCReceiptsCacheTTLFlag.Name)
+	cfg.Sync.PersistentReceiptsCache.Enabled = ctx.Bool(PersistentReceiptsCacheFlag.Name)
+	cfg.Sync.PersistentReceiptsCache.Blocks = ctx.Int(PersistentReceiptsCacheBlocksFlag.Name)
+	cfg.Sync.PersistentReceiptsCache.Depth = ctx.Uint64(PersistentReceiptsCacheDepthFlag.Name)
+	cfg.Sync.PeerReputation.Enabled = ctx.Bool(PeerReputationFlag.Name)
+	cfg.Sync.PeerReputation.Enforce = ctx.Bool(PeerReputationEnforceFlag.Name)
+	cfg.Sync.PeerReputation.Threshold = ctx.Float64(PeerReputationThresholdFlag.Name)
+	if horizon := ctx.Duration(PeerReputationHorizonFlag.Name); horizon > 0 {
+		cfg.Sync.PeerReputation.Horizon = horizon
+	}
+	cfg.Sync.RequestTraces = ctx.Int(RequestTracesFlag.Name)
+	cfg.Sync.MessageRecording.Dir = ctx.String(MessageRecordingDirFlag.Name)
+	if err := cfg.Sync.MessageRecording.FileSize.UnmarshalText([]byte(ctx.String(MessageRecordingFileSizeFlag.Name))); err != nil {
+		utils.Fatalf("Invalid p2p.record-messages.file-size provided: %v", err)
+	}
+	cfg.Sync.MessageRecording.Files = ctx.Int(MessageRecordingFilesFlag.Name)
 
 	if location := ctx.String(UploadLocationFlag.Name); len(location) > 0 {
 		cfg.Sync.UploadLocation = location
@@ -452,27 +622,43 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config, logger log.Logg
 			WriteTimeout: ctx.Duration(AuthRpcWriteTimeoutFlag.Name),
 			IdleTimeout:  ctx.Duration(HTTPIdleTimeoutFlag.Name),
 		},
-		EvmCallTimeout:            ctx.Duration(EvmCallTimeoutFlag.Name),
-		OverlayGetLogsTimeout:     ctx.Duration(OverlayGetLogsFlag.Name),
-		OverlayReplayBlockTimeout: ctx.Duration(OverlayReplayBlockFlag.Name),
-		WebsocketPort:             ctx.Int(utils.WSPortFlag.Name),
-		WebsocketEnabled:          ctx.IsSet(utils.WSEnabledFlag.Name),
-		RpcBatchConcurrency:       ctx.Uint(utils.RpcBatchConcurrencyFlag.Name),
-		RpcStreamingDisable:       ctx.Bool(utils.RpcStreamingDisableFlag.Name),
-		DBReadConcurrency:         ctx.Int(utils.DBReadConcurrencyFlag.Name),
-		RpcAllowListFilePath:      ctx.String(utils.RpcAccessListFlag.Name),
+		EvmCallTimeout:              ctx.Duration(EvmCallTimeoutFlag.Name),
+		OverlayGetLogsTimeout:       ctx.Duration(OverlayGetLogsFlag.Name),
+		OverlayReplayBlockTimeout:   ctx.Duration(OverlayReplayBlockFlag.Name),
+		WebsocketPort:               ctx.Int(utils.WSPortFlag.Name),
+		WebsocketEnabled:            ctx.IsSet(utils.WSEnabledFlag.Name),
+		WebsocketCompressionLevel:   ctx.Int(utils.WsCompressionLevelFlag.Name),
+		WebsocketCompressionMinSize: ctx.Int(utils.WsCompressionMinSizeFlag.Name),
+		RpcBatchConcurrency:         ctx.Uint(utils.RpcBatchConcurrencyFlag.Name),
+		RpcStreamingDisable:         ctx.Bool(utils.RpcStreamingDisableFlag.Name),
+		RpcTxReuse:                  rpchelper.TxReuse(ctx.String(utils.RpcTxReuseFlag.Name)),
+		RpcTxReuseMaxAge:            ctx.Duration(utils.RpcTxReuseMaxAgeFlag.Name),
+		RpcKvStats:                  ctx.Bool(utils.RpcKvStatsFlag.Name),
+		DBReadConcurrency:           ctx.Int(utils.DBReadConcurrencyFlag.Name),
+		RpcAllowListFilePath:        ctx.String(utils.RpcAccessListFlag.Name),
 		RpcFiltersConfig: rpchelper.FiltersConfig{
 			RpcSubscriptionFiltersMaxLogs:      ctx.Int(RpcSubscriptionFiltersMaxLogsFlag.Name),
 			RpcSubscriptionFiltersMaxHeaders:   ctx.Int(RpcSubscriptionFiltersMaxHeadersFlag.Name),
 			RpcSubscriptionFiltersMaxTxs:       ctx.Int(RpcSubscriptionFiltersMaxTxsFlag.Name),
 			RpcSubscriptionFiltersMaxAddresses: ctx.Int(RpcSubscriptionFiltersMaxAddressesFlag.Name),
 			RpcSubscriptionFiltersMaxTopics:    ctx.Int(RpcSubscriptionFiltersMaxTopicsFlag.Name),
+			RpcSubscriptionHeadsBufferSize:     ctx.Int(RpcSubscriptionHeadsBufferSizeFlag.Name),
+			RpcSubscriptionLogsBufferSize:      ctx.Int(RpcSubscriptionLogsBufferSizeFlag.Name),
+			RpcSubscriptionTxsBufferSize:       ctx.Int(RpcSubscriptionTxsBufferSizeFlag.Name),
+			RpcSubscriptionSlowConsumer:        rpchelper.SlowConsumerPolicy(ctx.String(RpcSubscriptionSlowConsumerFlag.Name)),
+			RpcSubscriptionLogsRateLimit:       ctx.Int(RpcSubscriptionLogsRateLimitFlag.Name),
+			RpcSubscriptionLogsMaxPerBlock:     ctx.Int(RpcSubscriptionLogsMaxPerBlockFlag.Name),
+			RpcSubscriptionLogsGlobalRateLimit: ctx.Int(RpcSubscriptionLogsGlobalRateLimitFlag.Name),
+			RpcFiltersTTL:                      ctx.Duration(RpcFiltersTTLFlag.Name),
+			RpcFiltersMaxPerClient:             ctx.Int(RpcFiltersMaxPerClientFlag.Name),
 		},
 		Gascap:              ctx.Uint64(utils.RpcGasCapFlag.Name),
 		Feecap:              ctx.Float64(utils.RPCGlobalTxFeeCapFlag.Name),
 		MaxTraces:           ctx.Uint64(utils.TraceMaxtracesFlag.Name),
+		TraceFilterMaxBytes: ctx.Uint64(utils.TraceFilterMaxBytesFlag.Name),
 		TraceCompatibility:  ctx.Bool(utils.RpcTraceCompatFlag.Name),
 		BatchLimit:          ctx.Int(utils.RpcBatchLimit.Name),
+		BatchGasLimit:       ctx.Uint64(utils.RpcBatchGasLimit.Name),
 		ReturnDataLimit:     ctx.Int(utils.RpcReturnDataLimit.Name),
 		AllowUnprotectedTxs: ctx.Bool(utils.AllowUnprotectedTxs.Name),
 
@@ -521,6 +707,10 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config, logger log.Logg
 		utils.Fatalf("Invalid state.cache value provided")
 	}
 
+	if err = c.RpcFiltersConfig.RpcSubscriptionSlowConsumer.Validate(); err != nil {
+		utils.Fatalf("Invalid %s value provided: %v", RpcSubscriptionSlowConsumerFlag.Name, err)
+	}
+
 	/*
 		rootCmd.PersistentFlags().BoolVar(&cfg.GRPCServerEnabled, "grpc", false, "Enable GRPC server")
 		rootCmd.PersistentFlags().StringVar(&cfg.GRPCListenAddress, "grpc.addr", node.DefaultGRPCHost, "GRPC server listening interface")
@@ -541,6 +731,8 @@ func setPrivateApi(ctx *cli.Context, cfg *nodecfg.Config) {
 		log.Warn("private.api.ratelimit is too big", "force", maxRateLimit)
 		cfg.PrivateApiRateLimit = maxRateLimit
 	}
+	cfg.PrivateApiSubscribeQueueSize = ctx.Int(PrivateApiSubscribeQueueSize.Name)
+	cfg.PrivateApiSlowSubscriberTimeout = ctx.Duration(PrivateApiSlowSubscriberTimeout.Name)
 	if ctx.Bool(TLSFlag.Name) {
 		certFile := ctx.String(TLSCertFlag.Name)
 		keyFile := ctx.String(TLSKeyFlag.Name)
@@ -39,6 +39,8 @@ import (
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/metrics"
 	"github.com/erigontech/erigon/eth/tracers"
+	"github.com/erigontech/erigon/rpc"
+	"github.com/erigontech/erigon/rpc/rpchelper"
 	"github.com/erigontech/erigon/turbo/logging"
 )
 
@@ -181,6 +183,8 @@ func SetupCobra(cmd *cobra.Command, filePrefix string) log.Logger {
 	if metricsEnabled && metricsAddr != "" {
 		metricsAddress = fmt.Sprintf("%s:%d", metricsAddr, metricsPort)
 		metricsMux = metrics.Setup(metricsAddress, logger)
+		metricsMux.Handle(rpc.DebugStatsPath, rpc.DebugStatsHandler())
+		metricsMux.Handle(rpchelper.DebugKvStatsPath, rpchelper.DebugKvStatsHandler())
 	}
 
 	if pprof {
@@ -246,6 +250,8 @@ func Setup(ctx *cli.Context, rootLogger bool) (log.Logger, *tracers.Tracer, *htt
 		metricsPort := ctx.Int(metricsPortFlag.Name)
 		metricsAddress = fmt.Sprintf("%s:%d", metricsAddr, metricsPort)
 		metricsMux = metrics.Setup(metricsAddress, logger)
+		metricsMux.Handle(rpc.DebugStatsPath, rpc.DebugStatsHandler())
+		metricsMux.Handle(rpchelper.DebugKvStatsPath, rpchelper.DebugKvStatsHandler())
 	}
 
 	if pprofEnabled {
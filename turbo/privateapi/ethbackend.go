@@ -54,7 +54,9 @@ import (
 // 3.1.0 - add Subscribe to logs
 // 3.2.0 - add EngineGetBlobsBundleV1k
 // 3.3.0 - merge EngineGetBlobsBundleV1 into EngineGetPayload
-var EthBackendAPIVersion = &types2.VersionReply{Major: 3, Minor: 3, Patch: 0}
+// 3.4.0 - add SubscribeBlocks
+// 3.5.0 - add SubscribePeerEvents
+var EthBackendAPIVersion = &types2.VersionReply{Major: 3, Minor: 5, Patch: 0}
 
 type EthBackendServer struct {
 	remote.UnimplementedETHBACKENDServer // must be embedded to have forward compatible implementations.
@@ -66,9 +68,10 @@ type EthBackendServer struct {
 	blockReader           services.FullBlockReader
 	latestBlockBuiltStore *builder.LatestBlockBuiltStore
 
-	logsFilter  *LogsFilterAggregator
-	logger      log.Logger
-	chainConfig *chain.Config
+	logsFilter   *LogsFilterAggregator
+	logger       log.Logger
+	chainConfig  *chain.Config
+	subscribeCfg SubscribeConfig
 }
 
 type EthBackend interface {
@@ -78,10 +81,14 @@ type EthBackend interface {
 	NodesInfo(limit int) (*remote.NodesInfoReply, error)
 	Peers(ctx context.Context) (*remote.PeersReply, error)
 	AddPeer(ctx context.Context, url *remote.AddPeerRequest) (*remote.AddPeerReply, error)
+	// SnapshotsDownloadProgress returns the percentage of the snapshots downloaded, false if no download is running.
+	SnapshotsDownloadProgress() (float32, bool)
+	// SyncMode returns the mode of the header downloader, see headerdownload.SyncMode, empty if there's none.
+	SyncMode() string
 }
 
 func NewEthBackendServer(ctx context.Context, eth EthBackend, db kv.RwDB, notifications *shards.Notifications, blockReader services.FullBlockReader,
-	logger log.Logger, latestBlockBuiltStore *builder.LatestBlockBuiltStore, chainConfig *chain.Config,
+	logger log.Logger, latestBlockBuiltStore *builder.LatestBlockBuiltStore, chainConfig *chain.Config, subscribeCfg SubscribeConfig,
 ) *EthBackendServer {
 	s := &EthBackendServer{
 		ctx:                   ctx,
@@ -93,6 +100,7 @@ func NewEthBackendServer(ctx context.Context, eth EthBackend, db kv.RwDB, notifi
 		logger:                logger,
 		latestBlockBuiltStore: latestBlockBuiltStore,
 		chainConfig:           chainConfig,
+		subscribeCfg:          subscribeCfg.withDefaults(),
 	}
 
 	ch, clean := s.notifications.Events.AddLogsSubscription()
@@ -147,6 +155,10 @@ func (s *EthBackendServer) Syncing(ctx context.Context, _ *emptypb.Empty) (*remo
 		FrozenBlocks:     frozenBlocks,
 		LastNewBlockSeen: highestBlock,
 		Syncing:          true,
+		Snapshots:        s.snapshotsProgress(),
+	}
+	if s.eth != nil {
+		reply.SyncMode = s.eth.SyncMode()
 	}
 
 	// Maybe it is still downloading snapshots. Impossible to determine the highest block.
@@ -161,6 +173,7 @@ func (s *EthBackendServer) Syncing(ctx context.Context, _ *emptypb.Empty) (*remo
 		return reply, nil
 	}
 
+	txNumsReader := s.blockReader.TxnumReader(ctx)
 	reply.Stages = make([]*remote.SyncingReply_StageProgress, len(stages.AllStages))
 	for i, stage := range stages.AllStages {
 		progress, err := stages.GetStageProgress(tx, stage)
@@ -170,11 +183,42 @@ func (s *EthBackendServer) Syncing(ctx context.Context, _ *emptypb.Empty) (*remo
 		reply.Stages[i] = &remote.SyncingReply_StageProgress{}
 		reply.Stages[i].StageName = string(stage)
 		reply.Stages[i].BlockNumber = progress
+		if progress > 0 {
+			if reply.Stages[i].TxNum, err = txNumsReader.Max(tx, progress); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return reply, nil
 }
 
+// snapshotsProgress returns the progress of the download and of the indexing of the snapshots, nil if neither is
+// running.
+func (s *EthBackendServer) snapshotsProgress() *remote.SyncingReply_SnapshotsProgress {
+	download, downloading := float32(100), false
+	if s.eth != nil {
+		download, downloading = s.eth.SnapshotsDownloadProgress()
+		if !downloading {
+			download = 100
+		}
+	}
+	var segments uint64
+	if snapshots := s.blockReader.Snapshots(); snapshots != nil {
+		segments = snapshots.SegmentsMax()
+	}
+	indexing := float32(100)
+	if indexed := s.blockReader.FrozenBlocks(); segments > indexed {
+		indexing = float32(indexed) * 100 / float32(segments)
+	} else if downloading && segments == 0 {
+		indexing = 0 // the segments are opened once downloaded
+	}
+	if !downloading && indexing == 100 {
+		return nil
+	}
+	return &remote.SyncingReply_SnapshotsProgress{DownloadPercent: download, IndexingPercent: indexing}
+}
+
 func (s *EthBackendServer) PendingBlock(ctx context.Context, _ *emptypb.Empty) (*remote.PendingBlockReply, error) {
 	pendingBlock := s.latestBlockBuiltStore.BlockBuilt()
 	if pendingBlock == nil {
@@ -239,7 +283,9 @@ func (s *EthBackendServer) Subscribe(r *remote.SubscribeRequest, subscribeServer
 			}
 		}
 	}()
-	_ = subscribeServer.Send(&remote.SubscribeReply{Type: remote.Event_NEW_SNAPSHOT})
+	sub := newSubscriber[*remote.SubscribeReply](subscribeServer, s.subscribeCfg)
+	defer sub.Close()
+	_ = sub.Send(&remote.SubscribeReply{Type: remote.Event_NEW_SNAPSHOT})
 	for {
 		select {
 		case <-s.ctx.Done():
@@ -248,7 +294,7 @@ func (s *EthBackendServer) Subscribe(r *remote.SubscribeRequest, subscribeServer
 			return subscribeServer.Context().Err()
 		case headersRlp := <-ch:
 			for _, headerRlp := range headersRlp {
-				if err = subscribeServer.Send(&remote.SubscribeReply{
+				if err = sub.Send(&remote.SubscribeReply{
 					Type: remote.Event_HEADER,
 					Data: headerRlp,
 				}); err != nil {
@@ -256,13 +302,97 @@ func (s *EthBackendServer) Subscribe(r *remote.SubscribeRequest, subscribeServer
 				}
 			}
 		case <-newSnCh:
-			if err = subscribeServer.Send(&remote.SubscribeReply{Type: remote.Event_NEW_SNAPSHOT}); err != nil {
+			if err = sub.Send(&remote.SubscribeReply{Type: remote.Event_NEW_SNAPSHOT}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// SubscribeBlocks sends each new canonical block with its body: the header and body RLP, the new head and, on the
+// first block of a new branch, the old head and the common ancestor.
+func (s *EthBackendServer) SubscribeBlocks(r *remote.SubscribeRequest, subscribeServer remote.ETHBACKEND_SubscribeBlocksServer) (err error) {
+	s.logger.Debug("[rpc] new subscription to `newBlocks` events")
+	ch, clean := s.notifications.Events.AddBlockSubscription()
+	defer clean()
+	defer func() {
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				s.logger.Warn("[rpc] terminated subscription to `newBlocks` events", "reason", err)
+			}
+		}
+	}()
+	sub := newSubscriber[*remote.SubscribeBlocksReply](subscribeServer, s.subscribeCfg)
+	defer sub.Close()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-subscribeServer.Context().Done():
+			return subscribeServer.Context().Err()
+		case blocks := <-ch:
+			for _, block := range blocks {
+				if err = sub.Send(newBlockReply(block)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func newBlockReply(block *shards.NewBlock) *remote.SubscribeBlocksReply {
+	reply := &remote.SubscribeBlocksReply{
+		HeaderRlp: block.HeaderRlp,
+		BodyRlp:   block.BodyRlp,
+		NewHead:   gointerfaces.ConvertHashToH256(block.NewHead),
+	}
+	if block.OldHead != (common.Hash{}) {
+		reply.OldHead = gointerfaces.ConvertHashToH256(block.OldHead)
+	}
+	if block.IsReorg() {
+		reply.CommonAncestor = gointerfaces.ConvertHashToH256(block.CommonAncestor)
+	}
+	return reply
+}
+
+// SubscribePeerEvents sends each peer connecting to or disconnecting from a sentry.
+func (s *EthBackendServer) SubscribePeerEvents(r *remote.SubscribeRequest, subscribeServer remote.ETHBACKEND_SubscribePeerEventsServer) (err error) {
+	s.logger.Debug("[rpc] new subscription to `peerEvents` events")
+	ch, clean := s.notifications.Events.AddPeerEventSubscription()
+	defer clean()
+	defer func() {
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				s.logger.Warn("[rpc] terminated subscription to `peerEvents` events", "reason", err)
+			}
+		}
+	}()
+	sub := newSubscriber[*remote.SubscribePeerEventsReply](subscribeServer, s.subscribeCfg)
+	defer sub.Close()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-subscribeServer.Context().Done():
+			return subscribeServer.Context().Err()
+		case event := <-ch:
+			if err = sub.Send(newPeerEventReply(event)); err != nil {
 				return err
 			}
 		}
 	}
 }
 
+func newPeerEventReply(event *shards.PeerEvent) *remote.SubscribePeerEventsReply {
+	return &remote.SubscribePeerEventsReply{
+		EventId: remote.SubscribePeerEventsReply_PeerEventId(event.EventId),
+		PeerId:  gointerfaces.ConvertHashToH512(event.PeerId),
+		Enode:   event.Enode,
+		Name:    event.Name,
+		Caps:    event.Caps,
+	}
+}
+
 func (s *EthBackendServer) ProtocolVersion(_ context.Context, _ *remote.ProtocolVersionRequest) (*remote.ProtocolVersionReply, error) {
 	return &remote.ProtocolVersionReply{Id: direct.ETH67}, nil
 }
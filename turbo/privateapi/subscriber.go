@@ -0,0 +1,158 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package privateapi
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/erigontech/erigon-lib/metrics"
+)
+
+// SubscribeConfig bounds what a subscriber of the Subscribe, SubscribeBlocks and SubscribePeerEvents streams which doesn't keep up
+// can cost: each subscriber gets its own queue of events, and is disconnected once it stays full for too long.
+type SubscribeConfig struct {
+	QueueSize   int           // events queued per subscriber, 0 for the default
+	SlowTimeout time.Duration // how long the queue can stay full before the subscriber is disconnected, 0 for the default
+}
+
+var DefaultSubscribeConfig = SubscribeConfig{
+	QueueSize:   1024,
+	SlowTimeout: 30 * time.Second,
+}
+
+func (cfg SubscribeConfig) withDefaults() SubscribeConfig {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultSubscribeConfig.QueueSize
+	}
+	if cfg.SlowTimeout <= 0 {
+		cfg.SlowTimeout = DefaultSubscribeConfig.SlowTimeout
+	}
+	return cfg
+}
+
+// ErrSlowSubscriber is returned to the subscribers disconnected for not reading their events in time.
+var ErrSlowSubscriber = status.Error(codes.ResourceExhausted, "subscriber too slow: events queue full")
+
+var (
+	subscribeQueueDepth       = metrics.GetOrCreateGauge(`privateapi_subscribe_queue_depth`)
+	subscribeSlowDisconnected = metrics.GetOrCreateCounter(`privateapi_subscribe_disconnected{reason="slow"}`)
+)
+
+// subscribeStream is the server side of a subscription stream, e.g. remote.ETHBACKEND_SubscribeServer.
+type subscribeStream[T any] interface {
+	Send(T) error
+	Context() context.Context
+}
+
+// subscriber decouples the dispatch of the events of a subscription from the stream they are sent to: events are
+// queued and sent by a dedicated goroutine, so a stream blocked by its consumer only holds up its own subscription.
+type subscriber[T any] struct {
+	stream      subscribeStream[T]
+	queue       chan T
+	slowTimeout time.Duration
+	stopped     chan error    // the error which stopped the sender
+	quit        chan struct{} // closed by Close
+	done        chan struct{} // closed once the sender exits
+}
+
+func newSubscriber[T any](stream subscribeStream[T], cfg SubscribeConfig) *subscriber[T] {
+	s := &subscriber[T]{
+		stream:      stream,
+		queue:       make(chan T, cfg.QueueSize),
+		slowTimeout: cfg.SlowTimeout,
+		stopped:     make(chan error, 1),
+		quit:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go s.sendLoop()
+	return s
+}
+
+func (s *subscriber[T]) sendLoop() {
+	defer close(s.done)
+	for {
+		// checked first: once closed, the queued replies are discarded rather than sent
+		select {
+		case <-s.quit:
+			return
+		default:
+		}
+		select {
+		case <-s.quit:
+			return
+		case reply := <-s.queue:
+			subscribeQueueDepth.Dec()
+			if err := s.stream.Send(reply); err != nil {
+				s.stopped <- err
+				return
+			}
+		}
+	}
+}
+
+// Send queues the reply. It fails with ErrSlowSubscriber if the queue stays full for the slow timeout,
+// or with the error of the stream if sending a previous reply failed.
+func (s *subscriber[T]) Send(reply T) error {
+	select {
+	case err := <-s.stopped:
+		return err
+	case s.queue <- reply:
+		subscribeQueueDepth.Inc()
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(s.slowTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-s.stopped:
+		return err
+	case s.queue <- reply:
+		subscribeQueueDepth.Inc()
+		return nil
+	case <-s.stream.Context().Done():
+		return s.stream.Context().Err()
+	case <-timer.C:
+		subscribeSlowDisconnected.Inc()
+		return ErrSlowSubscriber
+	}
+}
+
+// Close stops the sender, waits for it to exit and discards the queued replies. A sender blocked by its consumer is
+// only released by the end of the stream, which comes after Close: it is waited for up to the slow timeout, after
+// which it exits on its own once the stream ends.
+func (s *subscriber[T]) Close() {
+	close(s.quit)
+	timer := time.NewTimer(s.slowTimeout)
+	defer timer.Stop()
+	select {
+	case <-s.done:
+	case <-timer.C:
+	}
+	for {
+		select {
+		case <-s.queue:
+			subscribeQueueDepth.Dec()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package privateapi
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/erigontech/erigon-lib/direct"
+	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/turbo/shards"
+)
+
+// stuckStream is the stream of a subscriber which never reads its events.
+type stuckStream struct {
+	remote.ETHBACKEND_SubscribeServer
+	ctx     context.Context
+	sending chan struct{} // closed once the first event is being sent
+	once    sync.Once
+}
+
+func (s *stuckStream) Send(*remote.SubscribeReply) error {
+	s.once.Do(func() { close(s.sending) })
+	<-s.ctx.Done()
+	return s.ctx.Err()
+}
+
+func (s *stuckStream) Context() context.Context { return s.ctx }
+
+func TestSubscribeSlowSubscriber(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	notifications := shards.NewNotifications(nil)
+	server := NewEthBackendServer(ctx, nil, nil, notifications, nil, log.New(), nil, nil, SubscribeConfig{QueueSize: 2, SlowTimeout: 100 * time.Millisecond})
+	disconnected := subscribeSlowDisconnected.GetValueUint64()
+
+	stuck := &stuckStream{ctx: ctx, sending: make(chan struct{})}
+	stuckErr := make(chan error, 1)
+	go func() { stuckErr <- server.Subscribe(&remote.SubscribeRequest{}, stuck) }()
+	<-stuck.sending
+
+	healthy, err := direct.NewEthBackendClientDirect(server).Subscribe(ctx, &remote.SubscribeRequest{})
+	require.NoError(t, err)
+	reply, err := healthy.Recv()
+	require.NoError(t, err)
+	require.Equal(t, remote.Event_NEW_SNAPSHOT, reply.Type)
+
+	// the stuck subscriber fills its queue, then gets disconnected, without holding up the healthy one
+	for i := 0; i < 10; i++ {
+		notifications.Events.OnNewHeader([][]byte{{byte(i)}})
+		reply, err = healthy.Recv()
+		require.NoError(t, err)
+		require.Equal(t, remote.Event_HEADER, reply.Type)
+		require.Equal(t, []byte{byte(i)}, reply.Data)
+	}
+
+	err = <-stuckErr
+	require.ErrorIs(t, err, ErrSlowSubscriber)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Equal(t, disconnected+1, subscribeSlowDisconnected.GetValueUint64())
+
+	notifications.Events.OnNewHeader([][]byte{{0xff}})
+	reply, err = healthy.Recv()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xff}, reply.Data)
+}
+
+// releasedStream is the stream of a subscriber which reads its events only once released.
+type releasedStream struct {
+	remote.ETHBACKEND_SubscribeServer
+	ctx     context.Context
+	sending chan struct{} // signaled when an event is being sent
+	release chan struct{}
+	sent    atomic.Int32
+}
+
+func (s *releasedStream) Send(*remote.SubscribeReply) error {
+	s.sending <- struct{}{}
+	<-s.release
+	s.sent.Add(1)
+	return nil
+}
+
+func (s *releasedStream) Context() context.Context { return s.ctx }
+
+func TestSubscriberClose(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream := &releasedStream{ctx: ctx, sending: make(chan struct{}, 1), release: make(chan struct{})}
+	sub := newSubscriber[*remote.SubscribeReply](stream, SubscribeConfig{QueueSize: 4, SlowTimeout: 10 * time.Second})
+
+	require.NoError(t, sub.Send(&remote.SubscribeReply{}))
+	<-stream.sending
+	for i := 0; i < 3; i++ {
+		require.NoError(t, sub.Send(&remote.SubscribeReply{}))
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		sub.Close()
+		close(closed)
+	}()
+	// Close waits for the reply being sent
+	select {
+	case <-closed:
+		t.Fatal("closed while sending")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(stream.release)
+	select {
+	case <-closed:
+	case <-ctx.Done():
+		t.Fatal("not closed")
+	}
+	// the sender exited without sending the queued replies, which were discarded
+	select {
+	case <-sub.done:
+	default:
+		t.Fatal("sender still running")
+	}
+	require.Equal(t, int32(1), stream.sent.Load())
+	require.Empty(t, sub.queue)
+}
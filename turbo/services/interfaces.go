@@ -46,6 +46,8 @@ type BlockReader interface {
 
 type HeaderReader interface {
 	Header(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (*types.Header, error)
+	// HeaderRLP returns the header as stored, without decoding it: nil if it's unknown
+	HeaderRLP(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (rlp.RawValue, error)
 	HeaderByNumber(ctx context.Context, tx kv.Getter, blockNum uint64) (*types.Header, error)
 	HeaderNumber(ctx context.Context, tx kv.Getter, hash common.Hash) (*uint64, error)
 	HeaderByHash(ctx context.Context, tx kv.Getter, hash common.Hash) (*types.Header, error)
@@ -87,9 +89,20 @@ type CanonicalReader interface {
 	BadHeaderNumber(ctx context.Context, tx kv.Getter, hash common.Hash) (blockHeight *uint64, err error)
 }
 
+// CanonicalHashCache is implemented by the readers caching the canonical hashes of the db, the stage loop invalidates
+// them on unwinds.
+type CanonicalHashCache interface {
+	// InvalidateCanonicalHashes drops the cached hashes of the blocks from the block from on, the transactions of the
+	// views older than viewID, which may still see them as canonical, bypass the cache.
+	InvalidateCanonicalHashes(from, viewID uint64)
+}
+
 type BodyReader interface {
 	BodyWithTransactions(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (body *types.Body, err error)
 	BodyRlp(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (bodyRlp rlp.RawValue, err error)
+	// FrozenBodiesRlp returns the RLP of the bodies of the blocks from from to from+count-1, stopping at the first one
+	// which isn't in the snapshots
+	FrozenBodiesRlp(ctx context.Context, from uint64, count int) ([]rlp.RawValue, error)
 	Body(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (body *types.Body, txCount uint32, err error)
 	CanonicalBodyForStorage(ctx context.Context, tx kv.Getter, blockNum uint64) (body *types.BodyForStorage, err error)
 	HasSenders(ctx context.Context, tx kv.Getter, hash common.Hash, blockNum uint64) (bool, error)
@@ -25,6 +25,7 @@ import (
 	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
 	types2 "github.com/erigontech/erigon-lib/gointerfaces/typesproto"
 	"github.com/erigontech/erigon-lib/types"
+	"github.com/erigontech/erigon/execution/stagedsync/stages"
 )
 
 type RpcEventType uint64
@@ -36,10 +37,38 @@ type PendingBlockSubscription func(*types.Block) error
 type PendingTxsSubscription func([]types.Transaction) error
 type LogsSubscription func([]*remote.SubscribeLogsReply) error
 
+// NewBlock is a new canonical block with its body, as sent to the subscribers of full blocks.
+// The first block of a new branch also carries the reorg it completes: the head of the old branch
+// and the last block common to both branches.
+type NewBlock struct {
+	HeaderRlp      []byte
+	BodyRlp        []byte
+	NewHead        common.Hash // head of the chain once all the blocks of the update are applied
+	OldHead        common.Hash // zero if unknown, e.g. for the first update after a restart
+	CommonAncestor common.Hash // zero if the block doesn't follow a reorg
+}
+
+func (b *NewBlock) IsReorg() bool { return b.CommonAncestor != (common.Hash{}) }
+
+// PeerEvent is a peer connecting to or disconnecting from one of the sentries, as sent to the subscribers of peer events.
+// Enode, Name and Caps are only known for connections, and only if the sentry could still find the peer.
+type PeerEvent struct {
+	EventId uint64 // sentryproto.PeerEvent_PeerEventId
+	PeerId  [64]byte
+	Enode   string
+	Name    string
+	Caps    []string
+}
+
 // Events manages event subscriptions and dissimination. Thread-safe
 type Events struct {
 	id                          int
 	headerSubscriptions         map[int]chan [][]byte
+	blockSubscriptions          map[int]chan []*NewBlock
+	unwindSubscriptions         map[int]chan []common.Hash
+	peerEventSubscriptions      map[int]chan *PeerEvent
+	syncStatusSubscriptions     map[int]chan []stages.StageStatus
+	syncModeSubscriptions       map[int]chan string
 	newSnapshotSubscription     map[int]chan struct{}
 	retirementStartSubscription map[int]chan bool
 	retirementDoneSubscription  map[int]chan struct{}
@@ -48,12 +77,18 @@ type Events struct {
 	pendingTxsSubscriptions     map[int]PendingTxsSubscription
 	logsSubscriptions           map[int]chan []*remote.SubscribeLogsReply
 	hasLogSubscriptions         bool
+	head                        common.Hash // last notified head, the old head of the next reorg
 	lock                        sync.RWMutex
 }
 
 func NewEvents() *Events {
 	return &Events{
 		headerSubscriptions:         map[int]chan [][]byte{},
+		blockSubscriptions:          map[int]chan []*NewBlock{},
+		unwindSubscriptions:         map[int]chan []common.Hash{},
+		peerEventSubscriptions:      map[int]chan *PeerEvent{},
+		syncStatusSubscriptions:     map[int]chan []stages.StageStatus{},
+		syncModeSubscriptions:       map[int]chan string{},
 		pendingLogsSubscriptions:    map[int]PendingLogsSubscription{},
 		pendingBlockSubscriptions:   map[int]PendingBlockSubscription{},
 		pendingTxsSubscriptions:     map[int]PendingTxsSubscription{},
@@ -77,6 +112,113 @@ func (e *Events) AddHeaderSubscription() (chan [][]byte, func()) {
 	}
 }
 
+func (e *Events) AddBlockSubscription() (chan []*NewBlock, func()) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	ch := make(chan []*NewBlock, 8)
+	e.id++
+	id := e.id
+	e.blockSubscriptions[id] = ch
+	return ch, func() {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		delete(e.blockSubscriptions, id)
+		close(ch)
+	}
+}
+
+// HasBlockSubscriptions tells if reading the new blocks for OnNewBlocks is worth it.
+func (e *Events) HasBlockSubscriptions() bool {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return len(e.blockSubscriptions) > 0
+}
+
+// AddUnwindSubscription subscribes to the hashes of the blocks unwound by reorgs, see OnUnwind.
+func (e *Events) AddUnwindSubscription() (chan []common.Hash, func()) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	ch := make(chan []common.Hash, 8)
+	e.id++
+	id := e.id
+	e.unwindSubscriptions[id] = ch
+	return ch, func() {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		delete(e.unwindSubscriptions, id)
+		close(ch)
+	}
+}
+
+// HasUnwindSubscriptions tells if looking up the unwound blocks for OnUnwind is worth it.
+func (e *Events) HasUnwindSubscriptions() bool {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return len(e.unwindSubscriptions) > 0
+}
+
+func (e *Events) AddPeerEventSubscription() (chan *PeerEvent, func()) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	ch := make(chan *PeerEvent, 64)
+	e.id++
+	id := e.id
+	e.peerEventSubscriptions[id] = ch
+	return ch, func() {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		delete(e.peerEventSubscriptions, id)
+		close(ch)
+	}
+}
+
+// HasPeerEventSubscriptions tells if looking up the connected peers for OnPeerEvent is worth it.
+func (e *Events) HasPeerEventSubscriptions() bool {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return len(e.peerEventSubscriptions) > 0
+}
+
+// AddSyncStatusSubscription subscribes to the status of the sync stages, sent after each cycle of the stage loop.
+func (e *Events) AddSyncStatusSubscription() (chan []stages.StageStatus, func()) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	ch := make(chan []stages.StageStatus, 8)
+	e.id++
+	id := e.id
+	e.syncStatusSubscriptions[id] = ch
+	return ch, func() {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		delete(e.syncStatusSubscriptions, id)
+		close(ch)
+	}
+}
+
+// HasSyncStatusSubscriptions tells if reading the status of the stages for OnSyncStatus is worth it.
+func (e *Events) HasSyncStatusSubscriptions() bool {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return len(e.syncStatusSubscriptions) > 0
+}
+
+// AddSyncModeSubscription subscribes to the changes of mode of the header downloader, between following the new blocks
+// announced by the peers and dropping their announcements in the initial cycle.
+func (e *Events) AddSyncModeSubscription() (chan string, func()) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	ch := make(chan string, 8)
+	e.id++
+	id := e.id
+	e.syncModeSubscriptions[id] = ch
+	return ch, func() {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		delete(e.syncModeSubscriptions, id)
+		close(ch)
+	}
+}
+
 func (e *Events) AddNewSnapshotSubscription() (chan struct{}, func()) {
 	e.lock.Lock()
 	defer e.lock.Unlock()
@@ -169,6 +311,55 @@ func (e *Events) OnNewHeader(newHeadersRlp [][]byte) {
 	}
 }
 
+// UpdateHead records the head of the chain the subscribers are notified of and returns the previous one.
+func (e *Events) UpdateHead(head common.Hash) (prev common.Hash) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	prev, e.head = e.head, head
+	return prev
+}
+
+func (e *Events) OnNewBlocks(blocks []*NewBlock) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, ch := range e.blockSubscriptions {
+		common.PrioritizedSend(ch, blocks)
+	}
+}
+
+// OnUnwind sends the hashes of the blocks of the old branch of a reorg, which are no longer canonical.
+func (e *Events) OnUnwind(hashes []common.Hash) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, ch := range e.unwindSubscriptions {
+		common.PrioritizedSend(ch, hashes)
+	}
+}
+
+func (e *Events) OnPeerEvent(event *PeerEvent) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, ch := range e.peerEventSubscriptions {
+		common.PrioritizedSend(ch, event)
+	}
+}
+
+func (e *Events) OnSyncStatus(status []stages.StageStatus) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, ch := range e.syncStatusSubscriptions {
+		common.PrioritizedSend(ch, status)
+	}
+}
+
+func (e *Events) OnSyncMode(mode string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, ch := range e.syncModeSubscriptions {
+		common.PrioritizedSend(ch, mode)
+	}
+}
+
 func (e *Events) OnNewPendingLogs(logs types.Logs) {
 	e.lock.Lock()
 	defer e.lock.Unlock()
@@ -230,67 +421,100 @@ func NewNotifications(StateChangesConsumer StateChangeConsumer) *Notifications {
 // - need send notification after `rwtx.Commit` (or user will recv notification, but can't request new data by RPC)
 type RecentLogs struct {
 	receipts map[uint64]types.Receipts
+	unwound  map[uint64]types.Receipts // receipts of the blocks unwound since the last Notify
 	limit    uint64
 	mu       sync.Mutex
 }
 
 func NewRecentLogs(limit uint64) *RecentLogs {
-	return &RecentLogs{receipts: make(map[uint64]types.Receipts, limit), limit: limit}
+	return &RecentLogs{receipts: make(map[uint64]types.Receipts, limit), unwound: map[uint64]types.Receipts{}, limit: limit}
+}
+
+// Unwind moves aside the receipts of the blocks above unwindPoint, so that the next Notify of an unwind can
+// send their logs as removed. They are dropped from the recent receipts: blocks of the new branch without
+// receipts (e.g. empty blocks) are never added, and must not be notified with the logs of the old branch.
+func (r *RecentLogs) Unwind(unwindPoint uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for bn, receipts := range r.receipts {
+		if bn <= unwindPoint {
+			continue
+		}
+		// if several unwinds happen between notifications, subscribers have only seen the first branch
+		if _, ok := r.unwound[bn]; !ok {
+			r.unwound[bn] = receipts
+		}
+		delete(r.receipts, bn)
+	}
 }
 
 // [from,to)
+// On unwind, the logs of the unwound blocks are sent first, with Removed set, then the logs of the new
+// canonical blocks. Blocks are sent in ascending order.
 func (r *RecentLogs) Notify(n *Events, from, to uint64, isUnwind bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	unwound := r.unwound
+	r.unwound = map[uint64]types.Receipts{}
 	if !n.HasLogSubscriptions() {
 		return
 	}
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	for bn, receipts := range r.receipts {
+	if isUnwind {
+		for _, bn := range common.SortedKeys(unwound) {
+			if bn >= from {
+				n.OnLogs(logsReply(unwound[bn], true))
+			}
+		}
+	}
+	for bn := range r.receipts {
 		if bn+r.limit < from { //evict old
 			delete(r.receipts, bn)
-			continue
 		}
+	}
+	for _, bn := range common.SortedKeys(r.receipts) {
 		if bn < from || bn >= to {
 			continue
 		}
+		n.OnLogs(logsReply(r.receipts[bn], false))
+	}
+}
 
-		var blockNum uint64
-		reply := make([]*remote.SubscribeLogsReply, 0, len(receipts))
-		for _, receipt := range receipts {
-			if receipt == nil {
-				continue
-			}
+func logsReply(receipts types.Receipts, removed bool) []*remote.SubscribeLogsReply {
+	var blockNum uint64
+	reply := make([]*remote.SubscribeLogsReply, 0, len(receipts))
+	for _, receipt := range receipts {
+		if receipt == nil {
+			continue
+		}
 
-			blockNum = receipt.BlockNumber.Uint64()
-			//txIndex++
-			//// bor transactions are at the end of the bodies transactions (added manually but not actually part of the block)
-			//if txIndex == uint64(len(block.Transactions())) {
-			//	txHash = bortypes.ComputeBorTxHash(blockNum, block.Hash())
-			//} else {
-			//	txHash = block.Transactions()[txIndex].Hash()
-			//}
-
-			for _, l := range receipt.Logs {
-				res := &remote.SubscribeLogsReply{
-					Address:          gointerfaces.ConvertAddressToH160(l.Address),
-					BlockHash:        gointerfaces.ConvertHashToH256(receipt.BlockHash),
-					BlockNumber:      blockNum,
-					Data:             l.Data,
-					LogIndex:         uint64(l.Index),
-					Topics:           make([]*types2.H256, 0, len(l.Topics)),
-					TransactionHash:  gointerfaces.ConvertHashToH256(receipt.TxHash),
-					TransactionIndex: uint64(l.TxIndex),
-					Removed:          isUnwind,
-				}
-				for _, topic := range l.Topics {
-					res.Topics = append(res.Topics, gointerfaces.ConvertHashToH256(topic))
-				}
-				reply = append(reply, res)
+		blockNum = receipt.BlockNumber.Uint64()
+		//txIndex++
+		//// bor transactions are at the end of the bodies transactions (added manually but not actually part of the block)
+		//if txIndex == uint64(len(block.Transactions())) {
+		//	txHash = bortypes.ComputeBorTxHash(blockNum, block.Hash())
+		//} else {
+		//	txHash = block.Transactions()[txIndex].Hash()
+		//}
+
+		for _, l := range receipt.Logs {
+			res := &remote.SubscribeLogsReply{
+				Address:          gointerfaces.ConvertAddressToH160(l.Address),
+				BlockHash:        gointerfaces.ConvertHashToH256(receipt.BlockHash),
+				BlockNumber:      blockNum,
+				Data:             l.Data,
+				LogIndex:         uint64(l.Index),
+				Topics:           make([]*types2.H256, 0, len(l.Topics)),
+				TransactionHash:  gointerfaces.ConvertHashToH256(receipt.TxHash),
+				TransactionIndex: uint64(l.TxIndex),
+				Removed:          removed,
+			}
+			for _, topic := range l.Topics {
+				res.Topics = append(res.Topics, gointerfaces.ConvertHashToH256(topic))
 			}
+			reply = append(reply, res)
 		}
-
-		n.OnLogs(reply)
 	}
+	return reply
 }
 
 func (r *RecentLogs) Add(receipts types.Receipts) {
@@ -332,4 +556,5 @@ func (r *RecentLogs) CopyAndReset(target *RecentLogs) {
 		target.Add(receipts)
 		delete(r.receipts, blockNum)
 	}
+	clear(r.unwound)
 }
@@ -20,6 +20,8 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/gointerfaces"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/stretchr/testify/require"
 )
@@ -52,4 +54,59 @@ func TestRecentLogs(t *testing.T) {
 		e.Add(types.Receipts{{BlockNumber: big.NewInt(11)}})
 		require.Len(t, e.receipts, 2)
 	})
+	t.Run("Unwind", func(t *testing.T) {
+		receipts := func(bn int64, hash common.Hash) types.Receipts {
+			return types.Receipts{{BlockNumber: big.NewInt(bn), BlockHash: hash, Logs: types.Logs{{Index: 0}}}}
+		}
+		events := NewEvents()
+		events.EmptyLogSubscription(false)
+		ch, unsubscribe := events.AddLogsSubscription()
+		defer unsubscribe()
+
+		e := NewRecentLogs(8)
+		e.Add(receipts(1, common.Hash{1}))
+		e.Add(receipts(2, common.Hash{2}))
+		e.Add(receipts(3, common.Hash{3}))
+		e.Notify(events, 1, 4, false)
+		for bn := uint64(1); bn <= 3; bn++ {
+			reply := <-ch
+			require.Equal(t, bn, reply[0].BlockNumber)
+			require.False(t, reply[0].Removed)
+		}
+
+		e.Unwind(1)
+		e.Add(receipts(2, common.Hash{0xb2}))
+		e.Notify(events, 2, 3, true)
+		for _, want := range []struct {
+			bn      uint64
+			hash    common.Hash
+			removed bool
+		}{{2, common.Hash{2}, true}, {3, common.Hash{3}, true}, {2, common.Hash{0xb2}, false}} {
+			reply := <-ch
+			require.Equal(t, want.bn, reply[0].BlockNumber)
+			require.Equal(t, want.hash, gointerfaces.ConvertH256ToHash(reply[0].BlockHash))
+			require.Equal(t, want.removed, reply[0].Removed)
+		}
+		require.Empty(t, ch)
+
+		// the new branch has an empty block 3: the logs of the old block 3 are only sent as removed
+		e.Add(receipts(3, common.Hash{3}))
+		e.Notify(events, 3, 4, false)
+		reply := <-ch
+		require.Equal(t, uint64(3), reply[0].BlockNumber)
+		e.Unwind(2)
+		e.Add(types.Receipts{})
+		e.Notify(events, 3, 4, true)
+		reply = <-ch
+		require.Equal(t, uint64(3), reply[0].BlockNumber)
+		require.True(t, reply[0].Removed)
+		require.Empty(t, ch)
+		require.NotContains(t, e.receipts, uint64(3))
+
+		// an unwind which isn't notified as such, e.g. because it was rolled back, is forgotten
+		e.Unwind(1)
+		e.Notify(events, 3, 3, false)
+		e.Notify(events, 3, 3, true)
+		require.Empty(t, ch)
+	})
 }
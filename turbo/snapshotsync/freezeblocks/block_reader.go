@@ -29,6 +29,7 @@ import (
 	coresnaptype "github.com/erigontech/erigon-db/snaptype"
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/dbg"
+	"github.com/erigontech/erigon-lib/crypto"
 	"github.com/erigontech/erigon-lib/gointerfaces"
 	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
 	"github.com/erigontech/erigon-lib/kv"
@@ -266,6 +267,13 @@ func (r *RemoteBlockReader) Header(ctx context.Context, tx kv.Getter, hash commo
 	}
 	return block.Header(), nil
 }
+func (r *RemoteBlockReader) HeaderRLP(ctx context.Context, tx kv.Getter, hash common.Hash, blockHeight uint64) (rlp.RawValue, error) {
+	header, err := r.Header(ctx, tx, hash, blockHeight)
+	if err != nil || header == nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(header)
+}
 func (r *RemoteBlockReader) Body(ctx context.Context, tx kv.Getter, hash common.Hash, blockHeight uint64) (body *types.Body, txCount uint32, err error) {
 	block, _, err := r.BlockWithSenders(ctx, tx, hash, blockHeight)
 	if err != nil {
@@ -318,6 +326,10 @@ func (r *RemoteBlockReader) BodyRlp(ctx context.Context, tx kv.Getter, hash comm
 	return bodyRlp, nil
 }
 
+func (r *RemoteBlockReader) FrozenBodiesRlp(ctx context.Context, from uint64, count int) ([]rlp.RawValue, error) {
+	return nil, nil // no snapshots: BodyRlp reads each body
+}
+
 func (r *RemoteBlockReader) LastEventId(ctx context.Context, tx kv.Tx) (uint64, bool, error) {
 	return 0, false, errors.New("not implemented")
 }
@@ -425,6 +437,8 @@ type BlockReader struct {
 
 	//files are immutable: no reorgs, on updates - means no invalidation needed
 	headerByNumCache *lru.Cache[uint64, *types.Header]
+	// the canonical chain of the db changes on reorgs: invalidated by the stage loop hook
+	canonicalHashes *canonicalHashCache
 }
 
 var headerByNumCacheSize = dbg.EnvInt("RPC_HEADER_BY_NUM_LRU", 1_000)
@@ -434,6 +448,7 @@ func NewBlockReader(snapshots snapshotsync.BlockSnapshots, borSnapshots snapshot
 	sn, _ := snapshots.(*RoSnapshots)
 	br := &BlockReader{sn: sn, borSn: borSn, heimdallStore: heimdallStore, borBridgeStore: borBridge}
 	br.headerByNumCache, _ = lru.New[uint64, *types.Header](headerByNumCacheSize)
+	br.canonicalHashes = newCanonicalHashCache(canonicalHashCacheSize)
 	txnumReader := TxBlockIndexFromBlockReader(context.Background(), br).(*txBlockIndexWithBlockReader)
 	br.txBlockIndex = txnumReader
 	return br
@@ -532,7 +547,7 @@ func (r *BlockReader) HeaderByNumber(ctx context.Context, tx kv.Getter, blockHei
 	maxBlockNumInFiles := r.sn.BlocksAvailable()
 	if maxBlockNumInFiles == 0 || blockHeight > maxBlockNumInFiles {
 		if tx != nil {
-			blockHash, err := rawdb.ReadCanonicalHash(tx, blockHeight)
+			blockHash, err := r.canonicalHashes.read(tx, blockHeight)
 			if err != nil {
 				return nil, err
 			}
@@ -633,8 +648,13 @@ func (r *BlockReader) HeaderByHash(ctx context.Context, tx kv.Getter, hash commo
 
 var emptyHash = common.Hash{}
 
+// InvalidateCanonicalHashes implements services.CanonicalHashCache.
+func (r *BlockReader) InvalidateCanonicalHashes(from, viewID uint64) {
+	r.canonicalHashes.invalidate(from, viewID)
+}
+
 func (r *BlockReader) CanonicalHash(ctx context.Context, tx kv.Getter, blockHeight uint64) (h common.Hash, ok bool, err error) {
-	h, err = rawdb.ReadCanonicalHash(tx, blockHeight)
+	h, err = r.canonicalHashes.read(tx, blockHeight)
 	if err != nil {
 		return emptyHash, false, err
 	}
@@ -689,6 +709,40 @@ func (r *BlockReader) Header(ctx context.Context, tx kv.Getter, hash common.Hash
 	return h, nil
 }
 
+// HeaderRLP - returns the header as stored in the db or in the snapshots, without decoding and re-encoding it
+func (r *BlockReader) HeaderRLP(ctx context.Context, tx kv.Getter, hash common.Hash, blockHeight uint64) (rlp.RawValue, error) {
+	if tx != nil {
+		if h := rawdb.ReadHeaderRLP(tx, hash, blockHeight); len(h) > 0 {
+			return common.CopyBytes(h), nil
+		}
+	}
+
+	seg, ok, release := r.sn.ViewSingleFile(coresnaptype.Headers, blockHeight)
+	if !ok {
+		return nil, nil
+	}
+	defer release()
+
+	index := seg.Src().Index()
+	if index == nil {
+		return nil, nil
+	}
+	gg := seg.Src().MakeGetter()
+	gg.Reset(index.OrdinalLookup(blockHeight - index.BaseDataID()))
+	if !gg.HasNext() {
+		return nil, nil
+	}
+	buf, _ := gg.Next(nil)
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	// the snapshots hold the canonical headers only: make sure it's the requested one
+	if common.BytesToHash(crypto.Keccak256(buf[1:])) != hash {
+		return nil, nil
+	}
+	return buf[1:], nil
+}
+
 func (r *BlockReader) BodyWithTransactions(ctx context.Context, tx kv.Getter, hash common.Hash, blockHeight uint64) (body *types.Body, err error) {
 	var dbgPrefix string
 	dbgLogs := dbg.Enabled(ctx)
@@ -782,6 +836,116 @@ func (r *BlockReader) BodyRlp(ctx context.Context, tx kv.Getter, hash common.Has
 	return bodyRlp, nil
 }
 
+// FrozenBodiesRlp returns the RLP of the bodies of the blocks from from to from+count-1, as BodyRlp, stopping at the
+// first one which isn't in the snapshots. The bodies and the transactions of each segment are read by getters
+// positioned once, instead of once per block.
+func (r *BlockReader) FrozenBodiesRlp(ctx context.Context, from uint64, count int) (bodies []rlp.RawValue, err error) {
+	maxBlockNumInFiles := r.sn.BlocksAvailable()
+	for blockNum := from; len(bodies) < count && maxBlockNumInFiles > 0 && blockNum <= maxBlockNumInFiles; {
+		bodiesSeg, ok, release := r.sn.ViewSingleFile(coresnaptype.Bodies, blockNum)
+		if !ok {
+			break
+		}
+		txnSeg, ok, releaseTxs := r.sn.ViewSingleFile(coresnaptype.Transactions, blockNum)
+		if !ok {
+			release()
+			break
+		}
+		to := min(bodiesSeg.To(), txnSeg.To(), maxBlockNumInFiles+1, blockNum+uint64(count-len(bodies)))
+		segmentBodies, err := r.segmentBodiesRlp(bodiesSeg, txnSeg, blockNum, to)
+		releaseTxs()
+		release()
+		if err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, segmentBodies...)
+		if blockNum+uint64(len(segmentBodies)) < to {
+			break
+		}
+		blockNum = to
+	}
+	return bodies, nil
+}
+
+// maxTxnSkip is the largest gap between the transactions of consecutive blocks, their system transactions, the
+// transactions getter of segmentBodiesRlp skips instead of being positioned again.
+const maxTxnSkip = 16
+
+// segmentBodiesRlp returns the RLP of the bodies of the blocks [from, to) of the segments, stopping at the first one
+// missing.
+func (r *BlockReader) segmentBodiesRlp(bodiesSeg, txnSeg *snapshotsync.VisibleSegment, from, to uint64) (bodies []rlp.RawValue, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			panic(fmt.Errorf("%+v, snapshot: %d-%d, trace: %s", rec, bodiesSeg.From(), bodiesSeg.To(), dbg.Stack()))
+		}
+	}() // avoid crash because Erigon's core does many things
+
+	bodiesIdx, txnIdx := bodiesSeg.Src().Index(), txnSeg.Src().Index(coresnaptype.Indexes.TxnHash)
+	if bodiesIdx == nil || txnIdx == nil {
+		return nil, nil
+	}
+	bodiesGetter, txnGetter := bodiesSeg.Src().MakeGetter(), txnSeg.Src().MakeGetter()
+	bodiesGetter.Reset(bodiesIdx.OrdinalLookup(from - bodiesIdx.BaseDataID()))
+	nextTxnID, positioned := uint64(0), false // the id of the transaction the transactions getter is at
+	var bodyBuf, txnBuf []byte
+	for blockNum := from; blockNum < to; blockNum++ {
+		if !bodiesGetter.HasNext() {
+			break
+		}
+		bodyBuf, _ = bodiesGetter.Next(bodyBuf[:0])
+		if len(bodyBuf) == 0 {
+			break
+		}
+		b := &types.BodyForStorage{}
+		if err := rlp.DecodeBytes(bodyBuf, b); err != nil {
+			return nil, err
+		}
+		var txCount uint32
+		if b.TxCount >= 2 {
+			txCount = b.TxCount - 2 // empty txs in the beginning and end of block
+		}
+		baseTxnID := b.BaseTxnID.First()
+		if baseTxnID < txnIdx.BaseDataID() {
+			return nil, fmt.Errorf(".idx file has wrong baseDataID? %d<%d, %s", baseTxnID, txnIdx.BaseDataID(), txnSeg.Src().FileName())
+		}
+
+		txs, senders := make([]types.Transaction, txCount), make([]common.Address, txCount)
+		if txCount > 0 {
+			if !positioned || baseTxnID < nextTxnID || baseTxnID-nextTxnID > maxTxnSkip {
+				txnGetter.Reset(txnIdx.OrdinalLookup(baseTxnID - txnIdx.BaseDataID()))
+				nextTxnID, positioned = baseTxnID, true
+			}
+			for ; nextTxnID < baseTxnID; nextTxnID++ {
+				txnGetter.Skip()
+			}
+		}
+		for i := range txs {
+			if !txnGetter.HasNext() {
+				return bodies, nil
+			}
+			txnBuf, _ = txnGetter.Next(txnBuf[:0])
+			nextTxnID++
+			if len(txnBuf) < 1+20 {
+				return nil, fmt.Errorf("segment %s has too short record: len(buf)=%d < 21", txnSeg.Src().FileName(), len(txnBuf))
+			}
+			senders[i].SetBytes(txnBuf[1 : 1+20])
+			if txs[i], err = types.DecodeTransaction(txnBuf[1+20:]); err != nil {
+				return nil, err
+			}
+			txs[i].SetSender(senders[i])
+		}
+
+		body := &types.Body{Transactions: txs, Uncles: b.Uncles, Withdrawals: b.Withdrawals}
+		body.SendersToTxs(senders)
+		bodyRlp, err := rlp.EncodeToBytes(body)
+		if err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, bodyRlp)
+	}
+	return bodies, nil
+}
+
 func (r *BlockReader) Body(ctx context.Context, tx kv.Getter, hash common.Hash, blockHeight uint64) (body *types.Body, txCount uint32, err error) {
 	maxBlockNumInFiles := r.sn.BlocksAvailable()
 	if maxBlockNumInFiles == 0 || blockHeight > maxBlockNumInFiles {
@@ -1368,7 +1532,7 @@ func (r *BlockReader) BlockByNumber(ctx context.Context, db kv.Tx, number uint64
 	maxBlockNumInFiles := r.sn.BlocksAvailable()
 	if maxBlockNumInFiles == 0 || number > maxBlockNumInFiles {
 		var err error
-		hash, err = rawdb.ReadCanonicalHash(db, number)
+		hash, err = r.canonicalHashes.read(db, number)
 		if err != nil {
 			return nil, fmt.Errorf("failed ReadCanonicalHash: %w", err)
 		}
@@ -1466,6 +1630,17 @@ func (r *BlockReader) EventLookup(ctx context.Context, tx kv.Tx, txnHash common.
 	return txHandler.WithTx(tx).EventTxnToBlockNum(ctx, txnHash)
 }
 
+// EventTxnsLookup is EventLookup of a batch of hashes, the ones not found being absent from the result.
+func (r *BlockReader) EventTxnsLookup(ctx context.Context, tx kv.Tx, txnHashes []common.Hash) (map[common.Hash]uint64, error) {
+	txHandler, ok := r.borBridgeStore.(interface{ WithTx(kv.Tx) bridge.Store })
+
+	if !ok {
+		return nil, fmt.Errorf("%T has no WithTx converter", r.borBridgeStore)
+	}
+
+	return txHandler.WithTx(tx).EventTxnsToBlockNums(ctx, txnHashes)
+}
+
 func (r *BlockReader) BorStartEventId(ctx context.Context, tx kv.Tx, hash common.Hash, blockHeight uint64) (uint64, error) {
 	txHandler, ok := r.borBridgeStore.(interface{ WithTx(kv.Tx) bridge.Store })
 
@@ -1487,8 +1662,8 @@ func (r *BlockReader) EventsByBlock(ctx context.Context, tx kv.Tx, hash common.H
 }
 
 // EventsByIdFromSnapshot returns the list of records limited by time, or the number of records along with a bool value to signify if the records were limited by time
-func (r *BlockReader) EventsByIdFromSnapshot(from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
-	return r.borBridgeStore.EventsByIdFromSnapshot(from, to, limit)
+func (r *BlockReader) EventsByIdFromSnapshot(ctx context.Context, from uint64, to time.Time, limit int) ([]*heimdall.EventRecordWithTime, bool, error) {
+	return r.borBridgeStore.EventsByIdFromSnapshot(ctx, from, to, limit)
 }
 
 func (r *BlockReader) LastEventId(ctx context.Context, tx kv.Tx) (uint64, bool, error) {
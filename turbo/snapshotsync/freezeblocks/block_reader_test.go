@@ -28,7 +28,10 @@ import (
 
 	coresnaptype "github.com/erigontech/erigon-db/snaptype"
 	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/memdb"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon-lib/recsplit"
 	"github.com/erigontech/erigon-lib/seg"
@@ -36,6 +39,7 @@ import (
 	"github.com/erigontech/erigon-lib/testlog"
 	"github.com/erigontech/erigon-lib/version"
 	"github.com/erigontech/erigon/eth/ethconfig"
+	bortypes "github.com/erigontech/erigon/polygon/bor/types"
 	"github.com/erigontech/erigon/polygon/bridge"
 	"github.com/erigontech/erigon/polygon/heimdall"
 )
@@ -329,3 +333,83 @@ func createTestBorEventSegmentFile(t *testing.T, from, to, eventId uint64, dir s
 	err = idx.Build(context.Background())
 	require.NoError(t, err)
 }
+
+// createTestBorEventTxnSegmentFile creates an events segment holding a single event of the block, indexed by the hash
+// of its state-sync transaction.
+func createTestBorEventTxnSegmentFile(t *testing.T, from, to, blockNum, eventId uint64, txnHash common.Hash, dir string, logger log.Logger) {
+	compressCfg := seg.DefaultCfg
+	compressCfg.MinPatternScore = 100
+	compressor, err := seg.NewCompressor(context.Background(), "test", filepath.Join(dir, snaptype.SegmentFileName(version.V1_0, from, to, heimdall.Enums.Events)), dir, compressCfg, log.LvlDebug, logger)
+	require.NoError(t, err)
+	defer compressor.Close()
+	compressor.DisableFsync()
+	data := make([]byte, length.Hash+length.BlockNum+8, length.Hash+length.BlockNum+8+1)
+	copy(data, txnHash[:])
+	binary.BigEndian.PutUint64(data[length.Hash:], blockNum)
+	binary.BigEndian.PutUint64(data[length.Hash+length.BlockNum:], eventId)
+	data = append(data, 0xc0)
+	require.NoError(t, compressor.AddWord(data))
+	require.NoError(t, compressor.Compress())
+
+	idx, err := recsplit.NewRecSplit(recsplit.RecSplitArgs{
+		KeyCount:   1,
+		Enums:      true,
+		BucketSize: 10,
+		TmpDir:     dir,
+		IndexFile:  filepath.Join(dir, snaptype.IdxFileName(version.V1_0, from, to, heimdall.Events.Name())),
+		LeafSize:   8,
+		BaseDataID: eventId,
+	}, logger)
+	require.NoError(t, err)
+	defer idx.Close()
+	idx.DisableFsync()
+	require.NoError(t, idx.AddKey(txnHash[:], 0))
+	require.NoError(t, idx.Build(context.Background()))
+}
+
+func TestBlockReaderEventLookupFrozenEvents(t *testing.T) {
+	t.Parallel()
+
+	logger := testlog.Logger(t, log.LvlInfo)
+	ctx := context.Background()
+	newBlockReader := func(dir string) *BlockReader {
+		borRoSnapshots := heimdall.NewRoSnapshots(ethconfig.BlocksFreezing{ChainName: networkname.BorMainnet}, dir, 0, logger)
+		t.Cleanup(borRoSnapshots.Close)
+		require.NoError(t, borRoSnapshots.OpenFolder())
+		bridgeStore := bridge.NewSnapshotStore(bridge.NewMdbxStore(filepath.Join(t.TempDir(), "datadir"), logger, false, 1), borRoSnapshots, nil)
+		return &BlockReader{borSn: borRoSnapshots, borBridgeStore: bridgeStore}
+	}
+
+	const blockNum = 1_008
+	txnHash := bortypes.ComputeBorTxHash(blockNum, common.Hash{0x01})
+	tx, err := memdb.NewTestDB(t, kv.ChainDB).BeginRw(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	// before freezing the events, their transactions are looked up in the db
+	unfrozen := newBlockReader(t.TempDir())
+	require.NoError(t, unfrozen.borBridgeStore.(*bridge.SnapshotStore).WithTx(tx).PutEventTxnToBlockNum(ctx, map[common.Hash]uint64{txnHash: blockNum}))
+	found, ok, err := unfrozen.EventLookup(ctx, tx, txnHash)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(blockNum), found)
+
+	// once frozen and pruned from the db, in the snapshots
+	dir := t.TempDir()
+	createTestBorEventTxnSegmentFile(t, 0, 500_000, blockNum, 7, txnHash, dir, logger)
+	createTestSegmentFile(t, 0, 500_000, heimdall.Enums.Spans, dir, version.V1_0, logger)
+	require.NoError(t, tx.Delete(kv.BorTxLookup, txnHash[:]))
+	_, ok, err = unfrozen.EventLookup(ctx, tx, txnHash)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	frozen := newBlockReader(dir)
+	found, ok, err = frozen.EventLookup(ctx, tx, txnHash)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(blockNum), found)
+
+	_, ok, err = frozen.EventLookup(ctx, tx, bortypes.ComputeBorTxHash(blockNum, common.Hash{0x02}))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package freezeblocks
+
+import (
+	"sync"
+
+	"github.com/hashicorp/golang-lru/v2"
+
+	"github.com/erigontech/erigon-db/rawdb"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/dbg"
+	"github.com/erigontech/erigon-lib/kv"
+)
+
+var canonicalHashCacheSize = dbg.EnvInt("CANONICAL_HASH_LRU", 4_096)
+
+// canonicalHashCache is a read-through cache of the canonical hashes of the recently read block numbers, shared by
+// all the readers of the db. Unlike the files, the canonical chain of the db changes on reorgs: the stage loop hook
+// invalidates the hashes of the unwound blocks, see invalidate. The cache is off until the first invalidation: without
+// a stage loop to invalidate it, e.g. in an rpcdaemon on a remote db, it would serve the unwound hashes.
+//
+// Only read-only transactions go through the cache: a RwTx may read the hashes it wrote and may never commit. A
+// transaction of a view older than the last invalidation still sees the unwound hashes, so it bypasses the cache.
+type canonicalHashCache struct {
+	mu        sync.Mutex
+	hashes    *lru.Cache[uint64, common.Hash]
+	minViewID uint64 // the view of the last invalidation, the older ones bypass the cache
+	on        bool
+}
+
+func newCanonicalHashCache(size int) *canonicalHashCache {
+	hashes, _ := lru.New[uint64, common.Hash](size)
+	return &canonicalHashCache{hashes: hashes}
+}
+
+// cacheableView returns the view of tx, if its reads can go through the cache.
+func cacheableView(tx kv.Getter) (viewID uint64, ok bool) {
+	if ro, ok := tx.(interface{ IsRo() bool }); ok {
+		if !ro.IsRo() {
+			return 0, false
+		}
+	} else if _, rw := tx.(kv.Putter); rw {
+		return 0, false
+	}
+	view, ok := tx.(interface{ ViewID() uint64 })
+	if !ok {
+		return 0, false
+	}
+	return view.ViewID(), true
+}
+
+// read returns the canonical hash of the block blockNum in the db, the empty hash if there is none.
+func (c *canonicalHashCache) read(tx kv.Getter, blockNum uint64) (common.Hash, error) {
+	viewID, cacheable := cacheableView(tx)
+	if c == nil || !cacheable {
+		return rawdb.ReadCanonicalHash(tx, blockNum)
+	}
+	c.mu.Lock()
+	hash, cached := common.Hash{}, false
+	if c.on && viewID >= c.minViewID {
+		hash, cached = c.hashes.Get(blockNum)
+	}
+	c.mu.Unlock()
+	if cached {
+		return hash, nil
+	}
+
+	hash, err := rawdb.ReadCanonicalHash(tx, blockNum)
+	if err != nil || hash == (common.Hash{}) {
+		return hash, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// the hash may have been unwound while it was read
+	if c.on && viewID >= c.minViewID {
+		c.hashes.Add(blockNum, hash)
+	}
+	return hash, nil
+}
+
+// invalidate drops the hashes of the blocks from the block from on, which the transactions of the views from viewID on
+// may not see as canonical anymore.
+func (c *canonicalHashCache) invalidate(from, viewID uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.minViewID, c.on = max(c.minViewID, viewID), true
+	for _, blockNum := range c.hashes.Keys() {
+		if blockNum >= from {
+			c.hashes.Remove(blockNum)
+		}
+	}
+}
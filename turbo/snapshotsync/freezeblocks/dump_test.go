@@ -27,6 +27,7 @@ import (
 	"github.com/jinzhu/copier"
 	"github.com/stretchr/testify/require"
 
+	"github.com/erigontech/erigon-db/rawdb"
 	"github.com/erigontech/erigon-lib/chain"
 	"github.com/erigontech/erigon-lib/chain/networkname"
 	"github.com/erigontech/erigon-lib/chain/snapcfg"
@@ -38,6 +39,7 @@ import (
 	"github.com/erigontech/erigon-lib/rlp"
 	"github.com/erigontech/erigon-lib/types"
 	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/execution/stages/mock"
 	"github.com/erigontech/erigon/polygon/bor/borcfg"
 	polychain "github.com/erigontech/erigon/polygon/chain"
@@ -276,7 +278,7 @@ func TestDump(t *testing.T) {
 	}
 }
 
-func createDumpTestKV(t *testing.T, chainConfig *chain.Config, chainSize int) *mock.MockSentry {
+func createDumpTestKV(t testing.TB, chainConfig *chain.Config, chainSize int) *mock.MockSentry {
 	var (
 		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		addr   = crypto.PubkeyToAddress(key.PublicKey)
@@ -308,3 +310,79 @@ func createDumpTestKV(t *testing.T, chainConfig *chain.Config, chainSize int) *m
 
 	return m
 }
+
+// frozenBodiesTestReader returns a mock of 1000 blocks of a transaction each, and a reader of their snapshots.
+func frozenBodiesTestReader(tb testing.TB) (*mock.MockSentry, *freezeblocks.BlockReader) {
+	tb.Helper()
+	logger := log.New()
+	m := createDumpTestKV(tb, chain.TestChainConfig, 1_000)
+	snapDir := tb.TempDir()
+	require.NoError(tb, freezeblocks.DumpBlocks(m.Ctx, 0, 1_000, m.ChainConfig, tb.TempDir(), snapDir, m.DB, 1, log.LvlInfo, logger, m.BlockReader))
+	snapshots := freezeblocks.NewRoSnapshots(ethconfig.BlocksFreezing{ChainName: m.ChainConfig.ChainName}, snapDir, 0, logger)
+	tb.Cleanup(snapshots.Close)
+	require.NoError(tb, snapshots.OpenFolder())
+	require.Equal(tb, uint64(999), snapshots.BlocksAvailable())
+	return m, freezeblocks.NewBlockReader(snapshots, nil, nil, nil)
+}
+
+func TestFrozenBodiesRlp(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	require := require.New(t)
+	m, blockReader := frozenBodiesTestReader(t)
+	tx, err := m.DB.BeginRo(m.Ctx)
+	require.NoError(err)
+	defer tx.Rollback()
+
+	for _, test := range []struct {
+		from     uint64
+		count    int
+		expected int
+	}{
+		{from: 0, count: 1_000, expected: 1_000},
+		{from: 500, count: 256, expected: 256},
+		{from: 990, count: 20, expected: 10}, // up to the last frozen block
+		{from: 1_000, count: 10, expected: 0},
+	} {
+		bodies, err := blockReader.FrozenBodiesRlp(m.Ctx, test.from, test.count)
+		require.NoError(err)
+		require.Len(bodies, test.expected, "from %d", test.from)
+		for i, body := range bodies {
+			blockNum := test.from + uint64(i)
+			hash, err := rawdb.ReadCanonicalHash(tx, blockNum)
+			require.NoError(err)
+			expected, err := blockReader.BodyRlp(m.Ctx, tx, hash, blockNum)
+			require.NoError(err)
+			require.Equal(expected, body, "block %d", blockNum)
+		}
+	}
+}
+
+func BenchmarkFrozenBodiesRlp(b *testing.B) {
+	m, blockReader := frozenBodiesTestReader(b)
+	const from, count = 500, 256
+	b.Run("batched", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bodies, err := blockReader.FrozenBodiesRlp(m.Ctx, from, count)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(bodies) != count {
+				b.Fatalf("got %d bodies", len(bodies))
+			}
+		}
+	})
+	b.Run("individual", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for blockNum := uint64(from); blockNum < from+count; blockNum++ {
+				// frozen: read by number, the hash is only used for the blocks in the db
+				if _, err := blockReader.BodyRlp(m.Ctx, nil, common.Hash{}, blockNum); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
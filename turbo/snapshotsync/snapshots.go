@@ -260,6 +260,10 @@ type DirtySegment struct {
 
 	// only caplin state
 	filePath string
+
+	// the structures the readers derive from the data of the segment, dropped with it, see Derived
+	derivedLock sync.Mutex
+	derived     map[string]any
 }
 
 func NewDirtySegment(segType snaptype.Type, version snaptype.Version, from uint64, to uint64, frozen bool) *DirtySegment {
@@ -360,6 +364,10 @@ func (s *DirtySegment) FileInfo(dir string) snaptype.FileInfo {
 
 func (s *DirtySegment) GetRange() (from, to uint64) { return s.from, s.to }
 func (s *DirtySegment) GetType() snaptype.Type      { return s.segType }
+
+// Refcount is the number of the open views of a segment which isn't frozen, it being removed once they're all closed.
+func (s *DirtySegment) Refcount() int32 { return s.refcount.Load() }
+
 func (s *DirtySegment) isSubSetOf(j *DirtySegment) bool {
 	return (j.from <= s.from && s.to <= j.to) && (j.from != s.from || s.to != j.to)
 }
@@ -380,6 +388,28 @@ func (s *DirtySegment) closeSeg() {
 		s.Close()
 		s.Decompressor = nil
 	}
+	s.derivedLock.Lock()
+	s.derived = nil
+	s.derivedLock.Unlock()
+}
+
+// Derived returns the structure derived from the data of the segment under key, built by the first call. It's dropped
+// when the segment is closed, so that the segments opened again, e.g. by OpenFolder, are derived again.
+func (s *DirtySegment) Derived(key string, build func() (any, error)) (any, error) {
+	s.derivedLock.Lock()
+	defer s.derivedLock.Unlock()
+	if derived, ok := s.derived[key]; ok {
+		return derived, nil
+	}
+	derived, err := build()
+	if err != nil {
+		return nil, err
+	}
+	if s.derived == nil {
+		s.derived = map[string]any{}
+	}
+	s.derived[key] = derived
+	return derived, nil
 }
 
 func (s *DirtySegment) closeIdx() {
@@ -1085,8 +1115,22 @@ func (s *RoSnapshots) openSegments(fileNames []string, open bool, optimistic boo
 			sn = &DirtySegment{segType: f.Type, version: f.Version, Range: Range{f.From, f.To}, frozen: snConfig.IsFrozen(f)}
 		}
 
+		if open && !exists {
+			// a segment truncated by an interrupted download isn't made visible: it's left in place for the downloader
+			// to complete, or for VerifySegments to move away
+			if _, _, err := segmentSize(f.Path); errors.Is(err, ErrSegmentSize) {
+				log.Warn("[snapshots] skipping segment not matching its torrent", "file", fName, "err", err)
+				continue
+			}
+		}
+
 		if open {
 			if err := sn.Open(s.dir); err != nil {
+				var corrupted *seg.ErrCompressedFileCorrupted
+				if !exists && errors.As(err, &corrupted) {
+					log.Warn("[snapshots] skipping corrupted segment", "file", fName, "err", err)
+					continue
+				}
 				if errors.Is(err, os.ErrNotExist) {
 					if optimistic {
 						continue
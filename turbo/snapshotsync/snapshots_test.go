@@ -339,6 +339,42 @@ func TestDeleteSnapshots(t *testing.T) {
 	}
 }
 
+func TestSegmentDerivedDroppedWhenClosed(t *testing.T) {
+	logger := log.New()
+	dir, require := t.TempDir(), require.New(t)
+	for _, snT := range coresnaptype.BlockSnapshotTypes {
+		createTestSegmentFile(t, 0, 500_000, snT.Enum(), dir, version.V1_0, logger)
+	}
+	s := NewRoSnapshots(ethconfig.BlocksFreezing{ChainName: networkname.Mainnet}, dir, coresnaptype.BlockSnapshotTypes, 0, true, logger)
+	defer s.Close()
+	require.NoError(s.OpenFolder())
+
+	builds := 0
+	derive := func() *DirtySegment {
+		view := s.View()
+		defer view.Close()
+		sn, ok := view.Segment(coresnaptype.Headers, 10)
+		require.True(ok)
+		derived, err := sn.Src().Derived("test", func() (any, error) {
+			builds++
+			return builds, nil
+		})
+		require.NoError(err)
+		require.Equal(builds, derived)
+		return sn.Src()
+	}
+	first := derive()
+	derive()
+	require.Equal(1, builds)
+
+	// the segment opened again with the folder is derived again
+	s.Close()
+	require.NoError(s.OpenFolder())
+	require.NotSame(first, derive())
+	require.Equal(2, builds)
+	require.Nil(first.derived)
+}
+
 func TestRemoveOverlaps(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -0,0 +1,178 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshotsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/anacrolix/torrent/metainfo"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/seg"
+	"github.com/erigontech/erigon-lib/snaptype"
+)
+
+// QuarantineDir is the directory, in the snapshots directory, the segments failing VerifySegments are moved to with
+// their indices: out of the way of the open path, their .torrent left for the downloader to fetch them again. The
+// open path itself only skips the segments not matching their .torrent, the process opening them may not own the
+// snapshots directory.
+const QuarantineDir = "quarantine"
+
+var (
+	// ErrSegmentSize is the error of a segment whose size doesn't match the size in its .torrent: mostly truncated by
+	// an interrupted download.
+	ErrSegmentSize = errors.New("segment size doesn't match its torrent")
+	// ErrSegmentUnreadable is the error of a segment whose words, up to the last one, can't all be read.
+	ErrSegmentUnreadable = errors.New("segment words unreadable")
+)
+
+// SegmentStatus is the result of the verification of a segment file.
+type SegmentStatus struct {
+	FileName     string
+	Size         int64
+	ExpectedSize int64 // the size in the .torrent of the segment, 0 if it has none
+	Err          error // nil if the segment passed verification
+	Quarantined  bool
+}
+
+func (s SegmentStatus) OK() bool { return s.Err == nil }
+
+// VerifySegments verifies the segment files of the types of the snapshots: their size against their .torrent, their
+// header, and that all their words, the last one included, are readable. The segments failing verification are
+// quarantined, see QuarantineDir, and closed if they were open. With failFast it stops at the first failure.
+func (s *RoSnapshots) VerifySegments(ctx context.Context, failFast bool) ([]SegmentStatus, error) {
+	files, _, err := TypedSegments(s.dir, s.segmentsMin.Load(), s.Types(), true)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]SegmentStatus, 0, len(files))
+	var quarantined bool
+	for _, f := range files {
+		status := verifySegment(ctx, f.Path, true)
+		if err := ctx.Err(); err != nil {
+			return statuses, err
+		}
+		if !status.OK() {
+			if err := quarantineSegment(s.dir, f); err != nil {
+				log.Warn("[snapshots] can't quarantine segment", "file", status.FileName, "err", err)
+			} else {
+				status.Quarantined, quarantined = true, true
+			}
+		}
+		statuses = append(statuses, status)
+		if !status.OK() && failFast {
+			break
+		}
+	}
+	if quarantined && s.SegmentsReady() {
+		// closes the quarantined segments, gone from the directory
+		if err := s.OpenFolder(); err != nil {
+			return statuses, err
+		}
+	}
+	return statuses, nil
+}
+
+// verifySegment verifies the segment file at path: its size against its .torrent, if it has one, and its header.
+// With readWords all its words are read too, which the header and the size of a segment without .torrent don't
+// tell are complete.
+func verifySegment(ctx context.Context, path string, readWords bool) (status SegmentStatus) {
+	status.FileName = filepath.Base(path)
+	if status.Size, status.ExpectedSize, status.Err = segmentSize(path); status.Err != nil {
+		return status
+	}
+	d, err := seg.NewDecompressor(path)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	defer d.Close()
+	if readWords {
+		status.Err = readAllWords(ctx, d)
+	}
+	return status
+}
+
+// segmentSize returns the size of the segment file at path, and the size in its .torrent, 0 if it has none. The error
+// is ErrSegmentSize if they don't match.
+func segmentSize(path string) (size, expected int64, err error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	size = fi.Size()
+	mi, err := metainfo.LoadFromFile(path + ".torrent")
+	if errors.Is(err, fs.ErrNotExist) {
+		return size, 0, nil
+	}
+	if err != nil {
+		return size, 0, fmt.Errorf("can't read the torrent of the segment: %w", err)
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		return size, 0, fmt.Errorf("can't read the torrent of the segment: %w", err)
+	}
+	expected = info.TotalLength()
+	if size != expected {
+		return size, expected, fmt.Errorf("%w: %d bytes, expected %d", ErrSegmentSize, size, expected)
+	}
+	return size, expected, nil
+}
+
+// readAllWords skips through all the words of the segment, up to the last one.
+func readAllWords(ctx context.Context, d *seg.Decompressor) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%w: %v", ErrSegmentUnreadable, rec)
+		}
+	}()
+	g := d.MakeGetter()
+	var words int
+	for g.HasNext() {
+		if words%100_000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		g.Skip()
+		words++
+	}
+	if words != d.Count() {
+		return fmt.Errorf("%w: read %d words, expected %d", ErrSegmentUnreadable, words, d.Count())
+	}
+	return nil
+}
+
+// quarantineSegment moves the segment file and its indices to QuarantineDir.
+func quarantineSegment(dir string, f snaptype.FileInfo) error {
+	quarantineDir := filepath.Join(dir, QuarantineDir)
+	if err := os.MkdirAll(quarantineDir, 0o755); err != nil {
+		return err
+	}
+	fileNames := append([]string{filepath.Base(f.Path)}, f.Type.IdxFileNames(f.Version, f.From, f.To)...)
+	for _, fileName := range fileNames {
+		if err := os.Rename(filepath.Join(dir, fileName), filepath.Join(quarantineDir, fileName)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
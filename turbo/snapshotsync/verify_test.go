@@ -0,0 +1,142 @@
+// Copyright 2025 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshotsync
+
+import (
+	"context"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-db/downloader"
+	coresnaptype "github.com/erigontech/erigon-db/snaptype"
+	"github.com/erigontech/erigon-lib/chain/networkname"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/seg"
+	"github.com/erigontech/erigon-lib/snaptype"
+	"github.com/erigontech/erigon-lib/version"
+	"github.com/erigontech/erigon/eth/ethconfig"
+)
+
+// createTestSegmentWithWords creates a segment of random words, with its index, and its .torrent if withTorrent.
+func createTestSegmentWithWords(t *testing.T, dir string, from, to uint64, typ snaptype.Type, withTorrent bool, logger log.Logger) string {
+	createTestSegmentFile(t, from, to, typ.Enum(), dir, version.V1_0, logger) // the index
+
+	fileName := snaptype.SegmentFileName(version.V1_0, from, to, typ.Enum())
+	c, err := seg.NewCompressor(context.Background(), "test", filepath.Join(dir, fileName), dir, seg.DefaultCfg, log.LvlDebug, logger)
+	require.NoError(t, err)
+	defer c.Close()
+	c.DisableFsync()
+	for i := 0; i < 1_000; i++ {
+		word := make([]byte, 100)
+		_, _ = rand.Read(word)
+		require.NoError(t, c.AddWord(word))
+	}
+	require.NoError(t, c.Compress())
+
+	if withTorrent {
+		created, err := downloader.BuildTorrentIfNeed(context.Background(), fileName, dir, downloader.NewAtomicTorrentFS(dir))
+		require.NoError(t, err)
+		require.True(t, created)
+	}
+	return filepath.Join(dir, fileName)
+}
+
+func truncateFile(t *testing.T, path string, size int64) {
+	require.NoError(t, os.Chmod(path, 0o644))
+	require.NoError(t, os.Truncate(path, size))
+}
+
+func requireQuarantined(t *testing.T, dir string, path string) {
+	t.Helper()
+	require.NoFileExists(t, path)
+	require.FileExists(t, filepath.Join(dir, QuarantineDir, filepath.Base(path)))
+}
+
+func TestOpenFolderSkipsTruncatedSegments(t *testing.T) {
+	logger := log.New()
+	dir, require := t.TempDir(), require.New(t)
+	cfg := ethconfig.BlocksFreezing{ChainName: networkname.Mainnet}
+
+	intact := createTestSegmentWithWords(t, dir, 0, 500_000, coresnaptype.Headers, true, logger)
+	truncated := createTestSegmentWithWords(t, dir, 0, 500_000, coresnaptype.Bodies, true, logger)
+	fi, err := os.Stat(truncated)
+	require.NoError(err)
+	truncateFile(t, truncated, fi.Size()/2)
+	headerTruncated := createTestSegmentWithWords(t, dir, 0, 500_000, coresnaptype.Transactions, false, logger)
+	truncateFile(t, headerTruncated, 8)
+
+	s := NewRoSnapshots(cfg, dir, coresnaptype.BlockSnapshotTypes, 0, true, logger)
+	defer s.Close()
+	require.NoError(s.OpenFolder())
+	require.Equal([]string{intact}, s.OpenFiles())
+
+	// the skipped segments are left in place, for the downloader to complete them
+	require.FileExists(truncated)
+	require.FileExists(truncated + ".torrent")
+	require.FileExists(headerTruncated)
+	idx := coresnaptype.Bodies.IdxFileNames(version.V1_0, 0, 500_000)
+	require.FileExists(filepath.Join(dir, idx[0]))
+	require.NoDirExists(filepath.Join(dir, QuarantineDir))
+}
+
+func TestVerifySegments(t *testing.T) {
+	logger := log.New()
+	dir, require := t.TempDir(), require.New(t)
+	cfg := ethconfig.BlocksFreezing{ChainName: networkname.Mainnet}
+
+	intact := createTestSegmentWithWords(t, dir, 0, 500_000, coresnaptype.Headers, true, logger)
+	// without a .torrent to compare its size with, only reading the words tells the segment is truncated
+	truncated := createTestSegmentWithWords(t, dir, 0, 500_000, coresnaptype.Bodies, false, logger)
+	fi, err := os.Stat(truncated)
+	require.NoError(err)
+	truncateFile(t, truncated, fi.Size()-1_000)
+
+	s := NewRoSnapshots(cfg, dir, coresnaptype.BlockSnapshotTypes, 0, true, logger)
+	defer s.Close()
+	require.NoError(s.OpenFolder())
+	require.ElementsMatch([]string{intact, truncated}, s.OpenFiles())
+
+	statuses, err := s.VerifySegments(context.Background(), false)
+	require.NoError(err)
+	require.Len(statuses, 2)
+	for _, status := range statuses {
+		switch status.FileName {
+		case filepath.Base(intact):
+			require.True(status.OK(), status.Err)
+			require.Equal(status.Size, status.ExpectedSize)
+			require.False(status.Quarantined)
+		case filepath.Base(truncated):
+			require.ErrorIs(status.Err, ErrSegmentUnreadable)
+			require.Zero(status.ExpectedSize)
+			require.True(status.Quarantined)
+		default:
+			t.Fatalf("unexpected segment %s", status.FileName)
+		}
+	}
+	requireQuarantined(t, dir, truncated)
+	// the quarantined segment is closed
+	require.Equal([]string{intact}, s.OpenFiles())
+
+	statuses, err = s.VerifySegments(context.Background(), true)
+	require.NoError(err)
+	require.Len(statuses, 1)
+	require.True(statuses[0].OK())
+}
@@ -19,7 +19,6 @@ package transactions
 import (
 	"context"
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/holiman/uint256"
@@ -101,26 +100,50 @@ func DoCall(
 
 	evm := vm.NewEVM(blockCtx, txCtx, state, chainConfig, vm.Config{NoBaseFee: true})
 
-	// Wait for the context to be done and cancel the evm. Even if the
-	// EVM has finished, cancelling may be done (repeatedly)
-	go func() {
-		<-ctx.Done()
-		evm.Cancel()
-	}()
-
 	gp := new(core.GasPool).AddGas(msg.Gas()).AddBlobGas(msg.BlobGas())
+	stop := cancelOnDone(ctx, evm)
 	result, err := core.ApplyMessage(evm, msg, gp, true /* refunds */, false /* gasBailout */, engine)
+	stop()
 	if err != nil {
 		return nil, err
 	}
 
-	// If the timer caused an abort, return an appropriate error message
+	// If the timer or the request caused an abort, return an appropriate error message
 	if evm.Cancelled() {
-		return nil, fmt.Errorf("execution aborted (timeout = %v)", callTimeout)
+		return nil, abortError(ctx, callTimeout)
 	}
 	return result, nil
 }
 
+// cancelOnDone cancels the evm once ctx is done, until the returned function is called: the interpreter checks it
+// every few thousand steps, so that a call timing out, or whose request is cancelled, stops running. Once the
+// function returned the evm isn't cancelled anymore, and can be reset for another call.
+func cancelOnDone(ctx context.Context, evm *vm.EVM) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			evm.Cancel()
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// abortError returns the error of a call aborted as ctx is done: a TimeoutError if the timeout elapsed, the error of
+// ctx if the request was cancelled.
+func abortError(ctx context.Context, callTimeout time.Duration) error {
+	if err := ctx.Err(); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return &ethapi2.TimeoutError{Timeout: callTimeout}
+}
+
 func NewEVMBlockContext(engine consensus.EngineReader, header *types.Header, requireCanonical bool, tx kv.Getter,
 	headerReader services.HeaderReader, config *chain.Config) evmtypes.BlockContext {
 	blockHashFunc := MakeHeaderGetter(requireCanonical, tx, headerReader)
@@ -179,22 +202,18 @@ func (r *ReusableCaller) DoCallWithNewGas(
 
 	r.evm.Reset(txCtx, r.intraBlockState)
 
-	timedOut := false
-	go func() {
-		<-ctx.Done()
-		timedOut = true
-	}()
-
 	gp := new(core.GasPool).AddGas(r.message.Gas()).AddBlobGas(r.message.BlobGas())
 
+	stop := cancelOnDone(ctx, r.evm)
 	result, err := core.ApplyMessage(r.evm, r.message, gp, true /* refunds */, false /* gasBailout */, engine)
+	stop()
 	if err != nil {
 		return nil, err
 	}
 
-	// If the timer caused an abort, return an appropriate error message
-	if timedOut {
-		return nil, fmt.Errorf("execution aborted (timeout = %v)", r.callTimeout)
+	// If the timer or the request caused an abort, return an appropriate error message
+	if r.evm.Cancelled() {
+		return nil, abortError(ctx, r.callTimeout)
 	}
 
 	return result, nil
@@ -2686,6 +2686,52 @@ func (p *TxPool) deprecatedForEach(_ context.Context, f func(rlp []byte, sender
 
 	p.lock.Unlock()
 
+	p.forEachRlp(tx, txns, senders, f)
+}
+
+// contentPage calls f for at most limit transactions of the pool, in sender then nonce order, starting from the
+// transaction of the sender with id fromSender and nonce fromNonce. If sender is set, only its transactions are
+// visited. It returns the position of the first transaction of the next page, more is false on the last page.
+func (p *TxPool) contentPage(tx kv.Tx, sender *common.Address, fromSender, fromNonce uint64, limit int, f func(rlp []byte, sender common.Address, t SubPoolType)) (nextSender, nextNonce uint64, more bool) {
+	var txns []*metaTxn
+	var senders []common.Address
+
+	p.lock.Lock()
+
+	if sender != nil {
+		senderID, ok := p.senders.getID(*sender)
+		if !ok {
+			p.lock.Unlock()
+			return 0, 0, false
+		}
+		if fromSender != senderID {
+			fromSender, fromNonce = senderID, 0
+		}
+	}
+	p.all.ascendFrom(fromSender, fromNonce, func(mt *metaTxn) bool {
+		if sender != nil && mt.TxnSlot.SenderID != fromSender {
+			return false
+		}
+		if len(txns) == limit {
+			nextSender, nextNonce, more = mt.TxnSlot.SenderID, mt.TxnSlot.Nonce, true
+			return false
+		}
+		if addr, found := p.senders.getAddr(mt.TxnSlot.SenderID); found {
+			txns = append(txns, mt)
+			senders = append(senders, addr)
+		}
+		return true
+	})
+
+	p.lock.Unlock()
+
+	p.forEachRlp(tx, txns, senders, f)
+	return nextSender, nextNonce, more
+}
+
+// forEachRlp calls f for the given transactions with their RLP, reading it from the db for the transactions which
+// don't keep it in memory.
+func (p *TxPool) forEachRlp(tx kv.Tx, txns []*metaTxn, senders []common.Address, f func(rlp []byte, sender common.Address, t SubPoolType)) {
 	for i := range txns {
 		slotRlp := txns[i].TxnSlot.Rlp
 		if slotRlp == nil {
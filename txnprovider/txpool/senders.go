@@ -81,6 +81,15 @@ func (b *BySenderAndNonce) ascend(senderID uint64, f func(*metaTxn) bool) {
 	})
 }
 
+// ascendFrom iterates the transactions of all the senders in sender then nonce order, starting from the
+// transaction of the given sender and nonce.
+func (b *BySenderAndNonce) ascendFrom(senderID, nonce uint64, f func(*metaTxn) bool) {
+	s := b.search
+	s.TxnSlot.SenderID = senderID
+	s.TxnSlot.Nonce = nonce
+	b.tree.AscendGreaterOrEqual(s, f)
+}
+
 func (b *BySenderAndNonce) descend(senderID uint64, f func(*metaTxn) bool) {
 	s := b.search
 	s.TxnSlot.SenderID = senderID
@@ -18,6 +18,7 @@ package txpool
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
@@ -56,6 +57,7 @@ type txPool interface {
 	GetRlp(tx kv.Tx, hash []byte) ([]byte, error)
 	AddLocalTxns(ctx context.Context, newTxns TxnSlots) ([]txpoolcfg.DiscardReason, error)
 	deprecatedForEach(_ context.Context, f func(rlp []byte, sender common.Address, t SubPoolType), tx kv.Tx)
+	contentPage(tx kv.Tx, sender *common.Address, fromSender, fromNonce uint64, limit int, f func(rlp []byte, sender common.Address, t SubPoolType)) (nextSender, nextNonce uint64, more bool)
 	CountContent() (int, int, int)
 	IdHashKnown(tx kv.Tx, hash []byte) (bool, error)
 	NonceFromAddress(addr [20]byte) (nonce uint64, inPool bool)
@@ -86,6 +88,9 @@ func (*GrpcDisabled) Transactions(ctx context.Context, request *txpool_proto.Tra
 func (*GrpcDisabled) All(ctx context.Context, request *txpool_proto.AllRequest) (*txpool_proto.AllReply, error) {
 	return nil, ErrPoolDisabled
 }
+func (*GrpcDisabled) Content(ctx context.Context, request *txpool_proto.ContentRequest) (*txpool_proto.ContentReply, error) {
+	return nil, ErrPoolDisabled
+}
 func (*GrpcDisabled) Pending(ctx context.Context, empty *emptypb.Empty) (*txpool_proto.PendingReply, error) {
 	return nil, ErrPoolDisabled
 }
@@ -147,6 +152,66 @@ func (s *GrpcServer) All(ctx context.Context, _ *txpool_proto.AllRequest) (*txpo
 	return reply, nil
 }
 
+// defaultContentPageSize and maxContentPageSize bound the number of transactions in a reply of Content.
+const (
+	defaultContentPageSize = 1024
+	maxContentPageSize     = 16384
+)
+
+func (s *GrpcServer) Content(ctx context.Context, in *txpool_proto.ContentRequest) (*txpool_proto.ContentReply, error) {
+	fromSender, fromNonce, err := decodeContentPageToken(in.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	limit := defaultContentPageSize
+	if in.Limit > 0 {
+		limit = min(int(in.Limit), maxContentPageSize)
+	}
+	var onlySender *common.Address
+	if in.Sender != nil {
+		addr := gointerfaces.ConvertH160toAddress(in.Sender)
+		onlySender = &addr
+	}
+
+	tx, err := s.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	reply := &txpool_proto.ContentReply{}
+	reply.Txs = make([]*txpool_proto.AllReply_Tx, 0, 32)
+	nextSender, nextNonce, more := s.txPool.contentPage(tx, onlySender, fromSender, fromNonce, limit, func(rlp []byte, sender common.Address, t SubPoolType) {
+		reply.Txs = append(reply.Txs, &txpool_proto.AllReply_Tx{
+			Sender:  gointerfaces.ConvertAddressToH160(sender),
+			TxnType: convertSubPoolType(t),
+			RlpTx:   common.Copy(rlp),
+		})
+	})
+	if more {
+		reply.NextPageToken = encodeContentPageToken(nextSender, nextNonce)
+	}
+	return reply, nil
+}
+
+// A page token of Content is the position of the first transaction of the page: the id of its sender in the pool
+// and its nonce. Sender ids aren't reused, so the token stays valid while the pool changes.
+func encodeContentPageToken(senderID, nonce uint64) []byte {
+	token := make([]byte, 16)
+	binary.BigEndian.PutUint64(token, senderID)
+	binary.BigEndian.PutUint64(token[8:], nonce)
+	return token
+}
+
+func decodeContentPageToken(token []byte) (senderID, nonce uint64, err error) {
+	if len(token) == 0 {
+		return 0, 0, nil
+	}
+	if len(token) != 16 {
+		return 0, 0, fmt.Errorf("invalid page token: %x", token)
+	}
+	return binary.BigEndian.Uint64(token), binary.BigEndian.Uint64(token[8:]), nil
+}
+
 func (s *GrpcServer) Pending(ctx context.Context, _ *emptypb.Empty) (*txpool_proto.PendingReply, error) {
 	reply := &txpool_proto.PendingReply{}
 	reply.Txs = make([]*txpool_proto.PendingReply_Tx, 0, 32)